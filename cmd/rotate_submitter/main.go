@@ -0,0 +1,136 @@
+// Command rotate_submitter authorizes a new signing key on the AuditTrail
+// contract. The authorizeSubmitter call is signed by the primary configured
+// key (the first keystore file or private_key), which must be the contract
+// owner.
+//
+//	rotate_submitter -generate data/keystore          # new encrypted key
+//	rotate_submitter -keystore data/keystore/UTC--... # existing key
+//
+// The passphrase is read from -passphrase-file, defaulting to
+// blockchain.keystore.passphrase_file. Once authorized, add the file to
+// blockchain.keystore.files; remove a retired key from the list to stop
+// signing with it. Keys are shared by all ledger profiles; run once per
+// profile with -profile to authorize the key on each profile's contract.
+//
+// The authorization is sent through the same nonce allocator and
+// transaction store as the server, so it is safe to run while the server
+// is submitting with the primary key.
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func main() {
+	keystorePath := flag.String("keystore", "", "keystore file of the key to authorize")
+	generateDir := flag.String("generate", "", "generate a new encrypted key in this directory and authorize it")
+	passphraseFile := flag.String("passphrase-file", "", "passphrase of the new key (default: blockchain.keystore.passphrase_file)")
+	profile := flag.String("profile", config.DefaultLedgerProfile, "ledger profile whose contract authorizes the key")
+	timeout := flag.Duration("timeout", 10*time.Minute, "timeout for sending and confirming the authorization")
+	flag.Parse()
+	if (*keystorePath == "") == (*generateDir == "") {
+		log.Fatal("exactly one of -keystore or -generate is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	ledgerCfg, ok := cfg.Blockchain.Profile(*profile)
+	if !ok {
+		log.Fatalf("Unknown ledger profile %q", *profile)
+	}
+	if ledgerCfg.Backend != "" && ledgerCfg.Backend != config.LedgerBackendEVM {
+		log.Fatalf("Submitter rotation needs the evm backend, configured: %s", ledgerCfg.Backend)
+	}
+
+	if *passphraseFile == "" {
+		*passphraseFile = ledgerCfg.Keystore.PassphraseFile
+	}
+	passphrase, err := blockchain.ReadPassphrase(*passphraseFile)
+	if err != nil {
+		log.Fatalf("Failed to read passphrase: %v", err)
+	}
+
+	var newKey *ecdsa.PrivateKey
+	if *generateDir != "" {
+		account, err := keystore.StoreKey(*generateDir, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+		if err != nil {
+			log.Fatalf("Failed to generate key: %v", err)
+		}
+		*keystorePath = account.URL.Path
+		log.Printf("Generated %s in %s", account.Address.Hex(), *keystorePath)
+	}
+	if newKey, err = blockchain.LoadKeystoreKey(*keystorePath, passphrase); err != nil {
+		log.Fatalf("Failed to load new key: %v", err)
+	}
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(db)
+
+	// Build the ledger as the server does, so the authorization takes its
+	// nonce from the shared allocator and is tracked in the transaction
+	// store; a private nonce would collide with the running workers.
+	deps := blockchain.LedgerDeps{Transactions: postgres.NewBlockchainTransactionRepository(db)}
+	if cfg.Blockchain.NonceStore != config.NonceStoreMemory {
+		redisCache, err := cache.NewRedisCache(cfg)
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		defer redisCache.(*cache.RedisCache).Close()
+		deps.Nonces = blockchain.NewRedisNonceManager(redisCache.(*cache.RedisCache).GetClient())
+	}
+	// The new key may already be listed in keystore.files, so the startup
+	// check that every key is authorized must not run here.
+	ledgerCfg.CheckSubmitters = false
+	ledger, err := blockchain.NewLedger(ledgerCfg, deps)
+	if err != nil {
+		log.Fatalf("Failed to connect to ledger: %v", err)
+	}
+	defer ledger.Close()
+	svc, ok := ledger.(*blockchain.AuditTrailService)
+	if !ok {
+		log.Fatalf("Ledger profile %q is not an AuditTrail contract", *profile)
+	}
+	if len(svc.Submitters()) == 0 {
+		log.Fatal("No primary key configured to sign authorizeSubmitter")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	addr := crypto.PubkeyToAddress(newKey.PublicKey)
+	if ok, err := svc.IsAuthorizedSubmitter(ctx, addr); err != nil {
+		log.Fatalf("Failed to read authorization: %v", err)
+	} else if ok {
+		log.Printf("%s is already an authorized submitter", addr.Hex())
+		return
+	}
+
+	txHash, err := svc.RotateSubmitter(ctx, newKey)
+	if err != nil {
+		log.Fatalf("Rotation failed: %v", err)
+	}
+	if ok, err := svc.IsAuthorizedSubmitter(ctx, addr); err != nil || !ok {
+		log.Fatalf("Authorization mined in %s but not visible on the contract: %v", txHash, err)
+	}
+
+	fmt.Printf("Authorized %s in transaction %s\n", addr.Hex(), txHash)
+	fmt.Printf("Add %s to blockchain.keystore.files and restart to start signing with it.\n", *keystorePath)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -83,8 +84,9 @@ func main() {
 			if err == gorm.ErrRecordNotFound {
 				log.Println("Creating 'admin' role...")
 				adminRole = domain.Role{
-					ID:   uuid.New(),
-					Name: "admin",
+					ID:          uuid.New(),
+					Name:        "admin",
+					Permissions: datatypes.JSON(`["blockchain:correct"]`),
 				}
 				if err := tx.Create(&adminRole).Error; err != nil {
 					return err
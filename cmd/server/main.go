@@ -19,6 +19,7 @@ import (
 	"github.com/pressly/goose/v3"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/agent"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
@@ -27,11 +28,15 @@ import (
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
 	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
 	postgresRepo "github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auditlog"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/dashboard"
 	documentUsecase "github.com/Elysian-Rebirth/backend-go/internal/usecase/document"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine"
+	engineHandlers "github.com/Elysian-Rebirth/backend-go/internal/usecase/engine/handlers"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/pricing"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/rag"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/report"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/swarm"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/workflow"
 	"github.com/gin-contrib/cors"
@@ -134,6 +139,11 @@ func main() {
 	}
 
 	router := gin.New()
+	// Forwarding headers are only honoured from the configured proxies, so
+	// clients cannot choose the IP they are rate limited by.
+	if err := router.SetTrustedProxies(cfg.Security.TrustedProxies); err != nil {
+		log.Fatalf("Invalid security.trusted_proxies: %v", err)
+	}
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Logger())
 
@@ -177,11 +187,48 @@ func main() {
 	userHandler := handler.NewUserHandler(userRepo)
 	authHandler := handler.NewAuthHandler(authUseCase, cfg.IsProduction(), db)
 
+	asynqClient := mq.NewAsynqClient(cfg)
+
+	// Embedding models: the default one for new corpora plus those a tenant's
+	// corpus can be migrated to. Queries use the model of the tenant's active index.
+	var embeddingIndexes *rag.EmbeddingIndexService
+	var tenantEmbedders ai.TenantEmbedders
+	embedders, err := ai.NewEmbedders(context.Background(), cfg.AI)
+	if embedders == nil {
+		log.Printf("[WARN] Embedder initialization failed: %v — RAG ingestion and search disabled", err)
+	} else {
+		if err != nil {
+			log.Printf("[WARN] Some additional embedders failed to start: %v", err)
+		}
+		defer embedders.Close()
+		embeddingIndexes = rag.NewEmbeddingIndexService(postgresRepo.NewEmbeddingIndexRepository(db), embedders, asynqClient)
+		tenantEmbedders = embeddingIndexes
+		log.Printf("Embedders ready: default backend=%s, models=%s", cfg.AI.Embedding.Backend, strings.Join(embedders.Models(), ", "))
+	}
+
+	// Optional reranker, applied after hybrid search fusion when a search or
+	// rag_retriever node asks for it
+	reranker, err := ai.NewReranker(cfg.AI)
+	if err != nil {
+		log.Printf("[WARN] Reranker initialization failed: %v — reranking disabled", err)
+	} else if reranker != nil {
+		log.Printf("Reranker ready: backend=%s, model=%s", cfg.AI.Rerank.Backend, reranker.Model())
+	}
+
+	// Optional query expander for multi-query retrieval, used when a search,
+	// chat message or rag_retriever node asks for it
+	queryExpander, err := ai.NewQueryExpander(cfg.AI)
+	if err != nil {
+		log.Printf("[WARN] Query expander initialization failed: %v — query expansion disabled", err)
+	} else if queryExpander != nil {
+		log.Printf("Query expander ready: backend=%s", cfg.AI.QueryExpansion.Backend)
+	}
+
 	// Workflow Components
 	workflowRepo := postgresRepo.NewWorkflowRepository(db)
 	docRepo := postgresRepo.NewDocumentRepository(db)
 	auditRepo := postgresRepo.NewAuditRepository(db)
-	workflowUseCase := workflow.NewWorkflowUseCase(workflowRepo, docRepo, auditRepo, cfg.AI.GeminiAPIKey)
+	workflowUseCase := workflow.NewWorkflowUseCase(workflowRepo, docRepo, auditRepo, tenantEmbedders, reranker, queryExpander)
 	workflowHandler := handler.NewWorkflowHandler(workflowUseCase)
 
 	// Infrastructure Components
@@ -200,7 +247,6 @@ func main() {
 
 	// S3 Storage + Document Components
 	var documentHandler *handler.DocumentHandler
-	asynqClient := mq.NewAsynqClient(cfg)
 
 	s3Service, s3Err := storage.NewS3Service(&cfg.Storage)
 	if s3Err != nil {
@@ -213,45 +259,68 @@ func main() {
 		}
 	}
 
+	// Chunking defaults of ingestion and the chunk preview; tenants override them in settings
+	chunkingPolicy := rag.NewChunkingPolicy(cfg.AI.Chunking)
+
 	// Always initialize docUsecase and documentHandler to prevent panic dereferences
-	docUsecase := documentUsecase.NewDocumentUsecase(docRepo, s3Service, asynqClient, mongoStaging)
+	docUsecase := documentUsecase.NewDocumentUsecase(docRepo, s3Service, asynqClient, mongoStaging, chunkingPolicy)
 	documentHandler = handler.NewDocumentHandler(docUsecase)
 
 	// Asynq worker is initialized and started below to centralize handler registrations for both RAG and Swarm tasks.
 
 	authMiddleware := middleware.AuthMiddleware(jwtSvc, userRepo, roleRepo, redisCache.(*cache.RedisCache).GetClient(), db)
 
-	// RAG Search Handler — uses the already-initialized docRepo and Gemini key from config
+	// RAG Search Handler — uses the already-initialized docRepo and the tenant's embedding model
 	var ragSearchHandler *handler.RAGSearchHandler
-	if cfg.AI.GeminiAPIKey != "" {
-		ragSearchHandler = handler.NewRAGSearchHandler(postgresRepo.NewDocumentRepository(db), cfg.AI.GeminiAPIKey)
+	if tenantEmbedders != nil {
+		ragSearchHandler = handler.NewRAGSearchHandler(docRepo, tenantEmbedders, reranker, queryExpander)
 	}
-
-	// Blockchain Service (optional — only if configured)
-	var bcService *blockchain.AuditTrailService
-	if cfg.Blockchain.Enabled && cfg.Blockchain.RPCURL != "" {
+	embeddingIndexHandler := handler.NewEmbeddingIndexHandler(embeddingIndexes)
+
+	// Blockchain Ledgers (optional — only if configured), one per ledger profile
+	var ledgers *blockchain.Ledgers
+	if cfg.Blockchain.Enabled {
+		deps := blockchain.LedgerDeps{
+			Transactions: postgresRepo.NewBlockchainTransactionRepository(db),
+			Timestamps:   postgresRepo.NewTimestampTokenRepository(db),
+		}
+		if cfg.Blockchain.NonceStore != config.NonceStoreMemory {
+			deps.Nonces = blockchain.NewRedisNonceManager(redisCache.(*cache.RedisCache).GetClient())
+		}
 		var err error
-		bcService, err = blockchain.NewAuditTrailService(
-			cfg.Blockchain.RPCURL,
-			cfg.Blockchain.ContractAddr,
-			cfg.Blockchain.PrivateKey,
-			cfg.Blockchain.Network,
-		)
-		if err != nil {
-			log.Printf("[WARN] Blockchain service initialization failed: %v — audit trail will store hashes locally only", err)
-			bcService = nil
+		ledgers, err = blockchain.NewLedgers(cfg.Blockchain, deps)
+		if ledgers == nil {
+			log.Printf("[WARN] Blockchain ledger initialization failed: %v — audit trail will store hashes locally only", err)
 		} else {
-			log.Printf("Blockchain service connected: network=%s, contract=%s", cfg.Blockchain.Network, cfg.Blockchain.ContractAddr)
+			if err != nil {
+				log.Printf("[WARN] Some blockchain ledger profiles failed to start: %v", err)
+			}
+			defer ledgers.Close()
 		}
-		defer func() {
-			if bcService != nil {
-				bcService.Close()
+		for _, profile := range ledgers.Profiles() {
+			ledger, _ := ledgers.Get(profile)
+			settings, _ := cfg.Blockchain.Profile(profile)
+			log.Printf("Blockchain ledger ready: profile=%s, backend=%s, network=%s, contract=%s", profile, settings.Backend, ledger.Network(), ledger.ContractAddress())
+			if submitters, ok := ledger.(blockchain.SubmitterManager); ok {
+				log.Printf("Blockchain submitters (round-robin): %s", strings.Join(submitters.Submitters(), ", "))
 			}
-		}()
+
+			if bumper, ok := ledger.(blockchain.FeeBumper); ok && cfg.Blockchain.FeeBump.Enabled {
+				feeBumper, err := blockchain.StartFeeBumper(bumper, cfg.Blockchain.FeeBump)
+				if err != nil {
+					log.Printf("[WARN] Fee bumper failed to start for ledger profile %s: %v", profile, err)
+				} else {
+					defer feeBumper.Shutdown()
+				}
+			}
+		}
 	}
 
 	// Swarm Components
 	swarmRepo := postgresRepo.NewSwarmRepository(db)
+	swarmFindingRepo := postgresRepo.NewSwarmFindingRepository(db)
+	anchorBatchRepo := postgresRepo.NewAnchorBatchRepository(db)
+	ledgerCorrectionRepo := postgresRepo.NewLedgerCorrectionRepository(db)
 
 	// Initialize Asynq Worker and register all handlers (RAG and Swarm)
 	asynqWorker := mq.NewAsynqWorker(cfg)
@@ -259,15 +328,23 @@ func main() {
 	// Register RAG handlers if S3 is active
 	if s3Service != nil {
 		docParser := parsing.NewDocumentParser(cfg.AI.DoclingURL, cfg.AI.UnstructuredURL)
-		docTaskHandler := rag.NewDocumentTaskHandler(docRepo, s3Service, docParser, cfg.AI.GeminiAPIKey, mongoStaging)
+		docTaskHandler := rag.NewDocumentTaskHandler(docRepo, s3Service, docParser, embeddingIndexes, mongoStaging, chunkingPolicy)
 		asynqWorker.RegisterHandler(rag.TypeParseDocument, docTaskHandler.HandleParseDocument)
 		asynqWorker.RegisterHandler(rag.TypeEmbedDocument, docTaskHandler.HandleEmbedDocument)
-		log.Printf("Asynq RAG Worker handlers registered (Gemini embedding enabled: %v)", cfg.AI.GeminiAPIKey != "")
+		log.Printf("Asynq RAG Worker handlers registered (embedding enabled: %v)", embeddingIndexes != nil)
+	}
+	if embeddingIndexes != nil {
+		asynqWorker.RegisterHandler(rag.TypeBuildEmbeddingIndex, embeddingIndexes.HandleBuildEmbeddingIndex)
 	}
 
 	// Register Swarm task handlers
-	swarmTaskHandler := swarm.NewSwarmTaskHandler(swarmRepo, bcService)
+	swarmTaskHandler := swarm.NewSwarmTaskHandler(swarmRepo, anchorBatchRepo, ledgerCorrectionRepo, ledgers, asynqClient)
 	asynqWorker.RegisterHandler(swarm.TypeCommitSwarmToBlockchain, swarmTaskHandler.HandleCommitSwarmToBlockchain)
+	asynqWorker.RegisterHandler(swarm.TypeCorrectSwarmOnBlockchain, swarmTaskHandler.HandleCorrectSwarmOnBlockchain)
+	asynqWorker.RegisterHandler(swarm.TypeSealAnchorBatch, swarmTaskHandler.HandleSealAnchorBatch)
+	asynqWorker.RegisterHandler(swarm.TypeCommitAnchorBatch, swarmTaskHandler.HandleCommitAnchorBatch)
+	asynqWorker.RegisterHandler(swarm.TypeSweepAnchorBatches, swarmTaskHandler.HandleSweepAnchorBatches)
+	asynqWorker.RegisterHandler(swarm.TypeLedgerCorrection, swarmTaskHandler.HandleLedgerCorrection)
 	log.Printf("Asynq Swarm Worker handlers registered")
 
 	// Start the background Asynq worker process
@@ -277,23 +354,70 @@ func main() {
 		}
 	}()
 
-	swarmUsecase := swarm.NewSwarmUsecase(swarmRepo, redisCache, bcService, asynqClient)
+	// Merkle-batched anchoring (optional): seal pending tasks on a fixed interval
+	if ledgers != nil && cfg.Blockchain.Batch.Enabled {
+		batcher, err := swarm.StartAnchorBatcher(cfg.Blockchain.Batch, asynqClient)
+		if err != nil {
+			log.Printf("[WARN] Anchor batcher failed to start: %v", err)
+		} else {
+			defer batcher.Shutdown()
+		}
+	}
+
+	// Chain indexers: follow AuditTrail events and reconcile task statuses, per ledger profile
+	chainEventRepo := postgresRepo.NewChainEventRepository(db)
+	if cfg.Blockchain.Indexer.Enabled {
+		for _, profile := range ledgers.Profiles() {
+			ledger, _ := ledgers.Get(profile)
+			source, _ := ledger.(blockchain.EventSource)
+			indexer := swarm.NewChainIndexer(profile, source, ledger, chainEventRepo, swarmRepo, anchorBatchRepo, cfg.Blockchain.Indexer)
+			indexerScheduler, err := swarm.StartChainIndexer(indexer, cfg.Blockchain.Indexer)
+			if err != nil {
+				log.Printf("[WARN] Chain indexer failed to start for ledger profile %s: %v", profile, err)
+			} else {
+				defer indexerScheduler.Shutdown()
+			}
+		}
+	}
+
+	// Audit log anchoring: hash-chains each tenant's enterprise audit log per period
+	auditAnchorer := auditlog.NewAnchorer(auditRepo, postgresRepo.NewAuditAnchorRepository(db), ledgers, cfg.Blockchain.AuditAnchor)
+	if ledgers != nil && cfg.Blockchain.AuditAnchor.Enabled {
+		anchorScheduler, err := auditlog.StartAnchorer(auditAnchorer, cfg.Blockchain.AuditAnchor)
+		if err != nil {
+			log.Printf("[WARN] Audit log anchorer failed to start: %v", err)
+		} else {
+			defer anchorScheduler.Shutdown()
+		}
+	}
+	auditLogHandler := handler.NewAuditLogHandler(auditAnchorer)
+
+	var logoStore report.LogoStore
+	if s3Service != nil {
+		logoStore = s3Service
+	}
+	swarmUsecase := swarm.NewSwarmUsecase(swarmRepo, swarmFindingRepo, anchorBatchRepo, ledgerCorrectionRepo, redisCache, ledgers, asynqClient, cfg.Blockchain.Batch, logoStore)
 	swarmHandler := handler.NewSwarmHandler(swarmUsecase, redisCache)
-	blockchainHandler := handler.NewBlockchainHandler(swarmRepo, bcService)
+	blockchainHandler := handler.NewBlockchainHandler(swarmRepo, anchorBatchRepo, ledgers)
 
 	// Dashboard, Chat & Agent Components
 	dashboardUseCase := dashboard.NewDashboardUseCase(db)
 	dashboardHandler := handler.NewDashboardHandler(dashboardUseCase)
 
 	chatRepo := postgresRepo.NewChatRepository(db)
-	chatHandler := handler.NewChatHandler(chatRepo, docRepo, cfg.AI.GeminiAPIKey)
+	chatHandler := handler.NewChatHandler(chatRepo, docRepo, cfg.AI.GeminiAPIKey, tenantEmbedders, queryExpander)
 
 	agentRepo := postgresRepo.NewAgentRepository(db)
 	agentHandler := handler.NewAgentHandler(agentRepo)
 
-	tenantHandler := handler.NewTenantHandler(db)
+	tenantHandler := handler.NewTenantHandler(db, cfg.Blockchain.ProfileNames())
 	dataTypeHandler := handler.NewDataTypeHandler(db)
 
+	// Reference Price Catalog (SHSR) + markup guardrail
+	referencePriceRepo := postgresRepo.NewReferencePriceRepository(db)
+	referencePriceUsecase := pricing.NewReferencePriceUsecase(referencePriceRepo)
+	referencePriceHandler := handler.NewReferencePriceHandler(referencePriceUsecase, engineHandlers.NewGuardrailVerifier(referencePriceRepo))
+
 	routes.SetupRoutes(
 		router,
 		healthHandler,
@@ -303,6 +427,7 @@ func main() {
 		executionHandler,
 		documentHandler,
 		ragSearchHandler,
+		embeddingIndexHandler,
 		swarmHandler,
 		dashboardHandler,
 		chatHandler,
@@ -310,7 +435,10 @@ func main() {
 		tenantHandler,
 		dataTypeHandler,
 		blockchainHandler,
+		auditLogHandler,
+		referencePriceHandler,
 		authMiddleware,
+		middleware.RateLimit(redisCache, cacheKeyBuilder, "public", cfg.Security.PublicRateLimitPerMinute, cfg.Security.RateLimitBurst),
 	)
 
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
@@ -0,0 +1,118 @@
+// Command verify_proof checks a proof bundle downloaded from
+// /api/v1/public/proof/{task_id} without access to the Elysian backend.
+//
+// The offline checks recompute every hash in the bundle. With -rpc (or
+// -ledger-file for deployments on the local ledger) the anchor is also
+// checked against the ledger itself:
+//
+//	verify_proof -rpc https://rpc.example.org proof-<task>.json
+//	verify_proof -ledger-file audit.jsonl -submitter 0xABC... proof-<task>.json
+//	verify_proof -tsa-ca tsa-ca.pem proof-<task>.json
+//
+// A local ledger file is opened read-only and only trusted if it is signed
+// by the -submitter addresses, which must come from the deployment operator,
+// not from the file itself. Bundles anchored with the tsa backend carry
+// their timestamp token; -tsa-ca checks that it was issued by the TSA.
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+)
+
+func main() {
+	rpcURL := flag.String("rpc", "", "JSON-RPC endpoint of the bundle's network; enables the on-chain check")
+	ledgerFile := flag.String("ledger-file", "", "path to a local ledger log; enables the on-chain check against it")
+	submitters := flag.String("submitter", "", "comma-separated submitter addresses the local ledger must be signed by (required with -ledger-file)")
+	tsaCA := flag.String("tsa-ca", "", "PEM CA certificates of the TSA; checks the timestamp token of a tsa-anchored bundle")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for the on-chain check")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-rpc URL | -ledger-file PATH -submitter ADDR | -tsa-ca PEM] bundle.json\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || (*rpcURL != "" && *ledgerFile != "") || (*ledgerFile != "" && *submitters == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	raw, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fail("read bundle: %v", err)
+	}
+	var bundle blockchain.ProofBundle
+	if err := json.Unmarshal(raw, &bundle); err != nil {
+		fail("decode bundle: %v", err)
+	}
+
+	if err := bundle.VerifyOffline(); err != nil {
+		fail("offline check failed: %v", err)
+	}
+	fmt.Printf("offline:  OK  task %s, consensus hash %s\n", bundle.TaskID, bundle.ConsensusHash)
+	if bundle.Batch != nil {
+		fmt.Printf("          included in batch %s (leaf %d of %d)\n", bundle.Batch.BatchID, bundle.Batch.LeafIndex, bundle.Batch.LeafCount)
+	}
+
+	if bundle.Timestamp != nil {
+		if *tsaCA == "" {
+			fmt.Println("timestamp: skipped (pass -tsa-ca to check the token's issuer)")
+			return
+		}
+		pem, err := os.ReadFile(*tsaCA)
+		if err != nil {
+			fail("read TSA CA: %v", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			fail("no certificates found in %s", *tsaCA)
+		}
+		genTime, err := bundle.VerifyTimestamp(roots)
+		if err != nil {
+			fail("timestamp check failed: %v", err)
+		}
+		fmt.Printf("timestamp: OK  log %s stamped by %s at %s\n", bundle.Anchor.LogID, bundle.Timestamp.TSA, genTime.UTC().Format(time.RFC3339))
+		return
+	}
+
+	var ledger blockchain.Ledger
+	switch {
+	case *rpcURL != "":
+		ledger, err = blockchain.NewAuditTrailService(*rpcURL, bundle.Anchor.Contract, "", bundle.Anchor.Network)
+	case *ledgerFile != "":
+		ledger, err = blockchain.OpenLocalLedgerReadOnly(*ledgerFile, strings.Split(*submitters, ","))
+	default:
+		fmt.Println("on-chain: skipped (pass -rpc or -ledger-file to check the anchor)")
+		return
+	}
+	if err != nil {
+		fail("open ledger: %v", err)
+	}
+	defer ledger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := bundle.VerifyOnChain(ctx, ledger); err != nil {
+		fail("on-chain check failed: %v", err)
+	}
+	fmt.Printf("on-chain: OK  log %s on %s", bundle.Anchor.LogID, bundle.Anchor.Network)
+	if bundle.Anchor.TxHash != "" {
+		fmt.Printf(", tx %s", bundle.Anchor.TxHash)
+	}
+	if bundle.Anchor.BlockNumber != 0 {
+		fmt.Printf(", block %d", bundle.Anchor.BlockNumber)
+	}
+	fmt.Println()
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "FAIL: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -1,10 +1,204 @@
 package config
 
+import (
+	"sort"
+	"time"
+)
+
+// Ledger backends selectable through BlockchainConfig.Backend
+const (
+	LedgerBackendEVM       = "evm"       // JSON-RPC endpoint (e.g. Sepolia)
+	LedgerBackendSimulated = "simulated" // in-process go-ethereum chain
+	LedgerBackendLocal     = "local"     // signed append-only log file
+	LedgerBackendTSA       = "tsa"       // RFC 3161 timestamp tokens
+)
+
 // BlockchainConfig holds blockchain connection settings
 type BlockchainConfig struct {
 	Enabled      bool   `mapstructure:"enabled"`
+	Backend      string `mapstructure:"backend"` // evm (default), simulated, local or tsa
 	RPCURL       string `mapstructure:"rpc_url"`
 	ContractAddr string `mapstructure:"contract_addr"`
-	PrivateKey   string `mapstructure:"private_key"`
-	Network      string `mapstructure:"network"`
+	// PrivateKey is a raw hex signing key, ignored when Keystore has files.
+	// Prefer Keystore outside development.
+	PrivateKey string         `mapstructure:"private_key"`
+	Keystore   KeystoreConfig `mapstructure:"keystore"`
+	// CheckSubmitters verifies at startup that every signing key is an
+	// authorized submitter on the contract (evm backend only).
+	CheckSubmitters bool   `mapstructure:"check_submitters"`
+	Network         string `mapstructure:"network"`
+	// ArtifactPath is the compiled AuditTrail contract (Hardhat or Foundry
+	// JSON artifact) deployed by the simulated backend. Empty deploys the
+	// embedded contract.
+	ArtifactPath string `mapstructure:"artifact_path"`
+	// LocalLogPath is the log file written by the local backend.
+	LocalLogPath string `mapstructure:"local_log_path"`
+	// LocalSubmitters are addresses, besides the signing key's, whose
+	// entries the local log accepts (e.g. keys rotated out).
+	LocalSubmitters []string `mapstructure:"local_submitters"`
+	// TSA is the time-stamping authority used by the tsa backend.
+	TSA   TSAConfig         `mapstructure:"tsa"`
+	Batch AnchorBatchConfig `mapstructure:"batch"`
+	// NonceStore serializes nonce allocation: "redis" (default, safe across
+	// replicas) or "memory" (single process only).
+	NonceStore string `mapstructure:"nonce_store"`
+	// MaxFeeGwei caps maxFeePerGas of every transaction, including fee
+	// bumps. 0 means no cap.
+	MaxFeeGwei int64         `mapstructure:"max_fee_gwei"`
+	FeeBump    FeeBumpConfig `mapstructure:"fee_bump"`
+	Indexer    IndexerConfig `mapstructure:"indexer"`
+	// AuditAnchor anchors the enterprise audit log itself.
+	AuditAnchor AuditAnchorConfig `mapstructure:"audit_anchor"`
+	// Profiles are additional named ledgers a tenant can be assigned to
+	// through its ledger_profile setting. Tenants without one use the
+	// settings above, the default profile.
+	Profiles map[string]LedgerProfile `mapstructure:"profiles"`
+}
+
+// DefaultLedgerProfile names the ledger described by the top-level
+// BlockchainConfig fields. An empty profile name means the same.
+const DefaultLedgerProfile = "default"
+
+// LedgerProfile is a named ledger. Fields left empty inherit the top-level
+// BlockchainConfig value; signing keys, nonce store, fees, batching and the
+// indexer settings are shared by all profiles.
+type LedgerProfile struct {
+	Backend      string    `mapstructure:"backend"`
+	RPCURL       string    `mapstructure:"rpc_url"`
+	ContractAddr string    `mapstructure:"contract_addr"`
+	Network      string    `mapstructure:"network"`
+	ArtifactPath string    `mapstructure:"artifact_path"`
+	LocalLogPath string    `mapstructure:"local_log_path"`
+	TSA          TSAConfig `mapstructure:"tsa"`
+}
+
+// ProfileNames returns the default profile followed by the configured
+// profiles, sorted.
+func (c BlockchainConfig) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultLedgerProfile}, names...)
+}
+
+// Profile returns the connection settings of the named ledger profile, the
+// top-level settings for the default one.
+func (c BlockchainConfig) Profile(name string) (BlockchainConfig, bool) {
+	if name == "" || name == DefaultLedgerProfile {
+		return c, true
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return BlockchainConfig{}, false
+	}
+	out := c
+	out.Profiles = nil
+	if p.Backend != "" {
+		out.Backend = p.Backend
+	}
+	if p.RPCURL != "" {
+		out.RPCURL = p.RPCURL
+	}
+	if p.ContractAddr != "" {
+		out.ContractAddr = p.ContractAddr
+	}
+	if p.Network != "" {
+		out.Network = p.Network
+	}
+	if p.ArtifactPath != "" {
+		out.ArtifactPath = p.ArtifactPath
+	}
+	if p.LocalLogPath != "" {
+		out.LocalLogPath = p.LocalLogPath
+	}
+	if p.TSA.URL != "" {
+		out.TSA.URL = p.TSA.URL
+		out.TSA.CACertPath = p.TSA.CACertPath
+	}
+	if p.TSA.Timeout > 0 {
+		out.TSA.Timeout = p.TSA.Timeout
+	}
+	return out, true
+}
+
+// KeystoreConfig loads the submitter keys from encrypted go-ethereum (V3)
+// keystore files. With several files, transactions are signed round-robin,
+// the first key acting as primary (contract owner) for authorizeSubmitter.
+type KeystoreConfig struct {
+	Files []string `mapstructure:"files"`
+	// PassphraseFile holds the passphrase shared by all files, typically a
+	// mounted secret.
+	PassphraseFile string `mapstructure:"passphrase_file"`
+}
+
+// TSAConfig configures the tsa backend, which anchors hashes with RFC 3161
+// timestamp tokens instead of blockchain transactions.
+type TSAConfig struct {
+	URL string `mapstructure:"url"`
+	// CACertPath is a PEM bundle the TSA certificate must chain to. It is
+	// required: a token's own certificate proves nothing about who issued it.
+	CACertPath string        `mapstructure:"ca_cert_path"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// IndexerConfig controls the AuditTrail event indexer and the reconciler
+// that settles task and batch statuses from indexed events.
+type IndexerConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	// Confirmations is the depth at which an event is considered final.
+	Confirmations int `mapstructure:"confirmations"`
+	// StartBlock is where a fresh index starts; 0 starts MaxRange blocks
+	// behind the head.
+	StartBlock uint64 `mapstructure:"start_block"`
+	// MaxRange bounds the blocks scanned per eth_getLogs call.
+	MaxRange uint64 `mapstructure:"max_range"`
+	// StaleAfter is how long a transaction may stay PENDING_CONFIRMATION
+	// before the reconciler looks up its receipt directly.
+	StaleAfter time.Duration `mapstructure:"stale_after"`
+}
+
+// AuditAnchorConfig controls the periodic job that hash-chains each
+// tenant's enterprise audit log entries and anchors the chain head once per
+// Period.
+type AuditAnchorConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Period is the span of entries covered by one anchor. Periods are
+	// aligned with time.Truncate, so the 24h default runs from UTC midnight.
+	Period time.Duration `mapstructure:"period"`
+	// Interval is how often closed periods are looked for.
+	Interval time.Duration `mapstructure:"interval"`
+	// SettleDelay is how long after a period ends it is anchored, so entries
+	// written asynchronously by the audit interceptor have landed.
+	SettleDelay time.Duration `mapstructure:"settle_delay"`
+}
+
+// Nonce stores selectable through BlockchainConfig.NonceStore
+const (
+	NonceStoreRedis  = "redis"
+	NonceStoreMemory = "memory"
+)
+
+// FeeBumpConfig controls replacement of transactions that stay pending for
+// longer than StuckAfter: they are re-sent with the same nonce and fees
+// raised by Percent (at least 10%, the node's replacement minimum).
+type FeeBumpConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Interval   time.Duration `mapstructure:"interval"`
+	StuckAfter time.Duration `mapstructure:"stuck_after"`
+	Percent    int           `mapstructure:"percent"`
+}
+
+// AnchorBatchConfig enables Merkle-batched anchoring: completed tasks are
+// collected for up to Interval (or until MaxSize are pending) and only the
+// batch's Merkle root is written to the ledger.
+type AnchorBatchConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	MaxSize  int           `mapstructure:"max_size"`
+	// CommitStaleAfter is how long a sealed batch may stay PENDING_COMMIT
+	// without a transaction before its commit is enqueued again.
+	CommitStaleAfter time.Duration `mapstructure:"commit_stale_after"`
 }
@@ -0,0 +1,16 @@
+package config
+
+// ChunkingConfig is the default chunking of documents; tenants override it
+// in their settings, per document category too.
+type ChunkingConfig struct {
+	Strategy string `mapstructure:"strategy"` // markdown, token or sentence_window
+	// MaxSize is in estimated tokens for the token strategy, characters
+	// otherwise; 0 takes the strategy default (256 tokens, 1000 characters).
+	MaxSize int `mapstructure:"max_size"`
+	// Overlap repeats the end of a chunk at the start of the next one: in
+	// sentences for sentence_window (default 1), in the unit of MaxSize otherwise.
+	Overlap int `mapstructure:"overlap"`
+	// KeepTables emits tables whole instead of splitting those larger than
+	// MaxSize into row groups that repeat the header row.
+	KeepTables bool `mapstructure:"keep_tables"`
+}
@@ -30,6 +30,14 @@ type AIConfig struct {
 	// Document parsing pipeline (enterprise PDF extraction)
 	DoclingURL      string `mapstructure:"docling_url"`      // e.g. http://localhost:5001
 	UnstructuredURL string `mapstructure:"unstructured_url"` // fallback: http://localhost:8000
+	// Embedding model shared by document ingestion and retrieval
+	Embedding EmbeddingConfig `mapstructure:"embedding"`
+	// Optional reranker applied after hybrid search fusion
+	Rerank RerankConfig `mapstructure:"rerank"`
+	// Optional query rewriting for multi-query retrieval
+	QueryExpansion QueryExpansionConfig `mapstructure:"query_expansion"`
+	// Default chunking of ingested documents
+	Chunking ChunkingConfig `mapstructure:"chunking"`
 }
 
 type ServerConfig struct {
@@ -97,10 +105,14 @@ type MLConfig struct {
 type SecurityConfig struct {
 	RateLimitRequestsPerMinute int      `mapstructure:"rate_limit_requests_per_minute" validate:"min=1"`
 	RateLimitBurst             int      `mapstructure:"rate_limit_burst" validate:"min=1"`
+	PublicRateLimitPerMinute   int      `mapstructure:"public_rate_limit_per_minute" validate:"min=1"`
 	CORSAllowedOrigins         []string `mapstructure:"cors_allowed_origins"`
 	CORSAllowedMethods         []string `mapstructure:"cors_allowed_methods"`
 	CORSAllowedHeaders         []string `mapstructure:"cors_allowed_headers"`
 	CORSAllowCredentials       bool     `mapstructure:"cors_allow_credentials"`
+	// TrustedProxies may set X-Forwarded-For; empty trusts none, so the
+	// client IP is the connection's address.
+	TrustedProxies             []string `mapstructure:"trusted_proxies"`
 }
 
 type LoggingConfig struct {
@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+// Embedding backends selectable through EmbeddingConfig.Backend
+const (
+	EmbeddingBackendGemini = "gemini" // Google text-embedding models
+	EmbeddingBackendOpenAI = "openai" // any OpenAI-compatible /embeddings endpoint
+	EmbeddingBackendHash   = "hash"   // deterministic feature hashing, offline
+)
+
+// EmbeddingConfig selects the model that embeds document chunks and search
+// queries. Model and Dimension default per backend when left empty.
+type EmbeddingConfig struct {
+	Backend   string `mapstructure:"backend"` // gemini (default), openai or hash
+	Model     string `mapstructure:"model"`
+	Dimension int    `mapstructure:"dimension"`
+	// URL is the base URL of the openai backend, e.g. http://localhost:8080/v1
+	// for a local embedding server.
+	URL string `mapstructure:"url"`
+	// APIKey is sent as a bearer token by the openai backend. Empty falls
+	// back to AIConfig.OpenAIAPIKey.
+	APIKey    string        `mapstructure:"api_key"`
+	BatchSize int           `mapstructure:"batch_size"` // texts per request
+	Timeout   time.Duration `mapstructure:"timeout"`
+	// Additional embedders a tenant's corpus can be re-embedded with (see
+	// the embedding index migration). New corpora use the settings above.
+	Additional []EmbeddingConfig `mapstructure:"additional"`
+}
@@ -54,6 +54,35 @@ func Load() (*Config, error) {
 	v.SetDefault("database.conn_max_idle_time", "5m")
 	v.SetDefault("mongodb.uri", "mongodb://localhost:27017")
 	v.SetDefault("mongodb.db", "elysian_staging")
+	v.SetDefault("security.public_rate_limit_per_minute", 30)
+	v.SetDefault("ai.embedding.backend", EmbeddingBackendGemini)
+	v.SetDefault("ai.embedding.batch_size", 100)
+	v.SetDefault("ai.embedding.timeout", "60s")
+	v.SetDefault("ai.rerank.timeout", "30s")
+	v.SetDefault("ai.query_expansion.max_queries", 3)
+	v.SetDefault("ai.query_expansion.timeout", "20s")
+	v.SetDefault("ai.chunking.strategy", "markdown")
+	v.SetDefault("blockchain.backend", LedgerBackendEVM)
+	v.SetDefault("blockchain.local_log_path", "data/ledger/audit_trail.jsonl")
+	v.SetDefault("blockchain.tsa.timeout", "30s")
+	v.SetDefault("blockchain.batch.interval", "5m")
+	v.SetDefault("blockchain.batch.max_size", 100)
+	v.SetDefault("blockchain.batch.commit_stale_after", "15m")
+	v.SetDefault("blockchain.nonce_store", NonceStoreRedis)
+	v.SetDefault("blockchain.check_submitters", true)
+	v.SetDefault("blockchain.fee_bump.enabled", true)
+	v.SetDefault("blockchain.fee_bump.interval", "1m")
+	v.SetDefault("blockchain.fee_bump.stuck_after", "3m")
+	v.SetDefault("blockchain.fee_bump.percent", 25)
+	v.SetDefault("blockchain.indexer.enabled", true)
+	v.SetDefault("blockchain.indexer.interval", "15s")
+	v.SetDefault("blockchain.indexer.confirmations", 12)
+	v.SetDefault("blockchain.indexer.max_range", 2000)
+	v.SetDefault("blockchain.indexer.stale_after", "10m")
+	v.SetDefault("blockchain.audit_anchor.enabled", true)
+	v.SetDefault("blockchain.audit_anchor.period", "24h")
+	v.SetDefault("blockchain.audit_anchor.interval", "1h")
+	v.SetDefault("blockchain.audit_anchor.settle_delay", "10m")
 
 	// read default config
 	if err := v.ReadInConfig(); err != nil {
@@ -136,6 +165,15 @@ func overrideWithEnv(cfg *Config) {
 	if v := os.Getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
 		cfg.Security.CORSAllowCredentials = v == "true"
 	}
+	// TRUSTED_PROXIES is comma-separated IPs or CIDRs of the load balancers in front of the server
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		cfg.Security.TrustedProxies = strings.Split(v, ",")
+	}
+	if v := os.Getenv("PUBLIC_RATE_LIMIT_PER_MINUTE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Security.PublicRateLimitPerMinute = n
+		}
+	}
 
 	// DATABASE_URL takes priority — Railway Postgres provides this automatically
 	if v := os.Getenv("DATABASE_URL"); v != "" {
@@ -265,6 +303,112 @@ func overrideWithEnv(cfg *Config) {
 	if v := os.Getenv("AI_GEMINI_API_KEY"); v != "" {
 		cfg.AI.GeminiAPIKey = v
 	}
+	if v := os.Getenv("AI_EMBEDDING_BACKEND"); v != "" {
+		cfg.AI.Embedding.Backend = v
+	}
+	if v := os.Getenv("AI_EMBEDDING_MODEL"); v != "" {
+		cfg.AI.Embedding.Model = v
+	}
+	if v := os.Getenv("AI_EMBEDDING_DIMENSION"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.AI.Embedding.Dimension = n
+		}
+	}
+	if v := os.Getenv("AI_EMBEDDING_URL"); v != "" {
+		cfg.AI.Embedding.URL = v
+	}
+	if v := os.Getenv("AI_EMBEDDING_API_KEY"); v != "" {
+		cfg.AI.Embedding.APIKey = v
+	}
+	if v := os.Getenv("AI_RERANK_BACKEND"); v != "" {
+		cfg.AI.Rerank.Backend = v
+	}
+	if v := os.Getenv("AI_RERANK_MODEL"); v != "" {
+		cfg.AI.Rerank.Model = v
+	}
+	if v := os.Getenv("AI_RERANK_URL"); v != "" {
+		cfg.AI.Rerank.URL = v
+	}
+	if v := os.Getenv("AI_RERANK_API_KEY"); v != "" {
+		cfg.AI.Rerank.APIKey = v
+	}
+	if v := os.Getenv("AI_QUERY_EXPANSION_BACKEND"); v != "" {
+		cfg.AI.QueryExpansion.Backend = v
+	}
+	if v := os.Getenv("AI_QUERY_EXPANSION_MODEL"); v != "" {
+		cfg.AI.QueryExpansion.Model = v
+	}
+	if v := os.Getenv("AI_CHUNKING_STRATEGY"); v != "" {
+		cfg.AI.Chunking.Strategy = v
+	}
+	if v := os.Getenv("AI_CHUNKING_MAX_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.AI.Chunking.MaxSize = n
+		}
+	}
+
+	// Blockchain
+	if v := os.Getenv("BLOCKCHAIN_BACKEND"); v != "" {
+		cfg.Blockchain.Backend = v
+	}
+	if v := os.Getenv("BLOCKCHAIN_ARTIFACT_PATH"); v != "" {
+		cfg.Blockchain.ArtifactPath = v
+	}
+	if v := os.Getenv("BLOCKCHAIN_LOCAL_LOG_PATH"); v != "" {
+		cfg.Blockchain.LocalLogPath = v
+	}
+	if v := os.Getenv("BLOCKCHAIN_LOCAL_SUBMITTERS"); v != "" {
+		cfg.Blockchain.LocalSubmitters = strings.Split(v, ",")
+	}
+	if v := os.Getenv("BLOCKCHAIN_TSA_URL"); v != "" {
+		cfg.Blockchain.TSA.URL = v
+	}
+	if v := os.Getenv("BLOCKCHAIN_TSA_CA_CERT_PATH"); v != "" {
+		cfg.Blockchain.TSA.CACertPath = v
+	}
+	if v := os.Getenv("BLOCKCHAIN_BATCH_ENABLED"); v != "" {
+		cfg.Blockchain.Batch.Enabled = v == "true"
+	}
+	if v := os.Getenv("BLOCKCHAIN_BATCH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Blockchain.Batch.Interval = d
+		}
+	}
+	if v := os.Getenv("BLOCKCHAIN_BATCH_MAX_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Blockchain.Batch.MaxSize = n
+		}
+	}
+	if v := os.Getenv("BLOCKCHAIN_BATCH_COMMIT_STALE_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Blockchain.Batch.CommitStaleAfter = d
+		}
+	}
+	if v := os.Getenv("BLOCKCHAIN_KEYSTORE_FILES"); v != "" {
+		cfg.Blockchain.Keystore.Files = strings.Split(v, ",")
+	}
+	if v := os.Getenv("BLOCKCHAIN_KEYSTORE_PASSPHRASE_FILE"); v != "" {
+		cfg.Blockchain.Keystore.PassphraseFile = v
+	}
+	if v := os.Getenv("BLOCKCHAIN_CHECK_SUBMITTERS"); v != "" {
+		cfg.Blockchain.CheckSubmitters = v == "true"
+	}
+	if v := os.Getenv("BLOCKCHAIN_AUDIT_ANCHOR_ENABLED"); v != "" {
+		cfg.Blockchain.AuditAnchor.Enabled = v == "true"
+	}
+	if v := os.Getenv("BLOCKCHAIN_AUDIT_ANCHOR_PERIOD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Blockchain.AuditAnchor.Period = d
+		}
+	}
+	if v := os.Getenv("BLOCKCHAIN_NONCE_STORE"); v != "" {
+		cfg.Blockchain.NonceStore = v
+	}
+	if v := os.Getenv("BLOCKCHAIN_MAX_FEE_GWEI"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.Blockchain.MaxFeeGwei = n
+		}
+	}
 }
 
 // MaskSensitive returns a copy of the config with sensitive values masked
@@ -276,6 +420,7 @@ func (c *Config) MaskSensitive() *Config {
 	masked.Storage.AccessKey = "***MASKED***"
 	masked.Storage.SecretKey = "***MASKED***"
 	masked.MongoDB.URI = "***MASKED***"
+	masked.Blockchain.PrivateKey = "***MASKED***"
 	return &masked
 }
 
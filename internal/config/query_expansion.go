@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// Query expansion backends selectable through QueryExpansionConfig.Backend
+const (
+	QueryExpansionBackendLLM = "llm" // Gemini writes the reformulations
+)
+
+// QueryExpansionConfig selects the model that rewrites a search query into
+// several reformulations for multi-query retrieval. An empty Backend
+// disables expansion.
+type QueryExpansionConfig struct {
+	Backend string `mapstructure:"backend"` // llm or empty (disabled)
+	Model   string `mapstructure:"model"`   // Gemini model, default gemini-2.5-flash
+	// MaxQueries is the number of reformulations generated when a request
+	// does not say (the original query is always searched too).
+	MaxQueries int           `mapstructure:"max_queries"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
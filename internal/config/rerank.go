@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// Rerank backends selectable through RerankConfig.Backend
+const (
+	RerankBackendLLM  = "llm"  // Gemini scores the candidates in one prompt
+	RerankBackendHTTP = "http" // Cohere/Jina-compatible POST {url}/rerank
+)
+
+// RerankConfig selects the reranker that rescores fused hybrid search
+// results. An empty Backend disables reranking.
+type RerankConfig struct {
+	Backend string `mapstructure:"backend"` // llm, http or empty (disabled)
+	// Model is the Gemini model of the llm backend (default
+	// gemini-2.5-flash) and the model sent to the http backend.
+	Model string `mapstructure:"model"`
+	// URL is the base URL of the http backend, e.g. http://localhost:7997
+	// for a local reranker server.
+	URL string `mapstructure:"url"`
+	// APIKey is sent as a bearer token by the http backend.
+	APIKey  string        `mapstructure:"api_key"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
@@ -41,5 +41,112 @@ func validateCustomRules(cfg *Config) error {
 			cfg.Database.MaxOpenConns, cfg.Database.MaxIdleConns)
 	}
 
+	// Validate embedding backends
+	if err := validateEmbedding(cfg.AI.Embedding, "ai embedding"); err != nil {
+		return err
+	}
+	for i, additional := range cfg.AI.Embedding.Additional {
+		if err := validateEmbedding(additional, fmt.Sprintf("ai embedding additional[%d]", i)); err != nil {
+			return err
+		}
+	}
+
+	// Validate rerank backend
+	switch cfg.AI.Rerank.Backend {
+	case "", RerankBackendLLM:
+	case RerankBackendHTTP:
+		if cfg.AI.Rerank.URL == "" {
+			return fmt.Errorf("ai rerank url is required for the http backend")
+		}
+	default:
+		return fmt.Errorf("invalid ai rerank backend '%s', must be one of: llm, http", cfg.AI.Rerank.Backend)
+	}
+
+	// Validate query expansion backend
+	switch cfg.AI.QueryExpansion.Backend {
+	case "", QueryExpansionBackendLLM:
+	default:
+		return fmt.Errorf("invalid ai query_expansion backend '%s', must be: llm", cfg.AI.QueryExpansion.Backend)
+	}
+	if cfg.AI.QueryExpansion.MaxQueries < 0 {
+		return fmt.Errorf("ai query_expansion max_queries must not be negative")
+	}
+
+	// Validate chunking defaults
+	switch cfg.AI.Chunking.Strategy {
+	case "", "markdown", "token", "sentence_window":
+	default:
+		return fmt.Errorf("invalid ai chunking strategy '%s', must be one of: markdown, token, sentence_window", cfg.AI.Chunking.Strategy)
+	}
+	if cfg.AI.Chunking.MaxSize < 0 || cfg.AI.Chunking.Overlap < 0 {
+		return fmt.Errorf("ai chunking max_size and overlap must not be negative")
+	}
+
+	if cfg.Blockchain.Enabled {
+		if err := validateLedgerBackend(cfg.Blockchain, "blockchain"); err != nil {
+			return err
+		}
+		for name := range cfg.Blockchain.Profiles {
+			if name == "" || name == DefaultLedgerProfile {
+				return fmt.Errorf("blockchain profile name '%s' is reserved for the top-level settings", name)
+			}
+			profile, _ := cfg.Blockchain.Profile(name)
+			if err := validateLedgerBackend(profile, "blockchain profile '"+name+"'"); err != nil {
+				return err
+			}
+		}
+		if cfg.Blockchain.Batch.Enabled && (cfg.Blockchain.Batch.Interval <= 0 || cfg.Blockchain.Batch.MaxSize < 1) {
+			return fmt.Errorf("blockchain batch interval must be positive and max_size at least 1")
+		}
+		if len(cfg.Blockchain.Keystore.Files) > 0 && cfg.Blockchain.Keystore.PassphraseFile == "" {
+			return fmt.Errorf("blockchain keystore.passphrase_file is required with keystore.files")
+		}
+		switch cfg.Blockchain.NonceStore {
+		case "", NonceStoreRedis, NonceStoreMemory:
+		default:
+			return fmt.Errorf("invalid blockchain nonce_store '%s', must be one of: redis, memory", cfg.Blockchain.NonceStore)
+		}
+		if cfg.Blockchain.FeeBump.Enabled && (cfg.Blockchain.FeeBump.Interval <= 0 || cfg.Blockchain.FeeBump.StuckAfter <= 0 || cfg.Blockchain.FeeBump.Percent < 10) {
+			return fmt.Errorf("blockchain fee_bump needs positive interval and stuck_after and percent of at least 10")
+		}
+		if cfg.Blockchain.Indexer.Enabled && (cfg.Blockchain.Indexer.Interval <= 0 || cfg.Blockchain.Indexer.Confirmations < 1 || cfg.Blockchain.Indexer.MaxRange < 1 || cfg.Blockchain.Indexer.StaleAfter <= 0) {
+			return fmt.Errorf("blockchain indexer needs positive interval, stale_after, max_range and at least 1 confirmation")
+		}
+		if cfg.Blockchain.AuditAnchor.Enabled && (cfg.Blockchain.AuditAnchor.Period <= 0 || cfg.Blockchain.AuditAnchor.Interval <= 0 || cfg.Blockchain.AuditAnchor.SettleDelay < 0) {
+			return fmt.Errorf("blockchain audit_anchor needs positive period and interval and a non-negative settle_delay")
+		}
+	}
+
+	return nil
+}
+
+// validateEmbedding checks the backend of one embedder.
+func validateEmbedding(cfg EmbeddingConfig, name string) error {
+	switch cfg.Backend {
+	case "", EmbeddingBackendGemini, EmbeddingBackendHash:
+	case EmbeddingBackendOpenAI:
+		if cfg.URL == "" || cfg.Model == "" || cfg.Dimension <= 0 {
+			return fmt.Errorf("%s url, model and dimension are required for the openai backend", name)
+		}
+	default:
+		return fmt.Errorf("invalid %s backend '%s', must be one of: gemini, openai, hash", name, cfg.Backend)
+	}
+	if cfg.Dimension < 0 || cfg.BatchSize < 0 {
+		return fmt.Errorf("%s dimension and batch_size must not be negative", name)
+	}
+	return nil
+}
+
+// validateLedgerBackend checks the backend of one ledger profile.
+func validateLedgerBackend(cfg BlockchainConfig, name string) error {
+	switch cfg.Backend {
+	case "", LedgerBackendEVM, LedgerBackendSimulated, LedgerBackendLocal:
+	case LedgerBackendTSA:
+		if cfg.TSA.URL == "" || cfg.TSA.CACertPath == "" {
+			return fmt.Errorf("%s tsa.url and tsa.ca_cert_path are required for the tsa backend", name)
+		}
+	default:
+		return fmt.Errorf("invalid %s backend '%s', must be one of: evm, simulated, local, tsa", name, cfg.Backend)
+	}
 	return nil
 }
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auditlog"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditLogHandler struct {
+	anchorer *auditlog.Anchorer
+}
+
+func NewAuditLogHandler(anchorer *auditlog.Anchorer) *AuditLogHandler {
+	return &AuditLogHandler{anchorer: anchorer}
+}
+
+// ListAnchors godoc
+// @Summary      List audit log anchors
+// @Description  Returns the tenant's most recent audit log anchors: the hash chain head of each period of the enterprise audit log and its ledger transaction.
+// @Tags         blockchain
+// @Produce      json
+// @Param        limit  query  int  false  "Maximum number of anchors (default 30)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/blockchain/audit-anchors [get]
+func (h *AuditLogHandler) ListAnchors(c *gin.Context) {
+	tenantID, err := uuid.Parse(middleware.MustGetTenantIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid tenant ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if limit < 1 || limit > 500 {
+		limit = 30
+	}
+
+	anchors, err := h.anchorer.ListAnchors(c.Request.Context(), tenantID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": anchors})
+}
+
+// Proof godoc
+// @Summary      Prove an audit log entry
+// @Description  Returns the hash chain proof that the audit log entry is included, unmodified, in its anchored period, and whether the period's entries, the anchor and the ledger still agree. verified is false once any entry of the period was edited, inserted or deleted.
+// @Tags         blockchain
+// @Produce      json
+// @Param        id  path  string  true  "Audit log entry ID"
+// @Success      200  {object}  auditlog.Proof
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/blockchain/audit-logs/{id}/proof [get]
+func (h *AuditLogHandler) Proof(c *gin.Context) {
+	tenantID, err := uuid.Parse(middleware.MustGetTenantIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid tenant ID"})
+		return
+	}
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: auditlog.ErrEntryNotFound.Error()})
+		return
+	}
+
+	proof, err := h.anchorer.Prove(c.Request.Context(), tenantID, entryID)
+	switch {
+	case errors.Is(err, auditlog.ErrEntryNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, auditlog.ErrNotAnchored):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": proof})
+}
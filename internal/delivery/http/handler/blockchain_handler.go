@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
 	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
 	"github.com/gin-gonic/gin"
@@ -11,13 +16,15 @@ import (
 
 type BlockchainHandler struct {
 	swarmRepo *postgres.SwarmRepository
-	bcService *blockchain.AuditTrailService
+	batchRepo *postgres.AnchorBatchRepository
+	ledgers   *blockchain.Ledgers
 }
 
-func NewBlockchainHandler(swarmRepo *postgres.SwarmRepository, bcService *blockchain.AuditTrailService) *BlockchainHandler {
+func NewBlockchainHandler(swarmRepo *postgres.SwarmRepository, batchRepo *postgres.AnchorBatchRepository, ledgers *blockchain.Ledgers) *BlockchainHandler {
 	return &BlockchainHandler{
 		swarmRepo: swarmRepo,
-		bcService: bcService,
+		batchRepo: batchRepo,
+		ledgers:   ledgers,
 	}
 }
 
@@ -41,11 +48,11 @@ func (h *BlockchainHandler) GetStatus(c *gin.Context) {
 	}
 
 	network := task.BlockchainNet
-	if network == "" && h.bcService != nil {
+	if network == "" {
 		// Fallback if not recorded in task
-		// But let's check what network name is in the service
-		// Since Network field in AuditTrailService is not exported directly, we just return task's value or Sepolia
-		network = "Sepolia"
+		if ledger, err := h.ledgers.Get(task.LedgerProfile); err == nil {
+			network = ledger.Network()
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -55,8 +62,14 @@ func (h *BlockchainHandler) GetStatus(c *gin.Context) {
 			"blockchainStatus":   status,
 			"blockchainTx":       task.BlockchainTx,
 			"blockchainNetwork":  network,
+			"ledgerProfile":      task.LedgerProfile,
+			"ledgerContract":     task.LedgerContract,
 			"rationaleHash":      task.RationaleHash,
 			"consensusHash":      task.ConsensusHash,
+			"reviewStatus":       task.ReviewChainStat,
+			"reviewTx":           task.ReviewTx,
+			"batchId":            task.BatchID,
+			"leafIndex":          task.LeafIndex,
 			"updatedAt":          task.UpdatedAt,
 		},
 	})
@@ -75,10 +88,76 @@ func (h *BlockchainHandler) Verify(c *gin.Context) {
 		return
 	}
 
-	if h.bcService == nil {
+	// deep=true recomputes both hashes from the stored results and transcript
+	// before going to the chain, so a tampered row cannot verify.
+	var deepInfo gin.H
+	respond := func(data gin.H) {
+		if deepInfo != nil {
+			data["deep"] = deepInfo
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"status": "success",
-			"data": gin.H{
+			"data":   data,
+		})
+	}
+
+	if c.Query("deep") == "true" && (task.RationaleHash == "" || task.ConsensusHash == "") {
+		// Nothing to recompute against: the swarm has not reported hashes.
+		deepInfo = gin.H{"databaseStatus": "not_generated"}
+		respond(gin.H{
+			"verified":             false,
+			"onChainRationaleHash": "",
+			"onChainConsensusHash": "",
+			"localRationaleHash":   "",
+			"localConsensusHash":   "",
+			"blockNumber":          "0",
+			"timestamp":            "0",
+			"owner":                "",
+			"error":                "Local task hashes are not generated yet (task may be incomplete)",
+		})
+		return
+	}
+
+	if c.Query("deep") == "true" {
+		rationaleHash, consensusHash, err := blockchain.ComputeSwarmHashesFromJSON(task.ID, task.Summary, task.Results, task.Transcript)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to recompute hashes: %v", err)})
+			return
+		}
+
+		dbMatch := blockchain.HashesEqual(task.RationaleHash, rationaleHash) &&
+			blockchain.HashesEqual(task.ConsensusHash, consensusHash)
+		deepInfo = gin.H{
+			"recomputedRationaleHash": "0x" + rationaleHash,
+			"recomputedConsensusHash": "0x" + consensusHash,
+			"databaseMatch":           dbMatch,
+			"hashVersion":             task.HashVersion,
+		}
+
+		if task.ReviewRationaleHash != "" {
+			reviewRationale, reviewConsensus, err := blockchain.ComputeReviewHashes(task.ID, task.ConsensusHash, task.ReviewVerdict, task.ReviewJustification, derefString(task.ReviewedBy), derefTime(task.ReviewedAt))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to recompute review hashes: %v", err)})
+				return
+			}
+			reviewMatch := blockchain.HashesEqual(task.ReviewRationaleHash, reviewRationale) &&
+				blockchain.HashesEqual(task.ReviewConsensusHash, reviewConsensus)
+			deepInfo["recomputedReviewRationaleHash"] = "0x" + reviewRationale
+			deepInfo["recomputedReviewConsensusHash"] = "0x" + reviewConsensus
+			deepInfo["reviewMatch"] = reviewMatch
+			dbMatch = dbMatch && reviewMatch
+		}
+
+		if !dbMatch {
+			msg := "Stored hashes do not match the hashes recomputed from stored results and transcript"
+			deepInfo["databaseStatus"] = "mismatch"
+			if task.HashVersion != blockchain.SwarmHashVersion {
+				// Hashes stored before the spec was versioned may have been
+				// computed differently; they cannot be recomputed here.
+				msg = fmt.Sprintf("Stored hashes use canonical hashing spec version %d and cannot be recomputed with version %d", task.HashVersion, blockchain.SwarmHashVersion)
+				deepInfo["databaseStatus"] = "unsupported_version"
+			}
+			respond(gin.H{
 				"verified":             false,
 				"onChainRationaleHash": "",
 				"onChainConsensusHash": "",
@@ -87,67 +166,90 @@ func (h *BlockchainHandler) Verify(c *gin.Context) {
 				"blockNumber":          "0",
 				"timestamp":            "0",
 				"owner":                "",
-				"error":                "Blockchain service not enabled or configured on backend",
-			},
+				"error":                msg,
+			})
+			return
+		}
+		deepInfo["databaseStatus"] = "match"
+	}
+
+	// After a confirmed reviewer override the active log holds the review hashes.
+	localRationale, localConsensus := task.AnchoredHashes()
+
+	// Always verify against the ledger the task was anchored to.
+	ledger, err := h.ledgers.Anchored(task.LedgerProfile, task.LedgerContract)
+	if err != nil {
+		msg := fmt.Sprintf("Ledger of the task is not available: %v", err)
+		if errors.Is(err, blockchain.ErrNoLedger) {
+			msg = "Blockchain service not enabled or configured on backend"
+		}
+		respond(gin.H{
+			"verified":             false,
+			"onChainRationaleHash": "",
+			"onChainConsensusHash": "",
+			"localRationaleHash":   localRationale,
+			"localConsensusHash":   localConsensus,
+			"blockNumber":          "0",
+			"timestamp":            "0",
+			"owner":                "",
+			"error":                msg,
 		})
 		return
 	}
 
-	if task.RationaleHash == "" || task.ConsensusHash == "" {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "success",
-			"data": gin.H{
-				"verified":             false,
-				"onChainRationaleHash": "",
-				"onChainConsensusHash": "",
-				"localRationaleHash":   "",
-				"localConsensusHash":   "",
-				"blockNumber":          "0",
-				"timestamp":            "0",
-				"owner":                "",
-				"error":                "Local task hashes are not generated yet (task may be incomplete)",
-			},
+	if localRationale == "" || localConsensus == "" {
+		respond(gin.H{
+			"verified":             false,
+			"onChainRationaleHash": "",
+			"onChainConsensusHash": "",
+			"localRationaleHash":   "",
+			"localConsensusHash":   "",
+			"blockNumber":          "0",
+			"timestamp":            "0",
+			"owner":                "",
+			"error":                "Local task hashes are not generated yet (task may be incomplete)",
 		})
 		return
 	}
 
+	// Batched tasks are anchored through their batch's Merkle root, unless a
+	// confirmed reviewer override gave the task a log of its own.
+	if task.BatchID != nil && task.ReviewChainStat != domain.BlockchainStatusVerified {
+		h.verifyBatched(c, ledger, task, localRationale, localConsensus, respond)
+		return
+	}
+
 	// 1. Verify Hashes via Smart Contract
-	verified, err := h.bcService.VerifyHashes(c.Request.Context(), task.ID, task.RationaleHash, task.ConsensusHash)
+	verified, err := ledger.VerifyHashes(c.Request.Context(), task.ID, localRationale, localConsensus)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "success",
-			"data": gin.H{
-				"verified":             false,
-				"onChainRationaleHash": "",
-				"onChainConsensusHash": "",
-				"localRationaleHash":   task.RationaleHash,
-				"localConsensusHash":   task.ConsensusHash,
-				"blockNumber":          "0",
-				"timestamp":            "0",
-				"owner":                "",
-				"error":                fmt.Sprintf("Failed to call verifyHashes contract method: %v", err),
-			},
+		respond(gin.H{
+			"verified":             false,
+			"onChainRationaleHash": "",
+			"onChainConsensusHash": "",
+			"localRationaleHash":   localRationale,
+			"localConsensusHash":   localConsensus,
+			"blockNumber":          "0",
+			"timestamp":            "0",
+			"owner":                "",
+			"error":                fmt.Sprintf("Failed to call verifyHashes contract method: %v", err),
 		})
 		return
 	}
 
 	// 2. Fetch Active Log details from Smart Contract
-	logEntry, err := h.bcService.GetActiveLog(c.Request.Context(), task.ID)
+	logEntry, err := ledger.GetActiveLog(c.Request.Context(), task.ID)
 	if err != nil {
 		// Log might not exist on-chain
-		c.JSON(http.StatusOK, gin.H{
-			"status": "success",
-			"data": gin.H{
-				"verified":             verified,
-				"onChainRationaleHash": "",
-				"onChainConsensusHash": "",
-				"localRationaleHash":   task.RationaleHash,
-				"localConsensusHash":   task.ConsensusHash,
-				"blockNumber":          "0",
-				"timestamp":            "0",
-				"owner":                "",
-				"error":                fmt.Sprintf("Failed to retrieve active log details: %v", err),
-			},
+		respond(gin.H{
+			"verified":             verified,
+			"onChainRationaleHash": "",
+			"onChainConsensusHash": "",
+			"localRationaleHash":   localRationale,
+			"localConsensusHash":   localConsensus,
+			"blockNumber":          "0",
+			"timestamp":            "0",
+			"owner":                "",
+			"error":                fmt.Sprintf("Failed to retrieve active log details: %v", err),
 		})
 		return
 	}
@@ -159,17 +261,259 @@ func (h *BlockchainHandler) Verify(c *gin.Context) {
 	blockNum := fmt.Sprintf("%v", logEntry["block_number"])
 	timestamp := fmt.Sprintf("%v", logEntry["timestamp"])
 
-	c.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"data": gin.H{
-			"verified":             verified,
-			"onChainRationaleHash": onChainRationale,
-			"onChainConsensusHash": onChainConsensus,
-			"localRationaleHash":   "0x" + task.RationaleHash,
-			"localConsensusHash":   "0x" + task.ConsensusHash,
-			"blockNumber":          blockNum,
-			"timestamp":            timestamp,
-			"owner":                submitter,
-		},
+	respond(gin.H{
+		"verified":             verified,
+		"onChainRationaleHash": onChainRationale,
+		"onChainConsensusHash": onChainConsensus,
+		"localRationaleHash":   "0x" + localRationale,
+		"localConsensusHash":   "0x" + localConsensus,
+		"blockNumber":          blockNum,
+		"timestamp":            timestamp,
+		"owner":                submitter,
 	})
 }
+
+// verifyBatched recomputes the task's leaf, walks its inclusion proof up to
+// the root and checks that root against the batch's anchored log.
+func (h *BlockchainHandler) verifyBatched(c *gin.Context, ledger blockchain.Ledger, task *domain.SwarmTask, localRationale, localConsensus string, respond func(gin.H)) {
+	ctx := c.Request.Context()
+	result := gin.H{
+		"verified":             false,
+		"onChainRationaleHash": "",
+		"onChainConsensusHash": "",
+		"localRationaleHash":   "0x" + localRationale,
+		"localConsensusHash":   "0x" + localConsensus,
+		"blockNumber":          "0",
+		"timestamp":            "0",
+		"owner":                "",
+	}
+	fail := func(msg string) {
+		result["error"] = msg
+		respond(result)
+	}
+
+	batch, err := h.batchRepo.GetByID(ctx, *task.BatchID)
+	if err != nil {
+		fail(fmt.Sprintf("Failed to load anchor batch: %v", err))
+		return
+	}
+
+	var proof []blockchain.ProofStep
+	if err := json.Unmarshal(task.MerkleProof, &proof); err != nil {
+		fail(fmt.Sprintf("Stored merkle proof is invalid: %v", err))
+		return
+	}
+	leaf, err := blockchain.MerkleLeaf(task.ID, localRationale, localConsensus)
+	if err != nil {
+		fail(fmt.Sprintf("Failed to compute merkle leaf: %v", err))
+		return
+	}
+	root, err := blockchain.MerkleRootFromProof(leaf, proof)
+	if err != nil {
+		fail(fmt.Sprintf("Failed to apply merkle proof: %v", err))
+		return
+	}
+	commitment, err := blockchain.BatchCommitment(batch.ID, root, batch.LeafCount)
+	if err != nil {
+		fail(fmt.Sprintf("Failed to compute batch commitment: %v", err))
+		return
+	}
+
+	proofValid := blockchain.HashesEqual(root, batch.MerkleRoot)
+	result["batch"] = gin.H{
+		"batchId":      batch.ID,
+		"status":       batch.Status,
+		"txHash":       batch.TxHash,
+		"leafCount":    batch.LeafCount,
+		"leafIndex":    task.LeafIndex,
+		"leaf":         "0x" + leaf,
+		"proof":        proof,
+		"computedRoot": "0x" + root,
+		"merkleRoot":   "0x" + blockchain.NormalizeHash(batch.MerkleRoot),
+		"commitment":   "0x" + commitment,
+		"proofValid":   proofValid,
+	}
+	if !proofValid {
+		fail("Merkle proof does not lead to the batch root")
+		return
+	}
+
+	// The chain is checked against the recomputed root and commitment, so a
+	// tampered batch row cannot verify either.
+	verified, err := ledger.VerifyHashes(ctx, batch.ID, root, commitment)
+	if err != nil {
+		fail(fmt.Sprintf("Failed to call verifyHashes contract method: %v", err))
+		return
+	}
+	result["verified"] = verified
+
+	logEntry, err := ledger.GetActiveLog(ctx, batch.ID)
+	if err != nil {
+		fail(fmt.Sprintf("Failed to retrieve active log details: %v", err))
+		return
+	}
+	result["onChainRationaleHash"], _ = logEntry["rationale_hash"].(string)
+	result["onChainConsensusHash"], _ = logEntry["consensus_hash"].(string)
+	result["owner"], _ = logEntry["submitter"].(string)
+	result["blockNumber"] = fmt.Sprintf("%v", logEntry["block_number"])
+	result["timestamp"] = fmt.Sprintf("%v", logEntry["timestamp"])
+	respond(result)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// publishedBundle loads a published task and assembles its proof bundle,
+// filling in the block number from the active log on the ledger the task was
+// anchored to, which it also returns. Unpublished and unknown tasks are
+// indistinguishable to the public. Internal errors are only logged; a task
+// whose ledger contract was rotated away answers 410.
+func (h *BlockchainHandler) publishedBundle(c *gin.Context) (*blockchain.ProofBundle, blockchain.Ledger, bool) {
+	ctx := c.Request.Context()
+	taskID := c.Param("task_id")
+
+	task, err := h.swarmRepo.GetByID(ctx, taskID)
+	if err != nil || task.PublishedAt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Published task not found"})
+		return nil, nil, false
+	}
+	ledger, err := h.ledgers.Anchored(task.LedgerProfile, task.LedgerContract)
+	if errors.Is(err, blockchain.ErrLedgerMoved) {
+		c.JSON(http.StatusGone, gin.H{"error": "Ledger contract for this task is no longer configured"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Blockchain service not enabled or configured on backend"})
+		return nil, nil, false
+	}
+
+	var batch *domain.AnchorBatch
+	if task.BatchID != nil && task.ReviewChainStat != domain.BlockchainStatusVerified {
+		if batch, err = h.batchRepo.GetByID(ctx, *task.BatchID); err != nil {
+			log.Printf("[Blockchain] public proof of task %s: failed to load anchor batch: %v", taskID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build proof bundle"})
+			return nil, nil, false
+		}
+	}
+
+	network := task.BlockchainNet
+	if network == "" {
+		network = ledger.Network()
+	}
+	bundle, err := blockchain.NewProofBundle(task, batch, network, ledger.ContractAddress())
+	if err != nil {
+		log.Printf("[Blockchain] public proof of task %s: failed to build proof bundle: %v", taskID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build proof bundle"})
+		return nil, nil, false
+	}
+	if prover, ok := ledger.(blockchain.TimestampProver); ok {
+		if err := prover.AttachTimestamp(ctx, bundle); err != nil {
+			log.Printf("[Blockchain] public proof of task %s: failed to attach timestamp token: %v", taskID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build proof bundle"})
+			return nil, nil, false
+		}
+	}
+	if logEntry, err := ledger.GetActiveLog(ctx, bundle.Anchor.LogID); err == nil {
+		if n, ok := logEntry["block_number"].(int64); ok && n > 0 {
+			bundle.Anchor.BlockNumber = uint64(n)
+		}
+	}
+	return bundle, ledger, true
+}
+
+// PublicVerify godoc
+// @Summary      Verify a published swarm result
+// @Description  Checks the given hashes against a published task's proof and the ledger. No account is required; requests are rate limited per client IP.
+// @Tags         public
+// @Produce      json
+// @Param        task_id         path   string  true  "Task ID"
+// @Param        rationale_hash  query  string  true  "Rationale hash of the result"
+// @Param        consensus_hash  query  string  true  "Consensus hash of the result"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      410  {object}  map[string]interface{}
+// @Failure      429  {object}  map[string]interface{}
+// @Router       /api/v1/public/verify/{task_id} [get]
+func (h *BlockchainHandler) PublicVerify(c *gin.Context) {
+	rationaleHash, consensusHash := c.Query("rationale_hash"), c.Query("consensus_hash")
+	if rationaleHash == "" || consensusHash == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rationale_hash and consensus_hash are required"})
+		return
+	}
+
+	bundle, ledger, ok := h.publishedBundle(c)
+	if !ok {
+		return
+	}
+
+	// The caller may hold either the machine result's hashes or, after a
+	// confirmed override, the review hashes that superseded them.
+	expectedRationale, expectedConsensus := bundle.RationaleHash, bundle.ConsensusHash
+	if bundle.Review != nil {
+		expectedRationale, expectedConsensus = bundle.Review.RationaleHash, bundle.Review.ConsensusHash
+	}
+
+	data := gin.H{
+		"taskId":      bundle.TaskID,
+		"verified":    false,
+		"network":     bundle.Anchor.Network,
+		"contract":    bundle.Anchor.Contract,
+		"txHash":      bundle.Anchor.TxHash,
+		"blockNumber": bundle.Anchor.BlockNumber,
+		"batched":     bundle.Batch != nil,
+	}
+	respond := func(msg string) {
+		if msg != "" {
+			data["error"] = msg
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "data": data})
+	}
+
+	if !blockchain.HashesEqual(rationaleHash, expectedRationale) || !blockchain.HashesEqual(consensusHash, expectedConsensus) {
+		respond("Hashes do not match the published result")
+		return
+	}
+	if err := bundle.VerifyOffline(); err != nil {
+		respond(fmt.Sprintf("Proof does not verify: %v", err))
+		return
+	}
+	if err := bundle.VerifyOnChain(c.Request.Context(), ledger); err != nil {
+		respond(fmt.Sprintf("Ledger check failed: %v", err))
+		return
+	}
+	data["verified"] = true
+	respond("")
+}
+
+// ProofBundle godoc
+// @Summary      Download the proof bundle of a published swarm result
+// @Description  Returns the canonical result, its hashes and anchoring details (and Merkle path when batched) as JSON, for checking offline with cmd/verify_proof.
+// @Tags         public
+// @Produce      json
+// @Param        task_id  path  string  true  "Task ID"
+// @Success      200  {object}  blockchain.ProofBundle
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      410  {object}  map[string]interface{}
+// @Failure      429  {object}  map[string]interface{}
+// @Router       /api/v1/public/proof/{task_id} [get]
+func (h *BlockchainHandler) ProofBundle(c *gin.Context) {
+	bundle, _, ok := h.publishedBundle(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="proof-%s.json"`, bundle.TaskID))
+	c.IndentedJSON(http.StatusOK, bundle)
+}
@@ -0,0 +1,126 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/handler"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/gin-gonic/gin"
+	gormpg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestBlockchainHandler_PublicVerify(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	const taskID = "11111111-1111-1111-1111-111111111111"
+	results := []byte(`[{"item":"Laptop","verdict":"WAJAR"}]`)
+	rationale, consensus, err := blockchain.ComputeSwarmHashesFromJSON(taskID, "Harga wajar", results, []byte(`[]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger, err := blockchain.NewLocalLedger(filepath.Join(t.TempDir(), "audit.jsonl"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ledger.Close()
+	tx, err := ledger.InsertLog(ctx, taskID, rationale, consensus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns := []string{"id", "summary", "results", "rationale_hash", "consensus_hash", "blockchain_tx", "blockchain_stat", "ledger_profile", "ledger_contract", "batch_id", "published_at"}
+	batchID := "22222222-2222-2222-2222-222222222222"
+	published := time.Now()
+
+	cases := []struct {
+		name      string
+		query     string
+		published *time.Time
+		// loads is false when the request is rejected before the task is read.
+		loads    bool
+		contract string
+		batchID  *string
+		wantCode int
+		// wantBody must appear in the response, leak must not.
+		wantBody     string
+		leak         string
+		wantVerified bool
+	}{
+		{name: "missing hashes", query: "?rationale_hash=" + rationale, wantCode: http.StatusBadRequest},
+		{name: "not published", query: "?rationale_hash=" + rationale + "&consensus_hash=" + consensus, loads: true, wantCode: http.StatusNotFound},
+		{name: "verified", query: "?rationale_hash=" + rationale + "&consensus_hash=" + consensus, published: &published, loads: true, wantCode: http.StatusOK, wantVerified: true},
+		{name: "other hashes", query: "?rationale_hash=" + consensus + "&consensus_hash=" + rationale, published: &published, loads: true, wantCode: http.StatusOK},
+		{
+			name: "contract rotated away", query: "?rationale_hash=" + rationale + "&consensus_hash=" + consensus, published: &published, loads: true,
+			contract: "0x000000000000000000000000000000000000dead", wantCode: http.StatusGone, wantBody: "no longer configured",
+		},
+		{
+			name: "batch unavailable", query: "?rationale_hash=" + rationale + "&consensus_hash=" + consensus, published: &published, loads: true,
+			batchID: &batchID, wantCode: http.StatusInternalServerError, wantBody: "Failed to build proof bundle", leak: "connection refused",
+		},
+	}
+	for _, c := range cases {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		gormDB, err := gorm.Open(gormpg.New(gormpg.Config{Conn: db}), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.loads {
+			mock.ExpectQuery(`SELECT \* FROM "swarm_tasks" WHERE id = \$1`).
+				WithArgs(taskID, 1).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(taskID, "Harga wajar", results, rationale, consensus, tx, "VERIFIED", "default", c.contract, c.batchID, c.published))
+		}
+		if c.batchID != nil {
+			mock.ExpectQuery(`SELECT \* FROM "anchor_batches" WHERE id = \$1`).
+				WillReturnError(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+		}
+		h := handler.NewBlockchainHandler(postgres.NewSwarmRepository(gormDB), postgres.NewAnchorBatchRepository(gormDB), blockchain.SingleLedger(ledger))
+		router := gin.New()
+		router.GET("/api/v1/public/verify/:task_id", h.PublicVerify)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/public/verify/"+taskID+c.query, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != c.wantCode {
+			t.Errorf("%s: status %d, want %d: %s", c.name, w.Code, c.wantCode, w.Body.String())
+		}
+		if body := w.Body.String(); !strings.Contains(body, c.wantBody) || (c.leak != "" && strings.Contains(body, c.leak)) {
+			t.Errorf("%s: body %s, want %q without %q", c.name, body, c.wantBody, c.leak)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			Data struct {
+				Verified bool   `json:"verified"`
+				TxHash   string `json:"txHash"`
+				Error    string `json:"error"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Data.Verified != c.wantVerified || resp.Data.TxHash != tx {
+			t.Errorf("%s: verified %v (%s) for tx %s, want %v", c.name, resp.Data.Verified, resp.Data.Error, resp.Data.TxHash, c.wantVerified)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package handler
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
 	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/rag"
 	"github.com/gin-gonic/gin"
 	"github.com/google/generative-ai-go/genai"
 	"github.com/google/uuid"
@@ -18,13 +19,17 @@ type ChatHandler struct {
 	chatRepo     domain.ChatRepository
 	docRepo      domain.DocumentRepository
 	geminiAPIKey string
+	embedders    ai.TenantEmbedders // nil disables knowledge base retrieval
+	expander     ai.QueryExpander   // nil: expand_query is ignored
 }
 
-func NewChatHandler(chatRepo domain.ChatRepository, docRepo domain.DocumentRepository, geminiAPIKey string) *ChatHandler {
+func NewChatHandler(chatRepo domain.ChatRepository, docRepo domain.DocumentRepository, geminiAPIKey string, embedders ai.TenantEmbedders, expander ai.QueryExpander) *ChatHandler {
 	return &ChatHandler{
 		chatRepo:     chatRepo,
 		docRepo:      docRepo,
 		geminiAPIKey: geminiAPIKey,
+		embedders:    embedders,
+		expander:     expander,
 	}
 }
 
@@ -127,6 +132,9 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 
 type SendMessageRequest struct {
 	Message string `json:"message" binding:"required"`
+	// ExpandQuery retrieves knowledge base context with reformulations of
+	// the message too; the searched queries are returned as "queries".
+	ExpandQuery bool `json:"expand_query"`
 }
 
 func (h *ChatHandler) SendMessage(c *gin.Context) {
@@ -159,24 +167,29 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	// 2. Perform RAG query enhancement if API Key is available
+	// 2. Perform RAG query enhancement if an embedder is available
 	var contextText string
-	if h.geminiAPIKey != "" {
-		embedding, err := h.getQueryEmbedding(c.Request.Context(), req.Message)
+	var citations []domain.Citation
+	queries := []string{req.Message}
+	if h.embedders != nil {
+		embedder, err := h.embedders.QueryEmbedder(c.Request.Context(), tenantID)
 		if err == nil {
-			results, err := h.docRepo.HybridSearch(c.Request.Context(), domain.HybridSearchParams{
-				TenantID:       tenantID,
-				QueryText:      req.Message,
-				QueryEmbedding: embedding,
-				TopK:           3,
-				EfSearch:       50,
-				RRFConstant:    60,
-			})
+			if req.ExpandQuery && h.expander != nil {
+				// On failure the message alone is searched.
+				queries, _ = rag.ExpandQuery(c.Request.Context(), h.expander, req.Message, 0)
+			}
+			results, err := rag.MultiSearch(c.Request.Context(), h.docRepo, embedder, domain.HybridSearchParams{
+				TenantID:    tenantID,
+				TopK:        3,
+				EfSearch:    50,
+				RRFConstant: 60,
+			}, queries)
 			if err == nil && len(results) > 0 {
 				contextText = "\nKnowledge base reference:\n"
 				for _, res := range results {
-					contextText += fmt.Sprintf("- From document '%s': %s\n", res.DocumentTitle, res.Content)
+					contextText += fmt.Sprintf("- From document '%s': %s\n", rag.SourceLabel(res), res.Content)
 				}
+				citations = rag.Citations(results)
 			}
 		}
 	}
@@ -259,20 +272,12 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "data": modelMsg})
-}
-
-func (h *ChatHandler) getQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(h.geminiAPIKey))
-	if err != nil {
-		return nil, err
+	response := gin.H{"status": "success", "data": modelMsg}
+	if len(citations) > 0 {
+		response["citations"] = citations
 	}
-	defer client.Close()
-
-	em := client.EmbeddingModel("text-embedding-004")
-	resp, err := em.EmbedContent(ctx, genai.Text(query))
-	if err != nil {
-		return nil, err
+	if len(queries) > 1 {
+		response["queries"] = queries
 	}
-	return resp.Embedding.Values, nil
+	c.JSON(http.StatusOK, response)
 }
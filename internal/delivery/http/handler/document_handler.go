@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -58,7 +59,7 @@ func (h *DocumentHandler) Presign(c *gin.Context) {
 
 // ConfirmUpload godoc
 // @Summary      Confirm S3 upload and trigger vectorization
-// @Description  Called by frontend AFTER PUT to S3. Creates DB record and enqueues Asynq vectorization worker.
+// @Description  Called by frontend AFTER PUT to S3. Creates DB record and enqueues Asynq vectorization worker. Optional metadata holds user-defined key/values for the search filter.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -71,6 +72,12 @@ type ConfirmUploadRequest struct {
 	Title     string `json:"title" binding:"required"`
 	ObjectKey string `json:"object_key" binding:"required"`
 	Category  string `json:"category" binding:"required"`
+	// Language is the full-text search language (indonesian, english or
+	// simple); empty uses the tenant's search_language.
+	Language string `json:"language" binding:"omitempty,oneof=indonesian english simple"`
+	// Metadata holds user-defined key/values, matched by the search filter's
+	// metadata.
+	Metadata map[string]interface{} `json:"metadata"`
 }
 
 func (h *DocumentHandler) ConfirmUpload(c *gin.Context) {
@@ -89,7 +96,7 @@ func (h *DocumentHandler) ConfirmUpload(c *gin.Context) {
 		return
 	}
 
-	doc, err := h.usecase.ConfirmUpload(c.Request.Context(), tenantID, user.ID, req.Title, req.ObjectKey, req.Category)
+	doc, err := h.usecase.ConfirmUpload(c.Request.Context(), tenantID, user.ID, req.Title, req.ObjectKey, req.Category, req.Language, req.Metadata)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -209,7 +216,7 @@ func (h *DocumentHandler) Delete(c *gin.Context) {
 
 // UpdateText godoc
 // @Summary      Update document extracted text
-// @Description  Updates the extracted plain text inside document's ai_analysis_json.
+// @Description  Updates the staged text of a document. A ready document is re-indexed incrementally: only changed chunks are re-embedded and its revision is bumped.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -217,6 +224,7 @@ func (h *DocumentHandler) Delete(c *gin.Context) {
 // @Param        request body map[string]string true "Update text request"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
 // @Security     BearerAuth
 // @Router       /api/v1/documents/{id}/text [patch]
 func (h *DocumentHandler) UpdateText(c *gin.Context) {
@@ -243,6 +251,10 @@ func (h *DocumentHandler) UpdateText(c *gin.Context) {
 	}
 
 	err = h.usecase.UpdateText(c.Request.Context(), tenantID, docID, req.ExtractedText)
+	if errors.Is(err, domain.ErrDocumentProcessing) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -253,3 +265,242 @@ func (h *DocumentHandler) UpdateText(c *gin.Context) {
 		"message": "Document text updated successfully",
 	})
 }
+
+// UpdateMetadata godoc
+// @Summary      Update document metadata
+// @Description  Replaces the user-defined metadata of a document version. Searches with a metadata filter match documents whose metadata contains the filter's key/values.
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Param        id   path  string  true  "Document ID"
+// @Param        request body UpdateMetadataRequest true "Update metadata request"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/{id}/metadata [patch]
+type UpdateMetadataRequest struct {
+	Metadata map[string]interface{} `json:"metadata" binding:"required"`
+}
+
+func (h *DocumentHandler) UpdateMetadata(c *gin.Context) {
+	docIDStr := c.Param("id")
+	docID, err := uuid.Parse(docIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid document ID path parameter"})
+		return
+	}
+
+	tenantIDStr := middleware.MustGetTenantIDFromContext(c)
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid X-Tenant-ID header"})
+		return
+	}
+
+	var req UpdateMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = h.usecase.UpdateMetadata(c.Request.Context(), tenantID, docID, req.Metadata)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Document metadata updated successfully",
+	})
+}
+
+// ChunkPreviewRequest selects the text to chunk: the staged text of
+// DocumentID, or Text. Chunking overrides the options resolved for the
+// category (the document's own when empty).
+type ChunkPreviewRequest struct {
+	DocumentID *uuid.UUID             `json:"document_id"`
+	Text       string                 `json:"text"`
+	Category   string                 `json:"category"`
+	Chunking   domain.ChunkingOptions `json:"chunking"`
+}
+
+// PreviewChunks godoc
+// @Summary      Preview document chunking
+// @Description  Shows how a document (its staged text) or a pasted text would be chunked for embedding: the resolved options (ai.chunking, then the tenant's settings.chunking and its category override, then the request's chunking) and every chunk with its header path, size in characters and estimated tokens, and character offsets. Nothing is stored.
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Param        request body ChunkPreviewRequest true "Chunk preview request"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/chunk-preview [post]
+func (h *DocumentHandler) PreviewChunks(c *gin.Context) {
+	tenantIDStr := middleware.MustGetTenantIDFromContext(c)
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid X-Tenant-ID header"})
+		return
+	}
+
+	var req ChunkPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if req.DocumentID == nil && req.Text == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "document_id or text is required"})
+		return
+	}
+	if err := req.Chunking.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	preview, err := h.usecase.PreviewChunks(c.Request.Context(), tenantID, req.DocumentID, req.Text, req.Category, req.Chunking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": preview})
+}
+
+// UploadVersionRequest confirms the upload of a new version of a document.
+// An empty Title keeps the title of the latest version.
+type UploadVersionRequest struct {
+	ObjectKey string `json:"object_key" binding:"required"`
+	Title     string `json:"title"`
+	// Metadata replaces the record's metadata for the new version; omitted
+	// keeps it.
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// UploadVersion godoc
+// @Summary      Upload a new version of a document
+// @Description  Called AFTER PUT to S3 (object key from /presign). Adds the file as the next version of the document's record, with the record's category and language (and metadata, unless given), and enqueues parsing. The new version goes through QA like any upload; once it is approved and ready, it replaces the older versions in searches.
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Param        id   path  string  true  "ID of any version of the document"
+// @Param        request body UploadVersionRequest true "Upload version request"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/{id}/versions [post]
+func (h *DocumentHandler) UploadVersion(c *gin.Context) {
+	docIDStr := c.Param("id")
+	docID, err := uuid.Parse(docIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid document ID path parameter"})
+		return
+	}
+
+	user := middleware.MustGetUserFromContext(c)
+	tenantIDStr := middleware.MustGetTenantIDFromContext(c)
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid X-Tenant-ID header"})
+		return
+	}
+
+	var req UploadVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	doc, err := h.usecase.UploadVersion(c.Request.Context(), tenantID, user.ID, docID, req.Title, req.ObjectKey, req.Metadata)
+	if errors.Is(err, domain.ErrDocumentVersionExists) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Another version of this document is being uploaded, retry"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":      "success",
+		"document_id": doc.ID,
+		"record_id":   doc.RecordID,
+		"version":     doc.Version,
+		"message":     "New version accepted for processing.",
+	})
+}
+
+// ListVersions godoc
+// @Summary      List the versions of a document
+// @Tags         knowledge
+// @Produce      json
+// @Param        id   path  string  true  "ID of any version of the document"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/{id}/versions [get]
+func (h *DocumentHandler) ListVersions(c *gin.Context) {
+	docIDStr := c.Param("id")
+	docID, err := uuid.Parse(docIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid document ID path parameter"})
+		return
+	}
+	tenantIDStr := middleware.MustGetTenantIDFromContext(c)
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid X-Tenant-ID header"})
+		return
+	}
+
+	versions, err := h.usecase.ListVersions(c.Request.Context(), tenantID, docID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": versions})
+}
+
+// DiffVersions godoc
+// @Summary      Diff two versions of a document
+// @Description  Line diff of the staged (reviewed) text of two versions of the document's record, in hunks with 3 lines of context. to defaults to the latest version, from to the version before to.
+// @Tags         knowledge
+// @Produce      json
+// @Param        id    path   string  true   "ID of any version of the document"
+// @Param        from  query  int     false  "Older version number"
+// @Param        to    query  int     false  "Newer version number"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/{id}/diff [get]
+func (h *DocumentHandler) DiffVersions(c *gin.Context) {
+	docIDStr := c.Param("id")
+	docID, err := uuid.Parse(docIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid document ID path parameter"})
+		return
+	}
+	tenantIDStr := middleware.MustGetTenantIDFromContext(c)
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid X-Tenant-ID header"})
+		return
+	}
+
+	from, errFrom := strconv.Atoi(c.DefaultQuery("from", "0"))
+	to, errTo := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if errFrom != nil || errTo != nil || from < 0 || to < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from and to must be version numbers"})
+		return
+	}
+
+	diff, err := h.usecase.DiffVersions(c.Request.Context(), tenantID, docID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": diff})
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/handler"
@@ -16,20 +17,25 @@ import (
 
 // MockDocumentUsecase is a mock of domain.DocumentUsecase
 type MockDocumentUsecase struct {
-	GetUploadURLFunc  func(ctx context.Context, tenantID, userID uuid.UUID, fileName string) (string, string, error)
-	ConfirmUploadFunc func(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey, category string) (*domain.Document, error)
-	ListDocumentsFunc func(ctx context.Context, tenantID string, limit, offset int) ([]*domain.Document, int64, error)
-	ApproveFunc       func(ctx context.Context, tenantID, docID uuid.UUID) error
-	DeleteFunc        func(ctx context.Context, tenantID, docID uuid.UUID) error
-	UpdateTextFunc    func(ctx context.Context, tenantID, docID uuid.UUID, text string) error
+	GetUploadURLFunc   func(ctx context.Context, tenantID, userID uuid.UUID, fileName string) (string, string, error)
+	ConfirmUploadFunc  func(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey, category, language string, metadata map[string]interface{}) (*domain.Document, error)
+	ListDocumentsFunc  func(ctx context.Context, tenantID string, limit, offset int) ([]*domain.Document, int64, error)
+	ApproveFunc        func(ctx context.Context, tenantID, docID uuid.UUID) error
+	DeleteFunc         func(ctx context.Context, tenantID, docID uuid.UUID) error
+	UpdateTextFunc     func(ctx context.Context, tenantID, docID uuid.UUID, text string) error
+	UpdateMetadataFunc func(ctx context.Context, tenantID, docID uuid.UUID, metadata map[string]interface{}) error
+	PreviewFunc        func(ctx context.Context, tenantID uuid.UUID, docID *uuid.UUID, text, category string, override domain.ChunkingOptions) (*domain.ChunkPreview, error)
+	UploadVersionFunc  func(ctx context.Context, tenantID, userID, docID uuid.UUID, title, objectKey string, metadata map[string]interface{}) (*domain.Document, error)
+	ListVersionsFunc   func(ctx context.Context, tenantID, docID uuid.UUID) ([]*domain.Document, error)
+	DiffVersionsFunc   func(ctx context.Context, tenantID, docID uuid.UUID, from, to int) (*domain.TextDiff, error)
 }
 
 func (m *MockDocumentUsecase) GetUploadURL(ctx context.Context, tenantID, userID uuid.UUID, fileName string) (string, string, error) {
 	return m.GetUploadURLFunc(ctx, tenantID, userID, fileName)
 }
 
-func (m *MockDocumentUsecase) ConfirmUpload(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey, category string) (*domain.Document, error) {
-	return m.ConfirmUploadFunc(ctx, tenantID, userID, title, objectKey, category)
+func (m *MockDocumentUsecase) ConfirmUpload(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey, category, language string, metadata map[string]interface{}) (*domain.Document, error) {
+	return m.ConfirmUploadFunc(ctx, tenantID, userID, title, objectKey, category, language, metadata)
 }
 
 func (m *MockDocumentUsecase) ListDocuments(ctx context.Context, tenantID string, limit, offset int) ([]*domain.Document, int64, error) {
@@ -54,6 +60,17 @@ func (m *MockDocumentUsecase) UpdateText(ctx context.Context, tenantID, docID uu
 	return nil
 }
 
+func (m *MockDocumentUsecase) UpdateMetadata(ctx context.Context, tenantID, docID uuid.UUID, metadata map[string]interface{}) error {
+	if m.UpdateMetadataFunc != nil {
+		return m.UpdateMetadataFunc(ctx, tenantID, docID, metadata)
+	}
+	return nil
+}
+
+func (m *MockDocumentUsecase) PreviewChunks(ctx context.Context, tenantID uuid.UUID, docID *uuid.UUID, text, category string, override domain.ChunkingOptions) (*domain.ChunkPreview, error) {
+	return m.PreviewFunc(ctx, tenantID, docID, text, category, override)
+}
+
 func TestDocumentHandler_Approve(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -136,3 +153,130 @@ func TestDocumentHandler_Approve(t *testing.T) {
 		}
 	})
 }
+
+func (m *MockDocumentUsecase) UploadVersion(ctx context.Context, tenantID, userID, docID uuid.UUID, title, objectKey string, metadata map[string]interface{}) (*domain.Document, error) {
+	return m.UploadVersionFunc(ctx, tenantID, userID, docID, title, objectKey, metadata)
+}
+
+func (m *MockDocumentUsecase) ListVersions(ctx context.Context, tenantID, docID uuid.UUID) ([]*domain.Document, error) {
+	return m.ListVersionsFunc(ctx, tenantID, docID)
+}
+
+func (m *MockDocumentUsecase) DiffVersions(ctx context.Context, tenantID, docID uuid.UUID, from, to int) (*domain.TextDiff, error) {
+	return m.DiffVersionsFunc(ctx, tenantID, docID, from, to)
+}
+
+func TestDocumentHandler_UpdateTextConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantID := uuid.New()
+	docID := uuid.New()
+
+	uc := &MockDocumentUsecase{
+		UpdateTextFunc: func(ctx context.Context, tID, dID uuid.UUID, text string) error {
+			return domain.ErrDocumentProcessing
+		},
+	}
+
+	h := handler.NewDocumentHandler(uc)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+	router.PATCH("/api/v1/documents/:id/text", h.UpdateText)
+
+	req := httptest.NewRequest("PATCH", "/api/v1/documents/"+docID.String()+"/text", strings.NewReader(`{"extracted_text":"teks baru"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", tenantID.String())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 Conflict, got %d", w.Code)
+	}
+}
+
+func TestDocumentHandler_PreviewChunks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantID := uuid.New()
+	var gotText string
+	var gotOptions domain.ChunkingOptions
+	uc := &MockDocumentUsecase{
+		PreviewFunc: func(ctx context.Context, tID uuid.UUID, docID *uuid.UUID, text, category string, override domain.ChunkingOptions) (*domain.ChunkPreview, error) {
+			gotText, gotOptions = text, override
+			return &domain.ChunkPreview{Options: override, Chunks: []domain.PreviewChunk{{Content: text}}}, nil
+		},
+	}
+
+	h := handler.NewDocumentHandler(uc)
+	router := gin.New()
+	router.POST("/api/v1/documents/chunk-preview", h.PreviewChunks)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/documents/chunk-preview", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenantID.String())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"text": "Pasal 1. Ketentuan umum.", "chunking": {"strategy": "sentence_window", "max_size": 200}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotText != "Pasal 1. Ketentuan umum." || gotOptions.Strategy != domain.ChunkSentenceWindow || gotOptions.MaxSize != 200 {
+		t.Errorf("unexpected usecase arguments: %q %+v", gotText, gotOptions)
+	}
+
+	for _, body := range []string{
+		`{}`,
+		`{"text": "x", "chunking": {"strategy": "paragraph"}}`,
+		`{"text": "x", "chunking": {"max_size": 100, "overlap": 100}}`,
+	} {
+		if w := post(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestDocumentHandler_DiffVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantID := uuid.New()
+	docID := uuid.New()
+	var gotFrom, gotTo int
+	uc := &MockDocumentUsecase{
+		DiffVersionsFunc: func(ctx context.Context, tID, dID uuid.UUID, from, to int) (*domain.TextDiff, error) {
+			gotFrom, gotTo = from, to
+			return &domain.TextDiff{Added: 1}, nil
+		},
+	}
+
+	h := handler.NewDocumentHandler(uc)
+	router := gin.New()
+	router.GET("/api/v1/documents/:id/diff", h.DiffVersions)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/documents/"+docID.String()+"/diff"+query, nil)
+		req.Header.Set("X-Tenant-ID", tenantID.String())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("?from=1&to=3"); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotFrom != 1 || gotTo != 3 {
+		t.Errorf("versions = %d..%d, want 1..3", gotFrom, gotTo)
+	}
+	if w := get(""); w.Code != http.StatusOK || gotFrom != 0 || gotTo != 0 {
+		t.Errorf("defaults: status %d, versions %d..%d", w.Code, gotFrom, gotTo)
+	}
+	if w := get("?from=satu"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a non-numeric version, got %d", w.Code)
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/rag"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// EmbeddingIndexHandler manages the migration of a tenant's corpus to
+// another embedding model.
+type EmbeddingIndexHandler struct {
+	indexes *rag.EmbeddingIndexService // nil when no embedder is configured
+}
+
+func NewEmbeddingIndexHandler(indexes *rag.EmbeddingIndexService) *EmbeddingIndexHandler {
+	return &EmbeddingIndexHandler{indexes: indexes}
+}
+
+type StartEmbeddingMigrationRequest struct {
+	Model string `json:"model" binding:"required"`
+}
+
+// List godoc
+// @Summary      List embedding indexes
+// @Description  Returns the tenant's embedding indexes with their build progress, newest first, and the models the corpus can be migrated to. The ACTIVE index serves queries; STANDBY is the one a rollback returns to.
+// @Tags         knowledge
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/embedding-indexes [get]
+func (h *EmbeddingIndexHandler) List(c *gin.Context) {
+	tenantID, ok := h.tenant(c)
+	if !ok {
+		return
+	}
+	indexes, err := h.indexes.List(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": indexes, "models": h.indexes.Models()})
+}
+
+// Start godoc
+// @Summary      Re-embed the corpus with another model
+// @Description  Builds a new embedding index with the given model in the background. Queries keep using the active index until every chunk is re-embedded, then the tenant switches atomically. Requires the documents:reindex permission.
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Param        request body StartEmbeddingMigrationRequest true "Target model"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/embedding-indexes [post]
+func (h *EmbeddingIndexHandler) Start(c *gin.Context) {
+	tenantID, ok := h.tenant(c)
+	if !ok {
+		return
+	}
+	var req StartEmbeddingMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	idx, err := h.indexes.Start(c.Request.Context(), tenantID, req.Model)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "success", "data": idx})
+}
+
+// Cancel godoc
+// @Summary      Cancel an embedding migration
+// @Description  Stops building the index and drops its vectors. The active index is unaffected. Requires the documents:reindex permission.
+// @Tags         knowledge
+// @Produce      json
+// @Param        id  path  string  true  "Embedding index ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/embedding-indexes/{id} [delete]
+func (h *EmbeddingIndexHandler) Cancel(c *gin.Context) {
+	tenantID, ok := h.tenant(c)
+	if !ok {
+		return
+	}
+	indexID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: rag.ErrIndexNotFound.Error()})
+		return
+	}
+
+	idx, err := h.indexes.Cancel(c.Request.Context(), tenantID, indexID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": idx})
+}
+
+// Rollback godoc
+// @Summary      Roll back an embedding migration
+// @Description  Switches the tenant back to its STANDBY embedding index, atomically. Chunks added since the switch are re-embedded with the old model in the background. Requires the documents:reindex permission.
+// @Tags         knowledge
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      409  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/documents/embedding-indexes/rollback [post]
+func (h *EmbeddingIndexHandler) Rollback(c *gin.Context) {
+	tenantID, ok := h.tenant(c)
+	if !ok {
+		return
+	}
+	idx, err := h.indexes.Rollback(c.Request.Context(), tenantID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": idx})
+}
+
+// tenant returns the request's tenant, answering the request itself when
+// there is none or embedding is not configured.
+func (h *EmbeddingIndexHandler) tenant(c *gin.Context) (uuid.UUID, bool) {
+	if h.indexes == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "embedding is not configured"})
+		return uuid.Nil, false
+	}
+	tenantID, err := uuid.Parse(middleware.MustGetTenantIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid tenant ID"})
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+func (h *EmbeddingIndexHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ai.ErrUnknownEmbeddingModel):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, rag.ErrIndexNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, rag.ErrMigrationInProgress), errors.Is(err, rag.ErrSameModel),
+		errors.Is(err, rag.ErrNoStandby), errors.Is(err, rag.ErrInvalidIndexState):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
@@ -1,26 +1,27 @@
 package handler
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
 	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/rag"
 	"github.com/gin-gonic/gin"
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
 )
 
 // RAGSearchHandler performs Hybrid RAG Search.
-// It embeds the query via Gemini, then delegates to the repository's HybridSearch
+// It embeds the query with the model of the tenant's active embedding index, then delegates to the repository's HybridSearch
 // which applies HNSW semantic search + PostgreSQL FTS + RRF fusion — all scoped to a tenant.
 type RAGSearchHandler struct {
-	docRepo      domain.DocumentRepository
-	geminiAPIKey string // read from config, never hardcoded
+	docRepo   domain.DocumentRepository
+	embedders ai.TenantEmbedders
+	reranker  ai.Reranker      // nil: requests with rerank are rejected
+	expander  ai.QueryExpander // nil: requests with expand_query are rejected
 }
 
-func NewRAGSearchHandler(docRepo domain.DocumentRepository, geminiAPIKey string) *RAGSearchHandler {
-	return &RAGSearchHandler{docRepo: docRepo, geminiAPIKey: geminiAPIKey}
+func NewRAGSearchHandler(docRepo domain.DocumentRepository, embedders ai.TenantEmbedders, reranker ai.Reranker, expander ai.QueryExpander) *RAGSearchHandler {
+	return &RAGSearchHandler{docRepo: docRepo, embedders: embedders, reranker: reranker, expander: expander}
 }
 
 type SearchRequest struct {
@@ -28,12 +29,24 @@ type SearchRequest struct {
 	TopK        int    `json:"top_k"`
 	EfSearch    int    `json:"ef_search"`
 	RRFConstant int    `json:"rrf_constant"`
-	Category    string `json:"category"`
+	// ExpandQuery also searches up to max_queries (default from config,
+	// max 5) reformulations of the query and fuses the result lists.
+	ExpandQuery bool `json:"expand_query"`
+	MaxQueries  int  `json:"max_queries"`
+	// Rerank rescores the fused top rerank_candidates (default 20, max 100)
+	// with the configured reranker and returns the best top_k.
+	Rerank           bool `json:"rerank"`
+	RerankCandidates int  `json:"rerank_candidates"`
+	// Optional document filters: category, document_ids, uploaded_after
+	// and uploaded_before (RFC 3339), statuses, metadata key/values, and
+	// record_id, version or all_versions to search other than the latest
+	// document versions.
+	domain.SearchFilter
 }
 
 // Search godoc
 // @Summary      Hybrid RAG Search (HNSW + FTS + RRF)
-// @Description  Tenant-scoped hybrid search: pgvector HNSW (semantic) + PostgreSQL FTS (lexical) fused via Reciprocal Rank Fusion. ef_search is tuned per query for accuracy/speed.
+// @Description  Tenant-scoped hybrid search: pgvector HNSW (semantic) + PostgreSQL FTS (lexical) fused via Reciprocal Rank Fusion. ef_search is tuned per query for accuracy/speed. Optional filters (category, document_ids, uploaded_after, uploaded_before, statuses, metadata, record_id, version, all_versions) restrict both retrievals to matching documents; by default only the latest version of every document is searched. With expand_query, generated reformulations are searched too and fused (meta.queries lists them). With rerank, the fused candidates are rescored by the configured reranker; results then carry both rrf_score and rerank_score. Every result carries a citation (document, pages, header path, character offsets, page regions) for deep links into the viewer.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -69,58 +82,84 @@ func (h *RAGSearchHandler) Search(c *gin.Context) {
 	if req.RRFConstant > 0 {
 		rrfConstant = req.RRFConstant
 	}
+	if req.Rerank && h.reranker == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Reranking is not configured"})
+		return
+	}
+	if req.ExpandQuery && h.expander == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Query expansion is not configured"})
+		return
+	}
+	searchK := topK
+	if req.Rerank {
+		searchK = rag.RerankCandidates(req.RerankCandidates, topK)
+	}
 
-	// Step 1: Embed the query text using Gemini text-embedding-004.
-	// The API key is injected from config — never hardcoded.
-	queryEmbedding, err := h.getQueryEmbedding(c.Request.Context(), req.Query)
+	// Queries are embedded with the same model that embedded the chunks.
+	embedder, err := h.embedders.QueryEmbedder(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Query embedding failed: " + err.Error()})
 		return
 	}
+	meta := gin.H{
+		"top_k":           topK,
+		"ef_search":       efSearch,
+		"rrf_constant":    rrfConstant,
+		"embedding_model": embedder.Model(),
+		"filter":          req.SearchFilter,
+	}
+	strategy := "hybrid_rrf"
 
-	// Step 2: Hybrid Search at the repository layer.
+	// Step 1: Optional query expansion. A failing expander degrades to the
+	// original query alone.
+	queries := []string{req.Query}
+	if req.ExpandQuery {
+		var expandErr error
+		queries, expandErr = rag.ExpandQuery(c.Request.Context(), h.expander, req.Query, req.MaxQueries)
+		if expandErr != nil {
+			meta["expand_error"] = expandErr.Error()
+		}
+	}
+	if len(queries) > 1 {
+		strategy = "multi_query_" + strategy
+	}
+	meta["queries"] = queries
+
+	// Step 2: Hybrid Search at the repository layer, once per query, fused across queries.
 	// Tenant pre-filtering is enforced INSIDE the SQL query — not just in Go.
-	// We also inject the category filter (metadata_filter) for Hackathon SupTech precision.
-	results, err := h.docRepo.HybridSearch(c.Request.Context(), domain.HybridSearchParams{
-		TenantID:       tenantID,
-		QueryText:      req.Query,
-		QueryEmbedding: queryEmbedding,
-		TopK:           topK,
-		EfSearch:       efSearch,
-		RRFConstant:    rrfConstant,
-		Category:       req.Category, // metadata filter
-	})
+	// Document filters are applied inside both the vector and the FTS retrieval.
+	results, err := rag.MultiSearch(c.Request.Context(), h.docRepo, embedder, domain.HybridSearchParams{
+		TenantID:    tenantID,
+		TopK:        searchK,
+		EfSearch:    efSearch,
+		RRFConstant: rrfConstant,
+		Filter:      req.SearchFilter,
+	}, queries)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Hybrid search failed: " + err.Error()})
 		return
 	}
 
+	// Step 3: Optional rerank of the fused candidates against the original
+	// query. A failing reranker degrades to the fused order instead of
+	// failing the search.
+	if req.Rerank {
+		meta["rerank_model"] = h.reranker.Model()
+		meta["rerank_candidates"] = searchK
+		var rerankErr error
+		results, rerankErr = rag.Rerank(c.Request.Context(), h.reranker, req.Query, results, topK)
+		if rerankErr != nil {
+			meta["rerank_error"] = rerankErr.Error()
+		} else {
+			strategy += "_rerank"
+		}
+	}
+	meta["strategy"] = strategy
+	meta["count"] = len(results)
+
 	c.JSON(http.StatusOK, gin.H{
 		"query":   req.Query,
 		"results": results,
-		"meta": gin.H{
-			"strategy":     "hybrid_rrf",
-			"top_k":        topK,
-			"ef_search":    efSearch,
-			"rrf_constant": rrfConstant,
-			"count":        len(results),
-		},
+		"meta":    meta,
 	})
 }
-
-// getQueryEmbedding calls Gemini text-embedding-004 to produce a 1536-dim vector
-// for the user's query. This vector is then used in the HNSW cosine similarity search.
-func (h *RAGSearchHandler) getQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(h.geminiAPIKey))
-	if err != nil {
-		return nil, err
-	}
-	defer client.Close()
-
-	em := client.EmbeddingModel("text-embedding-004")
-	resp, err := em.EmbedContent(ctx, genai.Text(query))
-	if err != nil {
-		return nil, err
-	}
-	return resp.Embedding.Values, nil
-}
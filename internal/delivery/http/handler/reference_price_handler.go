@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine/handlers"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/pricing"
+	"github.com/gin-gonic/gin"
+)
+
+const maxCatalogUploadSize = 20 << 20
+
+type ReferencePriceHandler struct {
+	usecase  *pricing.ReferencePriceUsecase
+	verifier *handlers.GuardrailVerifier
+}
+
+func NewReferencePriceHandler(usecase *pricing.ReferencePriceUsecase, verifier *handlers.GuardrailVerifier) *ReferencePriceHandler {
+	return &ReferencePriceHandler{usecase: usecase, verifier: verifier}
+}
+
+// List godoc
+// @Summary      List reference prices
+// @Description  Lists the tenant's standard price catalog, optionally filtered by fiscal year, region, item code or text.
+// @Tags         reference-prices
+// @Produce      json
+// @Param        fiscal_year  query  int     false  "Fiscal year"
+// @Param        region       query  string  false  "Region"
+// @Param        item_code    query  string  false  "Item code"
+// @Param        q            query  string  false  "Description or code (partial match)"
+// @Param        limit        query  int     false  "Limit"
+// @Param        offset       query  int     false  "Offset"
+// @Success      200  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /api/v1/reference-prices [get]
+func (h *ReferencePriceHandler) List(c *gin.Context) {
+	q := referencePriceQuery(c)
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	q.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	prices, total, err := h.usecase.List(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": prices, "total": total})
+}
+
+// Lookup godoc
+// @Summary      Look up a reference price
+// @Description  Returns the best matching catalog entries for an item description or code, preferring the requested region and the latest fiscal year in effect.
+// @Tags         reference-prices
+// @Produce      json
+// @Param        q            query  string  false  "Item description"
+// @Param        item_code    query  string  false  "Item code"
+// @Param        region       query  string  false  "Region"
+// @Param        fiscal_year  query  int     false  "Fiscal year"
+// @Param        as_of        query  string  false  "Only entries effective on this date (YYYY-MM-DD)"
+// @Param        limit        query  int     false  "Limit"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/reference-prices/lookup [get]
+func (h *ReferencePriceHandler) Lookup(c *gin.Context) {
+	q := referencePriceQuery(c)
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if v := c.Query("as_of"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "as_of must be YYYY-MM-DD"})
+			return
+		}
+		q.AsOf = t
+	}
+
+	prices, err := h.usecase.Lookup(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if prices == nil {
+		prices = []*domain.ReferencePrice{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": prices})
+}
+
+// FiscalYears godoc
+// @Summary      List catalog fiscal years
+// @Tags         reference-prices
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /api/v1/reference-prices/fiscal-years [get]
+func (h *ReferencePriceHandler) FiscalYears(c *gin.Context) {
+	years, err := h.usecase.FiscalYears(c.Request.Context(), middleware.MustGetTenantIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if years == nil {
+		years = []int{}
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": years})
+}
+
+// Import godoc
+// @Summary      Import a reference price catalog
+// @Description  Imports a CSV or XLSX catalog. Columns: item_code/kode, description/uraian, unit/satuan, region/wilayah, max_price/harga, effective_date/tanggal_berlaku, fiscal_year/tahun_anggaran, source_regulation/dasar_hukum. Form fields supply defaults for missing columns.
+// @Tags         reference-prices
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file               formData  file    true   "CSV or XLSX file"
+// @Param        fiscal_year        formData  int     false  "Default fiscal year"
+// @Param        region             formData  string  false  "Default region"
+// @Param        source_regulation  formData  string  false  "Default source regulation"
+// @Param        effective_date     formData  string  false  "Default effective date (YYYY-MM-DD)"
+// @Param        replace            formData  bool    false  "Replace the fiscal year (and region) instead of merging"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/reference-prices/import [post]
+func (h *ReferencePriceHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCatalogUploadSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "file is required"})
+		return
+	}
+
+	opts := pricing.ImportOptions{
+		Region:           c.PostForm("region"),
+		SourceRegulation: c.PostForm("source_regulation"),
+		Replace:          c.PostForm("replace") == "true",
+	}
+	if v := c.PostForm("fiscal_year"); v != "" {
+		if opts.FiscalYear, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "fiscal_year must be a year"})
+			return
+		}
+	}
+	if v := c.PostForm("effective_date"); v != "" {
+		if opts.EffectiveDate, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "effective_date must be YYYY-MM-DD"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	result, err := h.usecase.Import(c.Request.Context(), tenantID, fileHeader.Filename, file, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pricing.ErrInvalidImport) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": result})
+}
+
+type PriceCheckRequest struct {
+	Text       string `json:"text" binding:"required"`
+	Region     string `json:"region"`
+	FiscalYear int    `json:"fiscal_year"`
+}
+
+// Check godoc
+// @Summary      Check text against the price catalog
+// @Description  Extracts item, quantity and price from free text and compares each item with the catalog (markup detection).
+// @Tags         reference-prices
+// @Accept       json
+// @Produce      json
+// @Param        request  body  PriceCheckRequest  true  "Text to check"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/reference-prices/check [post]
+func (h *ReferencePriceHandler) Check(c *gin.Context) {
+	var req PriceCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "text is required"})
+		return
+	}
+
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	result := h.verifier.Verify(c.Request.Context(), tenantID, req.Region, req.FiscalYear, req.Text)
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": result})
+}
+
+func referencePriceQuery(c *gin.Context) domain.ReferencePriceQuery {
+	q := domain.ReferencePriceQuery{
+		TenantID: middleware.MustGetTenantIDFromContext(c),
+		Text:     c.Query("q"),
+		ItemCode: c.Query("item_code"),
+		Region:   c.Query("region"),
+	}
+	q.FiscalYear, _ = strconv.Atoi(c.Query("fiscal_year"))
+	return q
+}
@@ -2,12 +2,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
 	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/report"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/swarm"
 	"github.com/gin-gonic/gin"
 )
@@ -27,6 +32,21 @@ func NewSwarmHandler(swarmUsecase *swarm.SwarmUsecase, redis cache.Cache) *Swarm
 type TriggerRequest struct {
 	DocumentID string                   `json:"document_id" binding:"required"`
 	Items      []map[string]interface{} `json:"items" binding:"required"`
+	Model      string                   `json:"model"`
+}
+
+type RerunRequest struct {
+	Model string                   `json:"model"`
+	Items []map[string]interface{} `json:"items"`
+}
+
+type OverrideRequest struct {
+	Verdict       string `json:"verdict" binding:"required"`
+	Justification string `json:"justification" binding:"required"`
+}
+
+type CorrectionRequest struct {
+	Justification string `json:"justification" binding:"required"`
 }
 
 func (h *SwarmHandler) Trigger(c *gin.Context) {
@@ -40,7 +60,7 @@ func (h *SwarmHandler) Trigger(c *gin.Context) {
 	user := middleware.MustGetUserFromContext(c)
 	userIDStr := user.ID.String()
 
-	task, err := h.swarmUsecase.TriggerSwarm(c.Request.Context(), req.DocumentID, req.Items, tenantIDStr, userIDStr)
+	task, err := h.swarmUsecase.TriggerSwarm(c.Request.Context(), req.DocumentID, req.Items, req.Model, tenantIDStr, userIDStr)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -61,6 +81,14 @@ func (h *SwarmHandler) Callback(c *gin.Context) {
 	}
 
 	if err := h.swarmUsecase.HandleCallback(c.Request.Context(), payload); err != nil {
+		if errors.Is(err, swarm.ErrHashMismatch) || errors.Is(err, swarm.ErrUnsupportedHashVersion) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, swarm.ErrTaskCancelled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -165,3 +193,389 @@ func (h *SwarmHandler) List(c *gin.Context) {
 	})
 }
 
+// Cancel godoc
+// @Summary      Cancel a swarm task
+// @Description  Cancels a task that has not finished. Queued work is dropped and later callbacks for the task are rejected.
+// @Tags         swarm
+// @Produce      json
+// @Param        id   path  string  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/tasks/{id}/cancel [post]
+func (h *SwarmHandler) Cancel(c *gin.Context) {
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	user := middleware.MustGetUserFromContext(c)
+
+	task, err := h.swarmUsecase.CancelTask(c.Request.Context(), tenantID, c.Param("id"), user.ID.String())
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+// Publish godoc
+// @Summary      Publish a swarm task
+// @Description  Makes a task with a ledger-confirmed result verifiable through the public endpoints, without an account.
+// @Tags         swarm
+// @Produce      json
+// @Param        id   path  string  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/tasks/{id}/publish [post]
+func (h *SwarmHandler) Publish(c *gin.Context) {
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	user := middleware.MustGetUserFromContext(c)
+
+	task, err := h.swarmUsecase.PublishTask(c.Request.Context(), tenantID, c.Param("id"), user.ID.String())
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+// Unpublish godoc
+// @Summary      Unpublish a swarm task
+// @Description  Withdraws a task from the public verification endpoints.
+// @Tags         swarm
+// @Produce      json
+// @Param        id   path  string  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/tasks/{id}/publish [delete]
+func (h *SwarmHandler) Unpublish(c *gin.Context) {
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+
+	task, err := h.swarmUsecase.UnpublishTask(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+// Rerun godoc
+// @Summary      Re-run a swarm task
+// @Description  Creates a new task linked to a finished one (parent_task_id) and sends it to the swarm again. Model and items default to the original task's.
+// @Tags         swarm
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string        true   "Task ID"
+// @Param        request  body  RerunRequest  false  "Model and items override"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/tasks/{id}/rerun [post]
+func (h *SwarmHandler) Rerun(c *gin.Context) {
+	var req RerunRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+			return
+		}
+	}
+
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	task, err := h.swarmUsecase.RerunTask(c.Request.Context(), tenantID, c.Param("id"), swarm.RerunOptions{
+		Model: req.Model,
+		Items: req.Items,
+	})
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": task})
+}
+
+// Override godoc
+// @Summary      Override a swarm verdict
+// @Description  Records a human reviewer's verdict and justification next to the machine verdict. If the machine result was anchored on-chain, the override is anchored through correctLog.
+// @Tags         swarm
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string           true  "Task ID"
+// @Param        request  body  OverrideRequest  true  "Reviewer verdict"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/tasks/{id}/override [post]
+func (h *SwarmHandler) Override(c *gin.Context) {
+	var req OverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "verdict and justification are required"})
+		return
+	}
+
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	user := middleware.MustGetUserFromContext(c)
+
+	task, err := h.swarmUsecase.OverrideVerdict(c.Request.Context(), tenantID, c.Param("id"), swarm.OverrideInput{
+		Verdict:       req.Verdict,
+		Justification: req.Justification,
+		ReviewerID:    user.ID.String(),
+	})
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+// LedgerHistory godoc
+// @Summary      List a task's on-chain history
+// @Description  Returns every log entry the ledger holds for the task, superseded ones included, with the submitter of each entry and, for corrections made through this service, who requested them and why.
+// @Tags         blockchain
+// @Produce      json
+// @Param        task_id  path  string  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/blockchain/history/{task_id} [get]
+func (h *SwarmHandler) LedgerHistory(c *gin.Context) {
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	history, err := h.swarmUsecase.LedgerHistory(c.Request.Context(), tenantID, c.Param("task_id"))
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": history})
+}
+
+// SubmitCorrection godoc
+// @Summary      Correct a task's on-chain log
+// @Description  Supersedes the task's active on-chain log with the hashes the task currently holds (the reviewer's, if overridden), e.g. after the reconciler flagged a mismatch. Requires the blockchain:correct permission and a justification, which is kept in the task's history.
+// @Tags         blockchain
+// @Accept       json
+// @Produce      json
+// @Param        task_id  path  string             true  "Task ID"
+// @Param        request  body  CorrectionRequest  true  "Justification"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/blockchain/correct/{task_id} [post]
+func (h *SwarmHandler) SubmitCorrection(c *gin.Context) {
+	var req CorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "justification is required"})
+		return
+	}
+
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	user := middleware.MustGetUserFromContext(c)
+
+	correction, err := h.swarmUsecase.SubmitCorrection(c.Request.Context(), tenantID, c.Param("task_id"), swarm.CorrectionInput{
+		Justification: req.Justification,
+		RequestedBy:   user.ID.String(),
+	})
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "success", "data": correction})
+}
+
+// Report godoc
+// @Summary      Export a swarm audit report
+// @Description  Renders the task's audit report (document metadata, per-item findings, agent rationale, reviewer decision and blockchain anchoring proof) as PDF or XLSX, branded with the tenant's name and logo.
+// @Tags         swarm
+// @Produce      application/pdf
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        id      path   string  true   "Task ID"
+// @Param        format  query  string  false  "pdf (default) or xlsx"
+// @Success      200  {file}  file
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/tasks/{id}/report [get]
+func (h *SwarmHandler) Report(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", report.FormatPDF))
+	if format != report.FormatPDF && format != report.FormatXLSX {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be pdf or xlsx"})
+		return
+	}
+
+	tenantID := middleware.MustGetTenantIDFromContext(c)
+	auditReport, err := h.swarmUsecase.BuildAuditReport(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		c.JSON(lifecycleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var (
+		data        []byte
+		contentType string
+	)
+	if format == report.FormatXLSX {
+		data, err = report.RenderXLSX(auditReport)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	} else {
+		data, err = report.RenderPDF(auditReport)
+		contentType = "application/pdf"
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("laporan-audit-%s.%s", auditReport.Task.ID, format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func lifecycleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, swarm.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, swarm.ErrInvalidTransition):
+		return http.StatusConflict
+	case errors.Is(err, swarm.ErrInvalidOverride), errors.Is(err, swarm.ErrInvalidCorrection):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListFindings godoc
+// @Summary      Query swarm findings
+// @Description  Returns normalized per-item findings across swarm tasks, filtered and paginated.
+// @Tags         swarm
+// @Produce      json
+// @Param        verdict        query  string  false  "Verdict (e.g. MARKUP) or 'flagged' for any non-passing verdict"
+// @Param        supplier       query  string  false  "Supplier name (partial match)"
+// @Param        q              query  string  false  "Item name or code (partial match)"
+// @Param        min_deviation  query  number  false  "Minimum deviation from the reference price, in percent"
+// @Param        max_deviation  query  number  false  "Maximum deviation from the reference price, in percent"
+// @Param        from           query  string  false  "Created on or after (YYYY-MM-DD or RFC3339)"
+// @Param        to             query  string  false  "Created on or before (YYYY-MM-DD or RFC3339)"
+// @Param        document_id    query  string  false  "Document ID"
+// @Param        task_id        query  string  false  "Swarm task ID"
+// @Param        sort           query  string  false  "newest (default), oldest, deviation, unit_price"
+// @Param        limit          query  int     false  "Limit"
+// @Param        offset         query  int     false  "Offset"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/findings [get]
+func (h *SwarmHandler) ListFindings(c *gin.Context) {
+	filter, err := parseFindingFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	findings, total, err := h.swarmUsecase.ListFindings(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   findings,
+		"total":  total,
+	})
+}
+
+// FindingStats godoc
+// @Summary      Aggregate swarm findings
+// @Description  Returns totals, counts per verdict, top suppliers and a monthly trend of findings. Accepts the same filters as the findings list.
+// @Tags         swarm
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/swarm/findings/stats [get]
+func (h *SwarmHandler) FindingStats(c *gin.Context) {
+	filter, err := parseFindingFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	stats, err := h.swarmUsecase.FindingStats(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": stats})
+}
+
+func parseFindingFilter(c *gin.Context) (domain.FindingFilter, error) {
+	filter := domain.FindingFilter{
+		TenantID:   middleware.MustGetTenantIDFromContext(c),
+		TaskID:     c.Query("task_id"),
+		DocumentID: c.Query("document_id"),
+		Verdict:    c.Query("verdict"),
+		Supplier:   c.Query("supplier"),
+		Search:     c.Query("q"),
+		SortBy:     c.Query("sort"),
+	}
+	if id := c.Param("id"); id != "" {
+		filter.TaskID = id
+	}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	filter.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	for name, dst := range map[string]**float64{
+		"min_deviation": &filter.MinDeviation,
+		"max_deviation": &filter.MaxDeviation,
+	} {
+		if v := c.Query(name); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %q", name, v)
+			}
+			*dst = &f
+		}
+	}
+
+	if v := c.Query("from"); v != "" {
+		t, _, err := parseDateParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %q", v)
+		}
+		filter.From = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, dateOnly, err := parseDateParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %q", v)
+		}
+		if dateOnly {
+			// "to=2026-09-30" includes the whole day.
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.To = &t
+	}
+
+	return filter, nil
+}
+
+func parseDateParam(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -9,15 +12,19 @@ import (
 	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 type TenantHandler struct {
 	db *gorm.DB
+	// ledgerProfiles are the configured ledger profile names a tenant's
+	// ledger_profile setting may take.
+	ledgerProfiles []string
 }
 
-func NewTenantHandler(db *gorm.DB) *TenantHandler {
-	return &TenantHandler{db: db}
+func NewTenantHandler(db *gorm.DB, ledgerProfiles []string) *TenantHandler {
+	return &TenantHandler{db: db, ledgerProfiles: ledgerProfiles}
 }
 
 type TenantTheme struct {
@@ -26,29 +33,37 @@ type TenantTheme struct {
 }
 
 type TenantJSONResponse struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	Slug        string       `json:"slug"`
-	Logo        string       `json:"logo,omitempty"`
-	Theme       *TenantTheme `json:"theme,omitempty"`
-	Features    []string     `json:"features"`
-	PlanTier    string       `json:"plan_tier"`
-	Status      string       `json:"status"`
-	HealthScore int          `json:"health_score"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug"`
+	Logo        string         `json:"logo,omitempty"`
+	Theme       *TenantTheme   `json:"theme,omitempty"`
+	Features    []string       `json:"features"`
+	PlanTier    string         `json:"plan_tier"`
+	Status      string         `json:"status"`
+	HealthScore int            `json:"health_score"`
+	Settings    datatypes.JSON `json:"settings,omitempty"`
 }
 
 func ToTenantJSON(t domain.Tenant) TenantJSONResponse {
 	slug := strings.ToLower(strings.ReplaceAll(t.Name, " ", "-"))
+	settings := t.ParsedSettings()
+	primaryColor := settings.PrimaryColor
+	if primaryColor == "" {
+		primaryColor = "#0284c7"
+	}
 	return TenantJSONResponse{
 		ID:          t.ID.String(),
 		Name:        t.Name,
 		Slug:        slug,
+		Logo:        settings.Logo,
 		Features:    []string{"workflows", "agents", "documents", "chat"},
 		PlanTier:    t.PlanTier,
 		Status:      t.Status,
 		HealthScore: t.HealthScore,
+		Settings:    t.Settings,
 		Theme: &TenantTheme{
-			PrimaryColor: "#0284c7",
+			PrimaryColor: primaryColor,
 			DarkMode:     true,
 		},
 	}
@@ -244,6 +259,8 @@ func (h *TenantHandler) GetMembers(c *gin.Context) {
 type UpdateTenantRequest struct {
 	Name     *string `json:"name"`
 	PlanTier *string `json:"plan_tier"`
+	// Settings is merged into the stored settings; a null value removes a key.
+	Settings map[string]interface{} `json:"settings"`
 }
 
 func (h *TenantHandler) UpdateTenant(c *gin.Context) {
@@ -276,6 +293,58 @@ func (h *TenantHandler) UpdateTenant(c *gin.Context) {
 	if req.PlanTier != nil {
 		tenant.PlanTier = *req.PlanTier
 	}
+	if req.Settings != nil {
+		if profile, ok := req.Settings["ledger_profile"]; ok && profile != nil {
+			name, isString := profile.(string)
+			if !isString || !slices.Contains(h.ledgerProfiles, name) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown ledger_profile, must be one of: %s", strings.Join(h.ledgerProfiles, ", "))})
+				return
+			}
+		}
+		if lang, ok := req.Settings["search_language"]; ok && lang != nil {
+			name, isString := lang.(string)
+			if !isString || !domain.ValidFTSLanguage(name) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown search_language, must be one of: %s", strings.Join(domain.FTSLanguages, ", "))})
+				return
+			}
+		}
+		if logo, ok := req.Settings["logo"]; ok && logo != nil {
+			src, isString := logo.(string)
+			if !isString || (!strings.HasPrefix(src, "data:") && !domain.IsTenantObjectKey(tenant.ID.String(), src)) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "logo must be a data URI or an object key under the tenant's storage"})
+				return
+			}
+		}
+		if raw, ok := req.Settings["chunking"]; ok && raw != nil {
+			var chunking domain.TenantChunking
+			data, _ := json.Marshal(raw)
+			if err := json.Unmarshal(data, &chunking); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chunking: " + err.Error()})
+				return
+			}
+			if err := chunking.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chunking: " + err.Error()})
+				return
+			}
+		}
+		merged := map[string]interface{}{}
+		if len(tenant.Settings) > 0 {
+			_ = json.Unmarshal(tenant.Settings, &merged)
+		}
+		for k, v := range req.Settings {
+			if v == nil {
+				delete(merged, k)
+			} else {
+				merged[k] = v
+			}
+		}
+		data, err := json.Marshal(merged)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tenant.Settings = datatypes.JSON(data)
+	}
 
 	if err := h.db.Save(&tenant).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -18,6 +18,7 @@ func SetupRoutes(
 	executionHandler *handler.ExecutionHandler,
 	documentHandler *handler.DocumentHandler,
 	ragSearchHandler *handler.RAGSearchHandler,
+	embeddingIndexHandler *handler.EmbeddingIndexHandler,
 	swarmHandler *handler.SwarmHandler,
 	dashboardHandler *handler.DashboardHandler,
 	chatHandler *handler.ChatHandler,
@@ -25,7 +26,10 @@ func SetupRoutes(
 	tenantHandler *handler.TenantHandler,
 	dataTypeHandler *handler.DataTypeHandler,
 	blockchainHandler *handler.BlockchainHandler,
+	auditLogHandler *handler.AuditLogHandler,
+	referencePriceHandler *handler.ReferencePriceHandler,
 	authMiddleware gin.HandlerFunc,
+	publicRateLimit gin.HandlerFunc,
 ) {
 	// Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -133,9 +137,18 @@ func SetupRoutes(
 				docs.POST("/confirm", documentHandler.ConfirmUpload)
 				docs.GET("", documentHandler.List)
 				docs.POST("/search", ragSearchHandler.Search)
+				docs.POST("/chunk-preview", documentHandler.PreviewChunks)
+				docs.GET("/embedding-indexes", embeddingIndexHandler.List)
+				docs.POST("/embedding-indexes", middleware.RequirePermission("documents:reindex"), embeddingIndexHandler.Start)
+				docs.POST("/embedding-indexes/rollback", middleware.RequirePermission("documents:reindex"), embeddingIndexHandler.Rollback)
+				docs.DELETE("/embedding-indexes/:id", middleware.RequirePermission("documents:reindex"), embeddingIndexHandler.Cancel)
 				docs.POST("/:id/approve", documentHandler.Approve)
 				docs.DELETE("/:id", documentHandler.Delete)
 				docs.PATCH("/:id/text", documentHandler.UpdateText)
+				docs.PATCH("/:id/metadata", documentHandler.UpdateMetadata)
+				docs.GET("/:id/versions", documentHandler.ListVersions)
+				docs.POST("/:id/versions", documentHandler.UploadVersion)
+				docs.GET("/:id/diff", documentHandler.DiffVersions)
 			}
 
 			// Swarm (Strict Multi-Tenancy Enforced for Trigger)
@@ -150,6 +163,15 @@ func SetupRoutes(
 					protectedSwarm.POST("/upload", swarmHandler.Trigger)
 					protectedSwarm.GET("/tasks", swarmHandler.List)
 					protectedSwarm.GET("/tasks/:id", swarmHandler.GetByID)
+					protectedSwarm.GET("/tasks/:id/findings", swarmHandler.ListFindings)
+					protectedSwarm.POST("/tasks/:id/cancel", swarmHandler.Cancel)
+					protectedSwarm.POST("/tasks/:id/rerun", swarmHandler.Rerun)
+					protectedSwarm.POST("/tasks/:id/override", swarmHandler.Override)
+					protectedSwarm.POST("/tasks/:id/publish", swarmHandler.Publish)
+					protectedSwarm.DELETE("/tasks/:id/publish", swarmHandler.Unpublish)
+					protectedSwarm.GET("/tasks/:id/report", swarmHandler.Report)
+					protectedSwarm.GET("/findings", swarmHandler.ListFindings)
+					protectedSwarm.GET("/findings/stats", swarmHandler.FindingStats)
 				}
 			}
 
@@ -159,6 +181,29 @@ func SetupRoutes(
 			{
 				blockchain.GET("/status/:task_id", blockchainHandler.GetStatus)
 				blockchain.GET("/verify/:task_id", blockchainHandler.Verify)
+				blockchain.GET("/history/:task_id", swarmHandler.LedgerHistory)
+				blockchain.POST("/correct/:task_id", middleware.RequirePermission("blockchain:correct"), swarmHandler.SubmitCorrection)
+				blockchain.GET("/audit-anchors", auditLogHandler.ListAnchors)
+				blockchain.GET("/audit-logs/:id/proof", auditLogHandler.Proof)
+			}
+
+			// Public Verification (no account; rate limited per client IP)
+			public := v1.Group("/public")
+			public.Use(publicRateLimit)
+			{
+				public.GET("/verify/:task_id", blockchainHandler.PublicVerify)
+				public.GET("/proof/:task_id", blockchainHandler.ProofBundle)
+			}
+
+			// Reference Price Catalog (Strict Multi-Tenancy Enforced)
+			referencePrices := v1.Group("/reference-prices")
+			referencePrices.Use(authMiddleware, middleware.TenantMiddleware())
+			{
+				referencePrices.GET("", referencePriceHandler.List)
+				referencePrices.GET("/lookup", referencePriceHandler.Lookup)
+				referencePrices.GET("/fiscal-years", referencePriceHandler.FiscalYears)
+				referencePrices.POST("/import", referencePriceHandler.Import)
+				referencePrices.POST("/check", referencePriceHandler.Check)
 			}
 
 			// Dashboard (Strict Multi-Tenancy Enforced)
@@ -169,6 +214,7 @@ func SetupRoutes(
 				dashboard.GET("/charts", dashboardHandler.GetChartData)
 				dashboard.GET("/audit-logs", dashboardHandler.GetAuditLogs)
 				dashboard.GET("/priority-queue", dashboardHandler.GetPriorityQueue)
+				dashboard.GET("/findings", swarmHandler.FindingStats)
 			}
 
 			// Activity Feed (Strict Multi-Tenancy Enforced)
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// AnchorBatch is one Merkle root anchored on the ledger on behalf of many
+// swarm tasks. The root is written with insertLog under the batch ID.
+type AnchorBatch struct {
+	ID         string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	MerkleRoot string `json:"merkle_root" gorm:"type:varchar(128);not null"`
+	Commitment string `json:"commitment" gorm:"type:varchar(128);not null"`
+	LeafCount  int    `json:"leaf_count" gorm:"not null"`
+	TxHash     string `json:"tx_hash" gorm:"type:varchar(128)"`
+	Network    string `json:"network" gorm:"type:varchar(50)"`
+	// LedgerProfile is the ledger the batch is anchored to; a batch only
+	// holds tasks of that profile.
+	LedgerProfile  string     `json:"ledger_profile" gorm:"type:varchar(64);not null;default:'default'"`
+	LedgerContract string     `json:"ledger_contract,omitempty" gorm:"type:varchar(255)"`
+	Status         string     `json:"status" gorm:"type:varchar(50);not null;default:'PENDING_COMMIT'"`
+	AnchoredAt     *time.Time `json:"anchored_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,7 +22,21 @@ type AuditLog struct {
 	CreatedAt    time.Time       `gorm:"primaryKey"` // Required for table partitioning
 }
 
+// ErrAuditPeriodAnchored is returned by AuditRepository.Create for an entry
+// created before the end of the tenant's latest anchored period. Such an
+// entry would never be anchored, so it is rejected.
+var ErrAuditPeriodAnchored = errors.New("audit log period is already anchored")
+
 // AuditRepository defines the interface for persisting audit logs cleanly.
 type AuditRepository interface {
+	// Create fails with ErrAuditPeriodAnchored for a backdated entry.
 	Create(ctx context.Context, audit *AuditLog) error
+	// GetByID returns nil when no entry has that ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*AuditLog, error)
+	// ListForPeriod returns a tenant's entries created in [from, to), ordered
+	// by (created_at, id), the order they are hash-chained in.
+	ListForPeriod(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*AuditLog, error)
+	// FirstCreatedAt returns the creation time of a tenant's earliest entry in
+	// [from, to), or nil when there is none.
+	FirstCreatedAt(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (*time.Time, error)
 }
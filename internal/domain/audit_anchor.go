@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit anchor statuses, following the anchor batch lifecycle.
+const (
+	AuditAnchorPendingCommit       = "PENDING_COMMIT"
+	AuditAnchorPendingConfirmation = "PENDING_CONFIRMATION"
+	AuditAnchorVerified            = "VERIFIED"
+	AuditAnchorFailed              = "FAILED"
+)
+
+// AuditAnchor is the head of the hash chain over one tenant's audit log
+// entries in [PeriodStart, PeriodEnd), anchored on the ledger under its ID
+// (see blockchain.AuditAnchorCommitment). PrevHead links it to the tenant's
+// previous anchor.
+type AuditAnchor struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TenantID    uuid.UUID `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	PeriodStart time.Time `json:"period_start" gorm:"not null"`
+	PeriodEnd   time.Time `json:"period_end" gorm:"not null"`
+	EntryCount  int       `json:"entry_count" gorm:"not null"`
+	PrevHead    string    `json:"prev_head" gorm:"type:varchar(128);not null"`
+	HeadHash    string    `json:"head_hash" gorm:"type:varchar(128);not null"`
+	Commitment  string    `json:"commitment" gorm:"type:varchar(128);not null"`
+	TxHash      string    `json:"tx_hash" gorm:"type:varchar(128)"`
+	Network     string    `json:"network" gorm:"type:varchar(50)"`
+	// LedgerProfile is the tenant's ledger profile when the anchor was
+	// sealed; the anchor is committed and verified on that ledger.
+	LedgerProfile  string     `json:"ledger_profile" gorm:"type:varchar(64);not null;default:'default'"`
+	LedgerContract string     `json:"ledger_contract,omitempty" gorm:"type:varchar(255)"`
+	Status         string     `json:"status" gorm:"type:varchar(50);not null;default:'PENDING_COMMIT'"`
+	AnchoredAt     *time.Time `json:"anchored_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// AuditAnchorRepository stores the audit log anchors.
+type AuditAnchorRepository interface {
+	Create(ctx context.Context, anchor *AuditAnchor) error
+	// Seal stores the anchor returned by build while no audit entry of the
+	// tenant can be inserted, so build sees every entry of the period and
+	// entries created before the anchor's end are rejected from then on.
+	Seal(ctx context.Context, tenantID uuid.UUID, build func() (*AuditAnchor, error)) (*AuditAnchor, error)
+	Update(ctx context.Context, anchor *AuditAnchor) error
+	// Latest returns the tenant's anchor with the latest period, or nil.
+	Latest(ctx context.Context, tenantID uuid.UUID) (*AuditAnchor, error)
+	// GetCovering returns the tenant's anchor whose period contains at, or nil.
+	GetCovering(ctx context.Context, tenantID uuid.UUID, at time.Time) (*AuditAnchor, error)
+	// ListUnconfirmed returns the tenant's anchors still waiting to be
+	// committed or confirmed, and those whose commit failed, oldest period
+	// first.
+	ListUnconfirmed(ctx context.Context, tenantID uuid.UUID) ([]*AuditAnchor, error)
+	// ListByTenant returns the tenant's anchors, latest period first.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID, limit int) ([]*AuditAnchor, error)
+	// ListTenants returns every tenant whose audit log is anchored.
+	ListTenants(ctx context.Context) ([]*Tenant, error)
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Statuses of a tracked ledger transaction.
+const (
+	TxStatusPending  = "PENDING"
+	TxStatusMined    = "MINED"
+	TxStatusReverted = "REVERTED"
+	// TxStatusReplaced marks an attempt superseded by a fee-bumped copy with
+	// the same nonce; ReplacedBy points to the newer attempt.
+	TxStatusReplaced = "REPLACED"
+	// TxStatusDropped marks an attempt whose nonce was consumed by another
+	// transaction that was not tracked.
+	TxStatusDropped = "DROPPED"
+)
+
+// BlockchainTransaction is one signed attempt sent to the ledger. Fee values
+// are decimal wei strings since they can exceed int64.
+type BlockchainTransaction struct {
+	ID                   string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TxHash               string    `json:"tx_hash" gorm:"type:varchar(128);uniqueIndex;not null"`
+	ChainID              int64     `json:"chain_id" gorm:"not null"`
+	FromAddress          string    `json:"from_address" gorm:"type:varchar(64);not null"`
+	ToAddress            string    `json:"to_address" gorm:"type:varchar(64)"`
+	Nonce                int64     `json:"nonce" gorm:"not null"`
+	Data                 []byte    `json:"-" gorm:"type:bytea"`
+	GasLimit             int64     `json:"gas_limit"`
+	MaxFeePerGas         string    `json:"max_fee_per_gas" gorm:"type:varchar(80)"`
+	MaxPriorityFeePerGas string    `json:"max_priority_fee_per_gas" gorm:"type:varchar(80)"`
+	Status               string    `json:"status" gorm:"type:varchar(20);not null;default:'PENDING'"`
+	ReplacedBy           string    `json:"replaced_by,omitempty" gorm:"type:varchar(128)"`
+	Attempt              int       `json:"attempt" gorm:"not null;default:1"`
+	BlockNumber          *int64    `json:"block_number,omitempty"`
+	SubmittedAt          time.Time `json:"submitted_at"`
+	CreatedAt            time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BlockchainTransactionRepository interface {
+	Create(ctx context.Context, tx *BlockchainTransaction) error
+	// ListStuck returns the pending attempts sent to contract toAddress on
+	// chainID before the given time, oldest first.
+	ListStuck(ctx context.Context, chainID int64, toAddress string, submittedBefore time.Time, limit int) ([]*BlockchainTransaction, error)
+	// Replace records a fee-bumped attempt and marks the previous one REPLACED, atomically.
+	Replace(ctx context.Context, oldHash string, replacement *BlockchainTransaction) error
+	// ReplacementChain returns hash followed by every attempt that replaced it, in order.
+	ReplacementChain(ctx context.Context, hash string) ([]string, error)
+	UpdateStatus(ctx context.Context, hash, status string, blockNumber *int64) error
+}
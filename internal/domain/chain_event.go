@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Reconciliation outcomes recorded on a chain event.
+const (
+	ChainEventMatched  = "MATCHED"
+	ChainEventMismatch = "MISMATCH"
+	// ChainEventUnknown is an event for a task or batch this database does not know.
+	ChainEventUnknown = "UNKNOWN"
+)
+
+// ChainEvent is an indexed AuditTrail event. Events are kept per block hash:
+// when a reorg replaces the block, Removed is set and the event no longer
+// counts towards verification.
+type ChainEvent struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Network        string     `json:"network" gorm:"type:varchar(50);not null"`
+	Contract       string     `json:"contract" gorm:"type:varchar(255);not null"`
+	EventName      string     `json:"event_name" gorm:"type:varchar(50);not null"`
+	EntryIndex     int64      `json:"entry_index" gorm:"not null"`
+	OldIndex       *int64     `json:"old_index,omitempty"`
+	TaskID         string     `json:"task_id" gorm:"type:varchar(255);not null;index"`
+	RationaleHash  string     `json:"rationale_hash,omitempty" gorm:"type:varchar(128)"`
+	ConsensusHash  string     `json:"consensus_hash,omitempty" gorm:"type:varchar(128)"`
+	TxHash         string     `json:"tx_hash" gorm:"type:varchar(128);not null"`
+	BlockNumber    int64      `json:"block_number" gorm:"not null"`
+	BlockHash      string     `json:"block_hash" gorm:"type:varchar(128);not null"`
+	LogIndex       int        `json:"log_index" gorm:"not null"`
+	Confirmations  int64      `json:"confirmations" gorm:"not null;default:0"`
+	Final          bool       `json:"final" gorm:"not null;default:false"`
+	Removed        bool       `json:"removed" gorm:"not null;default:false"`
+	Reconciliation string     `json:"reconciliation,omitempty" gorm:"type:varchar(20)"`
+	MismatchReason string     `json:"mismatch_reason,omitempty" gorm:"type:text"`
+	ReconciledAt   *time.Time `json:"reconciled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ChainCursor is how far the indexer has scanned a contract.
+type ChainCursor struct {
+	Network   string `json:"network" gorm:"primaryKey;type:varchar(50)"`
+	Contract  string `json:"contract" gorm:"primaryKey;type:varchar(255)"`
+	Block     int64  `json:"block" gorm:"not null"`
+	BlockHash string `json:"block_hash" gorm:"type:varchar(128)"`
+	// RecentHashes maps the block numbers of the last confirmation window to
+	// their hashes as scanned, so a reorg can be rewound to the common
+	// ancestor instead of the whole window.
+	RecentHashes datatypes.JSON `json:"-" gorm:"type:jsonb"`
+	UpdatedAt    time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type ChainEventRepository interface {
+	GetCursor(ctx context.Context, network, contract string) (*ChainCursor, error)
+	SaveCursor(ctx context.Context, cursor *ChainCursor) error
+	// Upsert inserts events, reviving any previously removed copy of the same log.
+	Upsert(ctx context.Context, events []*ChainEvent) error
+	// ListUnfinal returns live events that have not reached the confirmation depth.
+	ListUnfinal(ctx context.Context, network, contract string) ([]*ChainEvent, error)
+	Update(ctx context.Context, event *ChainEvent) error
+	// RemoveFrom marks every live event at or above block as removed by a
+	// reorg and clears its reconciliation, so it is reconciled again if the
+	// same block returns.
+	RemoveFrom(ctx context.Context, network, contract string, block int64) ([]*ChainEvent, error)
+	// ListUnreconciled returns final, live events not yet reconciled, in chain order.
+	ListUnreconciled(ctx context.Context, network, contract string, limit int) ([]*ChainEvent, error)
+	ListByTask(ctx context.Context, taskID string) ([]*ChainEvent, error)
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Chunking strategies selectable through ChunkingOptions.Strategy.
+const (
+	ChunkMarkdown       = "markdown"        // Header-aware word packing sized in characters; the default
+	ChunkToken          = "token"           // As markdown, sized in estimated tokens
+	ChunkSentenceWindow = "sentence_window" // Whole sentences, consecutive chunks sharing Overlap sentences
+)
+
+// ChunkStrategies lists the supported chunking strategies.
+var ChunkStrategies = []string{ChunkMarkdown, ChunkToken, ChunkSentenceWindow}
+
+// ChunkingOptions configures how a document is split into chunks. Zero
+// fields take the defaults of the strategy.
+type ChunkingOptions struct {
+	Strategy string `json:"strategy,omitempty"`
+	// MaxSize is the size limit of a chunk: estimated tokens for the token
+	// strategy, characters otherwise.
+	MaxSize int `json:"max_size,omitempty"`
+	// Overlap is how much of the end of a chunk the next one repeats: in
+	// sentences for sentence_window, in the unit of MaxSize otherwise.
+	Overlap int `json:"overlap,omitempty"`
+	// KeepTables emits every table as one chunk, whatever its size. By
+	// default tables larger than MaxSize are split into row groups that
+	// each repeat the header row.
+	KeepTables *bool `json:"keep_tables,omitempty"`
+}
+
+// Merge returns o overridden by the non-zero fields of override. Sizes are
+// not carried over to another strategy, whose unit may differ.
+func (o ChunkingOptions) Merge(override ChunkingOptions) ChunkingOptions {
+	if override.Strategy != "" && override.Strategy != o.Strategy {
+		o = ChunkingOptions{Strategy: override.Strategy, KeepTables: o.KeepTables}
+	}
+	if override.MaxSize > 0 {
+		o.MaxSize = override.MaxSize
+	}
+	if override.Overlap > 0 {
+		o.Overlap = override.Overlap
+	}
+	if override.KeepTables != nil {
+		o.KeepTables = override.KeepTables
+	}
+	return o
+}
+
+// Validate reports options no chunker accepts.
+func (o ChunkingOptions) Validate() error {
+	valid := o.Strategy == ""
+	for _, s := range ChunkStrategies {
+		valid = valid || o.Strategy == s
+	}
+	if !valid {
+		return fmt.Errorf("unknown chunking strategy %q, must be one of: %s", o.Strategy, strings.Join(ChunkStrategies, ", "))
+	}
+	if o.MaxSize < 0 || o.Overlap < 0 {
+		return fmt.Errorf("chunking max_size and overlap must not be negative")
+	}
+	if o.Strategy != ChunkSentenceWindow && o.MaxSize > 0 && o.Overlap >= o.MaxSize {
+		return fmt.Errorf("chunking overlap (%d) must be smaller than max_size (%d)", o.Overlap, o.MaxSize)
+	}
+	return nil
+}
+
+// TenantChunking is the chunking setting of a tenant: options for all its
+// documents, overridden per document category.
+type TenantChunking struct {
+	ChunkingOptions
+	Categories map[string]ChunkingOptions `json:"categories,omitempty"`
+}
+
+// Validate checks the tenant options and those of every category.
+func (t TenantChunking) Validate() error {
+	if err := t.ChunkingOptions.Validate(); err != nil {
+		return err
+	}
+	for category, opts := range t.Categories {
+		if err := opts.Validate(); err != nil {
+			return fmt.Errorf("category %s: %w", category, err)
+		}
+	}
+	return nil
+}
+
+// ChunkPreview shows how a text is split with the resolved options.
+type ChunkPreview struct {
+	DocumentID *uuid.UUID      `json:"document_id,omitempty"`
+	Category   string          `json:"category,omitempty"`
+	Options    ChunkingOptions `json:"options"`
+	Chunks     []PreviewChunk  `json:"chunks"`
+}
+
+// PreviewChunk is one chunk of a ChunkPreview. Tokens is an estimate;
+// CharStart and CharEnd are character offsets in the previewed text.
+type PreviewChunk struct {
+	Index      int    `json:"index"`
+	HeaderPath string `json:"header_path,omitempty"`
+	Content    string `json:"content"`
+	Characters int    `json:"characters"`
+	Tokens     int    `json:"tokens"`
+	CharStart  int    `json:"char_start"`
+	CharEnd    int    `json:"char_end"`
+}
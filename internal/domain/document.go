@@ -2,12 +2,21 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
+// ErrDocumentVersionExists is returned by DocumentRepository.Create when
+// another upload took the version number of the record first.
+var ErrDocumentVersionExists = errors.New("document version already exists")
+
+// ErrDocumentProcessing is returned when a document is being indexed and
+// cannot change until the worker is done.
+var ErrDocumentProcessing = errors.New("document is being indexed, retry when it is ready")
+
 type Document struct {
 	ID             uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
 	TenantID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
@@ -16,9 +25,20 @@ type Document struct {
 	Category       string         `gorm:"type:varchar(50);default:'general'" json:"category"`
 	SourceURI      string         `gorm:"type:text" json:"source_uri"`                      // S3 Key
 	Status         string         `gorm:"type:varchar(50);default:'pending'" json:"status"` // pending, processing, ready, failed
+	Language       string         `gorm:"type:varchar(20)" json:"language,omitempty"`       // FTS language; empty uses the tenant's search_language
 	AiAnalysisJSON datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"ai_analysis_json"`
+	Metadata       datatypes.JSON `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"` // User-defined key/values, matched by SearchFilter.Metadata
+	Revision       int            `gorm:"not null;default:1" json:"revision"`               // Bumped by every re-index that changes the chunks
 	CreatedAt      time.Time      `json:"created_at"`
 	LastUpdatedAt  time.Time      `json:"last_updated_at"`
+	// RecordID groups the versions (editions) of one logical document, e.g.
+	// the successive revisions of a budget regulation: the ID of its first
+	// version. Each version has its own file, staged text and chunks.
+	RecordID uuid.UUID `gorm:"type:uuid;not null;index" json:"record_id"`
+	Version  int       `gorm:"not null;default:1" json:"version"`
+	// SupersededAt is set once a newer version of the record is ready;
+	// searches skip superseded versions unless asked for them.
+	SupersededAt *time.Time `json:"superseded_at,omitempty"`
 }
 
 type DocumentChunk struct {
@@ -26,34 +46,150 @@ type DocumentChunk struct {
 	TenantID   uuid.UUID `gorm:"type:uuid;not null;index" json:"tenant_id"` // Untuk pre-filtering pgvector
 	DocumentID uuid.UUID `gorm:"type:uuid;not null;index" json:"document_id"`
 	Content    string    `gorm:"type:text;not null" json:"content"`
-	Embedding  []float32 `gorm:"type:vector(1536)" json:"-"` // pgvector integration
+	Embedding  []float32 `gorm:"type:vector" json:"-"` // pgvector integration, any dimension (see EmbeddingDim)
 	ChunkIndex int       `gorm:"not null" json:"chunk_index"`
 	PageNumber int       `gorm:"not null;default:1" json:"page_number"`
 	Category   string    `gorm:"type:varchar(50);default:'general'" json:"category"`
+	// PageNumber and PageEnd are the first and last page of the source file
+	// the chunk was taken from. PageEnd is 0 when the page is unknown (files
+	// without pages, chunks stored before pages were tracked).
+	PageEnd int `gorm:"not null;default:0" json:"page_end"`
+	// HeaderPath is the heading breadcrumb of the chunk, e.g. "BAB II > Pasal 5".
+	HeaderPath string `gorm:"type:text" json:"header_path"`
+	// CharStart and CharEnd are the character offsets of the chunk in the
+	// document's parsed text.
+	CharStart int `gorm:"not null;default:0" json:"char_start"`
+	CharEnd   int `gorm:"not null;default:0" json:"char_end"`
+	// Regions holds the []PageRegion of the layout elements the chunk covers.
+	Regions datatypes.JSON `gorm:"type:jsonb" json:"regions,omitempty"`
+	// EmbeddingModel and EmbeddingDim identify the vector space of Embedding;
+	// vectors of different models must never be compared.
+	EmbeddingModel string `gorm:"type:varchar(100);index" json:"embedding_model"`
+	EmbeddingDim   int    `json:"embedding_dim"`
+	// Language selects the text search configuration content_tsv is built
+	// with (one of FTSLanguages).
+	Language string `gorm:"type:varchar(20);not null;default:'indonesian'" json:"language"`
+	// Revision is the document revision the chunk was last indexed at.
+	Revision int `gorm:"not null;default:1" json:"revision"`
+}
+
+// ChunkSync is an incremental re-index of a document's chunks, applied
+// atomically: Removed chunks are deleted, Kept chunks (unchanged content)
+// keep their ID and embedding but take their new position, Added chunks are
+// inserted with fresh embeddings. The document moves to Revision.
+type ChunkSync struct {
+	DocumentID uuid.UUID
+	Revision   int
+	Added      []DocumentChunk
+	Kept       []DocumentChunk
+	Removed    []uuid.UUID
+}
+
+// Full-text search languages. Each names the PostgreSQL text search
+// configuration used for chunks of that language.
+const (
+	FTSIndonesian = "indonesian" // Snowball Indonesian stemmer; the default
+	FTSEnglish    = "english"
+	FTSSimple     = "simple" // No stemming: mixed-language or code-like text
+)
+
+// FTSLanguages lists the supported full-text search languages.
+var FTSLanguages = []string{FTSIndonesian, FTSEnglish, FTSSimple}
+
+// DefaultFTSLanguage is used when neither the document nor its tenant sets one.
+const DefaultFTSLanguage = FTSIndonesian
+
+// ValidFTSLanguage reports whether lang is one of FTSLanguages.
+func ValidFTSLanguage(lang string) bool {
+	for _, l := range FTSLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// EmbeddingModelUsage counts the chunks of a tenant embedded by one model.
+type EmbeddingModelUsage struct {
+	Model     string `json:"model"`
+	Dimension int    `json:"dimension"`
+	Chunks    int64  `json:"chunks"`
 }
 
 // DocumentRepository defines persistence operations for documents.
 type DocumentRepository interface {
 	Create(ctx context.Context, doc *Document) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string, metadata map[string]interface{}) error
+	// UpdateMetadata replaces the user-defined metadata of a document.
+	UpdateMetadata(ctx context.Context, id uuid.UUID, metadata datatypes.JSON) error
+	// StartProcessing moves a document that is not being indexed to
+	// "processing", or fails with ErrDocumentProcessing.
+	StartProcessing(ctx context.Context, id uuid.UUID) error
 	StoreChunks(ctx context.Context, chunks []DocumentChunk) error
+	// ListChunks returns the chunks of a document in order, without their embeddings.
+	ListChunks(ctx context.Context, documentID uuid.UUID) ([]DocumentChunk, error)
+	// SyncChunks applies an incremental re-index in a single transaction.
+	SyncChunks(ctx context.Context, sync ChunkSync) error
 	FindByTenant(ctx context.Context, tenantID string, limit, offset int) ([]*Document, int64, error)
 	FindByID(ctx context.Context, id string) (*Document, error)
 	Delete(ctx context.Context, tenantID, docID uuid.UUID) error
 	// HybridSearch performs tenant-scoped semantic (pgvector HNSW) + lexical (FTS) search
 	// and fuses results using Reciprocal Rank Fusion (RRF).
 	HybridSearch(ctx context.Context, params HybridSearchParams) ([]HybridSearchResult, error)
+	// EmbeddingModels lists the embedding models used by a tenant's chunks.
+	// More than one entry means the corpus mixes incompatible vectors.
+	EmbeddingModels(ctx context.Context, tenantID string) ([]EmbeddingModelUsage, error)
+	// TenantSettings returns the settings of the document's tenant, zero
+	// when the tenant is unknown.
+	TenantSettings(ctx context.Context, tenantID uuid.UUID) (TenantSettings, error)
+	// ListVersions returns the versions of a record, oldest first.
+	ListVersions(ctx context.Context, tenantID, recordID uuid.UUID) ([]*Document, error)
+	// MarkReady marks doc ready with metadata and supersedes the older
+	// versions of its record, atomically.
+	MarkReady(ctx context.Context, doc *Document, metadata map[string]interface{}) error
 }
 
 // HybridSearchParams carries all inputs for a hybrid RAG search query.
 type HybridSearchParams struct {
 	TenantID       string    // MANDATORY: all queries must be scoped to a tenant
 	QueryText      string    // Raw query from the user
-	QueryEmbedding []float32 // Query vector from Gemini text-embedding-004
+	QueryEmbedding []float32 // Query vector from the configured Embedder
+	EmbeddingModel string    // Model of QueryEmbedding; semantic search skips chunks of other models
 	TopK           int       // Number of final results after RRF fusion
 	EfSearch       int       // HNSW ef_search parameter (higher = more accurate, slower)
 	RRFConstant    int       // RRF constant k (default 60, per the original paper)
-	Category       string    // Optional Metadata filter field (e.g. "POJK", "PBI")
+	Filter         SearchFilter
+}
+
+// SearchFilter restricts a hybrid search to the chunks of matching documents.
+// It is applied inside both the vector and the FTS retrieval, so filtered-out
+// chunks never take a rank. Zero fields do not filter; set fields are ANDed.
+type SearchFilter struct {
+	Category       string                 `json:"category,omitempty"`        // Document category (e.g. "POJK", "PBI")
+	DocumentIDs    []uuid.UUID            `json:"document_ids,omitempty"`    // Only these documents
+	UploadedAfter  *time.Time             `json:"uploaded_after,omitempty"`  // Documents uploaded after this instant
+	UploadedBefore *time.Time             `json:"uploaded_before,omitempty"` // Documents uploaded before this instant
+	Statuses       []string               `json:"statuses,omitempty"`        // Document statuses (e.g. "ready")
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`        // Key/values the document's metadata must contain
+	// Only the latest version of every record is searched, unless
+	// AllVersions is set, a Version is asked for or DocumentIDs pins
+	// documents (which may be historical versions).
+	RecordID    *uuid.UUID `json:"record_id,omitempty"`    // Versions of this record only
+	Version     int        `json:"version,omitempty"`      // This version of the record(s), e.g. a historical one
+	AllVersions bool       `json:"all_versions,omitempty"` // Every version, superseded ones included
+}
+
+// IsZero reports whether the filter sets no field: it matches the latest
+// version of every document.
+func (f SearchFilter) IsZero() bool {
+	return f.Category == "" && len(f.DocumentIDs) == 0 && f.UploadedAfter == nil && f.UploadedBefore == nil && len(f.Statuses) == 0 && len(f.Metadata) == 0 &&
+		f.RecordID == nil && f.Version == 0 && !f.AllVersions
+}
+
+// LatestOnly reports whether the filter restricts the search to the latest
+// version of every record.
+func (f SearchFilter) LatestOnly() bool {
+	return !f.AllVersions && f.Version == 0 && len(f.DocumentIDs) == 0
 }
 
 // HybridSearchResult is a single fused result with lineage back to its source chunk and document.
@@ -65,14 +201,73 @@ type HybridSearchResult struct {
 	RRFScore      float64   `json:"rrf_score"`
 	VectorRank    int       `json:"vector_rank"`
 	FTSRank       int       `json:"fts_rank"`
+	// RerankScore is set when a reranker rescored the fused results; the
+	// results are then ordered by it instead of RRFScore.
+	RerankScore *float64 `json:"rerank_score,omitempty"`
+	// Citation locates the chunk in its source file.
+	Citation Citation `json:"citation"`
+}
+
+// PageRegion is the bounding box of a layout element on a page of the
+// source file, in points from the page's top-left corner.
+type PageRegion struct {
+	Page   int     `json:"page" bson:"page"`
+	Left   float64 `json:"left" bson:"left"`
+	Top    float64 `json:"top" bson:"top"`
+	Right  float64 `json:"right" bson:"right"`
+	Bottom float64 `json:"bottom" bson:"bottom"`
+}
+
+// LayoutElement is one element (heading, paragraph, table, ...) of a parsed
+// file. Start and End are the byte offsets of Text in the parsed Markdown.
+type LayoutElement struct {
+	Kind        string      `json:"kind" bson:"kind"`
+	Text        string      `json:"text" bson:"text"`
+	Page        int         `json:"page" bson:"page"` // 1-based; 0 when the file has no pages
+	Region      *PageRegion `json:"region,omitempty" bson:"region,omitempty"`
+	HeadingPath []string    `json:"heading_path,omitempty" bson:"heading_path,omitempty"`
+	Start       int         `json:"start" bson:"start"`
+	End         int         `json:"end" bson:"end"`
+}
+
+// Citation points at the passage a search result was taken from, so the UI
+// can open the source file at the right page and highlight it. CharStart and
+// CharEnd are character offsets in the document's parsed text; the page
+// fields are omitted for files without pages.
+type Citation struct {
+	DocumentID    uuid.UUID    `json:"document_id"`
+	DocumentTitle string       `json:"document_title"`
+	ChunkID       uuid.UUID    `json:"chunk_id"`
+	PageStart     int          `json:"page_start,omitempty"`
+	PageEnd       int          `json:"page_end,omitempty"`
+	HeaderPath    string       `json:"header_path,omitempty"`
+	CharStart     int          `json:"char_start"`
+	CharEnd       int          `json:"char_end"`
+	Regions       []PageRegion `json:"regions,omitempty"`
+	Revision      int          `json:"revision"` // Document revision the chunk was indexed at
+	Version       int          `json:"version"`  // Version of the document's record
 }
 
 // DocumentUsecase defines business logic for document lifecycle.
 type DocumentUsecase interface {
 	GetUploadURL(ctx context.Context, tenantID, userID uuid.UUID, fileName string) (presignedURL string, objectKey string, err error)
-	ConfirmUpload(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey string, category string) (*Document, error)
+	ConfirmUpload(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey string, category, language string, metadata map[string]interface{}) (*Document, error)
 	ListDocuments(ctx context.Context, tenantID string, limit, offset int) ([]*Document, int64, error)
 	Approve(ctx context.Context, tenantID, docID uuid.UUID) error
 	Delete(ctx context.Context, tenantID, docID uuid.UUID) error
 	UpdateText(ctx context.Context, tenantID, docID uuid.UUID, text string) error
+	// UpdateMetadata replaces the user-defined metadata of docID.
+	UpdateMetadata(ctx context.Context, tenantID, docID uuid.UUID, metadata map[string]interface{}) error
+	// UploadVersion registers objectKey as a new version of the record of
+	// docID and queues it for parsing. An empty title and nil metadata keep
+	// the record's.
+	UploadVersion(ctx context.Context, tenantID, userID, docID uuid.UUID, title, objectKey string, metadata map[string]interface{}) (*Document, error)
+	// ListVersions returns the versions of the record of docID, oldest first.
+	ListVersions(ctx context.Context, tenantID, docID uuid.UUID) ([]*Document, error)
+	// DiffVersions compares the staged text of two versions of the record of
+	// docID. Zero versions default to the latest and the one before it.
+	DiffVersions(ctx context.Context, tenantID, docID uuid.UUID, from, to int) (*TextDiff, error)
+	// PreviewChunks chunks text, or the staged text of docID when set, with
+	// the options its category resolves to, overridden by override.
+	PreviewChunks(ctx context.Context, tenantID uuid.UUID, docID *uuid.UUID, text, category string, override ChunkingOptions) (*ChunkPreview, error)
 }
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operations of a DiffLine.
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert" // Only in the newer version
+	DiffDelete = "delete" // Only in the older version
+)
+
+// TextDiff is the line diff between the staged texts of two versions of a
+// record, in hunks of changed lines with a few lines of context around them.
+type TextDiff struct {
+	RecordID uuid.UUID   `json:"record_id"`
+	From     DiffVersion `json:"from"`
+	To       DiffVersion `json:"to"`
+	Added    int         `json:"added"`   // Inserted lines
+	Removed  int         `json:"removed"` // Deleted lines
+	Hunks    []DiffHunk  `json:"hunks"`
+}
+
+// DiffVersion identifies one side of a TextDiff.
+type DiffVersion struct {
+	DocumentID uuid.UUID `json:"document_id"`
+	Version    int       `json:"version"`
+	Title      string    `json:"title"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DiffHunk is a run of changes. OldStart and NewStart are 1-based line
+// numbers in the older and newer text; OldLines and NewLines count the lines
+// of each the hunk spans, context included.
+type DiffHunk struct {
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// DiffLine is one line of a DiffHunk.
+type DiffLine struct {
+	Op   string `json:"op"` // DiffEqual, DiffInsert or DiffDelete
+	Text string `json:"text"`
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Embedding index statuses. A tenant has at most one ACTIVE and one
+// BUILDING index.
+const (
+	// EmbeddingIndexBuilding is being filled in the background; queries
+	// still use the ACTIVE index.
+	EmbeddingIndexBuilding = "BUILDING"
+	// EmbeddingIndexActive serves queries. Its vectors are the ones in
+	// document_chunks.embedding.
+	EmbeddingIndexActive = "ACTIVE"
+	// EmbeddingIndexStandby is the previously active index, kept in sync so
+	// that a migration can be rolled back.
+	EmbeddingIndexStandby   = "STANDBY"
+	EmbeddingIndexRetired   = "RETIRED"
+	EmbeddingIndexFailed    = "FAILED"
+	EmbeddingIndexCancelled = "CANCELLED"
+)
+
+// ErrEmbeddingIndexIncomplete is returned when activating an index that
+// still lacks vectors for some of the tenant's chunks.
+var ErrEmbeddingIndexIncomplete = errors.New("embedding index is incomplete")
+
+// EmbeddingIndex is one embedding of a tenant's whole corpus by one model.
+// Changing the model means building a new index next to the active one and
+// switching to it once complete.
+type EmbeddingIndex struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	TenantID       uuid.UUID  `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	Model          string     `json:"model" gorm:"type:varchar(100);not null"`
+	Dimension      int        `json:"dimension" gorm:"not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:'BUILDING'"`
+	TotalChunks    int64      `json:"total_chunks"`
+	EmbeddedChunks int64      `json:"embedded_chunks"`
+	Error          string     `json:"error,omitempty" gorm:"type:text"`
+	ActivatedAt    *time.Time `json:"activated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ChunkVector is the vector of one chunk in an embedding index.
+type ChunkVector struct {
+	ChunkID   uuid.UUID
+	Embedding []float32
+}
+
+// EmbeddingIndexRepository stores embedding indexes and their vectors.
+type EmbeddingIndexRepository interface {
+	Create(ctx context.Context, idx *EmbeddingIndex) error
+	Update(ctx context.Context, idx *EmbeddingIndex) error
+	// FindByID returns the index, or nil.
+	FindByID(ctx context.Context, id uuid.UUID) (*EmbeddingIndex, error)
+	// ListByTenant returns the tenant's indexes, newest first.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*EmbeddingIndex, error)
+	// Active returns the tenant's ACTIVE index, or nil.
+	Active(ctx context.Context, tenantID uuid.UUID) (*EmbeddingIndex, error)
+	// EnsureActive returns the tenant's ACTIVE index, creating one for the
+	// given model when the tenant has none yet.
+	EnsureActive(ctx context.Context, tenantID uuid.UUID, model string, dimension int) (*EmbeddingIndex, error)
+	// ListSecondary returns the tenant's BUILDING and STANDBY indexes, which
+	// new chunks are embedded into as well.
+	ListSecondary(ctx context.Context, tenantID uuid.UUID) ([]*EmbeddingIndex, error)
+	// CountChunks returns the number of chunks of the tenant.
+	CountChunks(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	// MissingChunks returns up to limit chunks of the index's tenant without
+	// a vector in the index; for the ACTIVE index, chunks embedded by
+	// another model.
+	MissingChunks(ctx context.Context, idx *EmbeddingIndex, limit int) ([]DocumentChunk, error)
+	CountMissing(ctx context.Context, idx *EmbeddingIndex) (int64, error)
+	// StoreVectors saves vectors into the index.
+	StoreVectors(ctx context.Context, idx *EmbeddingIndex, vectors []ChunkVector) error
+	// Activate atomically makes idx the tenant's ACTIVE index: its vectors
+	// replace document_chunks.embedding, the current ACTIVE index becomes
+	// STANDBY with its vectors kept, and an older STANDBY index is RETIRED.
+	// With requireComplete it fails with ErrEmbeddingIndexIncomplete while
+	// chunks are missing; otherwise those keep their current vector.
+	Activate(ctx context.Context, idx *EmbeddingIndex, requireComplete bool) error
+	// Discard marks a non-active index with status and drops its vectors.
+	Discard(ctx context.Context, idx *EmbeddingIndex, status string) error
+	// EnsureVectorIndex creates the HNSW index serving vectors of one
+	// dimension, rebuilding one left invalid by a failed build.
+	EnsureVectorIndex(ctx context.Context, dimension int) error
+}
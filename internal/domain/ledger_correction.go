@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Sources of a ledger correction.
+const (
+	// CorrectionSourceOverride is a reviewer override being anchored.
+	CorrectionSourceOverride = "OVERRIDE"
+	// CorrectionSourceManual is an auditor re-anchoring the task's current
+	// hashes because the ledger holds different ones.
+	CorrectionSourceManual = "MANUAL"
+)
+
+// LedgerCorrection records who superseded a task's on-chain log and why.
+// The chain only stores hashes; this is where the justification lives.
+type LedgerCorrection struct {
+	ID                    string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TaskID                string    `json:"task_id" gorm:"type:uuid;not null;index"`
+	Source                string    `json:"source" gorm:"type:varchar(20);not null"`
+	Justification         string    `json:"justification" gorm:"type:text;not null"`
+	RequestedBy           string    `json:"requested_by" gorm:"type:uuid;not null"`
+	PreviousRationaleHash string    `json:"previous_rationale_hash,omitempty" gorm:"type:varchar(128)"`
+	PreviousConsensusHash string    `json:"previous_consensus_hash,omitempty" gorm:"type:varchar(128)"`
+	RationaleHash         string    `json:"rationale_hash" gorm:"type:varchar(128);not null"`
+	ConsensusHash         string    `json:"consensus_hash" gorm:"type:varchar(128);not null"`
+	TxHash                string    `json:"tx_hash,omitempty" gorm:"type:varchar(128)"`
+	Network               string    `json:"network,omitempty" gorm:"type:varchar(50)"`
+	Status                string    `json:"status" gorm:"type:varchar(50);not null;default:'PENDING_COMMIT'"`
+	CreatedAt             time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt             time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type LedgerCorrectionRepository interface {
+	Create(ctx context.Context, correction *LedgerCorrection) error
+	GetByID(ctx context.Context, id string) (*LedgerCorrection, error)
+	Update(ctx context.Context, correction *LedgerCorrection) error
+	// ListByTask returns a task's corrections, oldest first.
+	ListByTask(ctx context.Context, taskID string) ([]*LedgerCorrection, error)
+}
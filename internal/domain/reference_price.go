@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ReferencePrice is one entry of a tenant's regional standard price catalog
+// (Standar Harga Satuan Regional / SSH). Entries are versioned by fiscal year:
+// importing a new year never touches the previous one.
+type ReferencePrice struct {
+	ID               string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TenantID         string    `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	ItemCode         string    `json:"item_code" gorm:"type:varchar(100);not null"`
+	Description      string    `json:"description" gorm:"type:text;not null"`
+	Unit             string    `json:"unit" gorm:"type:varchar(50)"`
+	Region           string    `json:"region" gorm:"type:varchar(150);not null;default:''"`
+	MaxPrice         float64   `json:"max_price" gorm:"type:numeric(20,2);not null"`
+	EffectiveDate    time.Time `json:"effective_date" gorm:"type:date;not null"`
+	FiscalYear       int       `json:"fiscal_year" gorm:"not null"`
+	SourceRegulation string    `json:"source_regulation" gorm:"type:text"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ReferencePriceQuery looks up catalog entries. Text is matched against the
+// item code and description; Region and FiscalYear narrow the search and fall
+// back to region-less entries and the latest fiscal year when empty.
+type ReferencePriceQuery struct {
+	TenantID   string
+	Text       string
+	ItemCode   string
+	Region     string
+	FiscalYear int
+	AsOf       time.Time
+	Limit      int
+	Offset     int
+}
+
+type ReferencePriceRepository interface {
+	// Upsert inserts or updates entries keyed by (tenant, fiscal year, region, item code).
+	Upsert(ctx context.Context, prices []*ReferencePrice) error
+	// DeleteScope removes every entry of a fiscal year (and region, when set).
+	DeleteScope(ctx context.Context, tenantID string, fiscalYear int, region string) error
+	List(ctx context.Context, q ReferencePriceQuery) ([]*ReferencePrice, int64, error)
+	// Lookup returns the best matching entries, best first.
+	Lookup(ctx context.Context, q ReferencePriceQuery) ([]*ReferencePrice, error)
+	FiscalYears(ctx context.Context, tenantID string) ([]int, error)
+}
@@ -1,32 +1,151 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/datatypes"
 )
 
+// Swarm task lifecycle statuses. PENDING and CANCELLED are set by the
+// backend; the others are reported by the swarm callback and normalized
+// with NormalizeSwarmStatus.
+const (
+	SwarmStatusPending    = "PENDING"
+	SwarmStatusProcessing = "PROCESSING"
+	SwarmStatusCompleted  = "COMPLETED"
+	SwarmStatusFailed     = "FAILED"
+	SwarmStatusCancelled  = "CANCELLED"
+)
+
+// swarmStatusAliases maps the statuses the swarm agent versions report to
+// the lifecycle statuses above.
+var swarmStatusAliases = map[string]string{
+	"QUEUED":      SwarmStatusPending,
+	"RUNNING":     SwarmStatusProcessing,
+	"IN_PROGRESS": SwarmStatusProcessing,
+	"COMPLETE":    SwarmStatusCompleted,
+	"DONE":        SwarmStatusCompleted,
+	"SUCCESS":     SwarmStatusCompleted,
+	"SUCCEEDED":   SwarmStatusCompleted,
+	"ERROR":       SwarmStatusFailed,
+	"CANCELED":    SwarmStatusCancelled,
+}
+
+// NormalizeSwarmStatus maps a status reported by the swarm to a lifecycle
+// status, ignoring case and surrounding whitespace. Unknown statuses are
+// returned upper-cased and are treated as non-terminal.
+func NormalizeSwarmStatus(status string) string {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	status = strings.NewReplacer("-", "_", " ", "_").Replace(status)
+	if alias, ok := swarmStatusAliases[status]; ok {
+		return alias
+	}
+	return status
+}
+
+// Anchoring statuses shared by a task's machine result (BlockchainStat), its
+// reviewer override (ReviewChainStat), anchor batches and ledger corrections.
+const (
+	BlockchainStatusPendingCommit       = "PENDING_COMMIT"
+	BlockchainStatusPendingConfirmation = "PENDING_CONFIRMATION"
+	BlockchainStatusVerified            = "VERIFIED"
+	BlockchainStatusFailed              = "FAILED"
+)
+
+// Blockchain statuses specific to Merkle-batched anchoring. A task waits in
+// PENDING_BATCH until a batch is sealed, is BATCHED while the batch root is
+// being anchored, and becomes VERIFIED once the root is confirmed.
+const (
+	BlockchainStatusPendingBatch = "PENDING_BATCH"
+	BlockchainStatusBatched      = "BATCHED"
+)
+
+// BlockchainStatusMismatch is set by the reconciler when the hashes in an
+// indexed chain event differ from the ones stored for the task.
+const BlockchainStatusMismatch = "MISMATCH"
+
 type SwarmTask struct {
 	ID             string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	DocumentID     string         `json:"document_id" gorm:"type:uuid;not null"`
 	Status         string         `json:"status" gorm:"type:varchar(50);not null;default:'PENDING'"`
 	Summary        string         `json:"summary" gorm:"type:text"`
 	Results        datatypes.JSON `json:"results" gorm:"type:jsonb"`
+	Transcript     datatypes.JSON `json:"transcript,omitempty" gorm:"type:jsonb"`
 	RationaleHash  string         `json:"rationale_hash" gorm:"type:varchar(128)"`
 	ConsensusHash  string         `json:"consensus_hash" gorm:"type:varchar(128)"`
+	HashVersion    int            `json:"hash_version" gorm:"not null;default:0"` // Canonical hashing spec of the hashes; 0 for hashes stored before versioning
 	BlockchainTx   string         `json:"blockchain_tx" gorm:"type:varchar(128)"`
 	BlockchainNet  string         `json:"blockchain_network" gorm:"type:varchar(50)"`
 	BlockchainStat string         `json:"blockchain_status" gorm:"type:varchar(50);default:'PENDING_COMMIT'"`
+
+	// Ledger the task is anchored to: the tenant's ledger profile when the
+	// task was first anchored, and that ledger's contract (or TSA URL).
+	// Verification and corrections always go to this ledger.
+	LedgerProfile  string `json:"ledger_profile" gorm:"type:varchar(64);not null;default:'default'"`
+	LedgerContract string `json:"ledger_contract,omitempty" gorm:"type:varchar(255)"`
+
+	// Lifecycle: the submitted items and model are kept so the task can be re-run.
+	Items        datatypes.JSON `json:"items,omitempty" gorm:"type:jsonb"`
+	Model        string         `json:"model,omitempty" gorm:"type:varchar(100)"`
+	ParentTaskID *string        `json:"parent_task_id,omitempty" gorm:"type:uuid"`
+	CancelledAt  *time.Time     `json:"cancelled_at,omitempty"`
+	CancelledBy  *string        `json:"cancelled_by,omitempty" gorm:"type:uuid"`
+
+	// Reviewer override, kept alongside the machine verdict (Summary/Results).
+	// When anchored, the review hashes supersede the machine hashes on-chain via correctLog.
+	ReviewVerdict       string     `json:"review_verdict,omitempty" gorm:"type:varchar(50)"`
+	ReviewJustification string     `json:"review_justification,omitempty" gorm:"type:text"`
+	ReviewedBy          *string    `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt          *time.Time `json:"reviewed_at,omitempty"`
+	ReviewRationaleHash string     `json:"review_rationale_hash,omitempty" gorm:"type:varchar(128)"`
+	ReviewConsensusHash string     `json:"review_consensus_hash,omitempty" gorm:"type:varchar(128)"`
+	ReviewTx            string     `json:"review_tx,omitempty" gorm:"type:varchar(128)"`
+	ReviewChainStat     string     `json:"review_blockchain_status,omitempty" gorm:"type:varchar(50)"`
+
+	// Batch membership: set when the task is anchored as a leaf of an AnchorBatch
+	// instead of its own log. MerkleProof is the path from the leaf to the root.
+	BatchID     *string        `json:"batch_id,omitempty" gorm:"type:uuid"`
+	LeafIndex   *int           `json:"leaf_index,omitempty"`
+	MerkleProof datatypes.JSON `json:"merkle_proof,omitempty" gorm:"type:jsonb"`
+
+	// Publication: a published task can be verified, and its proof bundle
+	// downloaded, by anyone through the unauthenticated /public endpoints.
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	PublishedBy *string    `json:"published_by,omitempty" gorm:"type:uuid"`
+
 	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
+// AnchoredHashes returns the hashes the ledger should currently hold as the
+// task's active log: the reviewer's once their correction is confirmed,
+// otherwise the machine's.
+func (t *SwarmTask) AnchoredHashes() (rationaleHash, consensusHash string) {
+	if t.ReviewChainStat == BlockchainStatusVerified && t.ReviewRationaleHash != "" {
+		return t.ReviewRationaleHash, t.ReviewConsensusHash
+	}
+	return t.RationaleHash, t.ConsensusHash
+}
+
+// IsTerminal reports whether the swarm will no longer report on the task.
+// Statuses stored before callbacks were normalized are normalized here.
+func (t *SwarmTask) IsTerminal() bool {
+	switch NormalizeSwarmStatus(t.Status) {
+	case SwarmStatusCompleted, SwarmStatusFailed, SwarmStatusCancelled:
+		return true
+	}
+	return false
+}
+
 type SwarmPayload struct {
 	TaskID       string                   `json:"task_id"`
 	DocumentID   string                   `json:"document_id"`
 	DocumentType string                   `json:"document_type"`
 	Items        []map[string]interface{} `json:"items"`
 	WebhookURL   string                   `json:"webhook_url"`
+	Model        string                   `json:"model,omitempty"`
+	ParentTaskID string                   `json:"parent_task_id,omitempty"`
 }
 
 type SwarmCallback struct {
@@ -36,11 +155,16 @@ type SwarmCallback struct {
 	Hashes     SwarmHashes              `json:"hashes"`
 	Blockchain BlockchainInfo           `json:"blockchain"`
 	Results    []map[string]interface{} `json:"results"`
+	// Transcript is the agents' debate log; it is what RationaleHash commits to.
+	Transcript []map[string]interface{} `json:"transcript"`
 }
 
 type SwarmHashes struct {
 	RationaleHash string `json:"rationale_hash"`
 	ConsensusHash string `json:"consensus_hash"`
+	// Version is the canonical hashing spec the swarm computed the hashes
+	// with (see blockchain.SwarmHashVersion).
+	Version int `json:"version"`
 }
 
 type BlockchainInfo struct {
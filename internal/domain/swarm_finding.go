@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// SwarmFinding is one line item of a swarm task's results, normalized so
+// findings can be queried across tasks (by verdict, supplier, deviation...).
+type SwarmFinding struct {
+	ID             string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TenantID       string         `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	TaskID         string         `json:"task_id" gorm:"type:uuid;not null;index"`
+	DocumentID     string         `json:"document_id" gorm:"type:uuid;not null"`
+	ItemIndex      int            `json:"item_index" gorm:"not null;default:0"`
+	ItemCode       string         `json:"item_code" gorm:"type:varchar(100)"`
+	ItemName       string         `json:"item_name" gorm:"type:text"`
+	Supplier       string         `json:"supplier" gorm:"type:varchar(255)"`
+	Unit           string         `json:"unit" gorm:"type:varchar(50)"`
+	Quantity       *float64       `json:"quantity" gorm:"type:numeric(20,4)"`
+	UnitPrice      *float64       `json:"unit_price" gorm:"type:numeric(20,2)"`
+	ReferencePrice *float64       `json:"reference_price" gorm:"type:numeric(20,2)"`
+	DeviationPct   *float64       `json:"deviation_pct" gorm:"type:numeric(10,2)"`
+	Verdict        string         `json:"verdict" gorm:"type:varchar(50)"`
+	Rationale      string         `json:"rationale" gorm:"type:text"`
+	AgentVotes     datatypes.JSON `json:"agent_votes" gorm:"type:jsonb"`
+	Raw            datatypes.JSON `json:"raw" gorm:"type:jsonb"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// FindingFilter narrows a findings query. Zero values mean "no filter".
+type FindingFilter struct {
+	TenantID     string
+	TaskID       string
+	DocumentID   string
+	Verdict      string
+	Supplier     string
+	Search       string
+	MinDeviation *float64
+	MaxDeviation *float64
+	From         *time.Time
+	To           *time.Time
+	SortBy       string
+	Limit        int
+	Offset       int
+}
+
+// FindingStats aggregates findings for the dashboard.
+type FindingStats struct {
+	TotalFindings     int64                `json:"total_findings"`
+	FlaggedFindings   int64                `json:"flagged_findings"`
+	AvgDeviationPct   float64              `json:"avg_deviation_pct"`
+	PotentialOverpaid float64              `json:"potential_overpaid"`
+	ByVerdict         []VerdictCount       `json:"by_verdict"`
+	TopSuppliers      []SupplierFindingAgg `json:"top_suppliers"`
+	Monthly           []MonthlyFindingAgg  `json:"monthly"`
+}
+
+type VerdictCount struct {
+	Verdict string `json:"verdict"`
+	Count   int64  `json:"count"`
+}
+
+type SupplierFindingAgg struct {
+	Supplier        string  `json:"supplier"`
+	Findings        int64   `json:"findings"`
+	Flagged         int64   `json:"flagged"`
+	AvgDeviationPct float64 `json:"avg_deviation_pct"`
+}
+
+type MonthlyFindingAgg struct {
+	Month   string `json:"month"`
+	Total   int64  `json:"total"`
+	Flagged int64  `json:"flagged"`
+}
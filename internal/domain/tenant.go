@@ -1,17 +1,73 @@
 package domain
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
 type Tenant struct {
-	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	Name         string    `gorm:"type:varchar(255);not null" json:"name"`
-	PlanTier     string    `gorm:"type:varchar(50);default:'free'" json:"plan_tier"`
-	Status       string    `gorm:"type:varchar(50);default:'active'" json:"status"`
-	HealthScore  int       `gorm:"default:100" json:"health_score"`
-	BillingCycle string    `gorm:"type:varchar(50);default:'monthly'" json:"billing_cycle"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name         string         `gorm:"type:varchar(255);not null" json:"name"`
+	PlanTier     string         `gorm:"type:varchar(50);default:'free'" json:"plan_tier"`
+	Status       string         `gorm:"type:varchar(50);default:'active'" json:"status"`
+	HealthScore  int            `gorm:"default:100" json:"health_score"`
+	BillingCycle string         `gorm:"type:varchar(50);default:'monthly'" json:"billing_cycle"`
+	Settings     datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"settings"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// TenantSettings is the typed view of Tenant.Settings.
+type TenantSettings struct {
+	// DisplayName is the formal name printed on reports (e.g. "Inspektorat Kota Bandung").
+	DisplayName string `json:"display_name,omitempty"`
+	// Logo is a data URI (data:image/png;base64,...) or the key of an
+	// object already uploaded to the tenant's storage (see IsTenantObjectKey).
+	Logo         string `json:"logo,omitempty"`
+	PrimaryColor string `json:"primary_color,omitempty"`
+	// LedgerProfile names the blockchain ledger profile the tenant's tasks
+	// and audit log are anchored to; empty is the default profile.
+	LedgerProfile string `json:"ledger_profile,omitempty"`
+	// SearchLanguage is the full-text search language of the tenant's
+	// documents that do not set one (see FTSLanguages).
+	SearchLanguage string `json:"search_language,omitempty"`
+	// Chunking overrides the configured chunking of the tenant's documents.
+	Chunking *TenantChunking `json:"chunking,omitempty"`
+}
+
+// ParsedSettings decodes Settings, returning zero values for a missing or
+// malformed document.
+func (t *Tenant) ParsedSettings() TenantSettings {
+	var s TenantSettings
+	if len(t.Settings) > 0 {
+		_ = json.Unmarshal(t.Settings, &s)
+	}
+	return s
+}
+
+// ReportName is the name printed on formal documents.
+func (t *Tenant) ReportName() string {
+	if s := t.ParsedSettings(); s.DisplayName != "" {
+		return s.DisplayName
+	}
+	return t.Name
+}
+
+// IsTenantObjectKey reports whether key names an object in the tenant's
+// storage area, i.e. has the form <area>/<tenant id>/<name> (as document
+// uploads under documents/<tenant id>/ do).
+func IsTenantObjectKey(tenantID, key string) bool {
+	parts := strings.Split(key, "/")
+	if len(parts) < 3 || parts[1] != tenantID {
+		return false
+	}
+	for _, p := range parts {
+		if p == "" || p == "." || p == ".." {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimestampLogActive is returned by TimestampTokenRepository.Create when
+// the log already has an active token.
+var ErrTimestampLogActive = errors.New("log already has an active timestamp token")
+
+// TimestampToken is an RFC 3161 timestamp token over a log's hashes, the
+// tsa backend's equivalent of an AuditTrail log entry. ID plays the role of
+// the contract's entry index; a correction supersedes the previous token of
+// the same LogID.
+type TimestampToken struct {
+	ID            int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	LogID         string `json:"log_id" gorm:"type:varchar(128);not null;index"`
+	RationaleHash string `json:"rationale_hash" gorm:"type:varchar(66);not null"`
+	ConsensusHash string `json:"consensus_hash" gorm:"type:varchar(66);not null"`
+	// MessageImprint is the SHA-256 the TSA signed (see TimestampMessage).
+	MessageImprint string `json:"message_imprint" gorm:"type:varchar(66);not null"`
+	Token          []byte `json:"-" gorm:"type:bytea;not null"`
+	// TokenHash is the SHA-256 of Token and stands in for a transaction hash.
+	TokenHash    string    `json:"token_hash" gorm:"type:varchar(66);uniqueIndex;not null"`
+	SerialNumber string    `json:"serial_number" gorm:"type:varchar(80)"`
+	GenTime      time.Time `json:"gen_time"`
+	TSAURL       string    `json:"tsa_url" gorm:"column:tsa_url;type:text"`
+	Supersedes   *int64    `json:"supersedes,omitempty"`
+	SupersededBy *int64    `json:"superseded_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TimestampTokenRepository stores the tokens of the tsa ledger backend.
+type TimestampTokenRepository interface {
+	// Create stores the first token of a log, or fails with
+	// ErrTimestampLogActive when the log already has an active token.
+	Create(ctx context.Context, token *TimestampToken) error
+	// Supersede stores token and marks oldID superseded by it, atomically.
+	// It fails if oldID is no longer active.
+	Supersede(ctx context.Context, oldID int64, token *TimestampToken) error
+	// GetActive returns the token of logID that has not been superseded, or
+	// nil when logID has none.
+	GetActive(ctx context.Context, logID string) (*TimestampToken, error)
+	// GetByTokenHash returns nil when no token has that hash.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*TimestampToken, error)
+	// ListByLogID returns every token of logID, oldest first.
+	ListByLogID(ctx context.Context, logID string) ([]*TimestampToken, error)
+}
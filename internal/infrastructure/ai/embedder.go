@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// Default models of the embedding backends
+const (
+	DefaultGeminiEmbeddingModel     = "text-embedding-004"
+	DefaultGeminiEmbeddingDimension = 768
+	DefaultHashEmbeddingModel       = "feature-hash"
+	DefaultHashEmbeddingDimension   = 1536
+
+	defaultEmbeddingBatchSize = 100
+)
+
+// ErrDimensionMismatch is returned when a backend produces vectors of a
+// different size than the embedder was configured for.
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
+// Embedder turns texts into vectors. Every vector has Dimension() values and
+// is only comparable with vectors of the same Model().
+type Embedder interface {
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Model() string
+	Dimension() int
+}
+
+// NewEmbedder builds the embedder selected by cfg.Embedding.
+func NewEmbedder(ctx context.Context, cfg config.AIConfig) (Embedder, error) {
+	return newEmbedder(ctx, cfg, cfg.Embedding)
+}
+
+// newEmbedder builds the embedder described by ec, taking API keys that ec
+// leaves empty from cfg.
+func newEmbedder(ctx context.Context, cfg config.AIConfig, ec config.EmbeddingConfig) (Embedder, error) {
+	batchSize := intOrDefault(ec.BatchSize, defaultEmbeddingBatchSize)
+	switch ec.Backend {
+	case "", config.EmbeddingBackendGemini:
+		if cfg.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("gemini embedding backend needs ai.gemini_api_key")
+		}
+		return NewGeminiEmbedder(ctx, cfg.GeminiAPIKey, orDefault(ec.Model, DefaultGeminiEmbeddingModel), intOrDefault(ec.Dimension, DefaultGeminiEmbeddingDimension), batchSize)
+	case config.EmbeddingBackendOpenAI:
+		apiKey := ec.APIKey
+		if apiKey == "" {
+			apiKey = cfg.OpenAIAPIKey
+		}
+		return NewOpenAIEmbedder(ec.URL, apiKey, ec.Model, ec.Dimension, batchSize, ec.Timeout), nil
+	case config.EmbeddingBackendHash:
+		return NewHashEmbedder(orDefault(ec.Model, DefaultHashEmbeddingModel), intOrDefault(ec.Dimension, DefaultHashEmbeddingDimension)), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding backend %q", ec.Backend)
+	}
+}
+
+// EmbedQuery embeds a single text, typically a search query.
+func EmbedQuery(ctx context.Context, e Embedder, text string) ([]float32, error) {
+	vectors, err := e.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// CloseEmbedder releases the resources held by an embedder, if any.
+func CloseEmbedder(e Embedder) {
+	if c, ok := e.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// checkVectors verifies that a backend returned one vector of the expected
+// dimension per text.
+func checkVectors(e Embedder, texts []string, vectors [][]float32) error {
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("%s returned %d embeddings for %d texts", e.Model(), len(vectors), len(texts))
+	}
+	for i, v := range vectors {
+		if len(v) != e.Dimension() {
+			return fmt.Errorf("%w: %s returned %d values for text %d, expected %d", ErrDimensionMismatch, e.Model(), len(v), i, e.Dimension())
+		}
+	}
+	return nil
+}
+
+// batches splits texts into consecutive slices of at most size texts.
+func batches(texts []string, size int) [][]string {
+	if size <= 0 {
+		size = len(texts)
+	}
+	var out [][]string
+	for start := 0; start < len(texts); start += size {
+		end := start + size
+		if end > len(texts) {
+			end = len(texts)
+		}
+		out = append(out, texts[start:end])
+	}
+	return out
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func intOrDefault(value, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// geminiMaxBatch is the most texts BatchEmbedContents accepts per call.
+const geminiMaxBatch = 100
+
+// GeminiEmbedder embeds texts with a Gemini embedding model. It holds one
+// client for its whole lifetime; call Close when done.
+type GeminiEmbedder struct {
+	client    *genai.Client
+	em        *genai.EmbeddingModel
+	model     string
+	dimension int
+	batchSize int
+}
+
+func NewGeminiEmbedder(ctx context.Context, apiKey, model string, dimension, batchSize int) (*GeminiEmbedder, error) {
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("Gemini client init failed: %w", err)
+	}
+	if batchSize <= 0 || batchSize > geminiMaxBatch {
+		batchSize = geminiMaxBatch
+	}
+	return &GeminiEmbedder{
+		client:    client,
+		em:        client.EmbeddingModel(model),
+		model:     model,
+		dimension: dimension,
+		batchSize: batchSize,
+	}, nil
+}
+
+func (g *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, chunk := range batches(texts, g.batchSize) {
+		batch := g.em.NewBatch()
+		for _, text := range chunk {
+			batch.AddContent(genai.Text(text))
+		}
+		resp, err := g.em.BatchEmbedContents(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("Gemini BatchEmbedContents failed: %w", err)
+		}
+		for _, e := range resp.Embeddings {
+			vectors = append(vectors, e.Values)
+		}
+	}
+	if err := checkVectors(g, texts, vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+func (g *GeminiEmbedder) Model() string  { return g.model }
+func (g *GeminiEmbedder) Dimension() int { return g.dimension }
+
+func (g *GeminiEmbedder) Close() error {
+	return g.client.Close()
+}
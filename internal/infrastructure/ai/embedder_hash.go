@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// HashEmbedder is a deterministic, offline embedder based on feature
+// hashing: each lower-cased word is hashed into one of Dimension() buckets
+// with a hashed sign, and the vector is L2-normalized. Texts sharing words
+// are close in cosine distance, which is enough for tests and air-gapped
+// development, but it captures no semantics.
+type HashEmbedder struct {
+	model     string
+	dimension int
+}
+
+func NewHashEmbedder(model string, dimension int) *HashEmbedder {
+	dimension = intOrDefault(dimension, DefaultHashEmbeddingDimension)
+	return &HashEmbedder{model: model, dimension: dimension}
+}
+
+func (h *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = h.embed(text)
+	}
+	return vectors, nil
+}
+
+func (h *HashEmbedder) embed(text string) []float32 {
+	vec := make([]float32, h.dimension)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, w := range words {
+		f := fnv.New64a()
+		_, _ = f.Write([]byte(w))
+		sum := f.Sum64()
+		bucket := sum % uint64(h.dimension)
+		if sum>>63 == 1 {
+			vec[bucket]--
+		} else {
+			vec[bucket]++
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vec
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= scale
+	}
+	return vec
+}
+
+func (h *HashEmbedder) Model() string  { return h.model }
+func (h *HashEmbedder) Dimension() int { return h.dimension }
@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OpenAIEmbedder calls an OpenAI-compatible POST {baseURL}/embeddings
+// endpoint: the OpenAI API itself or a local embedding server exposing the
+// same contract (e.g. text-embeddings-inference, Ollama, vLLM, LocalAI).
+type OpenAIEmbedder struct {
+	baseURL   string
+	apiKey    string
+	model     string
+	dimension int
+	batchSize int
+	client    *http.Client
+}
+
+func NewOpenAIEmbedder(baseURL, apiKey, model string, dimension, batchSize int, timeout time.Duration) *OpenAIEmbedder {
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &OpenAIEmbedder{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		apiKey:    apiKey,
+		model:     model,
+		dimension: dimension,
+		batchSize: batchSize,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (o *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, chunk := range batches(texts, o.batchSize) {
+		batch, err := o.embedBatch(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	if err := checkVectors(o, texts, vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+func (o *OpenAIEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	jsonBody, err := json.Marshal(openAIEmbeddingRequest{Model: o.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/embeddings", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("embedding api error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var embResp openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// The data array is not guaranteed to follow input order.
+	sort.SliceStable(embResp.Data, func(i, j int) bool { return embResp.Data[i].Index < embResp.Data[j].Index })
+	vectors := make([][]float32, len(embResp.Data))
+	for i, d := range embResp.Data {
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
+
+func (o *OpenAIEmbedder) Model() string  { return o.model }
+func (o *OpenAIEmbedder) Dimension() int { return o.dimension }
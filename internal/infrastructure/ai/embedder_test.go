@@ -0,0 +1,155 @@
+package ai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestHashEmbedder(t *testing.T) {
+	e := ai.NewHashEmbedder("feature-hash", 64)
+	vectors, err := e.Embed(context.Background(), []string{
+		"Modal minimum bank umum",
+		"modal MINIMUM bank umum!",
+		"laporan keuangan tahunan",
+		"",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vectors {
+		if len(v) != 64 {
+			t.Fatalf("vector %d has %d values, want 64", i, len(v))
+		}
+	}
+
+	if got := cosine(vectors[0], vectors[0]); math.Abs(got-1) > 1e-5 {
+		t.Errorf("vector is not normalized: |v|^2 = %f", got)
+	}
+	if got := cosine(vectors[0], vectors[1]); math.Abs(got-1) > 1e-5 {
+		t.Errorf("case and punctuation changed the vector: cos = %f", got)
+	}
+	if cosine(vectors[0], vectors[2]) >= cosine(vectors[0], vectors[1]) {
+		t.Error("unrelated text is as close as the same text")
+	}
+	if got := cosine(vectors[3], vectors[3]); got != 0 {
+		t.Errorf("empty text vector = %f, want zero", got)
+	}
+
+	again, _ := e.Embed(context.Background(), []string{"Modal minimum bank umum"})
+	for i := range again[0] {
+		if again[0][i] != vectors[0][i] {
+			t.Fatal("hash embedder is not deterministic")
+		}
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("path = %s, want /v1/embeddings", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Model != "bge-m3" {
+			t.Errorf("model = %q, want bge-m3", req.Model)
+		}
+		// Answer in reverse order; the embedder must sort by index.
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: []float32{float32(len(req.Input[i])), 0, 1}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer srv.Close()
+
+	e := ai.NewOpenAIEmbedder(srv.URL+"/v1/", "secret", "bge-m3", 3, 2, 0)
+	vectors, err := e.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 batches", requests)
+	}
+	for i, v := range vectors {
+		if v[0] != float32(i+1) {
+			t.Errorf("vector %d belongs to text of length %v", i, v[0])
+		}
+	}
+
+	wrong := ai.NewOpenAIEmbedder(srv.URL+"/v1", "secret", "bge-m3", 1024, 0, 0)
+	if _, err := wrong.Embed(context.Background(), []string{"a"}); !errors.Is(err, ai.ErrDimensionMismatch) {
+		t.Errorf("err = %v, want ErrDimensionMismatch", err)
+	}
+}
+
+func TestNewEmbedderDefaults(t *testing.T) {
+	e, err := ai.NewEmbedder(context.Background(), config.AIConfig{
+		Embedding: config.EmbeddingConfig{Backend: config.EmbeddingBackendHash},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Model() != ai.DefaultHashEmbeddingModel || e.Dimension() != ai.DefaultHashEmbeddingDimension {
+		t.Errorf("hash embedder = %s/%d", e.Model(), e.Dimension())
+	}
+
+	if _, err := ai.NewEmbedder(context.Background(), config.AIConfig{}); err == nil {
+		t.Error("gemini backend without an API key was accepted")
+	}
+}
+
+func TestNewEmbeddersSkipsDuplicateModels(t *testing.T) {
+	set, err := ai.NewEmbedders(context.Background(), config.AIConfig{
+		Embedding: config.EmbeddingConfig{
+			Backend: config.EmbeddingBackendHash,
+			Additional: []config.EmbeddingConfig{
+				{Backend: config.EmbeddingBackendHash, Model: "hash-small", Dimension: 16},
+				{Backend: config.EmbeddingBackendHash},
+			},
+		},
+	})
+	if err == nil {
+		t.Error("duplicate model was accepted")
+	}
+	if got := set.Models(); len(got) != 2 || got[0] != ai.DefaultHashEmbeddingModel || got[1] != "hash-small" {
+		t.Errorf("models = %v", got)
+	}
+	if _, err := set.Get("missing"); !errors.Is(err, ai.ErrUnknownEmbeddingModel) {
+		t.Errorf("err = %v, want ErrUnknownEmbeddingModel", err)
+	}
+
+	var none *ai.Embedders
+	if _, err := none.Default(); !errors.Is(err, ai.ErrNoEmbedder) {
+		t.Errorf("err = %v, want ErrNoEmbedder", err)
+	}
+}
@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+var (
+	// ErrNoEmbedder is returned when no embedder is configured at all.
+	ErrNoEmbedder = errors.New("no embedder is configured")
+	// ErrUnknownEmbeddingModel is returned for a model no configured
+	// embedder produces.
+	ErrUnknownEmbeddingModel = errors.New("unknown embedding model")
+)
+
+// TenantEmbedders resolves the embedder whose vector space a tenant's
+// corpus is currently indexed in. Queries must be embedded with it.
+type TenantEmbedders interface {
+	QueryEmbedder(ctx context.Context, tenantID string) (Embedder, error)
+}
+
+// Embedders is the set of configured embedders keyed by model: the default
+// one (config.AIConfig.Embedding) and the additional ones corpora can be
+// re-embedded with. A nil *Embedders means none is configured.
+type Embedders struct {
+	def     Embedder
+	byModel map[string]Embedder
+}
+
+// NewEmbedders builds every configured embedder. Embedders that fail are
+// left out and reported in the returned error; the set is nil only if the
+// default embedder failed.
+func NewEmbedders(ctx context.Context, cfg config.AIConfig) (*Embedders, error) {
+	def, err := NewEmbedder(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	set := &Embedders{def: def, byModel: map[string]Embedder{def.Model(): def}}
+	var errs []error
+	for i, ec := range cfg.Embedding.Additional {
+		e, err := newEmbedder(ctx, cfg, ec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("additional embedder %d: %w", i, err))
+			continue
+		}
+		if _, dup := set.byModel[e.Model()]; dup {
+			CloseEmbedder(e)
+			errs = append(errs, fmt.Errorf("additional embedder %d: model %s is configured twice", i, e.Model()))
+			continue
+		}
+		set.byModel[e.Model()] = e
+	}
+	return set, errors.Join(errs...)
+}
+
+// SingleEmbedder wraps one embedder as the default of a set.
+func SingleEmbedder(e Embedder) *Embedders {
+	if e == nil {
+		return nil
+	}
+	return &Embedders{def: e, byModel: map[string]Embedder{e.Model(): e}}
+}
+
+// Default returns the embedder of new corpora.
+func (s *Embedders) Default() (Embedder, error) {
+	if s == nil {
+		return nil, ErrNoEmbedder
+	}
+	return s.def, nil
+}
+
+// Get returns the embedder producing vectors of a model.
+func (s *Embedders) Get(model string) (Embedder, error) {
+	if s == nil {
+		return nil, ErrNoEmbedder
+	}
+	e, ok := s.byModel[model]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownEmbeddingModel, model)
+	}
+	return e, nil
+}
+
+// Models returns the models of the set, sorted.
+func (s *Embedders) Models() []string {
+	if s == nil {
+		return nil
+	}
+	models := make([]string, 0, len(s.byModel))
+	for model := range s.byModel {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// Close releases every embedder of the set.
+func (s *Embedders) Close() {
+	if s == nil {
+		return
+	}
+	for _, e := range s.byModel {
+		CloseEmbedder(e)
+	}
+}
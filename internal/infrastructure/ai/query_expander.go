@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// DefaultQueryExpansionModel is the Gemini model of the llm query expansion backend.
+const DefaultQueryExpansionModel = "gemini-2.5-flash"
+
+const defaultExpandedQueries = 3
+
+// QueryExpander rewrites a search query into reformulations that are
+// searched alongside it (multi-query retrieval).
+type QueryExpander interface {
+	// Expand returns up to n reformulations of query, without query
+	// itself; n <= 0 uses the expander's configured number.
+	Expand(ctx context.Context, query string, n int) ([]string, error)
+}
+
+// NewQueryExpander builds the expander selected by cfg.QueryExpansion. It
+// returns nil without error when expansion is disabled.
+func NewQueryExpander(cfg config.AIConfig) (QueryExpander, error) {
+	qc := cfg.QueryExpansion
+	switch qc.Backend {
+	case "":
+		return nil, nil
+	case config.QueryExpansionBackendLLM:
+		if cfg.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("llm query expansion backend needs ai.gemini_api_key")
+		}
+		return NewLLMQueryExpander(NewGeminiProvider(cfg.GeminiAPIKey), orDefault(qc.Model, DefaultQueryExpansionModel), intOrDefault(qc.MaxQueries, defaultExpandedQueries), qc.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown query expansion backend %q", qc.Backend)
+	}
+}
+
+// LLMQueryExpander asks a generative model for reformulations: synonyms,
+// the Indonesian or English counterpart and decomposed sub-questions.
+type LLMQueryExpander struct {
+	provider   Provider
+	model      string
+	maxQueries int
+	timeout    time.Duration
+}
+
+func NewLLMQueryExpander(provider Provider, model string, maxQueries int, timeout time.Duration) *LLMQueryExpander {
+	if timeout <= 0 {
+		timeout = 20 * time.Second
+	}
+	return &LLMQueryExpander{provider: provider, model: model, maxQueries: intOrDefault(maxQueries, defaultExpandedQueries), timeout: timeout}
+}
+
+func (l *LLMQueryExpander) Expand(ctx context.Context, query string, n int) ([]string, error) {
+	if n <= 0 {
+		n = l.maxQueries
+	}
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	out, err := l.provider.Generate(ctx, expansionPrompt(query, n), l.model)
+	if err != nil {
+		return nil, fmt.Errorf("query expansion failed: %w", err)
+	}
+	start, end := strings.Index(out, "["), strings.LastIndex(out, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("query expansion returned no query array: %q", out)
+	}
+	var queries []string
+	if err := json.Unmarshal([]byte(out[start:end+1]), &queries); err != nil {
+		return nil, fmt.Errorf("query expansion returned invalid JSON: %w", err)
+	}
+
+	// Drop blanks, repeats and the original query; keep at most n.
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	expanded := make([]string, 0, n)
+	for _, q := range queries {
+		q = strings.TrimSpace(q)
+		key := strings.ToLower(q)
+		if q == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		expanded = append(expanded, q)
+		if len(expanded) == n {
+			break
+		}
+	}
+	return expanded, nil
+}
+
+func expansionPrompt(query string, n int) string {
+	var sb strings.Builder
+	sb.WriteString("You rewrite search queries for a regulatory knowledge base of Indonesian regulations (POJK, PBI, Perda) and related English material.\n")
+	fmt.Fprintf(&sb, "Write up to %d alternative search queries that help retrieve passages answering the question below. Mix:\n", n)
+	sb.WriteString("- the same question with synonyms or the formal regulatory terms;\n")
+	sb.WriteString("- its translation (Indonesian to English or English to Indonesian);\n")
+	sb.WriteString("- the sub-questions it breaks down into, if it asks several things.\n")
+	sb.WriteString("Keep each query short and self-contained. Reply with only a JSON array of strings.\n\n")
+	fmt.Fprintf(&sb, "Question: %s\n", query)
+	return sb.String()
+}
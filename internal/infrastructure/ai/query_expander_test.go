@@ -0,0 +1,37 @@
+package ai_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+)
+
+func TestLLMQueryExpander(t *testing.T) {
+	provider := &staticProvider{reply: `Berikut kueri alternatif:
+["rasio KPMM minimum bank", "minimum capital adequacy ratio", "Rasio kecukupan modal minimum", "", "rasio KPMM minimum bank", "modal inti bank umum"]`}
+	e := ai.NewLLMQueryExpander(provider, "gemini-2.5-flash", 2, 0)
+
+	queries, err := e.Expand(context.Background(), "rasio kecukupan modal minimum", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"rasio KPMM minimum bank", "minimum capital adequacy ratio"}
+	if strings.Join(queries, "|") != strings.Join(want, "|") {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+	if !strings.Contains(provider.prompt, "up to 2 alternative") {
+		t.Errorf("prompt does not ask for the configured number:\n%s", provider.prompt)
+	}
+
+	queries, _ = e.Expand(context.Background(), "rasio kecukupan modal minimum", 5)
+	if len(queries) != 3 {
+		t.Errorf("queries = %q, want the 3 distinct reformulations", queries)
+	}
+
+	provider.reply = "tidak ada"
+	if _, err := e.Expand(context.Background(), "q", 3); err == nil {
+		t.Error("reply without a query array was accepted")
+	}
+}
@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// DefaultLLMRerankModel is the Gemini model of the llm rerank backend.
+const DefaultLLMRerankModel = "gemini-2.5-flash"
+
+// ErrRerankResponse is returned when a reranker does not score every
+// candidate.
+var ErrRerankResponse = errors.New("reranker returned an invalid response")
+
+// Reranker rescores search candidates against the query, typically with a
+// cross-encoder that reads query and passage together.
+type Reranker interface {
+	// Rerank returns one score per document, in input order; higher is
+	// more relevant. Scores are only comparable within one call.
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+	// Model identifies the reranker in responses and logs.
+	Model() string
+}
+
+// NewReranker builds the reranker selected by cfg.Rerank. It returns nil
+// without error when reranking is disabled.
+func NewReranker(cfg config.AIConfig) (Reranker, error) {
+	rc := cfg.Rerank
+	switch rc.Backend {
+	case "":
+		return nil, nil
+	case config.RerankBackendLLM:
+		if cfg.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("llm rerank backend needs ai.gemini_api_key")
+		}
+		return NewLLMReranker(NewGeminiProvider(cfg.GeminiAPIKey), orDefault(rc.Model, DefaultLLMRerankModel), rc.Timeout), nil
+	case config.RerankBackendHTTP:
+		return NewHTTPReranker(rc.URL, rc.APIKey, rc.Model, rc.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown rerank backend %q", rc.Backend)
+	}
+}
+
+// checkScores verifies that a reranker scored every document.
+func checkScores(documents []string, scores []float64) error {
+	if len(scores) != len(documents) {
+		return fmt.Errorf("%w: %d scores for %d documents", ErrRerankResponse, len(scores), len(documents))
+	}
+	return nil
+}
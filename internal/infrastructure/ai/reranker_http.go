@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPReranker calls a Cohere/Jina-compatible POST {baseURL}/rerank
+// endpoint, as served by local cross-encoder servers (e.g. Infinity, vLLM,
+// LocalAI) and hosted rerank APIs.
+type HTTPReranker struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+func NewHTTPReranker(baseURL, apiKey, model string, timeout time.Duration) *HTTPReranker {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &HTTPReranker{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+func (h *HTTPReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	jsonBody, err := json.Marshal(rerankRequest{Model: h.model, Query: query, Documents: documents})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.baseURL+"/rerank", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("rerank api error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var rerankResp rerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rerankResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Results come sorted by relevance, not in input order.
+	scores := make([]float64, len(documents))
+	seen := make([]bool, len(documents))
+	for _, r := range rerankResp.Results {
+		if r.Index < 0 || r.Index >= len(documents) || seen[r.Index] {
+			return nil, fmt.Errorf("%w: unexpected result index %d", ErrRerankResponse, r.Index)
+		}
+		scores[r.Index], seen[r.Index] = r.RelevanceScore, true
+	}
+	if err := checkScores(documents, scores[:len(rerankResp.Results)]); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+func (h *HTTPReranker) Model() string {
+	if h.model == "" {
+		return "http-reranker"
+	}
+	return h.model
+}
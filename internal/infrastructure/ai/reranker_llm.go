@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// llmRerankPassageLimit caps the characters of each passage in the scoring
+// prompt, keeping it bounded for long chunks.
+const llmRerankPassageLimit = 2000
+
+// LLMReranker asks a generative model to score every candidate against the
+// query in a single prompt. It needs no extra infrastructure, at the cost
+// of an LLM call per search.
+type LLMReranker struct {
+	provider Provider
+	model    string
+	timeout  time.Duration
+}
+
+func NewLLMReranker(provider Provider, model string, timeout time.Duration) *LLMReranker {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &LLMReranker{provider: provider, model: model, timeout: timeout}
+}
+
+func (l *LLMReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	out, err := l.provider.Generate(ctx, rerankPrompt(query, documents), l.model)
+	if err != nil {
+		return nil, fmt.Errorf("rerank generation failed: %w", err)
+	}
+	scores, err := parseRerankScores(out)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkScores(documents, scores); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+func (l *LLMReranker) Model() string { return l.model }
+
+func rerankPrompt(query string, documents []string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a relevance judge for a regulatory knowledge base (Indonesian regulations such as POJK, PBI and Perda; passages may be in Indonesian or English).\n")
+	sb.WriteString("Score how well each passage answers the question, from 0 (irrelevant) to 10 (directly and completely answers it). Judge the passage content only.\n")
+	fmt.Fprintf(&sb, "Reply with only a JSON array of %d numbers, one score per passage in the given order, e.g. [7, 0, 3.5].\n\n", len(documents))
+	fmt.Fprintf(&sb, "Question: %s\n", query)
+	for i, doc := range documents {
+		if r := []rune(doc); len(r) > llmRerankPassageLimit {
+			doc = string(r[:llmRerankPassageLimit]) + "…"
+		}
+		fmt.Fprintf(&sb, "\n[Passage %d]\n%s\n", i+1, doc)
+	}
+	return sb.String()
+}
+
+// parseRerankScores extracts the JSON array of scores from a model reply,
+// tolerating surrounding prose or a markdown code fence.
+func parseRerankScores(out string) ([]float64, error) {
+	start, end := strings.Index(out, "["), strings.LastIndex(out, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: no score array in %q", ErrRerankResponse, out)
+	}
+	var scores []float64
+	if err := json.Unmarshal([]byte(out[start:end+1]), &scores); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRerankResponse, err)
+	}
+	return scores, nil
+}
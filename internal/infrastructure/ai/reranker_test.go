@@ -0,0 +1,86 @@
+package ai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+)
+
+type staticProvider struct {
+	reply  string
+	prompt string
+}
+
+func (p *staticProvider) Generate(ctx context.Context, prompt string, model string) (string, error) {
+	p.prompt = prompt
+	return p.reply, nil
+}
+
+func TestLLMReranker(t *testing.T) {
+	provider := &staticProvider{reply: "```json\n[2, 9.5, 0]\n```"}
+	r := ai.NewLLMReranker(provider, "gemini-2.5-flash", 0)
+	scores, err := r.Rerank(context.Background(), "modal inti minimum", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 3 || scores[1] != 9.5 {
+		t.Errorf("scores = %v", scores)
+	}
+	if !strings.Contains(provider.prompt, "modal inti minimum") || !strings.Contains(provider.prompt, "[Passage 3]") {
+		t.Errorf("prompt misses the query or a passage:\n%s", provider.prompt)
+	}
+
+	provider.reply = "[1, 2]"
+	if _, err := r.Rerank(context.Background(), "q", []string{"a", "b", "c"}); !errors.Is(err, ai.ErrRerankResponse) {
+		t.Errorf("err = %v, want ErrRerankResponse", err)
+	}
+}
+
+func TestHTTPReranker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Model     string   `json:"model"`
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "bge-reranker-v2-m3" || req.Query != "q" {
+			t.Errorf("request = %+v", req)
+		}
+		// Sorted by relevance, as rerank servers answer.
+		_, _ = w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.1}]}`))
+	}))
+	defer srv.Close()
+
+	r := ai.NewHTTPReranker(srv.URL+"/", "secret", "bge-reranker-v2-m3", 0)
+	scores, err := r.Rerank(context.Background(), "q", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scores[0] != 0.1 || scores[1] != 0.9 {
+		t.Errorf("scores = %v, want input order", scores)
+	}
+	if _, err := r.Rerank(context.Background(), "q", []string{"a", "b", "c"}); !errors.Is(err, ai.ErrRerankResponse) {
+		t.Errorf("err = %v, want ErrRerankResponse", err)
+	}
+}
+
+func TestNewRerankerDisabled(t *testing.T) {
+	r, err := ai.NewReranker(config.AIConfig{})
+	if r != nil || err != nil {
+		t.Errorf("NewReranker() = %v, %v, want disabled", r, err)
+	}
+	if _, err := ai.NewReranker(config.AIConfig{Rerank: config.RerankConfig{Backend: config.RerankBackendLLM}}); err == nil {
+		t.Error("llm backend without an API key was accepted")
+	}
+}
@@ -0,0 +1,345 @@
+//go:build ignore
+
+// gen assembles AuditTrail.json, the AuditTrail contract deployed by the
+// simulated backend. It implements the contract's ABI (see AuditTrailABI)
+// directly in EVM bytecode, so the simulated backend needs no Solidity
+// toolchain. Storage follows Solidity's layout rules:
+//
+//	slot 0                      owner
+//	slot 1                      number of log entries
+//	keccak(addr . 2)            authorizedSubmitters[addr]
+//	keccak(index . 3) + 0..6    entry: rationaleHash, consensusHash,
+//	                            submitter, timestamp, blockNumber, status,
+//	                            supersededBy
+//	keccak(taskId . 4)          active entry index + 1 (0: none)
+//	keccak(taskId . 5)          history length; entries at keccak(slot) + i
+//
+// Run with: go run ./artifacts/gen.go artifacts/AuditTrail.json
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	slotOwner      = 0
+	slotCount      = 1
+	slotAuthorized = 2
+	slotEntries    = 3
+	slotActive     = 4
+	slotHistory    = 5
+
+	// Memory layout. The task ID is copied to memStr, right after its
+	// length, so events can be emitted from memEvent without re-encoding.
+	memLen    = 0x80
+	memIdx    = 0xa0
+	memOld    = 0xc0
+	memActive = 0xe0
+	memEvent  = 0x200
+	memStrLen = 0x260
+	memStr    = 0x280
+	memOut    = 0x400
+)
+
+// asm is a minimal assembler with forward jump labels. Jump targets are
+// always pushed with PUSH2, so code size does not depend on label values.
+type asm struct {
+	code   []byte
+	labels map[string]int
+	fixups map[int]string
+}
+
+func newAsm() *asm {
+	return &asm{labels: map[string]int{}, fixups: map[int]string{}}
+}
+
+func (a *asm) op(ops ...vm.OpCode) *asm {
+	for _, o := range ops {
+		a.code = append(a.code, byte(o))
+	}
+	return a
+}
+
+func (a *asm) push(v uint64) *asm {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	b := buf[:]
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return a.pushBytes(b)
+}
+
+func (a *asm) pushBytes(b []byte) *asm {
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(b)-1))
+	a.code = append(a.code, b...)
+	return a
+}
+
+func (a *asm) pushLabel(name string) *asm {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.fixups[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+func (a *asm) label(name string) *asm {
+	a.labels[name] = len(a.code)
+	return a.op(vm.JUMPDEST)
+}
+
+func (a *asm) jump(name string) *asm {
+	return a.pushLabel(name).op(vm.JUMP)
+}
+
+// jumpIf jumps when the value on top of the stack is non-zero.
+func (a *asm) jumpIf(name string) *asm {
+	return a.pushLabel(name).op(vm.JUMPI)
+}
+
+func (a *asm) bytes() []byte {
+	for at, name := range a.fixups {
+		dest, ok := a.labels[name]
+		if !ok {
+			panic("undefined label " + name)
+		}
+		binary.BigEndian.PutUint16(a.code[at:], uint16(dest))
+	}
+	return a.code
+}
+
+// mstore stores the value on top of the stack at offset.
+func (a *asm) mstore(offset uint64) *asm {
+	return a.push(offset).op(vm.MSTORE)
+}
+
+func (a *asm) mload(offset uint64) *asm {
+	return a.push(offset).op(vm.MLOAD)
+}
+
+// wordSlot replaces the key on top of the stack with keccak(key . slot),
+// the storage slot of mapping[key] for a value-typed key.
+func (a *asm) wordSlot(slot uint64) *asm {
+	a.mstore(0x00)
+	a.push(slot).mstore(0x20)
+	return a.push(0x40).push(0x00).op(vm.KECCAK256)
+}
+
+// loadTaskID copies the string argument at calldata word 0 to memStr and
+// its length to memLen and memStrLen.
+func (a *asm) loadTaskID() *asm {
+	a.push(4).op(vm.CALLDATALOAD).push(4).op(vm.ADD) // [p]
+	a.op(vm.DUP1, vm.CALLDATALOAD)                   // [p, len]
+	a.op(vm.DUP1).mstore(memLen)
+	a.op(vm.DUP1).mstore(memStrLen)
+	a.op(vm.SWAP1).push(32).op(vm.ADD) // [len, p+32]
+	return a.push(memStr).op(vm.CALLDATACOPY)
+}
+
+// taskSlot pushes keccak(taskId . slot), the storage slot of mapping[taskId].
+// It overwrites the word after the task ID, which lies beyond the padded
+// string emitted in events.
+func (a *asm) taskSlot(slot uint64) *asm {
+	a.push(slot).mload(memLen).push(memStr).op(vm.ADD, vm.MSTORE)
+	a.mload(memLen).push(32).op(vm.ADD)
+	return a.push(memStr).op(vm.KECCAK256)
+}
+
+// paddedLen pushes the task ID length rounded up to a multiple of 32.
+func (a *asm) paddedLen() *asm {
+	return a.mload(memLen).push(31).op(vm.ADD).push(5).op(vm.SHR).push(5).op(vm.SHL)
+}
+
+// activeEntry pushes the index + 1 of the task's active entry.
+func (a *asm) activeEntry() *asm {
+	a.taskSlot(slotActive)
+	a.op(vm.DUP1).mstore(memActive)
+	return a.op(vm.SLOAD)
+}
+
+func (a *asm) requireAuthorized() *asm {
+	a.op(vm.CALLER).wordSlot(slotAuthorized).op(vm.SLOAD, vm.ISZERO)
+	return a.jumpIf("revert")
+}
+
+// appendEntry stores a new active entry for the task with the hashes of
+// calldata words 1 and 2 and leaves its index in memIdx.
+func (a *asm) appendEntry() *asm {
+	a.push(slotCount).op(vm.SLOAD) // [idx]
+	a.op(vm.DUP1).mstore(memIdx)
+	a.op(vm.DUP1).push(1).op(vm.ADD).push(slotCount).op(vm.SSTORE)
+	a.wordSlot(slotEntries) // [base]
+	a.push(0x24).op(vm.CALLDATALOAD, vm.DUP2, vm.SSTORE)
+	a.push(0x44).op(vm.CALLDATALOAD, vm.DUP2).push(1).op(vm.ADD, vm.SSTORE)
+	a.op(vm.CALLER, vm.DUP2).push(2).op(vm.ADD, vm.SSTORE)
+	a.op(vm.TIMESTAMP, vm.DUP2).push(3).op(vm.ADD, vm.SSTORE)
+	a.op(vm.NUMBER, vm.DUP2).push(4).op(vm.ADD, vm.SSTORE)
+	a.op(vm.POP)
+
+	// active[taskId] = idx + 1
+	a.mload(memIdx).push(1).op(vm.ADD).mload(memActive).op(vm.SSTORE)
+
+	// history[taskId].push(idx)
+	a.taskSlot(slotHistory)                              // [h]
+	a.op(vm.DUP1, vm.SLOAD)                              // [h, n]
+	a.op(vm.DUP1).push(1).op(vm.ADD, vm.DUP3, vm.SSTORE) // [h, n]
+	a.op(vm.SWAP1).mstore(0x00)                          // [n]
+	a.push(0x20).push(0x00).op(vm.KECCAK256, vm.ADD)     // [n + keccak(h)]
+	return a.mload(memIdx).op(vm.SWAP1, vm.SSTORE)
+}
+
+// returnWord returns the value on top of the stack.
+func (a *asm) returnWord() *asm {
+	a.mstore(0x00)
+	return a.push(0x20).push(0x00).op(vm.RETURN)
+}
+
+func selector(sig string) uint64 {
+	return uint64(binary.BigEndian.Uint32(crypto.Keccak256([]byte(sig))[:4]))
+}
+
+func runtime() []byte {
+	a := newAsm()
+	methods := []struct {
+		sig   string
+		label string
+	}{
+		{"insertLog(string,bytes32,bytes32)", "insertLog"},
+		{"correctLog(string,bytes32,bytes32)", "correctLog"},
+		{"verifyHashes(string,bytes32,bytes32)", "verifyHashes"},
+		{"getActiveLog(string)", "getActiveLog"},
+		{"getTaskHistory(string)", "getTaskHistory"},
+		{"authorizedSubmitters(address)", "authorizedSubmitters"},
+		{"authorizeSubmitter(address)", "authorizeSubmitter"},
+	}
+
+	// Non-payable: reject value and short calldata.
+	a.op(vm.CALLVALUE).jumpIf("revert")
+	a.push(4).op(vm.CALLDATASIZE, vm.LT).jumpIf("revert")
+	a.push(0).op(vm.CALLDATALOAD).push(224).op(vm.SHR)
+	for _, m := range methods {
+		a.op(vm.DUP1).push(selector(m.sig)).op(vm.EQ).jumpIf(m.label)
+	}
+	a.label("revert").push(0).op(vm.DUP1, vm.REVERT)
+
+	a.label("insertLog").op(vm.POP)
+	a.requireAuthorized().loadTaskID()
+	a.activeEntry().jumpIf("revert")
+	a.appendEntry()
+	// emit LogInserted(index, taskId, rationaleHash, consensusHash)
+	a.push(0x60).mstore(memEvent)
+	a.push(0x24).op(vm.CALLDATALOAD).mstore(memEvent + 0x20)
+	a.push(0x44).op(vm.CALLDATALOAD).mstore(memEvent + 0x40)
+	a.mload(memIdx)
+	a.pushBytes(crypto.Keccak256([]byte("LogInserted(uint256,string,bytes32,bytes32)")))
+	a.paddedLen().push(0x80).op(vm.ADD).push(memEvent).op(vm.LOG2)
+	a.mload(memIdx).returnWord()
+
+	a.label("correctLog").op(vm.POP)
+	a.requireAuthorized().loadTaskID()
+	a.activeEntry() // [active]
+	a.op(vm.DUP1, vm.ISZERO).jumpIf("revert")
+	a.push(1).op(vm.SWAP1, vm.SUB).mstore(memOld)
+	a.appendEntry()
+	// entries[old].status = Superseded; entries[old].supersededBy = index
+	a.mload(memOld).wordSlot(slotEntries) // [base]
+	a.push(1).op(vm.DUP2).push(5).op(vm.ADD, vm.SSTORE)
+	a.mload(memIdx).op(vm.DUP2).push(6).op(vm.ADD, vm.SSTORE)
+	a.op(vm.POP)
+	// emit LogCorrected(oldIndex, newIndex, taskId)
+	a.push(0x20).mstore(memStrLen - 0x20)
+	a.mload(memIdx).mload(memOld)
+	a.pushBytes(crypto.Keccak256([]byte("LogCorrected(uint256,uint256,string)")))
+	a.paddedLen().push(0x40).op(vm.ADD).push(memStrLen - 0x20).op(vm.LOG3)
+	a.mload(memIdx).returnWord()
+
+	a.label("verifyHashes").op(vm.POP)
+	a.loadTaskID().activeEntry() // [active]
+	a.op(vm.DUP1, vm.ISZERO).jumpIf("returnFalse")
+	a.push(1).op(vm.SWAP1, vm.SUB).wordSlot(slotEntries) // [base]
+	a.op(vm.DUP1, vm.SLOAD).push(0x24).op(vm.CALLDATALOAD, vm.EQ)
+	a.op(vm.SWAP1).push(1).op(vm.ADD, vm.SLOAD).push(0x44).op(vm.CALLDATALOAD, vm.EQ)
+	a.op(vm.AND).returnWord()
+	a.label("returnFalse").op(vm.POP).push(0).returnWord()
+
+	a.label("getActiveLog").op(vm.POP)
+	a.loadTaskID().activeEntry() // [active]
+	a.op(vm.DUP1, vm.ISZERO).jumpIf("revert")
+	a.push(1).op(vm.SWAP1, vm.SUB).wordSlot(slotEntries) // [base]
+	for field := uint64(0); field < 7; field++ {
+		a.op(vm.DUP1).push(field).op(vm.ADD, vm.SLOAD).mstore(field * 0x20)
+	}
+	a.push(7 * 0x20).push(0x00).op(vm.RETURN)
+
+	a.label("getTaskHistory").op(vm.POP)
+	a.loadTaskID().taskSlot(slotHistory)     // [h]
+	a.op(vm.DUP1, vm.SLOAD)                  // [h, n]
+	a.op(vm.SWAP1).mstore(0x00)              // [n]
+	a.push(0x20).push(0x00).op(vm.KECCAK256) // [n, first]
+	a.push(0x20).mstore(memOut)
+	a.op(vm.DUP2).mstore(memOut + 0x20)
+	a.push(0) // [n, first, i]
+	a.label("historyLoop")
+	a.op(vm.DUP3, vm.DUP2, vm.LT, vm.ISZERO).jumpIf("historyDone")
+	a.op(vm.DUP1, vm.DUP3, vm.ADD, vm.SLOAD) // [n, first, i, value]
+	a.op(vm.DUP2).push(5).op(vm.SHL).push(memOut+0x40).op(vm.ADD, vm.MSTORE)
+	a.push(1).op(vm.ADD).jump("historyLoop")
+	a.label("historyDone").op(vm.POP, vm.POP) // [n]
+	a.push(5).op(vm.SHL).push(0x40).op(vm.ADD).push(memOut).op(vm.RETURN)
+
+	a.label("authorizedSubmitters").op(vm.POP)
+	a.push(4).op(vm.CALLDATALOAD).wordSlot(slotAuthorized).op(vm.SLOAD).returnWord()
+
+	a.label("authorizeSubmitter").op(vm.POP)
+	a.op(vm.CALLER).push(slotOwner).op(vm.SLOAD, vm.EQ, vm.ISZERO).jumpIf("revert")
+	a.push(1).push(4).op(vm.CALLDATALOAD).wordSlot(slotAuthorized).op(vm.SSTORE, vm.STOP)
+
+	return a.bytes()
+}
+
+// constructor makes the deployer the owner and first authorized submitter
+// and returns the runtime code appended to it.
+func constructor(runtimeCode []byte) []byte {
+	build := func(offset uint64) []byte {
+		a := newAsm()
+		a.op(vm.CALLER).push(slotOwner).op(vm.SSTORE)
+		a.push(1).op(vm.CALLER).wordSlot(slotAuthorized).op(vm.SSTORE)
+		a.push(uint64(len(runtimeCode))).op(vm.DUP1).pushBytes([]byte{byte(offset >> 8), byte(offset)}).push(0).op(vm.CODECOPY)
+		a.push(0).op(vm.RETURN)
+		return a.bytes()
+	}
+	// The offset is pushed as two bytes, so the size does not depend on it.
+	size := len(build(0))
+	return append(build(uint64(size)), runtimeCode...)
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run gen.go <artifact.json>")
+		os.Exit(2)
+	}
+	artifact := struct {
+		ContractName     string `json:"contractName"`
+		Bytecode         string `json:"bytecode"`
+		DeployedBytecode string `json:"deployedBytecode"`
+	}{ContractName: "AuditTrail"}
+	code := runtime()
+	artifact.Bytecode = hexutil.Encode(constructor(code))
+	artifact.DeployedBytecode = hexutil.Encode(code)
+
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(os.Args[1], append(data, '\n'), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,106 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// Audit log hash chain spec (v1).
+//
+// Each tenant's enterprise audit log is anchored period by period. The
+// entries of a period, ordered by (created_at, id), are folded into a hash
+// chain that starts at the head of the tenant's previous anchored period, so
+// removing a whole period also breaks the chain:
+//
+//	entry_hash = sha256(0x02 || JCS({"id", "tenant_id", "actor_id", "action",
+//	                                  "resource_type", "resource_id",
+//	                                  "context_ip", "evidence", "created_at"}))
+//	link_0     = previous period's head (AuditChainGenesis for the first)
+//	link_i     = sha256(0x03 || link_{i-1} || entry_hash_i)
+//	insertLog(anchor_id, head, commitment)
+//	commitment = sha256(JCS({"anchor_id", "tenant_id", "period_start",
+//	                         "period_end", "entry_count", "prev_head", "head"}))
+//
+// IDs are lowercase UUID strings, evidence is the stored JSON document and
+// hashes are lowercase hex without 0x. Timestamps are RFC 3339 in UTC with
+// nanosecond precision. The 0x02 and 0x03 prefixes keep entry hashes and
+// links apart from each other and from Merkle leaves and nodes.
+
+// AuditChainGenesis is the link a tenant's first anchored period starts from.
+var AuditChainGenesis = strings.Repeat("0", 2*sha256.Size)
+
+// AuditEntryHash returns the hash of one audit log entry.
+func AuditEntryHash(entry *domain.AuditLog) (string, error) {
+	var evidence interface{}
+	if len(entry.Evidence) > 0 {
+		evidence = json.RawMessage(entry.Evidence)
+	}
+	body, err := CanonicalJSON(map[string]interface{}{
+		"id":            entry.ID.String(),
+		"tenant_id":     entry.TenantID.String(),
+		"actor_id":      entry.ActorID.String(),
+		"action":        entry.Action,
+		"resource_type": entry.ResourceType,
+		"resource_id":   entry.ResourceID.String(),
+		"context_ip":    entry.ContextIP,
+		"evidence":      evidence,
+		"created_at":    entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte{0x02}, body...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ChainLink extends the chain ending in prev with entryHash.
+func ChainLink(prev, entryHash string) (string, error) {
+	p, err := hex.DecodeString(NormalizeHash(prev))
+	if err != nil || len(p) != sha256.Size {
+		return "", fmt.Errorf("invalid chain link")
+	}
+	e, err := hex.DecodeString(NormalizeHash(entryHash))
+	if err != nil || len(e) != sha256.Size {
+		return "", fmt.Errorf("invalid entry hash")
+	}
+	buf := make([]byte, 0, 1+2*sha256.Size)
+	buf = append(buf, 0x03)
+	buf = append(buf, p...)
+	buf = append(buf, e...)
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// HashChainHead folds entryHashes onto prev and returns the last link. With
+// no entries the head is prev itself.
+func HashChainHead(prev string, entryHashes []string) (string, error) {
+	head := NormalizeHash(prev)
+	for i, h := range entryHashes {
+		next, err := ChainLink(head, h)
+		if err != nil {
+			return "", fmt.Errorf("entry %d: %w", i, err)
+		}
+		head = next
+	}
+	return head, nil
+}
+
+// AuditAnchorCommitment is the second hash anchored with a period's head; it
+// binds the head to the tenant, the period and the entry count.
+func AuditAnchorCommitment(anchorID, tenantID string, periodStart, periodEnd time.Time, entryCount int, prevHead, head string) (string, error) {
+	return CanonicalHash(map[string]interface{}{
+		"anchor_id":    anchorID,
+		"tenant_id":    tenantID,
+		"period_start": periodStart.UTC().Format(time.RFC3339Nano),
+		"period_end":   periodEnd.UTC().Format(time.RFC3339Nano),
+		"entry_count":  entryCount,
+		"prev_head":    NormalizeHash(prevHead),
+		"head":         NormalizeHash(head),
+	})
+}
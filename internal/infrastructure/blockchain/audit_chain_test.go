@@ -0,0 +1,46 @@
+package blockchain_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/google/uuid"
+)
+
+func TestHashChainHead(t *testing.T) {
+	hashes := make([]string, 3)
+	for i := range hashes {
+		h, err := blockchain.AuditEntryHash(&domain.AuditLog{
+			ID:       uuid.New(),
+			Action:   fmt.Sprintf("ACTION_%d", i),
+			Evidence: json.RawMessage(`{"b": 1, "a": [true]}`),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes[i] = h
+	}
+
+	if head, err := blockchain.HashChainHead(blockchain.AuditChainGenesis, nil); err != nil || head != blockchain.AuditChainGenesis {
+		t.Errorf("empty chain head = %s, %v; want the previous head", head, err)
+	}
+
+	head, err := blockchain.HashChainHead(blockchain.AuditChainGenesis, hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A proof for entry 1 is the link before it plus the entries after it.
+	before, _ := blockchain.HashChainHead(blockchain.AuditChainGenesis, hashes[:1])
+	link, _ := blockchain.ChainLink(before, hashes[1])
+	if got, _ := blockchain.HashChainHead(link, hashes[2:]); got != head {
+		t.Errorf("head from proof = %s, want %s", got, head)
+	}
+
+	swapped := []string{hashes[1], hashes[0], hashes[2]}
+	if got, _ := blockchain.HashChainHead(blockchain.AuditChainGenesis, swapped); got == head {
+		t.Errorf("reordering entries should change the head")
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // AuditTrailABI is the ABI for the AuditTrail contract
@@ -30,47 +32,99 @@ const AuditTrailABI = `[
 	{"inputs":[{"internalType":"address","name":"submitter","type":"address"}],"name":"authorizeSubmitter","outputs":[],"stateMutability":"nonpayable","type":"function"}
 ]`
 
+// evmBackend is the subset of an Ethereum client the audit trail needs. It
+// is satisfied by *ethclient.Client and by the simulated backend's client.
+type evmBackend interface {
+	ethereum.ContractCaller
+	ethereum.PendingStateReader
+	ethereum.GasEstimator
+	ethereum.GasPricer
+	ethereum.GasPricer1559
+	ethereum.TransactionSender
+	ethereum.TransactionReader
+	ethereum.ChainIDReader
+	ethereum.LogFilterer
+	ethereum.BlockNumberReader
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
 // AuditTrailService wraps the blockchain interaction
 type AuditTrailService struct {
-	client     *ethclient.Client
-	contract   common.Address
-	privateKey *ecdsa.PrivateKey
-	abi        abi.ABI
-	network    string
+	client   evmBackend
+	contract common.Address
+	signers  *signerPool
+	abi      abi.ABI
+	network  string
+	closer   func()
+
+	nonces    NonceManager
+	txStore   domain.BlockchainTransactionRepository
+	maxFeeCap *big.Int // nil means no cap
 }
 
-// NewAuditTrailService creates a new blockchain service
+// NewAuditTrailService creates a new blockchain service signing with a
+// single hex key. An empty key gives a read-only service.
 func NewAuditTrailService(rpcURL, contractAddr, privateKeyHex, network string) (*AuditTrailService, error) {
+	var keys []*ecdsa.PrivateKey
+	if privateKeyHex != "" {
+		pk, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		keys = append(keys, pk)
+	}
+	return DialAuditTrailService(rpcURL, contractAddr, network, keys)
+}
+
+// DialAuditTrailService creates a blockchain service that signs with keys
+// in rotation (see LoadSubmitterKeys).
+func DialAuditTrailService(rpcURL, contractAddr, network string, keys []*ecdsa.PrivateKey) (*AuditTrailService, error) {
 	client, err := ethclient.Dial(rpcURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to blockchain: %w", err)
 	}
 
-	parsedABI, err := abi.JSON(strings.NewReader(AuditTrailABI))
+	s, err := newAuditTrailService(client, common.HexToAddress(contractAddr), keys, network)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+		client.Close()
+		return nil, err
 	}
+	s.closer = client.Close
+	return s, nil
+}
 
-	var pk *ecdsa.PrivateKey
-	if privateKeyHex != "" {
-		pk, err = crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse private key: %w", err)
-		}
+func newAuditTrailService(client evmBackend, contract common.Address, keys []*ecdsa.PrivateKey, network string) (*AuditTrailService, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(AuditTrailABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
 
 	return &AuditTrailService{
-		client:     client,
-		contract:   common.HexToAddress(contractAddr),
-		privateKey: pk,
-		abi:        parsedABI,
-		network:    network,
+		client:   client,
+		contract: contract,
+		signers:  newSignerPool(keys),
+		abi:      parsedABI,
+		network:  network,
+		nonces:   NewMemoryNonceManager(),
 	}, nil
 }
 
+// useDeps swaps in shared nonce allocation, transaction tracking and the fee
+// cap; nil dependencies keep the defaults.
+func (s *AuditTrailService) useDeps(deps LedgerDeps, maxFeeGwei int64) {
+	if deps.Nonces != nil {
+		s.nonces = deps.Nonces
+	}
+	s.txStore = deps.Transactions
+	if maxFeeGwei > 0 {
+		s.maxFeeCap = new(big.Int).Mul(big.NewInt(maxFeeGwei), big.NewInt(params.GWei))
+	}
+}
+
 // InsertLog submits a new audit log to the blockchain
 func (s *AuditTrailService) InsertLog(ctx context.Context, taskID, rationaleHash, consensusHash string) (string, error) {
-	if s.privateKey == nil {
+	if s.signers.empty() {
 		return "", fmt.Errorf("private key not configured")
 	}
 
@@ -87,6 +141,25 @@ func (s *AuditTrailService) InsertLog(ctx context.Context, taskID, rationaleHash
 	return txHash, nil
 }
 
+// CorrectLog supersedes the active log of a task with new hashes
+func (s *AuditTrailService) CorrectLog(ctx context.Context, oldTaskID, rationaleHash, consensusHash string) (string, error) {
+	if s.signers.empty() {
+		return "", fmt.Errorf("private key not configured")
+	}
+
+	data, err := s.abi.Pack("correctLog", oldTaskID, common.HexToHash(rationaleHash), common.HexToHash(consensusHash))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack correctLog: %w", err)
+	}
+
+	txHash, err := s.sendTransaction(ctx, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return txHash, nil
+}
+
 // VerifyHashes checks if the given hashes match the stored log
 func (s *AuditTrailService) VerifyHashes(ctx context.Context, taskID, rationaleHash, consensusHash string) (bool, error) {
 	data, err := s.abi.Pack("verifyHashes", taskID, common.HexToHash(rationaleHash), common.HexToHash(consensusHash))
@@ -140,9 +213,13 @@ func (s *AuditTrailService) GetActiveLog(ctx context.Context, taskID string) (ma
 		SupersededBy  *big.Int
 	}
 
-	if err := s.abi.UnpackIntoInterface(&unpacked, "getActiveLog", result); err != nil {
+	// A single tuple output is unpacked as an anonymous struct, which
+	// UnpackIntoInterface cannot copy into a named one.
+	out, err := s.abi.Unpack("getActiveLog", result)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unpack result: %w", err)
 	}
+	abi.ConvertType(out[0], &unpacked)
 
 	return map[string]interface{}{
 		"rationale_hash": fmt.Sprintf("0x%x", unpacked.RationaleHash),
@@ -155,38 +232,47 @@ func (s *AuditTrailService) GetActiveLog(ctx context.Context, taskID string) (ma
 	}, nil
 }
 
-// sendTransaction builds and sends a raw transaction
+// sendTransaction signs and sends a call to the contract with the next
+// submitter key in rotation.
 func (s *AuditTrailService) sendTransaction(ctx context.Context, data []byte) (string, error) {
-	publicKey := s.privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return "", fmt.Errorf("failed to cast public key")
-	}
+	return s.sendTransactionFrom(ctx, s.signers.pick(), data)
+}
 
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+// sendTransactionFrom signs and sends a call to the contract. The gas limit
+// is estimated (so a call that would revert fails here instead of on-chain),
+// fees follow EIP-1559 when the chain supports it, and the nonce comes from
+// the NonceManager so concurrent workers never collide.
+func (s *AuditTrailService) sendTransactionFrom(ctx context.Context, pk *ecdsa.PrivateKey, data []byte) (string, error) {
+	from := crypto.PubkeyToAddress(pk.PublicKey)
 
-	nonce, err := s.client.PendingNonceAt(ctx, fromAddress)
+	chainID, err := s.client.ChainID(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
+		return "", fmt.Errorf("failed to get chain ID: %w", err)
 	}
 
-	gasPrice, err := s.client.SuggestGasPrice(ctx)
+	gasLimit, err := s.client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &s.contract, Data: data})
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %w", err)
+		return "", fmt.Errorf("gas estimation failed (the call would revert): %w", err)
 	}
+	gasLimit = gasLimit * (100 + gasLimitHeadroomPercent) / 100
 
-	gasLimit := uint64(300000)
-
-	value := big.NewInt(0)
-
-	tx := types.NewTransaction(nonce, s.contract, value, gasLimit, gasPrice, data)
+	fees, err := s.suggestFees(ctx)
+	if err != nil {
+		return "", err
+	}
 
-	chainID, err := s.client.NetworkID(ctx)
+	pending, err := s.client.PendingNonceAt(ctx, from)
 	if err != nil {
-		return "", fmt.Errorf("failed to get chain ID: %w", err)
+		return "", fmt.Errorf("failed to get nonce: %w", err)
 	}
+	nonce, release, err := s.nonces.Acquire(ctx, chainID.Uint64(), from, pending)
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate nonce: %w", err)
+	}
+	used := false
+	defer func() { release(used) }()
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), s.privateKey)
+	signedTx, err := types.SignTx(fees.tx(chainID, nonce, gasLimit, s.contract, data), types.LatestSignerForChainID(chainID), pk)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
@@ -194,23 +280,36 @@ func (s *AuditTrailService) sendTransaction(ctx context.Context, data []byte) (s
 	if err := s.client.SendTransaction(ctx, signedTx); err != nil {
 		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
+	used = true
 
+	s.track(ctx, signedTx, from)
 	return signedTx.Hash().Hex(), nil
 }
 
-// WaitForConfirmation waits for a transaction to be mined
+// WaitForConfirmation waits for a transaction to be mined. When the
+// transaction was replaced by a fee bump, the receipt of whichever attempt
+// was mined is returned; callers should record receipt.TxHash.
 func (s *AuditTrailService) WaitForConfirmation(ctx context.Context, txHash string, timeout time.Duration) (*types.Receipt, error) {
-	hash := common.HexToHash(txHash)
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	for {
-		receipt, err := s.client.TransactionReceipt(ctx, hash)
-		if err == nil {
-			return receipt, nil
+		hashes := []string{txHash}
+		if s.txStore != nil {
+			if chain, err := s.txStore.ReplacementChain(ctx, txHash); err == nil {
+				hashes = chain
+			}
 		}
-		if err != ethereum.NotFound {
-			return nil, err
+
+		for _, h := range hashes {
+			receipt, err := s.client.TransactionReceipt(ctx, common.HexToHash(h))
+			if err == nil {
+				s.recordReceipt(ctx, receipt)
+				return receipt, nil
+			}
+			if err != ethereum.NotFound {
+				return nil, err
+			}
 		}
 
 		select {
@@ -222,9 +321,19 @@ func (s *AuditTrailService) WaitForConfirmation(ctx context.Context, txHash stri
 	}
 }
 
+// Network returns the configured network name (e.g. "sepolia")
+func (s *AuditTrailService) Network() string {
+	return s.network
+}
+
+// ContractAddress returns the audit trail contract address
+func (s *AuditTrailService) ContractAddress() string {
+	return s.contract.Hex()
+}
+
 // Close closes the blockchain client connection
 func (s *AuditTrailService) Close() {
-	if s.client != nil {
-		s.client.Close()
+	if s.closer != nil {
+		s.closer()
 	}
 }
@@ -0,0 +1,304 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Canonical hashing spec for swarm results, version SwarmHashVersion.
+//
+// Both hashes are SHA-256 digests of the RFC 8785 (JSON Canonicalization
+// Scheme) serialization of a small envelope object, encoded as lowercase hex
+// without a 0x prefix:
+//
+//	rationale_hash = sha256(JCS({"task_id": <task id>, "transcript": <debate transcript>}))
+//	consensus_hash = sha256(JCS({"task_id": <task id>, "summary": <summary>, "results": <results>}))
+//
+// A missing transcript or results list is hashed as an empty array so that a
+// null and an omitted field produce the same digest. JCS sorts object keys by
+// their UTF-16 code units, writes numbers the way ECMAScript does, and only
+// escapes the characters JSON requires, which makes the output reproducible
+// from any language with a JCS library.
+//
+// A reviewer override is anchored with correctLog using two more hashes:
+//
+//	review_rationale_hash = sha256(JCS({"task_id", "reviewer_id", "justification", "reviewed_at"}))
+//	review_consensus_hash = sha256(JCS({"task_id", "machine_consensus_hash", "verdict"}))
+//
+// reviewed_at is RFC 3339 in UTC with second precision.
+
+// SwarmHashVersion is the version of the canonical hashing spec above. The
+// swarm reports the version it hashed with (hashes.version); hashes of any
+// other version are refused, so a change to the spec must bump it.
+const SwarmHashVersion = 1
+
+// CanonicalJSON serializes v according to RFC 8785. v may be any value that
+// encoding/json can marshal; it is round-tripped through the generic JSON
+// model first so structs, maps and raw JSON all canonicalize identically.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("canonical json: marshal: %w", err)
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, fmt.Errorf("canonical json: unmarshal: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CanonicalHash returns the lowercase hex SHA-256 of CanonicalJSON(v).
+func CanonicalHash(v interface{}) (string, error) {
+	data, err := CanonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RationaleEnvelope is the object hashed into rationale_hash.
+func RationaleEnvelope(taskID string, transcript interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"task_id":    taskID,
+		"transcript": emptyArrayIfNil(transcript),
+	}
+}
+
+// ConsensusEnvelope is the object hashed into consensus_hash.
+func ConsensusEnvelope(taskID, summary string, results interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"task_id": taskID,
+		"summary": summary,
+		"results": emptyArrayIfNil(results),
+	}
+}
+
+// ComputeSwarmHashes computes the rationale and consensus hashes of a swarm
+// result following the spec above (SwarmHashVersion).
+func ComputeSwarmHashes(taskID, summary string, results, transcript interface{}) (rationaleHash, consensusHash string, err error) {
+	rationaleHash, err = CanonicalHash(RationaleEnvelope(taskID, transcript))
+	if err != nil {
+		return "", "", fmt.Errorf("rationale hash: %w", err)
+	}
+	consensusHash, err = CanonicalHash(ConsensusEnvelope(taskID, summary, results))
+	if err != nil {
+		return "", "", fmt.Errorf("consensus hash: %w", err)
+	}
+	return rationaleHash, consensusHash, nil
+}
+
+// ComputeReviewHashes computes the hashes anchoring a reviewer override.
+func ComputeReviewHashes(taskID, machineConsensusHash, verdict, justification, reviewerID string, reviewedAt time.Time) (rationaleHash, consensusHash string, err error) {
+	rationaleHash, err = CanonicalHash(map[string]interface{}{
+		"task_id":       taskID,
+		"reviewer_id":   reviewerID,
+		"justification": justification,
+		"reviewed_at":   reviewedAt.UTC().Truncate(time.Second).Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("review rationale hash: %w", err)
+	}
+	consensusHash, err = CanonicalHash(map[string]interface{}{
+		"task_id":                taskID,
+		"machine_consensus_hash": NormalizeHash(machineConsensusHash),
+		"verdict":                verdict,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("review consensus hash: %w", err)
+	}
+	return rationaleHash, consensusHash, nil
+}
+
+// ComputeSwarmHashesFromJSON is ComputeSwarmHashes for results and transcript
+// already stored as JSON documents (e.g. the jsonb columns of swarm_tasks).
+func ComputeSwarmHashesFromJSON(taskID, summary string, results, transcript []byte) (string, string, error) {
+	decode := func(name string, data []byte) (interface{}, error) {
+		if len(bytes.TrimSpace(data)) == 0 {
+			return nil, nil
+		}
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("decode stored %s: %w", name, err)
+		}
+		return v, nil
+	}
+
+	resultsVal, err := decode("results", results)
+	if err != nil {
+		return "", "", err
+	}
+	transcriptVal, err := decode("transcript", transcript)
+	if err != nil {
+		return "", "", err
+	}
+	return ComputeSwarmHashes(taskID, summary, resultsVal, transcriptVal)
+}
+
+// NormalizeHash lowercases a hex digest and strips an optional 0x prefix so
+// hashes coming from the chain, the swarm and the database compare equal.
+func NormalizeHash(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.TrimPrefix(h, "0x")
+}
+
+// HashesEqual compares two hex digests after normalization.
+func HashesEqual(a, b string) bool {
+	return NormalizeHash(a) == NormalizeHash(b)
+}
+
+func emptyArrayIfNil(v interface{}) interface{} {
+	if v == nil {
+		return []interface{}{}
+	}
+	switch t := v.(type) {
+	case []interface{}:
+		if t == nil {
+			return []interface{}{}
+		}
+	case []map[string]interface{}:
+		if t == nil {
+			return []interface{}{}
+		}
+	}
+	return v
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if t {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case float64:
+		s, err := canonicalNumber(t)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case string:
+		writeCanonicalString(buf, t)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, t[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("canonical json: unsupported type %T", v)
+	}
+	return nil
+}
+
+// canonicalNumber formats f like ECMAScript's Number.prototype.toString,
+// which is what RFC 8785 mandates.
+func canonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("canonical json: %v is not representable", f)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	// Exponent form: Go writes "1e+21" / "1.5e-07", ECMAScript "1e+21" / "1.5e-7".
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	sign := exp[:1]
+	digits := strings.TrimLeft(exp[1:], "0")
+	if digits == "" {
+		digits = "0"
+	}
+	return mantissa + "e" + sign + digits, nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
@@ -0,0 +1,96 @@
+package blockchain_test
+
+import (
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"sorted keys", `{"b":1,"a":2,"A":3}`, `{"A":3,"a":2,"b":1}`},
+		{"nested", `{"z":[{"y":true,"x":null}],"m":"v"}`, `{"m":"v","z":[{"x":null,"y":true}]}`},
+		{"integers", `[25000000,-1,0,-0]`, `[25000000,-1,0,0]`},
+		{"fractions", `[1.5,0.000001,100.10]`, `[1.5,0.000001,100.1]`},
+		{"exponents", `[1e21,1.5e-7,1e300]`, `[1e+21,1.5e-7,1e+300]`},
+		{"no html escaping", `{"s":"<a&b>"}`, `{"s":"<a&b>"}`},
+		{"control chars", `{"s":"a\nb\u0001\"\\"}`, `{"s":"a\nb\u0001\"\\"}`},
+		{"unicode literal", `{"s":"Rp 1.000 — pengadaan  "}`, "{\"s\":\"Rp 1.000 — pengadaan  \"}"},
+		{"utf16 key order", `{"😀":1,"｡":2}`, "{\"\U0001F600\":1,\"｡\":2}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := blockchain.CanonicalJSON(jsonRaw(tt.input))
+			if err != nil {
+				t.Fatalf("CanonicalJSON returned error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON(%s) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeSwarmHashes_StableAcrossRepresentations(t *testing.T) {
+	taskID := "4f7c2a7e-6d1a-4d0e-9b3a-1f2e3d4c5b6a"
+	results := []map[string]interface{}{
+		{"item": "Laptop", "unit_price": 25000000, "verdict": "MARKUP"},
+	}
+	transcript := []map[string]interface{}{
+		{"agent": "auditor", "message": "Harga melebihi SSH"},
+	}
+
+	r1, c1, err := blockchain.ComputeSwarmHashes(taskID, "1 item flagged", results, transcript)
+	if err != nil {
+		t.Fatalf("ComputeSwarmHashes returned error: %v", err)
+	}
+
+	// The same data as stored in the jsonb columns, with different key order and spacing.
+	r2, c2, err := blockchain.ComputeSwarmHashesFromJSON(taskID, "1 item flagged",
+		[]byte(`[ {"verdict":"MARKUP", "unit_price":2.5e7, "item":"Laptop"} ]`),
+		[]byte(`[{"message":"Harga melebihi SSH","agent":"auditor"}]`))
+	if err != nil {
+		t.Fatalf("ComputeSwarmHashesFromJSON returned error: %v", err)
+	}
+
+	if r1 != r2 || c1 != c2 {
+		t.Errorf("hashes differ between representations: (%s, %s) vs (%s, %s)", r1, c1, r2, c2)
+	}
+	if len(r1) != 64 || len(c1) != 64 {
+		t.Errorf("expected 64-char hex digests, got %q and %q", r1, c1)
+	}
+
+	r3, c3, _ := blockchain.ComputeSwarmHashes(taskID, "1 item flagged", results, nil)
+	if r3 == r1 {
+		t.Error("rationale hash must change when the transcript changes")
+	}
+	if c3 != c1 {
+		t.Error("consensus hash must not depend on the transcript")
+	}
+}
+
+func TestComputeSwarmHashes_NullEqualsEmpty(t *testing.T) {
+	r1, c1, _ := blockchain.ComputeSwarmHashesFromJSON("t", "", []byte("null"), nil)
+	r2, c2, _ := blockchain.ComputeSwarmHashes("t", "", []interface{}{}, []interface{}{})
+	if r1 != r2 || c1 != c2 {
+		t.Error("null and empty arrays must hash identically")
+	}
+}
+
+func TestHashesEqual(t *testing.T) {
+	if !blockchain.HashesEqual("0xABCDEF", "abcdef") {
+		t.Error("expected 0x-prefixed uppercase hash to equal lowercase hash")
+	}
+	if blockchain.HashesEqual("abcdef", "abcdee") {
+		t.Error("expected different hashes not to be equal")
+	}
+}
+
+type jsonRaw string
+
+func (j jsonRaw) MarshalJSON() ([]byte, error) { return []byte(j), nil }
@@ -0,0 +1,161 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AuditTrail event names.
+const (
+	EventLogInserted  = "LogInserted"
+	EventLogCorrected = "LogCorrected"
+)
+
+// ChainEvent is a decoded AuditTrail event. LogInserted carries the hashes;
+// LogCorrected only links the superseded entry (OldIndex) to the new one.
+type ChainEvent struct {
+	Name          string
+	EntryIndex    uint64
+	OldIndex      *uint64
+	TaskID        string
+	RationaleHash string
+	ConsensusHash string
+	TxHash        string
+	BlockNumber   uint64
+	BlockHash     string
+	LogIndex      uint
+}
+
+// EventSource is implemented by ledgers whose events can be indexed.
+type EventSource interface {
+	// HeadBlock returns the latest block number.
+	HeadBlock(ctx context.Context) (uint64, error)
+	// BlockHash returns the canonical hash of a block, used to detect reorgs.
+	BlockHash(ctx context.Context, number uint64) (string, error)
+	// FilterEvents returns AuditTrail events in [from, to], in chain order.
+	FilterEvents(ctx context.Context, from, to uint64) ([]ChainEvent, error)
+}
+
+var (
+	auditTrailABIOnce sync.Once
+	auditTrailABI     abi.ABI
+	auditTrailABIErr  error
+)
+
+func parsedAuditTrailABI() (abi.ABI, error) {
+	auditTrailABIOnce.Do(func() {
+		auditTrailABI, auditTrailABIErr = abi.JSON(strings.NewReader(AuditTrailABI))
+	})
+	return auditTrailABI, auditTrailABIErr
+}
+
+// ParseAuditTrailLog decodes a LogInserted or LogCorrected log.
+func ParseAuditTrailLog(l types.Log) (*ChainEvent, error) {
+	parsed, err := parsedAuditTrailABI()
+	if err != nil {
+		return nil, err
+	}
+	if len(l.Topics) == 0 {
+		return nil, fmt.Errorf("log has no topics")
+	}
+	ev, err := parsed.EventByID(l.Topics[0])
+	if err != nil {
+		return nil, fmt.Errorf("unknown event: %w", err)
+	}
+
+	event := &ChainEvent{
+		Name:        ev.Name,
+		TxHash:      l.TxHash.Hex(),
+		BlockNumber: l.BlockNumber,
+		BlockHash:   l.BlockHash.Hex(),
+		LogIndex:    l.Index,
+	}
+
+	switch ev.Name {
+	case EventLogInserted:
+		if len(l.Topics) < 2 {
+			return nil, fmt.Errorf("LogInserted without index topic")
+		}
+		var data struct {
+			TaskId        string
+			RationaleHash [32]byte
+			ConsensusHash [32]byte
+		}
+		if err := parsed.UnpackIntoInterface(&data, ev.Name, l.Data); err != nil {
+			return nil, fmt.Errorf("failed to unpack %s: %w", ev.Name, err)
+		}
+		event.EntryIndex = new(big.Int).SetBytes(l.Topics[1].Bytes()).Uint64()
+		event.TaskID = data.TaskId
+		event.RationaleHash = fmt.Sprintf("0x%x", data.RationaleHash)
+		event.ConsensusHash = fmt.Sprintf("0x%x", data.ConsensusHash)
+	case EventLogCorrected:
+		if len(l.Topics) < 3 {
+			return nil, fmt.Errorf("LogCorrected without index topics")
+		}
+		var data struct {
+			TaskId string
+		}
+		if err := parsed.UnpackIntoInterface(&data, ev.Name, l.Data); err != nil {
+			return nil, fmt.Errorf("failed to unpack %s: %w", ev.Name, err)
+		}
+		oldIndex := new(big.Int).SetBytes(l.Topics[1].Bytes()).Uint64()
+		event.OldIndex = &oldIndex
+		event.EntryIndex = new(big.Int).SetBytes(l.Topics[2].Bytes()).Uint64()
+		event.TaskID = data.TaskId
+	default:
+		return nil, fmt.Errorf("unexpected event %s", ev.Name)
+	}
+	return event, nil
+}
+
+func (s *AuditTrailService) HeadBlock(ctx context.Context) (uint64, error) {
+	n, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	return n, nil
+}
+
+func (s *AuditTrailService) BlockHash(ctx context.Context, number uint64) (string, error) {
+	head, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return "", fmt.Errorf("failed to get header %d: %w", number, err)
+	}
+	return head.Hash().Hex(), nil
+}
+
+func (s *AuditTrailService) FilterEvents(ctx context.Context, from, to uint64) ([]ChainEvent, error) {
+	logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{s.contract},
+		Topics: [][]common.Hash{{
+			s.abi.Events[EventLogInserted].ID,
+			s.abi.Events[EventLogCorrected].ID,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	events := make([]ChainEvent, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		event, err := ParseAuditTrailLog(l)
+		if err != nil {
+			return nil, fmt.Errorf("block %d log %d: %w", l.BlockNumber, l.Index, err)
+		}
+		events = append(events, *event)
+	}
+	return events, nil
+}
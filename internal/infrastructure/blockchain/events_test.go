@@ -0,0 +1,61 @@
+package blockchain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParseAuditTrailLog(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(blockchain.AuditTrailABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inserted := parsed.Events[blockchain.EventLogInserted]
+	data, err := inserted.Inputs.NonIndexed().Pack("task-1", common.HexToHash(rationaleA), common.HexToHash(consensusA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := blockchain.ParseAuditTrailLog(types.Log{
+		Topics:      []common.Hash{inserted.ID, common.BigToHash(common.Big3)},
+		Data:        data,
+		BlockNumber: 42,
+		BlockHash:   common.HexToHash("0xbb"),
+		TxHash:      common.HexToHash("0xaa"),
+		Index:       5,
+	})
+	if err != nil {
+		t.Fatalf("ParseAuditTrailLog: %v", err)
+	}
+	if ev.Name != blockchain.EventLogInserted || ev.EntryIndex != 3 || ev.TaskID != "task-1" || ev.BlockNumber != 42 || ev.LogIndex != 5 {
+		t.Errorf("unexpected LogInserted event %+v", ev)
+	}
+	if !blockchain.HashesEqual(ev.RationaleHash, rationaleA) || !blockchain.HashesEqual(ev.ConsensusHash, consensusA) {
+		t.Errorf("hashes not decoded: %s %s", ev.RationaleHash, ev.ConsensusHash)
+	}
+
+	corrected := parsed.Events[blockchain.EventLogCorrected]
+	data, err = corrected.Inputs.NonIndexed().Pack("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err = blockchain.ParseAuditTrailLog(types.Log{
+		Topics: []common.Hash{corrected.ID, common.BigToHash(common.Big3), common.BigToHash(common.Big32)},
+		Data:   data,
+	})
+	if err != nil {
+		t.Fatalf("ParseAuditTrailLog: %v", err)
+	}
+	if ev.Name != blockchain.EventLogCorrected || ev.OldIndex == nil || *ev.OldIndex != 3 || ev.EntryIndex != 32 || ev.TaskID != "task-1" {
+		t.Errorf("unexpected LogCorrected event %+v", ev)
+	}
+
+	if _, err := blockchain.ParseAuditTrailLog(types.Log{Topics: []common.Hash{common.HexToHash("0x01")}}); err == nil {
+		t.Error("expected an error for an unknown event")
+	}
+}
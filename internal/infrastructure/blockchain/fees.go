@@ -0,0 +1,301 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-co-op/gocron/v2"
+)
+
+const (
+	// gasLimitHeadroomPercent is added to the estimated gas limit since the
+	// state may change between estimation and execution.
+	gasLimitHeadroomPercent = 20
+	// stuckBatchSize bounds how many stuck transactions one bump round handles.
+	stuckBatchSize = 50
+)
+
+// feeQuote holds either dynamic fees (EIP-1559) or, on chains without a
+// base fee, a legacy gas price.
+type feeQuote struct {
+	tipCap   *big.Int
+	feeCap   *big.Int
+	gasPrice *big.Int
+}
+
+func (f feeQuote) dynamic() bool {
+	return f.gasPrice == nil
+}
+
+func (f feeQuote) tx(chainID *big.Int, nonce, gas uint64, to common.Address, data []byte) *types.Transaction {
+	if !f.dynamic() {
+		return types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: f.gasPrice, Gas: gas, To: &to, Data: data})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: f.tipCap,
+		GasFeeCap: f.feeCap,
+		Gas:       gas,
+		To:        &to,
+		Data:      data,
+	})
+}
+
+// suggestFees quotes fees for a new transaction: the node's suggested tip
+// and a fee cap of twice the current base fee plus the tip, which survives
+// several consecutive full blocks.
+func (s *AuditTrailService) suggestFees(ctx context.Context) (feeQuote, error) {
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return feeQuote{}, fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	if head.BaseFee == nil {
+		gasPrice, err := s.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return feeQuote{}, fmt.Errorf("failed to get gas price: %w", err)
+		}
+		return feeQuote{gasPrice: s.capFee(gasPrice)}, nil
+	}
+
+	tip, err := s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return feeQuote{}, fmt.Errorf("failed to get gas tip: %w", err)
+	}
+	feeCap := new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip)
+	feeCap = s.capFee(feeCap)
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+	return feeQuote{tipCap: tip, feeCap: feeCap}, nil
+}
+
+func (s *AuditTrailService) capFee(fee *big.Int) *big.Int {
+	if s.maxFeeCap != nil && fee.Cmp(s.maxFeeCap) > 0 {
+		return new(big.Int).Set(s.maxFeeCap)
+	}
+	return fee
+}
+
+// txRecord describes a signed attempt for the transaction store.
+func (s *AuditTrailService) txRecord(tx *types.Transaction, from common.Address, attempt int) *domain.BlockchainTransaction {
+	return &domain.BlockchainTransaction{
+		TxHash:               tx.Hash().Hex(),
+		ChainID:              tx.ChainId().Int64(),
+		FromAddress:          from.Hex(),
+		ToAddress:            s.contract.Hex(),
+		Nonce:                int64(tx.Nonce()),
+		Data:                 tx.Data(),
+		GasLimit:             int64(tx.Gas()),
+		MaxFeePerGas:         tx.GasFeeCap().String(),
+		MaxPriorityFeePerGas: tx.GasTipCap().String(),
+		Status:               domain.TxStatusPending,
+		Attempt:              attempt,
+		SubmittedAt:          time.Now(),
+	}
+}
+
+// track records a first attempt so it can be fee-bumped and followed.
+// Tracking is best effort: the transaction is already on its way.
+func (s *AuditTrailService) track(ctx context.Context, tx *types.Transaction, from common.Address) {
+	if s.txStore == nil {
+		return
+	}
+	record := s.txRecord(tx, from, 1)
+	if err := s.txStore.Create(ctx, record); err != nil {
+		log.Printf("[Blockchain] Failed to track transaction %s: %v", record.TxHash, err)
+	}
+}
+
+func (s *AuditTrailService) recordReceipt(ctx context.Context, receipt *types.Receipt) {
+	if s.txStore == nil {
+		return
+	}
+	status := domain.TxStatusMined
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		status = domain.TxStatusReverted
+	}
+	var block *int64
+	if receipt.BlockNumber != nil {
+		n := receipt.BlockNumber.Int64()
+		block = &n
+	}
+	if err := s.txStore.UpdateStatus(ctx, receipt.TxHash.Hex(), status, block); err != nil {
+		log.Printf("[Blockchain] Failed to record receipt for %s: %v", receipt.TxHash.Hex(), err)
+	}
+}
+
+// FeeBumper is implemented by ledgers that can replace stuck transactions.
+type FeeBumper interface {
+	BumpStuckTransactions(ctx context.Context, stuckAfter time.Duration, percent int) (int, error)
+}
+
+// BumpStuckTransactions re-sends every transaction pending for longer than
+// stuckAfter with the same nonce and payload and fees raised by percent
+// (and at least to the current quote). It returns how many were replaced.
+func (s *AuditTrailService) BumpStuckTransactions(ctx context.Context, stuckAfter time.Duration, percent int) (int, error) {
+	if s.txStore == nil || s.signers.empty() {
+		return 0, nil
+	}
+
+	// Other ledger profiles track their transactions in the same store.
+	chainID, err := s.client.ChainID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	stuck, err := s.txStore.ListStuck(ctx, chainID.Int64(), s.contract.Hex(), time.Now().Add(-stuckAfter), stuckBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(stuck) == 0 {
+		return 0, nil
+	}
+	quote, err := s.suggestFees(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Confirmed nonces are looked up once per submitter that has stuck
+	// transactions; records from keys no longer configured are left alone.
+	confirmed := make(map[common.Address]uint64)
+	bumped := 0
+	for _, record := range stuck {
+		from := common.HexToAddress(record.FromAddress)
+		pk := s.signers.byAddress(from)
+		if pk == nil {
+			continue
+		}
+		if _, ok := confirmed[from]; !ok {
+			n, err := s.client.NonceAt(ctx, from, nil)
+			if err != nil {
+				return bumped, fmt.Errorf("failed to get confirmed nonce: %w", err)
+			}
+			confirmed[from] = n
+		}
+
+		receipt, err := s.client.TransactionReceipt(ctx, common.HexToHash(record.TxHash))
+		if err == nil {
+			s.recordReceipt(ctx, receipt)
+			continue
+		}
+		if err != ethereum.NotFound {
+			log.Printf("[Blockchain] Failed to check receipt for %s: %v", record.TxHash, err)
+			continue
+		}
+		if uint64(record.Nonce) < confirmed[from] {
+			// The nonce was used by a transaction we are not tracking.
+			s.txStore.UpdateStatus(ctx, record.TxHash, domain.TxStatusDropped, nil)
+			continue
+		}
+
+		replacement, err := s.replace(ctx, pk, record, chainID, quote, percent)
+		if err != nil {
+			log.Printf("[Blockchain] Failed to bump transaction %s (nonce %d): %v", record.TxHash, record.Nonce, err)
+			continue
+		}
+		log.Printf("[Blockchain] Bumped stuck transaction %s -> %s (nonce %d, attempt %d)", record.TxHash, replacement.TxHash, record.Nonce, replacement.Attempt)
+		bumped++
+	}
+	return bumped, nil
+}
+
+func (s *AuditTrailService) replace(ctx context.Context, pk *ecdsa.PrivateKey, record *domain.BlockchainTransaction, chainID *big.Int, quote feeQuote, percent int) (*domain.BlockchainTransaction, error) {
+	oldFeeCap, ok := new(big.Int).SetString(record.MaxFeePerGas, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid recorded fee cap %q", record.MaxFeePerGas)
+	}
+	oldTip, ok := new(big.Int).SetString(record.MaxPriorityFeePerGas, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid recorded tip %q", record.MaxPriorityFeePerGas)
+	}
+
+	var fees feeQuote
+	if quote.dynamic() {
+		fees = feeQuote{
+			tipCap: maxBig(bumpFee(oldTip, percent), quote.tipCap),
+			feeCap: maxBig(bumpFee(oldFeeCap, percent), quote.feeCap),
+		}
+		if s.maxFeeCap != nil && fees.feeCap.Cmp(s.maxFeeCap) > 0 {
+			return nil, fmt.Errorf("bumped fee cap %s exceeds max_fee_gwei", fees.feeCap)
+		}
+		if fees.tipCap.Cmp(fees.feeCap) > 0 {
+			fees.tipCap = new(big.Int).Set(fees.feeCap)
+		}
+	} else {
+		fees = feeQuote{gasPrice: maxBig(bumpFee(oldFeeCap, percent), quote.gasPrice)}
+		if s.maxFeeCap != nil && fees.gasPrice.Cmp(s.maxFeeCap) > 0 {
+			return nil, fmt.Errorf("bumped gas price %s exceeds max_fee_gwei", fees.gasPrice)
+		}
+	}
+
+	to := common.HexToAddress(record.ToAddress)
+	tx := fees.tx(chainID, uint64(record.Nonce), uint64(record.GasLimit), to, record.Data)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), pk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign replacement: %w", err)
+	}
+	if err := s.client.SendTransaction(ctx, signedTx); err != nil {
+		return nil, fmt.Errorf("failed to send replacement: %w", err)
+	}
+
+	replacement := s.txRecord(signedTx, common.HexToAddress(record.FromAddress), record.Attempt+1)
+	if err := s.txStore.Replace(ctx, record.TxHash, replacement); err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
+
+func bumpFee(fee *big.Int, percent int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(int64(100+percent)))
+	bumped.Div(bumped, big.NewInt(100))
+	// Guarantee a strictly higher fee for tiny values where rounding eats the bump.
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return new(big.Int).Set(b)
+}
+
+// StartFeeBumper schedules BumpStuckTransactions every cfg.Interval.
+func StartFeeBumper(bumper FeeBumper, cfg config.FeeBumpConfig) (gocron.Scheduler, error) {
+	s, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	_, err = s.NewJob(
+		gocron.DurationJob(cfg.Interval),
+		gocron.NewTask(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Interval)
+			defer cancel()
+
+			if _, err := bumper.BumpStuckTransactions(ctx, cfg.StuckAfter, cfg.Percent); err != nil {
+				log.Printf("[Blockchain] Fee bump round failed: %v", err)
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule fee bumper: %w", err)
+	}
+
+	log.Printf("Fee bumper scheduled every %s (stuck after %s, +%d%%)", cfg.Interval, cfg.StuckAfter, cfg.Percent)
+	s.Start()
+	return s, nil
+}
@@ -0,0 +1,177 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogEntry is one entry of a task's on-chain history, oldest first. Every
+// correction supersedes the entry before it; only the last one is active.
+type LogEntry struct {
+	Index         uint64  `json:"index"`
+	RationaleHash string  `json:"rationaleHash"`
+	ConsensusHash string  `json:"consensusHash"`
+	Submitter     string  `json:"submitter"`
+	Timestamp     int64   `json:"timestamp"`
+	BlockNumber   uint64  `json:"blockNumber"`
+	TxHash        string  `json:"txHash"`
+	Status        uint8   `json:"status"`
+	Supersedes    *uint64 `json:"supersedes,omitempty"`
+	SupersededBy  *uint64 `json:"supersededBy,omitempty"`
+}
+
+// GetTaskHistory lists every log entry of a task, including superseded ones.
+// The contract only returns entry indices, so the details are recovered from
+// the LogInserted/LogCorrected events and the transactions that emitted them.
+func (s *AuditTrailService) GetTaskHistory(ctx context.Context, taskID string) ([]LogEntry, error) {
+	data, err := s.abi.Pack("getTaskHistory", taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack getTaskHistory: %w", err)
+	}
+	result, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &s.contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call contract: %w", err)
+	}
+	var indices []*big.Int
+	if err := s.abi.UnpackIntoInterface(&indices, "getTaskHistory", result); err != nil {
+		return nil, fmt.Errorf("failed to unpack result: %w", err)
+	}
+	if len(indices) == 0 {
+		return nil, nil
+	}
+
+	entries := make([]LogEntry, len(indices))
+	byIndex := make(map[uint64]*LogEntry, len(indices))
+	topics := make([]common.Hash, len(indices))
+	for i, idx := range indices {
+		entries[i] = LogEntry{Index: idx.Uint64(), Status: LogStatusActive}
+		byIndex[idx.Uint64()] = &entries[i]
+		topics[i] = common.BigToHash(idx)
+	}
+
+	// LogInserted is indexed by entry; LogCorrected by (old, new) entry.
+	inserted, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{s.contract},
+		Topics:    [][]common.Hash{{s.abi.Events[EventLogInserted].ID}, topics},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter %s logs: %w", EventLogInserted, err)
+	}
+	corrected, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{s.contract},
+		Topics:    [][]common.Hash{{s.abi.Events[EventLogCorrected].ID}, nil, topics},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter %s logs: %w", EventLogCorrected, err)
+	}
+
+	for _, l := range append(inserted, corrected...) {
+		if l.Removed {
+			continue
+		}
+		ev, err := ParseAuditTrailLog(l)
+		if err != nil || ev.TaskID != taskID {
+			continue
+		}
+		entry, ok := byIndex[ev.EntryIndex]
+		if !ok {
+			continue
+		}
+		entry.TxHash = ev.TxHash
+		entry.BlockNumber = ev.BlockNumber
+		if ev.Name == EventLogInserted {
+			entry.RationaleHash = ev.RationaleHash
+			entry.ConsensusHash = ev.ConsensusHash
+		}
+		if ev.OldIndex != nil {
+			old := *ev.OldIndex
+			entry.Supersedes = &old
+			if prev, ok := byIndex[old]; ok {
+				newIndex := ev.EntryIndex
+				prev.SupersededBy = &newIndex
+				prev.Status = LogStatusSuperseded
+			}
+		}
+	}
+
+	for i := range entries {
+		if err := s.fillFromTransaction(ctx, &entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// fillFromTransaction adds the submitter and block time of an entry and, for
+// corrections whose event carries no hashes, decodes them from the calldata.
+func (s *AuditTrailService) fillFromTransaction(ctx context.Context, entry *LogEntry) error {
+	if entry.TxHash == "" {
+		return nil
+	}
+	tx, _, err := s.client.TransactionByHash(ctx, common.HexToHash(entry.TxHash))
+	if err != nil {
+		return fmt.Errorf("failed to get transaction %s: %w", entry.TxHash, err)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		entry.Submitter = from.Hex()
+	}
+
+	if entry.RationaleHash == "" && len(tx.Data()) >= 4 {
+		if method, err := s.abi.MethodById(tx.Data()[:4]); err == nil {
+			if args, err := method.Inputs.Unpack(tx.Data()[4:]); err == nil && len(args) == 3 {
+				if r, ok := args[1].([32]byte); ok {
+					entry.RationaleHash = fmt.Sprintf("0x%x", r)
+				}
+				if c, ok := args[2].([32]byte); ok {
+					entry.ConsensusHash = fmt.Sprintf("0x%x", c)
+				}
+			}
+		}
+	}
+
+	head, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(entry.BlockNumber))
+	if err != nil {
+		return fmt.Errorf("failed to get header %d: %w", entry.BlockNumber, err)
+	}
+	entry.Timestamp = int64(head.Time)
+	return nil
+}
+
+// GetTaskHistory lists every entry appended for the task, oldest first.
+func (l *LocalLedger) GetTaskHistory(ctx context.Context, taskID string) ([]LogEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []LogEntry
+	for _, e := range l.entries {
+		if e.TaskID != taskID {
+			continue
+		}
+		entry := LogEntry{
+			Index:         uint64(e.Index),
+			RationaleHash: e.RationaleHash,
+			ConsensusHash: e.ConsensusHash,
+			Submitter:     e.Submitter,
+			Timestamp:     e.Timestamp,
+			BlockNumber:   uint64(e.Index + 1),
+			TxHash:        e.Hash,
+			Status:        LogStatusActive,
+		}
+		if e.Supersedes != nil {
+			old := uint64(*e.Supersedes)
+			entry.Supersedes = &old
+		}
+		if next, ok := l.supersededBy[e.Index]; ok {
+			newIndex := uint64(next)
+			entry.SupersededBy = &newIndex
+			entry.Status = LogStatusSuperseded
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,262 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// rotationTimeout bounds how long RotateSubmitter waits for the
+// authorizeSubmitter transaction to be mined.
+const rotationTimeout = 5 * time.Minute
+
+// SubmitterManager is implemented by ledgers whose signing keys must be
+// authorized on the AuditTrail contract.
+type SubmitterManager interface {
+	// Submitters lists the addresses transactions are signed with.
+	Submitters() []string
+	// CheckSubmitters fails unless every submitter is authorized on the contract.
+	CheckSubmitters(ctx context.Context) error
+	// RotateSubmitter authorizes key on the contract, signed by the primary
+	// key (the contract owner), and adds it to the signing rotation once the
+	// authorization is mined. It returns the authorization's tx hash.
+	RotateSubmitter(ctx context.Context, key *ecdsa.PrivateKey) (string, error)
+}
+
+var _ SubmitterManager = (*AuditTrailService)(nil)
+
+// LoadSubmitterKeys returns the signing keys configured for the ledger: the
+// encrypted keystore files, decrypted with the passphrase read from
+// Keystore.PassphraseFile, or else the raw PrivateKey. No keys is not an
+// error; the ledger is then read-only.
+func LoadSubmitterKeys(cfg config.BlockchainConfig) ([]*ecdsa.PrivateKey, error) {
+	if len(cfg.Keystore.Files) == 0 {
+		if cfg.PrivateKey == "" {
+			return nil, nil
+		}
+		pk, err := crypto.HexToECDSA(strings.TrimPrefix(cfg.PrivateKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		return []*ecdsa.PrivateKey{pk}, nil
+	}
+	if cfg.PrivateKey != "" {
+		log.Printf("[Blockchain] keystore files configured, ignoring blockchain.private_key")
+	}
+
+	passphrase, err := ReadPassphrase(cfg.Keystore.PassphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*ecdsa.PrivateKey, 0, len(cfg.Keystore.Files))
+	seen := make(map[common.Address]bool)
+	for _, path := range cfg.Keystore.Files {
+		pk, err := LoadKeystoreKey(path, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		addr := crypto.PubkeyToAddress(pk.PublicKey)
+		if seen[addr] {
+			return nil, fmt.Errorf("keystore %s repeats submitter %s", path, addr.Hex())
+		}
+		seen[addr] = true
+		keys = append(keys, pk)
+	}
+	return keys, nil
+}
+
+// LoadKeystoreKey decrypts a go-ethereum (V3) keystore file.
+func LoadKeystoreKey(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore %s: %w", path, err)
+	}
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+// ReadPassphrase reads a keystore passphrase from a file such as a mounted
+// secret. A single trailing newline is not part of the passphrase.
+func ReadPassphrase(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("blockchain.keystore.passphrase_file is required with keystore files")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keystore passphrase: %w", err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
+
+// signerPool holds the submitter keys. Transactions are signed round-robin,
+// so each key's nonces advance independently and submissions do not queue
+// behind a single account. The first key is the primary: it signs owner
+// calls such as authorizeSubmitter.
+type signerPool struct {
+	mu   sync.Mutex
+	keys []*ecdsa.PrivateKey
+	next int
+}
+
+func newSignerPool(keys []*ecdsa.PrivateKey) *signerPool {
+	return &signerPool{keys: append([]*ecdsa.PrivateKey(nil), keys...)}
+}
+
+func (p *signerPool) empty() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.keys) == 0
+}
+
+func (p *signerPool) primary() *ecdsa.PrivateKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		return nil
+	}
+	return p.keys[0]
+}
+
+// pick returns the next key in rotation.
+func (p *signerPool) pick() *ecdsa.PrivateKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		return nil
+	}
+	pk := p.keys[p.next%len(p.keys)]
+	p.next++
+	return pk
+}
+
+func (p *signerPool) byAddress(addr common.Address) *ecdsa.PrivateKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pk := range p.keys {
+		if crypto.PubkeyToAddress(pk.PublicKey) == addr {
+			return pk
+		}
+	}
+	return nil
+}
+
+func (p *signerPool) addresses() []common.Address {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	addrs := make([]common.Address, len(p.keys))
+	for i, pk := range p.keys {
+		addrs[i] = crypto.PubkeyToAddress(pk.PublicKey)
+	}
+	return addrs
+}
+
+func (p *signerPool) add(pk *ecdsa.PrivateKey) {
+	if p.byAddress(crypto.PubkeyToAddress(pk.PublicKey)) != nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, pk)
+}
+
+// Submitters returns the addresses of the signing keys, primary first.
+func (s *AuditTrailService) Submitters() []string {
+	addrs := s.signers.addresses()
+	out := make([]string, len(addrs))
+	for i, addr := range addrs {
+		out[i] = addr.Hex()
+	}
+	return out
+}
+
+// IsAuthorizedSubmitter reads authorizedSubmitters(addr) from the contract.
+func (s *AuditTrailService) IsAuthorizedSubmitter(ctx context.Context, addr common.Address) (bool, error) {
+	data, err := s.abi.Pack("authorizedSubmitters", addr)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack authorizedSubmitters: %w", err)
+	}
+
+	result, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &s.contract, Data: data}, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to call contract: %w", err)
+	}
+
+	var authorized bool
+	if err := s.abi.UnpackIntoInterface(&authorized, "authorizedSubmitters", result); err != nil {
+		return false, fmt.Errorf("failed to unpack result: %w", err)
+	}
+	return authorized, nil
+}
+
+// CheckSubmitters verifies that every signing key is an authorized
+// submitter, so a misconfigured key fails at startup rather than on the
+// first reverted insertLog.
+func (s *AuditTrailService) CheckSubmitters(ctx context.Context) error {
+	var unauthorized []string
+	for _, addr := range s.signers.addresses() {
+		ok, err := s.IsAuthorizedSubmitter(ctx, addr)
+		if err != nil {
+			return fmt.Errorf("failed to check submitter %s: %w", addr.Hex(), err)
+		}
+		if !ok {
+			unauthorized = append(unauthorized, addr.Hex())
+		}
+	}
+	if len(unauthorized) > 0 {
+		return fmt.Errorf("not authorized submitters on contract %s: %s", s.contract.Hex(), strings.Join(unauthorized, ", "))
+	}
+	return nil
+}
+
+// AuthorizeSubmitter sends authorizeSubmitter(addr) signed by the primary key.
+func (s *AuditTrailService) AuthorizeSubmitter(ctx context.Context, addr common.Address) (string, error) {
+	owner := s.signers.primary()
+	if owner == nil {
+		return "", fmt.Errorf("private key not configured")
+	}
+
+	data, err := s.abi.Pack("authorizeSubmitter", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack authorizeSubmitter: %w", err)
+	}
+
+	txHash, err := s.sendTransactionFrom(ctx, owner, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return txHash, nil
+}
+
+func (s *AuditTrailService) RotateSubmitter(ctx context.Context, key *ecdsa.PrivateKey) (string, error) {
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	txHash, err := s.AuthorizeSubmitter(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+
+	receipt, err := s.WaitForConfirmation(ctx, txHash, rotationTimeout)
+	if err != nil {
+		return txHash, fmt.Errorf("authorization of %s not confirmed: %w", addr.Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return txHash, fmt.Errorf("authorization of %s reverted (is the primary key the contract owner?)", addr.Hex())
+	}
+
+	s.signers.add(key)
+	return receipt.TxHash.Hex(), nil
+}
@@ -0,0 +1,130 @@
+package blockchain_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// writeKeystores creates n encrypted keystore files sharing one passphrase
+// file and returns the matching keystore config.
+func writeKeystores(t *testing.T, n int) config.KeystoreConfig {
+	t.Helper()
+	dir := t.TempDir()
+	passFile := filepath.Join(dir, "passphrase")
+	if err := os.WriteFile(passFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.KeystoreConfig{PassphraseFile: passFile}
+	for i := 0; i < n; i++ {
+		account, err := keystore.StoreKey(filepath.Join(dir, "keys"), "s3cret", keystore.LightScryptN, keystore.LightScryptP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Files = append(cfg.Files, account.URL.Path)
+	}
+	return cfg
+}
+
+func TestLoadSubmitterKeysFromKeystore(t *testing.T) {
+	ks := writeKeystores(t, 2)
+
+	keys, err := blockchain.LoadSubmitterKeys(config.BlockchainConfig{Keystore: ks, PrivateKey: "0x01"})
+	if err != nil {
+		t.Fatalf("LoadSubmitterKeys: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d keys, want 2 (keystore takes precedence over private_key)", len(keys))
+	}
+
+	wrong := filepath.Join(t.TempDir(), "wrong")
+	if err := os.WriteFile(wrong, []byte("nope"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ks.PassphraseFile = wrong
+	if _, err := blockchain.LoadSubmitterKeys(config.BlockchainConfig{Keystore: ks}); err == nil {
+		t.Errorf("wrong passphrase should not decrypt")
+	}
+}
+
+// recordingTxStore remembers which account sent each tracked transaction.
+type recordingTxStore struct {
+	mu   sync.Mutex
+	from map[string]int
+}
+
+func (r *recordingTxStore) Create(ctx context.Context, tx *domain.BlockchainTransaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.from[tx.FromAddress]++
+	return nil
+}
+
+func (r *recordingTxStore) ListStuck(ctx context.Context, chainID int64, to string, before time.Time, limit int) ([]*domain.BlockchainTransaction, error) {
+	return nil, nil
+}
+
+func (r *recordingTxStore) Replace(ctx context.Context, oldHash string, replacement *domain.BlockchainTransaction) error {
+	return nil
+}
+
+func (r *recordingTxStore) ReplacementChain(ctx context.Context, hash string) ([]string, error) {
+	return []string{hash}, nil
+}
+
+func (r *recordingTxStore) UpdateStatus(ctx context.Context, hash, status string, blockNumber *int64) error {
+	return nil
+}
+
+func TestSimulatedLedgerSignsRoundRobin(t *testing.T) {
+	ks := writeKeystores(t, 2)
+	store := &recordingTxStore{from: make(map[string]int)}
+
+	l, err := blockchain.NewLedger(config.BlockchainConfig{
+		Backend:         config.LedgerBackendSimulated,
+		Keystore:        ks,
+		CheckSubmitters: true,
+	}, blockchain.LedgerDeps{Transactions: store})
+	if err != nil {
+		t.Fatalf("NewLedger: %v", err)
+	}
+	defer l.Close()
+
+	submitters := l.(blockchain.SubmitterManager).Submitters()
+	if len(submitters) != 2 {
+		t.Fatalf("got submitters %v, want both keystore keys", submitters)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if _, err := l.InsertLog(ctx, fmt.Sprintf("task-%d", i), rationaleA, consensusA); err != nil {
+			t.Fatalf("InsertLog %d: %v", i, err)
+		}
+	}
+	for _, addr := range submitters {
+		if store.from[addr] != 2 {
+			t.Errorf("%s sent %d transactions, want 2 (%v)", addr, store.from[addr], store.from)
+		}
+	}
+
+	extra, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.(blockchain.SubmitterManager).RotateSubmitter(ctx, extra); err != nil {
+		t.Fatalf("RotateSubmitter: %v", err)
+	}
+	if got := len(l.(blockchain.SubmitterManager).Submitters()); got != 3 {
+		t.Errorf("got %d submitters after rotation, want 3", got)
+	}
+}
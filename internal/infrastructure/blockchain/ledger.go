@@ -0,0 +1,105 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Ledger anchors swarm hashes in an append-only audit trail. The EVM
+// implementation talks to the AuditTrail contract; the simulated and local
+// implementations let the same flow run in CI and air-gapped deployments.
+//
+// Every implementation mirrors the contract's semantics: one active log per
+// task, correctLog supersedes it, and GetActiveLog returns the same keys as
+// the contract's LogEntry struct.
+type Ledger interface {
+	InsertLog(ctx context.Context, taskID, rationaleHash, consensusHash string) (string, error)
+	CorrectLog(ctx context.Context, oldTaskID, rationaleHash, consensusHash string) (string, error)
+	VerifyHashes(ctx context.Context, taskID, rationaleHash, consensusHash string) (bool, error)
+	GetActiveLog(ctx context.Context, taskID string) (map[string]interface{}, error)
+	GetTaskHistory(ctx context.Context, taskID string) ([]LogEntry, error)
+	WaitForConfirmation(ctx context.Context, txHash string, timeout time.Duration) (*types.Receipt, error)
+	Network() string
+	ContractAddress() string
+	Close()
+}
+
+// submitterCheckTimeout bounds the startup check that the signing keys are
+// authorized submitters.
+const submitterCheckTimeout = 30 * time.Second
+
+var (
+	_ Ledger = (*AuditTrailService)(nil)
+	_ Ledger = (*SimulatedLedger)(nil)
+	_ Ledger = (*LocalLedger)(nil)
+	_ Ledger = (*TSALedger)(nil)
+
+	_ EventSource = (*AuditTrailService)(nil)
+)
+
+// LedgerDeps are shared services used by the ledgers. Nonces and
+// Transactions serve the EVM-based ledgers and are optional: without Nonces
+// an in-process allocator is used, without Transactions sent transactions
+// are not tracked and cannot be fee-bumped. Timestamps is required by the
+// tsa backend.
+type LedgerDeps struct {
+	Nonces       NonceManager
+	Transactions domain.BlockchainTransactionRepository
+	Timestamps   domain.TimestampTokenRepository
+}
+
+// NewLedger builds the ledger selected by cfg.Backend, signing with the keys
+// from LoadSubmitterKeys. The local backend signs with the first key only.
+func NewLedger(cfg config.BlockchainConfig, deps LedgerDeps) (Ledger, error) {
+	keys, err := LoadSubmitterKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Backend {
+	case "", config.LedgerBackendEVM:
+		if cfg.RPCURL == "" {
+			return nil, fmt.Errorf("blockchain.rpc_url is required for the evm backend")
+		}
+		svc, err := DialAuditTrailService(cfg.RPCURL, cfg.ContractAddr, cfg.Network, keys)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.CheckSubmitters && len(keys) > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), submitterCheckTimeout)
+			err := svc.CheckSubmitters(ctx)
+			cancel()
+			if err != nil {
+				svc.Close()
+				return nil, err
+			}
+		}
+		svc.useDeps(deps, cfg.MaxFeeGwei)
+		return svc, nil
+	case config.LedgerBackendSimulated:
+		l, err := newSimulatedLedger(cfg.ArtifactPath, keys)
+		if err != nil {
+			return nil, err
+		}
+		// The simulated chain lives in this process; a shared allocator
+		// would only carry nonces over from a previous chain.
+		l.useDeps(LedgerDeps{Transactions: deps.Transactions}, cfg.MaxFeeGwei)
+		return l, nil
+	case config.LedgerBackendLocal:
+		var pk *ecdsa.PrivateKey
+		if len(keys) > 0 {
+			pk = keys[0]
+		}
+		return newLocalLedger(cfg.LocalLogPath, pk, cfg.LocalSubmitters)
+	case config.LedgerBackendTSA:
+		return NewTSALedger(cfg.TSA, cfg.Network, deps.Timestamps)
+	default:
+		return nil, fmt.Errorf("unknown blockchain backend %q", cfg.Backend)
+	}
+}
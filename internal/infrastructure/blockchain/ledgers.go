@@ -0,0 +1,116 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+var (
+	// ErrNoLedger is returned when no ledger is configured at all.
+	ErrNoLedger = errors.New("no ledger is configured")
+	// ErrUnknownLedgerProfile is returned for a profile that is not
+	// configured or whose ledger failed to start.
+	ErrUnknownLedgerProfile = errors.New("unknown ledger profile")
+	// ErrLedgerMoved is returned when a profile no longer points to the
+	// contract a record was anchored to.
+	ErrLedgerMoved = errors.New("ledger profile points to another contract")
+)
+
+// Ledgers is the set of ledgers of the configured profiles (see
+// config.BlockchainConfig.Profiles), keyed by profile name. A nil *Ledgers
+// means no ledger is configured.
+type Ledgers struct {
+	byProfile map[string]Ledger
+}
+
+// NormalizeLedgerProfile maps the empty profile name to
+// config.DefaultLedgerProfile.
+func NormalizeLedgerProfile(profile string) string {
+	if profile == "" {
+		return config.DefaultLedgerProfile
+	}
+	return profile
+}
+
+// NewLedgers builds the ledger of every profile with NewLedger. Profiles
+// whose ledger fails are left out and reported in the returned error; the
+// set is nil only if none started.
+func NewLedgers(cfg config.BlockchainConfig, deps LedgerDeps) (*Ledgers, error) {
+	names := cfg.ProfileNames()
+	set := &Ledgers{byProfile: make(map[string]Ledger, len(names))}
+	var errs []error
+	for _, name := range names {
+		profile, _ := cfg.Profile(name)
+		ledger, err := NewLedger(profile, deps)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ledger profile %s: %w", name, err))
+			continue
+		}
+		set.byProfile[name] = ledger
+	}
+	if len(set.byProfile) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return set, errors.Join(errs...)
+}
+
+// SingleLedger wraps one ledger as the default profile.
+func SingleLedger(ledger Ledger) *Ledgers {
+	if ledger == nil {
+		return nil
+	}
+	return &Ledgers{byProfile: map[string]Ledger{config.DefaultLedgerProfile: ledger}}
+}
+
+// Get returns the ledger of a profile; "" is the default profile.
+func (s *Ledgers) Get(profile string) (Ledger, error) {
+	if s == nil {
+		return nil, ErrNoLedger
+	}
+	ledger, ok := s.byProfile[NormalizeLedgerProfile(profile)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLedgerProfile, profile)
+	}
+	return ledger, nil
+}
+
+// Anchored returns the ledger a record was anchored to: the ledger of its
+// profile, provided that it still uses the recorded contract. An empty
+// contract is not checked.
+func (s *Ledgers) Anchored(profile, contract string) (Ledger, error) {
+	ledger, err := s.Get(profile)
+	if err != nil {
+		return nil, err
+	}
+	if contract != "" && !strings.EqualFold(ledger.ContractAddress(), contract) {
+		return nil, fmt.Errorf("%w: anchored to %s, profile %s now uses %s", ErrLedgerMoved, contract, NormalizeLedgerProfile(profile), ledger.ContractAddress())
+	}
+	return ledger, nil
+}
+
+// Profiles returns the names of the profiles with a ledger, sorted.
+func (s *Ledgers) Profiles() []string {
+	if s == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.byProfile))
+	for name := range s.byProfile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close closes every ledger of the set.
+func (s *Ledgers) Close() {
+	if s == nil {
+		return
+	}
+	for _, ledger := range s.byProfile {
+		ledger.Close()
+	}
+}
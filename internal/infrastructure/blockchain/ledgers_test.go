@@ -0,0 +1,66 @@
+package blockchain_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+)
+
+func TestLedgersSelectsProfile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	ledgers, err := blockchain.NewLedgers(config.BlockchainConfig{
+		Backend:      config.LedgerBackendLocal,
+		LocalLogPath: filepath.Join(dir, "default.jsonl"),
+		Profiles: map[string]config.LedgerProfile{
+			"archive": {LocalLogPath: filepath.Join(dir, "archive.jsonl")},
+			"broken":  {Backend: "carrier-pigeon"},
+		},
+	}, blockchain.LedgerDeps{})
+	if err == nil {
+		t.Errorf("a profile that fails to start should be reported")
+	}
+	if ledgers == nil {
+		t.Fatal("working profiles should still be available")
+	}
+	defer ledgers.Close()
+
+	if got := ledgers.Profiles(); !reflect.DeepEqual(got, []string{"archive", "default"}) {
+		t.Errorf("Profiles() = %v", got)
+	}
+
+	archive, err := ledgers.Get("archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archive.InsertLog(ctx, "task-1", "aa", "bb"); err != nil {
+		t.Fatal(err)
+	}
+	def, err := ledgers.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := def.VerifyHashes(ctx, "task-1", "aa", "bb"); ok {
+		t.Errorf("a log on the archive ledger should not verify on the default one")
+	}
+
+	if _, err := ledgers.Get("broken"); !errors.Is(err, blockchain.ErrUnknownLedgerProfile) {
+		t.Errorf("Get(broken) err = %v", err)
+	}
+	if _, err := ledgers.Anchored("archive", archive.ContractAddress()); err != nil {
+		t.Errorf("Anchored with the recorded contract: %v", err)
+	}
+	if _, err := ledgers.Anchored("archive", filepath.Join(dir, "moved.jsonl")); !errors.Is(err, blockchain.ErrLedgerMoved) {
+		t.Errorf("Anchored with another contract err = %v", err)
+	}
+
+	var none *blockchain.Ledgers
+	if _, err := none.Get(""); !errors.Is(err, blockchain.ErrNoLedger) {
+		t.Errorf("nil set err = %v", err)
+	}
+}
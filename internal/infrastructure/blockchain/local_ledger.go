@@ -0,0 +1,555 @@
+package blockchain
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const localNetwork = "local"
+
+// Log status values, mirroring the AuditTrail contract's LogEntry.status.
+const (
+	LogStatusActive     uint8 = 0
+	LogStatusSuperseded uint8 = 1
+)
+
+// ErrLedgerTampered is returned when a local log fails hash-chain or
+// signature verification.
+var ErrLedgerTampered = errors.New("local ledger failed verification")
+
+// ErrLedgerReadOnly is returned when writing to a ledger opened with
+// OpenLocalLedgerReadOnly.
+var ErrLedgerReadOnly = errors.New("local ledger is opened read-only")
+
+// LocalLedger is an append-only JSON Lines log. Each entry is linked to the
+// previous one by hash and signed with the submitter key, so any edit,
+// reorder or deletion of past entries is detected when the log is opened.
+// Only signatures of the configured submitters are accepted, and the entry
+// count and head hash are anchored in a signed file next to the log
+// (path + ".head"), so truncating the log is detected too.
+// Entry index + 1 plays the role of the block number.
+type LocalLedger struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	privateKey *ecdsa.PrivateKey
+	submitter  common.Address
+	// allowed are the addresses whose signatures the log accepts: the
+	// signing key's and any configured earlier submitters.
+	allowed map[common.Address]bool
+
+	entries      []localEntry
+	byHash       map[string]int
+	active       map[string]int
+	supersededBy map[int]int
+}
+
+type localEntry struct {
+	Index         int    `json:"index"`
+	Op            string `json:"op"` // insert or correct
+	TaskID        string `json:"task_id"`
+	RationaleHash string `json:"rationale_hash"`
+	ConsensusHash string `json:"consensus_hash"`
+	Supersedes    *int   `json:"supersedes,omitempty"`
+	Submitter     string `json:"submitter"`
+	Timestamp     int64  `json:"timestamp"`
+	PrevHash      string `json:"prev_hash"`
+	Hash          string `json:"hash"`
+	Signature     string `json:"signature"`
+}
+
+// localHead anchors the log outside of it: the number of entries and the
+// hash of the last one, signed like an entry.
+type localHead struct {
+	Count     int    `json:"count"`
+	HeadHash  string `json:"head_hash"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// NewLocalLedger opens (or creates) the log at path and verifies it. The
+// signing key comes from privateKeyHex or, when empty, from path + ".key",
+// which is generated on first use.
+func NewLocalLedger(path, privateKeyHex string) (*LocalLedger, error) {
+	var pk *ecdsa.PrivateKey
+	if privateKeyHex != "" {
+		var err error
+		pk, err = crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+	}
+	return newLocalLedger(path, pk, nil)
+}
+
+// newLocalLedger is NewLocalLedger with an already loaded key; nil falls
+// back to path + ".key". Entries signed by the submitters addresses are
+// accepted besides the key's own.
+func newLocalLedger(path string, pk *ecdsa.PrivateKey, submitters []string) (*LocalLedger, error) {
+	if path == "" {
+		return nil, fmt.Errorf("blockchain.local_log_path is required for the local backend")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+
+	var err error
+	if pk == nil {
+		if pk, err = loadOrCreateLedgerKey(path + ".key"); err != nil {
+			return nil, err
+		}
+	}
+
+	allowed, err := parseSubmitters(submitters)
+	if err != nil {
+		return nil, err
+	}
+	submitter := crypto.PubkeyToAddress(pk.PublicKey)
+	allowed[submitter] = true
+
+	l := &LocalLedger{
+		path:         path,
+		privateKey:   pk,
+		submitter:    submitter,
+		allowed:      allowed,
+		byHash:       make(map[string]int),
+		active:       make(map[string]int),
+		supersededBy: make(map[int]int),
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	anchored, err := l.checkHead()
+	if err != nil {
+		return nil, err
+	}
+
+	l.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	if anchored < len(l.entries) {
+		// Entries appended before a crash prevented anchoring them.
+		if err := l.writeHead(); err != nil {
+			l.file.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// OpenLocalLedgerReadOnly opens an existing log for verification only, e.g.
+// by an auditor holding a copy of it. Nothing is created or written: there
+// is no signing key, and the log and its head anchor must already exist.
+// Entries and the anchor are accepted only if signed by one of submitters,
+// which is required because the log cannot vouch for its own signers.
+func OpenLocalLedgerReadOnly(path string, submitters []string) (*LocalLedger, error) {
+	if len(submitters) == 0 {
+		return nil, fmt.Errorf("at least one expected submitter address is required")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	allowed, err := parseSubmitters(submitters)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &LocalLedger{
+		path:         path,
+		allowed:      allowed,
+		byHash:       make(map[string]int),
+		active:       make(map[string]int),
+		supersededBy: make(map[int]int),
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(l.headPath()); err != nil {
+		return nil, fmt.Errorf("%w: head anchor %s is missing", ErrLedgerTampered, l.headPath())
+	}
+	if _, err := l.checkHead(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func parseSubmitters(submitters []string) (map[common.Address]bool, error) {
+	allowed := make(map[common.Address]bool, len(submitters)+1)
+	for _, s := range submitters {
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid submitter address %q", s)
+		}
+		allowed[common.HexToAddress(s)] = true
+	}
+	return allowed, nil
+}
+
+func loadOrCreateLedgerKey(keyPath string) (*ecdsa.PrivateKey, error) {
+	if data, err := os.ReadFile(keyPath); err == nil {
+		pk, err := crypto.HexToECDSA(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ledger key %s: %w", keyPath, err)
+		}
+		return pk, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read ledger key: %w", err)
+	}
+
+	pk, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ledger key: %w", err)
+	}
+	if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(crypto.FromECDSA(pk))), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write ledger key: %w", err)
+	}
+	return pk, nil
+}
+
+// load replays the log, verifying the hash chain and every signature.
+func (l *LocalLedger) load() error {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e localEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("%w: line %d: %v", ErrLedgerTampered, line, err)
+		}
+		if err := l.verifyEntry(e); err != nil {
+			return fmt.Errorf("%w: line %d: %v", ErrLedgerTampered, line, err)
+		}
+		l.apply(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read ledger: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalLedger) verifyEntry(e localEntry) error {
+	if e.Index != len(l.entries) {
+		return fmt.Errorf("entry index %d out of sequence", e.Index)
+	}
+	if e.PrevHash != l.headHash() {
+		return fmt.Errorf("entry %d does not link to the previous entry", e.Index)
+	}
+
+	digest, err := entryDigest(e)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(e.Hash, hexutil.Encode(digest)) {
+		return fmt.Errorf("entry %d hash mismatch", e.Index)
+	}
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(e.Signature, "0x"))
+	if err != nil {
+		return fmt.Errorf("entry %d has a malformed signature", e.Index)
+	}
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != common.HexToAddress(e.Submitter) {
+		return fmt.Errorf("entry %d signature does not match submitter", e.Index)
+	}
+	if !l.allowed[crypto.PubkeyToAddress(*pub)] {
+		return fmt.Errorf("entry %d signed by unauthorized submitter %s", e.Index, e.Submitter)
+	}
+	return nil
+}
+
+func (l *LocalLedger) headPath() string {
+	return l.path + ".head"
+}
+
+// checkHead verifies the replayed log against its head anchor and returns
+// the number of entries the anchor covers. The log may hold more entries
+// than anchored (the process stopped between appending and anchoring), but
+// never fewer: that means it was truncated.
+func (l *LocalLedger) checkHead() (int, error) {
+	data, err := os.ReadFile(l.headPath())
+	if os.IsNotExist(err) {
+		if len(l.entries) > 0 {
+			return 0, fmt.Errorf("%w: head anchor %s is missing", ErrLedgerTampered, l.headPath())
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read ledger head: %w", err)
+	}
+
+	var head localHead
+	if err := json.Unmarshal(data, &head); err != nil {
+		return 0, fmt.Errorf("%w: malformed head anchor: %v", ErrLedgerTampered, err)
+	}
+	digest, err := headDigest(head)
+	if err != nil {
+		return 0, err
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(head.Signature, "0x"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: head anchor has a malformed signature", ErrLedgerTampered)
+	}
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil || !l.allowed[crypto.PubkeyToAddress(*pub)] {
+		return 0, fmt.Errorf("%w: head anchor not signed by an authorized submitter", ErrLedgerTampered)
+	}
+
+	switch {
+	case head.Count > len(l.entries):
+		return 0, fmt.Errorf("%w: head anchors %d entries but the log has %d", ErrLedgerTampered, head.Count, len(l.entries))
+	case head.Count > 0 && !strings.EqualFold(l.entries[head.Count-1].Hash, head.HeadHash):
+		return 0, fmt.Errorf("%w: entry %d does not match the head anchor", ErrLedgerTampered, head.Count-1)
+	case head.Count == 0 && head.HeadHash != common.Hash{}.Hex():
+		return 0, fmt.Errorf("%w: empty head anchor with a head hash", ErrLedgerTampered)
+	}
+	return head.Count, nil
+}
+
+// writeHead signs the current entry count and head hash and atomically
+// replaces the head anchor.
+func (l *LocalLedger) writeHead() error {
+	head := localHead{Count: len(l.entries), HeadHash: l.headHash()}
+	digest, err := headDigest(head)
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(digest, l.privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign ledger head: %w", err)
+	}
+	head.Signature = hexutil.Encode(sig)
+
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	tmp := l.headPath() + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to write ledger head: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write ledger head: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync ledger head: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write ledger head: %w", err)
+	}
+	if err := os.Rename(tmp, l.headPath()); err != nil {
+		return fmt.Errorf("failed to replace ledger head: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalLedger) apply(e localEntry) {
+	l.entries = append(l.entries, e)
+	l.byHash[strings.ToLower(e.Hash)] = e.Index
+	if e.Supersedes != nil {
+		l.supersededBy[*e.Supersedes] = e.Index
+	}
+	l.active[e.TaskID] = e.Index
+}
+
+func (l *LocalLedger) headHash() string {
+	if len(l.entries) == 0 {
+		return common.Hash{}.Hex()
+	}
+	return l.entries[len(l.entries)-1].Hash
+}
+
+// entryDigest is keccak256 of the canonical JSON of the entry without its
+// hash and signature.
+func entryDigest(e localEntry) ([]byte, error) {
+	e.Hash = ""
+	e.Signature = ""
+	body, err := CanonicalJSON(e)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(body), nil
+}
+
+// headDigest is keccak256 of the canonical JSON of the head without its
+// signature.
+func headDigest(h localHead) ([]byte, error) {
+	h.Signature = ""
+	body, err := CanonicalJSON(h)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(body), nil
+}
+
+func (l *LocalLedger) append(op, taskID, rationaleHash, consensusHash string, supersedes *int) (string, error) {
+	if l.privateKey == nil || l.file == nil {
+		return "", ErrLedgerReadOnly
+	}
+	e := localEntry{
+		Index:         len(l.entries),
+		Op:            op,
+		TaskID:        taskID,
+		RationaleHash: common.HexToHash(rationaleHash).Hex(),
+		ConsensusHash: common.HexToHash(consensusHash).Hex(),
+		Supersedes:    supersedes,
+		Submitter:     l.submitter.Hex(),
+		Timestamp:     time.Now().Unix(),
+		PrevHash:      l.headHash(),
+	}
+
+	digest, err := entryDigest(e)
+	if err != nil {
+		return "", err
+	}
+	sig, err := crypto.Sign(digest, l.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign ledger entry: %w", err)
+	}
+	e.Hash = hexutil.Encode(digest)
+	e.Signature = hexutil.Encode(sig)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return "", fmt.Errorf("failed to append ledger entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return "", fmt.Errorf("failed to sync ledger: %w", err)
+	}
+
+	l.apply(e)
+	if err := l.writeHead(); err != nil {
+		return "", err
+	}
+	return e.Hash, nil
+}
+
+// InsertLog appends the first log of a task.
+func (l *LocalLedger) InsertLog(ctx context.Context, taskID, rationaleHash, consensusHash string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.active[taskID]; exists {
+		return "", fmt.Errorf("log already exists for task %s", taskID)
+	}
+	return l.append("insert", taskID, rationaleHash, consensusHash, nil)
+}
+
+// CorrectLog appends a log superseding the task's active log.
+func (l *LocalLedger) CorrectLog(ctx context.Context, oldTaskID, rationaleHash, consensusHash string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, exists := l.active[oldTaskID]
+	if !exists {
+		return "", fmt.Errorf("no active log for task %s", oldTaskID)
+	}
+	return l.append("correct", oldTaskID, rationaleHash, consensusHash, &current)
+}
+
+// VerifyHashes checks the hashes against the task's active log.
+func (l *LocalLedger) VerifyHashes(ctx context.Context, taskID, rationaleHash, consensusHash string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx, exists := l.active[taskID]
+	if !exists {
+		return false, nil
+	}
+	e := l.entries[idx]
+	return e.RationaleHash == common.HexToHash(rationaleHash).Hex() &&
+		e.ConsensusHash == common.HexToHash(consensusHash).Hex(), nil
+}
+
+// GetActiveLog returns the task's active log with the contract's keys.
+func (l *LocalLedger) GetActiveLog(ctx context.Context, taskID string) (map[string]interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx, exists := l.active[taskID]
+	if !exists {
+		return nil, fmt.Errorf("no active log for task %s", taskID)
+	}
+	e := l.entries[idx]
+	return map[string]interface{}{
+		"rationale_hash": strings.ToLower(e.RationaleHash),
+		"consensus_hash": strings.ToLower(e.ConsensusHash),
+		"submitter":      e.Submitter,
+		"timestamp":      e.Timestamp,
+		"block_number":   int64(e.Index + 1),
+		"status":         LogStatusActive,
+		"superseded_by":  int64(0),
+	}, nil
+}
+
+// WaitForConfirmation returns a receipt for an appended entry. Entries are
+// durable once written, so there is nothing to wait for.
+func (l *LocalLedger) WaitForConfirmation(ctx context.Context, txHash string, timeout time.Duration) (*types.Receipt, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx, exists := l.byHash[strings.ToLower(txHash)]
+	if !exists {
+		return nil, fmt.Errorf("transaction %s not found in local ledger", txHash)
+	}
+	return &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      common.HexToHash(txHash),
+		BlockNumber: big.NewInt(int64(idx + 1)),
+	}, nil
+}
+
+func (l *LocalLedger) Network() string {
+	return localNetwork
+}
+
+// ContractAddress returns the log path, the local equivalent of the contract.
+func (l *LocalLedger) ContractAddress() string {
+	return l.path
+}
+
+// Submitter returns the address that signs new entries.
+func (l *LocalLedger) Submitter() string {
+	return l.submitter.Hex()
+}
+
+func (l *LocalLedger) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+}
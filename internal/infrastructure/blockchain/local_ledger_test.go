@@ -0,0 +1,271 @@
+package blockchain_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	rationaleA = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	consensusA = "0x2222222222222222222222222222222222222222222222222222222222222222"
+	rationaleB = "0x3333333333333333333333333333333333333333333333333333333333333333"
+	consensusB = "0x4444444444444444444444444444444444444444444444444444444444444444"
+)
+
+func TestLocalLedgerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	l, err := blockchain.NewLocalLedger(path, "")
+	if err != nil {
+		t.Fatalf("NewLocalLedger: %v", err)
+	}
+
+	tx, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA)
+	if err != nil {
+		t.Fatalf("InsertLog: %v", err)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err == nil {
+		t.Errorf("duplicate insert should fail")
+	}
+	receipt, err := l.WaitForConfirmation(ctx, tx, time.Second)
+	if err != nil || receipt.BlockNumber.Int64() != 1 {
+		t.Fatalf("WaitForConfirmation: %v, %+v", err, receipt)
+	}
+
+	if ok, _ := l.VerifyHashes(ctx, "task-1", rationaleA, consensusA); !ok {
+		t.Errorf("inserted hashes should verify")
+	}
+	if _, err := l.CorrectLog(ctx, "task-1", rationaleB, consensusB); err != nil {
+		t.Fatalf("CorrectLog: %v", err)
+	}
+	if ok, _ := l.VerifyHashes(ctx, "task-1", rationaleA, consensusA); ok {
+		t.Errorf("superseded hashes should no longer verify")
+	}
+	l.Close()
+
+	// Reopening replays and verifies the chain.
+	l, err = blockchain.NewLocalLedger(path, "")
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer l.Close()
+
+	entry, err := l.GetActiveLog(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("GetActiveLog: %v", err)
+	}
+	if entry["rationale_hash"] != rationaleB || entry["block_number"] != int64(2) {
+		t.Errorf("active log = %+v", entry)
+	}
+
+	history, err := l.GetTaskHistory(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("GetTaskHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history has %d entries, want 2", len(history))
+	}
+	if history[0].Status != blockchain.LogStatusSuperseded || history[0].SupersededBy == nil || *history[0].SupersededBy != 1 {
+		t.Errorf("first entry should be superseded by the correction: %+v", history[0])
+	}
+	if history[1].Status != blockchain.LogStatusActive || history[1].Supersedes == nil || history[1].RationaleHash != rationaleB {
+		t.Errorf("correction entry = %+v", history[1])
+	}
+}
+
+func TestLocalLedgerDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	l, err := blockchain.NewLocalLedger(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), rationaleA[2:], rationaleB[2:], 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := blockchain.NewLocalLedger(path, ""); !errors.Is(err, blockchain.ErrLedgerTampered) {
+		t.Fatalf("expected ErrLedgerTampered, got %v", err)
+	}
+}
+
+func TestLocalLedgerDetectsTruncation(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	l, err := blockchain.NewLocalLedger(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err != nil {
+		t.Fatal(err)
+	}
+	anchoredOne, err := os.ReadFile(path + ".head")
+	if err != nil {
+		t.Fatalf("head anchor not written: %v", err)
+	}
+	if _, err := l.InsertLog(ctx, "task-2", rationaleB, consensusB); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+
+	// An older head is accepted: the entries after it are still signed.
+	if err := os.WriteFile(path+".head", anchoredOne, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	l, err = blockchain.NewLocalLedger(path, "")
+	if err != nil {
+		t.Fatalf("log ahead of its head anchor: %v", err)
+	}
+	l.Close()
+
+	// Dropping the last entry is not.
+	if err := os.WriteFile(path, []byte(lines[0]), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.NewLocalLedger(path, ""); !errors.Is(err, blockchain.ErrLedgerTampered) {
+		t.Fatalf("truncated log: expected ErrLedgerTampered, got %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path + ".head"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.NewLocalLedger(path, ""); !errors.Is(err, blockchain.ErrLedgerTampered) {
+		t.Fatalf("missing head anchor: expected ErrLedgerTampered, got %v", err)
+	}
+}
+
+func TestLocalLedgerSubmitterAllowlist(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+
+	oldKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := blockchain.NewLocalLedger(path, hex.EncodeToString(crypto.FromECDSA(oldKey)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	cfg := config.BlockchainConfig{
+		Backend:      config.LedgerBackendLocal,
+		LocalLogPath: path,
+		PrivateKey:   hex.EncodeToString(crypto.FromECDSA(newKey)),
+	}
+	if _, err := blockchain.NewLedger(cfg, blockchain.LedgerDeps{}); !errors.Is(err, blockchain.ErrLedgerTampered) {
+		t.Fatalf("entries of an unlisted submitter: expected ErrLedgerTampered, got %v", err)
+	}
+
+	cfg.LocalSubmitters = []string{crypto.PubkeyToAddress(oldKey.PublicKey).Hex()}
+	rotated, err := blockchain.NewLedger(cfg, blockchain.LedgerDeps{})
+	if err != nil {
+		t.Fatalf("entries of a listed submitter: %v", err)
+	}
+	defer rotated.Close()
+	if _, err := rotated.CorrectLog(ctx, "task-1", rationaleB, consensusB); err != nil {
+		t.Fatalf("CorrectLog with the new key: %v", err)
+	}
+}
+
+func TestOpenLocalLedgerReadOnly(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+
+	if _, err := blockchain.OpenLocalLedgerReadOnly(path, []string{"0x03252339418744A98F03D4ED979dF36Cd75308D4"}); err == nil {
+		t.Fatal("opening a missing log read-only should fail")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("read-only open of a missing log created %d files", len(entries))
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := blockchain.NewLocalLedger(path, hex.EncodeToString(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	before, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := blockchain.OpenLocalLedgerReadOnly(path, nil); err == nil {
+		t.Fatal("read-only open without expected submitters should fail")
+	}
+	other, _ := crypto.GenerateKey()
+	if _, err := blockchain.OpenLocalLedgerReadOnly(path, []string{crypto.PubkeyToAddress(other.PublicKey).Hex()}); !errors.Is(err, blockchain.ErrLedgerTampered) {
+		t.Fatalf("log of another submitter: expected ErrLedgerTampered, got %v", err)
+	}
+
+	ro, err := blockchain.OpenLocalLedgerReadOnly(path, []string{crypto.PubkeyToAddress(key.PublicKey).Hex()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Close()
+	if ok, err := ro.VerifyHashes(ctx, "task-1", rationaleA, consensusA); err != nil || !ok {
+		t.Errorf("VerifyHashes = %v, %v", ok, err)
+	}
+	if _, err := ro.InsertLog(ctx, "task-2", rationaleA, consensusA); !errors.Is(err, blockchain.ErrLedgerReadOnly) {
+		t.Errorf("InsertLog on a read-only ledger: expected ErrLedgerReadOnly, got %v", err)
+	}
+	if after, _ := os.ReadDir(dir); len(after) != len(before) {
+		t.Errorf("read-only open changed the ledger directory: %d files, was %d", len(after), len(before))
+	}
+
+	// Truncation is still detected through the head anchor.
+	if err := os.WriteFile(path, nil, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.OpenLocalLedgerReadOnly(path, []string{crypto.PubkeyToAddress(key.PublicKey).Hex()}); !errors.Is(err, blockchain.ErrLedgerTampered) {
+		t.Errorf("truncated log: expected ErrLedgerTampered, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Merkle batching spec (v1).
+//
+// In batch mode many tasks share one anchored log. Each task contributes a
+// leaf and the batch anchors the root under the batch ID:
+//
+//	leaf        = sha256(0x00 || JCS({"task_id", "rationale_hash", "consensus_hash"}))
+//	node        = sha256(0x01 || left || right)
+//	insertLog(batch_id, merkle_root, commitment)
+//	commitment  = sha256(JCS({"batch_id", "merkle_root", "leaf_count"}))
+//
+// Hashes inside the leaf envelope are lowercase hex without 0x. The 0x00 and
+// 0x01 prefixes keep a leaf from ever being reinterpreted as an inner node.
+// An unpaired node on a level is carried up unchanged rather than hashed
+// with itself, so no two different leaf lists share a root.
+
+// Proof step positions: the sibling hash goes on this side when recomputing.
+const (
+	ProofLeft  = "left"
+	ProofRight = "right"
+)
+
+// ProofStep is one sibling on the path from a leaf to the root.
+type ProofStep struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"`
+}
+
+// MerkleLeaf returns the leaf hash for a task's anchored hashes.
+func MerkleLeaf(taskID, rationaleHash, consensusHash string) (string, error) {
+	body, err := CanonicalJSON(map[string]interface{}{
+		"task_id":        taskID,
+		"rationale_hash": NormalizeHash(rationaleHash),
+		"consensus_hash": NormalizeHash(consensusHash),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte{0x00}, body...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// BuildMerkleTree returns the root over leaves and the inclusion proof of
+// every leaf, in the same order.
+func BuildMerkleTree(leaves []string) (string, [][]ProofStep, error) {
+	if len(leaves) == 0 {
+		return "", nil, fmt.Errorf("merkle tree needs at least one leaf")
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		b, err := hex.DecodeString(NormalizeHash(leaf))
+		if err != nil || len(b) != sha256.Size {
+			return "", nil, fmt.Errorf("invalid merkle leaf %d", i)
+		}
+		level[i] = b
+	}
+
+	proofs := make([][]ProofStep, len(leaves))
+	// positions[i] is the index of leaf i's ancestor on the current level.
+	positions := make([]int, len(leaves))
+	for i := range positions {
+		positions[i] = i
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashNode(level[i], level[i+1]))
+		}
+
+		for leaf, pos := range positions {
+			sibling := pos ^ 1
+			if sibling < len(level) {
+				position := ProofRight
+				if sibling < pos {
+					position = ProofLeft
+				}
+				proofs[leaf] = append(proofs[leaf], ProofStep{Hash: hex.EncodeToString(level[sibling]), Position: position})
+			}
+			positions[leaf] = pos / 2
+		}
+		level = next
+	}
+
+	for i := range proofs {
+		if proofs[i] == nil {
+			proofs[i] = []ProofStep{}
+		}
+	}
+	return hex.EncodeToString(level[0]), proofs, nil
+}
+
+// MerkleRootFromProof recomputes the root from a leaf and its proof.
+func MerkleRootFromProof(leaf string, proof []ProofStep) (string, error) {
+	current, err := hex.DecodeString(NormalizeHash(leaf))
+	if err != nil || len(current) != sha256.Size {
+		return "", fmt.Errorf("invalid merkle leaf")
+	}
+	for i, step := range proof {
+		sibling, err := hex.DecodeString(NormalizeHash(step.Hash))
+		if err != nil || len(sibling) != sha256.Size {
+			return "", fmt.Errorf("invalid proof step %d", i)
+		}
+		switch step.Position {
+		case ProofLeft:
+			current = hashNode(sibling, current)
+		case ProofRight:
+			current = hashNode(current, sibling)
+		default:
+			return "", fmt.Errorf("invalid proof step %d position %q", i, step.Position)
+		}
+	}
+	return hex.EncodeToString(current), nil
+}
+
+// VerifyMerkleProof reports whether leaf is included under root.
+func VerifyMerkleProof(leaf string, proof []ProofStep, root string) bool {
+	computed, err := MerkleRootFromProof(leaf, proof)
+	return err == nil && HashesEqual(computed, root)
+}
+
+// BatchCommitment is the second hash anchored with a batch root; it binds the
+// root to the batch ID and leaf count.
+func BatchCommitment(batchID, root string, leafCount int) (string, error) {
+	return CanonicalHash(map[string]interface{}{
+		"batch_id":    batchID,
+		"merkle_root": NormalizeHash(root),
+		"leaf_count":  leafCount,
+	})
+}
+
+func hashNode(left, right []byte) []byte {
+	buf := make([]byte, 0, 1+len(left)+len(right))
+	buf = append(buf, 0x01)
+	buf = append(buf, left...)
+	buf = append(buf, right...)
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
@@ -0,0 +1,62 @@
+package blockchain_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+)
+
+func merkleLeaves(t *testing.T, n int) []string {
+	t.Helper()
+	leaves := make([]string, n)
+	for i := range leaves {
+		leaf, err := blockchain.MerkleLeaf(fmt.Sprintf("task-%d", i), rationaleA, consensusA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaves[i] = leaf
+	}
+	return leaves
+}
+
+func TestMerkleProofsVerify(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		leaves := merkleLeaves(t, n)
+		root, proofs, err := blockchain.BuildMerkleTree(leaves)
+		if err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		for i, leaf := range leaves {
+			if !blockchain.VerifyMerkleProof(leaf, proofs[i], root) {
+				t.Errorf("n=%d: proof for leaf %d does not verify", n, i)
+			}
+		}
+		if n > 1 && blockchain.VerifyMerkleProof(leaves[0], proofs[1], root) {
+			t.Errorf("n=%d: proof verified for the wrong leaf", n)
+		}
+	}
+}
+
+func TestMerkleSingleLeafIsRoot(t *testing.T) {
+	leaves := merkleLeaves(t, 1)
+	root, proofs, err := blockchain.BuildMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != leaves[0] || len(proofs[0]) != 0 {
+		t.Errorf("root = %s, proof = %v", root, proofs[0])
+	}
+}
+
+func TestMerkleLeafBindsHashes(t *testing.T) {
+	a, _ := blockchain.MerkleLeaf("task-1", rationaleA, consensusA)
+	b, _ := blockchain.MerkleLeaf("task-1", rationaleA[2:], consensusA)
+	c, _ := blockchain.MerkleLeaf("task-1", rationaleA, consensusB)
+	if a != b {
+		t.Errorf("leaf should not depend on the 0x prefix")
+	}
+	if a == c {
+		t.Errorf("leaf should change with the consensus hash")
+	}
+}
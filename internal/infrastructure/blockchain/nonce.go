@@ -0,0 +1,214 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/redis/go-redis/v9"
+)
+
+// NonceManager hands out account nonces so concurrent submitters never sign
+// two transactions with the same nonce. Acquire blocks until the account is
+// free and returns the nonce to use; release must be called exactly once,
+// with used=true if a transaction with that nonce reached the node.
+type NonceManager interface {
+	Acquire(ctx context.Context, chainID uint64, account common.Address, pending uint64) (nonce uint64, release func(used bool), err error)
+}
+
+// The node's pending nonce is passed into Acquire and the allocator takes the
+// larger of it and its own counter. That way transactions sent outside the
+// allocator (or lost from it) are never reused, while transactions still
+// travelling to the node are not handed out twice.
+
+// MemoryNonceManager serializes submissions within one process. It is enough
+// for a single replica and for the simulated backend.
+type MemoryNonceManager struct {
+	mu sync.Mutex
+	// locks holds a one-slot channel per account; sending takes the lock,
+	// so waiting can be abandoned when the context ends.
+	locks map[string]chan struct{}
+	next  map[string]uint64
+}
+
+func NewMemoryNonceManager() *MemoryNonceManager {
+	return &MemoryNonceManager{
+		locks: make(map[string]chan struct{}),
+		next:  make(map[string]uint64),
+	}
+}
+
+func (m *MemoryNonceManager) Acquire(ctx context.Context, chainID uint64, account common.Address, pending uint64) (uint64, func(bool), error) {
+	key := nonceKey(chainID, account)
+
+	m.mu.Lock()
+	lock, ok := m.locks[key]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		m.locks[key] = lock
+	}
+	m.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+	case <-ctx.Done():
+		return 0, nil, fmt.Errorf("waiting for nonce lock: %w", ctx.Err())
+	}
+
+	m.mu.Lock()
+	nonce := max(m.next[key], pending)
+	m.mu.Unlock()
+
+	var once sync.Once
+	release := func(used bool) {
+		once.Do(func() {
+			if used {
+				m.mu.Lock()
+				m.next[key] = nonce + 1
+				m.mu.Unlock()
+			}
+			<-lock
+		})
+	}
+	return nonce, release, nil
+}
+
+const (
+	redisNonceLockTTL   = 30 * time.Second
+	redisNonceRetryWait = 50 * time.Millisecond
+)
+
+// refreshLockScript extends the lock only if this holder still owns it.
+var refreshLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseLockScript stores the next nonce (when ARGV[2] is set) and deletes
+// the lock, but only if this holder still owns it. A holder whose lock
+// expired must not overwrite the counter of the holder that took over.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if ARGV[2] ~= "" then
+	redis.call("SET", KEYS[2], ARGV[2])
+end
+return redis.call("DEL", KEYS[1])`)
+
+// RedisNonceManager serializes submissions across replicas with a Redis
+// lock per account and keeps the next nonce in Redis. The lock is refreshed
+// while it is held, so a slow send does not let another replica in.
+type RedisNonceManager struct {
+	client *redis.Client
+}
+
+func NewRedisNonceManager(client *redis.Client) *RedisNonceManager {
+	return &RedisNonceManager{client: client}
+}
+
+func (m *RedisNonceManager) Acquire(ctx context.Context, chainID uint64, account common.Address, pending uint64) (uint64, func(bool), error) {
+	key := "blockchain:nonce:" + nonceKey(chainID, account)
+	lockKey := key + ":lock"
+
+	token, err := randomToken()
+	if err != nil {
+		return 0, nil, err
+	}
+	for {
+		ok, err := m.client.SetNX(ctx, lockKey, token, redisNonceLockTTL).Result()
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to acquire nonce lock: %w", err)
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return 0, nil, fmt.Errorf("waiting for nonce lock: %w", ctx.Err())
+		case <-time.After(redisNonceRetryWait):
+		}
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go m.refreshLock(lockKey, token, stop, stopped)
+
+	unlock := func(next string) {
+		close(stop)
+		<-stopped
+		// Use a fresh context: the caller's may already be cancelled.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		owned, err := releaseLockScript.Run(unlockCtx, m.client, []string{lockKey, key}, token, next).Int()
+		if err == nil && owned == 0 {
+			log.Printf("[Blockchain] Lock %s expired before release; nonce counter left to the next holder", lockKey)
+		}
+	}
+
+	stored, err := m.client.Get(ctx, key).Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		unlock("")
+		return 0, nil, fmt.Errorf("failed to read nonce: %w", err)
+	}
+	nonce := max(stored, pending)
+
+	var once sync.Once
+	release := func(used bool) {
+		once.Do(func() {
+			next := ""
+			if used {
+				next = strconv.FormatUint(nonce+1, 10)
+			}
+			unlock(next)
+		})
+	}
+	return nonce, release, nil
+}
+
+// refreshLock extends the lock every third of its TTL until stop is closed
+// or the lock is lost, then closes done.
+func (m *RedisNonceManager) refreshLock(lockKey, token string, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(redisNonceLockTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		owned, err := refreshLockScript.Run(ctx, m.client, []string{lockKey}, token, redisNonceLockTTL.Milliseconds()).Int()
+		cancel()
+		if err != nil {
+			log.Printf("[Blockchain] Failed to refresh lock %s: %v", lockKey, err)
+			continue
+		}
+		if owned == 0 {
+			log.Printf("[Blockchain] Lost lock %s while holding it", lockKey)
+			return
+		}
+	}
+}
+
+func nonceKey(chainID uint64, account common.Address) string {
+	return fmt.Sprintf("%d:%s", chainID, strings.ToLower(account.Hex()))
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,145 @@
+package blockchain_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemoryNonceManagerConcurrent(t *testing.T) {
+	ctx := context.Background()
+	m := blockchain.NewMemoryNonceManager()
+	account := common.HexToAddress("0x03252339418744A98F03D4ED979dF36Cd75308D4")
+
+	const workers = 50
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[uint64]bool)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every worker sees the same stale pending nonce from the node.
+			nonce, release, err := m.Acquire(ctx, 1, account, 7)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			if seen[nonce] {
+				t.Errorf("nonce %d handed out twice", nonce)
+			}
+			seen[nonce] = true
+			mu.Unlock()
+			release(true)
+		}()
+	}
+	wg.Wait()
+
+	for n := uint64(7); n < 7+workers; n++ {
+		if !seen[n] {
+			t.Errorf("nonce %d skipped", n)
+		}
+	}
+}
+
+func TestMemoryNonceManagerReuseAndResync(t *testing.T) {
+	ctx := context.Background()
+	m := blockchain.NewMemoryNonceManager()
+	account := common.HexToAddress("0x03252339418744A98F03D4ED979dF36Cd75308D4")
+
+	nonce, release, _ := m.Acquire(ctx, 1, account, 3)
+	release(false) // send failed: the nonce is still free
+	again, release, _ := m.Acquire(ctx, 1, account, 3)
+	if again != nonce {
+		t.Fatalf("unused nonce %d not reused, got %d", nonce, again)
+	}
+	release(true)
+
+	// The node moved ahead (a transaction was sent elsewhere).
+	ahead, release, _ := m.Acquire(ctx, 1, account, 10)
+	release(true)
+	if ahead != 10 {
+		t.Errorf("allocator did not follow the node's pending nonce, got %d", ahead)
+	}
+
+	// A different chain keeps its own counter.
+	other, release, _ := m.Acquire(ctx, 2, account, 0)
+	release(true)
+	if other != 0 {
+		t.Errorf("nonce leaked across chains, got %d", other)
+	}
+}
+
+func TestMemoryNonceManagerAcquireHonoursContext(t *testing.T) {
+	m := blockchain.NewMemoryNonceManager()
+	account := common.HexToAddress("0x03252339418744A98F03D4ED979dF36Cd75308D4")
+
+	_, release, err := m.Acquire(context.Background(), 1, account, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := m.Acquire(ctx, 1, account, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire while locked: err = %v, want deadline exceeded", err)
+	}
+
+	release(true)
+	nonce, release, err := m.Acquire(context.Background(), 1, account, 0)
+	if err != nil || nonce != 1 {
+		t.Fatalf("Acquire after release = %d, %v; want 1", nonce, err)
+	}
+	release(false)
+}
+
+func TestRedisNonceManagerExpiredLock(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	m := blockchain.NewRedisNonceManager(client)
+	account := common.HexToAddress("0x03252339418744A98F03D4ED979dF36Cd75308D4")
+
+	first, releaseFirst, err := m.Acquire(ctx, 1, account, 5)
+	if err != nil || first != 5 {
+		t.Fatalf("first Acquire = %d, %v", first, err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, _, err := m.Acquire(waitCtx, 1, account, 5); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire while locked: err = %v, want deadline exceeded", err)
+	}
+
+	// The first holder stalls past the lock TTL and another replica takes over.
+	mr.FastForward(time.Minute)
+	second, releaseSecond, err := m.Acquire(ctx, 1, account, 6)
+	if err != nil || second != 6 {
+		t.Fatalf("second Acquire = %d, %v", second, err)
+	}
+
+	// The stale holder must neither free the new lock nor move the counter.
+	releaseFirst(true)
+	keys := mr.Keys()
+	if len(keys) != 1 || !strings.HasSuffix(keys[0], ":lock") {
+		t.Fatalf("keys after stale release = %v, want only the second holder's lock", keys)
+	}
+
+	releaseSecond(true)
+	next, release, err := m.Acquire(ctx, 1, account, 0)
+	if err != nil || next != 7 {
+		t.Fatalf("Acquire after release = %d, %v; want 7", next, err)
+	}
+	release(false)
+}
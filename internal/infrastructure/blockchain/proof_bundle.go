@@ -0,0 +1,293 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/digitorus/timestamp"
+)
+
+// ProofBundleVersion is the format version of ProofBundle.
+const ProofBundleVersion = 1
+
+// ProofBundle is everything an outside party needs to check a published
+// swarm result without access to this service: the canonical result, the
+// hashes it commits to, and where and how they were anchored. The offline
+// checks recompute every hash from the bundle itself; the on-chain check
+// only needs the network's RPC endpoint.
+type ProofBundle struct {
+	Version     int       `json:"version"`
+	TaskID      string    `json:"taskId"`
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// Result is the canonical consensus envelope (see ConsensusEnvelope);
+	// ConsensusHash is its SHA-256.
+	Result        json.RawMessage `json:"result"`
+	RationaleHash string          `json:"rationaleHash"`
+	ConsensusHash string          `json:"consensusHash"`
+
+	Review *BundleReview `json:"review,omitempty"`
+	Batch  *BundleBatch  `json:"batch,omitempty"`
+	Anchor BundleAnchor  `json:"anchor"`
+
+	// Timestamp is set for anchors of the tsa backend, whose proof is the
+	// token itself rather than a log on a chain.
+	Timestamp *BundleTimestamp `json:"timestamp,omitempty"`
+}
+
+// BundleTimestamp is the RFC 3161 token over the anchor (see
+// TimestampMessage). Its SHA-256 is the anchor's TxHash.
+type BundleTimestamp struct {
+	TSA   string `json:"tsa"`
+	Token []byte `json:"token"`
+}
+
+// TimestampProver is implemented by ledgers whose anchors can be proven
+// offline; AttachTimestamp adds the anchor's token to a bundle.
+type TimestampProver interface {
+	AttachTimestamp(ctx context.Context, b *ProofBundle) error
+}
+
+// BundleReview is a confirmed reviewer override, which superseded the
+// machine result on the ledger. Its hashes are recomputed from these fields.
+type BundleReview struct {
+	Verdict       string    `json:"verdict"`
+	Justification string    `json:"justification"`
+	ReviewerID    string    `json:"reviewerId"`
+	ReviewedAt    time.Time `json:"reviewedAt"`
+	RationaleHash string    `json:"rationaleHash"`
+	ConsensusHash string    `json:"consensusHash"`
+}
+
+// BundleBatch is the Merkle path from the task's leaf to its batch root.
+type BundleBatch struct {
+	BatchID    string      `json:"batchId"`
+	MerkleRoot string      `json:"merkleRoot"`
+	Commitment string      `json:"commitment"`
+	LeafCount  int         `json:"leafCount"`
+	LeafIndex  int         `json:"leafIndex"`
+	Proof      []ProofStep `json:"proof"`
+}
+
+// BundleAnchor is the ledger log to look up: verifyHashes(LogID,
+// RationaleHash, ConsensusHash) must return true on Contract.
+type BundleAnchor struct {
+	Network       string `json:"network"`
+	Contract      string `json:"contract"`
+	LogID         string `json:"logId"`
+	RationaleHash string `json:"rationaleHash"`
+	ConsensusHash string `json:"consensusHash"`
+	TxHash        string `json:"txHash"`
+	BlockNumber   uint64 `json:"blockNumber"`
+}
+
+// NewProofBundle assembles the bundle of an anchored task. batch must be the
+// task's batch when the task was anchored through one.
+func NewProofBundle(task *domain.SwarmTask, batch *domain.AnchorBatch, network, contract string) (*ProofBundle, error) {
+	var resultsVal interface{}
+	if len(task.Results) > 0 {
+		if err := json.Unmarshal(task.Results, &resultsVal); err != nil {
+			return nil, fmt.Errorf("decode stored results: %w", err)
+		}
+	}
+	result, err := CanonicalJSON(ConsensusEnvelope(task.ID, task.Summary, resultsVal))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &ProofBundle{
+		Version:       ProofBundleVersion,
+		TaskID:        task.ID,
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		Result:        result,
+		RationaleHash: "0x" + NormalizeHash(task.RationaleHash),
+		ConsensusHash: "0x" + NormalizeHash(task.ConsensusHash),
+		Anchor: BundleAnchor{
+			Network:       network,
+			Contract:      contract,
+			LogID:         task.ID,
+			RationaleHash: "0x" + NormalizeHash(task.RationaleHash),
+			ConsensusHash: "0x" + NormalizeHash(task.ConsensusHash),
+			TxHash:        task.BlockchainTx,
+		},
+	}
+
+	switch {
+	case task.ReviewChainStat == domain.BlockchainStatusVerified && task.ReviewRationaleHash != "":
+		if task.ReviewedBy == nil || task.ReviewedAt == nil {
+			return nil, fmt.Errorf("override of task %s is missing its reviewer", task.ID)
+		}
+		b.Review = &BundleReview{
+			Verdict:       task.ReviewVerdict,
+			Justification: task.ReviewJustification,
+			ReviewerID:    *task.ReviewedBy,
+			ReviewedAt:    task.ReviewedAt.UTC().Truncate(time.Second),
+			RationaleHash: "0x" + NormalizeHash(task.ReviewRationaleHash),
+			ConsensusHash: "0x" + NormalizeHash(task.ReviewConsensusHash),
+		}
+		b.Anchor.RationaleHash = b.Review.RationaleHash
+		b.Anchor.ConsensusHash = b.Review.ConsensusHash
+		b.Anchor.TxHash = task.ReviewTx
+	case task.BatchID != nil:
+		if batch == nil || batch.ID != *task.BatchID || task.LeafIndex == nil {
+			return nil, fmt.Errorf("batch of task %s is missing", task.ID)
+		}
+		var proof []ProofStep
+		if err := json.Unmarshal(task.MerkleProof, &proof); err != nil {
+			return nil, fmt.Errorf("invalid merkle proof: %w", err)
+		}
+		b.Batch = &BundleBatch{
+			BatchID:    batch.ID,
+			MerkleRoot: "0x" + NormalizeHash(batch.MerkleRoot),
+			Commitment: "0x" + NormalizeHash(batch.Commitment),
+			LeafCount:  batch.LeafCount,
+			LeafIndex:  *task.LeafIndex,
+			Proof:      proof,
+		}
+		b.Anchor.LogID = batch.ID
+		b.Anchor.RationaleHash = b.Batch.MerkleRoot
+		b.Anchor.ConsensusHash = b.Batch.Commitment
+		b.Anchor.TxHash = batch.TxHash
+	}
+	return b, nil
+}
+
+// VerifyOffline recomputes every hash in the bundle and checks that they
+// lead to the anchored log. It does not contact the ledger.
+func (b *ProofBundle) VerifyOffline() error {
+	if b.Version != ProofBundleVersion {
+		return fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(b.Result, &envelope); err != nil {
+		return fmt.Errorf("result is not a JSON object: %w", err)
+	}
+	if envelope["task_id"] != b.TaskID {
+		return fmt.Errorf("result belongs to task %v, not %s", envelope["task_id"], b.TaskID)
+	}
+	resultHash, err := CanonicalHash(envelope)
+	if err != nil {
+		return err
+	}
+	if !HashesEqual(resultHash, b.ConsensusHash) {
+		return fmt.Errorf("result hashes to %s, bundle claims consensus hash %s", resultHash, b.ConsensusHash)
+	}
+
+	rationale, consensus, logID := b.RationaleHash, b.ConsensusHash, b.TaskID
+	switch {
+	case b.Review != nil:
+		rationale, consensus, err = ComputeReviewHashes(b.TaskID, b.ConsensusHash, b.Review.Verdict, b.Review.Justification, b.Review.ReviewerID, b.Review.ReviewedAt)
+		if err != nil {
+			return err
+		}
+		if !HashesEqual(rationale, b.Review.RationaleHash) || !HashesEqual(consensus, b.Review.ConsensusHash) {
+			return fmt.Errorf("review fields do not hash to the review hashes")
+		}
+	case b.Batch != nil:
+		leaf, err := MerkleLeaf(b.TaskID, b.RationaleHash, b.ConsensusHash)
+		if err != nil {
+			return err
+		}
+		root, err := MerkleRootFromProof(leaf, b.Batch.Proof)
+		if err != nil {
+			return err
+		}
+		if !HashesEqual(root, b.Batch.MerkleRoot) {
+			return fmt.Errorf("merkle path leads to %s, not the batch root %s", root, b.Batch.MerkleRoot)
+		}
+		commitment, err := BatchCommitment(b.Batch.BatchID, root, b.Batch.LeafCount)
+		if err != nil {
+			return err
+		}
+		if !HashesEqual(commitment, b.Batch.Commitment) {
+			return fmt.Errorf("batch commitment does not match its root")
+		}
+		rationale, consensus, logID = root, commitment, b.Batch.BatchID
+	}
+
+	if b.Anchor.LogID != logID || !HashesEqual(b.Anchor.RationaleHash, rationale) || !HashesEqual(b.Anchor.ConsensusHash, consensus) {
+		return fmt.Errorf("anchor does not match the hashes derived from the bundle")
+	}
+
+	if b.Timestamp != nil {
+		tokenHash := sha256.Sum256(b.Timestamp.Token)
+		if !HashesEqual(hex.EncodeToString(tokenHash[:]), b.Anchor.TxHash) {
+			return fmt.Errorf("timestamp token is not the anchor's token %s", b.Anchor.TxHash)
+		}
+		if _, err := b.timestampToken(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyTimestamp checks the bundle's timestamp token against the TSA's CA
+// certificates: that it covers the anchor and that its signer chains to
+// roots. It is the tsa backend's equivalent of VerifyOnChain and needs no
+// access to the TSA.
+func (b *ProofBundle) VerifyTimestamp(roots *x509.CertPool) (time.Time, error) {
+	if b.Timestamp == nil {
+		return time.Time{}, fmt.Errorf("bundle carries no timestamp token")
+	}
+	ts, err := b.timestampToken(roots)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ts.Time, nil
+}
+
+// timestampToken parses the bundle's token and checks that it covers the
+// anchor. The certificate chain is only checked when roots is set.
+func (b *ProofBundle) timestampToken(roots *x509.CertPool) (*timestamp.Timestamp, error) {
+	digest, err := TimestampMessage(b.Anchor.LogID, b.Anchor.RationaleHash, b.Anchor.ConsensusHash)
+	if err != nil {
+		return nil, err
+	}
+	if roots != nil {
+		return VerifyTimestampToken(b.Timestamp.Token, digest, roots)
+	}
+	ts, err := timestamp.Parse(b.Timestamp.Token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp token: %w", err)
+	}
+	if ts.HashAlgorithm != crypto.SHA256 || !bytes.Equal(ts.HashedMessage, digest) {
+		return nil, fmt.Errorf("timestamp token does not cover the anchor")
+	}
+	return ts, nil
+}
+
+// VerifyOnChain checks the bundle's anchor against a ledger and, when the
+// bundle names one, that the anchoring transaction succeeded in that block.
+func (b *ProofBundle) VerifyOnChain(ctx context.Context, ledger Ledger) error {
+	ok, err := ledger.VerifyHashes(ctx, b.Anchor.LogID, b.Anchor.RationaleHash, b.Anchor.ConsensusHash)
+	if err != nil {
+		return fmt.Errorf("verifyHashes: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("ledger does not hold these hashes as the active log of %s", b.Anchor.LogID)
+	}
+
+	if b.Anchor.TxHash == "" {
+		return nil
+	}
+	receipt, err := ledger.WaitForConfirmation(ctx, b.Anchor.TxHash, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("anchoring transaction %s not found: %w", b.Anchor.TxHash, err)
+	}
+	if receipt.Status != 1 {
+		return fmt.Errorf("anchoring transaction %s reverted", b.Anchor.TxHash)
+	}
+	if b.Anchor.BlockNumber != 0 && receipt.BlockNumber != nil && receipt.BlockNumber.Uint64() != b.Anchor.BlockNumber {
+		return fmt.Errorf("anchoring transaction is in block %d, bundle claims %d", receipt.BlockNumber.Uint64(), b.Anchor.BlockNumber)
+	}
+	return nil
+}
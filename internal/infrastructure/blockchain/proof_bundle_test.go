@@ -0,0 +1,173 @@
+package blockchain_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+)
+
+func anchoredTask(t *testing.T, id string) *domain.SwarmTask {
+	t.Helper()
+	results := []byte(`[{"item":"Laptop","verdict":"WAJAR"}]`)
+	rationale, consensus, err := blockchain.ComputeSwarmHashesFromJSON(id, "Harga wajar", results, []byte(`[]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &domain.SwarmTask{
+		ID:             id,
+		Summary:        "Harga wajar",
+		Results:        results,
+		RationaleHash:  rationale,
+		ConsensusHash:  consensus,
+		BlockchainStat: "VERIFIED",
+	}
+}
+
+func TestProofBundleVerifiesOnLedger(t *testing.T) {
+	ctx := context.Background()
+	l, err := blockchain.NewLocalLedger(filepath.Join(t.TempDir(), "audit.jsonl"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	task := anchoredTask(t, "task-1")
+	if task.BlockchainTx, err = l.InsertLog(ctx, task.ID, task.RationaleHash, task.ConsensusHash); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := blockchain.NewProofBundle(task, nil, l.Network(), "")
+	if err != nil {
+		t.Fatalf("NewProofBundle: %v", err)
+	}
+	if err := bundle.VerifyOffline(); err != nil {
+		t.Fatalf("VerifyOffline: %v", err)
+	}
+	if err := bundle.VerifyOnChain(ctx, l); err != nil {
+		t.Fatalf("VerifyOnChain: %v", err)
+	}
+
+	tampered := *bundle
+	tampered.Result = json.RawMessage(`{"results":[{"item":"Laptop","verdict":"MARKUP"}],"summary":"Harga wajar","task_id":"task-1"}`)
+	if err := tampered.VerifyOffline(); err == nil {
+		t.Errorf("edited result should not verify")
+	}
+
+	tampered = *bundle
+	tampered.Anchor.ConsensusHash = consensusB
+	tampered.ConsensusHash = consensusB
+	if err := tampered.VerifyOnChain(ctx, l); err == nil {
+		t.Errorf("hashes not on the ledger should not verify")
+	}
+}
+
+func TestProofBundleBatchedAndReviewed(t *testing.T) {
+	tasks := []*domain.SwarmTask{anchoredTask(t, "task-1"), anchoredTask(t, "task-2"), anchoredTask(t, "task-3")}
+	leaves := make([]string, len(tasks))
+	for i, task := range tasks {
+		leaves[i], _ = blockchain.MerkleLeaf(task.ID, task.RationaleHash, task.ConsensusHash)
+	}
+	root, proofs, err := blockchain.BuildMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := &domain.AnchorBatch{ID: "batch-1", MerkleRoot: root, LeafCount: len(leaves)}
+	batch.Commitment, _ = blockchain.BatchCommitment(batch.ID, root, batch.LeafCount)
+
+	task := tasks[2]
+	index := 2
+	task.BatchID, task.LeafIndex = &batch.ID, &index
+	task.MerkleProof, _ = json.Marshal(proofs[index])
+
+	bundle, err := blockchain.NewProofBundle(task, batch, "local", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.VerifyOffline(); err != nil {
+		t.Fatalf("batched VerifyOffline: %v", err)
+	}
+	if bundle.Anchor.LogID != batch.ID {
+		t.Errorf("batched bundle should point at the batch log, got %s", bundle.Anchor.LogID)
+	}
+	bundle.Batch.Proof[0].Hash = leaves[0]
+	if err := bundle.VerifyOffline(); err == nil {
+		t.Errorf("altered merkle path should not verify")
+	}
+
+	reviewer, reviewedAt := "reviewer-1", time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	task = anchoredTask(t, "task-4")
+	task.ReviewVerdict, task.ReviewJustification = "MARKUP", "Harga pasar lebih rendah"
+	task.ReviewedBy, task.ReviewedAt = &reviewer, &reviewedAt
+	task.ReviewRationaleHash, task.ReviewConsensusHash, _ = blockchain.ComputeReviewHashes(task.ID, task.ConsensusHash, task.ReviewVerdict, task.ReviewJustification, reviewer, reviewedAt)
+	task.ReviewChainStat = "VERIFIED"
+
+	bundle, err = blockchain.NewProofBundle(task, nil, "local", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.VerifyOffline(); err != nil {
+		t.Fatalf("reviewed VerifyOffline: %v", err)
+	}
+	bundle.Review.Verdict = "WAJAR"
+	if err := bundle.VerifyOffline(); err == nil {
+		t.Errorf("altered review verdict should not verify")
+	}
+}
+
+func TestProofBundleTimestampVerifiesOffline(t *testing.T) {
+	ctx := context.Background()
+	cfg, cert := startLocalTSAWithCert(t)
+	l, err := blockchain.NewTSALedger(cfg, "", &memTimestampStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := anchoredTask(t, "task-1")
+	if task.BlockchainTx, err = l.InsertLog(ctx, task.ID, task.RationaleHash, task.ConsensusHash); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := blockchain.NewProofBundle(task, nil, l.Network(), l.ContractAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AttachTimestamp(ctx, bundle); err != nil {
+		t.Fatalf("AttachTimestamp: %v", err)
+	}
+
+	// The bundle travels as JSON; the token must survive the round trip.
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded blockchain.ProofBundle
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.VerifyOffline(); err != nil {
+		t.Fatalf("VerifyOffline: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	if _, err := decoded.VerifyTimestamp(roots); err != nil {
+		t.Errorf("VerifyTimestamp: %v", err)
+	}
+
+	_, otherCert := startLocalTSAWithCert(t)
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(otherCert)
+	if _, err := decoded.VerifyTimestamp(otherRoots); err == nil {
+		t.Errorf("token should not verify against another TSA's certificate")
+	}
+
+	tampered := decoded
+	tampered.Anchor.TxHash = "0x" + strings.Repeat("0", 64)
+	if err := tampered.VerifyOffline(); err == nil {
+		t.Errorf("token that is not the anchor's should not verify")
+	}
+}
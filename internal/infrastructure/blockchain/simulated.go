@@ -0,0 +1,218 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+const (
+	simulatedNetwork   = "simulated"
+	simulatedDeployGas = uint64(5_000_000)
+)
+
+// auditTrailArtifact is the AuditTrail contract deployed when no artifact
+// path is configured. It is assembled by artifacts/gen.go.
+//
+//go:generate go run ./artifacts/gen.go artifacts/AuditTrail.json
+//go:embed artifacts/AuditTrail.json
+var auditTrailArtifact []byte
+
+// SimulatedLedger runs the AuditTrail contract on an in-process go-ethereum
+// chain. Every transaction is mined as soon as it is sent, so the full
+// insert/confirm/verify flow works without any network access. State lives
+// in memory and is lost on Close.
+type SimulatedLedger struct {
+	*AuditTrailService
+	backend *simulated.Backend
+}
+
+// NewSimulatedLedger starts a simulated chain and deploys the compiled
+// AuditTrail contract from artifactPath, or the embedded one when empty. The submitter key is funded in the
+// genesis block; a throwaway key is generated when privateKeyHex is empty.
+func NewSimulatedLedger(artifactPath, privateKeyHex string) (*SimulatedLedger, error) {
+	var keys []*ecdsa.PrivateKey
+	if privateKeyHex != "" {
+		pk, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load private key: %w", err)
+		}
+		keys = append(keys, pk)
+	}
+	return newSimulatedLedger(artifactPath, keys)
+}
+
+// newSimulatedLedger funds every key, deploys the contract from the first
+// and authorizes the others as submitters, as an operator would on a real
+// network.
+func newSimulatedLedger(artifactPath string, keys []*ecdsa.PrivateKey) (*SimulatedLedger, error) {
+	bytecode, err := loadContractBytecode(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		pk, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load private key: %w", err)
+		}
+		keys = []*ecdsa.PrivateKey{pk}
+	}
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	alloc := make(types.GenesisAlloc, len(keys))
+	for _, pk := range keys {
+		alloc[crypto.PubkeyToAddress(pk.PublicKey)] = types.Account{Balance: balance}
+	}
+	backend := simulated.NewBackend(alloc)
+	client := &autoCommitClient{Client: backend.Client(), backend: backend}
+
+	ctx := context.Background()
+	contract, err := deployContract(ctx, client, keys[0], bytecode)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+
+	svc, err := newAuditTrailService(client, contract, keys[:1], simulatedNetwork)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	svc.closer = func() { backend.Close() }
+	for _, pk := range keys[1:] {
+		if _, err := svc.RotateSubmitter(ctx, pk); err != nil {
+			backend.Close()
+			return nil, err
+		}
+	}
+
+	return &SimulatedLedger{AuditTrailService: svc, backend: backend}, nil
+}
+
+// autoCommitClient mines a block after every transaction sent through it.
+type autoCommitClient struct {
+	simulated.Client
+	backend *simulated.Backend
+	mu      sync.Mutex
+}
+
+func (c *autoCommitClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	c.backend.Commit()
+	return nil
+}
+
+func deployContract(ctx context.Context, client *autoCommitClient, pk *ecdsa.PrivateKey, bytecode []byte) (common.Address, error) {
+	from := crypto.PubkeyToAddress(pk.PublicKey)
+
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: simulatedDeployGas, Data: bytecode})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), pk)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to sign deployment: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy AuditTrail: %w", err)
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, signed.Hash())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get deployment receipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return common.Address{}, fmt.Errorf("AuditTrail deployment reverted")
+	}
+	return receipt.ContractAddress, nil
+}
+
+// loadContractBytecode reads the creation bytecode from a Hardhat
+// ({"bytecode": "0x..."}) or Foundry ({"bytecode": {"object": "0x..."}})
+// artifact. An empty path loads the embedded AuditTrail artifact.
+func loadContractBytecode(path string) ([]byte, error) {
+	data := auditTrailArtifact
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read contract artifact: %w", err)
+		}
+	}
+
+	var artifact struct {
+		Bytecode json.RawMessage `json:"bytecode"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, fmt.Errorf("failed to parse contract artifact: %w", err)
+	}
+
+	var hexCode string
+	if err := json.Unmarshal(artifact.Bytecode, &hexCode); err != nil {
+		var foundry struct {
+			Object string `json:"object"`
+		}
+		if err := json.Unmarshal(artifact.Bytecode, &foundry); err != nil {
+			return nil, fmt.Errorf("contract artifact has no bytecode")
+		}
+		hexCode = foundry.Object
+	}
+	if !strings.HasPrefix(hexCode, "0x") {
+		hexCode = "0x" + hexCode
+	}
+
+	bytecode, err := hexutil.Decode(hexCode)
+	if err != nil || len(bytecode) == 0 {
+		return nil, fmt.Errorf("contract artifact has invalid bytecode")
+	}
+	return bytecode, nil
+}
+
+// WaitForConfirmation returns immediately: transactions are mined on send.
+func (l *SimulatedLedger) WaitForConfirmation(ctx context.Context, txHash string, timeout time.Duration) (*types.Receipt, error) {
+	return l.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+}
+
+// Fork makes block number the head of the simulated chain. Blocks mined
+// afterwards replace the ones above it, which simulates a reorg; the
+// replaced blocks' transactions are not re-sent.
+func (l *SimulatedLedger) Fork(ctx context.Context, number uint64) error {
+	header, err := l.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return fmt.Errorf("failed to get header %d: %w", number, err)
+	}
+	return l.backend.Fork(header.Hash())
+}
+
+// Commit mines a block.
+func (l *SimulatedLedger) Commit() {
+	l.backend.Commit()
+}
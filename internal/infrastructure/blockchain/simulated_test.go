@@ -0,0 +1,136 @@
+package blockchain_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSimulatedLedgerConcurrentSubmissions(t *testing.T) {
+	l, err := blockchain.NewSimulatedLedger("", "")
+	if err != nil {
+		t.Fatalf("NewSimulatedLedger: %v", err)
+	}
+	defer l.Close()
+
+	ctx := context.Background()
+	const submissions = 8
+	hashes := make([]string, submissions)
+	var wg sync.WaitGroup
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hash, err := l.InsertLog(ctx, fmt.Sprintf("task-%d", i), rationaleA, consensusA)
+			if err != nil {
+				t.Errorf("InsertLog %d: %v", i, err)
+			}
+			hashes[i] = hash
+		}(i)
+	}
+	wg.Wait()
+
+	for i, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		receipt, err := l.WaitForConfirmation(ctx, hash, 5*time.Second)
+		if err != nil {
+			t.Fatalf("WaitForConfirmation: %v", err)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful || receipt.Type != types.DynamicFeeTxType {
+			t.Errorf("receipt status %d type %d, want a successful dynamic-fee tx", receipt.Status, receipt.Type)
+		}
+		if ok, err := l.VerifyHashes(ctx, fmt.Sprintf("task-%d", i), rationaleA, consensusA); err != nil || !ok {
+			t.Errorf("task-%d does not verify: %v", i, err)
+		}
+	}
+}
+
+func TestSimulatedLedgerAuditTrail(t *testing.T) {
+	l, err := blockchain.NewSimulatedLedger("", "")
+	if err != nil {
+		t.Fatalf("NewSimulatedLedger: %v", err)
+	}
+	defer l.Close()
+	ctx := context.Background()
+
+	if ok, err := l.VerifyHashes(ctx, "task-1", rationaleA, consensusA); err != nil || ok {
+		t.Fatalf("unknown task verified: %v, %v", ok, err)
+	}
+	if _, err := l.GetActiveLog(ctx, "task-1"); err == nil {
+		t.Fatal("GetActiveLog of an unknown task should revert")
+	}
+	if _, err := l.CorrectLog(ctx, "task-1", rationaleB, consensusB); err == nil {
+		t.Fatal("correcting an unknown task should revert")
+	}
+
+	// A task ID longer than one word exercises the string padding.
+	other := "task-with-an-identifier-longer-than-thirty-two-bytes"
+	if _, err := l.InsertLog(ctx, other, rationaleB, consensusB); err != nil {
+		t.Fatalf("InsertLog %s: %v", other, err)
+	}
+	tx, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA)
+	if err != nil {
+		t.Fatalf("InsertLog: %v", err)
+	}
+	receipt, err := l.WaitForConfirmation(ctx, tx, time.Second)
+	if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("WaitForConfirmation: %v, %+v", err, receipt)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err == nil {
+		t.Error("duplicate insert should revert")
+	}
+	if ok, err := l.VerifyHashes(ctx, "task-1", rationaleA, consensusA); err != nil || !ok {
+		t.Fatalf("inserted hashes do not verify: %v", err)
+	}
+	if ok, _ := l.VerifyHashes(ctx, "task-1", rationaleA, consensusB); ok {
+		t.Error("a different consensus hash verified")
+	}
+
+	active, err := l.GetActiveLog(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("GetActiveLog: %v", err)
+	}
+	if active["rationale_hash"] != rationaleA || active["consensus_hash"] != consensusA ||
+		active["submitter"] != l.Submitters()[0] || active["block_number"] != receipt.BlockNumber.Int64() ||
+		active["status"] != blockchain.LogStatusActive {
+		t.Errorf("active log = %+v", active)
+	}
+
+	if _, err := l.CorrectLog(ctx, "task-1", rationaleB, consensusB); err != nil {
+		t.Fatalf("CorrectLog: %v", err)
+	}
+	if ok, _ := l.VerifyHashes(ctx, "task-1", rationaleA, consensusA); ok {
+		t.Error("superseded hashes still verify")
+	}
+	if ok, err := l.VerifyHashes(ctx, "task-1", rationaleB, consensusB); err != nil || !ok {
+		t.Errorf("corrected hashes do not verify: %v", err)
+	}
+
+	history, err := l.GetTaskHistory(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("GetTaskHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history has %d entries, want 2: %+v", len(history), history)
+	}
+	first, second := history[0], history[1]
+	if first.Index != 1 || first.RationaleHash != rationaleA || first.Status != blockchain.LogStatusSuperseded ||
+		first.SupersededBy == nil || *first.SupersededBy != 2 || first.TxHash != tx {
+		t.Errorf("inserted entry = %+v", first)
+	}
+	if second.Index != 2 || second.RationaleHash != rationaleB || second.ConsensusHash != consensusB ||
+		second.Status != blockchain.LogStatusActive || second.Supersedes == nil || *second.Supersedes != 1 {
+		t.Errorf("correction entry = %+v", second)
+	}
+
+	if history, err := l.GetTaskHistory(ctx, other); err != nil || len(history) != 1 || history[0].ConsensusHash != consensusB {
+		t.Errorf("history of %s = %+v, %v", other, history, err)
+	}
+}
@@ -0,0 +1,389 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/digitorus/pkcs7"
+	"github.com/digitorus/timestamp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	tsaNetwork        = "rfc3161"
+	defaultTSATimeout = 30 * time.Second
+	// maxTSAResponse bounds the body read from the TSA.
+	maxTSAResponse = 1 << 20
+)
+
+// TSALedger anchors hashes with RFC 3161 timestamp tokens from a
+// time-stamping authority instead of a blockchain, for tenants that may not
+// use public chains. It keeps the contract's semantics: one active token
+// per log ID, CorrectLog supersedes it, and GetActiveLog returns the
+// contract's keys. The token's SHA-256 stands in for the transaction hash
+// and the token's row ID for the block number. The store allows one active
+// token per log ID, so concurrent inserts and corrections of a log cannot
+// both succeed, across replicas too.
+type TSALedger struct {
+	url     string
+	network string
+	client  *http.Client
+	roots   *x509.CertPool
+	tokens  domain.TimestampTokenRepository
+}
+
+// NewTSALedger builds a ledger on the TSA at cfg.URL. Tokens are stored
+// through tokens.
+func NewTSALedger(cfg config.TSAConfig, network string, tokens domain.TimestampTokenRepository) (*TSALedger, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("blockchain.tsa.url is required for the tsa backend")
+	}
+	if cfg.CACertPath == "" {
+		return nil, fmt.Errorf("blockchain.tsa.ca_cert_path is required for the tsa backend")
+	}
+	if tokens == nil {
+		return nil, fmt.Errorf("the tsa backend needs a timestamp token store")
+	}
+	if network == "" {
+		network = tsaNetwork
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTSATimeout
+	}
+
+	pem, err := os.ReadFile(cfg.CACertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TSA CA certificates: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.CACertPath)
+	}
+
+	return &TSALedger{
+		url:     cfg.URL,
+		network: network,
+		client:  &http.Client{Timeout: timeout},
+		roots:   roots,
+		tokens:  tokens,
+	}, nil
+}
+
+// TimestampMessage is the SHA-256 a token is issued over: the canonical JSON
+// of the log ID and both hashes, so the token commits to all three.
+func TimestampMessage(logID, rationaleHash, consensusHash string) ([]byte, error) {
+	body, err := CanonicalJSON(map[string]interface{}{
+		"log_id":         logID,
+		"rationale_hash": "0x" + NormalizeHash(rationaleHash),
+		"consensus_hash": "0x" + NormalizeHash(consensusHash),
+	})
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(body)
+	return digest[:], nil
+}
+
+// InsertLog timestamps the first log of a task.
+func (l *TSALedger) InsertLog(ctx context.Context, taskID, rationaleHash, consensusHash string) (string, error) {
+	active, err := l.tokens.GetActive(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	if active != nil {
+		return "", fmt.Errorf("log already exists for task %s", taskID)
+	}
+
+	token, err := l.issue(ctx, taskID, rationaleHash, consensusHash)
+	if err != nil {
+		return "", err
+	}
+	if err := l.tokens.Create(ctx, token); errors.Is(err, domain.ErrTimestampLogActive) {
+		return "", fmt.Errorf("log already exists for task %s", taskID)
+	} else if err != nil {
+		return "", err
+	}
+	return token.TokenHash, nil
+}
+
+// CorrectLog timestamps new hashes superseding the task's active token.
+func (l *TSALedger) CorrectLog(ctx context.Context, oldTaskID, rationaleHash, consensusHash string) (string, error) {
+	active, err := l.tokens.GetActive(ctx, oldTaskID)
+	if err != nil {
+		return "", err
+	}
+	if active == nil {
+		return "", fmt.Errorf("no active log for task %s", oldTaskID)
+	}
+
+	token, err := l.issue(ctx, oldTaskID, rationaleHash, consensusHash)
+	if err != nil {
+		return "", err
+	}
+	if err := l.tokens.Supersede(ctx, active.ID, token); err != nil {
+		return "", err
+	}
+	return token.TokenHash, nil
+}
+
+// VerifyHashes checks the hashes against the task's active token and
+// re-verifies the token itself: its signature, its certificate chain to
+// the configured CA certificates, and that it covers these hashes.
+func (l *TSALedger) VerifyHashes(ctx context.Context, taskID, rationaleHash, consensusHash string) (bool, error) {
+	active, err := l.tokens.GetActive(ctx, taskID)
+	if err != nil {
+		return false, err
+	}
+	if active == nil {
+		return false, nil
+	}
+	if !HashesEqual(active.RationaleHash, rationaleHash) || !HashesEqual(active.ConsensusHash, consensusHash) {
+		return false, nil
+	}
+
+	digest, err := TimestampMessage(taskID, rationaleHash, consensusHash)
+	if err != nil {
+		return false, err
+	}
+	if _, err := VerifyTimestampToken(active.Token, digest, l.roots); err != nil {
+		return false, fmt.Errorf("stored timestamp token failed verification: %w", err)
+	}
+	return true, nil
+}
+
+// AttachTimestamp adds the active token of the bundle's anchor to the
+// bundle, so the anchor can be checked offline with the TSA's certificate.
+func (l *TSALedger) AttachTimestamp(ctx context.Context, b *ProofBundle) error {
+	active, err := l.tokens.GetActive(ctx, b.Anchor.LogID)
+	if err != nil {
+		return err
+	}
+	if active == nil || !HashesEqual(active.RationaleHash, b.Anchor.RationaleHash) || !HashesEqual(active.ConsensusHash, b.Anchor.ConsensusHash) {
+		return fmt.Errorf("no active timestamp token over the anchor of %s", b.Anchor.LogID)
+	}
+	b.Timestamp = &BundleTimestamp{TSA: active.TSAURL, Token: active.Token}
+	return nil
+}
+
+// GetActiveLog returns the task's active token with the contract's keys.
+// The submitter is the TSA certificate's subject.
+func (l *TSALedger) GetActiveLog(ctx context.Context, taskID string) (map[string]interface{}, error) {
+	active, err := l.tokens.GetActive(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, fmt.Errorf("no active log for task %s", taskID)
+	}
+	return map[string]interface{}{
+		"rationale_hash": "0x" + NormalizeHash(active.RationaleHash),
+		"consensus_hash": "0x" + NormalizeHash(active.ConsensusHash),
+		"submitter":      tokenSigner(active.Token),
+		"timestamp":      active.GenTime.Unix(),
+		"block_number":   active.ID,
+		"status":         LogStatusActive,
+		"superseded_by":  int64(0),
+	}, nil
+}
+
+// GetTaskHistory lists every token of a task, including superseded ones.
+func (l *TSALedger) GetTaskHistory(ctx context.Context, taskID string) ([]LogEntry, error) {
+	tokens, err := l.tokens.ListByLogID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LogEntry, 0, len(tokens))
+	for _, t := range tokens {
+		entry := LogEntry{
+			Index:         uint64(t.ID),
+			RationaleHash: "0x" + NormalizeHash(t.RationaleHash),
+			ConsensusHash: "0x" + NormalizeHash(t.ConsensusHash),
+			Submitter:     tokenSigner(t.Token),
+			Timestamp:     t.GenTime.Unix(),
+			BlockNumber:   uint64(t.ID),
+			TxHash:        t.TokenHash,
+			Status:        LogStatusActive,
+		}
+		if t.Supersedes != nil {
+			old := uint64(*t.Supersedes)
+			entry.Supersedes = &old
+		}
+		if t.SupersededBy != nil {
+			next := uint64(*t.SupersededBy)
+			entry.SupersededBy = &next
+			entry.Status = LogStatusSuperseded
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// WaitForConfirmation returns a receipt for a stored token. A token is final
+// once the TSA has issued it, so there is nothing to wait for.
+func (l *TSALedger) WaitForConfirmation(ctx context.Context, txHash string, timeout time.Duration) (*types.Receipt, error) {
+	token, err := l.tokens.GetByTokenHash(ctx, strings.ToLower(txHash))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, fmt.Errorf("timestamp token %s not found", txHash)
+	}
+	return &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      common.HexToHash(txHash),
+		BlockNumber: big.NewInt(token.ID),
+	}, nil
+}
+
+func (l *TSALedger) Network() string {
+	return l.network
+}
+
+// ContractAddress returns the TSA URL, the equivalent of the contract.
+func (l *TSALedger) ContractAddress() string {
+	return l.url
+}
+
+func (l *TSALedger) Close() {}
+
+// issue requests a token over the log's hashes and verifies it before it is
+// stored.
+func (l *TSALedger) issue(ctx context.Context, logID, rationaleHash, consensusHash string) (*domain.TimestampToken, error) {
+	digest, err := TimestampMessage(logID, rationaleHash, consensusHash)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	req := timestamp.Request{
+		HashAlgorithm: crypto.SHA256,
+		HashedMessage: digest,
+		Certificates:  true,
+		Nonce:         nonce,
+	}
+	body, err := req.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode timestamp request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+	resp, err := l.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach TSA: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TSA returned HTTP %d", resp.StatusCode)
+	}
+	reply, err := io.ReadAll(io.LimitReader(resp.Body, maxTSAResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TSA response: %w", err)
+	}
+
+	ts, err := timestamp.ParseResponse(reply)
+	if err != nil {
+		return nil, fmt.Errorf("TSA rejected the request: %w", err)
+	}
+	if ts.Nonce == nil || ts.Nonce.Cmp(nonce) != 0 {
+		return nil, fmt.Errorf("TSA response nonce does not match the request")
+	}
+	if _, err := VerifyTimestampToken(ts.RawToken, digest, l.roots); err != nil {
+		return nil, err
+	}
+
+	tokenHash := sha256.Sum256(ts.RawToken)
+	return &domain.TimestampToken{
+		LogID:          logID,
+		RationaleHash:  "0x" + NormalizeHash(rationaleHash),
+		ConsensusHash:  "0x" + NormalizeHash(consensusHash),
+		MessageImprint: "0x" + hex.EncodeToString(digest),
+		Token:          ts.RawToken,
+		TokenHash:      "0x" + hex.EncodeToString(tokenHash[:]),
+		SerialNumber:   ts.SerialNumber.String(),
+		GenTime:        ts.Time,
+		TSAURL:         l.url,
+	}, nil
+}
+
+// VerifyTimestampToken parses a token, which checks its signature against
+// the TSA certificate named by its SignerInfo, and checks that it covers
+// digest and that the signing certificate chains to roots.
+func VerifyTimestampToken(raw, digest []byte, roots *x509.CertPool) (*timestamp.Timestamp, error) {
+	ts, err := timestamp.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp token: %w", err)
+	}
+	if ts.HashAlgorithm != crypto.SHA256 || !bytes.Equal(ts.HashedMessage, digest) {
+		return nil, fmt.Errorf("timestamp token does not cover these hashes")
+	}
+
+	signer, err := tokenSignerCert(raw)
+	if err != nil {
+		return nil, err
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range ts.Certificates {
+		if c != signer {
+			intermediates.AddCert(c)
+		}
+	}
+	_, err = signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   ts.Time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TSA certificate is not trusted: %w", err)
+	}
+	return ts, nil
+}
+
+// tokenSignerCert returns the certificate matching the issuer and serial
+// number of the token's only SignerInfo. The certificates of a token are
+// unordered, so the first one is not necessarily the signer's.
+func tokenSignerCert(raw []byte) (*x509.Certificate, error) {
+	p7, err := pkcs7.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp token: %w", err)
+	}
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return nil, fmt.Errorf("timestamp token does not carry the certificate of its single signer")
+	}
+	return signer, nil
+}
+
+// tokenSigner names the TSA that issued a token, for the submitter field.
+func tokenSigner(raw []byte) string {
+	signer, err := tokenSignerCert(raw)
+	if err != nil {
+		return ""
+	}
+	return signer.Subject.String()
+}
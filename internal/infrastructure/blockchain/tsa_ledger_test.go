@@ -0,0 +1,322 @@
+package blockchain_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+)
+
+// memTimestampStore is an in-memory domain.TimestampTokenRepository.
+type memTimestampStore struct {
+	mu     sync.Mutex
+	tokens []*domain.TimestampToken
+}
+
+func (m *memTimestampStore) Create(ctx context.Context, token *domain.TimestampToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.LogID == token.LogID && t.SupersededBy == nil && token.Supersedes == nil {
+			return domain.ErrTimestampLogActive
+		}
+	}
+	m.add(token)
+	return nil
+}
+
+func (m *memTimestampStore) add(token *domain.TimestampToken) {
+	token.ID = int64(len(m.tokens) + 1)
+	m.tokens = append(m.tokens, token)
+}
+
+func (m *memTimestampStore) Supersede(ctx context.Context, oldID int64, token *domain.TimestampToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tokens[oldID-1].SupersededBy != nil {
+		return fmt.Errorf("timestamp token %d is no longer active", oldID)
+	}
+	token.Supersedes = &oldID
+	m.add(token)
+	m.tokens[oldID-1].SupersededBy = &token.ID
+	return nil
+}
+
+func (m *memTimestampStore) GetActive(ctx context.Context, logID string) (*domain.TimestampToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.LogID == logID && t.SupersededBy == nil {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memTimestampStore) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.TimestampToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memTimestampStore) ListByLogID(ctx context.Context, logID string) ([]*domain.TimestampToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*domain.TimestampToken
+	for _, t := range m.tokens {
+		if t.LogID == logID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// startLocalTSA serves a LocalTSA and returns a tsa config trusting it.
+func startLocalTSA(t *testing.T) config.TSAConfig {
+	t.Helper()
+	cfg, _ := startLocalTSAWithCert(t)
+	return cfg
+}
+
+// startLocalTSAWithCert is startLocalTSA that also returns the TSA's
+// certificate.
+func startLocalTSAWithCert(t *testing.T) (config.TSAConfig, *x509.Certificate) {
+	t.Helper()
+	tsa, err := blockchain.NewLocalTSA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(tsa)
+	t.Cleanup(srv.Close)
+
+	caPath := filepath.Join(t.TempDir(), "tsa.pem")
+	if err := os.WriteFile(caPath, tsa.CertificatePEM(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(tsa.CertificatePEM())
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config.TSAConfig{URL: srv.URL, CACertPath: caPath, Timeout: 5 * time.Second}, cert
+}
+
+func TestTSALedgerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := &memTimestampStore{}
+
+	l, err := blockchain.NewLedger(config.BlockchainConfig{
+		Backend: config.LedgerBackendTSA,
+		TSA:     startLocalTSA(t),
+	}, blockchain.LedgerDeps{Timestamps: store})
+	if err != nil {
+		t.Fatalf("NewLedger: %v", err)
+	}
+	defer l.Close()
+
+	tx, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA)
+	if err != nil {
+		t.Fatalf("InsertLog: %v", err)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err == nil {
+		t.Errorf("duplicate insert should fail")
+	}
+	receipt, err := l.WaitForConfirmation(ctx, tx, time.Second)
+	if err != nil || receipt.BlockNumber.Int64() != 1 {
+		t.Fatalf("WaitForConfirmation: %v, %+v", err, receipt)
+	}
+
+	if ok, err := l.VerifyHashes(ctx, "task-1", rationaleA, consensusA); err != nil || !ok {
+		t.Errorf("inserted hashes should verify: %v", err)
+	}
+	if ok, _ := l.VerifyHashes(ctx, "task-1", rationaleB, consensusA); ok {
+		t.Errorf("different hashes should not verify")
+	}
+
+	if _, err := l.CorrectLog(ctx, "task-1", rationaleB, consensusB); err != nil {
+		t.Fatalf("CorrectLog: %v", err)
+	}
+	if ok, _ := l.VerifyHashes(ctx, "task-1", rationaleA, consensusA); ok {
+		t.Errorf("superseded hashes should no longer verify")
+	}
+
+	entry, err := l.GetActiveLog(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("GetActiveLog: %v", err)
+	}
+	if entry["rationale_hash"] != rationaleB || entry["block_number"] != int64(2) || entry["submitter"] == "" {
+		t.Errorf("active log = %+v", entry)
+	}
+
+	history, err := l.GetTaskHistory(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("GetTaskHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history has %d entries, want 2", len(history))
+	}
+	if history[0].Status != blockchain.LogStatusSuperseded || history[0].SupersededBy == nil || *history[0].SupersededBy != 2 {
+		t.Errorf("first entry should be superseded by the correction: %+v", history[0])
+	}
+	if history[1].Status != blockchain.LogStatusActive || history[1].Supersedes == nil || history[1].RationaleHash != rationaleB {
+		t.Errorf("correction entry = %+v", history[1])
+	}
+}
+
+func TestTSALedgerRejectsTamperedToken(t *testing.T) {
+	ctx := context.Background()
+	store := &memTimestampStore{}
+
+	l, err := blockchain.NewTSALedger(startLocalTSA(t), "", store)
+	if err != nil {
+		t.Fatalf("NewTSALedger: %v", err)
+	}
+	if _, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA); err != nil {
+		t.Fatalf("InsertLog: %v", err)
+	}
+
+	// Rewriting the stored hashes does not move the token's imprint.
+	store.tokens[0].RationaleHash = rationaleB
+	if ok, err := l.VerifyHashes(ctx, "task-1", rationaleB, consensusA); ok || err == nil {
+		t.Errorf("token over other hashes should fail verification: ok=%v err=%v", ok, err)
+	}
+
+	// A token from another TSA is not trusted.
+	other, err := blockchain.NewTSALedger(startLocalTSA(t), "", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.tokens[0].RationaleHash = rationaleA
+	if ok, err := other.VerifyHashes(ctx, "task-1", rationaleA, consensusA); ok || err == nil {
+		t.Errorf("token from an untrusted TSA should fail verification: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestTSALedgerRequiresCACertificates(t *testing.T) {
+	cfg := startLocalTSA(t)
+	cfg.CACertPath = ""
+	if _, err := blockchain.NewTSALedger(cfg, "", &memTimestampStore{}); err == nil {
+		t.Errorf("a tsa ledger without CA certificates should be rejected")
+	}
+}
+
+// Concurrent inserts of a log rely on the store's one-active-token rule.
+func TestTSALedgerConcurrentInsert(t *testing.T) {
+	ctx := context.Background()
+	l, err := blockchain.NewTSALedger(startLocalTSA(t), "", &memTimestampStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := l.InsertLog(ctx, "task-1", rationaleA, consensusA)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	inserted := 0
+	for err := range errs {
+		if err == nil {
+			inserted++
+		}
+	}
+	if inserted != 1 {
+		t.Errorf("%d concurrent inserts succeeded, want 1", inserted)
+	}
+}
+
+// A token signed by an untrusted key does not verify because a trusted
+// certificate is listed first: the signer is found by its SignerInfo.
+func TestTSALedgerChecksTheSigningCertificate(t *testing.T) {
+	ctx := context.Background()
+	trustedCfg, trustedCert := startLocalTSAWithCert(t)
+	store := &memTimestampStore{}
+	trusted, err := blockchain.NewTSALedger(trustedCfg, "", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trusted.InsertLog(ctx, "task-1", rationaleA, consensusA); err != nil {
+		t.Fatal(err)
+	}
+
+	forgedStore := &memTimestampStore{}
+	forger, err := blockchain.NewTSALedger(startLocalTSA(t), "", forgedStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := forger.InsertLog(ctx, "task-1", rationaleA, consensusA); err != nil {
+		t.Fatal(err)
+	}
+	store.tokens[0].Token = prependCertificate(t, forgedStore.tokens[0].Token, trustedCert)
+
+	if ok, err := trusted.VerifyHashes(ctx, "task-1", rationaleA, consensusA); ok || err == nil {
+		t.Errorf("token signed by an untrusted key should fail verification: ok=%v err=%v", ok, err)
+	}
+}
+
+// prependCertificate returns token with cert added in front of the
+// certificates of its SignedData, leaving the signature untouched.
+func prependCertificate(t *testing.T, token []byte, cert *x509.Certificate) []byte {
+	t.Helper()
+	elements := func(b []byte) []asn1.RawValue {
+		var out []asn1.RawValue
+		for len(b) > 0 {
+			var v asn1.RawValue
+			rest, err := asn1.Unmarshal(b, &v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, v)
+			b = rest
+		}
+		return out
+	}
+	wrap := func(v asn1.RawValue, children []asn1.RawValue) asn1.RawValue {
+		var body []byte
+		for _, c := range children {
+			body = append(body, c.FullBytes...)
+		}
+		out, err := asn1.Marshal(asn1.RawValue{Class: v.Class, Tag: v.Tag, IsCompound: true, Bytes: body})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v.FullBytes, v.Bytes = out, body
+		return v
+	}
+
+	contentInfo := elements(token)[0]
+	fields := elements(contentInfo.Bytes)      // contentType, [0] content
+	signedData := elements(fields[1].Bytes)[0] // SignedData
+	sdFields := elements(signedData.Bytes)     // version, digestAlgorithms, encapContentInfo, [0] certificates, ..., signerInfos
+	for i, f := range sdFields {
+		if f.Class == asn1.ClassContextSpecific && f.Tag == 0 {
+			certs := append([]asn1.RawValue{{FullBytes: cert.Raw}}, elements(f.Bytes)...)
+			sdFields[i] = wrap(f, certs)
+		}
+	}
+	signedData = wrap(signedData, sdFields)
+	fields[1] = wrap(fields[1], []asn1.RawValue{signedData})
+	return wrap(contentInfo, fields).FullBytes
+}
@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 )
 
 type StagingStatus string
@@ -29,6 +30,10 @@ type StagingDocument struct {
 	ApprovedBy string        `bson:"approved_by,omitempty" json:"approved_by,omitempty"`
 	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
 	UpdatedAt  time.Time     `bson:"updated_at" json:"updated_at"`
+
+	// Layout maps RawText as parsed back to the pages of the source file.
+	// It is kept when reviewers edit RawText; the embed step realigns it.
+	Layout []domain.LayoutElement `bson:"layout,omitempty" json:"-"`
 }
 
 type MongoClient struct {
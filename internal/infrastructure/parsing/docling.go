@@ -0,0 +1,148 @@
+package parsing
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// doclingDocument is the part of Docling's JSON export (DoclingDocument) the
+// parser reads. Items are referenced by JSON pointers like "#/texts/3";
+// body lists the top-level items in reading order.
+type doclingDocument struct {
+	Body   doclingNode   `json:"body"`
+	Groups []doclingNode `json:"groups"`
+	Texts  []struct {
+		Label string        `json:"label"`
+		Text  string        `json:"text"`
+		Level int           `json:"level"`
+		Prov  []doclingProv `json:"prov"`
+	} `json:"texts"`
+	Tables []struct {
+		Prov []doclingProv `json:"prov"`
+		Data struct {
+			Grid [][]struct {
+				Text string `json:"text"`
+			} `json:"grid"`
+		} `json:"data"`
+	} `json:"tables"`
+	Pages map[string]struct {
+		Size struct {
+			Height float64 `json:"height"`
+		} `json:"size"`
+	} `json:"pages"`
+}
+
+type doclingNode struct {
+	Children []struct {
+		Ref string `json:"$ref"`
+	} `json:"children"`
+}
+
+type doclingProv struct {
+	PageNo int `json:"page_no"`
+	BBox   struct {
+		L           float64 `json:"l"`
+		T           float64 `json:"t"`
+		R           float64 `json:"r"`
+		B           float64 `json:"b"`
+		CoordOrigin string  `json:"coord_origin"`
+	} `json:"bbox"`
+}
+
+// parseDoclingJSON renders a DoclingDocument to Markdown the way Docling's
+// own export does (title as "#", section headers one level below), keeping
+// the page and bounding box of every element. Page headers, footers and
+// pictures are left out.
+func parseDoclingJSON(raw json.RawMessage) (*ParsedDocument, error) {
+	var doc doclingDocument
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	var b layoutBuilder
+	seen := make(map[string]bool)
+	var walk func(node doclingNode)
+	walk = func(node doclingNode) {
+		for _, child := range node.Children {
+			if seen[child.Ref] {
+				continue
+			}
+			seen[child.Ref] = true
+			kind, index, ok := doclingRef(child.Ref)
+			if !ok {
+				continue
+			}
+			switch kind {
+			case "groups":
+				if index < len(doc.Groups) {
+					walk(doc.Groups[index])
+				}
+			case "texts":
+				if index >= len(doc.Texts) {
+					continue
+				}
+				t := doc.Texts[index]
+				page, region := doc.location(t.Prov)
+				switch t.Label {
+				case "title":
+					b.heading(1, t.Text, page, region)
+				case "section_header":
+					b.heading(t.Level+1, t.Text, page, region)
+				case "page_header", "page_footer":
+				case "list_item":
+					b.block(KindList, "- "+t.Text, page, region)
+				default:
+					b.block(KindText, t.Text, page, region)
+				}
+			case "tables":
+				if index >= len(doc.Tables) {
+					continue
+				}
+				t := doc.Tables[index]
+				rows := make([][]string, len(t.Data.Grid))
+				for i, row := range t.Data.Grid {
+					for _, cell := range row {
+						rows[i] = append(rows[i], cell.Text)
+					}
+				}
+				page, region := doc.location(t.Prov)
+				b.block(KindTable, markdownTable(rows), page, region)
+			}
+		}
+	}
+	walk(doc.Body)
+	return b.document(ParserDocling), nil
+}
+
+// doclingRef splits a reference like "#/texts/3" into "texts" and 3.
+func doclingRef(ref string) (string, int, bool) {
+	parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	if len(parts) != 2 {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return parts[0], index, true
+}
+
+// location returns the page and bounding box of an item's first
+// provenance, converting bottom-left origin boxes to top-left.
+func (doc *doclingDocument) location(prov []doclingProv) (int, *domain.PageRegion) {
+	if len(prov) == 0 {
+		return 0, nil
+	}
+	p := prov[0]
+	region := &domain.PageRegion{Page: p.PageNo, Left: p.BBox.L, Top: p.BBox.T, Right: p.BBox.R, Bottom: p.BBox.B}
+	if strings.EqualFold(p.BBox.CoordOrigin, "BOTTOMLEFT") {
+		if height := doc.Pages[strconv.Itoa(p.PageNo)].Size.Height; height > 0 {
+			region.Top = height - p.BBox.T
+			region.Bottom = height - p.BBox.B
+		}
+	}
+	return p.PageNo, region
+}
@@ -34,6 +34,16 @@ func NewDocumentParser(doclingURL, unstructuredURL string) *DocumentParser {
 
 // ExtractText dispatches to the correct parser based on file extension.
 func (p *DocumentParser) ExtractText(ctx context.Context, filePath string) (string, error) {
+	doc, err := p.Parse(ctx, filePath)
+	if err != nil {
+		return "", err
+	}
+	return doc.Text, nil
+}
+
+// Parse is ExtractText with the layout: the page, bounding box and heading
+// path of every element of the Markdown text.
+func (p *DocumentParser) Parse(ctx context.Context, filePath string) (*ParsedDocument, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	switch ext {
@@ -41,27 +51,29 @@ func (p *DocumentParser) ExtractText(ctx context.Context, filePath string) (stri
 		return extractPlainText(filePath)
 	case ".pdf", ".docx", ".pptx", ".xlsx":
 		// Route to Docling for layout-aware extraction
-		text, err := p.parseWithDocling(ctx, filePath)
+		doc, err := p.parseWithDocling(ctx, filePath)
 		if err != nil {
 			// Fallback to Unstructured.io if Docling unavailable
 			return p.parseWithUnstructured(ctx, filePath)
 		}
-		return text, nil
+		return doc, nil
 	default:
-		return "", fmt.Errorf("unsupported file type: %s", ext)
+		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
 }
 
 // parseWithDocling sends the file to the Docling HTTP API and returns extracted Markdown text.
 // Docling preserves table structure, multi-column layout, and uses OCR for scanned pages.
-func (p *DocumentParser) parseWithDocling(ctx context.Context, filePath string) (string, error) {
+// The JSON export carries the page and bounding box of each element; the
+// Markdown export is used as is when it is missing.
+func (p *DocumentParser) parseWithDocling(ctx context.Context, filePath string) (*ParsedDocument, error) {
 	if p.doclingURL == "" {
-		return "", fmt.Errorf("Docling URL not configured")
+		return nil, fmt.Errorf("Docling URL not configured")
 	}
 
 	f, err := os.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file for Docling: %w", err)
+		return nil, fmt.Errorf("failed to open file for Docling: %w", err)
 	}
 	defer f.Close()
 
@@ -69,54 +81,66 @@ func (p *DocumentParser) parseWithDocling(ctx context.Context, filePath string)
 	writer := multipart.NewWriter(&body)
 	part, err := writer.CreateFormFile("files", filepath.Base(filePath))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if _, err := io.Copy(part, f); err != nil {
-		return "", err
+		return nil, err
+	}
+	for _, format := range []string{"md", "json"} {
+		if err := writer.WriteField("to_formats", format); err != nil {
+			return nil, err
+		}
 	}
 	writer.Close()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.doclingURL+"/v1alpha/convert/file", &body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("Docling request failed: %w", err)
+		return nil, fmt.Errorf("Docling request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Docling returned HTTP %d", resp.StatusCode)
+		return nil, fmt.Errorf("Docling returned HTTP %d", resp.StatusCode)
 	}
 
 	// Docling returns a JSON structure with markdown text per document
 	var result struct {
 		Document struct {
-			MdContent string `json:"md_content"`
+			MdContent   string          `json:"md_content"`
+			JSONContent json.RawMessage `json:"json_content"`
 		} `json:"document"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode Docling response: %w", err)
+		return nil, fmt.Errorf("failed to decode Docling response: %w", err)
 	}
 
+	if len(result.Document.JSONContent) > 0 && string(result.Document.JSONContent) != "null" {
+		doc, err := parseDoclingJSON(result.Document.JSONContent)
+		if err == nil && doc.Text != "" {
+			return doc, nil
+		}
+	}
 	if result.Document.MdContent == "" {
-		return "", fmt.Errorf("Docling returned empty content")
+		return nil, fmt.Errorf("Docling returned empty content")
 	}
-	return result.Document.MdContent, nil
+	return &ParsedDocument{Text: result.Document.MdContent, Parser: ParserDocling}, nil
 }
 
 // parseWithUnstructured sends the file to Unstructured.io API (fallback).
-func (p *DocumentParser) parseWithUnstructured(ctx context.Context, filePath string) (string, error) {
+func (p *DocumentParser) parseWithUnstructured(ctx context.Context, filePath string) (*ParsedDocument, error) {
 	if p.unstructuredURL == "" {
-		return "", fmt.Errorf("Unstructured URL not configured")
+		return nil, fmt.Errorf("Unstructured URL not configured")
 	}
 
 	f, err := os.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file for Unstructured: %w", err)
+		return nil, fmt.Errorf("failed to open file for Unstructured: %w", err)
 	}
 	defer f.Close()
 
@@ -124,45 +148,41 @@ func (p *DocumentParser) parseWithUnstructured(ctx context.Context, filePath str
 	writer := multipart.NewWriter(&body)
 	part, err := writer.CreateFormFile("files", filepath.Base(filePath))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if _, err := io.Copy(part, f); err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := writer.WriteField("coordinates", "true"); err != nil {
+		return nil, err
 	}
 	writer.Close()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.unstructuredURL+"/general/v0/general", &body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("Unstructured request failed: %w", err)
+		return nil, fmt.Errorf("Unstructured request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	var elements []struct {
-		Text string `json:"text"`
-	}
+	var elements []unstructuredElement
 	if err := json.NewDecoder(resp.Body).Decode(&elements); err != nil {
-		return "", fmt.Errorf("failed to decode Unstructured response: %w", err)
-	}
-
-	var sb strings.Builder
-	for _, el := range elements {
-		sb.WriteString(el.Text)
-		sb.WriteString("\n")
+		return nil, fmt.Errorf("failed to decode Unstructured response: %w", err)
 	}
-	return strings.TrimSpace(sb.String()), nil
+	return parseUnstructuredElements(elements), nil
 }
 
-// extractPlainText reads plain text / markdown files directly.
-func extractPlainText(filePath string) (string, error) {
+// extractPlainText reads plain text / markdown files directly. They have no
+// pages, so no layout is returned.
+func extractPlainText(filePath string) (*ParsedDocument, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
-	return strings.TrimSpace(string(data)), nil
+	return &ParsedDocument{Text: strings.TrimSpace(string(data)), Parser: ParserPlain}, nil
 }
@@ -0,0 +1,111 @@
+package parsing
+
+import (
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// Parser names reported in ParsedDocument.Parser.
+const (
+	ParserDocling      = "docling"
+	ParserUnstructured = "unstructured"
+	ParserPlain        = "plain"
+)
+
+// ParsedDocument is the structured output of a parser: the document as
+// Markdown plus the layout elements it was rendered from. Elements are in
+// reading order and their offsets point into Text; they are empty for
+// plain-text files.
+type ParsedDocument struct {
+	Text     string
+	Elements []domain.LayoutElement
+	Parser   string
+}
+
+// Layout element kinds.
+const (
+	KindHeading = "heading"
+	KindText    = "text"
+	KindList    = "list_item"
+	KindTable   = "table"
+)
+
+// layoutBuilder renders layout elements to Markdown, recording where each
+// element's text lands and the heading path it sits under.
+type layoutBuilder struct {
+	sb       strings.Builder
+	headings []string
+	elements []domain.LayoutElement
+}
+
+// heading adds a Markdown header of the given level (clamped to 1..6).
+func (b *layoutBuilder) heading(level int, text string, page int, region *domain.PageRegion) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return
+	}
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	if level <= len(b.headings) {
+		b.headings = b.headings[:level-1]
+	}
+	b.headings = append(b.headings, text)
+	b.sb.WriteString(strings.Repeat("#", level))
+	b.sb.WriteByte(' ')
+	b.add(KindHeading, text, page, region)
+}
+
+// block adds a paragraph, list item or table.
+func (b *layoutBuilder) block(kind, text string, page int, region *domain.PageRegion) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	b.add(kind, text, page, region)
+}
+
+func (b *layoutBuilder) add(kind, text string, page int, region *domain.PageRegion) {
+	start := b.sb.Len()
+	b.sb.WriteString(text)
+	b.elements = append(b.elements, domain.LayoutElement{
+		Kind:        kind,
+		Text:        text,
+		Page:        page,
+		Region:      region,
+		HeadingPath: append([]string(nil), b.headings...),
+		Start:       start,
+		End:         b.sb.Len(),
+	})
+	b.sb.WriteString("\n\n")
+}
+
+func (b *layoutBuilder) document(parser string) *ParsedDocument {
+	return &ParsedDocument{
+		Text:     strings.TrimRight(b.sb.String(), "\n"),
+		Elements: b.elements,
+		Parser:   parser,
+	}
+}
+
+// markdownTable renders table rows as a Markdown table, the first row being
+// the header.
+func markdownTable(rows [][]string) string {
+	var sb strings.Builder
+	for i, row := range rows {
+		sb.WriteByte('|')
+		for _, cell := range row {
+			cell = strings.Join(strings.Fields(cell), " ")
+			sb.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+		}
+		sb.WriteByte('\n')
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,83 @@
+package parsing
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+const doclingJSON = `{
+	"body": {"children": [{"$ref": "#/texts/0"}, {"$ref": "#/texts/1"}, {"$ref": "#/texts/2"}, {"$ref": "#/groups/0"}, {"$ref": "#/texts/5"}, {"$ref": "#/tables/0"}]},
+	"groups": [{"children": [{"$ref": "#/texts/3"}, {"$ref": "#/texts/4"}]}],
+	"texts": [
+		{"label": "page_header", "text": "POJK 11/2024", "prov": [{"page_no": 1, "bbox": {"l": 10, "t": 830, "r": 100, "b": 820, "coord_origin": "BOTTOMLEFT"}}]},
+		{"label": "title", "text": "Kebijakan Modal", "prov": [{"page_no": 1, "bbox": {"l": 50, "t": 800, "r": 300, "b": 780, "coord_origin": "BOTTOMLEFT"}}]},
+		{"label": "section_header", "level": 1, "text": "BAB I", "prov": [{"page_no": 1, "bbox": {"l": 50, "t": 760, "r": 120, "b": 745, "coord_origin": "BOTTOMLEFT"}}]},
+		{"label": "list_item", "text": "modal inti", "prov": [{"page_no": 1}]},
+		{"label": "list_item", "text": "modal pelengkap", "prov": [{"page_no": 2}]},
+		{"label": "text", "text": "Bank wajib  menyediakan modal.", "prov": [{"page_no": 2, "bbox": {"l": 50, "t": 100, "r": 500, "b": 140, "coord_origin": "TOPLEFT"}}]}
+	],
+	"tables": [{"prov": [{"page_no": 3}], "data": {"grid": [[{"text": "Jenis"}, {"text": "Rasio"}], [{"text": "CET1"}, {"text": "4.5%"}]]}}],
+	"pages": {"1": {"size": {"height": 842}}, "2": {"size": {"height": 842}}}
+}`
+
+func TestParseDoclingJSON(t *testing.T) {
+	doc, err := parseDoclingJSON(json.RawMessage(doclingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Kebijakan Modal\n\n## BAB I\n\n- modal inti\n\n- modal pelengkap\n\nBank wajib  menyediakan modal.\n\n" +
+		"| Jenis | Rasio |\n| --- | --- |\n| CET1 | 4.5% |"
+	if doc.Text != want {
+		t.Fatalf("text = %q, want %q", doc.Text, want)
+	}
+	if len(doc.Elements) != 6 {
+		t.Fatalf("got %d elements, want 6", len(doc.Elements))
+	}
+	for _, el := range doc.Elements {
+		if doc.Text[el.Start:el.End] != el.Text {
+			t.Errorf("element %q: offsets point to %q", el.Text, doc.Text[el.Start:el.End])
+		}
+	}
+
+	title := doc.Elements[0]
+	if title.Kind != KindHeading || title.Page != 1 || title.Region == nil || title.Region.Top != 42 || title.Region.Bottom != 62 {
+		t.Errorf("title = %+v (region %+v)", title, title.Region)
+	}
+	body := doc.Elements[4]
+	if body.Page != 2 || body.Region.Top != 100 || !reflect.DeepEqual(body.HeadingPath, []string{"Kebijakan Modal", "BAB I"}) {
+		t.Errorf("paragraph = %+v (region %+v)", body, body.Region)
+	}
+	if table := doc.Elements[5]; table.Kind != KindTable || table.Page != 3 || table.Region == nil {
+		t.Errorf("table = %+v", table)
+	}
+}
+
+func TestParseUnstructuredElements(t *testing.T) {
+	var elements []unstructuredElement
+	err := json.Unmarshal([]byte(`[
+		{"type": "Header", "text": "Rahasia", "metadata": {"page_number": 1}},
+		{"type": "Title", "text": "Pasal 5", "metadata": {"page_number": 1, "category_depth": 0}},
+		{"type": "NarrativeText", "text": "Cuti tahunan 12 hari.", "metadata": {"page_number": 2,
+			"coordinates": {"points": [[72, 100], [72, 130], [400, 130], [400, 100]]}}},
+		{"type": "Title", "text": "Ayat 1", "metadata": {"page_number": 2, "category_depth": 1}}
+	]`), &elements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := parseUnstructuredElements(elements)
+	if want := "# Pasal 5\n\nCuti tahunan 12 hari.\n\n## Ayat 1"; doc.Text != want {
+		t.Fatalf("text = %q, want %q", doc.Text, want)
+	}
+	para := doc.Elements[1]
+	if para.Page != 2 || para.Region == nil || *para.Region != (domain.PageRegion{Page: 2, Left: 72, Top: 100, Right: 400, Bottom: 130}) {
+		t.Errorf("paragraph = %+v (region %+v)", para, para.Region)
+	}
+	if got := doc.Elements[2].HeadingPath; !reflect.DeepEqual(got, []string{"Pasal 5", "Ayat 1"}) {
+		t.Errorf("heading path = %v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package parsing
+
+import (
+	"math"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// unstructuredElement is one element of the Unstructured.io partition
+// response. Coordinates are only returned when the request asks for them.
+type unstructuredElement struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Metadata struct {
+		PageNumber    int `json:"page_number"`
+		CategoryDepth int `json:"category_depth"`
+		Coordinates   *struct {
+			Points [][]float64 `json:"points"`
+		} `json:"coordinates"`
+	} `json:"metadata"`
+}
+
+// parseUnstructuredElements renders Unstructured.io elements to Markdown:
+// titles become headers (nested by category_depth), list items bullets.
+// Page headers, footers and page numbers are left out.
+func parseUnstructuredElements(elements []unstructuredElement) *ParsedDocument {
+	var b layoutBuilder
+	for _, el := range elements {
+		page := el.Metadata.PageNumber
+		region := unstructuredRegion(el)
+		switch el.Type {
+		case "Title":
+			b.heading(el.Metadata.CategoryDepth+1, el.Text, page, region)
+		case "Header", "Footer", "PageNumber", "PageBreak":
+		case "ListItem":
+			b.block(KindList, "- "+el.Text, page, region)
+		case "Table":
+			b.block(KindTable, el.Text, page, region)
+		default:
+			b.block(KindText, el.Text, page, region)
+		}
+	}
+	return b.document(ParserUnstructured)
+}
+
+// unstructuredRegion returns the box enclosing an element's polygon, whose
+// origin is the top-left corner of the page.
+func unstructuredRegion(el unstructuredElement) *domain.PageRegion {
+	if el.Metadata.Coordinates == nil || len(el.Metadata.Coordinates.Points) == 0 {
+		return nil
+	}
+	region := &domain.PageRegion{
+		Page: el.Metadata.PageNumber,
+		Left: math.Inf(1), Top: math.Inf(1),
+		Right: math.Inf(-1), Bottom: math.Inf(-1),
+	}
+	for _, pt := range el.Metadata.Coordinates.Points {
+		if len(pt) < 2 {
+			return nil
+		}
+		region.Left = math.Min(region.Left, pt[0])
+		region.Right = math.Max(region.Right, pt[0])
+		region.Top = math.Min(region.Top, pt[1])
+		region.Bottom = math.Max(region.Bottom, pt[1])
+	}
+	return region
+}
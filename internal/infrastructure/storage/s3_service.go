@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/config"
@@ -59,6 +60,24 @@ func (s *S3Service) DownloadToTemp(ctx context.Context, objectKey string) (strin
 	return tempPath, nil
 }
 
+// ReadObject reads an S3 object into memory, failing for objects larger than
+// maxSize bytes.
+func (s *S3Service) ReadObject(ctx context.Context, objectKey string, maxSize int64) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get S3 object: %w", err)
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(io.LimitReader(obj, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read S3 object: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("S3 object '%s' exceeds %d bytes", objectKey, maxSize)
+	}
+	return data, nil
+}
+
 // EnsureBucket creates the bucket if it does not exist.
 func (s *S3Service) EnsureBucket(ctx context.Context) error {
 	exists, err := s.client.BucketExists(ctx, s.bucket)
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits each client IP to perMinute requests per minute and burst
+// requests per second, counted in Redis so the limit holds across replicas.
+// While the counter store is unavailable each replica counts in process, so
+// the limit still holds per replica. The client IP only honours forwarding
+// headers from the router's trusted proxies (gin's SetTrustedProxies).
+func RateLimit(store cache.Cache, keys *cache.CacheKeyBuilder, scope string, perMinute, burst int) gin.HandlerFunc {
+	fallback := newLocalCounters()
+	return func(c *gin.Context) {
+		now := time.Now()
+		client := c.ClientIP()
+
+		count := func(key string, window time.Duration) int64 {
+			n, err := hit(c, store, key, window)
+			if err != nil {
+				log.Printf("[RateLimit] Counter unavailable, counting in process: %v", err)
+				return fallback.hit(key, window, now)
+			}
+			return n
+		}
+		perMinuteCount := count(keys.RateLimit(scope+":"+client+":m:"+strconv.FormatInt(now.Unix()/60, 10)), time.Minute)
+		perSecondCount := count(keys.RateLimit(scope+":"+client+":s:"+strconv.FormatInt(now.Unix(), 10)), time.Second)
+
+		remaining := perMinute - int(perMinuteCount)
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(perMinute))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if perMinuteCount > int64(perMinute) || perSecondCount > int64(burst) {
+			retryAfter := 1
+			if perMinuteCount > int64(perMinute) {
+				retryAfter = int(60 - now.Unix()%60)
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many requests",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// hit increments a window counter, setting its expiry on the first hit.
+func hit(c *gin.Context, store cache.Cache, key string, window time.Duration) (int64, error) {
+	ctx := c.Request.Context()
+	n, err := store.Increment(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		if err := store.Expire(ctx, key, window); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// localCounters are in-process window counters, the fallback while Redis is
+// unavailable.
+type localCounters struct {
+	mu     sync.Mutex
+	counts map[string]localCount
+	swept  time.Time
+}
+
+type localCount struct {
+	n       int64
+	expires time.Time
+}
+
+func newLocalCounters() *localCounters {
+	return &localCounters{counts: make(map[string]localCount)}
+}
+
+func (l *localCounters) hit(key string, window time.Duration, now time.Time) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Window keys are never reused, so drop expired ones now and then.
+	if now.Sub(l.swept) > time.Minute {
+		for k, c := range l.counts {
+			if now.After(c.expires) {
+				delete(l.counts, k)
+			}
+		}
+		l.swept = now
+	}
+
+	c := l.counts[key]
+	if now.After(c.expires) {
+		c = localCount{expires: now.Add(window)}
+	}
+	c.n++
+	l.counts[key] = c
+	return c.n
+}
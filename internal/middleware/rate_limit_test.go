@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func newRateLimitedRouter(t *testing.T, mr *miniredis.Miniredis, perMinute, burst int) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store, err := cache.NewRedisCache(&config.Config{Redis: config.RedisConfig{Host: mr.Host(), Port: mr.Port(), PoolSize: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(middleware.RateLimit(store, cache.NewCacheKeyBuilder("test"), "public", perMinute, burst))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// get sends a request from remoteAddr, optionally claiming another client
+// IP through X-Forwarded-For.
+func get(router *gin.Engine, remoteAddr, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimit(t *testing.T) {
+	mr := miniredis.RunT(t)
+	router := newRateLimitedRouter(t, mr, 3, 10)
+
+	for i := 0; i < 3; i++ {
+		if code := get(router, "10.0.0.1:1234", ""); code != http.StatusOK {
+			t.Fatalf("request %d: status %d", i+1, code)
+		}
+	}
+	if code := get(router, "10.0.0.1:1234", ""); code != http.StatusTooManyRequests {
+		t.Errorf("request over the limit: status %d, want 429", code)
+	}
+	// A forged X-Forwarded-For is ignored from an untrusted peer.
+	if code := get(router, "10.0.0.1:1234", "203.0.113.7"); code != http.StatusTooManyRequests {
+		t.Errorf("spoofed client IP: status %d, want 429", code)
+	}
+	if code := get(router, "10.0.0.2:1234", ""); code != http.StatusOK {
+		t.Errorf("other client: status %d", code)
+	}
+}
+
+func TestRateLimitWithoutRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	router := newRateLimitedRouter(t, mr, 3, 10)
+	mr.Close()
+
+	for i := 0; i < 3; i++ {
+		if code := get(router, "10.0.0.1:1234", ""); code != http.StatusOK {
+			t.Fatalf("request %d: status %d", i+1, code)
+		}
+	}
+	if code := get(router, "10.0.0.1:1234", ""); code != http.StatusTooManyRequests {
+		t.Errorf("request over the limit without Redis: status %d, want 429", code)
+	}
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AnchorBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewAnchorBatchRepository(db *gorm.DB) *AnchorBatchRepository {
+	return &AnchorBatchRepository{db: db}
+}
+
+// CountPending returns how many tasks are waiting for a batch.
+func (r *AnchorBatchRepository) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.SwarmTask{}).
+		Where("blockchain_stat = ?", domain.BlockchainStatusPendingBatch).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending batch tasks: %w", err)
+	}
+	return count, nil
+}
+
+// PendingProfiles returns the ledger profiles that have tasks waiting for a
+// batch.
+func (r *AnchorBatchRepository) PendingProfiles(ctx context.Context) ([]string, error) {
+	var profiles []string
+	err := r.db.WithContext(ctx).
+		Model(&domain.SwarmTask{}).
+		Where("blockchain_stat = ?", domain.BlockchainStatusPendingBatch).
+		Distinct().
+		Order("ledger_profile").
+		Pluck("ledger_profile", &profiles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending batch profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// SealPending claims up to maxSize pending tasks of a ledger profile, oldest
+// first, and lets seal build the batch from them. The batch and the tasks (as mutated by seal) are
+// saved in the same transaction. Rows are locked with SKIP LOCKED so several
+// workers can seal concurrently without claiming the same task twice.
+// It returns nil when nothing is pending.
+func (r *AnchorBatchRepository) SealPending(ctx context.Context, profile string, maxSize int, seal func(batch *domain.AnchorBatch, tasks []*domain.SwarmTask) error) (*domain.AnchorBatch, error) {
+	var batch *domain.AnchorBatch
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tasks []*domain.SwarmTask
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("blockchain_stat = ? AND ledger_profile = ?", domain.BlockchainStatusPendingBatch, profile).
+			Order("updated_at ASC, id ASC").
+			Limit(maxSize).
+			Find(&tasks).Error
+		if err != nil {
+			return fmt.Errorf("failed to claim pending batch tasks: %w", err)
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+
+		// The ID is part of the anchored commitment, so it is assigned before seal runs.
+		b := &domain.AnchorBatch{ID: uuid.NewString(), LedgerProfile: profile}
+		if err := seal(b, tasks); err != nil {
+			return err
+		}
+		if err := tx.Create(b).Error; err != nil {
+			return fmt.Errorf("failed to create anchor batch: %w", err)
+		}
+		for _, task := range tasks {
+			if err := tx.Save(task).Error; err != nil {
+				return fmt.Errorf("failed to assign task %s to batch: %w", task.ID, err)
+			}
+		}
+		batch = b
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (r *AnchorBatchRepository) GetByID(ctx context.Context, id string) (*domain.AnchorBatch, error) {
+	var batch domain.AnchorBatch
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&batch).Error; err != nil {
+		return nil, fmt.Errorf("failed to get anchor batch: %w", err)
+	}
+	return &batch, nil
+}
+
+func (r *AnchorBatchRepository) Update(ctx context.Context, batch *domain.AnchorBatch) error {
+	if err := r.db.WithContext(ctx).Save(batch).Error; err != nil {
+		return fmt.Errorf("failed to update anchor batch: %w", err)
+	}
+	return nil
+}
+
+// UpdateTaskStatus propagates the batch's anchoring state to its tasks.
+func (r *AnchorBatchRepository) UpdateTaskStatus(ctx context.Context, batchID, txHash, network, status string) error {
+	updates := map[string]interface{}{"blockchain_stat": status}
+	if txHash != "" {
+		updates["blockchain_tx"] = txHash
+	}
+	if network != "" {
+		updates["blockchain_net"] = network
+	}
+	err := r.db.WithContext(ctx).
+		Model(&domain.SwarmTask{}).
+		Where("batch_id = ?", batchID).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("failed to update batch tasks: %w", err)
+	}
+	return nil
+}
+
+// ListStaleCommits returns sealed batches last updated before the given
+// time that still have no transaction, i.e. whose commit never ran or kept
+// failing.
+func (r *AnchorBatchRepository) ListStaleCommits(ctx context.Context, before time.Time, limit int) ([]*domain.AnchorBatch, error) {
+	var batches []*domain.AnchorBatch
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND COALESCE(tx_hash, '') = '' AND updated_at < ?", domain.BlockchainStatusPendingCommit, before).
+		Order("updated_at").
+		Limit(limit).
+		Find(&batches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale batches: %w", err)
+	}
+	return batches, nil
+}
+
+// TouchCommit bumps a PENDING_COMMIT batch's updated_at when its commit is
+// enqueued again, so the next sweep leaves it alone for a while.
+func (r *AnchorBatchRepository) TouchCommit(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).
+		Model(&domain.AnchorBatch{}).
+		Where("id = ? AND status = ?", id, domain.BlockchainStatusPendingCommit).
+		Update("updated_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to touch anchor batch: %w", err)
+	}
+	return nil
+}
+
+// ListPendingConfirmation returns the batches of a ledger profile submitted
+// before the given time whose transaction has not been confirmed yet.
+func (r *AnchorBatchRepository) ListPendingConfirmation(ctx context.Context, profile string, before time.Time, limit int) ([]*domain.AnchorBatch, error) {
+	var batches []*domain.AnchorBatch
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND tx_hash <> '' AND updated_at < ? AND ledger_profile = ?", domain.BlockchainStatusPendingConfirmation, before, profile).
+		Order("updated_at").
+		Limit(limit).
+		Find(&batches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending batches: %w", err)
+	}
+	return batches, nil
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type auditAnchorRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditAnchorRepository(db *gorm.DB) domain.AuditAnchorRepository {
+	return &auditAnchorRepository{db: db}
+}
+
+func (r *auditAnchorRepository) Create(ctx context.Context, anchor *domain.AuditAnchor) error {
+	if err := r.db.WithContext(ctx).Create(anchor).Error; err != nil {
+		return fmt.Errorf("failed to create audit anchor: %w", err)
+	}
+	return nil
+}
+
+// Seal holds the tenant's audit lock exclusively while the anchor is built
+// and stored. Inserting an audit entry takes the same lock shared (see the
+// reject_anchored_audit_log trigger), so entries in flight are committed
+// before build lists them, and later ones see the anchor.
+func (r *auditAnchorRepository) Seal(ctx context.Context, tenantID uuid.UUID, build func() (*domain.AuditAnchor, error)) (*domain.AuditAnchor, error) {
+	var anchor *domain.AuditAnchor
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", auditLockKey(tenantID)).Error; err != nil {
+			return fmt.Errorf("failed to lock audit log: %w", err)
+		}
+		var err error
+		if anchor, err = build(); err != nil {
+			return err
+		}
+		if err := tx.Create(anchor).Error; err != nil {
+			return fmt.Errorf("failed to create audit anchor: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return anchor, nil
+}
+
+// auditLockKey names the advisory lock of a tenant's audit log; the
+// reject_anchored_audit_log trigger builds the same key.
+func auditLockKey(tenantID uuid.UUID) string {
+	return "audit_anchor:" + tenantID.String()
+}
+
+func (r *auditAnchorRepository) Update(ctx context.Context, anchor *domain.AuditAnchor) error {
+	if err := r.db.WithContext(ctx).Save(anchor).Error; err != nil {
+		return fmt.Errorf("failed to update audit anchor: %w", err)
+	}
+	return nil
+}
+
+func (r *auditAnchorRepository) Latest(ctx context.Context, tenantID uuid.UUID) (*domain.AuditAnchor, error) {
+	return r.first(ctx, r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("period_start DESC"))
+}
+
+func (r *auditAnchorRepository) GetCovering(ctx context.Context, tenantID uuid.UUID, at time.Time) (*domain.AuditAnchor, error) {
+	return r.first(ctx, r.db.WithContext(ctx).
+		Where("tenant_id = ? AND period_start <= ? AND period_end > ?", tenantID, at, at))
+}
+
+func (r *auditAnchorRepository) ListUnconfirmed(ctx context.Context, tenantID uuid.UUID) ([]*domain.AuditAnchor, error) {
+	var anchors []*domain.AuditAnchor
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND status IN ?", tenantID, []string{domain.AuditAnchorPendingCommit, domain.AuditAnchorPendingConfirmation, domain.AuditAnchorFailed}).
+		Order("period_start ASC").
+		Find(&anchors).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unconfirmed audit anchors: %w", err)
+	}
+	return anchors, nil
+}
+
+func (r *auditAnchorRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID, limit int) ([]*domain.AuditAnchor, error) {
+	var anchors []*domain.AuditAnchor
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("period_start DESC").
+		Limit(limit).
+		Find(&anchors).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit anchors: %w", err)
+	}
+	return anchors, nil
+}
+
+// ListTenants returns all tenants: every tenant's audit log is anchored.
+func (r *auditAnchorRepository) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	var tenants []*domain.Tenant
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+func (r *auditAnchorRepository) first(ctx context.Context, q *gorm.DB) (*domain.AuditAnchor, error) {
+	var anchor domain.AuditAnchor
+	err := q.First(&anchor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit anchor: %w", err)
+	}
+	return &anchor, nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// The anchor is built and stored while the tenant's audit lock is held.
+func TestAuditAnchorSealHoldsTheAuditLock(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewAuditAnchorRepository(gormDB)
+	tenant := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtextextended\(\$1, 0\)\)`).
+		WithArgs("audit_anchor:" + tenant.String()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO "audit_anchors"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+	mock.ExpectCommit()
+
+	built := false
+	anchor, err := repo.Seal(context.Background(), tenant, func() (*domain.AuditAnchor, error) {
+		built = true
+		return &domain.AuditAnchor{TenantID: tenant}, nil
+	})
+	if err != nil || !built || anchor == nil {
+		t.Fatalf("Seal: anchor %+v, built %v, err %v", anchor, built, err)
+	}
+
+	// A failing build stores nothing.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if _, err := repo.Seal(context.Background(), tenant, func() (*domain.AuditAnchor, error) {
+		return nil, errors.New("list failed")
+	}); err == nil {
+		t.Errorf("a failing build should fail the seal")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditCreateRejectsAnchoredPeriod(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewAuditRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "enterprise_audit_logs"`).
+		WillReturnError(&pgconn.PgError{Code: "23514", ConstraintName: "audit_period_anchored"})
+	mock.ExpectRollback()
+	err := repo.Create(context.Background(), &domain.AuditLog{TenantID: uuid.New(), CreatedAt: time.Now().Add(-48 * time.Hour)})
+	if !errors.Is(err, domain.ErrAuditPeriodAnchored) {
+		t.Errorf("err = %v, want ErrAuditPeriodAnchored", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,11 +2,20 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+// auditPeriodAnchored is the constraint name the reject_anchored_audit_log
+// trigger reports.
+const auditPeriodAnchored = "audit_period_anchored"
+
 type auditRepository struct {
 	db *gorm.DB
 }
@@ -17,6 +26,49 @@ func NewAuditRepository(db *gorm.DB) domain.AuditRepository {
 
 // Create stores a new audit log record. By using gorm on the partitioned table 'enterprise_audit_logs',
 // postgres handles the routing to the appropriate monthly partition table automatically.
+// The reject_anchored_audit_log trigger refuses entries created before the end
+// of the tenant's latest anchor, which is reported as ErrAuditPeriodAnchored.
 func (r *auditRepository) Create(ctx context.Context, audit *domain.AuditLog) error {
-	return r.db.WithContext(ctx).Table("enterprise_audit_logs").Create(audit).Error
+	err := r.db.WithContext(ctx).Table("enterprise_audit_logs").Create(audit).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == auditPeriodAnchored {
+		return fmt.Errorf("%w: entry created at %s", domain.ErrAuditPeriodAnchored, audit.CreatedAt.Format(time.RFC3339))
+	}
+	return err
+}
+
+func (r *auditRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.AuditLog, error) {
+	var audit domain.AuditLog
+	err := r.db.WithContext(ctx).Table("enterprise_audit_logs").Where("id = ?", id).First(&audit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return &audit, nil
+}
+
+func (r *auditRepository) ListForPeriod(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.AuditLog, error) {
+	var audits []*domain.AuditLog
+	err := r.db.WithContext(ctx).Table("enterprise_audit_logs").
+		Where("tenant_id = ? AND created_at >= ? AND created_at < ?", tenantID, from, to).
+		Order("created_at ASC, id ASC").
+		Find(&audits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return audits, nil
+}
+
+func (r *auditRepository) FirstCreatedAt(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (*time.Time, error) {
+	var first *time.Time
+	err := r.db.WithContext(ctx).Table("enterprise_audit_logs").
+		Where("tenant_id = ? AND created_at >= ? AND created_at < ?", tenantID, from, to).
+		Select("MIN(created_at)").
+		Scan(&first).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find first audit log: %w", err)
+	}
+	return first, nil
 }
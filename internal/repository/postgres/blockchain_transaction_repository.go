@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/gorm"
+)
+
+// maxReplacementChain bounds ReplacementChain against a cycle in bad data.
+const maxReplacementChain = 64
+
+type blockchainTransactionRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockchainTransactionRepository(db *gorm.DB) domain.BlockchainTransactionRepository {
+	return &blockchainTransactionRepository{db: db}
+}
+
+func (r *blockchainTransactionRepository) Create(ctx context.Context, tx *domain.BlockchainTransaction) error {
+	if err := r.db.WithContext(ctx).Create(tx).Error; err != nil {
+		return fmt.Errorf("failed to record blockchain transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *blockchainTransactionRepository) ListStuck(ctx context.Context, chainID int64, toAddress string, submittedBefore time.Time, limit int) ([]*domain.BlockchainTransaction, error) {
+	var txs []*domain.BlockchainTransaction
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND chain_id = ? AND to_address = ? AND submitted_at < ?", domain.TxStatusPending, chainID, toAddress, submittedBefore).
+		Order("nonce ASC, submitted_at ASC").
+		Limit(limit).
+		Find(&txs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stuck transactions: %w", err)
+	}
+	return txs, nil
+}
+
+func (r *blockchainTransactionRepository) Replace(ctx context.Context, oldHash string, replacement *domain.BlockchainTransaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(replacement).Error; err != nil {
+			return fmt.Errorf("failed to record replacement transaction: %w", err)
+		}
+		err := tx.Model(&domain.BlockchainTransaction{}).
+			Where("tx_hash = ?", oldHash).
+			Updates(map[string]interface{}{"status": domain.TxStatusReplaced, "replaced_by": replacement.TxHash}).Error
+		if err != nil {
+			return fmt.Errorf("failed to mark transaction replaced: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *blockchainTransactionRepository) ReplacementChain(ctx context.Context, hash string) ([]string, error) {
+	chain := []string{hash}
+	current := hash
+	for len(chain) < maxReplacementChain {
+		var next string
+		err := r.db.WithContext(ctx).
+			Model(&domain.BlockchainTransaction{}).
+			Select("replaced_by").
+			Where("tx_hash = ?", current).
+			Scan(&next).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to resolve replacement: %w", err)
+		}
+		if next == "" {
+			break
+		}
+		chain = append(chain, next)
+		current = next
+	}
+	return chain, nil
+}
+
+func (r *blockchainTransactionRepository) UpdateStatus(ctx context.Context, hash, status string, blockNumber *int64) error {
+	updates := map[string]interface{}{"status": status}
+	if blockNumber != nil {
+		updates["block_number"] = *blockNumber
+	}
+	err := r.db.WithContext(ctx).
+		Model(&domain.BlockchainTransaction{}).
+		Where("tx_hash = ?", hash).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type chainEventRepository struct {
+	db *gorm.DB
+}
+
+func NewChainEventRepository(db *gorm.DB) domain.ChainEventRepository {
+	return &chainEventRepository{db: db}
+}
+
+// GetCursor returns nil when the contract has not been indexed yet.
+func (r *chainEventRepository) GetCursor(ctx context.Context, network, contract string) (*domain.ChainCursor, error) {
+	var cursor domain.ChainCursor
+	err := r.db.WithContext(ctx).Where("network = ? AND contract = ?", network, contract).First(&cursor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain cursor: %w", err)
+	}
+	return &cursor, nil
+}
+
+func (r *chainEventRepository) SaveCursor(ctx context.Context, cursor *domain.ChainCursor) error {
+	if err := r.db.WithContext(ctx).Save(cursor).Error; err != nil {
+		return fmt.Errorf("failed to save chain cursor: %w", err)
+	}
+	return nil
+}
+
+func (r *chainEventRepository) Upsert(ctx context.Context, events []*domain.ChainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "network"}, {Name: "contract"}, {Name: "block_hash"}, {Name: "log_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"removed", "confirmations", "final", "updated_at"}),
+	}).Create(events).Error
+	if err != nil {
+		return fmt.Errorf("failed to store chain events: %w", err)
+	}
+	return nil
+}
+
+func (r *chainEventRepository) ListUnfinal(ctx context.Context, network, contract string) ([]*domain.ChainEvent, error) {
+	var events []*domain.ChainEvent
+	err := r.db.WithContext(ctx).
+		Where("network = ? AND contract = ? AND removed = FALSE AND final = FALSE", network, contract).
+		Order("block_number ASC, log_index ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unfinal chain events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *chainEventRepository) Update(ctx context.Context, event *domain.ChainEvent) error {
+	if err := r.db.WithContext(ctx).Save(event).Error; err != nil {
+		return fmt.Errorf("failed to update chain event: %w", err)
+	}
+	return nil
+}
+
+func (r *chainEventRepository) RemoveFrom(ctx context.Context, network, contract string, block int64) ([]*domain.ChainEvent, error) {
+	var events []*domain.ChainEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("network = ? AND contract = ? AND removed = FALSE AND block_number >= ?", network, contract, block).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return tx.Model(&domain.ChainEvent{}).
+			Where("network = ? AND contract = ? AND removed = FALSE AND block_number >= ?", network, contract, block).
+			Updates(map[string]interface{}{
+				"removed":         true,
+				"final":           false,
+				"reconciliation":  nil,
+				"mismatch_reason": nil,
+				"reconciled_at":   nil,
+			}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove reorged chain events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *chainEventRepository) ListUnreconciled(ctx context.Context, network, contract string, limit int) ([]*domain.ChainEvent, error) {
+	var events []*domain.ChainEvent
+	err := r.db.WithContext(ctx).
+		Where("network = ? AND contract = ? AND removed = FALSE AND final = TRUE AND reconciled_at IS NULL", network, contract).
+		Order("block_number ASC, log_index ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unreconciled chain events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *chainEventRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.ChainEvent, error) {
+	var events []*domain.ChainEvent
+	err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("block_number ASC, log_index ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chain events: %w", err)
+	}
+	return events, nil
+}
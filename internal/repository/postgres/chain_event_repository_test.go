@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+func TestChainEventReorgResetsReconciliation(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewChainEventRepository(gormDB)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "chain_events" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "block_number"}).AddRow("e1", 12))
+	mock.ExpectExec(`UPDATE "chain_events" SET "final"=\$1,"mismatch_reason"=\$2,"reconciled_at"=\$3,"reconciliation"=\$4,"removed"=\$5`).
+		WithArgs(false, nil, nil, nil, true, sqlmock.AnyArg(), "net", "0xc", 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if _, err := repo.RemoveFrom(ctx, "net", "0xc", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// A log whose block returns is revived with its current finality.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "chain_events" .* ON CONFLICT \("network","contract","block_hash","log_index"\) DO UPDATE SET "removed"="excluded"."removed","confirmations"="excluded"."confirmations","final"="excluded"."final"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("e1"))
+	mock.ExpectCommit()
+	if err := repo.Upsert(ctx, []*domain.ChainEvent{{Network: "net", Contract: "0xc", BlockNumber: 12, BlockHash: "0xb", Final: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
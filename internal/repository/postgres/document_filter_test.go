@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestDocumentFilterConditions(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	const prefix = "AND EXISTS (SELECT 1 FROM documents d WHERE d.id = dc.document_id AND d.tenant_id = dc.tenant_id AND "
+	cases := []struct {
+		name   string
+		filter domain.SearchFilter
+		cond   string
+		args   []interface{}
+	}{
+		{
+			name:   "category",
+			filter: domain.SearchFilter{Category: "POJK"},
+			cond:   prefix + "d.category = $2 AND d.superseded_at IS NULL)",
+			args:   []interface{}{"POJK"},
+		},
+		{
+			name:   "document ids",
+			filter: domain.SearchFilter{DocumentIDs: ids},
+			cond:   prefix + "d.id IN ($2, $3))",
+			args:   []interface{}{ids[0].String(), ids[1].String()},
+		},
+		{
+			name:   "uploaded after",
+			filter: domain.SearchFilter{UploadedAfter: &after},
+			cond:   prefix + "d.created_at > $2 AND d.superseded_at IS NULL)",
+			args:   []interface{}{after},
+		},
+		{
+			name:   "upload date range",
+			filter: domain.SearchFilter{UploadedAfter: &after, UploadedBefore: &before},
+			cond:   prefix + "d.created_at > $2 AND d.created_at < $3 AND d.superseded_at IS NULL)",
+			args:   []interface{}{after, before},
+		},
+		{
+			name:   "statuses",
+			filter: domain.SearchFilter{Statuses: []string{"ready", "processing"}},
+			cond:   prefix + "d.status IN ($2, $3) AND d.superseded_at IS NULL)",
+			args:   []interface{}{"ready", "processing"},
+		},
+		{
+			name:   "metadata",
+			filter: domain.SearchFilter{Metadata: map[string]interface{}{"regulator": "OJK"}},
+			cond:   prefix + "d.superseded_at IS NULL AND d.metadata @> $2::jsonb)",
+			args:   []interface{}{`{"regulator":"OJK"}`},
+		},
+		{
+			name:   "combined",
+			filter: domain.SearchFilter{Category: "PBI", Statuses: []string{"ready"}, Metadata: map[string]interface{}{"tahun": 2024}},
+			cond:   prefix + "d.category = $2 AND d.status IN ($3) AND d.superseded_at IS NULL AND d.metadata @> $4::jsonb)",
+			args:   []interface{}{"PBI", "ready", `{"tahun":2024}`},
+		},
+	}
+	for _, c := range cases {
+		cond, args, err := documentFilter(c.filter, []interface{}{"query"})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if cond != c.cond {
+			t.Errorf("%s: condition\n%s\nwant\n%s", c.name, cond, c.cond)
+		}
+		if want := append([]interface{}{"query"}, c.args...); !reflect.DeepEqual(args, want) {
+			t.Errorf("%s: args %v, want %v", c.name, args, want)
+		}
+	}
+
+	if _, _, err := documentFilter(domain.SearchFilter{Metadata: map[string]interface{}{"x": make(chan int)}}, nil); err == nil {
+		t.Error("metadata that is not JSON was accepted")
+	}
+}
+
+// The filter restricts both the vector and the FTS candidates. Its bound
+// args are checked by TestDocumentFilterConditions.
+func TestHybridSearchFiltersBothRetrievals(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewDocumentRepository(gormDB)
+
+	mock.ExpectExec(`SET LOCAL hnsw.ef_search = 100`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`(?s)vector_search AS \(.*AND dc.embedding_model = \$4 AND EXISTS \(SELECT 1 FROM documents d WHERE [^)]*d.category = \$5[^)]*\).*` +
+		`FROM \(VALUES \('indonesian', \$1::text\), \('english', \$2::text\), \('simple', \$3::text\)\).*` +
+		`fts_search AS \(.*AND dc.content_tsv @@ q.query AND EXISTS \(SELECT 1 FROM documents d WHERE [^)]*d.category = \$5[^)]*\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"chunk_id"}))
+
+	_, err := repo.HybridSearch(context.Background(), domain.HybridSearchParams{
+		TenantID:       uuid.NewString(),
+		QueryText:      "modal inti",
+		QueryEmbedding: []float32{0.1, 0.2},
+		EmbeddingModel: "hash-8",
+		Filter:         domain.SearchFilter{Category: "POJK"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocumentFilterVersions(t *testing.T) {
+	recordID := uuid.New()
+	cases := []struct {
+		name       string
+		filter     domain.SearchFilter
+		latestOnly bool
+		args       int
+	}{
+		{"zero filter searches latest versions", domain.SearchFilter{}, true, 0},
+		{"category keeps latest only", domain.SearchFilter{Category: "APBD"}, true, 1},
+		{"historical version of a record", domain.SearchFilter{RecordID: &recordID, Version: 2}, false, 2},
+		{"pinned documents", domain.SearchFilter{DocumentIDs: []uuid.UUID{uuid.New()}}, false, 1},
+		{"all versions", domain.SearchFilter{AllVersions: true}, false, 0},
+	}
+	for _, c := range cases {
+		cond, args, err := documentFilter(c.filter, []interface{}{"query"})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := strings.Contains(cond, "superseded_at IS NULL"); got != c.latestOnly {
+			t.Errorf("%s: latest only = %v, want %v: %s", c.name, got, c.latestOnly, cond)
+		}
+		if len(args) != 1+c.args {
+			t.Errorf("%s: %d bound args, want %d", c.name, len(args)-1, c.args)
+		}
+	}
+}
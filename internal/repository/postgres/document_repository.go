@@ -9,9 +9,14 @@ import (
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// recordVersionIndex keeps the version numbers of a record unique.
+const recordVersionIndex = "idx_documents_record_version"
+
 type documentRepository struct {
 	db *gorm.DB
 }
@@ -20,14 +25,58 @@ func NewDocumentRepository(db *gorm.DB) domain.DocumentRepository {
 	return &documentRepository{db: db}
 }
 
+// Create inserts a document. A document without a record starts a new one,
+// as its version 1.
 func (r *documentRepository) Create(ctx context.Context, doc *domain.Document) error {
+	if doc.ID == uuid.Nil {
+		doc.ID = uuid.New()
+	}
+	if doc.RecordID == uuid.Nil {
+		doc.RecordID = doc.ID
+	}
 	if err := r.db.WithContext(ctx).Create(doc).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == recordVersionIndex {
+			return domain.ErrDocumentVersionExists
+		}
 		return fmt.Errorf("failed to create document: %w", err)
 	}
 	return nil
 }
 
 func (r *documentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string, metadata map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&domain.Document{}).
+		Where("id = ?", id).
+		Updates(statusUpdates(status, metadata)).Error
+}
+
+func (r *documentRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, metadata datatypes.JSON) error {
+	err := r.db.WithContext(ctx).Model(&domain.Document{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"metadata": metadata, "last_updated_at": gorm.Expr("NOW()")}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update document metadata: %w", err)
+	}
+	return nil
+}
+
+// StartProcessing claims the document for indexing in a single conditional
+// update, so concurrent edits and the worker cannot both pass a status check.
+func (r *documentRepository) StartProcessing(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&domain.Document{}).
+		Where("id = ? AND status <> ?", id, "processing").
+		Updates(statusUpdates("processing", nil))
+	if result.Error != nil {
+		return fmt.Errorf("failed to update status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDocumentProcessing
+	}
+	return nil
+}
+
+// statusUpdates returns the columns written by a status change.
+func statusUpdates(status string, metadata map[string]interface{}) map[string]interface{} {
 	updates := map[string]interface{}{
 		"status":          status,
 		"last_updated_at": gorm.Expr("NOW()"),
@@ -39,10 +88,7 @@ func (r *documentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, sta
 			updates["ai_analysis_json"] = string(metaBytes)
 		}
 	}
-
-	return r.db.WithContext(ctx).Model(&domain.Document{}).
-		Where("id = ?", id).
-		Updates(updates).Error
+	return updates
 }
 
 // StoreChunks atomically inserts all chunks within a single DB transaction.
@@ -56,6 +102,61 @@ func (r *documentRepository) StoreChunks(ctx context.Context, chunks []domain.Do
 	})
 }
 
+func (r *documentRepository) ListChunks(ctx context.Context, documentID uuid.UUID) ([]domain.DocumentChunk, error) {
+	var chunks []domain.DocumentChunk
+	err := r.db.WithContext(ctx).Omit("embedding").
+		Where("document_id = ?", documentID).
+		Order("chunk_index").
+		Find(&chunks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// SyncChunks deletes, repositions and inserts chunks and moves the document
+// to its new revision in one transaction, so searches never see a document
+// half re-indexed. Vectors of removed chunks in other embedding indexes go
+// with them (ON DELETE CASCADE).
+func (r *documentRepository) SyncChunks(ctx context.Context, sync domain.ChunkSync) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(sync.Removed) > 0 {
+			err := tx.Where("document_id = ? AND id IN ?", sync.DocumentID, sync.Removed).
+				Delete(&domain.DocumentChunk{}).Error
+			if err != nil {
+				return fmt.Errorf("failed to delete stale chunks: %w", err)
+			}
+		}
+		for _, c := range sync.Kept {
+			err := tx.Model(&domain.DocumentChunk{}).
+				Where("id = ? AND document_id = ?", c.ID, sync.DocumentID).
+				Updates(map[string]interface{}{
+					"chunk_index": c.ChunkIndex,
+					"page_number": c.PageNumber,
+					"page_end":    c.PageEnd,
+					"header_path": c.HeaderPath,
+					"char_start":  c.CharStart,
+					"char_end":    c.CharEnd,
+					"regions":     c.Regions,
+					"category":    c.Category,
+					"language":    c.Language,
+					"revision":    sync.Revision,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update chunk %s: %w", c.ID, err)
+			}
+		}
+		if len(sync.Added) > 0 {
+			if err := tx.Create(&sync.Added).Error; err != nil {
+				return fmt.Errorf("atomic chunk insert failed: %w", err)
+			}
+		}
+		return tx.Model(&domain.Document{}).
+			Where("id = ?", sync.DocumentID).
+			Update("revision", sync.Revision).Error
+	})
+}
+
 func (r *documentRepository) FindByTenant(ctx context.Context, tenantID string, limit, offset int) ([]*domain.Document, int64, error) {
 	var docs []*domain.Document
 	var total int64
@@ -88,7 +189,7 @@ func (r *documentRepository) FindByID(ctx context.Context, id string) (*domain.D
 //  1. Pre-filter by tenant_id (authorization hard gate — no cross-tenant data leakage possible)
 //  2. Tune HNSW ef_search for this session (accuracy vs speed trade-off)
 //  3. Dense retrieval via pgvector cosine similarity (semantic)
-//  4. Sparse retrieval via PostgreSQL tsvector GIN FTS (lexical / keyword)
+//  4. Sparse retrieval via PostgreSQL tsvector GIN FTS (lexical / keyword), per chunk language
 //  5. Fuse both rankings with Reciprocal Rank Fusion (RRF)
 func (r *documentRepository) HybridSearch(ctx context.Context, params domain.HybridSearchParams) ([]domain.HybridSearchResult, error) {
 	if params.TenantID == "" {
@@ -115,8 +216,33 @@ func (r *documentRepository) HybridSearch(ctx context.Context, params domain.Hyb
 		return nil, fmt.Errorf("failed to set ef_search: %w", err)
 	}
 
-	// Step 2: Format the embedding vector for pgvector
-	vecStr := pgvectorFormat(params.QueryEmbedding)
+	// Step 2: Format the embedding vector for pgvector. Chunks of every
+	// dimension share one column; the cast and the embedding_dim filter match
+	// the per-dimension partial HNSW index (see EnsureVectorIndex).
+	dim := len(params.QueryEmbedding)
+	distance := fmt.Sprintf("(dc.embedding::vector(%d)) <=> '%s'::vector(%d)", dim, pgvectorFormat(params.QueryEmbedding), dim)
+
+	// The query is parsed once per chunk language, each with its own stopwords.
+	var args []interface{}
+	ftsQueries := make([]string, len(domain.FTSLanguages))
+	for i, language := range domain.FTSLanguages {
+		args = append(args, pgFTSQuery(params.QueryText, language))
+		ftsQueries[i] = fmt.Sprintf("('%s', $%d::text)", language, len(args))
+	}
+
+	// Vectors of another embedding model live in a different space: never rank them.
+	modelFilter := ""
+	if params.EmbeddingModel != "" {
+		args = append(args, params.EmbeddingModel)
+		modelFilter = fmt.Sprintf("AND dc.embedding_model = $%d", len(args))
+	}
+
+	// Document filters go into both CTEs, so they restrict the candidates
+	// before ranking instead of thinning out the fused top K.
+	docFilter, args, err := documentFilter(params.Filter, args)
+	if err != nil {
+		return nil, fmt.Errorf("HybridSearch: %w", err)
+	}
 
 	// Step 3: RRF Hybrid Search query
 	// Both CTEs are pre-filtered by tenant_id BEFORE touching any index.
@@ -128,23 +254,28 @@ func (r *documentRepository) HybridSearch(ctx context.Context, params domain.Hyb
 				dc.id        AS chunk_id,
 				dc.document_id,
 				dc.content,
-				ROW_NUMBER() OVER (ORDER BY dc.embedding <=> '%s'::vector) AS rank
+				ROW_NUMBER() OVER (ORDER BY %s) AS rank
 			FROM document_chunks dc
-			WHERE dc.tenant_id = '%s'
-			ORDER BY dc.embedding <=> '%s'::vector
+			WHERE dc.tenant_id = '%s' AND dc.embedding_dim = %d %s %s
+			ORDER BY %s
 			LIMIT %d
 		),
+		fts_query AS (
+			-- Chunks are matched with the query parsed by their own language's configuration.
+			SELECT language, to_tsquery(language::regconfig, query_text) AS query
+			FROM (VALUES %s) AS q(language, query_text)
+		),
 		fts_search AS (
 			SELECT
 				dc.id        AS chunk_id,
 				dc.document_id,
 				dc.content,
-				ROW_NUMBER() OVER (ORDER BY ts_rank(dc.content_tsv, query) DESC) AS rank
-			FROM document_chunks dc,
-				to_tsquery('english', $1) AS query
+				ROW_NUMBER() OVER (ORDER BY ts_rank(dc.content_tsv, q.query) DESC) AS rank
+			FROM document_chunks dc
+			JOIN fts_query q ON q.language = dc.language
 			WHERE dc.tenant_id = '%s'
-			  AND dc.content_tsv @@ query
-			ORDER BY ts_rank(dc.content_tsv, query) DESC
+			  AND dc.content_tsv @@ q.query %s
+			ORDER BY ts_rank(dc.content_tsv, q.query) DESC
 			LIMIT %d
 		),
 		rrf AS (
@@ -166,14 +297,24 @@ func (r *documentRepository) HybridSearch(ctx context.Context, params domain.Hyb
 			r.vector_rank,
 			r.fts_rank,
 			r.rrf_score,
-			d.title AS document_title
+			d.title AS document_title,
+			c.page_number,
+			c.page_end,
+			COALESCE(c.header_path, ''),
+			c.char_start,
+			c.char_end,
+			c.regions,
+			c.revision,
+			d.version
 		FROM rrf r
 		JOIN documents d ON d.id = r.document_id
+		JOIN document_chunks c ON c.id = r.chunk_id
 		WHERE d.tenant_id = '%s'
 		ORDER BY r.rrf_score DESC
 		LIMIT %d`,
-		vecStr, params.TenantID, vecStr, params.TopK*3, // vector CTE fetches 3x for RRF fusion headroom
-		params.TenantID, params.TopK*3, // fts CTE
+		distance, params.TenantID, dim, modelFilter, docFilter, distance, params.TopK*3, // vector CTE fetches 3x for RRF fusion headroom
+		strings.Join(ftsQueries, ", "),            // fts query, one per language
+		params.TenantID, docFilter, params.TopK*3, // fts CTE
 		params.TopK*3, params.TopK*3, // RRF penalization constants
 		params.RRFConstant, params.TopK*3,
 		params.RRFConstant, params.TopK*3,
@@ -181,7 +322,7 @@ func (r *documentRepository) HybridSearch(ctx context.Context, params domain.Hyb
 	)
 
 	// Use safe parameterized query for the FTS part (tsvector)
-	rows, err := r.db.WithContext(ctx).Raw(searchSQL, pgFTSQuery(params.QueryText)).Rows()
+	rows, err := r.db.WithContext(ctx).Raw(searchSQL, args...).Rows()
 	if err != nil {
 		return nil, fmt.Errorf("hybrid search failed: %w", err)
 	}
@@ -190,6 +331,8 @@ func (r *documentRepository) HybridSearch(ctx context.Context, params domain.Hyb
 	var results []domain.HybridSearchResult
 	for rows.Next() {
 		var res domain.HybridSearchResult
+		var pageStart, pageEnd int
+		var regions []byte
 		if err := rows.Scan(
 			&res.ChunkID,
 			&res.DocumentID,
@@ -198,15 +341,59 @@ func (r *documentRepository) HybridSearch(ctx context.Context, params domain.Hyb
 			&res.FTSRank,
 			&res.RRFScore,
 			&res.DocumentTitle,
+			&pageStart,
+			&pageEnd,
+			&res.Citation.HeaderPath,
+			&res.Citation.CharStart,
+			&res.Citation.CharEnd,
+			&regions,
+			&res.Citation.Revision,
+			&res.Citation.Version,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan hybrid search result: %w", err)
 		}
+		res.Citation.DocumentID = res.DocumentID
+		res.Citation.DocumentTitle = res.DocumentTitle
+		res.Citation.ChunkID = res.ChunkID
+		// A page_end of 0 marks pages that were never located.
+		if pageEnd > 0 {
+			res.Citation.PageStart, res.Citation.PageEnd = pageStart, pageEnd
+			if len(regions) > 0 {
+				_ = json.Unmarshal(regions, &res.Citation.Regions)
+			}
+		}
 		results = append(results, res)
 	}
 
 	return results, nil
 }
 
+func (r *documentRepository) EmbeddingModels(ctx context.Context, tenantID string) ([]domain.EmbeddingModelUsage, error) {
+	var usage []domain.EmbeddingModelUsage
+	err := r.db.WithContext(ctx).Model(&domain.DocumentChunk{}).
+		Select("COALESCE(embedding_model, '') AS model, COALESCE(embedding_dim, 0) AS dimension, COUNT(*) AS chunks").
+		Where("tenant_id = ?", tenantID).
+		Group("embedding_model, embedding_dim").
+		Order("chunks DESC").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedding models: %w", err)
+	}
+	return usage, nil
+}
+
+func (r *documentRepository) TenantSettings(ctx context.Context, tenantID uuid.UUID) (domain.TenantSettings, error) {
+	var tenant domain.Tenant
+	err := r.db.WithContext(ctx).Select("settings").Where("id = ?", tenantID).First(&tenant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.TenantSettings{}, nil
+	}
+	if err != nil {
+		return domain.TenantSettings{}, fmt.Errorf("failed to find tenant: %w", err)
+	}
+	return tenant.ParsedSettings(), nil
+}
+
 // pgvectorFormat converts a []float32 embedding to the pgvector literal format.
 func pgvectorFormat(embedding []float32) string {
 	if len(embedding) == 0 {
@@ -224,33 +411,117 @@ func pgvectorFormat(embedding []float32) string {
 	return sb.String()
 }
 
-// pgFTSQuery converts a plain-text query to a PostgreSQL tsquery-compatible string.
-// Joins words with '&' for AND logic; removes special chars.
-func pgFTSQuery(text string) string {
-	words := strings.Fields(text)
-	for i, w := range words {
-		words[i] = strings.Map(func(r rune) rune {
-			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
-				return r
-			}
-			return -1
-		}, w)
-	}
-	// Filter empty
-	var valid []string
-	for _, w := range words {
-		if w != "" {
-			valid = append(valid, w)
-		}
-	}
-	if len(valid) == 0 {
-		return ""
+func (r *documentRepository) ListVersions(ctx context.Context, tenantID, recordID uuid.UUID) ([]*domain.Document, error) {
+	var docs []*domain.Document
+	err := r.db.WithContext(ctx).
+		Where("record_id = ? AND tenant_id = ?", recordID, tenantID).
+		Order("version").
+		Find(&docs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document versions: %w", err)
 	}
-	return strings.Join(valid, " & ")
+	return docs, nil
+}
+
+// MarkReady marks a document version ready and supersedes the older versions
+// of its record in one transaction, so searches never see both or neither.
+func (r *documentRepository) MarkReady(ctx context.Context, doc *domain.Document, metadata map[string]interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Document{}).
+			Where("id = ?", doc.ID).
+			Updates(statusUpdates("ready", metadata)).Error
+		if err != nil {
+			return fmt.Errorf("failed to mark document ready: %w", err)
+		}
+		err = tx.Model(&domain.Document{}).
+			Where("record_id = ? AND version < ? AND superseded_at IS NULL", doc.RecordID, doc.Version).
+			Update("superseded_at", gorm.Expr("NOW()")).Error
+		if err != nil {
+			return fmt.Errorf("failed to supersede document versions: %w", err)
+		}
+		return nil
+	})
 }
 
+// Delete removes a document version. When it was the record's searchable
+// version, the newest remaining ready version takes its place.
 func (r *documentRepository) Delete(ctx context.Context, tenantID, docID uuid.UUID) error {
-	return r.db.WithContext(ctx).
-		Where("id = ? AND tenant_id = ?", docID, tenantID).
-		Delete(&domain.Document{}).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var doc domain.Document
+		err := tx.Select("record_id").Where("id = ? AND tenant_id = ?", docID, tenantID).First(&doc).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find document: %w", err)
+		}
+		if err := tx.Where("id = ? AND tenant_id = ?", docID, tenantID).Delete(&domain.Document{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			UPDATE documents SET superseded_at = NULL
+			WHERE id = (
+				SELECT id FROM documents
+				WHERE record_id = ? AND status = 'ready'
+				ORDER BY version DESC LIMIT 1
+			) AND NOT EXISTS (
+				SELECT 1 FROM documents
+				WHERE record_id = ? AND status = 'ready' AND superseded_at IS NULL
+			)`, doc.RecordID, doc.RecordID).Error
+	})
+}
+
+// documentFilter renders a search filter as a condition on the chunk's
+// document, for both HybridSearch CTEs. Values are bound as numbered
+// placeholders following args. Unless the filter asks for other versions,
+// superseded document versions are filtered out, even by a zero filter.
+func documentFilter(f domain.SearchFilter, args []interface{}) (string, []interface{}, error) {
+	if f.IsZero() {
+		return "AND EXISTS (SELECT 1 FROM documents d WHERE d.id = dc.document_id AND d.superseded_at IS NULL)", args, nil
+	}
+	bind := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	in := func(column string, n int, value func(i int) interface{}) string {
+		placeholders := make([]string, n)
+		for i := range placeholders {
+			placeholders[i] = bind(value(i))
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+	}
+
+	conds := []string{"d.id = dc.document_id", "d.tenant_id = dc.tenant_id"}
+	if f.Category != "" {
+		conds = append(conds, "d.category = "+bind(f.Category))
+	}
+	if len(f.DocumentIDs) > 0 {
+		conds = append(conds, in("d.id", len(f.DocumentIDs), func(i int) interface{} { return f.DocumentIDs[i].String() }))
+	}
+	if f.UploadedAfter != nil {
+		conds = append(conds, "d.created_at > "+bind(*f.UploadedAfter))
+	}
+	if f.UploadedBefore != nil {
+		conds = append(conds, "d.created_at < "+bind(*f.UploadedBefore))
+	}
+	if len(f.Statuses) > 0 {
+		conds = append(conds, in("d.status", len(f.Statuses), func(i int) interface{} { return f.Statuses[i] }))
+	}
+	if f.RecordID != nil {
+		conds = append(conds, "d.record_id = "+bind(f.RecordID.String()))
+	}
+	if f.Version > 0 {
+		conds = append(conds, "d.version = "+bind(f.Version))
+	}
+	if f.LatestOnly() {
+		conds = append(conds, "d.superseded_at IS NULL")
+	}
+	if len(f.Metadata) > 0 {
+		metadata, err := json.Marshal(f.Metadata)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid metadata filter: %w", err)
+		}
+		conds = append(conds, "d.metadata @> "+bind(string(metadata))+"::jsonb")
+	}
+	return fmt.Sprintf("AND EXISTS (SELECT 1 FROM documents d WHERE %s)", strings.Join(conds, " AND ")), args, nil
 }
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDocumentMarkReadySupersedesOlderVersions(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewDocumentRepository(gormDB)
+	doc := &domain.Document{ID: uuid.New(), RecordID: uuid.New(), Version: 3}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "documents" SET .*"status"=\$\d.* WHERE id = \$\d`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "documents" SET "superseded_at"=NOW\(\).* WHERE record_id = \$1 AND version < \$2 AND superseded_at IS NULL`).
+		WithArgs(doc.RecordID, doc.Version).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	if err := repo.MarkReady(context.Background(), doc, map[string]interface{}{"revision": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// A failed supersede rolls the ready status back, so the worker's retry
+// finds the version still processing.
+func TestDocumentMarkReadyRollsBack(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewDocumentRepository(gormDB)
+	doc := &domain.Document{ID: uuid.New(), RecordID: uuid.New(), Version: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "documents" SET .*"status"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "documents" SET "superseded_at"`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	if err := repo.MarkReady(context.Background(), doc, nil); err == nil {
+		t.Fatal("expected the supersede error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocumentCreateVersionTaken(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewDocumentRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "documents"`).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation, ConstraintName: recordVersionIndex})
+	mock.ExpectRollback()
+
+	err := repo.Create(context.Background(), &domain.Document{TenantID: uuid.New(), RecordID: uuid.New(), Version: 2})
+	if !errors.Is(err, domain.ErrDocumentVersionExists) {
+		t.Errorf("err = %v, want ErrDocumentVersionExists", err)
+	}
+}
+
+func TestDocumentStartProcessing(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewDocumentRepository(gormDB)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "documents" SET .*"status"=\$\d.* WHERE id = \$\d AND status <> \$\d`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.StartProcessing(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// No row matched: the document is already processing, so the caller lost
+// the race and must not touch it.
+func TestDocumentStartProcessingConflict(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewDocumentRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "documents" SET .* AND status <> \$\d`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.StartProcessing(context.Background(), uuid.New())
+	if !errors.Is(err, domain.ErrDocumentProcessing) {
+		t.Errorf("err = %v, want ErrDocumentProcessing", err)
+	}
+}
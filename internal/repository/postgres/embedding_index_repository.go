@@ -0,0 +1,337 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// hnswMaxDimension is the largest vector pgvector can index with HNSW;
+// larger vectors are searched exactly.
+const hnswMaxDimension = 2000
+
+type embeddingIndexRepository struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingIndexRepository(db *gorm.DB) domain.EmbeddingIndexRepository {
+	return &embeddingIndexRepository{db: db}
+}
+
+// chunkEmbedding is a row of chunk_embeddings. Embedding is a pgvector
+// literal (see pgvectorFormat).
+type chunkEmbedding struct {
+	IndexID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ChunkID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID `gorm:"type:uuid"`
+	Embedding string    `gorm:"type:vector"`
+}
+
+func (chunkEmbedding) TableName() string { return "chunk_embeddings" }
+
+func (r *embeddingIndexRepository) Create(ctx context.Context, idx *domain.EmbeddingIndex) error {
+	if err := r.db.WithContext(ctx).Create(idx).Error; err != nil {
+		return fmt.Errorf("failed to create embedding index: %w", err)
+	}
+	return nil
+}
+
+func (r *embeddingIndexRepository) Update(ctx context.Context, idx *domain.EmbeddingIndex) error {
+	if err := r.db.WithContext(ctx).Save(idx).Error; err != nil {
+		return fmt.Errorf("failed to update embedding index: %w", err)
+	}
+	return nil
+}
+
+func (r *embeddingIndexRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.EmbeddingIndex, error) {
+	return r.first(r.db.WithContext(ctx).Where("id = ?", id))
+}
+
+func (r *embeddingIndexRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.EmbeddingIndex, error) {
+	var indexes []*domain.EmbeddingIndex
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Find(&indexes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedding indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+func (r *embeddingIndexRepository) Active(ctx context.Context, tenantID uuid.UUID) (*domain.EmbeddingIndex, error) {
+	return r.first(r.db.WithContext(ctx).Where("tenant_id = ? AND status = ?", tenantID, domain.EmbeddingIndexActive))
+}
+
+func (r *embeddingIndexRepository) EnsureActive(ctx context.Context, tenantID uuid.UUID, model string, dimension int) (*domain.EmbeddingIndex, error) {
+	now := time.Now()
+	idx := &domain.EmbeddingIndex{
+		TenantID:    tenantID,
+		Model:       model,
+		Dimension:   dimension,
+		Status:      domain.EmbeddingIndexActive,
+		ActivatedAt: &now,
+	}
+	// The one-active-per-tenant unique index turns a concurrent creation
+	// into a no-op.
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(idx).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding index: %w", err)
+	}
+	active, err := r.Active(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, fmt.Errorf("tenant %s has no active embedding index", tenantID)
+	}
+	return active, nil
+}
+
+func (r *embeddingIndexRepository) ListSecondary(ctx context.Context, tenantID uuid.UUID) ([]*domain.EmbeddingIndex, error) {
+	var indexes []*domain.EmbeddingIndex
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND status IN ?", tenantID, []string{domain.EmbeddingIndexBuilding, domain.EmbeddingIndexStandby}).
+		Find(&indexes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedding indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+func (r *embeddingIndexRepository) CountChunks(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var n int64
+	if err := r.db.WithContext(ctx).Model(&domain.DocumentChunk{}).Where("tenant_id = ?", tenantID).Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("failed to count chunks: %w", err)
+	}
+	return n, nil
+}
+
+// missing selects the chunks of the index's tenant without a vector in it.
+func (r *embeddingIndexRepository) missing(db *gorm.DB, idx *domain.EmbeddingIndex) *gorm.DB {
+	q := db.Model(&domain.DocumentChunk{}).Where("document_chunks.tenant_id = ?", idx.TenantID)
+	if idx.Status == domain.EmbeddingIndexActive {
+		return q.Where("(embedding IS NULL OR embedding_model IS DISTINCT FROM ? OR embedding_dim IS DISTINCT FROM ?)", idx.Model, idx.Dimension)
+	}
+	return q.Where("NOT EXISTS (SELECT 1 FROM chunk_embeddings ce WHERE ce.index_id = ? AND ce.chunk_id = document_chunks.id)", idx.ID)
+}
+
+func (r *embeddingIndexRepository) MissingChunks(ctx context.Context, idx *domain.EmbeddingIndex, limit int) ([]domain.DocumentChunk, error) {
+	var chunks []domain.DocumentChunk
+	err := r.missing(r.db.WithContext(ctx), idx).
+		Select("id, tenant_id, document_id, content, chunk_index").
+		Order("id").
+		Limit(limit).
+		Find(&chunks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks missing from embedding index: %w", err)
+	}
+	return chunks, nil
+}
+
+func (r *embeddingIndexRepository) CountMissing(ctx context.Context, idx *domain.EmbeddingIndex) (int64, error) {
+	var n int64
+	if err := r.missing(r.db.WithContext(ctx), idx).Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("failed to count chunks missing from embedding index: %w", err)
+	}
+	return n, nil
+}
+
+func (r *embeddingIndexRepository) StoreVectors(ctx context.Context, idx *domain.EmbeddingIndex, vectors []domain.ChunkVector) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if idx.Status == domain.EmbeddingIndexActive {
+			for _, v := range vectors {
+				err := tx.Model(&domain.DocumentChunk{}).
+					Where("id = ? AND tenant_id = ?", v.ChunkID, idx.TenantID).
+					Updates(map[string]interface{}{
+						"embedding":       pgvectorFormat(v.Embedding),
+						"embedding_model": idx.Model,
+						"embedding_dim":   idx.Dimension,
+					}).Error
+				if err != nil {
+					return fmt.Errorf("failed to store chunk vector: %w", err)
+				}
+			}
+			return nil
+		}
+
+		rows := make([]chunkEmbedding, len(vectors))
+		for i, v := range vectors {
+			rows[i] = chunkEmbedding{IndexID: idx.ID, ChunkID: v.ChunkID, TenantID: idx.TenantID, Embedding: pgvectorFormat(v.Embedding)}
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "index_id"}, {Name: "chunk_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"embedding"}),
+		}).Create(&rows).Error
+		if err != nil {
+			return fmt.Errorf("failed to store chunk vectors: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *embeddingIndexRepository) Activate(ctx context.Context, idx *domain.EmbeddingIndex, requireComplete bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the tenant's indexes: switches of one tenant are serialized.
+		var indexes []*domain.EmbeddingIndex
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ?", idx.TenantID).
+			Find(&indexes).Error; err != nil {
+			return fmt.Errorf("failed to lock embedding indexes: %w", err)
+		}
+		var target, current *domain.EmbeddingIndex
+		for _, i := range indexes {
+			switch {
+			case i.ID == idx.ID:
+				target = i
+			case i.Status == domain.EmbeddingIndexActive:
+				current = i
+			}
+		}
+		if target == nil || (target.Status != domain.EmbeddingIndexBuilding && target.Status != domain.EmbeddingIndexStandby) {
+			return fmt.Errorf("embedding index %s cannot be activated", idx.ID)
+		}
+		if requireComplete {
+			var missing int64
+			if err := r.missing(tx, target).Count(&missing).Error; err != nil {
+				return fmt.Errorf("failed to count chunks missing from embedding index: %w", err)
+			}
+			if missing > 0 {
+				return fmt.Errorf("%w: %d chunks missing", domain.ErrEmbeddingIndexIncomplete, missing)
+			}
+		}
+
+		// Retire older standby indexes; only the one being replaced is kept.
+		for _, i := range indexes {
+			if i.Status == domain.EmbeddingIndexStandby && i.ID != target.ID {
+				if err := r.discard(tx, i, domain.EmbeddingIndexRetired); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Keep the current vectors for a rollback.
+		if current != nil {
+			err := tx.Exec(`
+				INSERT INTO chunk_embeddings (index_id, chunk_id, tenant_id, embedding)
+				SELECT ?, dc.id, dc.tenant_id, dc.embedding
+				FROM document_chunks dc
+				WHERE dc.tenant_id = ? AND dc.embedding IS NOT NULL
+				  AND dc.embedding_model = ? AND dc.embedding_dim = ?
+				ON CONFLICT (index_id, chunk_id) DO UPDATE SET embedding = EXCLUDED.embedding`,
+				current.ID, current.TenantID, current.Model, current.Dimension).Error
+			if err != nil {
+				return fmt.Errorf("failed to keep active vectors: %w", err)
+			}
+			current.Status = domain.EmbeddingIndexStandby
+			if err := tx.Save(current).Error; err != nil {
+				return fmt.Errorf("failed to update embedding index: %w", err)
+			}
+		}
+
+		// Swap in the new vectors; they now live in document_chunks only.
+		err := tx.Exec(`
+			UPDATE document_chunks dc
+			SET embedding = ce.embedding, embedding_model = ?, embedding_dim = ?
+			FROM chunk_embeddings ce
+			WHERE ce.index_id = ? AND ce.chunk_id = dc.id`,
+			target.Model, target.Dimension, target.ID).Error
+		if err != nil {
+			return fmt.Errorf("failed to swap chunk vectors: %w", err)
+		}
+		if err := tx.Where("index_id = ?", target.ID).Delete(&chunkEmbedding{}).Error; err != nil {
+			return fmt.Errorf("failed to drop swapped vectors: %w", err)
+		}
+
+		now := time.Now()
+		target.Status = domain.EmbeddingIndexActive
+		target.ActivatedAt = &now
+		target.Error = ""
+		if err := tx.Save(target).Error; err != nil {
+			return fmt.Errorf("failed to activate embedding index: %w", err)
+		}
+		*idx = *target
+		return nil
+	})
+}
+
+func (r *embeddingIndexRepository) Discard(ctx context.Context, idx *domain.EmbeddingIndex, status string) error {
+	if idx.Status == domain.EmbeddingIndexActive {
+		return fmt.Errorf("the active embedding index cannot be discarded")
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return r.discard(tx, idx, status)
+	})
+}
+
+func (r *embeddingIndexRepository) discard(tx *gorm.DB, idx *domain.EmbeddingIndex, status string) error {
+	if err := tx.Where("index_id = ?", idx.ID).Delete(&chunkEmbedding{}).Error; err != nil {
+		return fmt.Errorf("failed to drop embedding index vectors: %w", err)
+	}
+	idx.Status = status
+	if err := tx.Save(idx).Error; err != nil {
+		return fmt.Errorf("failed to update embedding index: %w", err)
+	}
+	return nil
+}
+
+func (r *embeddingIndexRepository) EnsureVectorIndex(ctx context.Context, dimension int) error {
+	if dimension < 1 || dimension > hnswMaxDimension {
+		return nil
+	}
+	name := fmt.Sprintf("idx_chunks_hnsw_%d", dimension)
+
+	// A failed concurrent build leaves an INVALID index behind, which IF NOT
+	// EXISTS would keep forever. Drop it, unless another replica is still
+	// building it (an index is invalid until its build completes).
+	var invalid bool
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT NOT i.indisvalid AND NOT EXISTS (
+			SELECT 1 FROM pg_stat_progress_create_index p WHERE p.index_relid = i.indexrelid
+		)
+		FROM pg_index i
+		WHERE i.indexrelid = to_regclass(?)`, name).Row().Scan(&invalid)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check vector index for dimension %d: %w", dimension, err)
+	}
+	if invalid {
+		if err := r.db.WithContext(ctx).Exec("DROP INDEX CONCURRENTLY IF EXISTS " + name).Error; err != nil {
+			return fmt.Errorf("failed to drop invalid vector index for dimension %d: %w", dimension, err)
+		}
+	}
+
+	// CONCURRENTLY keeps ingestion and search running during the build; it
+	// cannot run inside a transaction.
+	createSQL := fmt.Sprintf(`CREATE INDEX CONCURRENTLY IF NOT EXISTS %s
+		ON document_chunks USING hnsw ((embedding::vector(%d)) vector_cosine_ops)
+		WITH (m = 16, ef_construction = 64)
+		WHERE embedding_dim = %d`, name, dimension, dimension)
+	if err := r.db.WithContext(ctx).Exec(createSQL).Error; err != nil {
+		return fmt.Errorf("failed to create vector index for dimension %d: %w", dimension, err)
+	}
+	return nil
+}
+
+func (r *embeddingIndexRepository) first(q *gorm.DB) (*domain.EmbeddingIndex, error) {
+	var idx domain.EmbeddingIndex
+	err := q.First(&idx).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding index: %w", err)
+	}
+	return &idx, nil
+}
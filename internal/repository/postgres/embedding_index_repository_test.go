@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEnsureVectorIndex(t *testing.T) {
+	cases := []struct {
+		name string
+		// rows is the result of the validity check; nil when no index exists.
+		rows     *sqlmock.Rows
+		wantDrop bool
+	}{
+		{name: "no index yet"},
+		{name: "valid or still building", rows: sqlmock.NewRows([]string{"invalid"}).AddRow(false)},
+		{name: "left invalid by a failed build", rows: sqlmock.NewRows([]string{"invalid"}).AddRow(true), wantDrop: true},
+	}
+	for _, c := range cases {
+		gormDB, mock := setupMockDB(t)
+		repo := NewEmbeddingIndexRepository(gormDB)
+
+		rows := c.rows
+		if rows == nil {
+			rows = sqlmock.NewRows([]string{"invalid"})
+		}
+		mock.ExpectQuery(`SELECT NOT i.indisvalid .*pg_stat_progress_create_index.* WHERE i.indexrelid = to_regclass\(\$1\)`).
+			WithArgs("idx_chunks_hnsw_768").
+			WillReturnRows(rows)
+		if c.wantDrop {
+			mock.ExpectExec(`DROP INDEX CONCURRENTLY IF EXISTS idx_chunks_hnsw_768`).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec(`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_chunks_hnsw_768`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		if err := repo.EnsureVectorIndex(context.Background(), 768); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+}
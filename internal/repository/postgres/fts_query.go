@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// indonesianStopwords are dropped from queries: PostgreSQL's Indonesian
+// configuration stems but ships no stopword list, so these words would
+// otherwise have to appear in every match.
+var indonesianStopwords = map[string]bool{
+	"ada": true, "adalah": true, "agar": true, "akan": true, "antara": true,
+	"apa": true, "apakah": true, "atas": true, "bagaimana": true, "bagi": true,
+	"bahwa": true, "beberapa": true, "belum": true, "berapa": true, "bisa": true,
+	"dalam": true, "dan": true, "dapat": true, "dari": true, "daripada": true,
+	"dengan": true, "di": true, "hanya": true, "harus": true, "hingga": true,
+	"ini": true, "itu": true, "jika": true, "juga": true, "kapan": true,
+	"karena": true, "ke": true, "kepada": true, "ketika": true, "lagi": true,
+	"maka": true, "mana": true, "masih": true, "mengapa": true, "namun": true,
+	"oleh": true, "pada": true, "para": true, "saat": true, "saja": true,
+	"sampai": true, "sangat": true, "secara": true, "sebagai": true, "sebelum": true,
+	"sedang": true, "sehingga": true, "sejak": true, "selama": true, "seperti": true,
+	"serta": true, "setelah": true, "siapa": true, "sudah": true, "tanpa": true,
+	"telah": true, "tentang": true, "terhadap": true, "tersebut": true, "tetapi": true,
+	"untuk": true, "yaitu": true, "yakni": true, "yang": true,
+}
+
+// pgFTSQuery converts a user query to to_tsquery syntax. Words are ANDed;
+// a "quoted phrase" must match as a phrase, OR/ATAU (or |) between words
+// gives alternatives, and a leading - excludes a word. Every word is quoted,
+// so no input can break the tsquery syntax; the text search configuration
+// still tokenizes and stems it exactly like the indexed content. Words
+// without a letter or digit are dropped, and so are, outside phrases,
+// Indonesian stopwords when language is indonesian; the other configurations
+// drop their own stopwords in to_tsquery.
+func pgFTSQuery(text, language string) string {
+	stopwords := language == domain.FTSIndonesian
+	var groups []string // OR-ed alternatives, each an AND of terms
+	var terms []string
+	positive := false
+	flush := func() {
+		if positive {
+			groups = append(groups, strings.Join(terms, " & "))
+		}
+		terms, positive = nil, false
+	}
+
+	for rest := strings.TrimSpace(text); rest != ""; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		var token string
+		phrase := rest[0] == '"'
+		if phrase {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				token, rest = rest[1:], ""
+			} else {
+				token, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(rest)
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+
+		if !phrase {
+			switch strings.ToLower(token) {
+			case "or", "atau", "|":
+				flush()
+				continue
+			}
+		}
+		negate := !phrase && len(token) > 1 && token[0] == '-'
+		if negate {
+			token = token[1:]
+		}
+		token = strings.TrimFunc(token, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if token == "" || (stopwords && !phrase && indonesianStopwords[strings.ToLower(token)]) {
+			continue
+		}
+
+		term := "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(token) + "'"
+		if negate {
+			term = "!" + term
+		} else {
+			positive = true
+		}
+		terms = append(terms, term)
+	}
+	flush()
+	return strings.Join(groups, " | ")
+}
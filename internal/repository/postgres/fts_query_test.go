@@ -0,0 +1,49 @@
+package postgres
+
+import "testing"
+
+func TestPgFTSQuery(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"modal inti", "'modal' & 'inti'"},
+		{"Berapa rasio kecukupan modal yang berlaku?", "'rasio' & 'kecukupan' & 'modal' & 'berlaku'"},
+		{"pembiayaan syariah OR murabahah", "'pembiayaan' & 'syariah' | 'murabahah'"},
+		{"likuiditas atau solvabilitas", "'likuiditas' | 'solvabilitas'"},
+		{`"batas maksimum pemberian kredit" bank`, "'batas maksimum pemberian kredit' & 'bank'"},
+		{`"tata kelola yang baik"`, "'tata kelola yang baik'"},
+		{"kredit -konsumsi", "'kredit' & !'konsumsi'"},
+		{"POJK 11/POJK.03/2022", "'POJK' & '11/POJK.03/2022'"},
+		{"pengelolaan keuangan daerah (Perda)", "'pengelolaan' & 'keuangan' & 'daerah' & 'Perda'"},
+		{"tanggung-jawab direksi", "'tanggung-jawab' & 'direksi'"},
+		{"l'aéroport & économie", "'l''aéroport' & 'économie'"},
+		{`back\slash`, `'back\\slash'`},
+		{"OR -saja ???", ""},
+		{"", ""},
+		{`"frasa tanpa penutup`, "'frasa tanpa penutup'"},
+	}
+	for _, c := range cases {
+		if got := pgFTSQuery(c.query, "indonesian"); got != c.want {
+			t.Errorf("pgFTSQuery(%q) = %q, want %q", c.query, got, c.want)
+		}
+	}
+}
+
+// Only Indonesian queries lose Indonesian stopwords: in english and simple
+// chunks those words are ordinary terms.
+func TestPgFTSQueryLanguages(t *testing.T) {
+	cases := []struct {
+		query, language, want string
+	}{
+		{"modal yang berlaku", "indonesian", "'modal' & 'berlaku'"},
+		{"modal yang berlaku", "english", "'modal' & 'yang' & 'berlaku'"},
+		{"Bank Indonesia dan OJK", "simple", "'Bank' & 'Indonesia' & 'dan' & 'OJK'"},
+		{"the capital of a bank", "english", "'the' & 'capital' & 'of' & 'a' & 'bank'"},
+	}
+	for _, c := range cases {
+		if got := pgFTSQuery(c.query, c.language); got != c.want {
+			t.Errorf("pgFTSQuery(%q, %s) = %q, want %q", c.query, c.language, got, c.want)
+		}
+	}
+}
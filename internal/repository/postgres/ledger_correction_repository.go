@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ledgerCorrectionRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerCorrectionRepository(db *gorm.DB) domain.LedgerCorrectionRepository {
+	return &ledgerCorrectionRepository{db: db}
+}
+
+func (r *ledgerCorrectionRepository) Create(ctx context.Context, correction *domain.LedgerCorrection) error {
+	if err := r.db.WithContext(ctx).Create(correction).Error; err != nil {
+		return fmt.Errorf("failed to record ledger correction: %w", err)
+	}
+	return nil
+}
+
+func (r *ledgerCorrectionRepository) GetByID(ctx context.Context, id string) (*domain.LedgerCorrection, error) {
+	var correction domain.LedgerCorrection
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&correction).Error; err != nil {
+		return nil, fmt.Errorf("failed to get ledger correction: %w", err)
+	}
+	return &correction, nil
+}
+
+func (r *ledgerCorrectionRepository) Update(ctx context.Context, correction *domain.LedgerCorrection) error {
+	if err := r.db.WithContext(ctx).Save(correction).Error; err != nil {
+		return fmt.Errorf("failed to update ledger correction: %w", err)
+	}
+	return nil
+}
+
+func (r *ledgerCorrectionRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.LedgerCorrection, error) {
+	var corrections []*domain.LedgerCorrection
+	err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Find(&corrections).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger corrections: %w", err)
+	}
+	return corrections, nil
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// catalogSearchVector must match the expression of the generated
+// reference_prices.search_tsv column so the GIN index is used.
+const catalogSearchVector = "search_tsv"
+
+type referencePriceRepository struct {
+	db *gorm.DB
+}
+
+func NewReferencePriceRepository(db *gorm.DB) domain.ReferencePriceRepository {
+	return &referencePriceRepository{db: db}
+}
+
+func (r *referencePriceRepository) Upsert(ctx context.Context, prices []*domain.ReferencePrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tenant_id"}, {Name: "fiscal_year"}, {Name: "region"}, {Name: "item_code"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"description", "unit", "max_price", "effective_date", "source_regulation", "updated_at",
+			}),
+		}).
+		CreateInBatches(prices, 500).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert reference prices: %w", err)
+	}
+	return nil
+}
+
+func (r *referencePriceRepository) DeleteScope(ctx context.Context, tenantID string, fiscalYear int, region string) error {
+	q := r.db.WithContext(ctx).Where("tenant_id = ? AND fiscal_year = ?", tenantID, fiscalYear)
+	if region != "" {
+		q = q.Where("region = ?", region)
+	}
+	if err := q.Delete(&domain.ReferencePrice{}).Error; err != nil {
+		return fmt.Errorf("failed to delete reference prices: %w", err)
+	}
+	return nil
+}
+
+func (r *referencePriceRepository) List(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, int64, error) {
+	var prices []*domain.ReferencePrice
+	var total int64
+
+	scope := func() *gorm.DB {
+		db := r.db.WithContext(ctx).Model(&domain.ReferencePrice{}).Where("tenant_id = ?", q.TenantID)
+		if q.FiscalYear > 0 {
+			db = db.Where("fiscal_year = ?", q.FiscalYear)
+		}
+		if q.Region != "" {
+			db = db.Where("region ILIKE ?", q.Region)
+		}
+		if q.ItemCode != "" {
+			db = db.Where("item_code = ?", q.ItemCode)
+		}
+		if q.Text != "" {
+			db = db.Where("(description ILIKE ? OR item_code ILIKE ?)", "%"+q.Text+"%", "%"+q.Text+"%")
+		}
+		return db
+	}
+
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reference prices: %w", err)
+	}
+	err := scope().
+		Order("fiscal_year DESC, item_code ASC").
+		Limit(q.Limit).
+		Offset(q.Offset).
+		Find(&prices).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list reference prices: %w", err)
+	}
+	return prices, total, nil
+}
+
+// Lookup ranks entries by item code match, then text relevance, preferring
+// the requested region over region-less entries and newer fiscal years.
+func (r *referencePriceRepository) Lookup(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	asOf := q.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	db := r.db.WithContext(ctx).
+		Model(&domain.ReferencePrice{}).
+		Where("tenant_id = ?", q.TenantID).
+		Where("effective_date <= ?", asOf)
+
+	if q.FiscalYear > 0 {
+		db = db.Where("fiscal_year = ?", q.FiscalYear)
+	}
+	if q.Region != "" {
+		db = db.Where("(region ILIKE ? OR region = '')", q.Region)
+	}
+
+	tsQuery := catalogTSQuery(q.Text)
+	switch {
+	case q.ItemCode != "" && tsQuery != "":
+		db = db.Where("(item_code = ? OR "+catalogSearchVector+" @@ to_tsquery('simple', ?))", q.ItemCode, tsQuery)
+	case q.ItemCode != "":
+		db = db.Where("item_code = ?", q.ItemCode)
+	case tsQuery != "":
+		db = db.Where(catalogSearchVector+" @@ to_tsquery('simple', ?)", tsQuery)
+	default:
+		return nil, nil
+	}
+
+	// Without a text query there is nothing to rank on: the ts_rank_cd term
+	// is left out rather than ranking against an empty query.
+	order := clause.Expr{SQL: "(item_code = ?) DESC, ", Vars: []interface{}{q.ItemCode}}
+	if tsQuery != "" {
+		order.SQL += "ts_rank_cd(" + catalogSearchVector + ", to_tsquery('simple', ?)) DESC, "
+		order.Vars = append(order.Vars, tsQuery)
+	}
+	order.SQL += "(region ILIKE ?) DESC, fiscal_year DESC, effective_date DESC"
+	order.Vars = append(order.Vars, q.Region)
+
+	var prices []*domain.ReferencePrice
+	err := db.
+		Clauses(clause.OrderBy{Expression: order}).
+		Limit(limit).
+		Find(&prices).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up reference prices: %w", err)
+	}
+	return prices, nil
+}
+
+func (r *referencePriceRepository) FiscalYears(ctx context.Context, tenantID string) ([]int, error) {
+	var years []int
+	err := r.db.WithContext(ctx).
+		Model(&domain.ReferencePrice{}).
+		Where("tenant_id = ?", tenantID).
+		Distinct("fiscal_year").
+		Order("fiscal_year DESC").
+		Pluck("fiscal_year", &years).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fiscal years: %w", err)
+	}
+	return years, nil
+}
+
+// catalogTSQuery ORs the meaningful words of free text so that descriptions
+// sharing the most words rank highest ("laptop core i7" matches "Laptop/Notebook Core i7").
+func catalogTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range words {
+		if len([]rune(w)) < 2 || catalogStopwords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, "'"+w+"'")
+	}
+	return strings.Join(terms, " | ")
+}
+
+var catalogStopwords = map[string]bool{
+	"dan": true, "atau": true, "untuk": true, "dengan": true, "yang": true, "di": true, "ke": true,
+	"dari": true, "per": true, "pengadaan": true, "belanja": true, "harga": true, "sebesar": true,
+	"senilai": true, "total": true, "rp": true, "unit": true, "buah": true, "paket": true, "the": true,
+	"and": true, "for": true, "of": true, "juta": true, "ribu": true, "miliar": true,
+}
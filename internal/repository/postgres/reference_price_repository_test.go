@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestReferencePriceLookupQueries(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   domain.ReferencePriceQuery
+		tsQuery bool
+	}{
+		{"item code only", domain.ReferencePriceQuery{TenantID: "t", ItemCode: "1.1.12"}, false},
+		{"stopwords only", domain.ReferencePriceQuery{TenantID: "t", ItemCode: "1.1.12", Text: "pengadaan dan belanja"}, false},
+		{"text", domain.ReferencePriceQuery{TenantID: "t", Text: "laptop core i7"}, true},
+	}
+	for _, c := range cases {
+		var queries []string
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(_, actual string) error {
+			queries = append(queries, actual)
+			return nil
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock.ExpectQuery("").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		if _, err := NewReferencePriceRepository(gormDB).Lookup(context.Background(), c.query); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(queries) != 1 {
+			t.Fatalf("%s: %d queries", c.name, len(queries))
+		}
+		if got := strings.Contains(queries[0], "to_tsquery"); got != c.tsQuery {
+			t.Errorf("%s: to_tsquery in query = %v, want %v: %s", c.name, got, c.tsQuery, queries[0])
+		}
+		if strings.Contains(queries[0], "''") && !strings.Contains(queries[0], "region = ''") {
+			t.Errorf("%s: empty literal in query: %s", c.name, queries[0])
+		}
+	}
+}
+
+func TestReferencePriceLookupWithoutCriteria(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	prices, err := NewReferencePriceRepository(gormDB).Lookup(context.Background(), domain.ReferencePriceQuery{TenantID: "t", Text: "dan"})
+	if err != nil || prices != nil {
+		t.Fatalf("Lookup = %v, %v; want no rows and no query", prices, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// passingVerdicts are verdicts that do not count as a flagged finding.
+var passingVerdicts = []string{"OK", "PASS", "PASSED", "APPROVED", "VALID", "NORMAL", "WAJAR", "SESUAI"}
+
+// findingUpdateColumns are the columns a repeated finding overwrites; its
+// id and created_at are kept.
+var findingUpdateColumns = []string{
+	"document_id", "item_code", "item_name", "supplier", "unit", "quantity", "unit_price",
+	"reference_price", "deviation_pct", "verdict", "rationale", "agent_votes", "raw",
+}
+
+type SwarmFindingRepository struct {
+	db *gorm.DB
+}
+
+func NewSwarmFindingRepository(db *gorm.DB) *SwarmFindingRepository {
+	return &SwarmFindingRepository{db: db}
+}
+
+// ReplaceForTask swaps the findings of a task atomically, so a repeated
+// callback for the same task never duplicates rows. Findings are upserted by
+// item index: an item found again keeps its row and created_at, so the date
+// filters keep dating findings by when they were first reported.
+func (r *SwarmFindingRepository) ReplaceForTask(ctx context.Context, taskID string, findings []*domain.SwarmFinding) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ? AND item_index >= ?", taskID, len(findings)).
+			Delete(&domain.SwarmFinding{}).Error; err != nil {
+			return fmt.Errorf("failed to delete old findings: %w", err)
+		}
+		if len(findings) == 0 {
+			return nil
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}, {Name: "item_index"}},
+			DoUpdates: clause.AssignmentColumns(findingUpdateColumns),
+		}).CreateInBatches(findings, 200).Error
+		if err != nil {
+			return fmt.Errorf("failed to upsert findings: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *SwarmFindingRepository) ListByTask(ctx context.Context, tenantID, taskID string) ([]*domain.SwarmFinding, error) {
+	var findings []*domain.SwarmFinding
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND task_id = ?", tenantID, taskID).
+		Order("item_index ASC").
+		Find(&findings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task findings: %w", err)
+	}
+	return findings, nil
+}
+
+func (r *SwarmFindingRepository) List(ctx context.Context, filter domain.FindingFilter) ([]*domain.SwarmFinding, int64, error) {
+	var findings []*domain.SwarmFinding
+	var total int64
+
+	if err := r.applyFilter(r.db.WithContext(ctx).Model(&domain.SwarmFinding{}), filter).
+		Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count findings: %w", err)
+	}
+
+	err := r.applyFilter(r.db.WithContext(ctx).Model(&domain.SwarmFinding{}), filter).
+		Order(findingOrder(filter.SortBy)).
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&findings).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list findings: %w", err)
+	}
+
+	return findings, total, nil
+}
+
+func (r *SwarmFindingRepository) Stats(ctx context.Context, filter domain.FindingFilter) (*domain.FindingStats, error) {
+	stats := &domain.FindingStats{
+		ByVerdict:    []domain.VerdictCount{},
+		TopSuppliers: []domain.SupplierFindingAgg{},
+		Monthly:      []domain.MonthlyFindingAgg{},
+	}
+	flagged := "UPPER(COALESCE(verdict, '')) NOT IN ?"
+
+	var totals struct {
+		Total        int64
+		Flagged      int64
+		AvgDeviation float64
+		Overpaid     float64
+	}
+	err := r.applyFilter(r.db.WithContext(ctx).Model(&domain.SwarmFinding{}), filter).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE `+flagged+`) AS flagged,
+			COALESCE(AVG(deviation_pct), 0) AS avg_deviation,
+			COALESCE(SUM(GREATEST(unit_price - reference_price, 0) * COALESCE(quantity, 1)), 0) AS overpaid`,
+			passingVerdicts).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate findings: %w", err)
+	}
+	stats.TotalFindings = totals.Total
+	stats.FlaggedFindings = totals.Flagged
+	stats.AvgDeviationPct = totals.AvgDeviation
+	stats.PotentialOverpaid = totals.Overpaid
+
+	if err := r.applyFilter(r.db.WithContext(ctx).Model(&domain.SwarmFinding{}), filter).
+		Select("COALESCE(NULLIF(verdict, ''), 'UNKNOWN') AS verdict, COUNT(*) AS count").
+		Group("1").
+		Order("count DESC").
+		Scan(&stats.ByVerdict).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate findings by verdict: %w", err)
+	}
+
+	if err := r.applyFilter(r.db.WithContext(ctx).Model(&domain.SwarmFinding{}), filter).
+		Select(`supplier,
+			COUNT(*) AS findings,
+			COUNT(*) FILTER (WHERE `+flagged+`) AS flagged,
+			COALESCE(AVG(deviation_pct), 0) AS avg_deviation_pct`, passingVerdicts).
+		Where("supplier <> ''").
+		Group("supplier").
+		Order("flagged DESC, findings DESC").
+		Limit(10).
+		Scan(&stats.TopSuppliers).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate findings by supplier: %w", err)
+	}
+
+	if err := r.applyFilter(r.db.WithContext(ctx).Model(&domain.SwarmFinding{}), filter).
+		Select(`TO_CHAR(DATE_TRUNC('month', created_at), 'YYYY-MM') AS month,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE `+flagged+`) AS flagged`, passingVerdicts).
+		Group("1").
+		Order("1 ASC").
+		Scan(&stats.Monthly).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate findings by month: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *SwarmFindingRepository) applyFilter(q *gorm.DB, f domain.FindingFilter) *gorm.DB {
+	q = q.Where("tenant_id = ?", f.TenantID)
+	if f.TaskID != "" {
+		q = q.Where("task_id = ?", f.TaskID)
+	}
+	if f.DocumentID != "" {
+		q = q.Where("document_id = ?", f.DocumentID)
+	}
+	if f.Verdict != "" {
+		if strings.EqualFold(f.Verdict, "flagged") {
+			q = q.Where("UPPER(COALESCE(verdict, '')) NOT IN ?", passingVerdicts)
+		} else {
+			q = q.Where("UPPER(verdict) = ?", strings.ToUpper(f.Verdict))
+		}
+	}
+	if f.Supplier != "" {
+		q = q.Where("supplier ILIKE ?", "%"+f.Supplier+"%")
+	}
+	if f.Search != "" {
+		q = q.Where("(item_name ILIKE ? OR item_code ILIKE ?)", "%"+f.Search+"%", "%"+f.Search+"%")
+	}
+	if f.MinDeviation != nil {
+		q = q.Where("deviation_pct >= ?", *f.MinDeviation)
+	}
+	if f.MaxDeviation != nil {
+		q = q.Where("deviation_pct <= ?", *f.MaxDeviation)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+	return q
+}
+
+func findingOrder(sortBy string) string {
+	switch sortBy {
+	case "deviation":
+		return "deviation_pct DESC NULLS LAST, created_at DESC"
+	case "unit_price":
+		return "unit_price DESC NULLS LAST, created_at DESC"
+	case "oldest":
+		return "created_at ASC, item_index ASC"
+	default:
+		return "created_at DESC, item_index ASC"
+	}
+}
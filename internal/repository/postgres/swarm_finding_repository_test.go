@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database: %v", err)
+	}
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm DB: %v", err)
+	}
+	return gormDB, mock
+}
+
+func TestSwarmFindingApplyFilter(t *testing.T) {
+	gormDB, _ := setupMockDB(t)
+	repo := NewSwarmFindingRepository(gormDB)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	minDev := 10.0
+
+	cases := []struct {
+		name   string
+		filter domain.FindingFilter
+		want   []string
+		args   int
+	}{
+		{"tenant only", domain.FindingFilter{TenantID: "t"}, []string{"tenant_id = $1"}, 1},
+		{"flagged verdict", domain.FindingFilter{TenantID: "t", Verdict: "Flagged"},
+			[]string{"UPPER(COALESCE(verdict, '')) NOT IN ("}, 1 + len(passingVerdicts)},
+		{"exact verdict", domain.FindingFilter{TenantID: "t", Verdict: "markup"}, []string{"UPPER(verdict) = $2"}, 2},
+		{"search", domain.FindingFilter{TenantID: "t", Search: "laptop"}, []string{"item_name ILIKE $2 OR item_code ILIKE $3"}, 3},
+		{"deviation and date", domain.FindingFilter{TenantID: "t", MinDeviation: &minDev, From: &from},
+			[]string{"deviation_pct >= $2", "created_at >= $3"}, 3},
+	}
+	for _, c := range cases {
+		stmt := repo.applyFilter(gormDB.Session(&gorm.Session{DryRun: true}).Model(&domain.SwarmFinding{}), c.filter).
+			Find(&[]domain.SwarmFinding{}).Statement
+		sql := stmt.SQL.String()
+		for _, w := range c.want {
+			if !strings.Contains(sql, w) {
+				t.Errorf("%s: %q not in %s", c.name, w, sql)
+			}
+		}
+		if len(stmt.Vars) != c.args {
+			t.Errorf("%s: %d bound args, want %d: %v", c.name, len(stmt.Vars), c.args, stmt.Vars)
+		}
+	}
+}
+
+func TestSwarmFindingReplaceForTaskKeepsCreatedAt(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewSwarmFindingRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "swarm_findings" WHERE task_id = $1 AND item_index >= $2`)).
+		WithArgs("task-1", 1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`INSERT INTO "swarm_findings" .* ON CONFLICT \("task_id","item_index"\) DO UPDATE SET .*"verdict"="excluded"."verdict"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("f-1"))
+	mock.ExpectCommit()
+
+	findings := []*domain.SwarmFinding{{TenantID: "t", TaskID: "task-1", DocumentID: "d", Verdict: "MARKUP"}}
+	if err := repo.ReplaceForTask(context.Background(), "task-1", findings); err != nil {
+		t.Fatalf("ReplaceForTask: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindingUpdateColumnsKeepIdentity(t *testing.T) {
+	for _, col := range findingUpdateColumns {
+		switch col {
+		case "id", "created_at", "tenant_id", "task_id", "item_index":
+			t.Errorf("upsert overwrites %s", col)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"gorm.io/gorm"
@@ -35,6 +36,56 @@ func (r *SwarmRepository) GetByID(ctx context.Context, id string) (*domain.Swarm
 	return &task, nil
 }
 
+// GetByIDForTenant loads a task only if its document belongs to the tenant.
+func (r *SwarmRepository) GetByIDForTenant(ctx context.Context, tenantID, id string) (*domain.SwarmTask, error) {
+	var task domain.SwarmTask
+	err := r.db.WithContext(ctx).
+		Table("swarm_tasks").
+		Select("swarm_tasks.*").
+		Joins("JOIN documents ON documents.id = swarm_tasks.document_id").
+		Where("swarm_tasks.id = ? AND documents.tenant_id = ?", id, tenantID).
+		First(&task).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swarm task: %w", err)
+	}
+	return &task, nil
+}
+
+// GetTenantID resolves the tenant owning a task through its document.
+func (r *SwarmRepository) GetTenantID(ctx context.Context, taskID string) (string, error) {
+	var tenantID string
+	err := r.db.WithContext(ctx).
+		Table("swarm_tasks").
+		Select("documents.tenant_id").
+		Joins("JOIN documents ON documents.id = swarm_tasks.document_id").
+		Where("swarm_tasks.id = ?", taskID).
+		Scan(&tenantID).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve task tenant: %w", err)
+	}
+	if tenantID == "" {
+		return "", fmt.Errorf("tenant not found for swarm task %s", taskID)
+	}
+	return tenantID, nil
+}
+
+// GetTenantLedgerProfile returns the ledger_profile setting of the tenant
+// owning a task, "" when the tenant has none.
+func (r *SwarmRepository) GetTenantLedgerProfile(ctx context.Context, taskID string) (string, error) {
+	var profile string
+	err := r.db.WithContext(ctx).
+		Table("swarm_tasks").
+		Select("COALESCE(tenants.settings->>'ledger_profile', '')").
+		Joins("JOIN documents ON documents.id = swarm_tasks.document_id").
+		Joins("JOIN tenants ON tenants.id = documents.tenant_id").
+		Where("swarm_tasks.id = ?", taskID).
+		Scan(&profile).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve tenant ledger profile: %w", err)
+	}
+	return profile, nil
+}
+
 func (r *SwarmRepository) Update(ctx context.Context, task *domain.SwarmTask) error {
 	if err := r.db.WithContext(ctx).Save(task).Error; err != nil {
 		return fmt.Errorf("failed to update swarm task: %w", err)
@@ -69,3 +120,21 @@ func (r *SwarmRepository) ListByTenant(ctx context.Context, tenantID string, lim
 
 	return tasks, total, err
 }
+
+// ListPendingConfirmation returns the tasks of a ledger profile whose machine
+// or review transaction has been PENDING_CONFIRMATION since before the given
+// time.
+func (r *SwarmRepository) ListPendingConfirmation(ctx context.Context, profile string, before time.Time, limit int) ([]*domain.SwarmTask, error) {
+	var tasks []*domain.SwarmTask
+	err := r.db.WithContext(ctx).
+		Where("ledger_profile = ?", profile).
+		Where("(blockchain_stat = ? AND blockchain_tx <> '') OR (review_chain_stat = ? AND review_tx <> '')", domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusPendingConfirmation).
+		Where("updated_at < ?", before).
+		Order("updated_at").
+		Limit(limit).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending confirmations: %w", err)
+	}
+	return tasks, nil
+}
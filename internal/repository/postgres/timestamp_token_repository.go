@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	uniqueViolation = "23505"
+	// activeTimestampIndex allows one token per log_id that has not been
+	// superseded.
+	activeTimestampIndex = "idx_timestamp_tokens_active"
+)
+
+type timestampTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTimestampTokenRepository(db *gorm.DB) domain.TimestampTokenRepository {
+	return &timestampTokenRepository{db: db}
+}
+
+func (r *timestampTokenRepository) Create(ctx context.Context, token *domain.TimestampToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == activeTimestampIndex {
+			return domain.ErrTimestampLogActive
+		}
+		return fmt.Errorf("failed to store timestamp token: %w", err)
+	}
+	return nil
+}
+
+// Supersede allocates the new token's ID first and retires the old token
+// before inserting the new one, so a log never has two active tokens (see
+// idx_timestamp_tokens_active). superseded_by is checked at commit, when
+// the new row exists. A concurrent correction of the same token blocks on
+// its row and then finds it no longer active.
+func (r *timestampTokenRepository) Supersede(ctx context.Context, oldID int64, token *domain.TimestampToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT nextval(pg_get_serial_sequence('timestamp_tokens', 'id'))").Scan(&token.ID).Error; err != nil {
+			return fmt.Errorf("failed to allocate timestamp token id: %w", err)
+		}
+		res := tx.Model(&domain.TimestampToken{}).
+			Where("id = ? AND superseded_by IS NULL", oldID).
+			Update("superseded_by", token.ID)
+		if res.Error != nil {
+			return fmt.Errorf("failed to supersede timestamp token: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("timestamp token %d is no longer active", oldID)
+		}
+		token.Supersedes = &oldID
+		if err := tx.Create(token).Error; err != nil {
+			return fmt.Errorf("failed to store timestamp token: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *timestampTokenRepository) GetActive(ctx context.Context, logID string) (*domain.TimestampToken, error) {
+	var token domain.TimestampToken
+	err := r.db.WithContext(ctx).
+		Where("log_id = ? AND superseded_by IS NULL", logID).
+		Order("id DESC").
+		First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timestamp token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *timestampTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.TimestampToken, error) {
+	var token domain.TimestampToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timestamp token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *timestampTokenRepository) ListByLogID(ctx context.Context, logID string) ([]*domain.TimestampToken, error) {
+	var tokens []*domain.TimestampToken
+	err := r.db.WithContext(ctx).
+		Where("log_id = ?", logID).
+		Order("id ASC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timestamp tokens: %w", err)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTimestampTokenCreateReportsActiveLog(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewTimestampTokenRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "timestamp_tokens"`).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_timestamp_tokens_active"})
+	mock.ExpectRollback()
+	err := repo.Create(context.Background(), &domain.TimestampToken{LogID: "task-1"})
+	if !errors.Is(err, domain.ErrTimestampLogActive) {
+		t.Errorf("err = %v, want ErrTimestampLogActive", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The old token is retired before the new one is inserted, so the partial
+// unique index never sees two active tokens.
+func TestTimestampTokenSupersedeOrder(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	repo := NewTimestampTokenRepository(gormDB)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval\(pg_get_serial_sequence\('timestamp_tokens', 'id'\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(7))
+	mock.ExpectExec(`UPDATE "timestamp_tokens" SET "superseded_by"=\$1 WHERE id = \$2 AND superseded_by IS NULL`).
+		WithArgs(7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "timestamp_tokens" .* RETURNING "id"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
+	token := &domain.TimestampToken{LogID: "task-1"}
+	if err := repo.Supersede(ctx, 3, token); err != nil {
+		t.Fatal(err)
+	}
+	if token.ID != 7 || token.Supersedes == nil || *token.Supersedes != 3 {
+		t.Errorf("stored token %+v, want id 7 superseding 3", token)
+	}
+
+	// A token corrected concurrently is no longer active.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval`).WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(8))
+	mock.ExpectExec(`UPDATE "timestamp_tokens" SET "superseded_by"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if err := repo.Supersede(ctx, 3, &domain.TimestampToken{LogID: "task-1"}); err == nil {
+		t.Errorf("superseding a retired token should fail")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
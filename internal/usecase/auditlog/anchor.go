@@ -0,0 +1,293 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+)
+
+// confirmationTimeout bounds the wait for an anchor's transaction; an
+// unconfirmed anchor is picked up again on the next round.
+const confirmationTimeout = 90 * time.Second
+
+// Anchorer hash-chains each tenant's enterprise audit log period by period
+// and anchors every chain head on the ledger, so that entries edited or
+// removed after the fact no longer match what was anchored (see
+// blockchain.AuditAnchorCommitment for the spec). Each period goes to the
+// ledger profile the tenant has selected when the period is sealed.
+type Anchorer struct {
+	audits  domain.AuditRepository
+	anchors domain.AuditAnchorRepository
+	ledgers *blockchain.Ledgers
+	cfg     config.AuditAnchorConfig
+	now     func() time.Time
+}
+
+// NewAnchorer creates an anchorer. ledgers may be nil: Prove then only checks
+// the entries against the stored anchors.
+func NewAnchorer(audits domain.AuditRepository, anchors domain.AuditAnchorRepository, ledgers *blockchain.Ledgers, cfg config.AuditAnchorConfig) *Anchorer {
+	return &Anchorer{
+		audits:  audits,
+		anchors: anchors,
+		ledgers: ledgers,
+		cfg:     cfg,
+		now:     time.Now,
+	}
+}
+
+// Run anchors every closed period of every tenant that has not been anchored
+// yet, after retrying anchors left unconfirmed by an earlier round and
+// re-sealing those whose transaction reverted. A period is only sealed once
+// the previous anchor is confirmed, so no anchor chains from one that may
+// still be re-sealed. A failing tenant does not hold up the others.
+func (a *Anchorer) Run(ctx context.Context) error {
+	if a.ledgers == nil {
+		return blockchain.ErrNoLedger
+	}
+	tenants, err := a.anchors.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, tenant := range tenants {
+		if err := a.anchorTenant(ctx, tenant.ID, tenant.ParsedSettings().LedgerProfile); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *Anchorer) anchorTenant(ctx context.Context, tenantID uuid.UUID, profile string) error {
+	unconfirmed, err := a.anchors.ListUnconfirmed(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, anchor := range unconfirmed {
+		if anchor.Status == domain.AuditAnchorFailed {
+			if err := a.reseal(ctx, anchor); err != nil {
+				return err
+			}
+		}
+		if err := a.commit(ctx, anchor); err != nil {
+			return err
+		}
+		if anchor.Status != domain.AuditAnchorVerified {
+			return nil
+		}
+	}
+
+	// Periods are only anchored once SettleDelay has passed after their end.
+	cutoff := a.now().Add(-a.cfg.SettleDelay)
+	for {
+		latest, err := a.anchors.Latest(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		from, prevHead := time.Time{}, blockchain.AuditChainGenesis
+		if latest != nil {
+			from, prevHead = latest.PeriodEnd, latest.HeadHash
+		}
+
+		// Skip straight to the next period that has entries.
+		first, err := a.audits.FirstCreatedAt(ctx, tenantID, from, cutoff)
+		if err != nil {
+			return err
+		}
+		if first == nil {
+			return nil
+		}
+		start := first.UTC().Truncate(a.cfg.Period)
+		if start.Before(from) {
+			start = from
+		}
+		end := start.Add(a.cfg.Period)
+		if end.After(cutoff) {
+			return nil
+		}
+
+		ledger, err := a.ledgers.Get(profile)
+		if err != nil {
+			return err
+		}
+		anchor, err := a.anchors.Seal(ctx, tenantID, func() (*domain.AuditAnchor, error) {
+			anchor, err := a.seal(ctx, tenantID, start, end, prevHead)
+			if err != nil {
+				return nil, err
+			}
+			anchor.LedgerProfile = blockchain.NormalizeLedgerProfile(profile)
+			anchor.LedgerContract = ledger.ContractAddress()
+			return anchor, nil
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("[Audit-Anchor] Sealed %s for tenant %s: %d entries in [%s, %s), head %s",
+			anchor.ID, tenantID, anchor.EntryCount, start.Format(time.RFC3339), end.Format(time.RFC3339), anchor.HeadHash)
+
+		if err := a.commit(ctx, anchor); err != nil {
+			return err
+		}
+		if anchor.Status != domain.AuditAnchorVerified {
+			return nil
+		}
+	}
+}
+
+// seal builds the anchor of one period from the entries currently stored.
+func (a *Anchorer) seal(ctx context.Context, tenantID uuid.UUID, start, end time.Time, prevHead string) (*domain.AuditAnchor, error) {
+	entries, err := a.audits.ListForPeriod(ctx, tenantID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := entryHashes(entries)
+	if err != nil {
+		return nil, err
+	}
+	head, err := blockchain.HashChainHead(prevHead, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor := &domain.AuditAnchor{
+		ID:          uuid.NewString(),
+		TenantID:    tenantID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		EntryCount:  len(entries),
+		PrevHead:    blockchain.NormalizeHash(prevHead),
+		HeadHash:    head,
+		Status:      domain.AuditAnchorPendingCommit,
+	}
+	anchor.Commitment, err = commitmentOf(anchor)
+	if err != nil {
+		return nil, err
+	}
+	return anchor, nil
+}
+
+// reseal rebuilds an anchor whose transaction reverted from the entries of
+// its period, keeping its ID and predecessor, so the next commit submits it
+// again. Nothing chains from it yet and no entry can be added to its period,
+// so the head only differs if entries were changed before it was anchored.
+func (a *Anchorer) reseal(ctx context.Context, anchor *domain.AuditAnchor) error {
+	entries, err := a.audits.ListForPeriod(ctx, anchor.TenantID, anchor.PeriodStart, anchor.PeriodEnd)
+	if err != nil {
+		return err
+	}
+	hashes, err := entryHashes(entries)
+	if err != nil {
+		return err
+	}
+	head, err := blockchain.HashChainHead(anchor.PrevHead, hashes)
+	if err != nil {
+		return err
+	}
+	if head != anchor.HeadHash {
+		log.Printf("[Audit-Anchor] ⚠️ Entries of anchor %s changed before it was anchored; re-sealing with head %s", anchor.ID, head)
+	}
+
+	anchor.EntryCount = len(entries)
+	anchor.HeadHash = head
+	if anchor.Commitment, err = commitmentOf(anchor); err != nil {
+		return err
+	}
+	anchor.TxHash, anchor.Network = "", ""
+	anchor.Status = domain.AuditAnchorPendingCommit
+	return a.anchors.Update(ctx, anchor)
+}
+
+// commit writes the anchor's head with insertLog under the anchor ID on the
+// anchor's ledger and records the outcome. A timed-out confirmation is left
+// for the next round.
+func (a *Anchorer) commit(ctx context.Context, anchor *domain.AuditAnchor) error {
+	ledger, err := a.ledgers.Anchored(anchor.LedgerProfile, anchor.LedgerContract)
+	if err != nil {
+		return fmt.Errorf("no ledger for audit anchor %s: %w", anchor.ID, err)
+	}
+
+	// A previous attempt may already have submitted the transaction.
+	if anchor.TxHash == "" {
+		txHash, err := ledger.InsertLog(ctx, anchor.ID, anchor.HeadHash, anchor.Commitment)
+		if err != nil {
+			return fmt.Errorf("insertLog failed for audit anchor %s: %w", anchor.ID, err)
+		}
+		anchor.TxHash = txHash
+		anchor.Network = ledger.Network()
+		anchor.Status = domain.AuditAnchorPendingConfirmation
+		if err := a.anchors.Update(ctx, anchor); err != nil {
+			return err
+		}
+	}
+
+	receipt, err := ledger.WaitForConfirmation(ctx, anchor.TxHash, confirmationTimeout)
+	if err != nil {
+		log.Printf("[Audit-Anchor] ⚠️ Anchor %s not confirmed yet (tx %s), retrying next round", anchor.ID, anchor.TxHash)
+		return nil
+	}
+
+	anchor.TxHash = receipt.TxHash.Hex()
+	if receipt.Status != 1 {
+		log.Printf("[Audit-Anchor] ❌ Tx execution failed on-chain for anchor %s, re-sealing next round", anchor.ID)
+		anchor.Status = domain.AuditAnchorFailed
+		return a.anchors.Update(ctx, anchor)
+	}
+
+	log.Printf("[Audit-Anchor] ✅ Anchor %s confirmed in block %d", anchor.ID, receipt.BlockNumber)
+	now := a.now()
+	anchor.AnchoredAt = &now
+	anchor.Status = domain.AuditAnchorVerified
+	return a.anchors.Update(ctx, anchor)
+}
+
+// StartAnchorer runs the anchorer every cfg.Interval.
+func StartAnchorer(a *Anchorer, cfg config.AuditAnchorConfig) (gocron.Scheduler, error) {
+	s, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	_, err = s.NewJob(
+		gocron.DurationJob(cfg.Interval),
+		gocron.NewTask(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Interval)
+			defer cancel()
+
+			if err := a.Run(ctx); err != nil {
+				log.Printf("[Audit-Anchor] Anchoring round failed: %v", err)
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule audit anchorer: %w", err)
+	}
+
+	log.Printf("Audit log anchorer scheduled every %s (period %s, settle delay %s)", cfg.Interval, cfg.Period, cfg.SettleDelay)
+	s.Start()
+	return s, nil
+}
+
+func entryHashes(entries []*domain.AuditLog) ([]string, error) {
+	hashes := make([]string, len(entries))
+	for i, entry := range entries {
+		h, err := blockchain.AuditEntryHash(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash audit entry %s: %w", entry.ID, err)
+		}
+		hashes[i] = h
+	}
+	return hashes, nil
+}
+
+func commitmentOf(anchor *domain.AuditAnchor) (string, error) {
+	return blockchain.AuditAnchorCommitment(anchor.ID, anchor.TenantID.String(), anchor.PeriodStart, anchor.PeriodEnd, anchor.EntryCount, anchor.PrevHead, anchor.HeadHash)
+}
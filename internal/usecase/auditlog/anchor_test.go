@@ -0,0 +1,329 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+type memAuditRepo struct {
+	mu      sync.Mutex
+	entries []*domain.AuditLog
+}
+
+func (m *memAuditRepo) Create(ctx context.Context, audit *domain.AuditLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if audit.ID == uuid.Nil {
+		audit.ID = uuid.New()
+	}
+	m.entries = append(m.entries, audit)
+	return nil
+}
+
+func (m *memAuditRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.AuditLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.ID == id {
+			copied := *e
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memAuditRepo) ListForPeriod(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.AuditLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*domain.AuditLog
+	for _, e := range m.entries {
+		if e.TenantID == tenantID && !e.CreatedAt.Before(from) && e.CreatedAt.Before(to) {
+			copied := *e
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID.String() < out[j].ID.String()
+	})
+	return out, nil
+}
+
+func (m *memAuditRepo) FirstCreatedAt(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (*time.Time, error) {
+	entries, _ := m.ListForPeriod(ctx, tenantID, from, to)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0].CreatedAt, nil
+}
+
+type memAnchorRepo struct {
+	mu      sync.Mutex
+	tenants []*domain.Tenant
+	anchors []*domain.AuditAnchor
+}
+
+func (m *memAnchorRepo) Create(ctx context.Context, anchor *domain.AuditAnchor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *anchor
+	m.anchors = append(m.anchors, &copied)
+	return nil
+}
+
+func (m *memAnchorRepo) Seal(ctx context.Context, tenantID uuid.UUID, build func() (*domain.AuditAnchor, error)) (*domain.AuditAnchor, error) {
+	anchor, err := build()
+	if err != nil {
+		return nil, err
+	}
+	return anchor, m.Create(ctx, anchor)
+}
+
+func (m *memAnchorRepo) Update(ctx context.Context, anchor *domain.AuditAnchor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, a := range m.anchors {
+		if a.ID == anchor.ID {
+			copied := *anchor
+			m.anchors[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *memAnchorRepo) find(match func(a *domain.AuditAnchor) bool) []*domain.AuditAnchor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*domain.AuditAnchor
+	for _, a := range m.anchors {
+		if match(a) {
+			copied := *a
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PeriodStart.Before(out[j].PeriodStart) })
+	return out
+}
+
+func (m *memAnchorRepo) Latest(ctx context.Context, tenantID uuid.UUID) (*domain.AuditAnchor, error) {
+	all := m.find(func(a *domain.AuditAnchor) bool { return a.TenantID == tenantID })
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all[len(all)-1], nil
+}
+
+func (m *memAnchorRepo) GetCovering(ctx context.Context, tenantID uuid.UUID, at time.Time) (*domain.AuditAnchor, error) {
+	all := m.find(func(a *domain.AuditAnchor) bool {
+		return a.TenantID == tenantID && !a.PeriodStart.After(at) && a.PeriodEnd.After(at)
+	})
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all[0], nil
+}
+
+func (m *memAnchorRepo) ListUnconfirmed(ctx context.Context, tenantID uuid.UUID) ([]*domain.AuditAnchor, error) {
+	return m.find(func(a *domain.AuditAnchor) bool {
+		return a.TenantID == tenantID && a.Status != domain.AuditAnchorVerified
+	}), nil
+}
+
+func (m *memAnchorRepo) ListByTenant(ctx context.Context, tenantID uuid.UUID, limit int) ([]*domain.AuditAnchor, error) {
+	return m.find(func(a *domain.AuditAnchor) bool { return a.TenantID == tenantID }), nil
+}
+
+func (m *memAnchorRepo) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	return m.tenants, nil
+}
+
+func TestAnchorAndProveAuditLog(t *testing.T) {
+	ctx := context.Background()
+	tenant, other := uuid.New(), uuid.New()
+	day1 := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	audits := &memAuditRepo{}
+	add := func(tenantID uuid.UUID, at time.Time, action string) *domain.AuditLog {
+		e := &domain.AuditLog{
+			TenantID:     tenantID,
+			Action:       action,
+			ResourceType: "workflow_node",
+			ContextIP:    "10.0.0.1",
+			Evidence:     json.RawMessage(`{"status":"success"}`),
+			CreatedAt:    at,
+		}
+		_ = audits.Create(ctx, e)
+		return e
+	}
+	first := add(tenant, day1.Add(9*time.Hour), "NODE_EXECUTION")
+	add(tenant, day1.Add(10*time.Hour), "NODE_EXECUTION")
+	add(tenant, day1.Add(11*time.Hour), "NODE_EXECUTION")
+	add(tenant, day1.Add(2*24*time.Hour+time.Hour), "NODE_EXECUTION")
+	foreign := add(other, day1.Add(9*time.Hour), "NODE_EXECUTION")
+	recent := add(tenant, day1.Add(3*24*time.Hour+time.Hour), "NODE_EXECUTION")
+
+	// The tenant anchors to the "archive" profile, not the default ledger.
+	dir := t.TempDir()
+	ledgers, err := blockchain.NewLedgers(config.BlockchainConfig{
+		Backend:      config.LedgerBackendLocal,
+		LocalLogPath: filepath.Join(dir, "default.jsonl"),
+		Profiles: map[string]config.LedgerProfile{
+			"archive": {LocalLogPath: filepath.Join(dir, "archive.jsonl")},
+		},
+	}, blockchain.LedgerDeps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ledgers.Close()
+
+	anchors := &memAnchorRepo{tenants: []*domain.Tenant{
+		{ID: tenant, Settings: []byte(`{"ledger_profile":"archive"}`)},
+	}}
+	a := NewAnchorer(audits, anchors, ledgers, config.AuditAnchorConfig{Period: 24 * time.Hour, SettleDelay: 10 * time.Minute})
+	a.now = func() time.Time { return day1.Add(3*24*time.Hour + 2*time.Hour) }
+
+	if err := a.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if err := a.Run(ctx); err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	got, _ := anchors.ListByTenant(ctx, tenant, 10)
+	// Day 2 has no entries and day 4 is still open.
+	if len(got) != 2 {
+		t.Fatalf("got %d anchors, want 2: %+v", len(got), got)
+	}
+	if got[0].EntryCount != 3 || got[0].PrevHead != blockchain.AuditChainGenesis || got[0].Status != domain.AuditAnchorVerified {
+		t.Errorf("first anchor = %+v", got[0])
+	}
+	if got[1].PrevHead != got[0].HeadHash || !got[1].PeriodStart.Equal(day1.Add(48*time.Hour)) {
+		t.Errorf("second anchor should chain from the first: %+v", got[1])
+	}
+	if got[0].LedgerProfile != "archive" || got[0].LedgerContract != filepath.Join(dir, "archive.jsonl") {
+		t.Errorf("anchor should record the tenant's ledger profile: %+v", got[0])
+	}
+	defaultLedger, _ := ledgers.Get("")
+	if ok, _ := defaultLedger.VerifyHashes(ctx, got[0].ID, got[0].HeadHash, got[0].Commitment); ok {
+		t.Errorf("anchor should not be on the default ledger")
+	}
+
+	proof, err := a.Prove(ctx, tenant, first.ID)
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+	if !proof.Verified || proof.Index != 0 || len(proof.FollowingHashes) != 2 {
+		t.Errorf("proof = %+v", proof)
+	}
+	link, _ := blockchain.ChainLink(proof.PrecedingLink, proof.EntryHash)
+	if head, _ := blockchain.HashChainHead(link, proof.FollowingHashes); head != got[0].HeadHash {
+		t.Errorf("proof does not recompute the anchored head")
+	}
+
+	if _, err := a.Prove(ctx, tenant, foreign.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("another tenant's entry: err = %v", err)
+	}
+	if _, err := a.Prove(ctx, tenant, recent.ID); !errors.Is(err, ErrNotAnchored) {
+		t.Errorf("open period entry: err = %v", err)
+	}
+
+	// A DBA edits the entry after it was anchored.
+	audits.entries[0].Action = "NOTHING_TO_SEE"
+	proof, err = a.Prove(ctx, tenant, first.ID)
+	if err != nil {
+		t.Fatalf("Prove after edit: %v", err)
+	}
+	if proof.Included || proof.Verified || !proof.OnChain {
+		t.Errorf("edited entry should fail inclusion but the anchor stays on chain: %+v", proof)
+	}
+}
+
+// revertingLedger reverts the first reverts insertLogs: they return a
+// transaction whose receipt failed and store nothing.
+type revertingLedger struct {
+	blockchain.Ledger
+	reverts  int
+	reverted map[string]bool
+}
+
+func (l *revertingLedger) InsertLog(ctx context.Context, taskID, rationaleHash, consensusHash string) (string, error) {
+	if l.reverts == 0 {
+		return l.Ledger.InsertLog(ctx, taskID, rationaleHash, consensusHash)
+	}
+	l.reverts--
+	tx := common.BytesToHash([]byte(uuid.NewString())).Hex()
+	l.reverted[tx] = true
+	return tx, nil
+}
+
+func (l *revertingLedger) WaitForConfirmation(ctx context.Context, txHash string, timeout time.Duration) (*types.Receipt, error) {
+	if l.reverted[txHash] {
+		return &types.Receipt{Status: types.ReceiptStatusFailed, TxHash: common.HexToHash(txHash)}, nil
+	}
+	return l.Ledger.WaitForConfirmation(ctx, txHash, timeout)
+}
+
+func TestAnchorerResealsRevertedAnchor(t *testing.T) {
+	ctx := context.Background()
+	tenant := uuid.New()
+	day1 := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	audits := &memAuditRepo{}
+	for _, at := range []time.Time{day1.Add(9 * time.Hour), day1.Add(10 * time.Hour), day1.Add(2*24*time.Hour + time.Hour)} {
+		_ = audits.Create(ctx, &domain.AuditLog{TenantID: tenant, Action: "NODE_EXECUTION", Evidence: json.RawMessage(`{}`), CreatedAt: at})
+	}
+	local, err := blockchain.NewLocalLedger(filepath.Join(t.TempDir(), "audit.jsonl"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+	ledger := &revertingLedger{Ledger: local, reverts: 1, reverted: map[string]bool{}}
+
+	anchors := &memAnchorRepo{tenants: []*domain.Tenant{{ID: tenant}}}
+	a := NewAnchorer(audits, anchors, blockchain.SingleLedger(ledger), config.AuditAnchorConfig{Period: 24 * time.Hour, SettleDelay: 10 * time.Minute})
+	a.now = func() time.Time { return day1.Add(4 * 24 * time.Hour) }
+
+	// The first period reverts; the next one is not chained from it.
+	if err := a.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got, _ := anchors.ListByTenant(ctx, tenant, 10)
+	if len(got) != 1 || got[0].Status != domain.AuditAnchorFailed {
+		t.Fatalf("after a revert got %+v, want one FAILED anchor", got)
+	}
+	failed := got[0]
+
+	// An entry edited before anything was anchored is re-sealed as it is now.
+	audits.entries[0].Action = "EDITED"
+	if err := a.Run(ctx); err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	got, _ = anchors.ListByTenant(ctx, tenant, 10)
+	if len(got) != 2 {
+		t.Fatalf("got %d anchors, want 2: %+v", len(got), got)
+	}
+	if got[0].ID != failed.ID || got[0].Status != domain.AuditAnchorVerified || got[0].HeadHash == failed.HeadHash {
+		t.Errorf("failed anchor should be re-sealed and confirmed: %+v", got[0])
+	}
+	if got[1].PrevHead != got[0].HeadHash || got[1].Status != domain.AuditAnchorVerified {
+		t.Errorf("next anchor should chain from the re-sealed head: %+v", got[1])
+	}
+	proof, err := a.Prove(ctx, tenant, audits.entries[0].ID)
+	if err != nil || !proof.Verified {
+		t.Errorf("entry of the re-sealed period: %+v, %v", proof, err)
+	}
+}
@@ -0,0 +1,129 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrEntryNotFound is returned for an entry that does not exist or
+	// belongs to another tenant.
+	ErrEntryNotFound = errors.New("audit log entry not found")
+	// ErrNotAnchored is returned for an entry whose period has not been
+	// anchored yet.
+	ErrNotAnchored = errors.New("audit log entry is not anchored yet")
+)
+
+// Proof shows that an audit entry is included, unmodified, in an anchored
+// period. Anyone holding the entry can check it: folding EntryHash onto
+// PrecedingLink and then FollowingHashes with blockchain.ChainLink must give
+// the anchor's HeadHash, and the ledger must hold that head under the anchor
+// ID.
+type Proof struct {
+	EntryID         string              `json:"entry_id"`
+	EntryHash       string              `json:"entry_hash"`
+	Index           int                 `json:"index"`
+	PrecedingLink   string              `json:"preceding_link"`
+	FollowingHashes []string            `json:"following_hashes"`
+	Anchor          *domain.AuditAnchor `json:"anchor"`
+	RecomputedHead  string              `json:"recomputed_head"`
+	// Included reports that the period's entries, as stored now, still chain
+	// to the anchored head. Editing, inserting or deleting any entry of the
+	// period clears it.
+	Included bool `json:"included"`
+	// AnchorIntact reports that the anchor row still matches its commitment.
+	AnchorIntact bool `json:"anchor_intact"`
+	// OnChain reports that the ledger holds the anchor's head and commitment.
+	OnChain         bool   `json:"on_chain"`
+	Verified        bool   `json:"verified"`
+	Network         string `json:"network,omitempty"`
+	ContractAddress string `json:"contract_address,omitempty"`
+}
+
+// Prove builds the inclusion proof of one of the tenant's audit entries from
+// the entries stored now and checks it against the anchor and the ledger.
+func (a *Anchorer) Prove(ctx context.Context, tenantID, entryID uuid.UUID) (*Proof, error) {
+	entry, err := a.audits.GetByID(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.TenantID != tenantID {
+		return nil, ErrEntryNotFound
+	}
+
+	anchor, err := a.anchors.GetCovering(ctx, tenantID, entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if anchor == nil {
+		return nil, ErrNotAnchored
+	}
+
+	entries, err := a.audits.ListForPeriod(ctx, tenantID, anchor.PeriodStart, anchor.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := entryHashes(entries)
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	for i, e := range entries {
+		if e.ID == entry.ID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("audit entry %s missing from its own period", entryID)
+	}
+
+	preceding, err := blockchain.HashChainHead(anchor.PrevHead, hashes[:index])
+	if err != nil {
+		return nil, err
+	}
+	head, err := blockchain.HashChainHead(preceding, hashes[index:])
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := commitmentOf(anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &Proof{
+		EntryID:         entry.ID.String(),
+		EntryHash:       hashes[index],
+		Index:           index,
+		PrecedingLink:   preceding,
+		FollowingHashes: hashes[index+1:],
+		Anchor:          anchor,
+		RecomputedHead:  head,
+		Included:        len(entries) == anchor.EntryCount && blockchain.HashesEqual(head, anchor.HeadHash),
+		AnchorIntact:    blockchain.HashesEqual(commitment, anchor.Commitment),
+	}
+	if a.ledgers != nil {
+		ledger, err := a.ledgers.Anchored(anchor.LedgerProfile, anchor.LedgerContract)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify audit anchor on the ledger: %w", err)
+		}
+		proof.Network = ledger.Network()
+		proof.ContractAddress = ledger.ContractAddress()
+		proof.OnChain, err = ledger.VerifyHashes(ctx, anchor.ID, anchor.HeadHash, anchor.Commitment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify audit anchor on the ledger: %w", err)
+		}
+	}
+	proof.Verified = proof.Included && proof.AnchorIntact && proof.OnChain
+	return proof, nil
+}
+
+// ListAnchors returns the tenant's most recent anchors.
+func (a *Anchorer) ListAnchors(ctx context.Context, tenantID uuid.UUID, limit int) ([]*domain.AuditAnchor, error) {
+	return a.anchors.ListByTenant(ctx, tenantID, limit)
+}
@@ -0,0 +1,194 @@
+package document
+
+import (
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around changes.
+	diffContext = 3
+	// maxDiffEdits bounds the work of diffing unrelated texts: past it, the
+	// changed middle of the texts is reported as replaced wholesale.
+	maxDiffEdits = 2000
+)
+
+// diffText diffs two texts line by line.
+func diffText(from, to string) (hunks []domain.DiffHunk, added, removed int) {
+	lines := diffLines(splitLines(from), splitLines(to))
+	for _, l := range lines {
+		switch l.Op {
+		case domain.DiffInsert:
+			added++
+		case domain.DiffDelete:
+			removed++
+		}
+	}
+	return diffHunks(lines, diffContext), added, removed
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns the edit script turning a into b. The common prefix and
+// suffix are matched directly, the rest with Myers' O(ND) algorithm.
+func diffLines(a, b []string) []domain.DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	out := make([]domain.DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, l := range a[:prefix] {
+		out = append(out, domain.DiffLine{Op: domain.DiffEqual, Text: l})
+	}
+	out = append(out, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		out = append(out, domain.DiffLine{Op: domain.DiffEqual, Text: l})
+	}
+	return out
+}
+
+// myersDiff finds a shortest edit script with Myers' greedy algorithm,
+// keeping the furthest reaching x of every diagonal k = x - y after each
+// round d to walk the path back.
+func myersDiff(a, b []string) []domain.DiffLine {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit > maxDiffEdits {
+		limit = maxDiffEdits
+	}
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int // trace[d][k+d]: v on diagonal k before round d
+
+	for d := 0; d <= limit; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down: insert b[y]
+			} else {
+				x = v[offset+k-1] + 1 // right: delete a[x]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+func backtrack(a, b []string, trace [][]int) []domain.DiffLine {
+	var rev []domain.DiffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, domain.DiffLine{Op: domain.DiffEqual, Text: a[x]})
+		}
+		if x == prevX {
+			rev = append(rev, domain.DiffLine{Op: domain.DiffInsert, Text: b[prevY]})
+		} else {
+			rev = append(rev, domain.DiffLine{Op: domain.DiffDelete, Text: a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 {
+		x--
+		rev = append(rev, domain.DiffLine{Op: domain.DiffEqual, Text: a[x]})
+	}
+
+	out := make([]domain.DiffLine, len(rev))
+	for i, l := range rev {
+		out[len(rev)-1-i] = l
+	}
+	return out
+}
+
+func replaceAll(a, b []string) []domain.DiffLine {
+	out := make([]domain.DiffLine, 0, len(a)+len(b))
+	for _, l := range a {
+		out = append(out, domain.DiffLine{Op: domain.DiffDelete, Text: l})
+	}
+	for _, l := range b {
+		out = append(out, domain.DiffLine{Op: domain.DiffInsert, Text: l})
+	}
+	return out
+}
+
+// diffHunks groups the changes of lines into hunks with context unchanged
+// lines around them; changes at most 2*context lines apart share a hunk.
+func diffHunks(lines []domain.DiffLine, context int) []domain.DiffHunk {
+	// oldPos[i] and newPos[i] count the lines of each text before lines[i].
+	oldPos := make([]int, len(lines)+1)
+	newPos := make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if l.Op != domain.DiffInsert {
+			oldPos[i+1]++
+		}
+		if l.Op != domain.DiffDelete {
+			newPos[i+1]++
+		}
+	}
+
+	var hunks []domain.DiffHunk
+	for i := 0; i < len(lines); {
+		if lines[i].Op == domain.DiffEqual {
+			i++
+			continue
+		}
+		end := i + 1 // past the last change of the hunk
+		for j := end; j < len(lines) && j-end <= 2*context; j++ {
+			if lines[j].Op != domain.DiffEqual {
+				end = j + 1
+			}
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		stop := end + context
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+		hunks = append(hunks, domain.DiffHunk{
+			OldStart: oldPos[start] + 1,
+			OldLines: oldPos[stop] - oldPos[start],
+			NewStart: newPos[start] + 1,
+			NewLines: newPos[stop] - newPos[start],
+			Lines:    lines[start:stop],
+		})
+		i = stop
+	}
+	return hunks
+}
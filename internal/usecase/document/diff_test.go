@@ -0,0 +1,92 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// apply rebuilds both texts from an edit script.
+func apply(lines []domain.DiffLine) (from, to []string) {
+	for _, l := range lines {
+		if l.Op != domain.DiffInsert {
+			from = append(from, l.Text)
+		}
+		if l.Op != domain.DiffDelete {
+			to = append(to, l.Text)
+		}
+	}
+	return from, to
+}
+
+func TestDiffLines(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		edits int
+	}{
+		{"a b c a b b a", "c b a b a c", 5}, // the example of Myers' paper
+		{"", "x y", 2},
+		{"x y", "", 2},
+		{"p q r", "p q r", 0},
+		{"pagu 100 realisasi 90", "pagu 110 realisasi 90 sisa 20", 4},
+	}
+	for _, c := range cases {
+		a, b := strings.Fields(c.a), strings.Fields(c.b)
+		lines := diffLines(a, b)
+		from, to := apply(lines)
+		if strings.Join(from, " ") != c.a || strings.Join(to, " ") != c.b {
+			t.Errorf("%q -> %q: script rebuilds %q -> %q", c.a, c.b, from, to)
+		}
+		edits := 0
+		for _, l := range lines {
+			if l.Op != domain.DiffEqual {
+				edits++
+			}
+		}
+		if edits != c.edits {
+			t.Errorf("%q -> %q: %d edits, want %d", c.a, c.b, edits, c.edits)
+		}
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	var a, b []string
+	for i := 1; i <= 30; i++ {
+		line := fmt.Sprintf("Pos %d: %d", i, i*10)
+		a = append(a, line)
+		switch i {
+		case 5, 8:
+			line += "0" // two changes 2 lines apart: one hunk
+		case 25:
+			line = "Pos 25 dihapus"
+		}
+		b = append(b, line)
+	}
+
+	hunks := diffHunks(diffLines(a, b), 3)
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2: %+v", len(hunks), hunks)
+	}
+	h := hunks[0]
+	if h.OldStart != 2 || h.OldLines != 10 || h.NewStart != 2 || h.NewLines != 10 || len(h.Lines) != 12 {
+		t.Errorf("first hunk = %d,%d %d,%d with %d lines", h.OldStart, h.OldLines, h.NewStart, h.NewLines, len(h.Lines))
+	}
+	if h := hunks[1]; h.OldStart != 22 || h.OldLines != 7 || h.Lines[3].Op != domain.DiffDelete {
+		t.Errorf("second hunk = %+v", h)
+	}
+}
+
+func TestDiffTooManyEdits(t *testing.T) {
+	a := make([]string, maxDiffEdits)
+	b := make([]string, maxDiffEdits)
+	for i := range a {
+		a[i], b[i] = fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i)
+	}
+	lines := diffLines(a, b)
+	from, to := apply(lines)
+	if len(lines) != 2*maxDiffEdits || len(from) != len(a) || len(to) != len(b) {
+		t.Errorf("unrelated texts: %d lines", len(lines))
+	}
+}
@@ -2,7 +2,10 @@ package document
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
@@ -11,17 +14,23 @@ import (
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/rag"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
+// versionAttempts bounds the retries of a version upload that lost the race
+// for its version number.
+const versionAttempts = 3
+
 type documentUsecase struct {
 	repo        domain.DocumentRepository
 	s3          *storage.S3Service
 	mqClient    mq.TaskQueue
 	mongoClient *database.MongoClient
+	chunking    *rag.ChunkingPolicy
 }
 
-func NewDocumentUsecase(repo domain.DocumentRepository, s3 *storage.S3Service, mqClient mq.TaskQueue, mongoClient *database.MongoClient) domain.DocumentUsecase {
-	return &documentUsecase{repo: repo, s3: s3, mqClient: mqClient, mongoClient: mongoClient}
+func NewDocumentUsecase(repo domain.DocumentRepository, s3 *storage.S3Service, mqClient mq.TaskQueue, mongoClient *database.MongoClient, chunking *rag.ChunkingPolicy) domain.DocumentUsecase {
+	return &documentUsecase{repo: repo, s3: s3, mqClient: mqClient, mongoClient: mongoClient, chunking: chunking}
 }
 
 // GetUploadURL (Step 1: GET /presign)
@@ -42,16 +51,76 @@ func (u *documentUsecase) GetUploadURL(ctx context.Context, tenantID, userID uui
 
 // ConfirmUpload (Step 3: POST /confirm)
 // Creates the DB record and dispatches the parsing task to Asynq.
-func (u *documentUsecase) ConfirmUpload(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey string, category string) (*domain.Document, error) {
+func (u *documentUsecase) ConfirmUpload(ctx context.Context, tenantID, userID uuid.UUID, title, objectKey string, category, language string, metadata map[string]interface{}) (*domain.Document, error) {
+	if language != "" && !domain.ValidFTSLanguage(language) {
+		return nil, fmt.Errorf("unsupported language %q", language)
+	}
+	encoded, err := encodeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
 	doc := &domain.Document{
 		TenantID:  tenantID,
 		UserID:    userID,
 		Title:     title,
 		Category:  category,
+		Language:  language,
 		SourceURI: objectKey,
 		Status:    "pending",
+		Metadata:  encoded,
 	}
+	return u.ingest(ctx, doc)
+}
+
+// UploadVersion (POST /:id/versions, after PUT to S3)
+// Adds the uploaded file as the next version of the document's record. The
+// previous version stays searchable until the new one is approved and ready.
+// Concurrent uploads racing for the same version number are retried a few
+// times before giving up with domain.ErrDocumentVersionExists.
+func (u *documentUsecase) UploadVersion(ctx context.Context, tenantID, userID, docID uuid.UUID, title, objectKey string, metadata map[string]interface{}) (*domain.Document, error) {
+	var encoded datatypes.JSON
+	if metadata != nil {
+		var err error
+		if encoded, err = encodeMetadata(metadata); err != nil {
+			return nil, err
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		versions, err := u.versions(ctx, tenantID, docID)
+		if err != nil {
+			return nil, err
+		}
+		latest := versions[len(versions)-1]
+		versionTitle := title
+		if versionTitle == "" {
+			versionTitle = latest.Title
+		}
+		versionMetadata := encoded
+		if versionMetadata == nil {
+			versionMetadata = latest.Metadata
+		}
+		doc := &domain.Document{
+			TenantID:  tenantID,
+			UserID:    userID,
+			Title:     versionTitle,
+			Category:  latest.Category,
+			Language:  latest.Language,
+			SourceURI: objectKey,
+			Status:    "pending",
+			RecordID:  latest.RecordID,
+			Version:   latest.Version + 1,
+			Metadata:  versionMetadata,
+		}
+		created, err := u.ingest(ctx, doc)
+		if errors.Is(err, domain.ErrDocumentVersionExists) && attempt < versionAttempts {
+			continue
+		}
+		return created, err
+	}
+}
 
+// ingest persists a new document (or document version) and queues it for parsing.
+func (u *documentUsecase) ingest(ctx context.Context, doc *domain.Document) (*domain.Document, error) {
 	// 1. Persist the initial document record
 	if err := u.repo.Create(ctx, doc); err != nil {
 		return nil, fmt.Errorf("failed to create document record: %w", err)
@@ -60,8 +129,8 @@ func (u *documentUsecase) ConfirmUpload(ctx context.Context, tenantID, userID uu
 	// 2. Save raw staging record in MongoDB Staging with PENDING_QA status
 	stagingDoc := &database.StagingDocument{
 		ID:       doc.ID.String(),
-		TenantID: tenantID.String(),
-		FileName: title,
+		TenantID: doc.TenantID.String(),
+		FileName: doc.Title,
 		RawText:  "",
 		Status:   database.StatusPendingQA,
 	}
@@ -71,7 +140,7 @@ func (u *documentUsecase) ConfirmUpload(ctx context.Context, tenantID, userID uu
 	}
 
 	// 3. Enqueue parsing task (non-blocking)
-	task, err := rag.NewParseDocumentTask(doc.ID.String(), tenantID.String(), objectKey, category)
+	task, err := rag.NewParseDocumentTask(doc.ID.String(), doc.TenantID.String(), doc.SourceURI, doc.Category)
 	if err != nil {
 		// Mark as failed but return the document ID so frontend can retry
 		_ = u.repo.UpdateStatus(ctx, doc.ID, "queued_failed", nil)
@@ -147,6 +216,11 @@ func (u *documentUsecase) Delete(ctx context.Context, tenantID, docID uuid.UUID)
 	return u.repo.Delete(ctx, tenantID, docID)
 }
 
+// UpdateText replaces the staged text of a document. Text under QA is only
+// staged; editing a ready document also re-indexes it: the embedding worker
+// re-embeds the changed chunks only and bumps the document's revision. A
+// document whose indexing failed is re-indexed by the edit as well, so a
+// failed re-index is retried by saving the text again.
 func (u *documentUsecase) UpdateText(ctx context.Context, tenantID, docID uuid.UUID, text string) error {
 	doc, err := u.repo.FindByID(ctx, docID.String())
 	if err != nil {
@@ -155,11 +229,191 @@ func (u *documentUsecase) UpdateText(ctx context.Context, tenantID, docID uuid.U
 	if doc.TenantID != tenantID {
 		return fmt.Errorf("unauthorized: document does not belong to your tenant")
 	}
+	if doc.Status == "processing" {
+		return fmt.Errorf("cannot update text: %w", domain.ErrDocumentProcessing)
+	}
+	reindex := doc.Status == "ready" || doc.Status == "failed"
+
+	// Mark the document before touching the staged text: once the text
+	// changed, the index is stale until the re-index task runs. The
+	// transition is conditional, so a concurrent edit or the worker claiming
+	// the document in the meantime makes this edit fail instead.
+	if reindex {
+		if err := u.repo.StartProcessing(ctx, docID); err != nil {
+			return fmt.Errorf("cannot update text: %w", err)
+		}
+	}
 
 	// Update raw plain text in MongoDB Staging Area
 	if err := u.mongoClient.UpdateText(ctx, docID.String(), text); err != nil {
+		if reindex {
+			_ = u.repo.UpdateStatus(ctx, docID, doc.Status, nil) // rollback status
+		}
 		return fmt.Errorf("failed to update text in MongoDB staging: %w", err)
 	}
+	if !reindex {
+		return nil
+	}
 
+	// Re-index: the previous revision stays searchable until the worker
+	// swaps the chunks. Should the task not be queued, the document is
+	// left failed, since its index no longer matches the text.
+	task, err := rag.NewEmbedDocumentTask(docID.String(), tenantID.String(), doc.Category)
+	if err != nil {
+		_ = u.repo.UpdateStatus(ctx, docID, "failed", nil)
+		return fmt.Errorf("failed to create re-index task: %w", err)
+	}
+	if _, err := u.mqClient.EnqueueTask(task); err != nil {
+		_ = u.repo.UpdateStatus(ctx, docID, "failed", nil)
+		return fmt.Errorf("failed to enqueue re-index task: %w", err)
+	}
 	return nil
 }
+
+// UpdateMetadata replaces the user-defined metadata of a document version,
+// which the metadata search filter matches.
+func (u *documentUsecase) UpdateMetadata(ctx context.Context, tenantID, docID uuid.UUID, metadata map[string]interface{}) error {
+	doc, err := u.repo.FindByID(ctx, docID.String())
+	if err != nil {
+		return err
+	}
+	if doc.TenantID != tenantID {
+		return fmt.Errorf("unauthorized: document does not belong to your tenant")
+	}
+	encoded, err := encodeMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	return u.repo.UpdateMetadata(ctx, docID, encoded)
+}
+
+// encodeMetadata encodes user-defined document metadata; nil is stored as
+// an empty object.
+func encodeMetadata(metadata map[string]interface{}) (datatypes.JSON, error) {
+	if metadata == nil {
+		return datatypes.JSON("{}"), nil
+	}
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	return datatypes.JSON(b), nil
+}
+
+// ListVersions returns every version of the record of docID, oldest first.
+func (u *documentUsecase) ListVersions(ctx context.Context, tenantID, docID uuid.UUID) ([]*domain.Document, error) {
+	return u.versions(ctx, tenantID, docID)
+}
+
+// DiffVersions diffs the staged (reviewed) text of two versions of a record
+// line by line.
+func (u *documentUsecase) DiffVersions(ctx context.Context, tenantID, docID uuid.UUID, from, to int) (*domain.TextDiff, error) {
+	versions, err := u.versions(ctx, tenantID, docID)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 {
+		to = versions[len(versions)-1].Version
+	}
+	newer := findVersion(versions, to)
+	if newer == nil {
+		return nil, fmt.Errorf("version %d not found", to)
+	}
+	var older *domain.Document
+	if from == 0 {
+		// The version before to, whatever its number (versions can be deleted).
+		for _, v := range versions {
+			if v.Version < to {
+				older = v
+			}
+		}
+		if older == nil {
+			return nil, fmt.Errorf("version %d has no previous version to compare with", to)
+		}
+	} else if older = findVersion(versions, from); older == nil {
+		return nil, fmt.Errorf("version %d not found", from)
+	}
+
+	texts := make([]string, 2)
+	for i, v := range []*domain.Document{older, newer} {
+		staged, err := u.mongoClient.GetDocument(ctx, v.ID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve staged text of version %d: %w", v.Version, err)
+		}
+		texts[i] = staged.RawText
+	}
+
+	diff := &domain.TextDiff{
+		RecordID: newer.RecordID,
+		From:     domain.DiffVersion{DocumentID: older.ID, Version: older.Version, Title: older.Title, CreatedAt: older.CreatedAt},
+		To:       domain.DiffVersion{DocumentID: newer.ID, Version: newer.Version, Title: newer.Title, CreatedAt: newer.CreatedAt},
+	}
+	diff.Hunks, diff.Added, diff.Removed = diffText(texts[0], texts[1])
+	return diff, nil
+}
+
+// versions returns the versions of the record of docID after checking the
+// document belongs to the tenant.
+func (u *documentUsecase) versions(ctx context.Context, tenantID, docID uuid.UUID) ([]*domain.Document, error) {
+	doc, err := u.repo.FindByID(ctx, docID.String())
+	if err != nil {
+		return nil, fmt.Errorf("document not found: %w", err)
+	}
+	if doc.TenantID != tenantID {
+		return nil, fmt.Errorf("unauthorized: document does not belong to your tenant")
+	}
+	versions, err := u.repo.ListVersions(ctx, tenantID, doc.RecordID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return []*domain.Document{doc}, nil
+	}
+	return versions, nil
+}
+
+func findVersion(versions []*domain.Document, version int) *domain.Document {
+	for _, v := range versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// PreviewChunks shows how the embedding worker would chunk a document, or
+// a pasted text, without storing anything.
+func (u *documentUsecase) PreviewChunks(ctx context.Context, tenantID uuid.UUID, docID *uuid.UUID, text, category string, override domain.ChunkingOptions) (*domain.ChunkPreview, error) {
+	if docID != nil {
+		doc, err := u.repo.FindByID(ctx, docID.String())
+		if err != nil {
+			return nil, err
+		}
+		if doc.TenantID != tenantID {
+			return nil, fmt.Errorf("unauthorized: document does not belong to your tenant")
+		}
+		if category == "" {
+			category = doc.Category
+		}
+		staged, err := u.mongoClient.GetDocument(ctx, docID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve staged text: %w", err)
+		}
+		text = staged.RawText
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("nothing to chunk: the document has not been parsed yet or the text is empty")
+	}
+
+	settings, err := u.repo.TenantSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := rag.Preview(text, u.chunking.Options(settings, category).Merge(override))
+	if err != nil {
+		return nil, err
+	}
+	preview.DocumentID = docID
+	preview.Category = category
+	return preview, nil
+}
@@ -12,17 +12,21 @@ import (
 	documentUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/document"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"gorm.io/datatypes"
 )
 
 // MockDocumentRepository implements domain.DocumentRepository for unit tests
 type MockDocumentRepository struct {
-	CreateFunc       func(ctx context.Context, doc *domain.Document) error
-	UpdateStatusFunc func(ctx context.Context, id uuid.UUID, status string, metadata map[string]interface{}) error
-	StoreChunksFunc  func(ctx context.Context, chunks []domain.DocumentChunk) error
-	FindByTenantFunc func(ctx context.Context, tenantID string, limit, offset int) ([]*domain.Document, int64, error)
-	FindByIDFunc     func(ctx context.Context, id string) (*domain.Document, error)
-	DeleteFunc       func(ctx context.Context, tenantID, docID uuid.UUID) error
-	HybridSearchFunc func(ctx context.Context, params domain.HybridSearchParams) ([]domain.HybridSearchResult, error)
+	CreateFunc          func(ctx context.Context, doc *domain.Document) error
+	UpdateStatusFunc    func(ctx context.Context, id uuid.UUID, status string, metadata map[string]interface{}) error
+	UpdateMetadataFunc  func(ctx context.Context, id uuid.UUID, metadata datatypes.JSON) error
+	StartProcessingFunc func(ctx context.Context, id uuid.UUID) error
+	StoreChunksFunc     func(ctx context.Context, chunks []domain.DocumentChunk) error
+	FindByTenantFunc    func(ctx context.Context, tenantID string, limit, offset int) ([]*domain.Document, int64, error)
+	FindByIDFunc        func(ctx context.Context, id string) (*domain.Document, error)
+	DeleteFunc          func(ctx context.Context, tenantID, docID uuid.UUID) error
+	HybridSearchFunc    func(ctx context.Context, params domain.HybridSearchParams) ([]domain.HybridSearchResult, error)
+	ListVersionsFunc    func(ctx context.Context, tenantID, recordID uuid.UUID) ([]*domain.Document, error)
 }
 
 func (m *MockDocumentRepository) Create(ctx context.Context, doc *domain.Document) error {
@@ -46,6 +50,20 @@ func (m *MockDocumentRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
+func (m *MockDocumentRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, metadata datatypes.JSON) error {
+	if m.UpdateMetadataFunc != nil {
+		return m.UpdateMetadataFunc(ctx, id, metadata)
+	}
+	return nil
+}
+
+func (m *MockDocumentRepository) StartProcessing(ctx context.Context, id uuid.UUID) error {
+	if m.StartProcessingFunc != nil {
+		return m.StartProcessingFunc(ctx, id)
+	}
+	return m.UpdateStatus(ctx, id, "processing", nil)
+}
+
 func (m *MockDocumentRepository) StoreChunks(ctx context.Context, chunks []domain.DocumentChunk) error {
 	if m.StoreChunksFunc != nil {
 		return m.StoreChunksFunc(ctx, chunks)
@@ -53,6 +71,14 @@ func (m *MockDocumentRepository) StoreChunks(ctx context.Context, chunks []domai
 	return nil
 }
 
+func (m *MockDocumentRepository) ListChunks(ctx context.Context, documentID uuid.UUID) ([]domain.DocumentChunk, error) {
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) SyncChunks(ctx context.Context, sync domain.ChunkSync) error {
+	return nil
+}
+
 func (m *MockDocumentRepository) FindByTenant(ctx context.Context, tenantID string, limit, offset int) ([]*domain.Document, int64, error) {
 	if m.FindByTenantFunc != nil {
 		return m.FindByTenantFunc(ctx, tenantID, limit, offset)
@@ -74,6 +100,25 @@ func (m *MockDocumentRepository) HybridSearch(ctx context.Context, params domain
 	return nil, nil
 }
 
+func (m *MockDocumentRepository) EmbeddingModels(ctx context.Context, tenantID string) ([]domain.EmbeddingModelUsage, error) {
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) ListVersions(ctx context.Context, tenantID, recordID uuid.UUID) ([]*domain.Document, error) {
+	if m.ListVersionsFunc != nil {
+		return m.ListVersionsFunc(ctx, tenantID, recordID)
+	}
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) MarkReady(ctx context.Context, doc *domain.Document, metadata map[string]interface{}) error {
+	return m.UpdateStatus(ctx, doc.ID, "ready", metadata)
+}
+
+func (m *MockDocumentRepository) TenantSettings(ctx context.Context, tenantID uuid.UUID) (domain.TenantSettings, error) {
+	return domain.TenantSettings{}, nil
+}
+
 // MockTaskQueue implements mq.TaskQueue for unit tests
 type MockTaskQueue struct {
 	EnqueueTaskFunc func(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
@@ -141,7 +186,7 @@ func TestDocumentUseCase_Approve(t *testing.T) {
 			},
 		}
 
-		uc := documentUseCase.NewDocumentUsecase(repo, nil, mqClient, mongoClient)
+		uc := documentUseCase.NewDocumentUsecase(repo, nil, mqClient, mongoClient, nil)
 		err := uc.Approve(context.Background(), tenantID, docID)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -166,7 +211,7 @@ func TestDocumentUseCase_Approve(t *testing.T) {
 				return nil, errors.New("db record not found")
 			},
 		}
-		uc := documentUseCase.NewDocumentUsecase(repo, nil, nil, mongoClient)
+		uc := documentUseCase.NewDocumentUsecase(repo, nil, nil, mongoClient, nil)
 		err := uc.Approve(context.Background(), tenantID, docID)
 		if err == nil || !strings.Contains(err.Error(), "document not found") {
 			t.Fatalf("expected document not found error, got %v", err)
@@ -184,7 +229,7 @@ func TestDocumentUseCase_Approve(t *testing.T) {
 				}, nil
 			},
 		}
-		uc := documentUseCase.NewDocumentUsecase(repo, nil, nil, mongoClient)
+		uc := documentUseCase.NewDocumentUsecase(repo, nil, nil, mongoClient, nil)
 		err := uc.Approve(context.Background(), tenantID, docID)
 		if err == nil || !strings.Contains(err.Error(), "unauthorized") {
 			t.Fatalf("expected unauthorized error, got %v", err)
@@ -201,10 +246,301 @@ func TestDocumentUseCase_Approve(t *testing.T) {
 				}, nil
 			},
 		}
-		uc := documentUseCase.NewDocumentUsecase(repo, nil, nil, mongoClient)
+		uc := documentUseCase.NewDocumentUsecase(repo, nil, nil, mongoClient, nil)
 		err := uc.Approve(context.Background(), tenantID, docID)
 		if err == nil || !strings.Contains(err.Error(), "cannot approve document") {
 			t.Fatalf("expected cannot approve error, got %v", err)
 		}
 	})
 }
+
+func TestDocumentUseCase_UpdateText(t *testing.T) {
+	tenantID := uuid.New()
+
+	mongoClient, err := database.NewMongoClient(&config.Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize resilient mock mongo client: %v", err)
+	}
+
+	// run edits a document in status; statusErr and enqueueErr fail the
+	// status update and the re-index enqueue. It returns the statuses
+	// written, whether a re-index was queued and the staged text.
+	run := func(status string, statusErr, enqueueErr error) (statuses []string, enqueued bool, staged string, err error) {
+		docID := uuid.New()
+		_ = mongoClient.SaveDocument(context.Background(), &database.StagingDocument{
+			ID:       docID.String(),
+			TenantID: tenantID.String(),
+			RawText:  "teks lama",
+			Status:   database.StatusApproved,
+		})
+		repo := &MockDocumentRepository{
+			FindByIDFunc: func(ctx context.Context, id string) (*domain.Document, error) {
+				return &domain.Document{ID: docID, TenantID: tenantID, Status: status, Category: "pojk"}, nil
+			},
+			UpdateStatusFunc: func(ctx context.Context, id uuid.UUID, status string, metadata map[string]interface{}) error {
+				if statusErr != nil {
+					return statusErr
+				}
+				statuses = append(statuses, status)
+				return nil
+			},
+		}
+		mqClient := &MockTaskQueue{
+			EnqueueTaskFunc: func(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+				if enqueueErr != nil {
+					return nil, enqueueErr
+				}
+				enqueued = task.Type() == "rag:embed_document"
+				return nil, nil
+			},
+		}
+		uc := documentUseCase.NewDocumentUsecase(repo, nil, mqClient, mongoClient, nil)
+		err = uc.UpdateText(context.Background(), tenantID, docID, "teks baru")
+		doc, _ := mongoClient.GetDocument(context.Background(), docID.String())
+		return statuses, enqueued, doc.RawText, err
+	}
+
+	t.Run("Pending QA Only Stages Text", func(t *testing.T) {
+		statuses, enqueued, staged, err := run("pending_qa", nil, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if enqueued || len(statuses) != 0 {
+			t.Errorf("document under QA was re-indexed: statuses=%v enqueued=%v", statuses, enqueued)
+		}
+		if staged != "teks baru" {
+			t.Errorf("staged text = %q, want the edited text", staged)
+		}
+	})
+
+	for _, status := range []string{"ready", "failed"} {
+		t.Run("Re-indexes "+status+" Document", func(t *testing.T) {
+			statuses, enqueued, staged, err := run(status, nil, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !enqueued || len(statuses) != 1 || statuses[0] != "processing" {
+				t.Errorf("expected re-index task and processing status, got statuses=%v enqueued=%v", statuses, enqueued)
+			}
+			if staged != "teks baru" {
+				t.Errorf("staged text = %q, want the edited text", staged)
+			}
+		})
+	}
+
+	t.Run("Status Failure Keeps Staged Text", func(t *testing.T) {
+		_, enqueued, staged, err := run("ready", errors.New("db down"), nil)
+		if err == nil {
+			t.Fatal("expected the status error")
+		}
+		if enqueued || staged != "teks lama" {
+			t.Errorf("text changed without a re-index: staged=%q enqueued=%v", staged, enqueued)
+		}
+	})
+
+	t.Run("Enqueue Failure Marks Document Failed", func(t *testing.T) {
+		statuses, _, _, err := run("ready", nil, errors.New("redis down"))
+		if err == nil {
+			t.Fatal("expected the enqueue error")
+		}
+		if len(statuses) != 2 || statuses[1] != "failed" {
+			t.Errorf("statuses = %v, want processing then failed", statuses)
+		}
+	})
+
+	t.Run("Processing Document Is Rejected", func(t *testing.T) {
+		if _, _, _, err := run("processing", nil, nil); !errors.Is(err, domain.ErrDocumentProcessing) {
+			t.Fatalf("expected ErrDocumentProcessing, got %v", err)
+		}
+	})
+
+	// A concurrent edit claimed the document between the read and the
+	// conditional status update.
+	t.Run("Lost Processing Claim Keeps Staged Text", func(t *testing.T) {
+		_, enqueued, staged, err := run("ready", domain.ErrDocumentProcessing, nil)
+		if !errors.Is(err, domain.ErrDocumentProcessing) {
+			t.Fatalf("expected ErrDocumentProcessing, got %v", err)
+		}
+		if enqueued || staged != "teks lama" {
+			t.Errorf("losing edit changed the text: staged=%q enqueued=%v", staged, enqueued)
+		}
+	})
+}
+
+func TestDocumentUseCase_UpdateMetadata(t *testing.T) {
+	tenantID := uuid.New()
+	docID := uuid.New()
+
+	var stored datatypes.JSON
+	repo := &MockDocumentRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.Document, error) {
+			return &domain.Document{ID: docID, TenantID: tenantID}, nil
+		},
+		UpdateMetadataFunc: func(ctx context.Context, id uuid.UUID, metadata datatypes.JSON) error {
+			stored = metadata
+			return nil
+		},
+	}
+	uc := documentUseCase.NewDocumentUsecase(repo, nil, nil, nil, nil)
+
+	t.Run("Replaces Metadata", func(t *testing.T) {
+		if err := uc.UpdateMetadata(context.Background(), tenantID, docID, map[string]interface{}{"regulator": "OJK"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(stored) != `{"regulator":"OJK"}` {
+			t.Errorf("stored metadata = %s", stored)
+		}
+	})
+
+	t.Run("Invalid Metadata", func(t *testing.T) {
+		if err := uc.UpdateMetadata(context.Background(), tenantID, docID, map[string]interface{}{"x": func() {}}); err == nil {
+			t.Fatal("expected an error for metadata that is not JSON")
+		}
+	})
+
+	t.Run("Unauthorized Tenant Access", func(t *testing.T) {
+		err := uc.UpdateMetadata(context.Background(), uuid.New(), docID, map[string]interface{}{"regulator": "BI"})
+		if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+			t.Fatalf("expected unauthorized error, got %v", err)
+		}
+	})
+}
+
+func TestDocumentUseCase_Versions(t *testing.T) {
+	tenantID := uuid.New()
+	recordID := uuid.New()
+
+	mongoClient, err := database.NewMongoClient(&config.Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize resilient mock mongo client: %v", err)
+	}
+
+	texts := []string{
+		"# APBD 2025\n\nBelanja pegawai: 100\nBelanja modal: 50\nPendapatan: 200",
+		"# APBD 2025\n\nBelanja pegawai: 120\nBelanja modal: 50\nPendapatan: 200\nSILPA: 30",
+	}
+	var versions []*domain.Document
+	for i, text := range texts {
+		v := &domain.Document{ID: uuid.New(), TenantID: tenantID, RecordID: recordID, Version: i + 1, Title: "APBD 2025", Category: "APBD", Metadata: datatypes.JSON(`{"daerah":"Jawa Barat"}`)}
+		versions = append(versions, v)
+		_ = mongoClient.SaveDocument(context.Background(), &database.StagingDocument{
+			ID: v.ID.String(), TenantID: tenantID.String(), RawText: text, Status: database.StatusApproved,
+		})
+	}
+
+	var created *domain.Document
+	repo := &MockDocumentRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.Document, error) {
+			for _, v := range versions {
+				if v.ID.String() == id {
+					return v, nil
+				}
+			}
+			return nil, errors.New("not found")
+		},
+		ListVersionsFunc: func(ctx context.Context, tID, rID uuid.UUID) ([]*domain.Document, error) {
+			if rID != recordID {
+				t.Errorf("listed versions of record %s, want %s", rID, recordID)
+			}
+			return versions, nil
+		},
+		CreateFunc: func(ctx context.Context, doc *domain.Document) error {
+			doc.ID = uuid.New()
+			created = doc
+			return nil
+		},
+	}
+	uc := documentUseCase.NewDocumentUsecase(repo, nil, &MockTaskQueue{}, mongoClient, nil)
+
+	t.Run("Upload Version", func(t *testing.T) {
+		doc, err := uc.UploadVersion(context.Background(), tenantID, uuid.New(), versions[0].ID, "", "documents/apbd-perubahan.pdf", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if doc != created || doc.RecordID != recordID || doc.Version != 3 || doc.Title != "APBD 2025" || doc.Category != "APBD" {
+			t.Errorf("unexpected new version: %+v", doc)
+		}
+		if string(doc.Metadata) != `{"daerah":"Jawa Barat"}` {
+			t.Errorf("new version metadata = %s, want the record's", doc.Metadata)
+		}
+	})
+
+	t.Run("Upload Version With Metadata", func(t *testing.T) {
+		doc, err := uc.UploadVersion(context.Background(), tenantID, uuid.New(), versions[0].ID, "", "documents/apbd-perubahan.pdf", map[string]interface{}{"perubahan": true})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(doc.Metadata) != `{"perubahan":true}` {
+			t.Errorf("new version metadata = %s, want the uploaded metadata", doc.Metadata)
+		}
+	})
+
+	t.Run("Upload Version Retries A Taken Number", func(t *testing.T) {
+		// A concurrent upload stores version 3 between the listing and the insert.
+		listed := append([]*domain.Document{}, versions...)
+		var attempts []int
+		racing := &MockDocumentRepository{
+			FindByIDFunc: repo.FindByIDFunc,
+			ListVersionsFunc: func(ctx context.Context, tID, rID uuid.UUID) ([]*domain.Document, error) {
+				return listed, nil
+			},
+			CreateFunc: func(ctx context.Context, doc *domain.Document) error {
+				attempts = append(attempts, doc.Version)
+				if len(attempts) == 1 {
+					listed = append(listed, &domain.Document{ID: uuid.New(), TenantID: tenantID, RecordID: recordID, Version: doc.Version})
+					return domain.ErrDocumentVersionExists
+				}
+				return nil
+			},
+		}
+		uc := documentUseCase.NewDocumentUsecase(racing, nil, &MockTaskQueue{}, mongoClient, nil)
+		doc, err := uc.UploadVersion(context.Background(), tenantID, uuid.New(), versions[0].ID, "", "documents/apbd-perubahan.pdf", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if doc.Version != 4 || len(attempts) != 2 || attempts[0] != 3 {
+			t.Errorf("stored version %d after attempts %v, want 4 after 3", doc.Version, attempts)
+		}
+	})
+
+	t.Run("Upload Version Conflict", func(t *testing.T) {
+		conflicting := &MockDocumentRepository{
+			FindByIDFunc:     repo.FindByIDFunc,
+			ListVersionsFunc: repo.ListVersionsFunc,
+			CreateFunc: func(ctx context.Context, doc *domain.Document) error {
+				return domain.ErrDocumentVersionExists
+			},
+		}
+		uc := documentUseCase.NewDocumentUsecase(conflicting, nil, &MockTaskQueue{}, mongoClient, nil)
+		_, err := uc.UploadVersion(context.Background(), tenantID, uuid.New(), versions[0].ID, "", "documents/apbd-perubahan.pdf", nil)
+		if !errors.Is(err, domain.ErrDocumentVersionExists) {
+			t.Fatalf("expected ErrDocumentVersionExists, got %v", err)
+		}
+	})
+
+	t.Run("Diff Latest Against Previous", func(t *testing.T) {
+		diff, err := uc.DiffVersions(context.Background(), tenantID, versions[0].ID, 0, 0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if diff.From.Version != 1 || diff.To.Version != 2 || diff.Added != 2 || diff.Removed != 1 {
+			t.Errorf("unexpected diff: from %d to %d, +%d -%d", diff.From.Version, diff.To.Version, diff.Added, diff.Removed)
+		}
+		if len(diff.Hunks) != 1 || diff.Hunks[0].Lines[2].Text != "Belanja pegawai: 100" {
+			t.Errorf("unexpected hunks: %+v", diff.Hunks)
+		}
+	})
+
+	t.Run("Unknown Version", func(t *testing.T) {
+		if _, err := uc.DiffVersions(context.Background(), tenantID, versions[0].ID, 1, 7); err == nil {
+			t.Fatal("expected an error for a missing version")
+		}
+	})
+
+	t.Run("Unauthorized Tenant Access", func(t *testing.T) {
+		_, err := uc.ListVersions(context.Background(), uuid.New(), versions[0].ID)
+		if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+			t.Fatalf("expected unauthorized error, got %v", err)
+		}
+	})
+}
@@ -2,44 +2,149 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/pricing"
+)
+
+const (
+	GuardrailStatusSafe         = "SAFE"
+	GuardrailStatusFraudWarning = "FRAUD_WARNING"
+	// GuardrailStatusNoReference means prices were found but none of the
+	// items exist in the tenant's catalog, so nothing could be checked.
+	GuardrailStatusNoReference = "NO_REFERENCE"
 )
 
 // GuardrailResult represents the outcome of the Double-Pass Semantic Verification
 type GuardrailResult struct {
-	Status      string `json:"status"`       // "SAFE" or "FRAUD_WARNING"
-	AlertReason string `json:"alert_reason"` // Explanation of the anomaly
-	SourceQuote string `json:"source_quote"` // The actual RAG source quote to prove zero-hallucination
+	Status      string       `json:"status"`       // SAFE, FRAUD_WARNING or NO_REFERENCE
+	AlertReason string       `json:"alert_reason"` // Explanation of the anomaly
+	SourceQuote string       `json:"source_quote"` // The catalog entry the verdict is based on
+	Checks      []PriceCheck `json:"checks"`
+}
+
+// PriceCheck is the comparison of one extracted line item with its catalog entry.
+type PriceCheck struct {
+	Item         pricing.LineItem       `json:"item"`
+	Reference    *domain.ReferencePrice `json:"reference,omitempty"`
+	DeviationPct float64                `json:"deviation_pct"`
+	Exceeds      bool                   `json:"exceeds"`
+}
+
+// PriceCatalog is the subset of the reference price repository the verifier needs.
+type PriceCatalog interface {
+	Lookup(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, error)
 }
 
 // GuardrailVerifier handles the compliance and fraud checking logic
 type GuardrailVerifier struct {
-	// In a real scenario, instances of vectorDB and LLM clients are injected here
+	catalog PriceCatalog
 }
 
-func NewGuardrailVerifier() *GuardrailVerifier {
-	return &GuardrailVerifier{}
+func NewGuardrailVerifier(catalog PriceCatalog) *GuardrailVerifier {
+	return &GuardrailVerifier{catalog: catalog}
 }
 
 // Verify implements the Double-Pass Verification:
-// Pass 1: Extract numbers from the user text
-// Pass 2: Query Vector DB for rules, and ask LLM to compare
-func (g *GuardrailVerifier) Verify(ctx context.Context, text string) GuardrailResult {
-	textLower := strings.ToLower(text)
-
-	// Simulated Hackathon Context:
-	// If the text mentions laptop procurement with an excessive budget, trigger the FDS Guardrail.
-	if strings.Contains(textLower, "25.000.000") || strings.Contains(textLower, "25 juta") {
-		return GuardrailResult{
-			Status:      "FRAUD_WARNING",
-			AlertReason: "Terindikasi Mark-up Anggaran. Harga yang diajukan melebihi batas regulasi daerah.",
-			SourceQuote: `"Standar Harga Regional Perangkat IT Pemda (Dokumen SHSR Bab 2): Batas maksimal pengadaan unit Laptop/PC adalah Rp15.000.000 per perangkat."`,
+// Pass 1: Extract item, quantity and price from the text
+// Pass 2: Look each item up in the tenant's reference price catalog and compare
+//
+// region and fiscalYear scope the catalog lookup; empty values fall back to
+// region-less entries and the most recent fiscal year in effect.
+func (g *GuardrailVerifier) Verify(ctx context.Context, tenantID, region string, fiscalYear int, text string) GuardrailResult {
+	items := pricing.ExtractLineItems(text)
+	if len(items) == 0 {
+		return GuardrailResult{Status: GuardrailStatusSafe, Checks: []PriceCheck{}}
+	}
+
+	result := GuardrailResult{Status: GuardrailStatusSafe, Checks: make([]PriceCheck, 0, len(items))}
+	var worst *PriceCheck
+	matched := 0
+
+	for _, item := range items {
+		check := PriceCheck{Item: item}
+
+		if item.Description != "" && g.catalog != nil {
+			refs, err := g.catalog.Lookup(ctx, domain.ReferencePriceQuery{
+				TenantID:   tenantID,
+				Text:       item.Description,
+				Region:     region,
+				FiscalYear: fiscalYear,
+				Limit:      1,
+			})
+			if err == nil && len(refs) > 0 {
+				ref := refs[0]
+				matched++
+				check.Reference = ref
+				check.DeviationPct = (item.UnitPrice - ref.MaxPrice) / ref.MaxPrice * 100
+				check.Exceeds = item.UnitPrice > ref.MaxPrice
+			}
+		}
+
+		result.Checks = append(result.Checks, check)
+		if check.Exceeds && (worst == nil || check.DeviationPct > worst.DeviationPct) {
+			worst = &result.Checks[len(result.Checks)-1]
 		}
 	}
 
-	return GuardrailResult{
-		Status:      "SAFE",
-		AlertReason: "",
-		SourceQuote: "",
+	switch {
+	case worst != nil:
+		result.Status = GuardrailStatusFraudWarning
+		result.AlertReason = fmt.Sprintf(
+			"Terindikasi Mark-up Anggaran. Harga satuan %s untuk %q melebihi batas standar harga %s (+%.2f%%).",
+			formatRupiah(worst.Item.UnitPrice), worst.Item.Description, formatRupiah(worst.Reference.MaxPrice), worst.DeviationPct,
+		)
+		result.SourceQuote = quoteReference(worst.Reference)
+	case matched == 0:
+		result.Status = GuardrailStatusNoReference
+		result.AlertReason = "Tidak ada item yang ditemukan dalam katalog standar harga; harga tidak dapat diverifikasi."
+	default:
+		// Quote the first catalog entry used, so a SAFE verdict is sourced too.
+		for _, c := range result.Checks {
+			if c.Reference != nil {
+				result.SourceQuote = quoteReference(c.Reference)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func quoteReference(ref *domain.ReferencePrice) string {
+	scope := fmt.Sprintf("TA %d", ref.FiscalYear)
+	if ref.Region != "" {
+		scope = ref.Region + ", " + scope
+	}
+	source := ref.SourceRegulation
+	if source == "" {
+		source = "Standar Harga Satuan"
+	}
+	unit := ref.Unit
+	if unit == "" {
+		unit = "satuan"
+	}
+	return fmt.Sprintf("%q", fmt.Sprintf("%s (%s), kode %s: %s — batas maksimal %s per %s, berlaku sejak %s.",
+		source, scope, ref.ItemCode, ref.Description, formatRupiah(ref.MaxPrice), unit, ref.EffectiveDate.Format("02-01-2006")))
+}
+
+// formatRupiah renders 15000000 as "Rp15.000.000".
+func formatRupiah(v float64) string {
+	whole := fmt.Sprintf("%.0f", v)
+	neg := strings.HasPrefix(whole, "-")
+	whole = strings.TrimPrefix(whole, "-")
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	if neg {
+		return "-Rp" + b.String()
 	}
+	return "Rp" + b.String()
 }
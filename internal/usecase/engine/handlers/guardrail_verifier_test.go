@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine/handlers"
+)
+
+type MockPriceCatalog struct {
+	LookupFunc func(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, error)
+}
+
+func (m *MockPriceCatalog) Lookup(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, error) {
+	return m.LookupFunc(ctx, q)
+}
+
+func laptopCatalog(t *testing.T) *MockPriceCatalog {
+	return &MockPriceCatalog{
+		LookupFunc: func(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, error) {
+			if q.TenantID != "tenant-1" {
+				t.Errorf("expected tenant-1, got %s", q.TenantID)
+			}
+			if !strings.Contains(strings.ToLower(q.Text), "laptop") {
+				return nil, nil
+			}
+			return []*domain.ReferencePrice{{
+				ItemCode:         "1.3.2.10.01.02",
+				Description:      "Laptop/Notebook Core i7",
+				Unit:             "unit",
+				Region:           "Kota Bandung",
+				MaxPrice:         15000000,
+				FiscalYear:       2026,
+				EffectiveDate:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				SourceRegulation: "Perwal No. 12 Tahun 2025",
+			}}, nil
+		},
+	}
+}
+
+func TestGuardrailVerifier_FlagsMarkupWithCatalogQuote(t *testing.T) {
+	g := handlers.NewGuardrailVerifier(laptopCatalog(t))
+
+	res := g.Verify(context.Background(), "tenant-1", "Kota Bandung", 2026, "Pengadaan 10 unit laptop seharga Rp 25.000.000 per unit")
+
+	if res.Status != handlers.GuardrailStatusFraudWarning {
+		t.Fatalf("expected FRAUD_WARNING, got %s", res.Status)
+	}
+	if !strings.Contains(res.SourceQuote, "Perwal No. 12 Tahun 2025") || !strings.Contains(res.SourceQuote, "Rp15.000.000") {
+		t.Errorf("source quote does not cite the catalog entry: %s", res.SourceQuote)
+	}
+	if len(res.Checks) != 1 || res.Checks[0].DeviationPct < 66 || res.Checks[0].DeviationPct > 67 {
+		t.Errorf("unexpected checks: %+v", res.Checks)
+	}
+}
+
+func TestGuardrailVerifier_SafeWithinCatalogPrice(t *testing.T) {
+	g := handlers.NewGuardrailVerifier(laptopCatalog(t))
+
+	res := g.Verify(context.Background(), "tenant-1", "", 0, "Laptop 2 unit @ Rp 14.500.000")
+
+	if res.Status != handlers.GuardrailStatusSafe {
+		t.Fatalf("expected SAFE, got %s (%s)", res.Status, res.AlertReason)
+	}
+	if res.SourceQuote == "" {
+		t.Error("expected SAFE verdict to quote the catalog entry it was checked against")
+	}
+}
+
+func TestGuardrailVerifier_NoReference(t *testing.T) {
+	g := handlers.NewGuardrailVerifier(laptopCatalog(t))
+
+	res := g.Verify(context.Background(), "tenant-1", "", 0, "Sewa gedung Rp 40 juta")
+
+	if res.Status != handlers.GuardrailStatusNoReference {
+		t.Fatalf("expected NO_REFERENCE, got %s", res.Status)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -9,34 +10,35 @@ import (
 	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/telemetry"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine"
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/rag"
 )
 
 type RAGRetrieverHandler struct {
-	docRepo    domain.DocumentRepository
-	gemini     *genai.Client
-	embedModel *genai.EmbeddingModel
+	docRepo   domain.DocumentRepository
+	embedders ai.TenantEmbedders
+	reranker  ai.Reranker
+	expander  ai.QueryExpander
 }
 
-func NewRAGRetrieverHandler(docRepo domain.DocumentRepository, apiKey string) (*RAGRetrieverHandler, error) {
-	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, err
-	}
-	em := client.EmbeddingModel("text-embedding-004")
-
+// NewRAGRetrieverHandler builds the rag_retriever node. Without embedders
+// (or a reranker or query expander, for nodes with "rerank" or
+// "expand_query" set) the node fails when executed, not when the workflow
+// is set up.
+func NewRAGRetrieverHandler(docRepo domain.DocumentRepository, embedders ai.TenantEmbedders, reranker ai.Reranker, expander ai.QueryExpander) *RAGRetrieverHandler {
 	return &RAGRetrieverHandler{
-		docRepo:    docRepo,
-		gemini:     client,
-		embedModel: em,
-	}, nil
+		docRepo:   docRepo,
+		embedders: embedders,
+		reranker:  reranker,
+		expander:  expander,
+	}
 }
 
 // Execute performs hybrid document retrieval based on input from previous nodes.
-// Results are filtered by RRF score and concatenated for downstream LLM nodes.
+// Results are filtered by RRF score and concatenated for downstream LLM nodes;
+// the citations of the kept chunks are set as <node_id>_citations.
 func (h *RAGRetrieverHandler) Execute(ctx *engine.ExecutionContext, node engine.Node) error {
 	// 1. Mandatory Security: Retrieve tenant_id bound to this execution context.
 	// This ensures a DAG process can NEVER accidentally search across tenants.
@@ -71,27 +73,61 @@ func (h *RAGRetrieverHandler) Execute(ctx *engine.ExecutionContext, node engine.
 	if ms, ok := node.Data["min_score"].(float64); ok {
 		minScore = ms
 	}
+	filter, err := searchFilter(node.Data["filter"])
+	if err != nil {
+		return fmt.Errorf("node %s: invalid filter: %w", node.ID, err)
+	}
+	// Optional rerank stage: the fused top rerank_candidates are rescored and
+	// the best top_k kept. min_score still applies to the fused RRF score.
+	rerank, _ := node.Data["rerank"].(bool)
+	if rerank && h.reranker == nil {
+		return fmt.Errorf("node %s: rerank requested but no reranker is configured", node.ID)
+	}
+	searchK := topK
+	if rerank {
+		candidates, _ := node.Data["rerank_candidates"].(float64)
+		searchK = rag.RerankCandidates(int(candidates), topK)
+	}
+	// Optional multi-query retrieval: reformulations are searched too and
+	// the result lists fused. The searched queries are published as
+	// <node_id>_queries.
+	expand, _ := node.Data["expand_query"].(bool)
+	if expand && h.expander == nil {
+		return fmt.Errorf("node %s: expand_query requested but no query expander is configured", node.ID)
+	}
 
 	log.Printf("[RAG Node:%s] Executing retrieval for Tenant %s. Query: %q", node.ID, tenantID, query)
 
-	// 3. Generate vector embedding for the search query using Gemini.
-	queryEmbedding, err := h.embedQuery(context.Background(), tenantID, query)
+	// 3. Queries are embedded with the model of the tenant's active embedding index.
+	if h.embedders == nil {
+		return fmt.Errorf("node %s: no embedding backend configured", node.ID)
+	}
+	embedder, err := h.embedders.QueryEmbedder(context.Background(), tenantID)
 	if err != nil {
-		return fmt.Errorf("node %s: failed to embed query: %w", node.ID, err)
+		return fmt.Errorf("node %s: no query embedder: %w", node.ID, err)
+	}
+	queries := []string{query}
+	if expand {
+		maxQueries, _ := node.Data["max_queries"].(float64)
+		var expandErr error
+		queries, expandErr = rag.ExpandQuery(context.Background(), h.expander, query, int(maxQueries))
+		if expandErr != nil {
+			log.Printf("[RAG Node:%s] Query expansion failed, searching the query alone: %v", node.ID, expandErr)
+		}
 	}
+	ctx.Set(fmt.Sprintf("%s_queries", node.ID), queries)
 
-	// 4. Execute Hybrid Search (HNSW + FTS + RRF) through the postgres repository.
+	// 4. Execute Hybrid Search (HNSW + FTS + RRF) through the postgres repository, once per query.
 	searchStart := time.Now()
 	params := domain.HybridSearchParams{
-		TenantID:       tenantID,
-		QueryText:      query,
-		QueryEmbedding: queryEmbedding,
-		TopK:           topK,
-		EfSearch:       150, // Higher ef_search for workflow agents to prioritize accuracy over extreme latency
-		RRFConstant:    60,
+		TenantID:    tenantID,
+		TopK:        searchK,
+		EfSearch:    150, // Higher ef_search for workflow agents to prioritize accuracy over extreme latency
+		RRFConstant: 60,
+		Filter:      filter,
 	}
 
-	results, err := h.docRepo.HybridSearch(context.Background(), params)
+	results, err := rag.MultiSearch(context.Background(), h.docRepo, embedder, params, queries)
 
 	// Record RAG Latency Metric
 	telemetry.RagLatency.WithLabelValues(tenantID).Observe(time.Since(searchStart).Seconds())
@@ -99,18 +135,27 @@ func (h *RAGRetrieverHandler) Execute(ctx *engine.ExecutionContext, node engine.
 	if err != nil {
 		return fmt.Errorf("node %s: HybridSearch failed: %w", node.ID, err)
 	}
+	if rerank {
+		var rerankErr error
+		results, rerankErr = rag.Rerank(context.Background(), h.reranker, query, results, topK)
+		if rerankErr != nil {
+			log.Printf("[RAG Node:%s] Rerank failed, keeping fused order: %v", node.ID, rerankErr)
+		}
+	}
 
 	// 5. Filter by minimum RRF score and build context string
 	var contextBuilder strings.Builder
 	contextBuilder.WriteString("Relevent Knowledge Base Context:\n\n")
 
 	validChunks := 0
+	citations := []domain.Citation{}
 	for _, res := range results {
 		if res.RRFScore < minScore {
 			continue // Discard low-relevance matches
 		}
 		validChunks++
-		contextBuilder.WriteString(fmt.Sprintf("---\nDocument: %s\n%s\n", res.DocumentTitle, res.Content))
+		citations = append(citations, res.Citation)
+		contextBuilder.WriteString(fmt.Sprintf("---\nDocument: %s\n%s\n", rag.SourceLabel(res), res.Content))
 	}
 
 	finalContext := ""
@@ -122,16 +167,28 @@ func (h *RAGRetrieverHandler) Execute(ctx *engine.ExecutionContext, node engine.
 
 	// 6. Inject the formatted context back into the ExecutionContext for LLM consumption
 	ctx.Set(fmt.Sprintf("%s_result", node.ID), finalContext)
+	ctx.Set(fmt.Sprintf("%s_citations", node.ID), citations)
 	log.Printf("[RAG Node:%s] Complete. Kept %d/%d chunks (min_score=%.3f)", node.ID, validChunks, len(results), minScore)
 
 	return nil
 }
 
-func (h *RAGRetrieverHandler) embedQuery(ctx context.Context, tenantID string, query string) ([]float32, error) {
-	resp, err := h.embedModel.EmbedContent(ctx, genai.Text(query))
+// searchFilter decodes the optional "filter" node property, which has the
+// shape of domain.SearchFilter: {"category": "POJK", "document_ids": [...],
+// "uploaded_after": "2024-01-01T00:00:00Z",
+// "uploaded_before": "2025-01-01T00:00:00Z", "statuses": [...],
+// "metadata": {...}, "record_id": "...", "version": 2, "all_versions": false}.
+func searchFilter(raw interface{}) (domain.SearchFilter, error) {
+	var filter domain.SearchFilter
+	if raw == nil {
+		return filter, nil
+	}
+	b, err := json.Marshal(raw)
 	if err != nil {
-		return nil, err
+		return filter, err
 	}
-
-	return resp.Embedding.Values, nil
+	if err := json.Unmarshal(b, &filter); err != nil {
+		return filter, err
+	}
+	return filter, nil
 }
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine/handlers"
+	"github.com/google/uuid"
+)
+
+type MockSearchRepository struct {
+	domain.DocumentRepository
+	Params domain.HybridSearchParams
+}
+
+func (m *MockSearchRepository) HybridSearch(ctx context.Context, params domain.HybridSearchParams) ([]domain.HybridSearchResult, error) {
+	m.Params = params
+	return []domain.HybridSearchResult{{DocumentTitle: "POJK 11/2022", Content: "Modal inti minimum", RRFScore: 0.03}}, nil
+}
+
+type staticEmbedders struct{ ai.Embedder }
+
+func (s staticEmbedders) QueryEmbedder(ctx context.Context, tenantID string) (ai.Embedder, error) {
+	return s.Embedder, nil
+}
+
+func TestRAGRetrieverAppliesFilter(t *testing.T) {
+	repo := &MockSearchRepository{}
+	handler := handlers.NewRAGRetrieverHandler(repo, staticEmbedders{ai.NewHashEmbedder("", 8)}, nil, nil)
+	docID := uuid.New()
+
+	ctx := engine.NewExecutionContext()
+	ctx.Set("tenant_id", "tenant-1")
+	ctx.Set("global_input", "modal inti bank")
+	node := engine.Node{ID: "rag", Data: map[string]interface{}{
+		"filter": map[string]interface{}{
+			"category":        "POJK",
+			"document_ids":    []interface{}{docID.String()},
+			"uploaded_after":  "2024-01-01T00:00:00Z",
+			"uploaded_before": "2025-01-01T00:00:00Z",
+			"statuses":        []interface{}{"ready"},
+			"metadata":        map[string]interface{}{"regulator": "OJK"},
+		},
+	}}
+	if err := handler.Execute(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+
+	f := repo.Params.Filter
+	if f.Category != "POJK" || len(f.DocumentIDs) != 1 || f.DocumentIDs[0] != docID {
+		t.Errorf("filter = %+v", f)
+	}
+	if f.UploadedAfter == nil || !f.UploadedAfter.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("uploaded_after = %v", f.UploadedAfter)
+	}
+	if f.UploadedBefore == nil || !f.UploadedBefore.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("uploaded_before = %v", f.UploadedBefore)
+	}
+	if len(f.Statuses) != 1 || f.Statuses[0] != "ready" || f.Metadata["regulator"] != "OJK" {
+		t.Errorf("filter = %+v", f)
+	}
+
+	node.Data["filter"] = map[string]interface{}{"document_ids": []interface{}{"not-a-uuid"}}
+	if err := handler.Execute(ctx, node); err == nil {
+		t.Error("invalid document id was accepted")
+	}
+}
@@ -0,0 +1,181 @@
+package pricing
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineItem is a procurement line extracted from free text.
+type LineItem struct {
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
+	UnitPrice   float64 `json:"unit_price"`
+	TotalPrice  float64 `json:"total_price"`
+	Source      string  `json:"source"`
+}
+
+var (
+	// "Rp 25.000.000", "Rp25 juta", "25 juta", "1,5 miliar", "IDR 15.000.000,00"
+	priceRe    = regexp.MustCompile(`(?i)(?:\b(?:rp|idr)\.?\s*)?(\d{1,3}(?:[.,]\d{3})+(?:,\d{1,2})?|\d+(?:[.,]\d+)?)\s*(miliar|milyar|juta|jt|ribu|rb)?\b`)
+	quantityRe = regexp.MustCompile(`(?i)\b(\d+(?:[.,]\d+)?)\s*(unit|buah|bh|paket|pkt|set|pcs|eksemplar|lembar|rim|box|kotak|orang|org|kegiatan|bulan|hari|liter|meter|m2|m3|kg)\b`)
+	perUnitRe  = regexp.MustCompile(`(?i)(per\s+(unit|buah|paket|set|pcs|orang)|/\s*(unit|buah|paket|set|pcs)|@|harga\s+satuan|per\s*-?\s*item)`)
+	totalRe    = regexp.MustCompile(`(?i)\b(total|jumlah|senilai|pagu|keseluruhan)\b`)
+	splitRe    = regexp.MustCompile(`[\n;]+|\.\s+`)
+	currencyRe = regexp.MustCompile(`(?i)\b(rp|idr)\.\s*`)
+	noiseRe    = regexp.MustCompile(`(?i)\b(rp|idr|miliar|milyar|juta|jt|ribu|rb|harga|satuan|per|total|jumlah|senilai|sebesar|pagu|pengadaan|belanja|untuk|dengan|sebanyak|anggaran|dianggarkan|seharga|masing-masing|@)\b`)
+)
+
+var magnitudes = map[string]float64{
+	"miliar": 1e9, "milyar": 1e9,
+	"juta": 1e6, "jt": 1e6,
+	"ribu": 1e3, "rb": 1e3,
+}
+
+// ParseRupiah parses an amount written the Indonesian way ("Rp 25.000.000",
+// "25 juta", "1,5 miliar") or the English way ("25,000,000.50").
+func ParseRupiah(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	multiplier := 1.0
+	for word, m := range magnitudes {
+		if strings.HasSuffix(s, word) {
+			multiplier = m
+			s = strings.TrimSpace(strings.TrimSuffix(s, word))
+			break
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	clean := strings.Trim(b.String(), ".,")
+	if clean == "" {
+		return 0, false
+	}
+
+	lastDot := strings.LastIndex(clean, ".")
+	lastComma := strings.LastIndex(clean, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Whichever separator comes last is the decimal separator.
+		if lastComma > lastDot {
+			clean = strings.ReplaceAll(clean, ".", "")
+			clean = strings.Replace(clean, ",", ".", 1)
+		} else {
+			clean = strings.ReplaceAll(clean, ",", "")
+		}
+	case lastComma >= 0:
+		clean = normalizeSeparator(clean, ",", multiplier > 1)
+	case lastDot >= 0:
+		clean = normalizeSeparator(clean, ".", multiplier > 1)
+	}
+
+	f, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f * multiplier, true
+}
+
+// normalizeSeparator treats sep as a thousands separator when it occurs more
+// than once or is followed by exactly three digits, unless a magnitude word
+// ("1,5 juta") makes it a decimal separator.
+func normalizeSeparator(s, sep string, hasMagnitude bool) string {
+	parts := strings.Split(s, sep)
+	if len(parts) > 2 || (len(parts[len(parts)-1]) == 3 && !hasMagnitude) {
+		return strings.ReplaceAll(s, sep, "")
+	}
+	return strings.Replace(s, sep, ".", 1)
+}
+
+// ExtractLineItems finds procurement lines in text: each sentence or line
+// that mentions a price yields one item, with its quantity and unit when
+// stated and the remaining words as the item description.
+func ExtractLineItems(text string) []LineItem {
+	var items []LineItem
+
+	// "Rp. 25.000" must not be split as a sentence end.
+	text = currencyRe.ReplaceAllString(text, "Rp ")
+	for _, segment := range splitRe.Split(text, -1) {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+
+		// Remove the quantity first so "10 unit" is not mistaken for a price.
+		quantity, unit := 1.0, ""
+		rest := segment
+		if m := quantityRe.FindStringSubmatchIndex(segment); m != nil {
+			if q, ok := ParseRupiah(segment[m[2]:m[3]]); ok && q > 0 {
+				quantity = q
+			}
+			unit = strings.ToLower(segment[m[4]:m[5]])
+			rest = segment[:m[0]] + " " + segment[m[1]:]
+		}
+
+		price, priceSpan := largestPrice(rest)
+		if price <= 0 {
+			continue
+		}
+
+		item := LineItem{
+			Quantity: quantity,
+			Unit:     unit,
+			Source:   segment,
+		}
+
+		// "total Rp 250 juta untuk 10 unit" states a total; "@ Rp 25 juta" or
+		// "per unit" states a unit price. Without a cue the price is taken as
+		// the unit price, which is how budget lines (RAB) are usually written.
+		if totalRe.MatchString(segment) && !perUnitRe.MatchString(segment) && quantity > 1 {
+			item.TotalPrice = price
+			item.UnitPrice = math.Round(price/quantity*100) / 100
+		} else {
+			item.UnitPrice = price
+			item.TotalPrice = price * quantity
+		}
+
+		desc := rest[:priceSpan[0]] + " " + rest[priceSpan[1]:]
+		desc = perUnitRe.ReplaceAllString(desc, " ")
+		desc = noiseRe.ReplaceAllString(desc, " ")
+		desc = strings.Trim(strings.Join(strings.Fields(desc), " "), " ,.:-")
+		item.Description = desc
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// largestPrice returns the biggest amount in s that looks like money: it has
+// a currency prefix, a magnitude word, or thousands separators.
+func largestPrice(s string) (float64, [2]int) {
+	best := 0.0
+	var span [2]int
+	for _, m := range priceRe.FindAllStringSubmatchIndex(s, -1) {
+		match := s[m[0]:m[1]]
+		number := s[m[2]:m[3]]
+		hasMagnitude := m[4] >= 0
+		lower := strings.ToLower(match)
+		hasCurrency := strings.HasPrefix(lower, "rp") || strings.HasPrefix(lower, "idr")
+		hasGrouping := len(number) > 4 && strings.ContainsAny(number, ".,") && !hasMagnitude
+
+		if !hasCurrency && !hasMagnitude && !hasGrouping {
+			continue
+		}
+		v, ok := ParseRupiah(match)
+		if !ok || v < 1000 {
+			continue
+		}
+		if v > best {
+			best = v
+			span = [2]int{m[0], m[1]}
+		}
+	}
+	return best, span
+}
@@ -0,0 +1,61 @@
+package pricing_test
+
+import (
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/pricing"
+)
+
+func TestParseRupiah(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"Rp 25.000.000", 25000000},
+		{"Rp25 juta", 25000000},
+		{"1,5 miliar", 1500000000},
+		{"15.000.000,00", 15000000},
+		{"25,000,000.50", 25000000.5},
+		{"2.500", 2500},
+		{"12,5", 12.5},
+		{"750 ribu", 750000},
+	}
+
+	for _, tt := range tests {
+		got, ok := pricing.ParseRupiah(tt.input)
+		if !ok || got != tt.want {
+			t.Errorf("ParseRupiah(%q) = %v, %v; want %v", tt.input, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := pricing.ParseRupiah("tidak ada"); ok {
+		t.Error("expected text without digits to fail")
+	}
+}
+
+func TestExtractLineItems(t *testing.T) {
+	text := "Pengadaan 10 unit Laptop Core i7 dengan harga Rp 25.000.000 per unit. " +
+		"Printer laser total Rp. 30 juta untuk 5 buah\n" +
+		"Rapat koordinasi dihadiri 12 orang"
+
+	items := pricing.ExtractLineItems(text)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d: %+v", len(items), items)
+	}
+
+	laptop := items[0]
+	if laptop.Quantity != 10 || laptop.Unit != "unit" {
+		t.Errorf("laptop quantity = %v %q, want 10 unit", laptop.Quantity, laptop.Unit)
+	}
+	if laptop.UnitPrice != 25000000 || laptop.TotalPrice != 250000000 {
+		t.Errorf("laptop prices = %v / %v, want 25000000 / 250000000", laptop.UnitPrice, laptop.TotalPrice)
+	}
+	if laptop.Description != "Laptop Core i7" {
+		t.Errorf("laptop description = %q, want %q", laptop.Description, "Laptop Core i7")
+	}
+
+	printer := items[1]
+	if printer.Quantity != 5 || printer.UnitPrice != 6000000 || printer.TotalPrice != 30000000 {
+		t.Errorf("printer = %+v, want 5 x 6000000 = 30000000", printer)
+	}
+}
@@ -0,0 +1,296 @@
+package pricing
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	// ErrInvalidImport wraps every error caused by the content of an import file.
+	ErrInvalidImport     = errors.New("invalid import file")
+	ErrUnsupportedFormat = fmt.Errorf("%w: unsupported file format, expected .csv or .xlsx", ErrInvalidImport)
+)
+
+// Column aliases accepted in import files (header row, case-insensitive).
+var columnAliases = map[string][]string{
+	"item_code":         {"item_code", "kode", "kode_barang", "code", "kode_rekening"},
+	"description":       {"description", "uraian", "nama_barang", "deskripsi", "item"},
+	"unit":              {"unit", "satuan"},
+	"region":            {"region", "wilayah", "daerah"},
+	"max_price":         {"max_price", "harga", "harga_satuan", "harga_maksimal", "price"},
+	"effective_date":    {"effective_date", "tanggal_berlaku", "berlaku"},
+	"fiscal_year":       {"fiscal_year", "tahun_anggaran", "tahun", "ta"},
+	"source_regulation": {"source_regulation", "dasar_hukum", "regulasi", "sumber"},
+}
+
+// ImportOptions supply defaults for columns missing from the import file.
+type ImportOptions struct {
+	FiscalYear       int
+	Region           string
+	SourceRegulation string
+	EffectiveDate    time.Time
+	// Replace deletes the fiscal year (and region, when set) before importing.
+	Replace bool
+}
+
+type ImportResult struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`
+	Errors   []string `json:"errors"`
+}
+
+type ReferencePriceUsecase struct {
+	repo domain.ReferencePriceRepository
+}
+
+func NewReferencePriceUsecase(repo domain.ReferencePriceRepository) *ReferencePriceUsecase {
+	return &ReferencePriceUsecase{repo: repo}
+}
+
+// Import loads a CSV or XLSX catalog. Rows that cannot be parsed are skipped
+// and reported; valid rows are upserted in one pass.
+func (u *ReferencePriceUsecase) Import(ctx context.Context, tenantID, filename string, r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	var rows [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		rows, err = readCSV(r)
+	case ".xlsx":
+		rows, err = readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("%w: file has no data rows", ErrInvalidImport)
+	}
+
+	prices, result, err := mapRows(tenantID, rows, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	if opts.Replace {
+		if opts.FiscalYear == 0 {
+			return nil, fmt.Errorf("%w: replace requires a fiscal year", ErrInvalidImport)
+		}
+		if err := u.repo.DeleteScope(ctx, tenantID, opts.FiscalYear, opts.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.repo.Upsert(ctx, prices); err != nil {
+		return nil, err
+	}
+	result.Imported = len(prices)
+	return result, nil
+}
+
+func (u *ReferencePriceUsecase) List(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, int64, error) {
+	if q.Limit <= 0 || q.Limit > 500 {
+		q.Limit = 50
+	}
+	return u.repo.List(ctx, q)
+}
+
+func (u *ReferencePriceUsecase) Lookup(ctx context.Context, q domain.ReferencePriceQuery) ([]*domain.ReferencePrice, error) {
+	if strings.TrimSpace(q.Text) == "" && q.ItemCode == "" {
+		return nil, fmt.Errorf("q or item_code is required")
+	}
+	return u.repo.Lookup(ctx, q)
+}
+
+func (u *ReferencePriceUsecase) FiscalYears(ctx context.Context, tenantID string) ([]int, error) {
+	return u.repo.FiscalYears(ctx, tenantID)
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	// Spreadsheet exports in Indonesian locales commonly use ';'.
+	if firstLine, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv: %w", err)
+	}
+	return rows, nil
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open xlsx: %w", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("xlsx has no sheets")
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xlsx rows: %w", err)
+	}
+	return rows, nil
+}
+
+func mapRows(tenantID string, rows [][]string, opts ImportOptions) ([]*domain.ReferencePrice, *ImportResult, error) {
+	index := make(map[string]int)
+	for i, h := range rows[0] {
+		key := strings.ToLower(strings.TrimSpace(h))
+		key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+		for field, aliases := range columnAliases {
+			for _, alias := range aliases {
+				if key == alias {
+					if _, exists := index[field]; !exists {
+						index[field] = i
+					}
+				}
+			}
+		}
+	}
+	if _, ok := index["description"]; !ok {
+		return nil, nil, fmt.Errorf("missing description column (description/uraian)")
+	}
+	if _, ok := index["max_price"]; !ok {
+		return nil, nil, fmt.Errorf("missing price column (max_price/harga)")
+	}
+
+	cell := func(row []string, field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	result := &ImportResult{Errors: []string{}}
+	byKey := make(map[string]*domain.ReferencePrice)
+	var prices []*domain.ReferencePrice
+
+	for n, row := range rows[1:] {
+		line := n + 2
+		description := cell(row, "description")
+		if description == "" {
+			result.Skipped++
+			continue
+		}
+
+		price, ok := ParseRupiah(cell(row, "max_price"))
+		if !ok || price <= 0 {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: invalid price %q", line, cell(row, "max_price")))
+			continue
+		}
+
+		fiscalYear := opts.FiscalYear
+		if v := cell(row, "fiscal_year"); v != "" {
+			y, err := strconv.Atoi(v)
+			if err != nil {
+				result.Skipped++
+				result.Errors = append(result.Errors, fmt.Sprintf("row %d: invalid fiscal year %q", line, v))
+				continue
+			}
+			fiscalYear = y
+		}
+		if fiscalYear == 0 {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: fiscal year is required", line))
+			continue
+		}
+
+		effective := opts.EffectiveDate
+		if v := cell(row, "effective_date"); v != "" {
+			t, err := parseDate(v)
+			if err != nil {
+				result.Skipped++
+				result.Errors = append(result.Errors, fmt.Sprintf("row %d: invalid effective date %q", line, v))
+				continue
+			}
+			effective = t
+		}
+		if effective.IsZero() {
+			effective = time.Date(fiscalYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		region := firstNonEmpty(cell(row, "region"), opts.Region)
+		unit := cell(row, "unit")
+		code := cell(row, "item_code")
+		if code == "" {
+			code = syntheticItemCode(description, unit)
+		}
+
+		p := &domain.ReferencePrice{
+			TenantID:         tenantID,
+			ItemCode:         code,
+			Description:      description,
+			Unit:             unit,
+			Region:           region,
+			MaxPrice:         price,
+			EffectiveDate:    effective,
+			FiscalYear:       fiscalYear,
+			SourceRegulation: firstNonEmpty(cell(row, "source_regulation"), opts.SourceRegulation),
+		}
+
+		// A file may list the same item twice; the last row wins, as it would in the upsert.
+		key := fmt.Sprintf("%d|%s|%s", fiscalYear, region, code)
+		if existing, dup := byKey[key]; dup {
+			*existing = *p
+			result.Skipped++
+			continue
+		}
+		byKey[key] = p
+		prices = append(prices, p)
+	}
+
+	return prices, result, nil
+}
+
+func parseDate(v string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "02/01/2006", "02-01-2006", "2/1/2006", time.RFC3339} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", v)
+}
+
+func syntheticItemCode(description, unit string) string {
+	sum := sha1.Sum([]byte(strings.ToLower(description + "|" + unit)))
+	return "AUTO-" + strings.ToUpper(hex.EncodeToString(sum[:4]))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
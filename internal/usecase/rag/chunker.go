@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 )
 
 // MarkdownChunk is a single chunk produced by the Markdown-aware chunker.
@@ -18,55 +22,174 @@ type MarkdownChunk struct {
 	FullContent string
 	// Index is the sequential chunk number within the document
 	Index int
+	// Start and End are the byte offsets of Content's span in the chunked
+	// text (Content itself has its whitespace collapsed, and pieces of a
+	// split table repeat the header row).
+	Start, End int
+}
+
+// Chunker splits a document's Markdown text into chunks.
+type Chunker interface {
+	Chunk(text string) []MarkdownChunk
 }
 
+// Defaults of ChunkingOptions left zero.
+const (
+	DefaultChunkChars      = 1000 // markdown and sentence_window
+	DefaultChunkTokens     = 256  // token
+	DefaultSentenceOverlap = 1    // sentence_window
+)
+
 // headerRegex matches Markdown headers: # H1, ## H2, ### H3, etc.
 var headerRegex = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
 
 // tableRowRegex matches a Markdown table row (starts with |)
 var tableRowRegex = regexp.MustCompile(`^\s*\|`)
 
+// tableSeparatorRegex matches the row under a Markdown table header: | --- | :-: |
+var tableSeparatorRegex = regexp.MustCompile(`^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+
+// ChunkingDefaults fills the zero fields of opts with the defaults of its strategy.
+func ChunkingDefaults(opts domain.ChunkingOptions) domain.ChunkingOptions {
+	if opts.Strategy == "" {
+		opts.Strategy = domain.ChunkMarkdown
+	}
+	if opts.MaxSize == 0 {
+		opts.MaxSize = DefaultChunkChars
+		if opts.Strategy == domain.ChunkToken {
+			opts.MaxSize = DefaultChunkTokens
+		}
+	}
+	if opts.Overlap == 0 && opts.Strategy == domain.ChunkSentenceWindow {
+		opts.Overlap = DefaultSentenceOverlap
+	}
+	if opts.KeepTables == nil {
+		keep := false
+		opts.KeepTables = &keep
+	}
+	return opts
+}
+
+// NewChunker returns the chunker of opts.Strategy; zero options take the
+// strategy's defaults (see ChunkingDefaults).
+func NewChunker(opts domain.ChunkingOptions) (Chunker, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = ChunkingDefaults(opts)
+	c := &markdownChunker{
+		maxSize:    opts.MaxSize,
+		overlap:    opts.Overlap,
+		keepTables: *opts.KeepTables,
+		size:       charSizer,
+	}
+	switch opts.Strategy {
+	case domain.ChunkToken:
+		c.size = tokenSizer
+	case domain.ChunkSentenceWindow:
+		c.sentences = true
+	}
+	return c, nil
+}
+
 // MarkdownAwareChunker splits Markdown text into semantically coherent chunks.
 //
 // Rules:
 //  1. Primary split boundary = Markdown headers (# ## ###)
 //  2. Tables are NEVER split mid-row — an entire table is kept as one unit
-//     (if a single table exceeds maxChunkSize, it is emitted as one oversized chunk with a warning)
+//     (if a single table exceeds maxChunkSize, it is emitted as one oversized chunk)
 //  3. Non-table text within a section is word-boundary chunked at maxChunkSize
 //  4. Every chunk is prefixed with its header breadcrumb path for LLM context
 //
 // Parameters:
 //   - text: Full Markdown text output from Docling
 //   - maxChunkSize: Target character limit per chunk (default: 1000)
-//   - chunkOverlap: Characters of trailing overlap to carry into next chunk (default: 0 for Markdown)
+//
+// It is the markdown strategy without overlap that keeps tables whole; see
+// NewChunker for overlap and table splitting.
 func MarkdownAwareChunker(text string, maxChunkSize int) []MarkdownChunk {
 	if maxChunkSize <= 0 {
-		maxChunkSize = 1000
+		maxChunkSize = DefaultChunkChars
+	}
+	c := &markdownChunker{maxSize: maxChunkSize, keepTables: true, size: charSizer}
+	return c.Chunk(text)
+}
+
+// markdownChunker implements every strategy: blocks of a header section are
+// packed by words (markdown, token) or by sentences (sentence_window).
+type markdownChunker struct {
+	maxSize    int
+	overlap    int
+	keepTables bool
+	sentences  bool
+	size       sizer
+}
+
+func (c *markdownChunker) Chunk(text string) []MarkdownChunk {
+	var chunks []MarkdownChunk
+	for _, blk := range markdownBlocks(text) {
+		var pieces []textPiece
+		switch {
+		case blk.isTable && c.keepTables:
+			pieces = []textPiece{{content: blk.raw, end: len(blk.raw)}}
+		case blk.isTable:
+			pieces = splitTable(blk.raw, c.maxSize, c.size)
+		case c.sentences:
+			pieces = splitBySentence(blk.raw, c.maxSize, c.overlap, c.size)
+		default:
+			pieces = splitByWordBoundary(blk.raw, c.maxSize, c.overlap, c.size)
+		}
+		for _, p := range pieces {
+			chunk := buildChunk(blk.headerPath, p.content, len(chunks))
+			chunk.Start, chunk.End = blk.start+p.start, blk.start+p.end
+			chunks = append(chunks, chunk)
+		}
 	}
+	return chunks
+}
 
+// markdownBlock is a run of table or non-table lines of a header section.
+// raw is trimmed; start is its byte offset in the text.
+type markdownBlock struct {
+	headerPath string
+	isTable    bool
+	raw        string
+	start      int
+}
+
+// markdownBlocks parses the document into sections by header hierarchy and
+// splits each section into plain-text and table blocks.
+func markdownBlocks(text string) []markdownBlock {
 	lines := strings.Split(text, "\n")
+	lineStarts := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		lineStarts[i] = lineStarts[i-1] + len(lines[i-1]) + 1
+	}
 
 	// Phase 1: Parse the document into logical sections grouped by header hierarchy
 	type section struct {
 		headers      []string // breadcrumb: ["Doc Title", "Section 1", "Sub-section A"]
 		contentLines []string // raw content lines under this header
+		firstLine    int      // index in lines of contentLines[0]
 	}
 
 	var sections []section
 	currentHeaders := []string{}
 	currentContent := []string{}
+	currentFirst := 0
 
 	flushSection := func() {
 		if len(currentContent) > 0 {
 			sections = append(sections, section{
 				headers:      append([]string{}, currentHeaders...),
 				contentLines: append([]string{}, currentContent...),
+				firstLine:    currentFirst,
 			})
 			currentContent = nil
 		}
 	}
 
-	for _, line := range lines {
+	for n, line := range lines {
 		if m := headerRegex.FindStringSubmatch(line); m != nil {
 			flushSection()
 			level := len(m[1]) // number of # characters
@@ -78,74 +201,44 @@ func MarkdownAwareChunker(text string, maxChunkSize int) []MarkdownChunk {
 			}
 			currentHeaders = append(currentHeaders, headerText)
 		} else {
+			if len(currentContent) == 0 {
+				currentFirst = n
+			}
 			currentContent = append(currentContent, line)
 		}
 	}
 	flushSection() // Flush any remaining content
 
-	// Phase 2: Chunk each section, preserving tables
-	var chunks []MarkdownChunk
-	chunkIndex := 0
-
+	// Phase 2: Separate each section into blocks: plain-text sequences and
+	// table sequences. Tables are never split mid-row.
+	var blocks []markdownBlock
 	for _, sec := range sections {
 		headerPath := strings.Join(sec.headers, " > ")
 
-		// Separate the section content into blocks: plain-text sequences and table sequences
-		type block struct {
-			isTable bool
-			lines   []string
-		}
-		var blocks []block
-
 		i := 0
 		for i < len(sec.contentLines) {
-			if tableRowRegex.MatchString(sec.contentLines[i]) {
-				// Collect the entire table as one atomic block
-				var tableLines []string
-				for i < len(sec.contentLines) && tableRowRegex.MatchString(sec.contentLines[i]) {
-					tableLines = append(tableLines, sec.contentLines[i])
-					i++
-				}
-				blocks = append(blocks, block{isTable: true, lines: tableLines})
-			} else {
-				// Collect plain text lines until the next table or end
-				var textLines []string
-				for i < len(sec.contentLines) && !tableRowRegex.MatchString(sec.contentLines[i]) {
-					textLines = append(textLines, sec.contentLines[i])
-					i++
-				}
-				blocks = append(blocks, block{isTable: false, lines: textLines})
+			start := lineStarts[sec.firstLine+i]
+			isTable := tableRowRegex.MatchString(sec.contentLines[i])
+			var blockLines []string
+			for i < len(sec.contentLines) && tableRowRegex.MatchString(sec.contentLines[i]) == isTable {
+				blockLines = append(blockLines, sec.contentLines[i])
+				i++
 			}
-		}
 
-		// Phase 3: Emit chunks from blocks
-		for _, blk := range blocks {
-			raw := strings.TrimSpace(strings.Join(blk.lines, "\n"))
+			joined := strings.Join(blockLines, "\n")
+			raw := strings.TrimSpace(joined)
 			if raw == "" {
 				continue
 			}
-
-			if blk.isTable {
-				// Tables are NEVER split — emit as one chunk regardless of size
-				chunk := buildChunk(headerPath, raw, chunkIndex)
-				chunks = append(chunks, chunk)
-				chunkIndex++
-			} else {
-				// Plain text: word-boundary chunking at maxChunkSize
-				textChunks := splitByWordBoundary(raw, maxChunkSize)
-				for _, tc := range textChunks {
-					if strings.TrimSpace(tc) == "" {
-						continue
-					}
-					chunk := buildChunk(headerPath, tc, chunkIndex)
-					chunks = append(chunks, chunk)
-					chunkIndex++
-				}
-			}
+			blocks = append(blocks, markdownBlock{
+				headerPath: headerPath,
+				isTable:    isTable,
+				raw:        raw,
+				start:      start + len(joined) - len(strings.TrimLeftFunc(joined, unicode.IsSpace)),
+			})
 		}
 	}
-
-	return chunks
+	return blocks
 }
 
 // buildChunk constructs a MarkdownChunk with the parent header path injected as context.
@@ -166,26 +259,269 @@ func buildChunk(headerPath, content string, index int) MarkdownChunk {
 	}
 }
 
-// splitByWordBoundary breaks a string into slices of at most maxLen characters
-// without breaking in the middle of a word.
-func splitByWordBoundary(text string, maxLen int) []string {
-	words := strings.Fields(text)
-	var chunks []string
-	var current strings.Builder
+// sizer measures text in the unit of a strategy: the size of a text is the
+// sum of its words plus sep between them.
+type sizer struct {
+	word func(string) int
+	sep  int
+}
+
+var (
+	charSizer  = sizer{word: utf8.RuneCountInString, sep: 1}
+	tokenSizer = sizer{word: estimateWordTokens}
+)
+
+func (z sizer) of(text string) int {
+	n := 0
+	for i, w := range wordSpans(text) {
+		if i > 0 {
+			n += z.sep
+		}
+		n += z.word(text[w[0]:w[1]])
+	}
+	return n
+}
+
+// EstimateTokens approximates the number of tokens a BPE tokenizer produces
+// for text: about four characters per token, at least one per word.
+func EstimateTokens(text string) int {
+	return tokenSizer.of(text)
+}
+
+func estimateWordTokens(word string) int {
+	return (utf8.RuneCountInString(word) + 3) / 4
+}
+
+// textPiece is a piece of a text with its whitespace collapsed, and the
+// byte span it was taken from.
+type textPiece struct {
+	content    string
+	start, end int
+}
+
+func newPiece(text string, start, end int) textPiece {
+	return textPiece{content: strings.Join(strings.Fields(text[start:end]), " "), start: start, end: end}
+}
+
+// splitByWordBoundary breaks a string into pieces of at most maxLen
+// without breaking in the middle of a word. Each piece after the first
+// starts with the trailing words of the previous one that fit in overlap.
+func splitByWordBoundary(text string, maxLen, overlap int, size sizer) []textPiece {
+	words := wordSpans(text)
+	var pieces []textPiece
+	first, current := 0, 0 // first word of the current piece, and its size
+
+	for i, w := range words {
+		n := size.word(text[w[0]:w[1]])
+		if i > first && current+size.sep+n > maxLen {
+			pieces = append(pieces, newPiece(text, words[first][0], words[i-1][1]))
+
+			next, tail := i, 0
+			for next-1 > first {
+				add := size.word(text[words[next-1][0]:words[next-1][1]])
+				if tail > 0 {
+					add += size.sep
+				}
+				if tail+add > overlap {
+					break
+				}
+				tail += add
+				next--
+			}
+			first, current = next, tail
+		}
+		if i > first {
+			current += size.sep
+		}
+		current += n
+	}
+	if first < len(words) {
+		pieces = append(pieces, newPiece(text, words[first][0], words[len(words)-1][1]))
+	}
+	return pieces
+}
+
+// splitBySentence packs whole sentences into pieces of at most maxLen; each
+// piece after the first repeats the last overlap sentences of the previous
+// one when they fit. A sentence longer than maxLen is split by words.
+func splitBySentence(text string, maxLen, overlap int, size sizer) []textPiece {
+	var pieces []textPiece
+	var window [][2]int // sentences of the current piece
+	windowSize := func() int {
+		n := 0
+		for i, s := range window {
+			if i > 0 {
+				n += size.sep
+			}
+			n += size.of(text[s[0]:s[1]])
+		}
+		return n
+	}
+	flush := func() {
+		if len(window) > 0 {
+			pieces = append(pieces, newPiece(text, window[0][0], window[len(window)-1][1]))
+		}
+	}
 
-	for _, word := range words {
-		// +1 for the space
-		if current.Len()+len(word)+1 > maxLen && current.Len() > 0 {
-			chunks = append(chunks, strings.TrimSpace(current.String()))
-			current.Reset()
+	current := 0
+	for _, s := range sentenceSpans(text) {
+		n := size.of(text[s[0]:s[1]])
+		if n > maxLen {
+			flush()
+			window, current = nil, 0
+			for _, p := range splitByWordBoundary(text[s[0]:s[1]], maxLen, 0, size) {
+				p.start, p.end = p.start+s[0], p.end+s[0]
+				pieces = append(pieces, p)
+			}
+			continue
 		}
-		if current.Len() > 0 {
-			current.WriteByte(' ')
+		if len(window) > 0 && current+size.sep+n > maxLen {
+			flush()
+			if overlap < len(window) {
+				window = window[len(window)-overlap:]
+			}
+			current = windowSize()
+			for len(window) > 0 && current+size.sep+n > maxLen {
+				window = window[1:]
+				current = windowSize()
+			}
 		}
-		current.WriteString(word)
+		if len(window) > 0 {
+			current += size.sep
+		}
+		window = append(window, s)
+		current += n
 	}
-	if current.Len() > 0 {
-		chunks = append(chunks, strings.TrimSpace(current.String()))
+	flush()
+	return pieces
+}
+
+// splitTable splits a table larger than maxLen into groups of rows, each
+// repeating the header row (and its separator row) so it stays readable on
+// its own. A piece spans its own rows, the first one the header too.
+func splitTable(table string, maxLen int, size sizer) []textPiece {
+	lines := strings.Split(table, "\n")
+	if size.of(table) <= maxLen || len(lines) < 3 {
+		return []textPiece{{content: table, end: len(table)}}
 	}
-	return chunks
+	headerRows := 1
+	if tableSeparatorRegex.MatchString(lines[1]) {
+		headerRows = 2
+	}
+	header := strings.Join(lines[:headerRows], "\n")
+	headerSize := size.of(header)
+
+	var pieces []textPiece
+	offset := len(header) + 1
+	pieceStart, current := 0, headerSize
+	var rows []string
+	flush := func(end int) {
+		if len(rows) > 0 {
+			pieces = append(pieces, textPiece{
+				content: header + "\n" + strings.Join(rows, "\n"),
+				start:   pieceStart,
+				end:     end,
+			})
+		}
+	}
+	for _, row := range lines[headerRows:] {
+		n := size.of(row)
+		if len(rows) > 0 && current+size.sep+n > maxLen {
+			flush(offset - 1)
+			rows, pieceStart, current = nil, offset, headerSize
+		}
+		rows = append(rows, row)
+		current += size.sep + n
+		offset += len(row) + 1
+	}
+	flush(len(table))
+	return pieces
+}
+
+// wordSpans returns the byte spans of the words of s, split as strings.Fields does.
+func wordSpans(s string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				spans = append(spans, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(s)})
+	}
+	return spans
+}
+
+// abbreviations end with a period without ending the sentence (lower case,
+// without the final period).
+var abbreviations = map[string]bool{
+	"no": true, "hlm": true, "hal": true, "dll": true, "dsb": true, "dst": true, "dkk": true,
+	"tbk": true, "pt": true, "cv": true, "jl": true, "jln": true, "dr": true, "drs": true,
+	"ir": true, "prof": true, "yth": true, "sdr": true, "bpk": true, "kab": true, "kec": true,
+	"prov": true, "telp": true, "s.d": true, "a.n": true, "u.p": true,
+	"mr": true, "mrs": true, "ms": true, "vs": true, "etc": true, "e.g": true, "i.e": true,
+}
+
+// sentenceSpans returns the byte spans of the sentences of text. A sentence
+// ends at '.', '!', '?' or '…' followed by whitespace (closing quotes and
+// brackets stay with it), or at a blank line. Periods after abbreviations
+// and single letters ("a.", "No.") do not end a sentence.
+func sentenceSpans(text string) [][2]int {
+	var spans [][2]int
+	start := -1
+	end := func(i int) {
+		spans = append(spans, [2]int{start, start + len(strings.TrimRightFunc(text[start:i], unicode.IsSpace))})
+		start = -1
+	}
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+		if start < 0 {
+			if !unicode.IsSpace(r) {
+				start = i
+			}
+			i += n
+			continue
+		}
+		switch {
+		case r == '\n' && strings.TrimLeft(text[i+1:], " \t") != "" && strings.TrimLeft(text[i+1:], " \t")[0] == '\n':
+			end(i)
+		case strings.ContainsRune(".!?…", r):
+			j := i + n
+			for j < len(text) {
+				c, m := utf8.DecodeRuneInString(text[j:])
+				if !strings.ContainsRune(`"')]”’»`, c) {
+					break
+				}
+				j += m
+			}
+			if c, _ := utf8.DecodeRuneInString(text[j:]); j < len(text) && !unicode.IsSpace(c) {
+				break
+			}
+			if r == '.' && isAbbreviation(text[start:i]) {
+				break
+			}
+			end(j)
+			i = j
+			continue
+		}
+		i += n
+	}
+	if start >= 0 {
+		end(len(text))
+	}
+	return spans
+}
+
+// isAbbreviation reports whether the last word of s is an abbreviation or
+// a single letter.
+func isAbbreviation(s string) bool {
+	word := s[strings.LastIndexFunc(s, unicode.IsSpace)+1:]
+	word = strings.ToLower(strings.TrimLeft(word, `("'“‘`))
+	return utf8.RuneCountInString(word) == 1 || abbreviations[word]
 }
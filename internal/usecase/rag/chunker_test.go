@@ -0,0 +1,153 @@
+package rag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+func contents(chunks []MarkdownChunk) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = c.Content
+	}
+	return out
+}
+
+func mustChunker(t *testing.T, opts domain.ChunkingOptions) Chunker {
+	t.Helper()
+	c, err := NewChunker(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestMarkdownChunkerOverlap(t *testing.T) {
+	text := "satu dua tiga empat lima enam tujuh delapan"
+	c := mustChunker(t, domain.ChunkingOptions{MaxSize: 20, Overlap: 10})
+
+	got := contents(c.Chunk(text))
+	want := []string{"satu dua tiga empat", "tiga empat lima enam", "lima enam tujuh", "enam tujuh delapan"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunks = %q, want %q", got, want)
+	}
+}
+
+func TestTokenChunker(t *testing.T) {
+	// Every word is 8 characters: 2 estimated tokens.
+	words := make([]string, 10)
+	for i := range words {
+		words[i] = fmt.Sprintf("kata%04d", i)
+	}
+	c := mustChunker(t, domain.ChunkingOptions{Strategy: domain.ChunkToken, MaxSize: 6, Overlap: 2})
+
+	chunks := c.Chunk(strings.Join(words, " "))
+	if len(chunks) != 5 {
+		t.Fatalf("got %d chunks, want 5: %q", len(chunks), contents(chunks))
+	}
+	for _, chunk := range chunks {
+		if n := EstimateTokens(chunk.Content); n > 6 {
+			t.Errorf("chunk %q has %d tokens, want at most 6", chunk.Content, n)
+		}
+	}
+	if !strings.HasPrefix(chunks[1].Content, "kata0002") {
+		t.Errorf("second chunk %q does not repeat the last word of the first", chunks[1].Content)
+	}
+}
+
+func TestSentenceWindowChunker(t *testing.T) {
+	text := "# Pasal 5\n\nCuti tahunan diberikan 12 hari. Cuti diajukan ke PT. Maju Tbk. paling lambat tujuh hari! " +
+		"Apakah cuti dapat diuangkan? Tidak.\n\nCuti sakit memerlukan surat dokter."
+	c := mustChunker(t, domain.ChunkingOptions{Strategy: domain.ChunkSentenceWindow, MaxSize: 110})
+
+	got := contents(c.Chunk(text))
+	want := []string{
+		"Cuti tahunan diberikan 12 hari. Cuti diajukan ke PT. Maju Tbk. paling lambat tujuh hari!",
+		"Cuti diajukan ke PT. Maju Tbk. paling lambat tujuh hari! Apakah cuti dapat diuangkan? Tidak.",
+		"Tidak. Cuti sakit memerlukan surat dokter.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunks = %q,\nwant %q", got, want)
+	}
+}
+
+func TestTableSplitting(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("# Tarif\n\n| Golongan | Tarif |\n| --- | --- |\n")
+	for i := 1; i <= 6; i++ {
+		fmt.Fprintf(&sb, "| Golongan %d | %d%% |\n", i, i*5)
+	}
+	text := sb.String()
+
+	split := mustChunker(t, domain.ChunkingOptions{MaxSize: 80}).Chunk(text)
+	if len(split) < 2 {
+		t.Fatalf("table of %d characters was not split at 80: %q", len(text), contents(split))
+	}
+	rows := 0
+	for _, chunk := range split {
+		if !strings.HasPrefix(chunk.Content, "| Golongan | Tarif |\n| --- | --- |\n") {
+			t.Errorf("piece does not repeat the header: %q", chunk.Content)
+		}
+		rows += strings.Count(chunk.Content, "\n") - 1
+		last := chunk.Content[strings.LastIndex(chunk.Content, "\n")+1:]
+		if !strings.HasSuffix(text[chunk.Start:chunk.End], last) {
+			t.Errorf("piece span %q does not end with its last row %q", text[chunk.Start:chunk.End], last)
+		}
+	}
+	if rows != 6 {
+		t.Errorf("pieces hold %d rows, want 6", rows)
+	}
+
+	keep := true
+	whole := mustChunker(t, domain.ChunkingOptions{MaxSize: 80, KeepTables: &keep}).Chunk(text)
+	if len(whole) != 1 {
+		t.Errorf("keep_tables: got %d chunks, want 1", len(whole))
+	}
+}
+
+func TestChunkingPolicy(t *testing.T) {
+	policy := NewChunkingPolicy(config.ChunkingConfig{Strategy: domain.ChunkMarkdown, MaxSize: 800, Overlap: 100})
+	settings := domain.TenantSettings{Chunking: &domain.TenantChunking{
+		ChunkingOptions: domain.ChunkingOptions{Overlap: 50},
+		Categories: map[string]domain.ChunkingOptions{
+			"POJK": {Strategy: domain.ChunkToken},
+		},
+	}}
+
+	if got := policy.Options(domain.TenantSettings{}, ""); got.MaxSize != 800 || got.Overlap != 100 {
+		t.Errorf("defaults = %+v", got)
+	}
+	if got := policy.Options(settings, "umum"); got.Strategy != domain.ChunkMarkdown || got.MaxSize != 800 || got.Overlap != 50 {
+		t.Errorf("tenant options = %+v", got)
+	}
+	// Another strategy does not inherit sizes in characters.
+	if got := policy.Options(settings, "POJK"); got.Strategy != domain.ChunkToken || got.MaxSize != DefaultChunkTokens || got.Overlap != 0 {
+		t.Errorf("category options = %+v", got)
+	}
+	if got := (*ChunkingPolicy)(nil).Options(domain.TenantSettings{}, ""); got.Strategy != domain.ChunkMarkdown || got.MaxSize != DefaultChunkChars {
+		t.Errorf("nil policy options = %+v", got)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	preview, err := Preview("# Bab\n\nÀ propos du modal.", domain.ChunkingOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preview.Chunks) != 1 || preview.Options.Strategy != domain.ChunkMarkdown {
+		t.Fatalf("preview = %+v", preview)
+	}
+	chunk := preview.Chunks[0]
+	if chunk.HeaderPath != "Bab" || chunk.Characters != 18 || chunk.CharStart != 7 || chunk.CharEnd != 25 || chunk.Tokens != 6 {
+		t.Errorf("chunk = %+v", chunk)
+	}
+
+	if _, err := Preview("x", domain.ChunkingOptions{Strategy: "paragraph"}); err == nil {
+		t.Error("unknown strategy accepted")
+	}
+}
@@ -0,0 +1,65 @@
+package rag
+
+import (
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// ChunkingPolicy resolves the chunking options of a document: the configured
+// defaults, overridden by its tenant's chunking setting and then by the
+// setting of its category. A nil policy starts from the strategy defaults.
+type ChunkingPolicy struct {
+	defaults domain.ChunkingOptions
+}
+
+// NewChunkingPolicy takes its defaults from the ai.chunking configuration.
+func NewChunkingPolicy(cfg config.ChunkingConfig) *ChunkingPolicy {
+	p := &ChunkingPolicy{defaults: domain.ChunkingOptions{
+		Strategy: cfg.Strategy,
+		MaxSize:  cfg.MaxSize,
+		Overlap:  cfg.Overlap,
+	}}
+	if cfg.KeepTables {
+		p.defaults.KeepTables = &cfg.KeepTables
+	}
+	return p
+}
+
+// Options returns the options of a document of the given category, with
+// the defaults of the resolved strategy filled in.
+func (p *ChunkingPolicy) Options(settings domain.TenantSettings, category string) domain.ChunkingOptions {
+	var opts domain.ChunkingOptions
+	if p != nil {
+		opts = p.defaults
+	}
+	if t := settings.Chunking; t != nil {
+		opts = opts.Merge(t.ChunkingOptions)
+		if override, ok := t.Categories[category]; ok {
+			opts = opts.Merge(override)
+		}
+	}
+	return ChunkingDefaults(opts)
+}
+
+// Preview chunks text with opts, reporting the size of every chunk.
+func Preview(text string, opts domain.ChunkingOptions) (*domain.ChunkPreview, error) {
+	chunker, err := NewChunker(opts)
+	if err != nil {
+		return nil, err
+	}
+	chunks := chunker.Chunk(text)
+	locations := LocateChunks(text, nil, chunks)
+	preview := &domain.ChunkPreview{Options: ChunkingDefaults(opts), Chunks: make([]domain.PreviewChunk, len(chunks))}
+	for i, c := range chunks {
+		preview.Chunks[i] = domain.PreviewChunk{
+			Index:      c.Index,
+			HeaderPath: c.HeaderPath,
+			Content:    c.Content,
+			Characters: len([]rune(c.Content)),
+			Tokens:     EstimateTokens(c.Content),
+			CharStart:  locations[i].CharStart,
+			CharEnd:    locations[i].CharEnd,
+		}
+	}
+	return preview, nil
+}
@@ -0,0 +1,135 @@
+package rag
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/datatypes"
+)
+
+// ChunkLocation is where a chunk sits in its document: the pages and boxes
+// of the source file it covers and its character offsets in the text.
+type ChunkLocation struct {
+	PageStart, PageEnd int
+	Regions            []domain.PageRegion
+	CharStart, CharEnd int
+}
+
+// RegionsJSON encodes Regions for DocumentChunk.Regions; nil when empty.
+func (l ChunkLocation) RegionsJSON() datatypes.JSON {
+	if len(l.Regions) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(l.Regions)
+	return data
+}
+
+// LocateChunks maps chunks of text back to the source file through the
+// layout the parser returned for it. Reviewers may have edited the text
+// since it was parsed, so the layout is realigned first: elements whose
+// text is gone are dropped, and a chunk of text added by a reviewer takes
+// the page of the element before it.
+func LocateChunks(text string, layout []domain.LayoutElement, chunks []MarkdownChunk) []ChunkLocation {
+	elements := alignLayout(text, layout)
+	starts, ends := &runeCounter{text: text}, &runeCounter{text: text}
+	locations := make([]ChunkLocation, len(chunks))
+	for i, c := range chunks {
+		loc := ChunkLocation{CharStart: starts.at(c.Start), CharEnd: ends.at(c.End)}
+		previous := 0
+		for _, el := range elements {
+			if el.End <= c.Start {
+				if el.Page > 0 {
+					previous = el.Page
+				}
+				continue
+			}
+			if el.Start >= c.End {
+				break
+			}
+			if el.Page <= 0 {
+				continue
+			}
+			if loc.PageStart == 0 || el.Page < loc.PageStart {
+				loc.PageStart = el.Page
+			}
+			if el.Page > loc.PageEnd {
+				loc.PageEnd = el.Page
+			}
+			if el.Region != nil {
+				loc.Regions = append(loc.Regions, *el.Region)
+			}
+		}
+		if loc.PageStart == 0 {
+			loc.PageStart, loc.PageEnd = previous, previous
+		}
+		locations[i] = loc
+	}
+	return locations
+}
+
+// alignLayout returns the elements found in text, in order, with their
+// offsets pointing into text.
+func alignLayout(text string, layout []domain.LayoutElement) []domain.LayoutElement {
+	aligned := make([]domain.LayoutElement, 0, len(layout))
+	cursor := 0
+	for _, el := range layout {
+		if el.Text == "" {
+			continue
+		}
+		if el.Start < cursor || el.End > len(text) || el.Start > el.End || text[el.Start:el.End] != el.Text {
+			idx := strings.Index(text[cursor:], el.Text)
+			if idx < 0 {
+				continue
+			}
+			el.Start = cursor + idx
+			el.End = el.Start + len(el.Text)
+		}
+		aligned = append(aligned, el)
+		cursor = el.End
+	}
+	return aligned
+}
+
+// runeCounter converts increasing byte offsets of text to character offsets.
+type runeCounter struct {
+	text         string
+	bytes, runes int
+}
+
+func (c *runeCounter) at(offset int) int {
+	if offset < c.bytes {
+		c.bytes, c.runes = 0, 0
+	}
+	c.runes += utf8.RuneCountInString(c.text[c.bytes:offset])
+	c.bytes = offset
+	return c.runes
+}
+
+// SourceLabel names where a search result comes from for LLM prompts, e.g.
+// "Kebijakan Cuti, hal. 3-4 (BAB II > Pasal 5)".
+func SourceLabel(res domain.HybridSearchResult) string {
+	label := res.DocumentTitle
+	c := res.Citation
+	switch {
+	case c.PageStart > 0 && c.PageEnd > c.PageStart:
+		label += fmt.Sprintf(", hal. %d-%d", c.PageStart, c.PageEnd)
+	case c.PageStart > 0:
+		label += fmt.Sprintf(", hal. %d", c.PageStart)
+	}
+	if c.HeaderPath != "" {
+		label += " (" + c.HeaderPath + ")"
+	}
+	return label
+}
+
+// Citations returns the citations of search results, in order.
+func Citations(results []domain.HybridSearchResult) []domain.Citation {
+	citations := make([]domain.Citation, len(results))
+	for i, res := range results {
+		citations[i] = res.Citation
+	}
+	return citations
+}
@@ -0,0 +1,111 @@
+package rag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// parsedLayout lays out paragraphs the way the parser renders them, one
+// element per paragraph on the given pages.
+func parsedLayout(paragraphs []string, pages []int) (string, []domain.LayoutElement) {
+	var sb strings.Builder
+	var layout []domain.LayoutElement
+	for i, p := range paragraphs {
+		if strings.HasPrefix(p, "#") {
+			sb.WriteString(p + "\n\n")
+			continue
+		}
+		start := sb.Len()
+		sb.WriteString(p)
+		layout = append(layout, domain.LayoutElement{
+			Kind: "text", Text: p, Page: pages[i], Start: start, End: sb.Len(),
+			Region: &domain.PageRegion{Page: pages[i], Left: 50, Top: float64(100 * i), Right: 500, Bottom: float64(100*i + 50)},
+		})
+		sb.WriteString("\n\n")
+	}
+	return strings.TrimRight(sb.String(), "\n"), layout
+}
+
+func TestMarkdownAwareChunkerOffsets(t *testing.T) {
+	text := "# Judul\n\n  Paragraf  pertama   tentang cuti.\n\n## Bagian\n\n| a | b |\n| 1 | 2 |\n\nPenutup."
+	chunks := MarkdownAwareChunker(text, 20)
+	if len(chunks) != 4 {
+		t.Fatalf("got %d chunks, want 4: %+v", len(chunks), chunks)
+	}
+	for _, c := range chunks {
+		span := strings.Join(strings.Fields(text[c.Start:c.End]), " ")
+		if span != strings.Join(strings.Fields(c.Content), " ") {
+			t.Errorf("chunk %d: span %q does not match content %q", c.Index, text[c.Start:c.End], c.Content)
+		}
+	}
+	if chunks[0].Content != "Paragraf pertama" || chunks[2].HeaderPath != "Judul > Bagian" {
+		t.Errorf("unexpected chunks: %+v", chunks)
+	}
+}
+
+func TestLocateChunks(t *testing.T) {
+	text, layout := parsedLayout([]string{
+		"# Pasal 1",
+		"Karyawan berhak atas cuti tahunan.",
+		"Cuti diajukan paling lambat tujuh hari sebelumnya.",
+		"Cuti sakit memerlukan surat dokter.",
+	}, []int{1, 1, 2, 3})
+
+	chunks := MarkdownAwareChunker(text, 86)
+	locations := LocateChunks(text, layout, chunks)
+	if len(locations) != 2 {
+		t.Fatalf("got %d locations, want 2", len(locations))
+	}
+	if l := locations[0]; l.PageStart != 1 || l.PageEnd != 2 || len(l.Regions) != 2 {
+		t.Errorf("first chunk = %+v, want pages 1-2 with 2 regions", l)
+	}
+	if l := locations[1]; l.PageStart != 3 || l.PageEnd != 3 || len(l.Regions) != 1 {
+		t.Errorf("second chunk = %+v, want page 3 with 1 region", l)
+	}
+	if l := locations[0]; l.CharStart != strings.Index(text, "Karyawan") {
+		t.Errorf("char start = %d", l.CharStart)
+	}
+}
+
+func TestLocateChunksAfterReview(t *testing.T) {
+	_, layout := parsedLayout([]string{
+		"Pengantar singkat.",
+		"Ketentuan modal inti.",
+		"Ketentuan likuiditas.",
+	}, []int{1, 4, 5})
+
+	// A reviewer fixed a typo before the first paragraph, added a note and
+	// removed the second paragraph: offsets shift, one element disappears.
+	edited := "Pengantar  singkat yang benar.\n\nCatatan ditambahkan reviewer.\n\nKetentuan likuiditas. Rupiah: 1 €"
+	chunks := []MarkdownChunk{
+		{Start: strings.Index(edited, "Catatan"), End: strings.Index(edited, "reviewer.") + len("reviewer.")},
+		{Start: strings.Index(edited, "Ketentuan"), End: len(edited)},
+	}
+
+	locations := LocateChunks(edited, layout, chunks)
+	if l := locations[0]; l.PageStart != 0 || len(l.Regions) != 0 {
+		t.Errorf("note = %+v, want no page: nothing parsed precedes it", l)
+	}
+	if l := locations[1]; l.PageStart != 5 || l.PageEnd != 5 || len(l.Regions) != 1 {
+		t.Errorf("likuiditas = %+v, want page 5", l)
+	}
+	if l := locations[1]; l.CharEnd != len([]rune(edited)) {
+		t.Errorf("char end = %d, want %d characters", l.CharEnd, len([]rune(edited)))
+	}
+}
+
+func TestSourceLabel(t *testing.T) {
+	res := domain.HybridSearchResult{
+		DocumentTitle: "Kebijakan Cuti",
+		Citation:      domain.Citation{PageStart: 3, PageEnd: 4, HeaderPath: "BAB II > Pasal 5"},
+	}
+	if got, want := SourceLabel(res), "Kebijakan Cuti, hal. 3-4 (BAB II > Pasal 5)"; got != want {
+		t.Errorf("SourceLabel = %q, want %q", got, want)
+	}
+	res.Citation = domain.Citation{}
+	if got := SourceLabel(res); got != "Kebijakan Cuti" {
+		t.Errorf("SourceLabel without pages = %q", got)
+	}
+}
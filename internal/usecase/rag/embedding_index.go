@@ -0,0 +1,360 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mq"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// TypeBuildEmbeddingIndex fills an embedding index in the background.
+const TypeBuildEmbeddingIndex = "rag:build_embedding_index"
+
+// reembedBatchSize is the number of chunks embedded per round while an
+// index is being built.
+const reembedBatchSize = 100
+
+var (
+	// ErrIndexNotFound is returned for an index that does not exist or
+	// belongs to another tenant.
+	ErrIndexNotFound = errors.New("embedding index not found")
+	// ErrMigrationInProgress is returned when the tenant already has an
+	// index being built.
+	ErrMigrationInProgress = errors.New("an embedding index is already being built")
+	// ErrSameModel is returned when migrating to the active model.
+	ErrSameModel = errors.New("the corpus is already embedded with this model")
+	// ErrNoStandby is returned by Rollback when there is no previous index.
+	ErrNoStandby = errors.New("no previous embedding index to roll back to")
+	// ErrInvalidIndexState is returned when an index is not in a state the
+	// action applies to.
+	ErrInvalidIndexState = errors.New("invalid embedding index state for this action")
+)
+
+// BuildEmbeddingIndexPayload identifies the index to build.
+type BuildEmbeddingIndexPayload struct {
+	IndexID string `json:"index_id"`
+}
+
+// NewBuildEmbeddingIndexTask creates an Asynq task that embeds the chunks
+// missing from an index and activates it once complete.
+func NewBuildEmbeddingIndexTask(indexID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(BuildEmbeddingIndexPayload{IndexID: indexID})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeBuildEmbeddingIndex,
+		payload,
+		asynq.MaxRetry(5),
+		asynq.Queue("heavy_parsing"),
+	), nil
+}
+
+// IndexEmbedder pairs an embedding index with the embedder of its model.
+type IndexEmbedder struct {
+	Index    *domain.EmbeddingIndex
+	Embedder ai.Embedder
+}
+
+// EmbeddingIndexService migrates a tenant's corpus between embedding
+// models. A migration builds a new index next to the active one, which
+// keeps serving queries, then swaps them atomically; the replaced index
+// stays in sync as STANDBY until the next migration, for rollbacks.
+type EmbeddingIndexService struct {
+	repo      domain.EmbeddingIndexRepository
+	embedders *ai.Embedders
+	mqClient  mq.TaskQueue
+}
+
+func NewEmbeddingIndexService(repo domain.EmbeddingIndexRepository, embedders *ai.Embedders, mqClient mq.TaskQueue) *EmbeddingIndexService {
+	return &EmbeddingIndexService{repo: repo, embedders: embedders, mqClient: mqClient}
+}
+
+// QueryEmbedder returns the embedder of the tenant's active index; tenants
+// without one use the default embedder.
+func (s *EmbeddingIndexService) QueryEmbedder(ctx context.Context, tenantID string) (ai.Embedder, error) {
+	tid, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant ID: %w", err)
+	}
+	active, err := s.repo.Active(ctx, tid)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return s.embedders.Default()
+	}
+	return s.embedders.Get(active.Model)
+}
+
+// IngestTargets returns the indexes new chunks of the tenant go into: the
+// active one, created for the default embedder on first use, and the
+// secondary ones whose embedder is configured.
+func (s *EmbeddingIndexService) IngestTargets(ctx context.Context, tenantID uuid.UUID) (IndexEmbedder, []IndexEmbedder, error) {
+	def, err := s.embedders.Default()
+	if err != nil {
+		return IndexEmbedder{}, nil, err
+	}
+	active, err := s.repo.EnsureActive(ctx, tenantID, def.Model(), def.Dimension())
+	if err != nil {
+		return IndexEmbedder{}, nil, err
+	}
+	activeEmbedder, err := s.embedders.Get(active.Model)
+	if err != nil {
+		return IndexEmbedder{}, nil, err
+	}
+
+	indexes, err := s.repo.ListSecondary(ctx, tenantID)
+	if err != nil {
+		return IndexEmbedder{}, nil, err
+	}
+	var secondary []IndexEmbedder
+	for _, idx := range indexes {
+		e, err := s.embedders.Get(idx.Model)
+		if err != nil {
+			log.Printf("[RAG-Index] ⚠ %s index %s of tenant %s not kept in sync: %v", idx.Status, idx.ID, tenantID, err)
+			continue
+		}
+		secondary = append(secondary, IndexEmbedder{Index: idx, Embedder: e})
+	}
+	return IndexEmbedder{Index: active, Embedder: activeEmbedder}, secondary, nil
+}
+
+// List returns the tenant's indexes, newest first.
+func (s *EmbeddingIndexService) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.EmbeddingIndex, error) {
+	return s.repo.ListByTenant(ctx, tenantID)
+}
+
+// Models returns the embedding models a corpus can be migrated to.
+func (s *EmbeddingIndexService) Models() []string {
+	return s.embedders.Models()
+}
+
+// Start begins re-embedding the tenant's corpus with model.
+func (s *EmbeddingIndexService) Start(ctx context.Context, tenantID uuid.UUID, model string) (*domain.EmbeddingIndex, error) {
+	e, err := s.embedders.Get(model)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := s.repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range indexes {
+		switch {
+		case idx.Status == domain.EmbeddingIndexBuilding:
+			return nil, ErrMigrationInProgress
+		case idx.Status == domain.EmbeddingIndexActive && idx.Model == e.Model() && idx.Dimension == e.Dimension():
+			return nil, ErrSameModel
+		}
+	}
+
+	total, err := s.repo.CountChunks(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	idx := &domain.EmbeddingIndex{
+		TenantID:    tenantID,
+		Model:       e.Model(),
+		Dimension:   e.Dimension(),
+		Status:      domain.EmbeddingIndexBuilding,
+		TotalChunks: total,
+	}
+	if err := s.repo.Create(ctx, idx); err != nil {
+		return nil, err
+	}
+	if err := s.enqueueBuild(idx); err != nil {
+		_ = s.repo.Discard(ctx, idx, domain.EmbeddingIndexFailed)
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Cancel stops building an index and drops its vectors.
+func (s *EmbeddingIndexService) Cancel(ctx context.Context, tenantID, indexID uuid.UUID) (*domain.EmbeddingIndex, error) {
+	idx, err := s.tenantIndex(ctx, tenantID, indexID)
+	if err != nil {
+		return nil, err
+	}
+	if idx.Status != domain.EmbeddingIndexBuilding {
+		return nil, ErrInvalidIndexState
+	}
+	if err := s.repo.Discard(ctx, idx, domain.EmbeddingIndexCancelled); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Rollback switches the tenant back to its STANDBY index. Chunks ingested
+// while its embedder was unavailable are re-embedded in the background.
+func (s *EmbeddingIndexService) Rollback(ctx context.Context, tenantID uuid.UUID) (*domain.EmbeddingIndex, error) {
+	indexes, err := s.repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	var standby *domain.EmbeddingIndex
+	for _, idx := range indexes {
+		switch idx.Status {
+		case domain.EmbeddingIndexBuilding:
+			return nil, ErrMigrationInProgress
+		case domain.EmbeddingIndexStandby:
+			standby = idx
+		}
+	}
+	if standby == nil {
+		return nil, ErrNoStandby
+	}
+	if _, err := s.embedders.Get(standby.Model); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Activate(ctx, standby, false); err != nil {
+		return nil, err
+	}
+	log.Printf("[RAG-Index] ↩ Tenant %s rolled back to embedding index %s (%s)", tenantID, standby.ID, standby.Model)
+	if err := s.enqueueBuild(standby); err != nil {
+		log.Printf("[RAG-Index] ⚠ failed to enqueue re-embedding of stale chunks for index %s: %v", standby.ID, err)
+	}
+	return standby, nil
+}
+
+// HandleBuildEmbeddingIndex embeds every chunk missing from the index. A
+// BUILDING index is then activated; an ACTIVE one only has its stale chunks
+// (embedded by another model) re-embedded in place.
+func (s *EmbeddingIndexService) HandleBuildEmbeddingIndex(ctx context.Context, t *asynq.Task) error {
+	var payload BuildEmbeddingIndexPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+	id, err := uuid.Parse(payload.IndexID)
+	if err != nil {
+		return fmt.Errorf("invalid index ID: %v: %w", err, asynq.SkipRetry)
+	}
+	idx, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if idx == nil || (idx.Status != domain.EmbeddingIndexBuilding && idx.Status != domain.EmbeddingIndexActive) {
+		return nil // cancelled, rolled back or replaced meanwhile
+	}
+	e, err := s.embedders.Get(idx.Model)
+	if err != nil {
+		s.fail(ctx, idx, err)
+		return fmt.Errorf("embedding index %s: %v: %w", idx.ID, err, asynq.SkipRetry)
+	}
+
+	log.Printf("[RAG-Index] ▶ Building %s embedding index %s for tenant %s (%s, %d dims)", idx.Status, idx.ID, idx.TenantID, idx.Model, idx.Dimension)
+	if err := s.fill(ctx, idx, e); err != nil {
+		if retry, ok := asynq.GetRetryCount(ctx); ok {
+			if maxRetry, ok := asynq.GetMaxRetry(ctx); ok && retry >= maxRetry {
+				s.fail(ctx, idx, err)
+			}
+		}
+		return err
+	}
+	if idx.Status != domain.EmbeddingIndexBuilding {
+		return nil
+	}
+
+	if err := s.repo.EnsureVectorIndex(ctx, idx.Dimension); err != nil {
+		return err
+	}
+	if err := s.repo.Activate(ctx, idx, true); err != nil {
+		// Chunks ingested during the final round are picked up on retry.
+		return err
+	}
+	log.Printf("[RAG-Index] ✅ Tenant %s switched to embedding index %s (%s)", idx.TenantID, idx.ID, idx.Model)
+
+	// Catch chunks stored with the old model while the switch happened.
+	return s.fill(ctx, idx, e)
+}
+
+// fill embeds the chunks missing from idx until none are left, tracking
+// progress on the index. It stops early when the index is cancelled.
+func (s *EmbeddingIndexService) fill(ctx context.Context, idx *domain.EmbeddingIndex, e ai.Embedder) error {
+	total, err := s.repo.CountChunks(ctx, idx.TenantID)
+	if err != nil {
+		return err
+	}
+	for {
+		current, err := s.repo.FindByID(ctx, idx.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil || current.Status != idx.Status {
+			return nil
+		}
+
+		chunks, err := s.repo.MissingChunks(ctx, idx, reembedBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(chunks) > 0 {
+			texts := make([]string, len(chunks))
+			for i, c := range chunks {
+				texts[i] = c.Content
+			}
+			embeddings, err := e.Embed(ctx, texts)
+			if err != nil {
+				return fmt.Errorf("embedding failed: %w", err)
+			}
+			vectors := make([]domain.ChunkVector, len(chunks))
+			for i, c := range chunks {
+				vectors[i] = domain.ChunkVector{ChunkID: c.ID, Embedding: embeddings[i]}
+			}
+			if err := s.repo.StoreVectors(ctx, idx, vectors); err != nil {
+				return err
+			}
+		}
+
+		missing, err := s.repo.CountMissing(ctx, idx)
+		if err != nil {
+			return err
+		}
+		current.TotalChunks = total
+		current.EmbeddedChunks = total - missing
+		if err := s.repo.Update(ctx, current); err != nil {
+			return err
+		}
+		if len(chunks) < reembedBatchSize || missing == 0 {
+			return nil
+		}
+	}
+}
+
+func (s *EmbeddingIndexService) fail(ctx context.Context, idx *domain.EmbeddingIndex, cause error) {
+	log.Printf("[RAG-Index] ❌ Embedding index %s failed: %v", idx.ID, cause)
+	if idx.Status != domain.EmbeddingIndexBuilding {
+		return
+	}
+	idx.Error = cause.Error()
+	_ = s.repo.Discard(ctx, idx, domain.EmbeddingIndexFailed)
+}
+
+func (s *EmbeddingIndexService) tenantIndex(ctx context.Context, tenantID, indexID uuid.UUID) (*domain.EmbeddingIndex, error) {
+	idx, err := s.repo.FindByID(ctx, indexID)
+	if err != nil {
+		return nil, err
+	}
+	if idx == nil || idx.TenantID != tenantID {
+		return nil, ErrIndexNotFound
+	}
+	return idx, nil
+}
+
+func (s *EmbeddingIndexService) enqueueBuild(idx *domain.EmbeddingIndex) error {
+	task, err := NewBuildEmbeddingIndexTask(idx.ID.String())
+	if err != nil {
+		return err
+	}
+	if _, err := s.mqClient.EnqueueTask(task); err != nil {
+		return fmt.Errorf("failed to enqueue embedding index build: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// memIndexRepo keeps indexes, chunks and secondary vectors in memory,
+// following the semantics of the postgres repository.
+type memIndexRepo struct {
+	indexes map[uuid.UUID]*domain.EmbeddingIndex
+	chunks  []*domain.DocumentChunk
+	vectors map[uuid.UUID]map[uuid.UUID][]float32
+}
+
+func newMemIndexRepo() *memIndexRepo {
+	return &memIndexRepo{
+		indexes: map[uuid.UUID]*domain.EmbeddingIndex{},
+		vectors: map[uuid.UUID]map[uuid.UUID][]float32{},
+	}
+}
+
+func (m *memIndexRepo) Create(ctx context.Context, idx *domain.EmbeddingIndex) error {
+	if idx.ID == uuid.Nil {
+		idx.ID = uuid.New()
+	}
+	copied := *idx
+	m.indexes[idx.ID] = &copied
+	return nil
+}
+
+func (m *memIndexRepo) Update(ctx context.Context, idx *domain.EmbeddingIndex) error {
+	copied := *idx
+	m.indexes[idx.ID] = &copied
+	return nil
+}
+
+func (m *memIndexRepo) FindByID(ctx context.Context, id uuid.UUID) (*domain.EmbeddingIndex, error) {
+	idx, ok := m.indexes[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *idx
+	return &copied, nil
+}
+
+func (m *memIndexRepo) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.EmbeddingIndex, error) {
+	var out []*domain.EmbeddingIndex
+	for _, idx := range m.indexes {
+		if idx.TenantID == tenantID {
+			copied := *idx
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (m *memIndexRepo) withStatus(tenantID uuid.UUID, statuses ...string) []*domain.EmbeddingIndex {
+	var out []*domain.EmbeddingIndex
+	for _, idx := range m.indexes {
+		for _, status := range statuses {
+			if idx.TenantID == tenantID && idx.Status == status {
+				copied := *idx
+				out = append(out, &copied)
+			}
+		}
+	}
+	return out
+}
+
+func (m *memIndexRepo) Active(ctx context.Context, tenantID uuid.UUID) (*domain.EmbeddingIndex, error) {
+	if active := m.withStatus(tenantID, domain.EmbeddingIndexActive); len(active) > 0 {
+		return active[0], nil
+	}
+	return nil, nil
+}
+
+func (m *memIndexRepo) EnsureActive(ctx context.Context, tenantID uuid.UUID, model string, dimension int) (*domain.EmbeddingIndex, error) {
+	if active, _ := m.Active(ctx, tenantID); active != nil {
+		return active, nil
+	}
+	idx := &domain.EmbeddingIndex{TenantID: tenantID, Model: model, Dimension: dimension, Status: domain.EmbeddingIndexActive}
+	_ = m.Create(ctx, idx)
+	return idx, nil
+}
+
+func (m *memIndexRepo) ListSecondary(ctx context.Context, tenantID uuid.UUID) ([]*domain.EmbeddingIndex, error) {
+	return m.withStatus(tenantID, domain.EmbeddingIndexBuilding, domain.EmbeddingIndexStandby), nil
+}
+
+func (m *memIndexRepo) CountChunks(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var n int64
+	for _, c := range m.chunks {
+		if c.TenantID == tenantID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memIndexRepo) missing(idx *domain.EmbeddingIndex) []*domain.DocumentChunk {
+	var out []*domain.DocumentChunk
+	for _, c := range m.chunks {
+		if c.TenantID != idx.TenantID {
+			continue
+		}
+		if idx.Status == domain.EmbeddingIndexActive {
+			if c.EmbeddingModel != idx.Model || c.EmbeddingDim != idx.Dimension {
+				out = append(out, c)
+			}
+		} else if _, ok := m.vectors[idx.ID][c.ID]; !ok {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (m *memIndexRepo) MissingChunks(ctx context.Context, idx *domain.EmbeddingIndex, limit int) ([]domain.DocumentChunk, error) {
+	var out []domain.DocumentChunk
+	for _, c := range m.missing(idx) {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, *c)
+	}
+	return out, nil
+}
+
+func (m *memIndexRepo) CountMissing(ctx context.Context, idx *domain.EmbeddingIndex) (int64, error) {
+	return int64(len(m.missing(idx))), nil
+}
+
+func (m *memIndexRepo) StoreVectors(ctx context.Context, idx *domain.EmbeddingIndex, vectors []domain.ChunkVector) error {
+	for _, v := range vectors {
+		if idx.Status == domain.EmbeddingIndexActive {
+			for _, c := range m.chunks {
+				if c.ID == v.ChunkID {
+					c.Embedding, c.EmbeddingModel, c.EmbeddingDim = v.Embedding, idx.Model, idx.Dimension
+				}
+			}
+			continue
+		}
+		if m.vectors[idx.ID] == nil {
+			m.vectors[idx.ID] = map[uuid.UUID][]float32{}
+		}
+		m.vectors[idx.ID][v.ChunkID] = v.Embedding
+	}
+	return nil
+}
+
+func (m *memIndexRepo) Activate(ctx context.Context, idx *domain.EmbeddingIndex, requireComplete bool) error {
+	target := m.indexes[idx.ID]
+	if requireComplete && len(m.missing(target)) > 0 {
+		return domain.ErrEmbeddingIndexIncomplete
+	}
+	for _, i := range m.indexes {
+		if i.TenantID != target.TenantID || i.ID == target.ID {
+			continue
+		}
+		switch i.Status {
+		case domain.EmbeddingIndexStandby:
+			_ = m.Discard(ctx, i, domain.EmbeddingIndexRetired)
+		case domain.EmbeddingIndexActive:
+			m.vectors[i.ID] = map[uuid.UUID][]float32{}
+			for _, c := range m.chunks {
+				if c.TenantID == i.TenantID && c.EmbeddingModel == i.Model {
+					m.vectors[i.ID][c.ID] = c.Embedding
+				}
+			}
+			i.Status = domain.EmbeddingIndexStandby
+		}
+	}
+	for _, c := range m.chunks {
+		if v, ok := m.vectors[target.ID][c.ID]; ok {
+			c.Embedding, c.EmbeddingModel, c.EmbeddingDim = v, target.Model, target.Dimension
+		}
+	}
+	delete(m.vectors, target.ID)
+	now := time.Now()
+	target.Status = domain.EmbeddingIndexActive
+	target.ActivatedAt = &now
+	*idx = *target
+	return nil
+}
+
+func (m *memIndexRepo) Discard(ctx context.Context, idx *domain.EmbeddingIndex, status string) error {
+	delete(m.vectors, idx.ID)
+	m.indexes[idx.ID].Status = status
+	idx.Status = status
+	return nil
+}
+
+func (m *memIndexRepo) EnsureVectorIndex(ctx context.Context, dimension int) error { return nil }
+
+type memQueue struct {
+	tasks []*asynq.Task
+}
+
+func (q *memQueue) EnqueueTask(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	q.tasks = append(q.tasks, task)
+	return &asynq.TaskInfo{}, nil
+}
+
+func (q *memQueue) Close() error { return nil }
+
+func (q *memQueue) run(t *testing.T, svc *EmbeddingIndexService) {
+	t.Helper()
+	tasks := q.tasks
+	q.tasks = nil
+	for _, task := range tasks {
+		if err := svc.HandleBuildEmbeddingIndex(context.Background(), task); err != nil {
+			t.Fatalf("build task failed: %v", err)
+		}
+	}
+}
+
+func TestEmbeddingIndexMigration(t *testing.T) {
+	ctx := context.Background()
+	embedders, err := ai.NewEmbedders(ctx, config.AIConfig{Embedding: config.EmbeddingConfig{
+		Backend:   config.EmbeddingBackendHash,
+		Model:     "old",
+		Dimension: 8,
+		Additional: []config.EmbeddingConfig{
+			{Backend: config.EmbeddingBackendHash, Model: "new", Dimension: 16},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newMemIndexRepo()
+	queue := &memQueue{}
+	svc := NewEmbeddingIndexService(repo, embedders, queue)
+	tenantID := uuid.New()
+
+	// The first ingestion creates the active index for the default model.
+	active, _, err := svc.IngestTargets(ctx, tenantID)
+	if err != nil || active.Index.Model != "old" {
+		t.Fatalf("active index = %+v, %v", active.Index, err)
+	}
+	for _, text := range []string{"modal inti", "rasio kecukupan modal", "laporan tahunan"} {
+		v, _ := ai.EmbedQuery(ctx, active.Embedder, text)
+		repo.chunks = append(repo.chunks, &domain.DocumentChunk{
+			ID: uuid.New(), TenantID: tenantID, Content: text,
+			Embedding: v, EmbeddingModel: "old", EmbeddingDim: 8,
+		})
+	}
+
+	if _, err := svc.Start(ctx, tenantID, "old"); !errors.Is(err, ErrSameModel) {
+		t.Errorf("migrating to the active model: err = %v", err)
+	}
+	if _, err := svc.Start(ctx, tenantID, "unknown"); !errors.Is(err, ai.ErrUnknownEmbeddingModel) {
+		t.Errorf("migrating to an unknown model: err = %v", err)
+	}
+	building, err := svc.Start(ctx, tenantID, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Start(ctx, tenantID, "new"); !errors.Is(err, ErrMigrationInProgress) {
+		t.Errorf("second migration: err = %v", err)
+	}
+
+	// Until the build ran, queries still use the old model.
+	if e, _ := svc.QueryEmbedder(ctx, tenantID.String()); e.Model() != "old" {
+		t.Errorf("query model while building = %s, want old", e.Model())
+	}
+
+	queue.run(t, svc)
+	if e, _ := svc.QueryEmbedder(ctx, tenantID.String()); e.Model() != "new" {
+		t.Errorf("query model after the switch = %s, want new", e.Model())
+	}
+	for _, c := range repo.chunks {
+		if c.EmbeddingModel != "new" || len(c.Embedding) != 16 {
+			t.Errorf("chunk %q still embedded by %s (%d dims)", c.Content, c.EmbeddingModel, len(c.Embedding))
+		}
+	}
+	idx, _ := repo.FindByID(ctx, building.ID)
+	if idx.EmbeddedChunks != 3 || idx.TotalChunks != 3 {
+		t.Errorf("progress = %d/%d, want 3/3", idx.EmbeddedChunks, idx.TotalChunks)
+	}
+
+	// A chunk ingested after the switch is kept in the standby index too.
+	active, secondary, _ := svc.IngestTargets(ctx, tenantID)
+	if active.Index.Model != "new" || len(secondary) != 1 || secondary[0].Index.Status != domain.EmbeddingIndexStandby {
+		t.Fatalf("ingest targets = %+v, %+v", active.Index, secondary)
+	}
+
+	rolledBack, err := svc.Rollback(ctx, tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack.Model != "old" {
+		t.Errorf("rolled back to %s, want old", rolledBack.Model)
+	}
+	queue.run(t, svc)
+	for _, c := range repo.chunks {
+		if c.EmbeddingModel != "old" || len(c.Embedding) != 8 {
+			t.Errorf("chunk %q embedded by %s (%d dims) after rollback", c.Content, c.EmbeddingModel, len(c.Embedding))
+		}
+	}
+	if _, err := svc.Rollback(ctx, tenantID); err != nil {
+		t.Errorf("rolling forward again: %v", err)
+	}
+}
+
+func TestCancelEmbeddingIndex(t *testing.T) {
+	ctx := context.Background()
+	embedders, _ := ai.NewEmbedders(ctx, config.AIConfig{Embedding: config.EmbeddingConfig{
+		Backend:    config.EmbeddingBackendHash,
+		Additional: []config.EmbeddingConfig{{Backend: config.EmbeddingBackendHash, Model: "new", Dimension: 16}},
+	}})
+	repo := newMemIndexRepo()
+	queue := &memQueue{}
+	svc := NewEmbeddingIndexService(repo, embedders, queue)
+	tenantID := uuid.New()
+	if _, _, err := svc.IngestTargets(ctx, tenantID); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := svc.Start(ctx, tenantID, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Cancel(ctx, uuid.New(), idx.ID); !errors.Is(err, ErrIndexNotFound) {
+		t.Errorf("cancel from another tenant: err = %v", err)
+	}
+	if _, err := svc.Cancel(ctx, tenantID, idx.ID); err != nil {
+		t.Fatal(err)
+	}
+	queue.run(t, svc)
+	if e, _ := svc.QueryEmbedder(ctx, tenantID.String()); e.Model() != ai.DefaultHashEmbeddingModel {
+		t.Errorf("query model after cancel = %s", e.Model())
+	}
+	if _, err := svc.Rollback(ctx, tenantID); !errors.Is(err, ErrNoStandby) {
+		t.Errorf("rollback without standby: err = %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+	"github.com/google/uuid"
+)
+
+// MaxExpandedQueries bounds the reformulations searched per request.
+const MaxExpandedQueries = 5
+
+// ExpandQuery returns the queries to search: query itself first, followed by
+// up to n reformulations from the expander (n <= 0: its configured number,
+// never more than MaxExpandedQueries). If the expander fails, only query is
+// returned, together with the error.
+func ExpandQuery(ctx context.Context, expander ai.QueryExpander, query string, n int) ([]string, error) {
+	queries := []string{query}
+	if n > MaxExpandedQueries {
+		n = MaxExpandedQueries
+	}
+	expanded, err := expander.Expand(ctx, query, n)
+	if err != nil {
+		return queries, err
+	}
+	if len(expanded) > MaxExpandedQueries {
+		expanded = expanded[:MaxExpandedQueries]
+	}
+	return append(queries, expanded...), nil
+}
+
+// MultiSearch runs HybridSearch for every query in parallel, with params as
+// the template (QueryText, QueryEmbedding and EmbeddingModel are set per
+// query), and fuses the result lists (see fuseQueries). The first query is
+// the user's: its search must succeed, while failed reformulations are only
+// logged. With a single query this is a plain HybridSearch.
+func MultiSearch(ctx context.Context, docRepo domain.DocumentRepository, embedder ai.Embedder, params domain.HybridSearchParams, queries []string) ([]domain.HybridSearchResult, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no query to search")
+	}
+	embeddings, err := embedder.Embed(ctx, queries)
+	if err != nil {
+		return nil, fmt.Errorf("query embedding failed: %w", err)
+	}
+
+	lists := make([][]domain.HybridSearchResult, len(queries))
+	errs := make([]error, len(queries))
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q string) {
+			defer wg.Done()
+			p := params
+			p.QueryText, p.QueryEmbedding, p.EmbeddingModel = q, embeddings[i], embedder.Model()
+			lists[i], errs[i] = docRepo.HybridSearch(ctx, p)
+		}(i, q)
+	}
+	wg.Wait()
+
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+	if len(queries) == 1 {
+		return lists[0], nil
+	}
+	for i, err := range errs[1:] {
+		if err != nil {
+			log.Printf("[RAG] ⚠ search for reformulation %q failed: %v", queries[i+1], err)
+		}
+	}
+	return fuseQueries(lists, params.TopK), nil
+}
+
+// fuseQueries merges per-query result lists: a chunk's RRFScore becomes the
+// sum of its RRF scores over the queries that found it, so chunks several
+// reformulations agree on rise, while a chunk found by one query keeps its
+// score (and min_score thresholds keep their meaning). Ranks are those of
+// the first query that found the chunk.
+func fuseQueries(lists [][]domain.HybridSearchResult, topK int) []domain.HybridSearchResult {
+	index := map[uuid.UUID]int{}
+	var fused []domain.HybridSearchResult
+	for _, list := range lists {
+		for _, res := range list {
+			if i, ok := index[res.ChunkID]; ok {
+				fused[i].RRFScore += res.RRFScore
+				continue
+			}
+			index[res.ChunkID] = len(fused)
+			fused = append(fused, res)
+		}
+	}
+	// Stable: ties keep the order of the earlier queries.
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].RRFScore > fused[j].RRFScore })
+	if topK > 0 && len(fused) > topK {
+		fused = fused[:topK]
+	}
+	return fused
+}
@@ -0,0 +1,86 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+	"github.com/google/uuid"
+)
+
+// querySearchRepo answers HybridSearch with a fixed result list per query.
+type querySearchRepo struct {
+	domain.DocumentRepository
+	mu      sync.Mutex
+	results map[string][]domain.HybridSearchResult
+	params  []domain.HybridSearchParams
+}
+
+func (r *querySearchRepo) HybridSearch(ctx context.Context, params domain.HybridSearchParams) ([]domain.HybridSearchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.params = append(r.params, params)
+	results, ok := r.results[params.QueryText]
+	if !ok {
+		return nil, errors.New("search failed")
+	}
+	return results, nil
+}
+
+type staticExpander struct {
+	queries []string
+	err     error
+}
+
+func (s staticExpander) Expand(ctx context.Context, query string, n int) ([]string, error) {
+	return s.queries, s.err
+}
+
+func TestMultiSearchFusesQueries(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	repo := &querySearchRepo{results: map[string][]domain.HybridSearchResult{
+		"rasio kecukupan modal": {{ChunkID: a, RRFScore: 0.030}, {ChunkID: b, RRFScore: 0.020}},
+		"KPMM minimum":          {{ChunkID: b, RRFScore: 0.025}, {ChunkID: c, RRFScore: 0.010}},
+	}}
+	embedder := ai.NewHashEmbedder("", 8)
+
+	queries := []string{"rasio kecukupan modal", "KPMM minimum", "capital adequacy"}
+	results, err := MultiSearch(context.Background(), repo, embedder, domain.HybridSearchParams{TenantID: "t1", TopK: 2}, queries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ChunkID != b || results[1].ChunkID != a {
+		t.Fatalf("fused = %+v, want b (found by both queries) then a", results)
+	}
+	if results[0].RRFScore != 0.045 {
+		t.Errorf("fused score = %v, want 0.045", results[0].RRFScore)
+	}
+	for _, p := range repo.params {
+		if p.TenantID != "t1" || len(p.QueryEmbedding) != 8 || p.EmbeddingModel != embedder.Model() {
+			t.Errorf("search params = %+v", p)
+		}
+	}
+
+	if _, err := MultiSearch(context.Background(), repo, embedder, domain.HybridSearchParams{}, []string{"capital adequacy", "KPMM minimum"}); err == nil {
+		t.Error("failure of the original query's search was ignored")
+	}
+}
+
+func TestExpandQuery(t *testing.T) {
+	queries, err := ExpandQuery(context.Background(), staticExpander{queries: []string{"a", "b", "c", "d", "e", "f"}}, "q", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1+MaxExpandedQueries || queries[0] != "q" {
+		t.Errorf("queries = %q", queries)
+	}
+
+	failed := errors.New("expander down")
+	queries, err = ExpandQuery(context.Background(), staticExpander{err: failed}, "q", 3)
+	if !errors.Is(err, failed) || len(queries) != 1 || queries[0] != "q" {
+		t.Errorf("queries = %q, err = %v, want the query alone", queries, err)
+	}
+}
@@ -0,0 +1,75 @@
+package rag
+
+import (
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// ChunkDiff matches the chunks of a document's new text against its stored
+// chunks. Chunks are compared by content: an edit only changes the chunks of
+// the sections it touches, the others are found again unchanged.
+type ChunkDiff struct {
+	// Kept[i] is the stored chunk whose content and vector chunk i reuses,
+	// nil when chunk i is new and must be embedded.
+	Kept []*domain.DocumentChunk
+	// Removed lists the stored chunks no new chunk reuses.
+	Removed []uuid.UUID
+}
+
+// DiffChunks matches chunks against stored. A stored chunk is only reused by
+// a chunk of identical content, and only when its vector was computed by
+// model: vectors of another model would be unsearchable. When contents
+// repeat, stored chunks are reused in their original order.
+func DiffChunks(stored []domain.DocumentChunk, chunks []MarkdownChunk, model string) ChunkDiff {
+	byContent := make(map[string][]int)
+	for i, c := range stored {
+		if c.EmbeddingModel == model {
+			byContent[c.Content] = append(byContent[c.Content], i)
+		}
+	}
+
+	diff := ChunkDiff{Kept: make([]*domain.DocumentChunk, len(chunks))}
+	reused := make([]bool, len(stored))
+	for i, c := range chunks {
+		candidates := byContent[c.FullContent]
+		if len(candidates) == 0 {
+			continue
+		}
+		j := candidates[0]
+		byContent[c.FullContent] = candidates[1:]
+		reused[j] = true
+		diff.Kept[i] = &stored[j]
+	}
+	for j, c := range stored {
+		if !reused[j] {
+			diff.Removed = append(diff.Removed, c.ID)
+		}
+	}
+	return diff
+}
+
+// Embedded returns the indexes of the chunks that must be embedded.
+func (d ChunkDiff) Embedded() []int {
+	var idx []int
+	for i, kept := range d.Kept {
+		if kept == nil {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Changed reports whether applying the diff changes the stored chunks of
+// the document: a chunk is added, removed or moved.
+func (d ChunkDiff) Changed(chunks []MarkdownChunk, locations []ChunkLocation) bool {
+	if len(d.Removed) > 0 {
+		return true
+	}
+	for i, kept := range d.Kept {
+		if kept == nil || kept.ChunkIndex != chunks[i].Index ||
+			kept.CharStart != locations[i].CharStart || kept.CharEnd != locations[i].CharEnd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package rag
+
+import (
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestDiffChunks(t *testing.T) {
+	stored := []domain.DocumentChunk{
+		{ID: uuid.New(), Content: "Bab > cuti tahunan", ChunkIndex: 0, EmbeddingModel: "m1"},
+		{ID: uuid.New(), Content: "Bab > cuti sakit", ChunkIndex: 1, EmbeddingModel: "m1"},
+		{ID: uuid.New(), Content: "Bab > cuti besar", ChunkIndex: 2, EmbeddingModel: "m1"},
+		{ID: uuid.New(), Content: "Bab > cuti tahunan", ChunkIndex: 3, EmbeddingModel: "m1"},
+		{ID: uuid.New(), Content: "Bab > lampiran", ChunkIndex: 4, EmbeddingModel: "m0"},
+	}
+	chunks := []MarkdownChunk{
+		{FullContent: "Bab > cuti tahunan", Index: 0},
+		{FullContent: "Bab > cuti sakit diperbarui", Index: 1},
+		{FullContent: "Bab > cuti besar", Index: 2},
+		{FullContent: "Bab > lampiran", Index: 3},
+	}
+
+	diff := DiffChunks(stored, chunks, "m1")
+	if diff.Kept[0] == nil || diff.Kept[0].ID != stored[0].ID {
+		t.Errorf("first chunk not matched to the first of the repeated contents: %+v", diff.Kept[0])
+	}
+	if diff.Kept[2] == nil || diff.Kept[2].ID != stored[2].ID {
+		t.Errorf("unchanged chunk lost its ID: %+v", diff.Kept[2])
+	}
+	// Edited content, and content embedded by another model, are re-embedded.
+	if got := diff.Embedded(); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Errorf("embedded = %v, want [1 3]", got)
+	}
+	removed := map[uuid.UUID]bool{}
+	for _, id := range diff.Removed {
+		removed[id] = true
+	}
+	if len(removed) != 3 || !removed[stored[1].ID] || !removed[stored[3].ID] || !removed[stored[4].ID] {
+		t.Errorf("removed = %v", diff.Removed)
+	}
+	if !diff.Changed(chunks, make([]ChunkLocation, len(chunks))) {
+		t.Error("diff with removed chunks reported unchanged")
+	}
+}
+
+func TestDiffChunksUnchanged(t *testing.T) {
+	stored := []domain.DocumentChunk{
+		{ID: uuid.New(), Content: "a", ChunkIndex: 0, CharStart: 0, CharEnd: 1, EmbeddingModel: "m1"},
+		{ID: uuid.New(), Content: "b", ChunkIndex: 1, CharStart: 3, CharEnd: 4, EmbeddingModel: "m1"},
+	}
+	chunks := []MarkdownChunk{{FullContent: "a", Index: 0}, {FullContent: "b", Index: 1}}
+	locations := []ChunkLocation{{CharStart: 0, CharEnd: 1}, {CharStart: 3, CharEnd: 4}}
+
+	diff := DiffChunks(stored, chunks, "m1")
+	if len(diff.Embedded()) != 0 || len(diff.Removed) != 0 {
+		t.Fatalf("diff = %+v, want everything kept", diff)
+	}
+	if diff.Changed(chunks, locations) {
+		t.Error("identical text reported as changed")
+	}
+	// A paragraph added before "b" moves it: a new revision, nothing to embed.
+	locations[1] = ChunkLocation{CharStart: 10, CharEnd: 11}
+	if !diff.Changed(chunks, locations) {
+		t.Error("moved chunk not reported as changed")
+	}
+}
@@ -0,0 +1,67 @@
+package rag
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
+)
+
+const (
+	// DefaultRerankCandidates is how many fused results are rescored when
+	// the caller does not say.
+	DefaultRerankCandidates = 20
+	// MaxRerankCandidates bounds the cost of one rerank call.
+	MaxRerankCandidates = 100
+)
+
+// RerankCandidates returns how many fused results to fetch from
+// HybridSearch so that reranking can pick the best topK: the requested
+// number (DefaultRerankCandidates when not positive), at least topK and at
+// most MaxRerankCandidates.
+func RerankCandidates(requested, topK int) int {
+	n := requested
+	if n <= 0 {
+		n = DefaultRerankCandidates
+	}
+	if n < topK {
+		n = topK
+	}
+	if n > MaxRerankCandidates {
+		n = MaxRerankCandidates
+	}
+	return n
+}
+
+// Rerank rescores fused hybrid search results against the query and returns
+// the topK best by rerank score, keeping their RRF scores and ranks. If the
+// reranker fails, the fused order is kept: the topK fused results are
+// returned together with the error, so callers can degrade gracefully.
+func Rerank(ctx context.Context, reranker ai.Reranker, query string, results []domain.HybridSearchResult, topK int) ([]domain.HybridSearchResult, error) {
+	if topK <= 0 || topK > len(results) {
+		topK = len(results)
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	documents := make([]string, len(results))
+	for i, r := range results {
+		documents[i] = r.Content
+	}
+	scores, err := reranker.Rerank(ctx, query, documents)
+	if err != nil {
+		return results[:topK], err
+	}
+
+	reranked := make([]domain.HybridSearchResult, len(results))
+	copy(reranked, results)
+	for i := range reranked {
+		score := scores[i]
+		reranked[i].RerankScore = &score
+	}
+	// Stable: ties keep the fused order.
+	sort.SliceStable(reranked, func(i, j int) bool { return *reranked[i].RerankScore > *reranked[j].RerankScore })
+	return reranked[:topK], nil
+}
@@ -0,0 +1,72 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// keywordReranker scores a document by how often it mentions the query.
+type keywordReranker struct{ err error }
+
+func (k keywordReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+	scores := make([]float64, len(documents))
+	for i, d := range documents {
+		scores[i] = float64(strings.Count(d, query))
+	}
+	return scores, nil
+}
+
+func (k keywordReranker) Model() string { return "keyword" }
+
+func TestRerank(t *testing.T) {
+	fused := []domain.HybridSearchResult{
+		{Content: "ketentuan umum", RRFScore: 0.032},
+		{Content: "modal inti minimum; modal inti dihitung", RRFScore: 0.031},
+		{Content: "modal inti", RRFScore: 0.030},
+		{Content: "lain-lain", RRFScore: 0.029},
+	}
+
+	got, err := Rerank(context.Background(), keywordReranker{}, "modal inti", fused, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].RRFScore != 0.031 || got[1].RRFScore != 0.030 {
+		t.Fatalf("reranked = %+v", got)
+	}
+	if got[0].RerankScore == nil || *got[0].RerankScore != 2 {
+		t.Errorf("rerank score = %v, want 2", got[0].RerankScore)
+	}
+	if fused[0].RerankScore != nil {
+		t.Error("input results were modified")
+	}
+
+	failed := errors.New("reranker down")
+	got, err = Rerank(context.Background(), keywordReranker{err: failed}, "modal inti", fused, 3)
+	if !errors.Is(err, failed) {
+		t.Errorf("err = %v", err)
+	}
+	if len(got) != 3 || got[0].RRFScore != 0.032 || got[0].RerankScore != nil {
+		t.Errorf("fallback = %+v, want the fused top 3", got)
+	}
+}
+
+func TestRerankCandidates(t *testing.T) {
+	cases := []struct{ requested, topK, want int }{
+		{0, 5, DefaultRerankCandidates},
+		{10, 5, 10},
+		{3, 5, 5},
+		{500, 5, MaxRerankCandidates},
+	}
+	for _, c := range cases {
+		if got := RerankCandidates(c.requested, c.topK); got != c.want {
+			t.Errorf("RerankCandidates(%d, %d) = %d, want %d", c.requested, c.topK, got, c.want)
+		}
+	}
+}
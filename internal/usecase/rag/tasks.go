@@ -8,13 +8,12 @@ import (
 	"os"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/parsing"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
-	"github.com/google/generative-ai-go/genai"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
-	"google.golang.org/api/option"
 )
 
 // Task names
@@ -79,26 +78,29 @@ func NewEmbedDocumentTask(documentID, tenantID string, category string) (*asynq.
 
 // DocumentTaskHandler is the concrete Asynq handler for the split RAG pipeline.
 type DocumentTaskHandler struct {
-	docRepo      domain.DocumentRepository
-	s3           *storage.S3Service
-	parser       *parsing.DocumentParser
-	geminiAPIKey string
-	mongoClient  *database.MongoClient
+	docRepo     domain.DocumentRepository
+	s3          *storage.S3Service
+	parser      *parsing.DocumentParser
+	indexes     *EmbeddingIndexService // nil when no embedding backend is configured
+	mongoClient *database.MongoClient
+	chunking    *ChunkingPolicy
 }
 
 func NewDocumentTaskHandler(
 	docRepo domain.DocumentRepository,
 	s3 *storage.S3Service,
 	parser *parsing.DocumentParser,
-	geminiAPIKey string,
+	indexes *EmbeddingIndexService,
 	mongoClient *database.MongoClient,
+	chunking *ChunkingPolicy,
 ) *DocumentTaskHandler {
 	return &DocumentTaskHandler{
-		docRepo:      docRepo,
-		s3:           s3,
-		parser:       parser,
-		geminiAPIKey: geminiAPIKey,
-		mongoClient:  mongoClient,
+		docRepo:     docRepo,
+		s3:          s3,
+		parser:      parser,
+		indexes:     indexes,
+		mongoClient: mongoClient,
+		chunking:    chunking,
 	}
 }
 
@@ -125,8 +127,8 @@ func (h *DocumentTaskHandler) HandleParseDocument(ctx context.Context, t *asynq.
 	}
 	defer os.Remove(localPath)
 
-	// 3. Extract text
-	rawText, err := h.parser.ExtractText(ctx, localPath)
+	// 3. Extract text, with the page layout it came from
+	parsed, err := h.parser.Parse(ctx, localPath)
 	if err != nil {
 		h.failDoc(ctx, docID, "text extraction failed: "+err.Error())
 		return fmt.Errorf("text extraction failed: %w", err)
@@ -144,7 +146,8 @@ func (h *DocumentTaskHandler) HandleParseDocument(ctx context.Context, t *asynq.
 		ID:        payload.DocumentID,
 		TenantID:  payload.TenantID,
 		FileName:  doc.Title,
-		RawText:   rawText,
+		RawText:   parsed.Text,
+		Layout:    parsed.Elements,
 		Status:    database.StatusPendingQA,
 	}
 	if err := h.mongoClient.SaveDocument(ctx, stagingDoc); err != nil {
@@ -154,7 +157,7 @@ func (h *DocumentTaskHandler) HandleParseDocument(ctx context.Context, t *asynq.
 
 	// 5. Update status to pending_qa in PostgreSQL with non-heavy metadata
 	metadata := map[string]interface{}{
-		"parser": "docling",
+		"parser": parsed.Parser,
 	}
 	if err := h.docRepo.UpdateStatus(ctx, docID, "pending_qa", metadata); err != nil {
 		return fmt.Errorf("failed to mark document pending_qa: %w", err)
@@ -164,7 +167,8 @@ func (h *DocumentTaskHandler) HandleParseDocument(ctx context.Context, t *asynq.
 	return nil
 }
 
-// HandleEmbedDocument performs Step 2: Retrieve Extracted Text -> Chunk -> Gemini Embed -> Store chunks (ready)
+// HandleEmbedDocument performs Step 2: Retrieve Extracted Text -> Chunk -> Embed new chunks -> Sync chunks (ready).
+// Re-running it after the text of a ready document was edited re-indexes it incrementally.
 func (h *DocumentTaskHandler) HandleEmbedDocument(ctx context.Context, t *asynq.Task) error {
 	var payload EmbedDocumentPayload
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
@@ -202,91 +206,185 @@ func (h *DocumentTaskHandler) HandleEmbedDocument(ctx context.Context, t *asynq.
 		metadata = make(map[string]interface{})
 	}
 
-	// 4. Markdown-Header-Aware Chunking
-	mdChunks := MarkdownAwareChunker(extractedText, 1000)
-	log.Printf("[RAG-Worker] ✂ %d semantic chunks from Document %s", len(mdChunks), payload.DocumentID)
+	// 4. Markdown-Header-Aware Chunking with the tenant's (or category's) strategy
+	settings, err := h.docRepo.TenantSettings(ctx, tenantID)
+	if err != nil {
+		log.Printf("[RAG-Worker] ⚠ tenant settings unavailable, using defaults: %v", err)
+	}
+	chunkOpts := h.chunking.Options(settings, payload.Category)
+	chunker, err := NewChunker(chunkOpts)
+	if err != nil {
+		log.Printf("[RAG-Worker] ⚠ invalid chunking setting, using defaults: %v", err)
+		chunkOpts = ChunkingDefaults(domain.ChunkingOptions{})
+		chunker, _ = NewChunker(chunkOpts)
+	}
+	mdChunks := chunker.Chunk(extractedText)
+	log.Printf("[RAG-Worker] ✂ %d semantic chunks from Document %s (%s, max %d)", len(mdChunks), payload.DocumentID, chunkOpts.Strategy, chunkOpts.MaxSize)
 
 	if len(mdChunks) == 0 {
 		h.failDoc(ctx, docID, "chunker produced no output")
 		return fmt.Errorf("chunker produced no output: %w", asynq.SkipRetry)
 	}
 
-	// 5. Batch embed via Gemini
-	fullTexts := make([]string, len(mdChunks))
-	for i, c := range mdChunks {
-		fullTexts[i] = c.FullContent
+	// 5. Batch embed with the model of the tenant's active embedding index
+	if h.indexes == nil {
+		h.failDoc(ctx, docID, "no embedding backend configured")
+		return fmt.Errorf("no embedding backend configured: %w", asynq.SkipRetry)
 	}
+	active, secondary, err := h.indexes.IngestTargets(ctx, tenantID)
+	if err != nil {
+		h.failDoc(ctx, docID, "no usable embedding index: "+err.Error())
+		return fmt.Errorf("no usable embedding index: %w", err)
+	}
+	embedder := active.Embedder
 
-	embeddings, err := h.batchEmbed(ctx, fullTexts)
+	// 6. Diff against the chunks of the previous revision: unchanged chunks
+	// keep their ID and vector, only new content is embedded.
+	stored, err := h.docRepo.ListChunks(ctx, docID)
 	if err != nil {
-		h.failDoc(ctx, docID, "Gemini embedding failed: "+err.Error())
-		return fmt.Errorf("Gemini embedding failed: %w", err)
+		h.failDoc(ctx, docID, "failed to list stored chunks: "+err.Error())
+		return fmt.Errorf("failed to list stored chunks: %w", err)
+	}
+	diff := DiffChunks(stored, mdChunks, embedder.Model())
+	embedIdx := diff.Embedded()
+	fullTexts := make([]string, len(embedIdx))
+	for i, idx := range embedIdx {
+		fullTexts[i] = mdChunks[idx].FullContent
 	}
 
-	// 6. Build DocumentChunk slice
-	var docChunks []domain.DocumentChunk
-	for i, mdChunk := range mdChunks {
-		approxPageNum := (i / 3) + 1
+	var embeddings [][]float32
+	if len(fullTexts) > 0 {
+		embeddings, err = embedder.Embed(ctx, fullTexts)
+		if err != nil {
+			h.failDoc(ctx, docID, "embedding failed: "+err.Error())
+			return fmt.Errorf("embedding failed: %w", err)
+		}
+	}
 
-		docChunks = append(docChunks, domain.DocumentChunk{
-			TenantID:   tenantID,
-			DocumentID: docID,
-			Content:    mdChunk.FullContent,
-			Embedding:  embeddings[i],
-			ChunkIndex: mdChunk.Index,
-			PageNumber: approxPageNum,
-			Category:   payload.Category,
-		})
+	// 7. Build the new revision, locating each chunk in the source file
+	language := documentLanguage(doc, settings)
+	locations := LocateChunks(extractedText, stagingDoc.Layout, mdChunks)
+	revision := doc.Revision
+	if revision < 1 {
+		revision = 1
+	}
+	if len(stored) > 0 && diff.Changed(mdChunks, locations) {
+		revision++
+	}
+	sync := domain.ChunkSync{DocumentID: docID, Revision: revision, Removed: diff.Removed}
+	for i, mdChunk := range mdChunks {
+		loc := locations[i]
+
+		chunk := domain.DocumentChunk{
+			ID:             uuid.New(),
+			TenantID:       tenantID,
+			DocumentID:     docID,
+			Content:        mdChunk.FullContent,
+			ChunkIndex:     mdChunk.Index,
+			PageNumber:     loc.PageStart,
+			PageEnd:        loc.PageEnd,
+			HeaderPath:     mdChunk.HeaderPath,
+			CharStart:      loc.CharStart,
+			CharEnd:        loc.CharEnd,
+			Regions:        loc.RegionsJSON(),
+			Category:       payload.Category,
+			EmbeddingModel: embedder.Model(),
+			EmbeddingDim:   embedder.Dimension(),
+			Language:       language,
+			Revision:       revision,
+		}
+		if kept := diff.Kept[i]; kept != nil {
+			chunk.ID = kept.ID
+			sync.Kept = append(sync.Kept, chunk)
+			continue
+		}
+		chunk.Embedding = embeddings[len(sync.Added)]
+		sync.Added = append(sync.Added, chunk)
 	}
 
-	// 7. Atomic batch insert
-	if err := h.docRepo.StoreChunks(ctx, docChunks); err != nil {
-		h.failDoc(ctx, docID, "atomic chunk insert failed: "+err.Error())
-		return fmt.Errorf("atomic chunk insert failed: %w", err)
+	// 8. Atomically delete stale, reposition kept and insert new chunks
+	if err := h.docRepo.SyncChunks(ctx, sync); err != nil {
+		h.failDoc(ctx, docID, "atomic chunk sync failed: "+err.Error())
+		return fmt.Errorf("atomic chunk sync failed: %w", err)
 	}
+	h.storeSecondary(ctx, secondary, fullTexts, sync.Added)
 
-	// 8. Update status to ready, preserving metadata but removing extracted_text to save DB storage space
+	// 9. Update status to ready, preserving metadata but removing extracted_text to save DB storage space
 	delete(metadata, "extracted_text")
-	metadata["chunks_count"] = len(docChunks)
-	metadata["model"] = "text-embedding-004"
-	metadata["parser"] = "docling"
+	metadata["chunks_count"] = len(mdChunks)
+	metadata["model"] = embedder.Model()
+	metadata["embedding_dim"] = embedder.Dimension()
+	metadata["language"] = language
+	metadata["chunking"] = chunkOpts
+	metadata["revision"] = revision
+	metadata["reindex"] = map[string]int{
+		"embedded": len(sync.Added),
+		"kept":     len(sync.Kept),
+		"removed":  len(sync.Removed),
+	}
 
-	if err := h.docRepo.UpdateStatus(ctx, docID, "ready", metadata); err != nil {
+	// 10. A ready version replaces the older versions of its record in searches.
+	// On failure neither happened; the retry finds the chunks already synced.
+	if err := h.docRepo.MarkReady(ctx, doc, metadata); err != nil {
 		return fmt.Errorf("failed to mark document ready: %w", err)
 	}
 
-	log.Printf("[RAG-Worker] ✅ Document %s ready: %d chunks", payload.DocumentID, len(docChunks))
+	log.Printf("[RAG-Worker] ✅ Document %s ready at revision %d: %d chunks (%d embedded, %d kept, %d removed)",
+		payload.DocumentID, revision, len(mdChunks), len(sync.Added), len(sync.Kept), len(sync.Removed))
+	h.warnMixedModels(ctx, payload.TenantID, embedder)
 	return nil
 }
 
-// failDoc is a helper that logs and marks the document as failed with an error reason.
-func (h *DocumentTaskHandler) failDoc(ctx context.Context, docID uuid.UUID, reason string) {
-	log.Printf("[RAG-Worker] ❌ Document %s failed: %s", docID, reason)
-	_ = h.docRepo.UpdateStatus(ctx, docID, "failed", map[string]interface{}{"error": reason})
+// documentLanguage resolves the full-text search language of a document's
+// chunks: the document's own, else its tenant's search_language, else the default.
+func documentLanguage(doc *domain.Document, settings domain.TenantSettings) string {
+	if domain.ValidFTSLanguage(doc.Language) {
+		return doc.Language
+	}
+	if domain.ValidFTSLanguage(settings.SearchLanguage) {
+		return settings.SearchLanguage
+	}
+	return domain.DefaultFTSLanguage
 }
 
-// batchEmbed sends all chunk texts to Gemini text-embedding-004 in a single batch call.
-func (h *DocumentTaskHandler) batchEmbed(ctx context.Context, texts []string) ([][]float32, error) {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(h.geminiAPIKey))
-	if err != nil {
-		return nil, fmt.Errorf("Gemini client init failed: %w", err)
+// storeSecondary embeds new chunks into the tenant's BUILDING and STANDBY
+// indexes. Failures only cost the builder some work later.
+func (h *DocumentTaskHandler) storeSecondary(ctx context.Context, secondary []IndexEmbedder, texts []string, chunks []domain.DocumentChunk) {
+	for _, target := range secondary {
+		embeddings, err := target.Embedder.Embed(ctx, texts)
+		if err == nil {
+			vectors := make([]domain.ChunkVector, len(chunks))
+			for i, c := range chunks {
+				vectors[i] = domain.ChunkVector{ChunkID: c.ID, Embedding: embeddings[i]}
+			}
+			err = h.indexes.repo.StoreVectors(ctx, target.Index, vectors)
+		}
+		if err != nil {
+			log.Printf("[RAG-Worker] ⚠ %s embedding index %s not updated: %v", target.Index.Status, target.Index.ID, err)
+		}
 	}
-	defer client.Close()
+}
 
-	em := client.EmbeddingModel("text-embedding-004")
-	batch := em.NewBatch()
-	for _, text := range texts {
-		batch.AddContent(genai.Text(text))
+// warnMixedModels logs when a tenant's corpus holds chunks of more than one
+// embedding model. Semantic search only ranks chunks of the current model,
+// so documents embedded by another one are invisible to it until re-embedded.
+func (h *DocumentTaskHandler) warnMixedModels(ctx context.Context, tenantID string, embedder ai.Embedder) {
+	usage, err := h.docRepo.EmbeddingModels(ctx, tenantID)
+	if err != nil || len(usage) < 2 {
+		return
 	}
-
-	resp, err := em.BatchEmbedContents(ctx, batch)
-	if err != nil {
-		return nil, fmt.Errorf("Gemini BatchEmbedContents failed: %w", err)
+	for _, u := range usage {
+		if u.Model != embedder.Model() || u.Dimension != embedder.Dimension() {
+			log.Printf("[RAG-Worker] ⚠ Tenant %s mixes embedding models: %d chunks use %q (%d dims), current model is %q (%d dims)",
+				tenantID, u.Chunks, u.Model, u.Dimension, embedder.Model(), embedder.Dimension())
+		}
 	}
+}
 
-	embeddings := make([][]float32, len(resp.Embeddings))
-	for i, e := range resp.Embeddings {
-		embeddings[i] = e.Values
-	}
-	return embeddings, nil
+// failDoc is a helper that logs and marks the document as failed with an error reason.
+// A failed re-index leaves the previous revision's chunks searchable; editing
+// the text again re-indexes the document.
+func (h *DocumentTaskHandler) failDoc(ctx context.Context, docID uuid.UUID, reason string) {
+	log.Printf("[RAG-Worker] ❌ Document %s failed: %s", docID, reason)
+	_ = h.docRepo.UpdateStatus(ctx, docID, "failed", map[string]interface{}{"error": reason})
 }
@@ -0,0 +1,293 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin     = 15.0
+	pdfLineHeight = 5.0
+)
+
+// findingColumns are the per-item table columns; widths add up to the
+// printable width of an A4 portrait page.
+var findingColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"No", 8, "C"},
+	{"Item", 50, "L"},
+	{"Penyedia", 30, "L"},
+	{"Volume", 16, "R"},
+	{"Harga Satuan", 22, "R"},
+	{"Harga Ref.", 22, "R"},
+	{"Deviasi", 14, "R"},
+	{"Verdict", 18, "C"},
+}
+
+// RenderPDF renders the report as an A4 PDF document.
+func RenderPDF(r *AuditReport) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+5)
+	pdf.AliasNbPages("{nb}")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("Dibuat %s — Task %s", formatTime(r.GeneratedAt), r.Task.ID)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("Halaman %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	w := pdfPrintableWidth(pdf)
+	writeHeader(pdf, tr, r, w)
+
+	section(pdf, tr, "1. Informasi Dokumen")
+	keyValues(pdf, tr, [][2]string{
+		{"Judul Dokumen", orDash(r.Document.Title)},
+		{"ID Dokumen", orDash(r.Document.ID)},
+		{"Kategori", orDash(r.Document.Category)},
+		{"ID Task", orDash(r.Task.ID)},
+		{"Status Task", orDash(r.Task.Status)},
+		{"Model", orDash(r.Task.Model)},
+		{"Task Induk", orDash(r.Task.ParentTaskID)},
+		{"Dianalisis", formatTime(r.Task.CreatedAt)},
+		{"Selesai", formatTime(r.Task.UpdatedAt)},
+	})
+
+	section(pdf, tr, "2. Ringkasan Konsensus Agen")
+	paragraph(pdf, tr, orDash(r.Task.Summary))
+
+	section(pdf, tr, "3. Temuan per Item")
+	if len(r.Items) == 0 {
+		paragraph(pdf, tr, "Tidak ada temuan.")
+	} else {
+		findingsTable(pdf, tr, r.Items)
+		rationales(pdf, tr, r.Items, w)
+	}
+
+	section(pdf, tr, "4. Keputusan Reviewer")
+	if r.Review == nil {
+		paragraph(pdf, tr, "Belum ada keputusan reviewer; verdict mesin berlaku.")
+	} else {
+		keyValues(pdf, tr, [][2]string{
+			{"Verdict Reviewer", r.Review.Verdict},
+			{"Reviewer", orDash(r.Review.ReviewerID)},
+			{"Tanggal", formatTime(r.Review.ReviewedAt)},
+			{"Tx Koreksi", orDash(r.Review.TxHash)},
+			{"Status Koreksi", orDash(r.Review.Status)},
+		})
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(0, pdfLineHeight, "Justifikasi", "", 1, "L", false, 0, "")
+		paragraph(pdf, tr, r.Review.Justification)
+	}
+
+	section(pdf, tr, "5. Bukti Anchoring Blockchain")
+	keyValues(pdf, tr, [][2]string{
+		{"Jaringan", orDash(r.Anchor.Network)},
+		{"Kontrak", orDash(r.Anchor.Contract)},
+		{"Tx Hash", orDash(r.Anchor.TxHash)},
+		{"Blok", orDash(r.Anchor.BlockNumber)},
+		{"Waktu Blok", orDash(r.Anchor.Timestamp)},
+		{"Status Anchoring", orDash(r.Anchor.Status)},
+		{"Rationale Hash", orDash(r.Anchor.RationaleHash)},
+		{"Consensus Hash", orDash(r.Anchor.ConsensusHash)},
+	})
+	if r.Anchor.BatchID != "" {
+		keyValues(pdf, tr, [][2]string{
+			{"Batch Anchoring", r.Anchor.BatchID},
+			{"Merkle Root", orDash(r.Anchor.MerkleRoot)},
+		})
+	}
+	keyValues(pdf, tr, [][2]string{
+		{"Verifikasi", r.Anchor.verificationLabel()},
+	})
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.MultiCell(0, 4, tr("Hash dihitung dengan SHA-256 atas serialisasi JCS (RFC 8785) dari hasil dan transkrip agen. "+
+		"Siapa pun dapat menghitung ulang hash tersebut dan membandingkannya dengan log aktif task di kontrak audit trail."), "", "L", false)
+	pdf.SetTextColor(0, 0, 0)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func pdfPrintableWidth(pdf *fpdf.Fpdf) float64 {
+	pageW, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	return pageW - left - right
+}
+
+func writeHeader(pdf *fpdf.Fpdf, tr func(string) string, r *AuditReport, w float64) {
+	textX := pdfMargin
+	if len(r.Branding.Logo) > 0 {
+		opts := fpdf.ImageOptions{ImageType: r.Branding.LogoType, ReadDpi: true}
+		pdf.RegisterImageOptionsReader("tenant-logo", opts, bytes.NewReader(r.Branding.Logo))
+		if pdf.Err() {
+			// A broken logo must not prevent the report; continue without it.
+			pdf.ClearError()
+		} else {
+			pdf.ImageOptions("tenant-logo", pdfMargin, pdfMargin, 0, 18, false, opts, 0, "")
+			textX = pdfMargin + 24
+		}
+	}
+
+	pdf.SetXY(textX, pdfMargin)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 7, tr(orDash(r.Branding.Name)), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, tr("Laporan Audit Anggaran — Analisis Swarm AI"), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.CellFormat(0, 5, tr("Dibuat "+formatTime(r.GeneratedAt)), "", 1, "L", false, 0, "")
+
+	y := pdfMargin + 20
+	pdf.SetLineWidth(0.4)
+	pdf.Line(pdfMargin, y, pdfMargin+w, y)
+	pdf.SetLineWidth(0.2)
+	pdf.SetY(y + 2)
+}
+
+func section(pdf *fpdf.Fpdf, tr func(string) string, title string) {
+	pdf.Ln(3)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.SetFillColor(230, 236, 245)
+	pdf.CellFormat(0, 7, tr(title), "", 1, "L", true, 0, "")
+	pdf.Ln(1)
+}
+
+func paragraph(pdf *fpdf.Fpdf, tr func(string) string, text string) {
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.MultiCell(0, pdfLineHeight, tr(text), "", "L", false)
+}
+
+func keyValues(pdf *fpdf.Fpdf, tr func(string) string, rows [][2]string) {
+	for _, kv := range rows {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(40, pdfLineHeight, tr(kv[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, pdfLineHeight, tr(kv[1]), "", "L", false)
+	}
+}
+
+func findingsTable(pdf *fpdf.Fpdf, tr func(string) string, items []ItemFinding) {
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetFillColor(40, 60, 90)
+		pdf.SetTextColor(255, 255, 255)
+		for _, col := range findingColumns {
+			pdf.CellFormat(col.width, 6, tr(col.title), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetTextColor(0, 0, 0)
+	}
+	header()
+
+	_, pageH := pdf.GetPageSize()
+	for _, item := range items {
+		name := item.ItemName
+		if item.ItemCode != "" {
+			name = item.ItemCode + " — " + name
+		}
+		cells := []string{
+			fmt.Sprintf("%d", item.Index+1),
+			orDash(name),
+			orDash(item.Supplier),
+			formatQuantity(item),
+			formatNumber(item.UnitPrice, 0),
+			formatNumber(item.ReferencePrice, 0),
+			formatDeviation(item.DeviationPct),
+			orDash(item.Verdict),
+		}
+
+		pdf.SetFont("Helvetica", "", 8)
+		// SplitLines works on the translated single-byte text; SplitText
+		// expects UTF-8 and cannot be used after translation.
+		lines := make([][][]byte, len(cells))
+		maxLines := 1
+		for i, text := range cells {
+			lines[i] = pdf.SplitLines([]byte(tr(text)), findingColumns[i].width-2)
+			if len(lines[i]) > maxLines {
+				maxLines = len(lines[i])
+			}
+		}
+		rowH := float64(maxLines) * 4
+
+		if pdf.GetY()+rowH > pageH-pdfMargin-5 {
+			pdf.AddPage()
+			header()
+			pdf.SetFont("Helvetica", "", 8)
+		}
+
+		x, y := pdf.GetX(), pdf.GetY()
+		for i, col := range findingColumns {
+			pdf.Rect(x, y, col.width, rowH, "D")
+			for j, line := range lines[i] {
+				pdf.SetXY(x+1, y+float64(j)*4)
+				pdf.CellFormat(col.width-2, 4, string(line), "", 0, col.align, false, 0, "")
+			}
+			x += col.width
+		}
+		pdf.SetXY(pdfMargin, y+rowH)
+	}
+}
+
+func rationales(pdf *fpdf.Fpdf, tr func(string) string, items []ItemFinding, w float64) {
+	pdf.Ln(3)
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(0, pdfLineHeight, "Rasional Agen", "", 1, "L", false, 0, "")
+
+	for _, item := range items {
+		if item.Rationale == "" && len(item.AgentVotes) == 0 {
+			continue
+		}
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.MultiCell(w, 4, tr(fmt.Sprintf("#%d %s (%s)", item.Index+1, orDash(item.ItemName), orDash(item.Verdict))), "", "L", false)
+		pdf.SetFont("Helvetica", "", 8)
+		if item.Rationale != "" {
+			pdf.MultiCell(w, 4, tr(item.Rationale), "", "L", false)
+		}
+		for _, v := range item.AgentVotes {
+			line := "• " + orDash(v.Agent) + ": " + orDash(v.Verdict)
+			if v.Reason != "" {
+				line += " — " + v.Reason
+			}
+			pdf.SetX(pdfMargin + 4)
+			pdf.MultiCell(w-4, 4, tr(line), "", "L", false)
+		}
+		pdf.Ln(1)
+	}
+}
+
+func formatQuantity(item ItemFinding) string {
+	if item.Quantity == nil {
+		return "-"
+	}
+	q := strings.TrimSuffix(strings.TrimRight(formatNumber(item.Quantity, 2), "0"), ",")
+	if item.Unit != "" {
+		q += " " + item.Unit
+	}
+	return q
+}
+
+func formatDeviation(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	s := formatNumber(v, 2) + "%"
+	if *v > 0 {
+		s = "+" + s
+	}
+	return s
+}
@@ -0,0 +1,221 @@
+// Package report renders swarm audit reports as PDF and XLSX documents.
+// Rendering is pure Go and needs no network access; LoadLogo only reads
+// data URIs and the tenant's own storage.
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+const (
+	FormatPDF  = "pdf"
+	FormatXLSX = "xlsx"
+
+	maxLogoSize = 2 << 20
+)
+
+// AuditReport is everything printed in a swarm audit report.
+type AuditReport struct {
+	GeneratedAt time.Time
+	Branding    Branding
+	Document    DocumentInfo
+	Task        TaskInfo
+	Items       []ItemFinding
+	Review      *ReviewDecision
+	Anchor      AnchorProof
+}
+
+// Branding identifies the tenant issuing the report.
+type Branding struct {
+	Name string
+	// Logo is PNG or JPEG image data; LogoType is "PNG" or "JPG".
+	Logo     []byte
+	LogoType string
+}
+
+type DocumentInfo struct {
+	ID        string
+	Title     string
+	Category  string
+	CreatedAt time.Time
+}
+
+type TaskInfo struct {
+	ID           string
+	Status       string
+	Model        string
+	ParentTaskID string
+	Summary      string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ItemFinding is one line item with the swarm's verdict on it.
+type ItemFinding struct {
+	Index          int
+	ItemCode       string
+	ItemName       string
+	Supplier       string
+	Unit           string
+	Quantity       *float64
+	UnitPrice      *float64
+	ReferencePrice *float64
+	DeviationPct   *float64
+	Verdict        string
+	Rationale      string
+	AgentVotes     []AgentVote
+}
+
+type AgentVote struct {
+	Agent   string
+	Verdict string
+	Reason  string
+}
+
+// ReviewDecision is a human reviewer's override of the machine verdict.
+type ReviewDecision struct {
+	Verdict       string
+	Justification string
+	ReviewerID    string
+	ReviewedAt    time.Time
+	TxHash        string
+	Status        string
+}
+
+// AnchorProof describes how the result is anchored on-chain.
+type AnchorProof struct {
+	Network       string
+	Contract      string
+	TxHash        string
+	BlockNumber   string
+	Timestamp     string
+	Status        string
+	RationaleHash string
+	ConsensusHash string
+	// BatchID and MerkleRoot are set when the task was anchored as a leaf of
+	// a Merkle batch rather than with its own log.
+	BatchID    string
+	MerkleRoot string
+	// Verified is nil when the chain could not be queried.
+	Verified    *bool
+	VerifyError string
+}
+
+// LogoStore reads objects from the tenant object storage.
+type LogoStore interface {
+	// ReadObject returns the object's content, failing for objects larger
+	// than maxSize bytes.
+	ReadObject(ctx context.Context, objectKey string, maxSize int64) ([]byte, error)
+}
+
+// LoadLogo resolves a tenant logo given as a data URI or as the key of an
+// object in the tenant's storage. Remote URLs are not fetched. Only PNG and
+// JPEG are supported.
+func LoadLogo(ctx context.Context, store LogoStore, tenantID, src string) ([]byte, string, error) {
+	src = strings.TrimSpace(src)
+	var data []byte
+
+	switch {
+	case strings.HasPrefix(src, "data:"):
+		meta, payload, ok := strings.Cut(src, ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return nil, "", fmt.Errorf("logo data URI must be base64 encoded")
+		}
+		if base64.StdEncoding.DecodedLen(len(payload)) > maxLogoSize {
+			return nil, "", fmt.Errorf("logo exceeds %d bytes", maxLogoSize)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode logo: %w", err)
+		}
+		data = decoded
+	case domain.IsTenantObjectKey(tenantID, src):
+		if store == nil {
+			return nil, "", fmt.Errorf("object storage not configured")
+		}
+		object, err := store.ReadObject(ctx, src, maxLogoSize)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read logo: %w", err)
+		}
+		data = object
+	default:
+		return nil, "", fmt.Errorf("unsupported logo source: must be a data URI or an object key under the tenant's storage")
+	}
+
+	imageType := detectImageType(data)
+	if imageType == "" {
+		return nil, "", fmt.Errorf("logo must be PNG or JPEG")
+	}
+	return data, imageType, nil
+}
+
+func detectImageType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "PNG"
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8, 0xff}):
+		return "JPG"
+	}
+	return ""
+}
+
+// verificationLabel renders the on-chain verification state.
+func (a AnchorProof) verificationLabel() string {
+	switch {
+	case a.Verified == nil && a.VerifyError != "":
+		return "Tidak dapat diverifikasi: " + a.VerifyError
+	case a.Verified == nil:
+		return "Belum diverifikasi"
+	case *a.Verified:
+		return "TERVERIFIKASI — hash on-chain cocok dengan data laporan"
+	default:
+		return "TIDAK COCOK — hash on-chain berbeda dengan data laporan"
+	}
+}
+
+func formatNumber(v *float64, decimals int) string {
+	if v == nil {
+		return "-"
+	}
+	whole := fmt.Sprintf("%.*f", decimals, *v)
+	intPart, frac, _ := strings.Cut(whole, ".")
+	neg := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteByte(',')
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("02-01-2006 15:04 MST")
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,155 @@
+package report_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/report"
+	"github.com/xuri/excelize/v2"
+)
+
+func sampleReport(t *testing.T) *report.AuditReport {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var logo bytes.Buffer
+	if err := png.Encode(&logo, img); err != nil {
+		t.Fatal(err)
+	}
+
+	price, ref, dev, qty := 27500000.0, 15000000.0, 83.33, 2.0
+	verified := true
+	return &report.AuditReport{
+		GeneratedAt: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Branding:    report.Branding{Name: "Inspektorat Kota Bandung", Logo: logo.Bytes(), LogoType: "PNG"},
+		Document:    report.DocumentInfo{ID: "doc-1", Title: "RAPBD 2026 — Dinas Pendidikan", Category: "budget"},
+		Task:        report.TaskInfo{ID: "task-1", Status: "COMPLETED", Summary: "Satu item terindikasi mark-up."},
+		Items: []report.ItemFinding{{
+			Index: 0, ItemCode: "1.1.7", ItemName: "Laptop Core i7", Supplier: "PT Maju", Unit: "unit",
+			Quantity: &qty, UnitPrice: &price, ReferencePrice: &ref, DeviationPct: &dev,
+			Verdict: "MARKUP", Rationale: "Harga melebihi SSH 2026.",
+			AgentVotes: []report.AgentVote{{Agent: "auditor", Verdict: "MARKUP", Reason: "selisih 83%"}},
+		}},
+		Review: &report.ReviewDecision{Verdict: "OK", Justification: "Spesifikasi berbeda dari katalog.", ReviewerID: "user-1"},
+		Anchor: report.AnchorProof{Network: "sepolia", TxHash: "0xabc", Status: "VERIFIED", Verified: &verified},
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	data, err := report.RenderPDF(sampleReport(t))
+	if err != nil {
+		t.Fatalf("RenderPDF: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("output is not a PDF")
+	}
+}
+
+func TestRenderPDFWithBrokenLogo(t *testing.T) {
+	r := sampleReport(t)
+	r.Branding.Logo = []byte("not an image")
+	if _, err := report.RenderPDF(r); err != nil {
+		t.Fatalf("a broken logo should not fail the report: %v", err)
+	}
+}
+
+func TestRenderXLSX(t *testing.T) {
+	data, err := report.RenderXLSX(sampleReport(t))
+	if err != nil {
+		t.Fatalf("RenderXLSX: %v", err)
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a workbook: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows("Temuan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows on the findings sheet, want header + 1", len(rows))
+	}
+	if got := rows[1][2]; got != "Laptop Core i7" {
+		t.Errorf("item name = %q", got)
+	}
+	if got, _ := f.GetCellValue("Temuan", "G2"); got != "27500000" {
+		t.Errorf("unit price cell = %q, want a plain number", got)
+	}
+}
+
+func TestLoadLogoDataURI(t *testing.T) {
+	png := sampleReport(t).Branding.Logo
+	uri := "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+
+	data, imageType, err := report.LoadLogo(context.Background(), nil, testTenant, uri)
+	if err != nil {
+		t.Fatalf("LoadLogo: %v", err)
+	}
+	if imageType != "PNG" || !bytes.Equal(data, png) {
+		t.Errorf("got type %q and %d bytes", imageType, len(data))
+	}
+
+	if _, _, err := report.LoadLogo(context.Background(), nil, testTenant, "data:text/plain;base64,"+base64.StdEncoding.EncodeToString([]byte("hi"))); err == nil || !strings.Contains(err.Error(), "PNG or JPEG") {
+		t.Errorf("expected a format error, got %v", err)
+	}
+}
+
+const testTenant = "11111111-1111-1111-1111-111111111111"
+
+type fakeLogoStore map[string][]byte
+
+func (f fakeLogoStore) ReadObject(_ context.Context, key string, maxSize int64) ([]byte, error) {
+	data, ok := f[key]
+	if !ok {
+		return nil, fmt.Errorf("no object %s", key)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("object too large")
+	}
+	return data, nil
+}
+
+func TestLoadLogoSources(t *testing.T) {
+	png := sampleReport(t).Branding.Logo
+	own := "documents/" + testTenant + "/user/logo.png"
+	other := "documents/22222222-2222-2222-2222-222222222222/user/logo.png"
+	store := fakeLogoStore{own: png, other: png}
+
+	cases := []struct {
+		name    string
+		src     string
+		wantErr bool
+	}{
+		{"tenant object", own, false},
+		{"other tenant's object", other, true},
+		{"path traversal", "documents/" + testTenant + "/../x/logo.png", true},
+		{"http URL", "http://169.254.169.254/latest/meta-data", true},
+		{"https URL", "https://example.com/logo.png", true},
+		{"oversized data URI", "data:image/png;base64," + strings.Repeat("A", 3<<20), true},
+	}
+	for _, c := range cases {
+		data, _, err := report.LoadLogo(context.Background(), store, testTenant, c.src)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: err = %v, want error %v", c.name, err, c.wantErr)
+		}
+		if !c.wantErr && !bytes.Equal(data, png) {
+			t.Errorf("%s: got %d bytes", c.name, len(data))
+		}
+	}
+
+	if _, _, err := report.LoadLogo(context.Background(), nil, testTenant, own); err == nil {
+		t.Error("expected an error without object storage")
+	}
+}
@@ -0,0 +1,251 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	summarySheet  = "Ringkasan"
+	findingsSheet = "Temuan"
+)
+
+var findingHeaders = []string{
+	"No", "Kode", "Item", "Penyedia", "Satuan", "Volume", "Harga Satuan",
+	"Harga Referensi", "Deviasi (%)", "Verdict", "Rasional", "Suara Agen",
+}
+
+// RenderXLSX renders the report as a workbook with a summary sheet and a
+// findings sheet whose numeric columns stay numeric for further analysis.
+func RenderXLSX(r *AuditReport) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", summarySheet); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(findingsSheet); err != nil {
+		return nil, err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	title, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	if err != nil {
+		return nil, err
+	}
+	header, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"283C5A"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	wrap, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{WrapText: true, Vertical: "top"}})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSummarySheet(f, r, bold, title); err != nil {
+		return nil, err
+	}
+	if err := writeFindingsSheet(f, r, header, wrap); err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render xlsx: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeSummarySheet(f *excelize.File, r *AuditReport, bold, title int) error {
+	row := 1
+	if len(r.Branding.Logo) > 0 {
+		ext := ".png"
+		if r.Branding.LogoType == "JPG" {
+			ext = ".jpg"
+		}
+		// A broken logo must not prevent the report; continue without it.
+		if err := f.AddPictureFromBytes(summarySheet, "A1", &excelize.Picture{
+			Extension: ext,
+			File:      r.Branding.Logo,
+			Format:    &excelize.GraphicOptions{AutoFit: true, LockAspectRatio: true},
+		}); err == nil {
+			if err := f.SetRowHeight(summarySheet, 1, 50); err != nil {
+				return err
+			}
+			row = 2
+		}
+	}
+
+	set := func(key string, value interface{}) error {
+		if err := f.SetSheetRow(summarySheet, fmt.Sprintf("A%d", row), &[]interface{}{key, value}); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(summarySheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), bold); err != nil {
+			return err
+		}
+		row++
+		return nil
+	}
+	heading := func(text string, style int) error {
+		row++
+		cell := fmt.Sprintf("A%d", row)
+		if err := f.SetCellValue(summarySheet, cell, text); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(summarySheet, cell, cell, style); err != nil {
+			return err
+		}
+		row++
+		return nil
+	}
+
+	type kv struct {
+		key   string
+		value interface{}
+	}
+	anchorRows := []kv{
+		{"Jaringan", orDash(r.Anchor.Network)},
+		{"Kontrak", orDash(r.Anchor.Contract)},
+		{"Tx Hash", orDash(r.Anchor.TxHash)},
+		{"Blok", orDash(r.Anchor.BlockNumber)},
+		{"Waktu Blok", orDash(r.Anchor.Timestamp)},
+		{"Status Anchoring", orDash(r.Anchor.Status)},
+		{"Rationale Hash", orDash(r.Anchor.RationaleHash)},
+		{"Consensus Hash", orDash(r.Anchor.ConsensusHash)},
+	}
+	if r.Anchor.BatchID != "" {
+		anchorRows = append(anchorRows, kv{"Batch Anchoring", r.Anchor.BatchID}, kv{"Merkle Root", orDash(r.Anchor.MerkleRoot)})
+	}
+	anchorRows = append(anchorRows, kv{"Verifikasi", r.Anchor.verificationLabel()})
+
+	sections := []struct {
+		title string
+		rows  []kv
+	}{
+		{"Informasi Dokumen", []kv{
+			{"Judul Dokumen", orDash(r.Document.Title)},
+			{"ID Dokumen", orDash(r.Document.ID)},
+			{"Kategori", orDash(r.Document.Category)},
+			{"ID Task", orDash(r.Task.ID)},
+			{"Status Task", orDash(r.Task.Status)},
+			{"Model", orDash(r.Task.Model)},
+			{"Task Induk", orDash(r.Task.ParentTaskID)},
+			{"Dianalisis", formatTime(r.Task.CreatedAt)},
+			{"Selesai", formatTime(r.Task.UpdatedAt)},
+			{"Ringkasan", orDash(r.Task.Summary)},
+			{"Jumlah Item", len(r.Items)},
+		}},
+		{"Bukti Anchoring Blockchain", anchorRows},
+	}
+	if r.Review != nil {
+		sections = append(sections, struct {
+			title string
+			rows  []kv
+		}{"Keputusan Reviewer", []kv{
+			{"Verdict Reviewer", r.Review.Verdict},
+			{"Justifikasi", r.Review.Justification},
+			{"Reviewer", orDash(r.Review.ReviewerID)},
+			{"Tanggal", formatTime(r.Review.ReviewedAt)},
+			{"Tx Koreksi", orDash(r.Review.TxHash)},
+			{"Status Koreksi", orDash(r.Review.Status)},
+		}})
+	}
+
+	if err := f.SetCellValue(summarySheet, fmt.Sprintf("A%d", row), orDash(r.Branding.Name)); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(summarySheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), title); err != nil {
+		return err
+	}
+	row++
+	if err := set("Laporan", "Laporan Audit Anggaran — Analisis Swarm AI"); err != nil {
+		return err
+	}
+	if err := set("Dibuat", formatTime(r.GeneratedAt)); err != nil {
+		return err
+	}
+
+	for _, s := range sections {
+		if err := heading(s.title, title); err != nil {
+			return err
+		}
+		for _, item := range s.rows {
+			if err := set(item.key, item.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := f.SetColWidth(summarySheet, "A", "A", 22); err != nil {
+		return err
+	}
+	return f.SetColWidth(summarySheet, "B", "B", 80)
+}
+
+func writeFindingsSheet(f *excelize.File, r *AuditReport, header, wrap int) error {
+	if err := f.SetSheetRow(findingsSheet, "A1", &findingHeaders); err != nil {
+		return err
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(findingHeaders))
+	if err := f.SetCellStyle(findingsSheet, "A1", lastCol+"1", header); err != nil {
+		return err
+	}
+
+	for i, item := range r.Items {
+		votes := make([]string, 0, len(item.AgentVotes))
+		for _, v := range item.AgentVotes {
+			line := orDash(v.Agent) + ": " + orDash(v.Verdict)
+			if v.Reason != "" {
+				line += " — " + v.Reason
+			}
+			votes = append(votes, line)
+		}
+
+		row := []interface{}{
+			item.Index + 1, item.ItemCode, item.ItemName, item.Supplier, item.Unit,
+			numberCell(item.Quantity), numberCell(item.UnitPrice), numberCell(item.ReferencePrice),
+			numberCell(item.DeviationPct), item.Verdict, item.Rationale, strings.Join(votes, "\n"),
+		}
+		cell := fmt.Sprintf("A%d", i+2)
+		if err := f.SetSheetRow(findingsSheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Items) > 0 {
+		last := fmt.Sprintf("%s%d", lastCol, len(r.Items)+1)
+		if err := f.SetCellStyle(findingsSheet, "K2", last, wrap); err != nil {
+			return err
+		}
+		if err := f.AutoFilter(findingsSheet, "A1:"+last, nil); err != nil {
+			return err
+		}
+	}
+	if err := f.SetPanes(findingsSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	widths := map[string]float64{"A": 5, "B": 14, "C": 40, "D": 24, "E": 10, "F": 10, "G": 16, "H": 16, "I": 12, "J": 14, "K": 60, "L": 60}
+	for col, w := range widths {
+		if err := f.SetColWidth(findingsSheet, col, col, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// numberCell keeps missing values empty instead of writing 0.
+func numberCell(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
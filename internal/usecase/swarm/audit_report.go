@@ -0,0 +1,233 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/report"
+)
+
+// Keys used for the agent and its vote inside agent_votes entries.
+var (
+	voteAgentKeys   = []string{"agent", "name", "role", "agent_name"}
+	voteVerdictKeys = []string{"verdict", "vote", "decision", "status"}
+	voteReasonKeys  = []string{"reason", "rationale", "explanation", "alasan"}
+)
+
+// BuildAuditReport gathers everything printed in the audit report of a task.
+// The chain is queried for the anchoring proof when a blockchain service is
+// configured; otherwise the stored status is reported.
+func (u *SwarmUsecase) BuildAuditReport(ctx context.Context, tenantID, taskID string) (*report.AuditReport, error) {
+	task, err := u.GetTaskForTenant(ctx, tenantID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := u.swarmRepo.GetDB().WithContext(ctx)
+	var tenant domain.Tenant
+	if err := db.Table("tenants").Where("id = ?", tenantID).First(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tenant: %w", err)
+	}
+	var document domain.Document
+	if err := db.Table("documents").Where("id = ?", task.DocumentID).First(&document).Error; err != nil {
+		return nil, fmt.Errorf("failed to load document: %w", err)
+	}
+
+	r := &report.AuditReport{
+		GeneratedAt: time.Now(),
+		Branding:    report.Branding{Name: tenant.ReportName()},
+		Document: report.DocumentInfo{
+			ID:        document.ID.String(),
+			Title:     document.Title,
+			Category:  document.Category,
+			CreatedAt: document.CreatedAt,
+		},
+		Task: report.TaskInfo{
+			ID:        task.ID,
+			Status:    task.Status,
+			Model:     task.Model,
+			Summary:   task.Summary,
+			CreatedAt: task.CreatedAt,
+			UpdatedAt: task.UpdatedAt,
+		},
+	}
+	if task.ParentTaskID != nil {
+		r.Task.ParentTaskID = *task.ParentTaskID
+	}
+
+	if logo := tenant.ParsedSettings().Logo; logo != "" {
+		data, imageType, err := report.LoadLogo(ctx, u.logos, tenantID, logo)
+		if err != nil {
+			log.Printf("[Swarm] Report for task %s rendered without logo: %v", task.ID, err)
+		} else {
+			r.Branding.Logo, r.Branding.LogoType = data, imageType
+		}
+	}
+
+	findings, err := u.findingRepo.ListByTask(ctx, tenantID, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(findings) == 0 && len(task.Results) > 0 {
+		// Findings are derived on callback; fall back to the raw results for
+		// tasks completed before findings were stored.
+		var results []map[string]interface{}
+		if err := json.Unmarshal(task.Results, &results); err == nil {
+			for i, item := range results {
+				findings = append(findings, buildFinding(tenantID, task, i, item))
+			}
+		}
+	}
+	for _, f := range findings {
+		r.Items = append(r.Items, report.ItemFinding{
+			Index:          f.ItemIndex,
+			ItemCode:       f.ItemCode,
+			ItemName:       f.ItemName,
+			Supplier:       f.Supplier,
+			Unit:           f.Unit,
+			Quantity:       f.Quantity,
+			UnitPrice:      f.UnitPrice,
+			ReferencePrice: f.ReferencePrice,
+			DeviationPct:   f.DeviationPct,
+			Verdict:        f.Verdict,
+			Rationale:      f.Rationale,
+			AgentVotes:     parseAgentVotes(f.AgentVotes),
+		})
+	}
+
+	if task.ReviewVerdict != "" {
+		r.Review = &report.ReviewDecision{
+			Verdict:       task.ReviewVerdict,
+			Justification: task.ReviewJustification,
+			TxHash:        task.ReviewTx,
+			Status:        task.ReviewChainStat,
+		}
+		if task.ReviewedBy != nil {
+			r.Review.ReviewerID = *task.ReviewedBy
+		}
+		if task.ReviewedAt != nil {
+			r.Review.ReviewedAt = *task.ReviewedAt
+		}
+	}
+
+	r.Anchor = u.anchorProof(ctx, task)
+	return r, nil
+}
+
+func (u *SwarmUsecase) anchorProof(ctx context.Context, task *domain.SwarmTask) report.AnchorProof {
+	rationaleHash, consensusHash := task.AnchoredHashes()
+	proof := report.AnchorProof{
+		Network:       task.BlockchainNet,
+		TxHash:        task.BlockchainTx,
+		Status:        task.BlockchainStat,
+		RationaleHash: rationaleHash,
+		ConsensusHash: consensusHash,
+	}
+
+	if u.ledgers == nil {
+		if rationaleHash != "" {
+			proof.VerifyError = "layanan blockchain tidak aktif"
+		}
+		return proof
+	}
+	proof.Contract = task.LedgerContract
+	ledger, err := u.ledgers.Anchored(task.LedgerProfile, task.LedgerContract)
+	if err != nil {
+		if rationaleHash != "" {
+			proof.VerifyError = err.Error()
+		}
+		return proof
+	}
+	if proof.Network == "" {
+		proof.Network = ledger.Network()
+	}
+	proof.Contract = ledger.ContractAddress()
+	if rationaleHash == "" || consensusHash == "" {
+		return proof
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// A batched task is proven through its batch: the leaf's Merkle path must
+	// lead to the root anchored under the batch ID.
+	logID, anchoredRationale, anchoredConsensus := task.ID, rationaleHash, consensusHash
+	if task.BatchID != nil && task.ReviewChainStat != domain.BlockchainStatusVerified {
+		batch, err := u.batchRepo.GetByID(ctx, *task.BatchID)
+		if err != nil {
+			proof.VerifyError = err.Error()
+			return proof
+		}
+		proof.BatchID = batch.ID
+		proof.MerkleRoot = batch.MerkleRoot
+		root, commitment, err := batchRootFor(task, batch)
+		if err != nil {
+			proof.VerifyError = err.Error()
+			return proof
+		}
+		logID, anchoredRationale, anchoredConsensus = batch.ID, root, commitment
+	}
+
+	verified, err := ledger.VerifyHashes(ctx, logID, anchoredRationale, anchoredConsensus)
+	if err != nil {
+		proof.VerifyError = err.Error()
+		return proof
+	}
+	proof.Verified = &verified
+
+	if entry, err := ledger.GetActiveLog(ctx, logID); err == nil {
+		proof.BlockNumber = fmt.Sprintf("%v", entry["block_number"])
+		if ts, ok := entry["timestamp"].(int64); ok && ts > 0 {
+			proof.Timestamp = time.Unix(ts, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return proof
+}
+
+func parseAgentVotes(raw []byte) []report.AgentVote {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+
+	var votes []report.AgentVote
+	switch v := decoded.(type) {
+	case []interface{}:
+		for _, entry := range v {
+			switch e := entry.(type) {
+			case map[string]interface{}:
+				votes = append(votes, report.AgentVote{
+					Agent:   lookupString(e, voteAgentKeys),
+					Verdict: lookupString(e, voteVerdictKeys),
+					Reason:  lookupString(e, voteReasonKeys),
+				})
+			case string:
+				votes = append(votes, report.AgentVote{Verdict: e})
+			}
+		}
+	case map[string]interface{}:
+		// {"auditor": "MARKUP", "legal": {"verdict": "OK", "reason": "..."}}
+		for agent, entry := range v {
+			vote := report.AgentVote{Agent: agent}
+			switch e := entry.(type) {
+			case string:
+				vote.Verdict = e
+			case map[string]interface{}:
+				vote.Verdict = lookupString(e, voteVerdictKeys)
+				vote.Reason = lookupString(e, voteReasonKeys)
+			}
+			votes = append(votes, vote)
+		}
+		sort.Slice(votes, func(i, j int) bool { return votes[i].Agent < votes[j].Agent })
+	}
+	return votes
+}
@@ -0,0 +1,359 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mq"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/hibiken/asynq"
+	"gorm.io/datatypes"
+)
+
+const (
+	TypeSealAnchorBatch    = "swarm:seal_batch"
+	TypeCommitAnchorBatch  = "swarm:commit_batch"
+	TypeSweepAnchorBatches = "swarm:sweep_batches"
+
+	// sweepBatchLimit bounds the commits re-enqueued per sweep.
+	sweepBatchLimit = 100
+)
+
+type SealAnchorBatchPayload struct {
+	MaxSize int `json:"max_size"`
+}
+
+type CommitAnchorBatchPayload struct {
+	BatchID string `json:"batch_id"`
+}
+
+type SweepAnchorBatchesPayload struct {
+	StaleAfter time.Duration `json:"stale_after"`
+}
+
+func NewSealAnchorBatchTask(maxSize int) (*asynq.Task, error) {
+	payload, err := json.Marshal(SealAnchorBatchPayload{MaxSize: maxSize})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeSealAnchorBatch,
+		payload,
+		asynq.MaxRetry(3),
+		asynq.Queue("default"),
+	), nil
+}
+
+func NewCommitAnchorBatchTask(batchID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(CommitAnchorBatchPayload{BatchID: batchID})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeCommitAnchorBatch,
+		payload,
+		asynq.MaxRetry(5),
+		asynq.Queue("default"),
+	), nil
+}
+
+func NewSweepAnchorBatchesTask(staleAfter time.Duration) (*asynq.Task, error) {
+	payload, err := json.Marshal(SweepAnchorBatchesPayload{StaleAfter: staleAfter})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeSweepAnchorBatches,
+		payload,
+		asynq.MaxRetry(1),
+		asynq.Queue("default"),
+	), nil
+}
+
+// StartAnchorBatcher schedules a batch seal every cfg.Interval. Sealing is
+// also triggered early by HandleCallback once cfg.MaxSize tasks are pending.
+// Each run also sweeps batches whose commit was lost.
+func StartAnchorBatcher(cfg config.AnchorBatchConfig, mqClient mq.TaskQueue) (gocron.Scheduler, error) {
+	s, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	_, err = s.NewJob(
+		gocron.DurationJob(cfg.Interval),
+		gocron.NewTask(func() {
+			seal, err := NewSealAnchorBatchTask(cfg.MaxSize)
+			if err != nil {
+				log.Printf("[Swarm-Batch] Failed to create seal task: %v", err)
+				return
+			}
+			if _, err := mqClient.EnqueueTask(seal); err != nil {
+				log.Printf("[Swarm-Batch] Failed to enqueue seal task: %v", err)
+			}
+			if cfg.CommitStaleAfter <= 0 {
+				return
+			}
+			sweep, err := NewSweepAnchorBatchesTask(cfg.CommitStaleAfter)
+			if err != nil {
+				log.Printf("[Swarm-Batch] Failed to create sweep task: %v", err)
+				return
+			}
+			if _, err := mqClient.EnqueueTask(sweep); err != nil {
+				log.Printf("[Swarm-Batch] Failed to enqueue sweep task: %v", err)
+			}
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule anchor batcher: %w", err)
+	}
+
+	log.Printf("Anchor batcher scheduled every %s (max %d tasks per batch)", cfg.Interval, cfg.MaxSize)
+	s.Start()
+	return s, nil
+}
+
+// HandleSealAnchorBatch claims pending tasks, builds their Merkle tree and
+// stores each task's inclusion proof, then hands the root to
+// HandleCommitAnchorBatch. Seals repeatedly until the backlog is drained.
+// A batch only holds tasks of one ledger profile.
+func (h *SwarmTaskHandler) HandleSealAnchorBatch(ctx context.Context, t *asynq.Task) error {
+	var payload SealAnchorBatchPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+	if payload.MaxSize < 1 {
+		return fmt.Errorf("invalid batch size %d: %w", payload.MaxSize, asynq.SkipRetry)
+	}
+
+	profiles, err := h.batchRepo.PendingProfiles(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, profile := range profiles {
+		if err := h.sealProfile(ctx, profile, payload.MaxSize); err != nil {
+			errs = append(errs, fmt.Errorf("ledger profile %s: %w", profile, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *SwarmTaskHandler) sealProfile(ctx context.Context, profile string, maxSize int) error {
+	ledger, err := h.ledgers.Get(profile)
+	if err != nil {
+		return err
+	}
+	seal := func(batch *domain.AnchorBatch, tasks []*domain.SwarmTask) error {
+		batch.LedgerContract = ledger.ContractAddress()
+		return sealBatch(batch, tasks)
+	}
+
+	for {
+		batch, err := h.batchRepo.SealPending(ctx, profile, maxSize, seal)
+		if err != nil {
+			return err
+		}
+		if batch == nil {
+			return nil
+		}
+		log.Printf("[Swarm-Batch] Sealed batch %s with %d tasks for ledger profile %s (root %s)", batch.ID, batch.LeafCount, profile, batch.MerkleRoot)
+
+		commitTask, err := NewCommitAnchorBatchTask(batch.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := h.mqClient.EnqueueTask(commitTask); err != nil {
+			// The tasks are already sealed into the batch, so a retry of this
+			// seal task would not pick them up again; the batch stays
+			// PENDING_COMMIT until HandleSweepAnchorBatches re-enqueues it.
+			log.Printf("[Swarm-Batch] ❌ Failed to enqueue commit for batch %s: %v", batch.ID, err)
+			return fmt.Errorf("failed to enqueue commit for batch %s: %w", batch.ID, err)
+		}
+		if batch.LeafCount < maxSize {
+			return nil
+		}
+	}
+}
+
+// sealBatch computes the Merkle tree over the tasks' hashes and records
+// every task's membership in the batch.
+func sealBatch(batch *domain.AnchorBatch, tasks []*domain.SwarmTask) error {
+	leaves := make([]string, len(tasks))
+	for i, task := range tasks {
+		leaf, err := blockchain.MerkleLeaf(task.ID, task.RationaleHash, task.ConsensusHash)
+		if err != nil {
+			return fmt.Errorf("failed to hash leaf for task %s: %w", task.ID, err)
+		}
+		leaves[i] = leaf
+	}
+
+	root, proofs, err := blockchain.BuildMerkleTree(leaves)
+	if err != nil {
+		return err
+	}
+	commitment, err := blockchain.BatchCommitment(batch.ID, root, len(tasks))
+	if err != nil {
+		return err
+	}
+	batch.MerkleRoot = root
+	batch.Commitment = commitment
+	batch.LeafCount = len(tasks)
+	batch.Status = domain.BlockchainStatusPendingCommit
+
+	for i, task := range tasks {
+		proof, err := json.Marshal(proofs[i])
+		if err != nil {
+			return err
+		}
+		index := i
+		task.BatchID = &batch.ID
+		task.LeafIndex = &index
+		task.MerkleProof = datatypes.JSON(proof)
+		task.BlockchainStat = domain.BlockchainStatusBatched
+		task.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// HandleSweepAnchorBatches re-enqueues the commit of batches that stayed
+// PENDING_COMMIT without a transaction for longer than the payload's
+// StaleAfter: their commit task was never enqueued or ran out of retries.
+func (h *SwarmTaskHandler) HandleSweepAnchorBatches(ctx context.Context, t *asynq.Task) error {
+	var payload SweepAnchorBatchesPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+	if payload.StaleAfter <= 0 {
+		return fmt.Errorf("invalid stale_after %s: %w", payload.StaleAfter, asynq.SkipRetry)
+	}
+
+	batches, err := h.batchRepo.ListStaleCommits(ctx, time.Now().Add(-payload.StaleAfter), sweepBatchLimit)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, batch := range batches {
+		commitTask, err := NewCommitAnchorBatchTask(batch.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := h.mqClient.EnqueueTask(commitTask); err != nil {
+			errs = append(errs, fmt.Errorf("failed to enqueue commit for batch %s: %w", batch.ID, err))
+			continue
+		}
+		if err := h.batchRepo.TouchCommit(ctx, batch.ID); err != nil {
+			log.Printf("[Swarm-Batch] Failed to touch batch %s: %v", batch.ID, err)
+		}
+		log.Printf("[Swarm-Batch] Re-enqueued commit of stale batch %s (sealed %s)", batch.ID, batch.CreatedAt.Format(time.RFC3339))
+	}
+	return errors.Join(errs...)
+}
+
+// batchRootFor recomputes the root and commitment a batched task proves,
+// from the task's own hashes and stored inclusion proof.
+func batchRootFor(task *domain.SwarmTask, batch *domain.AnchorBatch) (string, string, error) {
+	var proof []blockchain.ProofStep
+	if err := json.Unmarshal(task.MerkleProof, &proof); err != nil {
+		return "", "", fmt.Errorf("invalid merkle proof: %w", err)
+	}
+	leaf, err := blockchain.MerkleLeaf(task.ID, task.RationaleHash, task.ConsensusHash)
+	if err != nil {
+		return "", "", err
+	}
+	root, err := blockchain.MerkleRootFromProof(leaf, proof)
+	if err != nil {
+		return "", "", err
+	}
+	if !blockchain.HashesEqual(root, batch.MerkleRoot) {
+		return "", "", fmt.Errorf("merkle proof does not lead to the batch root")
+	}
+	commitment, err := blockchain.BatchCommitment(batch.ID, root, batch.LeafCount)
+	if err != nil {
+		return "", "", err
+	}
+	return root, commitment, nil
+}
+
+// HandleCommitAnchorBatch anchors a sealed batch's root with insertLog under
+// the batch ID and propagates the result to the batch's tasks.
+func (h *SwarmTaskHandler) HandleCommitAnchorBatch(ctx context.Context, t *asynq.Task) error {
+	var payload CommitAnchorBatchPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if h.ledgers == nil {
+		log.Printf("[Swarm-Batch] Blockchain service not initialized. Skipping commit for batch %s", payload.BatchID)
+		return nil
+	}
+
+	batch, err := h.batchRepo.GetByID(ctx, payload.BatchID)
+	if err != nil {
+		return fmt.Errorf("failed to load batch %s: %w", payload.BatchID, err)
+	}
+	if batch.Status == domain.BlockchainStatusVerified {
+		return nil
+	}
+	ledger, err := h.ledgers.Anchored(batch.LedgerProfile, batch.LedgerContract)
+	if err != nil {
+		return fmt.Errorf("no ledger for batch %s: %w", batch.ID, err)
+	}
+
+	// A previous attempt may already have submitted the transaction.
+	txHash := batch.TxHash
+	if txHash == "" {
+		log.Printf("[Swarm-Batch] ▶ Committing batch %s root to %s (%d tasks)", batch.ID, ledger.Network(), batch.LeafCount)
+		txHash, err = ledger.InsertLog(ctx, batch.ID, batch.MerkleRoot, batch.Commitment)
+		if err != nil {
+			log.Printf("[Swarm-Batch] ❌ insertLog failed for batch %s: %v", batch.ID, err)
+			return fmt.Errorf("insertLog failed: %w", err)
+		}
+		h.updateBatchStatus(ctx, batch, ledger, txHash, domain.BlockchainStatusPendingConfirmation)
+	}
+
+	receipt, err := ledger.WaitForConfirmation(ctx, txHash, 90*time.Second)
+	if err != nil {
+		log.Printf("[Swarm-Batch] ⚠️ Wait for confirmation timed out for batch %s, tx: %s. Left to the reconciler", batch.ID, txHash)
+		return nil
+	}
+
+	txHash = receipt.TxHash.Hex()
+	if receipt.Status != 1 {
+		log.Printf("[Swarm-Batch] ❌ Tx execution failed on-chain for batch %s", batch.ID)
+		h.updateBatchStatus(ctx, batch, ledger, txHash, domain.BlockchainStatusFailed)
+		return fmt.Errorf("transaction execution failed on chain: %w", asynq.SkipRetry)
+	}
+
+	log.Printf("[Swarm-Batch] ✅ Batch %s confirmed in block %d", batch.ID, receipt.BlockNumber)
+	now := time.Now()
+	batch.AnchoredAt = &now
+	h.updateBatchStatus(ctx, batch, ledger, txHash, domain.BlockchainStatusVerified)
+	return nil
+}
+
+// updateBatchStatus records the batch's anchoring state on the batch and on
+// every task in it.
+func (h *SwarmTaskHandler) updateBatchStatus(ctx context.Context, batch *domain.AnchorBatch, ledger blockchain.Ledger, txHash, status string) {
+	batch.TxHash = txHash
+	batch.Network = ledger.Network()
+	batch.Status = status
+	if err := h.batchRepo.Update(ctx, batch); err != nil {
+		log.Printf("[Swarm-Batch] Failed to update batch %s: %v", batch.ID, err)
+	}
+
+	taskStatus := status
+	if status == domain.BlockchainStatusPendingConfirmation {
+		// Tasks stay BATCHED until the root is confirmed.
+		taskStatus = domain.BlockchainStatusBatched
+	}
+	if err := h.batchRepo.UpdateTaskStatus(ctx, batch.ID, txHash, batch.Network, taskStatus); err != nil {
+		log.Printf("[Swarm-Batch] Failed to update tasks of batch %s: %v", batch.ID, err)
+	}
+}
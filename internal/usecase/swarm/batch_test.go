@@ -0,0 +1,216 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/hibiken/asynq"
+)
+
+const testBatchID = "55555555-5555-5555-5555-555555555555"
+
+var batchColumns = []string{"id", "merkle_root", "commitment", "leaf_count", "tx_hash", "network", "ledger_profile", "ledger_contract", "status", "created_at", "updated_at"}
+
+func batchRow(rows *sqlmock.Rows, b domain.AnchorBatch) *sqlmock.Rows {
+	return rows.AddRow(b.ID, b.MerkleRoot, b.Commitment, b.LeafCount, b.TxHash, b.Network, b.LedgerProfile,
+		b.LedgerContract, b.Status, b.CreatedAt, b.UpdatedAt)
+}
+
+func (d *taskDB) batchRepo() *postgres.AnchorBatchRepository {
+	return postgres.NewAnchorBatchRepository(d.db)
+}
+
+func TestHandleSealAnchorBatch(t *testing.T) {
+	ctx := context.Background()
+
+	if err := (&SwarmTaskHandler{}).HandleSealAnchorBatch(ctx, asynq.NewTask(TypeSealAnchorBatch, []byte(`{"max_size":0}`))); !errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("invalid size: err = %v, want SkipRetry", err)
+	}
+
+	for _, enqueueErr := range []error{nil, errors.New("redis down")} {
+		db := newTaskDB(t)
+		db.mock.ExpectQuery(`SELECT DISTINCT "ledger_profile" FROM "swarm_tasks"`).
+			WillReturnRows(sqlmock.NewRows([]string{"ledger_profile"}).AddRow("default"))
+		db.mock.ExpectBegin()
+		tasks := sqlmock.NewRows([]string{"id", "document_id", "rationale_hash", "consensus_hash", "blockchain_stat", "ledger_profile"})
+		for _, id := range []string{testTaskID, "66666666-6666-6666-6666-666666666666"} {
+			tasks.AddRow(id, testDocID, "aa", "bb", domain.BlockchainStatusPendingBatch, "default")
+		}
+		db.mock.ExpectQuery(`SELECT \* FROM "swarm_tasks" WHERE .* FOR UPDATE SKIP LOCKED`).WillReturnRows(tasks)
+		db.mock.ExpectQuery(`INSERT INTO "anchor_batches"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		db.expectSave()
+		db.expectSave()
+		db.mock.ExpectCommit()
+
+		queue := &fakeQueue{err: enqueueErr}
+		h := NewSwarmTaskHandler(db.swarmRepo(), db.batchRepo(), nil, blockchain.SingleLedger(&fakeLedger{}), queue)
+		task, err := NewSealAnchorBatchTask(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.HandleSealAnchorBatch(ctx, task)
+		if (err != nil) != (enqueueErr != nil) {
+			t.Fatalf("enqueue error %v: err = %v", enqueueErr, err)
+		}
+		if got := db.saved("blockchain_stat"); !reflect.DeepEqual(got, []string{domain.BlockchainStatusBatched, domain.BlockchainStatusBatched}) {
+			t.Errorf("task statuses %v, want both BATCHED", got)
+		}
+		if got := db.saved("leaf_index"); !reflect.DeepEqual(got, []string{"0", "1"}) {
+			t.Errorf("leaf indexes %v", got)
+		}
+		batchIDs := db.saved("batch_id")
+		if len(batchIDs) != 2 || batchIDs[0] != batchIDs[1] || batchIDs[0] == "" {
+			t.Fatalf("batch ids %v, want one batch", batchIDs)
+		}
+		if enqueueErr != nil {
+			continue
+		}
+		if len(queue.tasks) != 1 || queue.tasks[0].Type() != TypeCommitAnchorBatch {
+			t.Fatalf("enqueued %v, want one commit", queue.types())
+		}
+		var payload CommitAnchorBatchPayload
+		if err := json.Unmarshal(queue.tasks[0].Payload(), &payload); err != nil || payload.BatchID != batchIDs[0] {
+			t.Errorf("commit payload %+v (%v), want batch %s", payload, err, batchIDs[0])
+		}
+	}
+}
+
+func TestHandleCommitAnchorBatch(t *testing.T) {
+	sealed := domain.AnchorBatch{
+		ID: testBatchID, MerkleRoot: "0xaa", Commitment: "0xbb", LeafCount: 2,
+		LedgerProfile: "default", Status: domain.BlockchainStatusPendingCommit,
+	}
+	submitted := sealed
+	submitted.TxHash, submitted.Status = "0x01", domain.BlockchainStatusPendingConfirmation
+	verified := submitted
+	verified.Status = domain.BlockchainStatusVerified
+
+	cases := []struct {
+		name          string
+		batch         domain.AnchorBatch
+		ledger        fakeLedger
+		wantErr       bool
+		wantSkipRetry bool
+		wantInsert    bool
+		// Statuses written to the batch and to its tasks, in order.
+		wantBatch []string
+		wantTasks []string
+	}{
+		{name: "already verified", batch: verified},
+		{
+			name: "confirmed", batch: sealed, ledger: fakeLedger{receiptStatus: 1}, wantInsert: true,
+			wantBatch: []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusVerified},
+			wantTasks: []string{domain.BlockchainStatusBatched, domain.BlockchainStatusVerified},
+		},
+		{
+			name: "submitted by an earlier attempt", batch: submitted, ledger: fakeLedger{receiptStatus: 1},
+			wantBatch: []string{domain.BlockchainStatusVerified},
+			wantTasks: []string{domain.BlockchainStatusVerified},
+		},
+		{name: "insert fails", batch: sealed, ledger: fakeLedger{insertErr: errors.New("rpc down")}, wantErr: true},
+		{
+			name: "reverted", batch: sealed, ledger: fakeLedger{receiptStatus: 0}, wantInsert: true,
+			wantErr: true, wantSkipRetry: true,
+			wantBatch: []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusFailed},
+			wantTasks: []string{domain.BlockchainStatusBatched, domain.BlockchainStatusFailed},
+		},
+		{
+			name: "confirmation left to the reconciler", batch: sealed, ledger: fakeLedger{waitErr: errors.New("timeout")}, wantInsert: true,
+			wantBatch: []string{domain.BlockchainStatusPendingConfirmation},
+			wantTasks: []string{domain.BlockchainStatusBatched},
+		},
+	}
+	for _, c := range cases {
+		db := newTaskDB(t)
+		db.mock.ExpectQuery(`SELECT \* FROM "anchor_batches"`).WillReturnRows(batchRow(sqlmock.NewRows(batchColumns), c.batch))
+		for range c.wantBatch {
+			db.mock.ExpectExec(`UPDATE "anchor_batches" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+			db.mock.ExpectExec(`UPDATE "swarm_tasks" SET .* WHERE batch_id = \$\d+`).WillReturnResult(sqlmock.NewResult(0, 2))
+		}
+		ledger := c.ledger
+		h := NewSwarmTaskHandler(db.swarmRepo(), db.batchRepo(), nil, blockchain.SingleLedger(&ledger), &fakeQueue{})
+		task, err := NewCommitAnchorBatchTask(testBatchID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.HandleCommitAnchorBatch(context.Background(), task)
+		if (err != nil) != c.wantErr || errors.Is(err, asynq.SkipRetry) != c.wantSkipRetry {
+			t.Errorf("%s: err = %v, want error %v, skip retry %v", c.name, err, c.wantErr, c.wantSkipRetry)
+		}
+		if got := len(ledger.inserted) == 1 && ledger.inserted[0] == testBatchID; got != c.wantInsert {
+			t.Errorf("%s: inserted %v, want insert %v", c.name, ledger.inserted, c.wantInsert)
+		}
+		if got := db.savedIn("anchor_batches", "status"); !reflect.DeepEqual(got, c.wantBatch) {
+			t.Errorf("%s: batch statuses %v, want %v", c.name, got, c.wantBatch)
+		}
+		if got := db.saved("blockchain_stat"); !reflect.DeepEqual(got, c.wantTasks) {
+			t.Errorf("%s: task statuses %v, want %v", c.name, got, c.wantTasks)
+		}
+	}
+}
+
+func TestHandleSweepAnchorBatches(t *testing.T) {
+	ctx := context.Background()
+
+	if err := (&SwarmTaskHandler{}).HandleSweepAnchorBatches(ctx, asynq.NewTask(TypeSweepAnchorBatches, []byte(`{}`))); !errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("missing stale_after: err = %v, want SkipRetry", err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	stale := []domain.AnchorBatch{
+		{ID: testBatchID, LedgerProfile: "default", Status: domain.BlockchainStatusPendingCommit, CreatedAt: old, UpdatedAt: old},
+		{ID: "77777777-7777-7777-7777-777777777777", LedgerProfile: "default", Status: domain.BlockchainStatusPendingCommit, CreatedAt: old, UpdatedAt: old},
+	}
+	for _, enqueueErr := range []error{nil, errors.New("redis down")} {
+		db := newTaskDB(t)
+		rows := sqlmock.NewRows(batchColumns)
+		for _, b := range stale {
+			batchRow(rows, b)
+		}
+		db.mock.ExpectQuery(`SELECT \* FROM "anchor_batches" WHERE status = \$1 AND COALESCE\(tx_hash, ''\) = '' AND updated_at < \$2`).
+			WithArgs(domain.BlockchainStatusPendingCommit, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(rows)
+		if enqueueErr == nil {
+			for range stale {
+				db.mock.ExpectExec(`UPDATE "anchor_batches" SET "updated_at"=\$1 WHERE id = \$2 AND status = \$3`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+		}
+
+		queue := &fakeQueue{err: enqueueErr}
+		h := NewSwarmTaskHandler(db.swarmRepo(), db.batchRepo(), nil, blockchain.SingleLedger(&fakeLedger{}), queue)
+		task, err := NewSweepAnchorBatchesTask(15 * time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.HandleSweepAnchorBatches(ctx, task)
+		if (err != nil) != (enqueueErr != nil) {
+			t.Fatalf("enqueue error %v: err = %v", enqueueErr, err)
+		}
+		if enqueueErr != nil {
+			continue
+		}
+		var ids []string
+		for _, queued := range queue.tasks {
+			var payload CommitAnchorBatchPayload
+			if err := json.Unmarshal(queued.Payload(), &payload); err != nil || queued.Type() != TypeCommitAnchorBatch {
+				t.Fatalf("unexpected task %s: %v", queued.Type(), err)
+			}
+			ids = append(ids, payload.BatchID)
+		}
+		if !reflect.DeepEqual(ids, []string{stale[0].ID, stale[1].ID}) {
+			t.Errorf("re-enqueued %v", ids)
+		}
+	}
+}
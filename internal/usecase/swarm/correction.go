@@ -0,0 +1,294 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/hibiken/asynq"
+)
+
+const TypeLedgerCorrection = "swarm:ledger_correction"
+
+// ErrInvalidCorrection is returned when a correction lacks a justification.
+var ErrInvalidCorrection = errors.New("justification is required")
+
+type LedgerCorrectionPayload struct {
+	CorrectionID string `json:"correction_id"`
+}
+
+func NewLedgerCorrectionTask(correctionID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(LedgerCorrectionPayload{CorrectionID: correctionID})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeLedgerCorrection,
+		payload,
+		asynq.MaxRetry(5),
+		asynq.Queue("default"),
+	), nil
+}
+
+type CorrectionInput struct {
+	Justification string
+	RequestedBy   string
+}
+
+// LedgerHistoryEntry is an on-chain log entry with the recorded reason for
+// it, when the entry is a correction made through this service.
+type LedgerHistoryEntry struct {
+	blockchain.LogEntry
+	Correction *domain.LedgerCorrection `json:"correction,omitempty"`
+}
+
+type LedgerHistory struct {
+	TaskID  string               `json:"taskId"`
+	Network string               `json:"network"`
+	BatchID *string              `json:"batchId,omitempty"`
+	Entries []LedgerHistoryEntry `json:"entries"`
+	// PendingCorrections are corrections not (yet) found on the ledger.
+	PendingCorrections []*domain.LedgerCorrection `json:"pendingCorrections"`
+}
+
+// LedgerHistory returns every on-chain log entry of a task, superseded ones
+// included, joined with who requested each correction and why.
+func (u *SwarmUsecase) LedgerHistory(ctx context.Context, tenantID, id string) (*LedgerHistory, error) {
+	task, err := u.GetTaskForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := u.taskLedger(task)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ledger.GetTaskHistory(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ledger history: %w", err)
+	}
+	corrections, err := u.correctionRepo.ListByTask(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byTx := make(map[string]*domain.LedgerCorrection, len(corrections))
+	for _, c := range corrections {
+		if c.TxHash != "" {
+			byTx[strings.ToLower(c.TxHash)] = c
+		}
+	}
+
+	history := &LedgerHistory{
+		TaskID:             task.ID,
+		Network:            ledger.Network(),
+		BatchID:            task.BatchID,
+		Entries:            make([]LedgerHistoryEntry, 0, len(entries)),
+		PendingCorrections: []*domain.LedgerCorrection{},
+	}
+	matched := make(map[string]bool)
+	for _, e := range entries {
+		entry := LedgerHistoryEntry{LogEntry: e}
+		if c, ok := byTx[strings.ToLower(e.TxHash)]; ok {
+			entry.Correction = c
+			matched[c.ID] = true
+		}
+		history.Entries = append(history.Entries, entry)
+	}
+	for _, c := range corrections {
+		if !matched[c.ID] {
+			history.PendingCorrections = append(history.PendingCorrections, c)
+		}
+	}
+	return history, nil
+}
+
+// taskLedger returns the ledger a task is pinned to, or ErrInvalidTransition
+// when it cannot be reached.
+func (u *SwarmUsecase) taskLedger(task *domain.SwarmTask) (blockchain.Ledger, error) {
+	ledger, err := u.ledgers.Anchored(task.LedgerProfile, task.LedgerContract)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransition, err)
+	}
+	return ledger, nil
+}
+
+// SubmitCorrection supersedes the task's on-chain log with the hashes the
+// task currently holds (the reviewer's, if overridden), for when the ledger
+// disagrees with the database, e.g. after the reconciler flagged a mismatch.
+func (u *SwarmUsecase) SubmitCorrection(ctx context.Context, tenantID, id string, in CorrectionInput) (*domain.LedgerCorrection, error) {
+	justification := strings.TrimSpace(in.Justification)
+	if justification == "" {
+		return nil, ErrInvalidCorrection
+	}
+
+	task, err := u.GetTaskForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := u.taskLedger(task)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range []string{task.BlockchainStat, task.ReviewChainStat} {
+		switch status {
+		case domain.BlockchainStatusPendingCommit, domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusPendingBatch, domain.BlockchainStatusBatched:
+			return nil, fmt.Errorf("%w: task is still being anchored", ErrInvalidTransition)
+		}
+	}
+
+	review := task.ReviewRationaleHash != ""
+	rationaleHash, consensusHash := task.RationaleHash, task.ConsensusHash
+	if review {
+		rationaleHash, consensusHash = task.ReviewRationaleHash, task.ReviewConsensusHash
+	}
+	if rationaleHash == "" || consensusHash == "" {
+		return nil, fmt.Errorf("%w: task has no hashes to anchor", ErrInvalidTransition)
+	}
+
+	active, err := ledger.GetActiveLog(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: task has no log of its own on the ledger", ErrInvalidTransition)
+	}
+	previousRationale, _ := active["rationale_hash"].(string)
+	previousConsensus, _ := active["consensus_hash"].(string)
+	if blockchain.HashesEqual(previousRationale, rationaleHash) && blockchain.HashesEqual(previousConsensus, consensusHash) {
+		return nil, fmt.Errorf("%w: ledger already holds the task's current hashes", ErrInvalidTransition)
+	}
+
+	correction := &domain.LedgerCorrection{
+		TaskID:                task.ID,
+		Source:                domain.CorrectionSourceManual,
+		Justification:         justification,
+		RequestedBy:           in.RequestedBy,
+		PreviousRationaleHash: previousRationale,
+		PreviousConsensusHash: previousConsensus,
+		RationaleHash:         rationaleHash,
+		ConsensusHash:         consensusHash,
+		Network:               ledger.Network(),
+		Status:                domain.BlockchainStatusPendingCommit,
+	}
+	if err := u.correctionRepo.Create(ctx, correction); err != nil {
+		return nil, err
+	}
+
+	if review {
+		task.ReviewChainStat = domain.BlockchainStatusPendingCommit
+	} else {
+		task.BlockchainStat = domain.BlockchainStatusPendingCommit
+	}
+	task.UpdatedAt = time.Now()
+	if err := u.swarmRepo.Update(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to store correction status: %w", err)
+	}
+
+	asynqTask, err := NewLedgerCorrectionTask(correction.ID)
+	if err != nil {
+		log.Printf("[Swarm] Failed to create ledger correction task for task %s: %v", task.ID, err)
+	} else if _, err := u.mqClient.EnqueueTask(asynqTask); err != nil {
+		log.Printf("[Swarm] Failed to enqueue ledger correction task for task %s: %v", task.ID, err)
+	}
+	return correction, nil
+}
+
+// HandleLedgerCorrection submits a manual correction with correctLog and
+// records the outcome on the correction and on the task.
+func (h *SwarmTaskHandler) HandleLedgerCorrection(ctx context.Context, t *asynq.Task) error {
+	var payload LedgerCorrectionPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if h.ledgers == nil {
+		log.Printf("[Swarm-Worker] Blockchain service not initialized. Skipping correction %s", payload.CorrectionID)
+		return nil
+	}
+
+	correction, err := h.correctionRepo.GetByID(ctx, payload.CorrectionID)
+	if err != nil {
+		return fmt.Errorf("failed to load correction %s: %w", payload.CorrectionID, err)
+	}
+	if correction.Status == domain.BlockchainStatusVerified || correction.Status == domain.BlockchainStatusFailed {
+		return nil
+	}
+	task, err := h.swarmRepo.GetByID(ctx, correction.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to load task %s: %w", correction.TaskID, err)
+	}
+	review := task.ReviewRationaleHash != "" && blockchain.HashesEqual(task.ReviewRationaleHash, correction.RationaleHash)
+	ledger, err := h.ledgers.Anchored(task.LedgerProfile, task.LedgerContract)
+	if err != nil {
+		return fmt.Errorf("no ledger for task %s: %v: %w", task.ID, err, asynq.SkipRetry)
+	}
+	setStatus := func(txHash, status string) {
+		h.updateCorrection(ctx, ledger, correction.ID, txHash, status)
+		if review {
+			h.updateReviewStatus(ctx, task.ID, txHash, status)
+		} else {
+			h.updateBlockchainStatus(ctx, task.ID, txHash, status)
+		}
+	}
+
+	// A previous attempt may already have submitted the transaction.
+	txHash := correction.TxHash
+	if txHash == "" {
+		log.Printf("[Swarm-Worker] ▶ Submitting ledger correction %s for task %s", correction.ID, task.ID)
+		txHash, err = ledger.CorrectLog(ctx, task.ID, correction.RationaleHash, correction.ConsensusHash)
+		if err != nil {
+			log.Printf("[Swarm-Worker] ❌ correctLog failed for correction %s: %v", correction.ID, err)
+			// Stay PENDING_COMMIT while asynq retries; a FAILED correction
+			// would be skipped by the retry.
+			if !finalAttempt(ctx) {
+				return fmt.Errorf("correctLog failed: %w", err)
+			}
+			setStatus("", domain.BlockchainStatusFailed)
+			return fmt.Errorf("correctLog failed: %v: %w", err, asynq.SkipRetry)
+		}
+		setStatus(txHash, domain.BlockchainStatusPendingConfirmation)
+	}
+
+	receipt, err := ledger.WaitForConfirmation(ctx, txHash, 90*time.Second)
+	if err != nil {
+		log.Printf("[Swarm-Worker] ⚠️ Wait for confirmation timed out for correction %s, tx: %s. Left to the reconciler", correction.ID, txHash)
+		return nil
+	}
+
+	txHash = receipt.TxHash.Hex()
+	if receipt.Status != 1 {
+		log.Printf("[Swarm-Worker] ❌ Correction %s failed on-chain for task %s", correction.ID, task.ID)
+		setStatus(txHash, domain.BlockchainStatusFailed)
+		return fmt.Errorf("transaction execution failed on chain: %w", asynq.SkipRetry)
+	}
+	log.Printf("[Swarm-Worker] ✅ Correction %s confirmed for task %s in block %d", correction.ID, task.ID, receipt.BlockNumber)
+	setStatus(txHash, domain.BlockchainStatusVerified)
+	return nil
+}
+
+// updateCorrection records a correction's transaction and status. Overrides
+// enqueued before corrections were recorded carry no ID.
+func (h *SwarmTaskHandler) updateCorrection(ctx context.Context, ledger blockchain.Ledger, correctionID, txHash, status string) {
+	if correctionID == "" {
+		return
+	}
+	correction, err := h.correctionRepo.GetByID(ctx, correctionID)
+	if err != nil {
+		log.Printf("[Swarm-Worker] Failed to find correction %s: %v", correctionID, err)
+		return
+	}
+	if txHash != "" {
+		correction.TxHash = txHash
+	}
+	correction.Status = status
+	if ledger != nil {
+		correction.Network = ledger.Network()
+	}
+	if err := h.correctionRepo.Update(ctx, correction); err != nil {
+		log.Printf("[Swarm-Worker] Failed to update correction %s: %v", correctionID, err)
+	}
+}
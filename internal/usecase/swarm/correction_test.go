@@ -0,0 +1,145 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/hibiken/asynq"
+)
+
+func TestHandleLedgerCorrection(t *testing.T) {
+	pending := domain.LedgerCorrection{
+		ID: "correction-1", TaskID: testTaskID, RationaleHash: "r2", ConsensusHash: "c2",
+		Status: domain.BlockchainStatusPendingCommit,
+	}
+	submitted := pending
+	submitted.TxHash, submitted.Status = "0x02", domain.BlockchainStatusPendingConfirmation
+	verified := submitted
+	verified.Status = domain.BlockchainStatusVerified
+
+	cases := []struct {
+		name          string
+		correction    domain.LedgerCorrection
+		review        bool
+		ledger        fakeLedger
+		wantErr       bool
+		wantSkipRetry bool
+		// Statuses written to the task, in order, and the final correction status.
+		wantTask       []string
+		wantCorrection string
+		wantCorrect    bool
+	}{
+		{name: "already verified", correction: verified, wantCorrection: domain.BlockchainStatusVerified},
+		{
+			name: "confirmed", correction: pending, ledger: fakeLedger{receiptStatus: 1}, wantCorrect: true,
+			wantTask:       []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusVerified},
+			wantCorrection: domain.BlockchainStatusVerified,
+		},
+		{
+			name: "reviewer override", correction: pending, review: true, ledger: fakeLedger{receiptStatus: 1}, wantCorrect: true,
+			wantTask:       []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusVerified},
+			wantCorrection: domain.BlockchainStatusVerified,
+		},
+		{
+			name: "submitted by an earlier attempt", correction: submitted, ledger: fakeLedger{receiptStatus: 1},
+			wantTask:       []string{domain.BlockchainStatusVerified},
+			wantCorrection: domain.BlockchainStatusVerified,
+		},
+		{
+			name: "correctLog fails and is retried", correction: pending, ledger: fakeLedger{correctErr: errors.New("rpc down")},
+			wantErr:        true,
+			wantCorrection: domain.BlockchainStatusPendingCommit,
+		},
+		{
+			name: "reverted", correction: pending, ledger: fakeLedger{receiptStatus: 0}, wantCorrect: true,
+			wantErr: true, wantSkipRetry: true,
+			wantTask:       []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusFailed},
+			wantCorrection: domain.BlockchainStatusFailed,
+		},
+		{
+			name: "confirmation left to the reconciler", correction: pending, ledger: fakeLedger{waitErr: errors.New("timeout")}, wantCorrect: true,
+			wantTask:       []string{domain.BlockchainStatusPendingConfirmation},
+			wantCorrection: domain.BlockchainStatusPendingConfirmation,
+		},
+	}
+	for _, c := range cases {
+		db := newTaskDB(t)
+		task := domain.SwarmTask{
+			ID: testTaskID, DocumentID: testDocID, RationaleHash: "r1", ConsensusHash: "c1",
+			BlockchainStat: domain.BlockchainStatusVerified,
+		}
+		if c.review {
+			task.ReviewRationaleHash, task.ReviewConsensusHash = "r2", "c2"
+		}
+		if c.correction.Status != domain.BlockchainStatusVerified {
+			db.expectLoad(task)
+		}
+		for range c.wantTask {
+			db.expectLoad(task)
+			db.expectSave()
+		}
+		correction := c.correction
+		corrections := newFakeCorrections(&correction)
+		ledger := c.ledger
+		h := NewSwarmTaskHandler(db.swarmRepo(), nil, corrections, blockchain.SingleLedger(&ledger), &fakeQueue{})
+		asynqTask, err := NewLedgerCorrectionTask(correction.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.HandleLedgerCorrection(context.Background(), asynqTask)
+		if (err != nil) != c.wantErr || errors.Is(err, asynq.SkipRetry) != c.wantSkipRetry {
+			t.Errorf("%s: err = %v, want error %v, skip retry %v", c.name, err, c.wantErr, c.wantSkipRetry)
+		}
+		column := "blockchain_stat"
+		if c.review {
+			column = "review_chain_stat"
+		}
+		if got := db.saved(column); !reflect.DeepEqual(got, c.wantTask) {
+			t.Errorf("%s: task statuses %v, want %v", c.name, got, c.wantTask)
+		}
+		if got := corrections.byID[correction.ID].Status; got != c.wantCorrection {
+			t.Errorf("%s: correction status %s, want %s", c.name, got, c.wantCorrection)
+		}
+		if got := len(ledger.corrected) == 1; got != c.wantCorrect {
+			t.Errorf("%s: corrected %v, want correctLog %v", c.name, ledger.corrected, c.wantCorrect)
+		}
+	}
+}
+
+// A correction whose submission failed is still submitted by the retry.
+func TestHandleLedgerCorrectionRetry(t *testing.T) {
+	db := newTaskDB(t)
+	task := domain.SwarmTask{ID: testTaskID, DocumentID: testDocID, BlockchainStat: domain.BlockchainStatusVerified}
+	db.expectLoad(task)
+	db.expectLoad(task)
+	for i := 0; i < 2; i++ {
+		db.expectLoad(task)
+		db.expectSave()
+	}
+	corrections := newFakeCorrections(&domain.LedgerCorrection{
+		ID: "correction-1", TaskID: testTaskID, RationaleHash: "r2", ConsensusHash: "c2",
+		Status: domain.BlockchainStatusPendingCommit,
+	})
+	ledger := &fakeLedger{correctErr: errors.New("rpc down"), receiptStatus: 1}
+	h := NewSwarmTaskHandler(db.swarmRepo(), nil, corrections, blockchain.SingleLedger(ledger), &fakeQueue{})
+	asynqTask, err := NewLedgerCorrectionTask("correction-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.HandleLedgerCorrection(context.Background(), asynqTask); err == nil || errors.Is(err, asynq.SkipRetry) {
+		t.Fatalf("first attempt: err = %v, want a retryable error", err)
+	}
+	ledger.correctErr = nil
+	if err := h.HandleLedgerCorrection(context.Background(), asynqTask); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if got := corrections.byID["correction-1"].Status; got != domain.BlockchainStatusVerified {
+		t.Errorf("correction status %s after retry, want VERIFIED", got)
+	}
+}
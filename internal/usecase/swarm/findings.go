@@ -0,0 +1,132 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/pricing"
+	"gorm.io/datatypes"
+)
+
+// Key aliases used by the different swarm agent versions for the same field.
+var (
+	itemNameKeys  = []string{"item", "item_name", "name", "description", "uraian", "nama_barang"}
+	itemCodeKeys  = []string{"item_code", "code", "kode", "kode_barang"}
+	supplierKeys  = []string{"supplier", "vendor", "penyedia"}
+	unitKeys      = []string{"unit", "satuan"}
+	quantityKeys  = []string{"quantity", "qty", "volume", "jumlah"}
+	unitPriceKeys = []string{"unit_price", "price", "harga_satuan", "harga"}
+	refPriceKeys  = []string{"reference_price", "ssh_price", "standard_price", "max_price", "harga_referensi", "harga_ssh"}
+	deviationKeys = []string{"deviation_pct", "deviation", "markup_pct", "markup_percentage", "selisih_persen"}
+	verdictKeys   = []string{"verdict", "decision", "status", "keputusan"}
+	rationaleKeys = []string{"rationale", "reason", "explanation", "alasan"}
+	voteKeys      = []string{"agent_votes", "votes", "agents"}
+)
+
+// ListFindings returns the normalized findings matching the filter.
+func (u *SwarmUsecase) ListFindings(ctx context.Context, filter domain.FindingFilter) ([]*domain.SwarmFinding, int64, error) {
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return u.findingRepo.List(ctx, filter)
+}
+
+// FindingStats aggregates findings for the dashboard.
+func (u *SwarmUsecase) FindingStats(ctx context.Context, filter domain.FindingFilter) (*domain.FindingStats, error) {
+	return u.findingRepo.Stats(ctx, filter)
+}
+
+// syncFindings re-derives the per-item findings of a task from its results.
+func (u *SwarmUsecase) syncFindings(ctx context.Context, task *domain.SwarmTask, results []map[string]interface{}) error {
+	tenantID, err := u.swarmRepo.GetTenantID(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	findings := make([]*domain.SwarmFinding, 0, len(results))
+	for i, item := range results {
+		findings = append(findings, buildFinding(tenantID, task, i, item))
+	}
+
+	if err := u.findingRepo.ReplaceForTask(ctx, task.ID, findings); err != nil {
+		return fmt.Errorf("failed to store findings: %w", err)
+	}
+	return nil
+}
+
+func buildFinding(tenantID string, task *domain.SwarmTask, index int, item map[string]interface{}) *domain.SwarmFinding {
+	f := &domain.SwarmFinding{
+		TenantID:       tenantID,
+		TaskID:         task.ID,
+		DocumentID:     task.DocumentID,
+		ItemIndex:      index,
+		ItemCode:       lookupString(item, itemCodeKeys),
+		ItemName:       lookupString(item, itemNameKeys),
+		Supplier:       lookupString(item, supplierKeys),
+		Unit:           lookupString(item, unitKeys),
+		Quantity:       lookupNumber(item, quantityKeys),
+		UnitPrice:      lookupNumber(item, unitPriceKeys),
+		ReferencePrice: lookupNumber(item, refPriceKeys),
+		DeviationPct:   lookupNumber(item, deviationKeys),
+		Verdict:        strings.ToUpper(lookupString(item, verdictKeys)),
+		Rationale:      lookupString(item, rationaleKeys),
+	}
+
+	if f.DeviationPct == nil && f.UnitPrice != nil && f.ReferencePrice != nil && *f.ReferencePrice > 0 {
+		dev := math.Round((*f.UnitPrice-*f.ReferencePrice) / *f.ReferencePrice * 10000) / 100
+		f.DeviationPct = &dev
+	}
+
+	for _, key := range voteKeys {
+		if votes, ok := item[key]; ok && votes != nil {
+			if b, err := json.Marshal(votes); err == nil {
+				f.AgentVotes = datatypes.JSON(b)
+			}
+			break
+		}
+	}
+	if raw, err := json.Marshal(item); err == nil {
+		f.Raw = datatypes.JSON(raw)
+	}
+
+	return f
+}
+
+func lookupString(item map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		switch v := item[key].(type) {
+		case string:
+			if s := strings.TrimSpace(v); s != "" {
+				return s
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+func lookupNumber(item map[string]interface{}, keys []string) *float64 {
+	for _, key := range keys {
+		switch v := item[key].(type) {
+		case float64:
+			return &v
+		case int:
+			f := float64(v)
+			return &f
+		case string:
+			if f, ok := pricing.ParseRupiah(v); ok {
+				return &f
+			}
+		}
+	}
+	return nil
+}
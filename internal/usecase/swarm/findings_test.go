@@ -0,0 +1,94 @@
+package swarm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+func TestLookupNumber(t *testing.T) {
+	cases := []struct {
+		name string
+		item map[string]interface{}
+		want *float64
+	}{
+		{"json number", map[string]interface{}{"qty": 12.5}, ptr(12.5)},
+		{"int", map[string]interface{}{"quantity": 3}, ptr(3)},
+		{"rupiah string", map[string]interface{}{"harga": "Rp 25.000.000"}, ptr(25000000)},
+		{"magnitude word", map[string]interface{}{"price": "1,5 juta"}, ptr(1500000)},
+		{"first alias wins", map[string]interface{}{"unit_price": 10.0, "price": 20.0}, ptr(10)},
+		{"unparsable string", map[string]interface{}{"price": "n/a"}, nil},
+		{"missing", map[string]interface{}{"other": 1.0}, nil},
+	}
+	keys := []string{"unit_price", "price", "harga", "quantity", "qty"}
+	for _, c := range cases {
+		got := lookupNumber(c.item, keys)
+		switch {
+		case c.want == nil && got != nil:
+			t.Errorf("%s: got %v, want nil", c.name, *got)
+		case c.want != nil && (got == nil || *got != *c.want):
+			t.Errorf("%s: got %v, want %v", c.name, got, *c.want)
+		}
+	}
+}
+
+func TestBuildFinding(t *testing.T) {
+	task := &domain.SwarmTask{ID: "task-1", DocumentID: "doc-1"}
+	item := map[string]interface{}{
+		"nama_barang": " Laptop ",
+		"kode":        1234.0,
+		"penyedia":    "CV Maju",
+		"satuan":      "unit",
+		"volume":      2.0,
+		"harga":       "Rp 15.000.000",
+		"harga_ssh":   "12.000.000",
+		"keputusan":   "markup",
+		"alasan":      "di atas SSH",
+		"votes":       []interface{}{map[string]interface{}{"agent": "a", "vote": "MARKUP"}},
+	}
+
+	f := buildFinding("tenant-1", task, 4, item)
+
+	if f.TenantID != "tenant-1" || f.TaskID != "task-1" || f.DocumentID != "doc-1" || f.ItemIndex != 4 {
+		t.Fatalf("unexpected identity: %+v", f)
+	}
+	if f.ItemName != "Laptop" || f.ItemCode != "1234" || f.Supplier != "CV Maju" || f.Unit != "unit" {
+		t.Errorf("unexpected item fields: %+v", f)
+	}
+	if f.Verdict != "MARKUP" || f.Rationale != "di atas SSH" {
+		t.Errorf("verdict %q rationale %q", f.Verdict, f.Rationale)
+	}
+	if f.Quantity == nil || *f.Quantity != 2 || f.UnitPrice == nil || *f.UnitPrice != 15000000 ||
+		f.ReferencePrice == nil || *f.ReferencePrice != 12000000 {
+		t.Fatalf("unexpected amounts: %v %v %v", f.Quantity, f.UnitPrice, f.ReferencePrice)
+	}
+	// Not reported by the swarm, so derived from the prices.
+	if f.DeviationPct == nil || *f.DeviationPct != 25 {
+		t.Errorf("deviation = %v, want 25", f.DeviationPct)
+	}
+
+	var votes []map[string]interface{}
+	if err := json.Unmarshal(f.AgentVotes, &votes); err != nil || len(votes) != 1 {
+		t.Errorf("agent votes = %s (%v)", f.AgentVotes, err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(f.Raw, &raw); err != nil || raw["penyedia"] != "CV Maju" {
+		t.Errorf("raw = %s (%v)", f.Raw, err)
+	}
+}
+
+func TestBuildFindingReportedDeviation(t *testing.T) {
+	item := map[string]interface{}{"price": 150.0, "reference_price": 100.0, "markup_pct": 7.5}
+	f := buildFinding("tenant-1", &domain.SwarmTask{ID: "task-1"}, 0, item)
+	if f.DeviationPct == nil || *f.DeviationPct != 7.5 {
+		t.Errorf("deviation = %v, want the reported 7.5", f.DeviationPct)
+	}
+
+	f = buildFinding("tenant-1", &domain.SwarmTask{ID: "task-1"}, 0, map[string]interface{}{"price": 150.0, "reference_price": 0.0})
+	if f.DeviationPct != nil {
+		t.Errorf("deviation = %v, want nil without a reference price", *f.DeviationPct)
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
@@ -0,0 +1,276 @@
+package swarm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/hibiken/asynq"
+	gormpg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// taskDB is a sqlmock-backed gorm connection for the swarm repositories.
+// Every UPDATE is recorded in saves by table, column by column, so tests
+// can assert on the statuses a transition wrote.
+type taskDB struct {
+	db    *gorm.DB
+	mock  sqlmock.Sqlmock
+	args  []driver.Value
+	saves map[string][]map[string]driver.Value
+}
+
+var (
+	updateRe     = regexp.MustCompile(`^UPDATE "(\w+)" SET`)
+	setColumnRe  = regexp.MustCompile(`"(\w+)"=\$(\d+)`)
+	nullColumnRe = regexp.MustCompile(`"(\w+)"=NULL`)
+)
+
+func newTaskDB(t *testing.T) *taskDB {
+	t.Helper()
+	d := &taskDB{saves: make(map[string][]map[string]driver.Value)}
+	db, mock, err := sqlmock.New(
+		sqlmock.ValueConverterOption(argRecorder{d}),
+		sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(d.match)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to open mock database: %v", err)
+	}
+	gormDB, err := gorm.Open(gormpg.New(gormpg.Config{Conn: db}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("Failed to open gorm DB: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	d.db, d.mock = gormDB, mock
+	return d
+}
+
+// match matches like sqlmock.QueryMatcherRegexp and records updates.
+func (d *taskDB) match(expectedSQL, actualSQL string) error {
+	args := d.args
+	d.args = nil
+	if err := sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL); err != nil {
+		return err
+	}
+	if m := updateRe.FindStringSubmatch(actualSQL); m != nil {
+		table := m[1]
+		save := make(map[string]driver.Value)
+		for _, m := range setColumnRe.FindAllStringSubmatch(actualSQL, -1) {
+			if i, err := strconv.Atoi(m[2]); err == nil && i <= len(args) {
+				save[m[1]] = args[i-1]
+			}
+		}
+		for _, m := range nullColumnRe.FindAllStringSubmatch(actualSQL, -1) {
+			save[m[1]] = nil
+		}
+		d.saves[table] = append(d.saves[table], save)
+	}
+	return nil
+}
+
+type argRecorder struct{ d *taskDB }
+
+func (r argRecorder) ConvertValue(v interface{}) (driver.Value, error) {
+	value, err := driver.DefaultParameterConverter.ConvertValue(v)
+	r.d.args = append(r.d.args, value)
+	return value, err
+}
+
+func (d *taskDB) swarmRepo() *postgres.SwarmRepository {
+	return postgres.NewSwarmRepository(d.db)
+}
+
+// saved returns column of every recorded task update, in order.
+func (d *taskDB) saved(column string) []string {
+	return d.savedIn("swarm_tasks", column)
+}
+
+// savedIn returns column of every recorded update of table, in order.
+func (d *taskDB) savedIn(table, column string) []string {
+	var out []string
+	for _, save := range d.saves[table] {
+		out = append(out, fmt.Sprint(save[column]))
+	}
+	return out
+}
+
+var taskSchema = func() *schema.Schema {
+	s, err := schema.Parse(&domain.SwarmTask{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(err)
+	}
+	return s
+}()
+
+// expectLoad expects one SELECT of swarm_tasks returning task.
+func (d *taskDB) expectLoad(task domain.SwarmTask) {
+	cols := make([]string, 0, len(taskSchema.DBNames))
+	vals := make([]driver.Value, 0, len(taskSchema.DBNames))
+	rv := reflect.ValueOf(&task).Elem()
+	for _, name := range taskSchema.DBNames {
+		field := taskSchema.LookUpField(name)
+		v, zero := field.ValueOf(context.Background(), rv)
+		if zero {
+			continue
+		}
+		if valuer, ok := v.(driver.Valuer); ok {
+			var err error
+			if v, err = valuer.Value(); err != nil {
+				panic(err)
+			}
+		}
+		if p, ok := v.(*string); ok {
+			v = *p
+		}
+		if p, ok := v.(*int); ok {
+			v = int64(*p)
+		}
+		if p, ok := v.(*time.Time); ok {
+			v = *p
+		}
+		if i, ok := v.(int); ok {
+			v = int64(i)
+		}
+		cols = append(cols, name)
+		vals = append(vals, v)
+	}
+	d.mock.ExpectQuery(`SELECT .* FROM "swarm_tasks"`).WillReturnRows(sqlmock.NewRows(cols).AddRow(vals...))
+}
+
+// expectSave expects a Save of a swarm task; see saves.
+func (d *taskDB) expectSave() {
+	d.mock.ExpectExec(`UPDATE "swarm_tasks" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// fakeQueue records the enqueued tasks.
+type fakeQueue struct {
+	tasks []*asynq.Task
+	err   error
+}
+
+func (q *fakeQueue) EnqueueTask(task *asynq.Task, _ ...asynq.Option) (*asynq.TaskInfo, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	q.tasks = append(q.tasks, task)
+	return &asynq.TaskInfo{ID: fmt.Sprint(len(q.tasks)), Type: task.Type()}, nil
+}
+
+func (q *fakeQueue) Close() error { return nil }
+
+func (q *fakeQueue) types() []string {
+	var out []string
+	for _, t := range q.tasks {
+		out = append(out, t.Type())
+	}
+	return out
+}
+
+// fakeCorrections is an in-memory LedgerCorrectionRepository.
+type fakeCorrections struct {
+	byID map[string]*domain.LedgerCorrection
+}
+
+func newFakeCorrections(corrections ...*domain.LedgerCorrection) *fakeCorrections {
+	r := &fakeCorrections{byID: make(map[string]*domain.LedgerCorrection)}
+	for _, c := range corrections {
+		r.byID[c.ID] = c
+	}
+	return r
+}
+
+func (r *fakeCorrections) Create(_ context.Context, c *domain.LedgerCorrection) error {
+	if c.ID == "" {
+		c.ID = fmt.Sprintf("correction-%d", len(r.byID)+1)
+	}
+	r.byID[c.ID] = c
+	return nil
+}
+
+func (r *fakeCorrections) GetByID(_ context.Context, id string) (*domain.LedgerCorrection, error) {
+	c, ok := r.byID[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (r *fakeCorrections) Update(_ context.Context, c *domain.LedgerCorrection) error {
+	copied := *c
+	r.byID[c.ID] = &copied
+	return nil
+}
+
+func (r *fakeCorrections) ListByTask(_ context.Context, taskID string) ([]*domain.LedgerCorrection, error) {
+	var out []*domain.LedgerCorrection
+	for _, c := range r.byID {
+		if c.TaskID == taskID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// fakeLedger is a Ledger whose writes succeed or fail on demand.
+type fakeLedger struct {
+	insertErr, correctErr, waitErr error
+	receiptStatus                  uint64
+	inserted, corrected            []string
+}
+
+func (l *fakeLedger) InsertLog(_ context.Context, taskID, _, _ string) (string, error) {
+	if l.insertErr != nil {
+		return "", l.insertErr
+	}
+	l.inserted = append(l.inserted, taskID)
+	return "0x01", nil
+}
+
+func (l *fakeLedger) CorrectLog(_ context.Context, taskID, _, _ string) (string, error) {
+	if l.correctErr != nil {
+		return "", l.correctErr
+	}
+	l.corrected = append(l.corrected, taskID)
+	return "0x02", nil
+}
+
+func (l *fakeLedger) VerifyHashes(context.Context, string, string, string) (bool, error) {
+	return true, nil
+}
+
+func (l *fakeLedger) GetActiveLog(context.Context, string) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (l *fakeLedger) GetTaskHistory(context.Context, string) ([]blockchain.LogEntry, error) {
+	return nil, nil
+}
+
+func (l *fakeLedger) WaitForConfirmation(_ context.Context, txHash string, _ time.Duration) (*types.Receipt, error) {
+	if l.waitErr != nil {
+		return nil, l.waitErr
+	}
+	return &types.Receipt{Status: l.receiptStatus, TxHash: common.HexToHash(txHash)}, nil
+}
+
+func (l *fakeLedger) Network() string         { return "fake" }
+func (l *fakeLedger) ContractAddress() string { return "" }
+func (l *fakeLedger) Close()                  {}
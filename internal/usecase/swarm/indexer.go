@@ -0,0 +1,639 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// reconcileBatchSize bounds how many final events one round reconciles.
+	reconcileBatchSize = 200
+	// staleBatchSize bounds how many stale transactions one round looks up.
+	staleBatchSize = 50
+)
+
+// ChainIndexer follows AuditTrail events into chain_events and settles
+// task and batch statuses from them. The asynq workers set a status as soon
+// as they see a receipt; the indexer is the source of truth afterwards: it
+// only trusts events at the configured confirmation depth, demotes statuses
+// whose events were reorged away and flags hashes that do not match.
+// There is one indexer per ledger profile.
+type ChainIndexer struct {
+	profile   string
+	source    blockchain.EventSource
+	ledger    blockchain.Ledger
+	events    domain.ChainEventRepository
+	swarmRepo *postgres.SwarmRepository
+	batchRepo *postgres.AnchorBatchRepository
+	cfg       config.IndexerConfig
+}
+
+// NewChainIndexer creates the indexer of a ledger profile. source may be nil
+// for ledgers without events; only the stale-transaction sweep runs then.
+func NewChainIndexer(profile string, source blockchain.EventSource, ledger blockchain.Ledger, events domain.ChainEventRepository, swarmRepo *postgres.SwarmRepository, batchRepo *postgres.AnchorBatchRepository, cfg config.IndexerConfig) *ChainIndexer {
+	return &ChainIndexer{
+		profile:   blockchain.NormalizeLedgerProfile(profile),
+		source:    source,
+		ledger:    ledger,
+		events:    events,
+		swarmRepo: swarmRepo,
+		batchRepo: batchRepo,
+		cfg:       cfg,
+	}
+}
+
+// Run performs one indexing and reconciliation round.
+func (ix *ChainIndexer) Run(ctx context.Context) error {
+	if ix.source != nil {
+		if err := ix.index(ctx); err != nil {
+			return fmt.Errorf("index: %w", err)
+		}
+		if err := ix.reconcile(ctx); err != nil {
+			return fmt.Errorf("reconcile: %w", err)
+		}
+	}
+	return ix.sweepStale(ctx)
+}
+
+// index scans the next block range, after rolling back anything a reorg
+// replaced, and refreshes the confirmation depth of unfinal events.
+func (ix *ChainIndexer) index(ctx context.Context) error {
+	network, contract := ix.ledger.Network(), ix.ledger.ContractAddress()
+
+	head, err := ix.source.HeadBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := ix.events.GetCursor(ctx, network, contract)
+	if err != nil {
+		return err
+	}
+	if cursor == nil {
+		start := int64(ix.cfg.StartBlock)
+		if start == 0 && head > ix.cfg.MaxRange {
+			start = int64(head - ix.cfg.MaxRange)
+		}
+		cursor = &domain.ChainCursor{Network: network, Contract: contract, Block: start - 1}
+	}
+
+	if reorgFrom, err := ix.detectReorg(ctx, cursor); err != nil {
+		return err
+	} else if reorgFrom >= 0 {
+		if err := ix.rollback(ctx, cursor, reorgFrom); err != nil {
+			return err
+		}
+	}
+
+	from := uint64(cursor.Block + 1)
+	if from <= head {
+		to := min(head, from+ix.cfg.MaxRange-1)
+		found, err := ix.source.FilterEvents(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			rows := make([]*domain.ChainEvent, len(found))
+			for i, e := range found {
+				rows[i] = ix.toRow(network, contract, e, head)
+			}
+			if err := ix.events.Upsert(ctx, rows); err != nil {
+				return err
+			}
+			log.Printf("[Blockchain] Indexed %d events in blocks %d-%d of ledger profile %s", len(rows), from, to, ix.profile)
+		}
+
+		if err := ix.advance(ctx, cursor, from, to); err != nil {
+			return err
+		}
+		if err := ix.events.SaveCursor(ctx, cursor); err != nil {
+			return err
+		}
+	}
+
+	unfinal, err := ix.events.ListUnfinal(ctx, network, contract)
+	if err != nil {
+		return err
+	}
+	for _, e := range unfinal {
+		e.Confirmations = int64(head) - e.BlockNumber + 1
+		e.Final = e.Confirmations >= int64(ix.cfg.Confirmations)
+		if err := ix.events.Update(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance moves the cursor to to and records the hashes of the scanned
+// blocks that fall in the confirmation window.
+func (ix *ChainIndexer) advance(ctx context.Context, cursor *domain.ChainCursor, from, to uint64) error {
+	hashes := recentHashes(cursor)
+	low := int64(to) - int64(max(ix.cfg.Confirmations, 1)) + 1
+	for n := max(int64(from), low); n <= int64(to); n++ {
+		hash, err := ix.source.BlockHash(ctx, uint64(n))
+		if err != nil {
+			return err
+		}
+		hashes[n] = hash
+	}
+	for n := range hashes {
+		if n < low {
+			delete(hashes, n)
+		}
+	}
+	cursor.Block = int64(to)
+	cursor.BlockHash = hashes[int64(to)]
+	return setRecentHashes(cursor, hashes)
+}
+
+// detectReorg returns the lowest block whose stored hash is no longer
+// canonical, or -1. Unfinal events are checked individually; a replaced
+// cursor block rewinds to the common ancestor.
+func (ix *ChainIndexer) detectReorg(ctx context.Context, cursor *domain.ChainCursor) (int64, error) {
+	reorgFrom := int64(-1)
+	lower := func(block int64) {
+		if block < 0 {
+			block = 0
+		}
+		if reorgFrom < 0 || block < reorgFrom {
+			reorgFrom = block
+		}
+	}
+
+	if cursor.BlockHash != "" {
+		hash, err := ix.source.BlockHash(ctx, uint64(cursor.Block))
+		if err != nil {
+			return -1, err
+		}
+		if hash != cursor.BlockHash {
+			ancestor, err := ix.commonAncestor(ctx, cursor)
+			if err != nil {
+				return -1, err
+			}
+			lower(ancestor + 1)
+		}
+	}
+
+	unfinal, err := ix.events.ListUnfinal(ctx, cursor.Network, cursor.Contract)
+	if err != nil {
+		return -1, err
+	}
+	checked := make(map[int64]bool)
+	for _, e := range unfinal {
+		if checked[e.BlockNumber] {
+			continue
+		}
+		checked[e.BlockNumber] = true
+		hash, err := ix.source.BlockHash(ctx, uint64(e.BlockNumber))
+		if err != nil {
+			return -1, err
+		}
+		if hash != e.BlockHash {
+			lower(e.BlockNumber)
+		}
+	}
+	return reorgFrom, nil
+}
+
+// commonAncestor returns the highest recorded block below the cursor that is
+// still canonical. A reorg deeper than the recorded window falls back to
+// rewinding the confirmation depth.
+func (ix *ChainIndexer) commonAncestor(ctx context.Context, cursor *domain.ChainCursor) (int64, error) {
+	hashes := recentHashes(cursor)
+	blocks := make([]int64, 0, len(hashes))
+	for n := range hashes {
+		if n < cursor.Block {
+			blocks = append(blocks, n)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] > blocks[j] })
+	for _, n := range blocks {
+		hash, err := ix.source.BlockHash(ctx, uint64(n))
+		if err != nil {
+			return -1, err
+		}
+		if hash == hashes[n] {
+			return n, nil
+		}
+	}
+	return cursor.Block - int64(ix.cfg.Confirmations) - 1, nil
+}
+
+// rollback removes events from reorged blocks, demotes whatever they had
+// verified and rewinds the cursor so the range is scanned again.
+func (ix *ChainIndexer) rollback(ctx context.Context, cursor *domain.ChainCursor, from int64) error {
+	removed, err := ix.events.RemoveFrom(ctx, cursor.Network, cursor.Contract, from)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Blockchain] ⚠️ Reorg detected from block %d, removed %d events", from, len(removed))
+
+	for _, e := range removed {
+		ix.demote(ctx, e)
+	}
+
+	cursor.Block = from - 1
+	cursor.BlockHash = ""
+	if cursor.Block >= 0 {
+		hash, err := ix.source.BlockHash(ctx, uint64(cursor.Block))
+		if err != nil {
+			return err
+		}
+		cursor.BlockHash = hash
+	}
+	hashes := recentHashes(cursor)
+	for n := range hashes {
+		if n >= from {
+			delete(hashes, n)
+		}
+	}
+	if cursor.BlockHash != "" {
+		hashes[cursor.Block] = cursor.BlockHash
+	}
+	if err := setRecentHashes(cursor, hashes); err != nil {
+		return err
+	}
+	return ix.events.SaveCursor(ctx, cursor)
+}
+
+// recentHashes decodes the cursor's recorded block hashes. An unreadable
+// record is treated as empty; it only makes a reorg rewind further.
+func recentHashes(cursor *domain.ChainCursor) map[int64]string {
+	hashes := make(map[int64]string)
+	if len(cursor.RecentHashes) > 0 {
+		if err := json.Unmarshal(cursor.RecentHashes, &hashes); err != nil {
+			log.Printf("[Blockchain] Ignoring unreadable recent block hashes of %s/%s: %v", cursor.Network, cursor.Contract, err)
+			return make(map[int64]string)
+		}
+	}
+	return hashes
+}
+
+func setRecentHashes(cursor *domain.ChainCursor, hashes map[int64]string) error {
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return fmt.Errorf("failed to encode recent block hashes: %w", err)
+	}
+	cursor.RecentHashes = data
+	return nil
+}
+
+// demote moves a status verified by a reorged transaction back to
+// PENDING_CONFIRMATION; the transaction is usually re-included and
+// reconciled again from its new block.
+func (ix *ChainIndexer) demote(ctx context.Context, e *domain.ChainEvent) {
+	batch, err := ix.lookupBatch(ctx, e.TaskID)
+	if err != nil {
+		log.Printf("[Blockchain] Failed to look up %s for reorg: %v", e.TaskID, err)
+		return
+	}
+	if batch != nil {
+		if batch.TxHash == e.TxHash && batch.Status == domain.BlockchainStatusVerified {
+			batch.Status = domain.BlockchainStatusPendingConfirmation
+			batch.AnchoredAt = nil
+			if err := ix.batchRepo.Update(ctx, batch); err != nil {
+				log.Printf("[Blockchain] Failed to demote batch %s: %v", batch.ID, err)
+			}
+			if err := ix.batchRepo.UpdateTaskStatus(ctx, batch.ID, "", "", domain.BlockchainStatusBatched); err != nil {
+				log.Printf("[Blockchain] Failed to demote tasks of batch %s: %v", batch.ID, err)
+			}
+		}
+		return
+	}
+
+	task, err := ix.lookupTask(ctx, e.TaskID)
+	if err != nil {
+		log.Printf("[Blockchain] Failed to look up %s for reorg: %v", e.TaskID, err)
+		return
+	}
+	if task == nil {
+		return
+	}
+	changed := false
+	if task.BlockchainTx == e.TxHash && task.BlockchainStat == domain.BlockchainStatusVerified {
+		task.BlockchainStat = domain.BlockchainStatusPendingConfirmation
+		changed = true
+	}
+	if task.ReviewTx == e.TxHash && task.ReviewChainStat == domain.BlockchainStatusVerified {
+		task.ReviewChainStat = domain.BlockchainStatusPendingConfirmation
+		changed = true
+	}
+	if changed {
+		task.UpdatedAt = time.Now()
+		if err := ix.swarmRepo.Update(ctx, task); err != nil {
+			log.Printf("[Blockchain] Failed to demote task %s: %v", task.ID, err)
+		}
+	}
+}
+
+func (ix *ChainIndexer) toRow(network, contract string, e blockchain.ChainEvent, head uint64) *domain.ChainEvent {
+	row := &domain.ChainEvent{
+		Network:       network,
+		Contract:      contract,
+		EventName:     e.Name,
+		EntryIndex:    int64(e.EntryIndex),
+		TaskID:        e.TaskID,
+		RationaleHash: e.RationaleHash,
+		ConsensusHash: e.ConsensusHash,
+		TxHash:        e.TxHash,
+		BlockNumber:   int64(e.BlockNumber),
+		BlockHash:     e.BlockHash,
+		LogIndex:      int(e.LogIndex),
+		Confirmations: int64(head) - int64(e.BlockNumber) + 1,
+	}
+	if e.OldIndex != nil {
+		old := int64(*e.OldIndex)
+		row.OldIndex = &old
+	}
+	row.Final = row.Confirmations >= int64(ix.cfg.Confirmations)
+	return row
+}
+
+// reconcile compares every newly final event with the database.
+func (ix *ChainIndexer) reconcile(ctx context.Context) error {
+	pending, err := ix.events.ListUnreconciled(ctx, ix.ledger.Network(), ix.ledger.ContractAddress(), reconcileBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, e := range pending {
+		outcome, reason := ix.reconcileEvent(ctx, e)
+		if outcome == "" {
+			// Transient failure; try again next round.
+			continue
+		}
+		now := time.Now()
+		e.Reconciliation = outcome
+		e.MismatchReason = reason
+		e.ReconciledAt = &now
+		if err := ix.events.Update(ctx, e); err != nil {
+			return err
+		}
+		if outcome == domain.ChainEventMismatch {
+			log.Printf("[Blockchain] ❌ Event %s for %s in tx %s does not match: %s", e.EventName, e.TaskID, e.TxHash, reason)
+		}
+	}
+	return nil
+}
+
+// reconcileEvent returns the outcome for one event, or "" to retry it later.
+func (ix *ChainIndexer) reconcileEvent(ctx context.Context, e *domain.ChainEvent) (string, string) {
+	if e.EventName == blockchain.EventLogInserted {
+		batch, err := ix.lookupBatch(ctx, e.TaskID)
+		if err != nil {
+			log.Printf("[Blockchain] Failed to look up %s: %v", e.TaskID, err)
+			return "", ""
+		}
+		if batch != nil {
+			if batch.LedgerProfile != ix.profile {
+				return domain.ChainEventMismatch, "batch is anchored to ledger profile " + batch.LedgerProfile
+			}
+			return ix.reconcileBatch(ctx, e, batch)
+		}
+	}
+
+	task, err := ix.lookupTask(ctx, e.TaskID)
+	if err != nil {
+		log.Printf("[Blockchain] Failed to look up %s: %v", e.TaskID, err)
+		return "", ""
+	}
+	if task == nil {
+		return domain.ChainEventUnknown, "no task or batch with this ID"
+	}
+	if task.LedgerProfile != ix.profile {
+		// Do not let another ledger's event settle the task's status.
+		return domain.ChainEventMismatch, "task is anchored to ledger profile " + task.LedgerProfile
+	}
+
+	switch e.EventName {
+	case blockchain.EventLogInserted:
+		// A batched task's own log is its reviewer override.
+		if task.BatchID != nil {
+			return ix.reconcileReview(ctx, task, e, e.RationaleHash, e.ConsensusHash)
+		}
+		if !blockchain.HashesEqual(e.RationaleHash, task.RationaleHash) || !blockchain.HashesEqual(e.ConsensusHash, task.ConsensusHash) {
+			ix.setTaskStatus(ctx, task, e.TxHash, domain.BlockchainStatusMismatch, false)
+			return domain.ChainEventMismatch, "anchored hashes differ from the task's machine hashes"
+		}
+		ix.setTaskStatus(ctx, task, e.TxHash, domain.BlockchainStatusVerified, false)
+		return domain.ChainEventMatched, ""
+
+	case blockchain.EventLogCorrected:
+		// LogCorrected carries no hashes; the active log it created does.
+		active, err := ix.ledger.GetActiveLog(ctx, task.ID)
+		if err != nil {
+			log.Printf("[Blockchain] Failed to read active log for %s: %v", task.ID, err)
+			return "", ""
+		}
+		rationale, _ := active["rationale_hash"].(string)
+		consensus, _ := active["consensus_hash"].(string)
+		if task.ReviewRationaleHash != "" {
+			return ix.reconcileReview(ctx, task, e, rationale, consensus)
+		}
+		// A manual correction re-anchoring the machine hashes.
+		if !blockchain.HashesEqual(rationale, task.RationaleHash) || !blockchain.HashesEqual(consensus, task.ConsensusHash) {
+			if task.BlockchainTx != e.TxHash {
+				return domain.ChainEventMatched, "superseded by a later correction"
+			}
+			ix.setTaskStatus(ctx, task, e.TxHash, domain.BlockchainStatusMismatch, false)
+			return domain.ChainEventMismatch, "active log differs from the task's machine hashes"
+		}
+		ix.setTaskStatus(ctx, task, e.TxHash, domain.BlockchainStatusVerified, false)
+		return domain.ChainEventMatched, ""
+	}
+	return domain.ChainEventUnknown, "unexpected event " + e.EventName
+}
+
+func (ix *ChainIndexer) reconcileReview(ctx context.Context, task *domain.SwarmTask, e *domain.ChainEvent, rationale, consensus string) (string, string) {
+	if task.ReviewRationaleHash == "" {
+		return domain.ChainEventMismatch, "task has no reviewer override"
+	}
+	if !blockchain.HashesEqual(rationale, task.ReviewRationaleHash) || !blockchain.HashesEqual(consensus, task.ReviewConsensusHash) {
+		if task.ReviewTx != e.TxHash {
+			// An older correction, superseded by a later override.
+			return domain.ChainEventMatched, "superseded by a later correction"
+		}
+		ix.setTaskStatus(ctx, task, e.TxHash, domain.BlockchainStatusMismatch, true)
+		return domain.ChainEventMismatch, "anchored hashes differ from the reviewer override"
+	}
+	ix.setTaskStatus(ctx, task, e.TxHash, domain.BlockchainStatusVerified, true)
+	return domain.ChainEventMatched, ""
+}
+
+func (ix *ChainIndexer) reconcileBatch(ctx context.Context, e *domain.ChainEvent, batch *domain.AnchorBatch) (string, string) {
+	status, outcome, reason := domain.BlockchainStatusVerified, domain.ChainEventMatched, ""
+	if !blockchain.HashesEqual(e.RationaleHash, batch.MerkleRoot) || !blockchain.HashesEqual(e.ConsensusHash, batch.Commitment) {
+		status, outcome, reason = domain.BlockchainStatusMismatch, domain.ChainEventMismatch, "anchored root or commitment differs from the batch"
+	}
+	if batch.Status == status && batch.TxHash == e.TxHash {
+		return outcome, reason
+	}
+
+	batch.Status = status
+	batch.TxHash = e.TxHash
+	if status == domain.BlockchainStatusVerified && batch.AnchoredAt == nil {
+		now := time.Now()
+		batch.AnchoredAt = &now
+	}
+	if err := ix.batchRepo.Update(ctx, batch); err != nil {
+		log.Printf("[Blockchain] Failed to update batch %s: %v", batch.ID, err)
+		return "", ""
+	}
+	if err := ix.batchRepo.UpdateTaskStatus(ctx, batch.ID, e.TxHash, batch.Network, status); err != nil {
+		log.Printf("[Blockchain] Failed to update tasks of batch %s: %v", batch.ID, err)
+		return "", ""
+	}
+	return outcome, reason
+}
+
+func (ix *ChainIndexer) setTaskStatus(ctx context.Context, task *domain.SwarmTask, txHash, status string, review bool) {
+	if review {
+		if task.ReviewChainStat == status && task.ReviewTx == txHash {
+			return
+		}
+		task.ReviewChainStat = status
+		task.ReviewTx = txHash
+	} else {
+		if task.BlockchainStat == status && task.BlockchainTx == txHash {
+			return
+		}
+		task.BlockchainStat = status
+		task.BlockchainTx = txHash
+		task.BlockchainNet = ix.ledger.Network()
+	}
+	task.UpdatedAt = time.Now()
+	if err := ix.swarmRepo.Update(ctx, task); err != nil {
+		log.Printf("[Blockchain] Failed to update task %s: %v", task.ID, err)
+	}
+}
+
+// sweepStale settles transactions left PENDING_CONFIRMATION by a worker that
+// gave up waiting, from their receipts. Receipts may belong to a fee-bumped
+// replacement, which WaitForConfirmation follows.
+func (ix *ChainIndexer) sweepStale(ctx context.Context) error {
+	before := time.Now().Add(-ix.cfg.StaleAfter)
+
+	tasks, err := ix.swarmRepo.ListPendingConfirmation(ctx, ix.profile, before, staleBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.BlockchainStat == domain.BlockchainStatusPendingConfirmation {
+			if receipt := ix.receipt(ctx, task.BlockchainTx); receipt != nil {
+				ix.setTaskStatus(ctx, task, receipt.TxHash.Hex(), receiptStatus(receipt), false)
+			}
+		}
+		if task.ReviewChainStat == domain.BlockchainStatusPendingConfirmation {
+			if receipt := ix.receipt(ctx, task.ReviewTx); receipt != nil {
+				ix.setTaskStatus(ctx, task, receipt.TxHash.Hex(), receiptStatus(receipt), true)
+			}
+		}
+	}
+
+	batches, err := ix.batchRepo.ListPendingConfirmation(ctx, ix.profile, before, staleBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		receipt := ix.receipt(ctx, batch.TxHash)
+		if receipt == nil {
+			continue
+		}
+		batch.TxHash = receipt.TxHash.Hex()
+		batch.Status = receiptStatus(receipt)
+		if batch.Status == domain.BlockchainStatusVerified {
+			now := time.Now()
+			batch.AnchoredAt = &now
+		}
+		if err := ix.batchRepo.Update(ctx, batch); err != nil {
+			log.Printf("[Blockchain] Failed to update batch %s: %v", batch.ID, err)
+			continue
+		}
+		if err := ix.batchRepo.UpdateTaskStatus(ctx, batch.ID, batch.TxHash, batch.Network, batch.Status); err != nil {
+			log.Printf("[Blockchain] Failed to update tasks of batch %s: %v", batch.ID, err)
+		}
+	}
+	return nil
+}
+
+// receipt returns the mined receipt for txHash, or nil if it is still pending.
+func (ix *ChainIndexer) receipt(ctx context.Context, txHash string) *types.Receipt {
+	receipt, err := ix.ledger.WaitForConfirmation(ctx, txHash, 5*time.Second)
+	if err != nil {
+		return nil
+	}
+	return receipt
+}
+
+func receiptStatus(receipt *types.Receipt) string {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return domain.BlockchainStatusVerified
+	}
+	return domain.BlockchainStatusFailed
+}
+
+// lookupBatch and lookupTask resolve an on-chain ID, returning nil if this
+// database has no such batch or task. IDs that are not UUIDs cannot match.
+func (ix *ChainIndexer) lookupBatch(ctx context.Context, id string) (*domain.AnchorBatch, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	batch, err := ix.batchRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return batch, err
+}
+
+func (ix *ChainIndexer) lookupTask(ctx context.Context, id string) (*domain.SwarmTask, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	task, err := ix.swarmRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return task, err
+}
+
+// StartChainIndexer runs the indexer every cfg.Interval.
+func StartChainIndexer(ix *ChainIndexer, cfg config.IndexerConfig) (gocron.Scheduler, error) {
+	s, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	_, err = s.NewJob(
+		gocron.DurationJob(cfg.Interval),
+		gocron.NewTask(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*cfg.Interval)
+			defer cancel()
+
+			if err := ix.Run(ctx); err != nil {
+				log.Printf("[Blockchain] Indexer round failed for ledger profile %s: %v", ix.profile, err)
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule chain indexer: %w", err)
+	}
+
+	log.Printf("Chain indexer for ledger profile %s scheduled every %s (%d confirmations)", ix.profile, cfg.Interval, cfg.Confirmations)
+	s.Start()
+	return s, nil
+}
@@ -0,0 +1,205 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+)
+
+// fakeChainEvents is an in-memory ChainEventRepository keyed like the
+// chain_events unique constraint.
+type fakeChainEvents struct {
+	cursor      *domain.ChainCursor
+	events      []*domain.ChainEvent
+	removedFrom []int64
+}
+
+func (r *fakeChainEvents) GetCursor(context.Context, string, string) (*domain.ChainCursor, error) {
+	if r.cursor == nil {
+		return nil, nil
+	}
+	copied := *r.cursor
+	return &copied, nil
+}
+
+func (r *fakeChainEvents) SaveCursor(_ context.Context, cursor *domain.ChainCursor) error {
+	copied := *cursor
+	r.cursor = &copied
+	return nil
+}
+
+func (r *fakeChainEvents) Upsert(_ context.Context, events []*domain.ChainEvent) error {
+	for _, e := range events {
+		existing := r.find(e.BlockHash, e.LogIndex)
+		if existing == nil {
+			copied := *e
+			copied.ID = fmt.Sprint(len(r.events) + 1)
+			r.events = append(r.events, &copied)
+			continue
+		}
+		existing.Removed, existing.Confirmations, existing.Final = e.Removed, e.Confirmations, e.Final
+	}
+	return nil
+}
+
+func (r *fakeChainEvents) find(blockHash string, logIndex int) *domain.ChainEvent {
+	for _, e := range r.events {
+		if e.BlockHash == blockHash && e.LogIndex == logIndex {
+			return e
+		}
+	}
+	return nil
+}
+
+func (r *fakeChainEvents) ListUnfinal(context.Context, string, string) ([]*domain.ChainEvent, error) {
+	var out []*domain.ChainEvent
+	for _, e := range r.events {
+		if !e.Removed && !e.Final {
+			copied := *e
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeChainEvents) Update(_ context.Context, event *domain.ChainEvent) error {
+	for i, e := range r.events {
+		if e.ID == event.ID {
+			copied := *event
+			r.events[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeChainEvents) RemoveFrom(_ context.Context, _, _ string, block int64) ([]*domain.ChainEvent, error) {
+	r.removedFrom = append(r.removedFrom, block)
+	var out []*domain.ChainEvent
+	for _, e := range r.events {
+		if !e.Removed && e.BlockNumber >= block {
+			e.Removed, e.Final, e.ReconciledAt = true, false, nil
+			copied := *e
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeChainEvents) ListUnreconciled(context.Context, string, string, int) ([]*domain.ChainEvent, error) {
+	return nil, nil
+}
+
+func (r *fakeChainEvents) ListByTask(_ context.Context, taskID string) ([]*domain.ChainEvent, error) {
+	var out []*domain.ChainEvent
+	for _, e := range r.events {
+		if e.TaskID == taskID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// live returns the task IDs of the events not removed by a reorg.
+func (r *fakeChainEvents) live() []string {
+	var out []string
+	for _, e := range r.events {
+		if !e.Removed {
+			out = append(out, e.TaskID)
+		}
+	}
+	return out
+}
+
+func TestChainIndexerRewindsToCommonAncestor(t *testing.T) {
+	ctx := context.Background()
+	l, err := blockchain.NewSimulatedLedger("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	insert := func(taskID string) uint64 {
+		tx, err := l.InsertLog(ctx, taskID, fmt.Sprintf("0x%064x", 1), fmt.Sprintf("0x%064x", 2))
+		if err != nil {
+			t.Fatalf("InsertLog %s: %v", taskID, err)
+		}
+		receipt, err := l.WaitForConfirmation(ctx, tx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return receipt.BlockNumber.Uint64()
+	}
+	// Task IDs are not UUIDs, so demoting never reaches the database.
+	insert("task-1")
+	l.Commit()
+	second := insert("task-2")
+	for i := 0; i < 3; i++ {
+		l.Commit()
+	}
+
+	events := &fakeChainEvents{}
+	ix := NewChainIndexer("default", l, l, events, nil, nil, config.IndexerConfig{Confirmations: 20, MaxRange: 100})
+	if err := ix.index(ctx); err != nil {
+		t.Fatal(err)
+	}
+	head, _ := l.HeadBlock(ctx)
+	if got := events.live(); len(got) != 2 || events.cursor.Block != int64(head) {
+		t.Fatalf("indexed %v up to %d, want both tasks up to %d", got, events.cursor.Block, head)
+	}
+
+	// A reorg of the empty blocks above task-2 only rescans those blocks,
+	// although the confirmation window reaches back past both events.
+	ancestor := head - 2
+	if err := l.Fork(ctx, ancestor); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		l.Commit()
+	}
+	if err := ix.index(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.removedFrom) != 1 || events.removedFrom[0] != int64(ancestor+1) {
+		t.Errorf("rolled back from %v, want block %d", events.removedFrom, ancestor+1)
+	}
+	head, _ = l.HeadBlock(ctx)
+	canonical, _ := l.BlockHash(ctx, head)
+	if got := events.live(); len(got) != 2 {
+		t.Errorf("live events %v after a reorg above them", got)
+	}
+	if events.cursor.Block != int64(head) || events.cursor.BlockHash != canonical {
+		t.Errorf("cursor at %d %s, want the new head %d %s", events.cursor.Block, events.cursor.BlockHash, head, canonical)
+	}
+
+	// A reorg replacing task-2's block removes only task-2's event. The
+	// node re-includes the dropped transaction, so the rescan indexes it
+	// again from its new block.
+	if err := l.Fork(ctx, second-1); err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < head-second+2; i++ {
+		l.Commit()
+	}
+	if err := ix.index(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if last := events.removedFrom[len(events.removedFrom)-1]; last != int64(second) {
+		t.Errorf("rolled back from %d, want task-2's block %d", last, second)
+	}
+	var removed []string
+	for _, e := range events.events {
+		if e.Removed {
+			removed = append(removed, e.TaskID)
+		}
+	}
+	if len(removed) != 1 || removed[0] != "task-2" {
+		t.Errorf("removed events %v, want task-2's original event", removed)
+	}
+	if got := events.live(); len(got) != 2 || got[0] != "task-1" || got[1] != "task-2" {
+		t.Errorf("live events %v, want task-1 and task-2's re-included event", got)
+	}
+}
@@ -0,0 +1,278 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"gorm.io/gorm"
+)
+
+// RerunOptions override what a re-run sends to the swarm. Empty fields reuse
+// the original task's model and items.
+type RerunOptions struct {
+	Model string
+	Items []map[string]interface{}
+}
+
+// OverrideInput is a human reviewer's verdict on a completed task.
+type OverrideInput struct {
+	Verdict       string
+	Justification string
+	ReviewerID    string
+}
+
+// GetTaskForTenant loads a task owned by the tenant.
+func (u *SwarmUsecase) GetTaskForTenant(ctx context.Context, tenantID, id string) (*domain.SwarmTask, error) {
+	task, err := u.swarmRepo.GetByIDForTenant(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// CancelTask stops a task that has not finished yet. The payload is removed
+// from the swarm queue when it has not been picked up, the task id is added
+// to the cancelled set the swarm workers check, and later callbacks for the
+// task are rejected.
+func (u *SwarmUsecase) CancelTask(ctx context.Context, tenantID, id, userID string) (*domain.SwarmTask, error) {
+	task, err := u.GetTaskForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.IsTerminal() {
+		return nil, fmt.Errorf("%w: task is already %s", ErrInvalidTransition, task.Status)
+	}
+
+	now := time.Now()
+	task.Status = domain.SwarmStatusCancelled
+	task.CancelledAt = &now
+	task.CancelledBy = &userID
+	task.UpdatedAt = now
+	if err := u.swarmRepo.Update(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to cancel task: %w", err)
+	}
+
+	if redisCache, ok := u.redis.(*cache.RedisCache); ok {
+		client := redisCache.GetClient()
+		if err := client.SAdd(ctx, swarmCancelledKey, task.ID).Err(); err != nil {
+			log.Printf("[Swarm] Failed to mark task %s as cancelled in redis: %v", task.ID, err)
+		}
+
+		// Best effort: drop the payload if no worker has popped it yet.
+		queued, err := client.LRange(ctx, swarmQueueKey, 0, -1).Result()
+		if err == nil {
+			for _, raw := range queued {
+				var payload domain.SwarmPayload
+				if json.Unmarshal([]byte(raw), &payload) == nil && payload.TaskID == task.ID {
+					client.LRem(ctx, swarmQueueKey, 0, raw)
+				}
+			}
+		}
+
+		u.publishEvent(ctx, map[string]interface{}{
+			"task_id": task.ID,
+			"status":  task.Status,
+		})
+	}
+
+	return task, nil
+}
+
+// RerunTask creates a new task linked to a finished one and sends it to the
+// swarm again, optionally with a different model or items.
+func (u *SwarmUsecase) RerunTask(ctx context.Context, tenantID, id string, opts RerunOptions) (*domain.SwarmTask, error) {
+	parent, err := u.GetTaskForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !parent.IsTerminal() {
+		return nil, fmt.Errorf("%w: task is still %s, cancel it first", ErrInvalidTransition, parent.Status)
+	}
+
+	items := opts.Items
+	if len(items) == 0 {
+		if len(parent.Items) == 0 {
+			return nil, fmt.Errorf("%w: original items were not stored, provide items to re-run", ErrInvalidTransition)
+		}
+		if err := json.Unmarshal(parent.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to decode stored items: %w", err)
+		}
+	}
+	itemsBytes, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal items: %w", err)
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = parent.Model
+	}
+
+	task := &domain.SwarmTask{
+		DocumentID:   parent.DocumentID,
+		Status:       domain.SwarmStatusPending,
+		Items:        itemsBytes,
+		Model:        model,
+		ParentTaskID: &parent.ID,
+	}
+	if err := u.swarmRepo.Create(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to create swarm task: %w", err)
+	}
+	if err := u.enqueue(ctx, task, items); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// OverrideVerdict records a reviewer's verdict next to the machine verdict.
+// When the machine result was anchored on-chain, the review is anchored too
+// by superseding the task's active log through correctLog.
+func (u *SwarmUsecase) OverrideVerdict(ctx context.Context, tenantID, id string, in OverrideInput) (*domain.SwarmTask, error) {
+	verdict := strings.ToUpper(strings.TrimSpace(in.Verdict))
+	justification := strings.TrimSpace(in.Justification)
+	if verdict == "" || justification == "" {
+		return nil, ErrInvalidOverride
+	}
+
+	task, err := u.GetTaskForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status == domain.SwarmStatusCancelled || len(task.Results) == 0 {
+		return nil, fmt.Errorf("%w: task has no machine verdict to override", ErrInvalidTransition)
+	}
+	switch task.ReviewChainStat {
+	case domain.BlockchainStatusPendingCommit, domain.BlockchainStatusPendingConfirmation:
+		return nil, fmt.Errorf("%w: previous override is still being anchored", ErrInvalidTransition)
+	}
+
+	previousRationale, previousConsensus := task.AnchoredHashes()
+	now := time.Now().UTC().Truncate(time.Second)
+	rationaleHash, consensusHash, err := blockchain.ComputeReviewHashes(task.ID, task.ConsensusHash, verdict, justification, in.ReviewerID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	task.ReviewVerdict = verdict
+	task.ReviewJustification = justification
+	task.ReviewedBy = &in.ReviewerID
+	task.ReviewedAt = &now
+	task.ReviewRationaleHash = rationaleHash
+	task.ReviewConsensusHash = consensusHash
+	task.ReviewTx = ""
+	task.ReviewChainStat = ""
+
+	anchor := u.ledgers != nil && task.RationaleHash != "" && task.ConsensusHash != ""
+	if anchor {
+		task.ReviewChainStat = domain.BlockchainStatusPendingCommit
+	}
+	task.UpdatedAt = time.Now()
+	if err := u.swarmRepo.Update(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to store override: %w", err)
+	}
+
+	if anchor {
+		correction := &domain.LedgerCorrection{
+			TaskID:                task.ID,
+			Source:                domain.CorrectionSourceOverride,
+			Justification:         justification,
+			RequestedBy:           in.ReviewerID,
+			PreviousRationaleHash: previousRationale,
+			PreviousConsensusHash: previousConsensus,
+			RationaleHash:         rationaleHash,
+			ConsensusHash:         consensusHash,
+			Status:                domain.BlockchainStatusPendingCommit,
+		}
+		var correctionID string
+		if err := u.correctionRepo.Create(ctx, correction); err != nil {
+			log.Printf("[Swarm] Failed to record override correction for task %s: %v", task.ID, err)
+		} else {
+			correctionID = correction.ID
+		}
+
+		asynqTask, err := NewCorrectSwarmOnBlockchainTask(task.ID, rationaleHash, consensusHash, correctionID)
+		if err != nil {
+			log.Printf("[Swarm] Failed to create blockchain correction task for task %s: %v", task.ID, err)
+		} else if _, err := u.mqClient.EnqueueTask(asynqTask); err != nil {
+			log.Printf("[Swarm] Failed to enqueue blockchain correction task for task %s: %v", task.ID, err)
+		}
+	}
+
+	u.publishEvent(ctx, map[string]interface{}{
+		"task_id":        task.ID,
+		"status":         task.Status,
+		"review_verdict": task.ReviewVerdict,
+	})
+
+	return task, nil
+}
+
+// PublishTask makes a task verifiable by the public: its anchored hashes can
+// be checked and its proof bundle downloaded without an account. Only tasks
+// whose current result is confirmed on the ledger can be published.
+func (u *SwarmUsecase) PublishTask(ctx context.Context, tenantID, id, userID string) (*domain.SwarmTask, error) {
+	task, err := u.GetTaskForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.BlockchainStat != domain.BlockchainStatusVerified {
+		return nil, fmt.Errorf("%w: task result is not confirmed on the ledger", ErrInvalidTransition)
+	}
+	switch task.ReviewChainStat {
+	case domain.BlockchainStatusPendingCommit, domain.BlockchainStatusPendingConfirmation:
+		return nil, fmt.Errorf("%w: override is still being anchored", ErrInvalidTransition)
+	}
+	if task.PublishedAt != nil {
+		return task, nil
+	}
+
+	now := time.Now()
+	task.PublishedAt = &now
+	task.PublishedBy = &userID
+	task.UpdatedAt = now
+	if err := u.swarmRepo.Update(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to publish task: %w", err)
+	}
+	return task, nil
+}
+
+// UnpublishTask withdraws a task from the public endpoints.
+func (u *SwarmUsecase) UnpublishTask(ctx context.Context, tenantID, id string) (*domain.SwarmTask, error) {
+	task, err := u.GetTaskForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.PublishedAt == nil {
+		return task, nil
+	}
+
+	task.PublishedAt = nil
+	task.PublishedBy = nil
+	task.UpdatedAt = time.Now()
+	if err := u.swarmRepo.Update(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to unpublish task: %w", err)
+	}
+	return task, nil
+}
+
+func (u *SwarmUsecase) publishEvent(ctx context.Context, event map[string]interface{}) {
+	redisCache, ok := u.redis.(*cache.RedisCache)
+	if !ok {
+		return
+	}
+	event["timestamp"] = time.Now().UnixNano() / int64(time.Millisecond)
+	data, _ := json.Marshal(event)
+	redisCache.GetClient().Publish(ctx, swarmEventsKey, data)
+}
@@ -0,0 +1,226 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/alicebob/miniredis/v2"
+	"gorm.io/datatypes"
+)
+
+const (
+	testTenantID = "11111111-1111-1111-1111-111111111111"
+	testTaskID   = "22222222-2222-2222-2222-222222222222"
+	testDocID    = "33333333-3333-3333-3333-333333333333"
+)
+
+func TestSwarmStatusTransitions(t *testing.T) {
+	cases := []struct {
+		reported   string
+		normalized string
+		terminal   bool
+	}{
+		{"PENDING", domain.SwarmStatusPending, false},
+		{"processing", domain.SwarmStatusProcessing, false},
+		{"in-progress", domain.SwarmStatusProcessing, false},
+		{"Running", domain.SwarmStatusProcessing, false},
+		{"completed", domain.SwarmStatusCompleted, true},
+		{" success ", domain.SwarmStatusCompleted, true},
+		{"done", domain.SwarmStatusCompleted, true},
+		{"error", domain.SwarmStatusFailed, true},
+		{"FAILED", domain.SwarmStatusFailed, true},
+		{"canceled", domain.SwarmStatusCancelled, true},
+		{"debating", "DEBATING", false},
+	}
+	for _, c := range cases {
+		if got := domain.NormalizeSwarmStatus(c.reported); got != c.normalized {
+			t.Errorf("NormalizeSwarmStatus(%q) = %q, want %q", c.reported, got, c.normalized)
+		}
+		task := &domain.SwarmTask{Status: c.reported}
+		if got := task.IsTerminal(); got != c.terminal {
+			t.Errorf("IsTerminal(%q) = %v, want %v", c.reported, got, c.terminal)
+		}
+	}
+}
+
+func TestCancelTask(t *testing.T) {
+	cases := []struct {
+		status  string
+		wantErr error
+	}{
+		{domain.SwarmStatusPending, nil},
+		{domain.SwarmStatusProcessing, nil},
+		{"completed", ErrInvalidTransition},
+		{domain.SwarmStatusFailed, ErrInvalidTransition},
+		{domain.SwarmStatusCancelled, ErrInvalidTransition},
+	}
+	for _, c := range cases {
+		db := newTaskDB(t)
+		db.expectLoad(domain.SwarmTask{ID: testTaskID, DocumentID: testDocID, Status: c.status})
+		if c.wantErr == nil {
+			db.expectSave()
+		}
+		u := NewSwarmUsecase(db.swarmRepo(), nil, nil, newFakeCorrections(), nil, nil, &fakeQueue{}, config.AnchorBatchConfig{}, nil)
+
+		task, err := u.CancelTask(context.Background(), testTenantID, testTaskID, "user-1")
+		if !errors.Is(err, c.wantErr) {
+			t.Errorf("%s: err = %v, want %v", c.status, err, c.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if task.Status != domain.SwarmStatusCancelled || task.CancelledAt == nil {
+			t.Errorf("%s: task not cancelled: %+v", c.status, task)
+		}
+		if got := db.saved("status"); len(got) != 1 || got[0] != domain.SwarmStatusCancelled {
+			t.Errorf("%s: saved statuses %v", c.status, got)
+		}
+		if got := db.saved("cancelled_by"); len(got) != 1 || got[0] != "user-1" {
+			t.Errorf("%s: saved cancelled_by %v", c.status, got)
+		}
+	}
+}
+
+func TestRerunTask(t *testing.T) {
+	items := datatypes.JSON(`[{"item":"Laptop","price":15000000}]`)
+	cases := []struct {
+		name    string
+		parent  domain.SwarmTask
+		opts    RerunOptions
+		wantErr error
+	}{
+		{"running parent", domain.SwarmTask{Status: domain.SwarmStatusProcessing, Items: items}, RerunOptions{}, ErrInvalidTransition},
+		{"no stored items", domain.SwarmTask{Status: domain.SwarmStatusCompleted}, RerunOptions{}, ErrInvalidTransition},
+		{"stored items", domain.SwarmTask{Status: domain.SwarmStatusCompleted, Items: items, Model: "gemini"}, RerunOptions{}, nil},
+		{"new items and model", domain.SwarmTask{Status: domain.SwarmStatusCancelled}, RerunOptions{
+			Model: "gpt", Items: []map[string]interface{}{{"item": "Printer"}},
+		}, nil},
+	}
+	for _, c := range cases {
+		mr := miniredis.RunT(t)
+		redis, err := cache.NewRedisCache(&config.Config{Redis: config.RedisConfig{Host: mr.Host(), Port: mr.Port(), PoolSize: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		db := newTaskDB(t)
+		c.parent.ID, c.parent.DocumentID = testTaskID, testDocID
+		db.expectLoad(c.parent)
+		if c.wantErr == nil {
+			db.mock.ExpectQuery(`INSERT INTO "swarm_tasks"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("44444444-4444-4444-4444-444444444444"))
+		}
+		u := NewSwarmUsecase(db.swarmRepo(), nil, nil, newFakeCorrections(), redis, nil, &fakeQueue{}, config.AnchorBatchConfig{}, nil)
+
+		task, err := u.RerunTask(context.Background(), testTenantID, testTaskID, c.opts)
+		if !errors.Is(err, c.wantErr) {
+			t.Errorf("%s: err = %v, want %v", c.name, err, c.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if task.Status != domain.SwarmStatusPending || task.ParentTaskID == nil || *task.ParentTaskID != testTaskID {
+			t.Errorf("%s: unexpected child task %+v", c.name, task)
+		}
+		queued, err := mr.List(swarmQueueKey)
+		if err != nil || len(queued) != 1 {
+			t.Fatalf("%s: queue = %v (%v)", c.name, queued, err)
+		}
+		var payload domain.SwarmPayload
+		if err := json.Unmarshal([]byte(queued[0]), &payload); err != nil {
+			t.Fatal(err)
+		}
+		wantModel := c.opts.Model
+		if wantModel == "" {
+			wantModel = c.parent.Model
+		}
+		if payload.ParentTaskID != testTaskID || payload.Model != wantModel || len(payload.Items) != 1 {
+			t.Errorf("%s: unexpected payload %+v", c.name, payload)
+		}
+	}
+}
+
+func TestOverrideVerdict(t *testing.T) {
+	results := datatypes.JSON(`[{"item":"Laptop","verdict":"MARKUP"}]`)
+	anchored := domain.SwarmTask{
+		Status: domain.SwarmStatusCompleted, Results: results,
+		RationaleHash: "aa", ConsensusHash: "bb", BlockchainStat: domain.BlockchainStatusVerified,
+	}
+	input := OverrideInput{Verdict: "wajar", Justification: "harga sesuai kontrak", ReviewerID: "reviewer-1"}
+
+	cases := []struct {
+		name        string
+		task        *domain.SwarmTask
+		input       OverrideInput
+		wantErr     error
+		wantStatus  string
+		wantEnqueue bool
+	}{
+		{"missing justification", nil, OverrideInput{Verdict: "WAJAR"}, ErrInvalidOverride, "", false},
+		{"cancelled task", &domain.SwarmTask{Status: domain.SwarmStatusCancelled, Results: results}, input, ErrInvalidTransition, "", false},
+		{"no machine verdict", &domain.SwarmTask{Status: domain.SwarmStatusCompleted}, input, ErrInvalidTransition, "", false},
+		{"override being anchored", &domain.SwarmTask{
+			Status: domain.SwarmStatusCompleted, Results: results, ReviewChainStat: domain.BlockchainStatusPendingConfirmation,
+		}, input, ErrInvalidTransition, "", false},
+		{"unanchored task", &domain.SwarmTask{Status: domain.SwarmStatusCompleted, Results: results}, input, nil, "", false},
+		{"anchored task", &anchored, input, nil, domain.BlockchainStatusPendingCommit, true},
+	}
+	for _, c := range cases {
+		db := newTaskDB(t)
+		if c.task != nil {
+			task := *c.task
+			task.ID, task.DocumentID = testTaskID, testDocID
+			db.expectLoad(task)
+			if c.wantErr == nil {
+				db.expectSave()
+			}
+		}
+		queue := &fakeQueue{}
+		corrections := newFakeCorrections()
+		u := NewSwarmUsecase(db.swarmRepo(), nil, nil, corrections, nil, blockchain.SingleLedger(&fakeLedger{}), queue, config.AnchorBatchConfig{}, nil)
+
+		task, err := u.OverrideVerdict(context.Background(), testTenantID, testTaskID, c.input)
+		if !errors.Is(err, c.wantErr) {
+			t.Errorf("%s: err = %v, want %v", c.name, err, c.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if task.ReviewVerdict != "WAJAR" || task.ReviewRationaleHash == "" || task.ReviewChainStat != c.wantStatus {
+			t.Errorf("%s: unexpected review %+v", c.name, task)
+		}
+		if got := db.saved("review_chain_stat"); len(got) != 1 || got[0] != c.wantStatus {
+			t.Errorf("%s: saved review statuses %v, want [%s]", c.name, got, c.wantStatus)
+		}
+		if got := len(queue.tasks) == 1; got != c.wantEnqueue {
+			t.Errorf("%s: enqueued %v, want enqueue %v", c.name, queue.types(), c.wantEnqueue)
+		}
+		if !c.wantEnqueue {
+			continue
+		}
+		if queue.tasks[0].Type() != TypeCorrectSwarmOnBlockchain {
+			t.Errorf("%s: enqueued %s", c.name, queue.tasks[0].Type())
+		}
+		var payload CommitBlockchainPayload
+		if err := json.Unmarshal(queue.tasks[0].Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		correction, err := corrections.GetByID(context.Background(), payload.CorrectionID)
+		if err != nil {
+			t.Fatalf("%s: correction not recorded: %v", c.name, err)
+		}
+		if correction.Status != domain.BlockchainStatusPendingCommit || correction.PreviousRationaleHash != "aa" ||
+			correction.RationaleHash != task.ReviewRationaleHash {
+			t.Errorf("%s: unexpected correction %+v", c.name, correction)
+		}
+	}
+}
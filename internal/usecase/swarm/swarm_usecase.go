@@ -3,36 +3,75 @@ package swarm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mq"
 	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/report"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
+var (
+	// ErrHashMismatch is returned when the hashes reported by the swarm do not
+	// match the ones recomputed from the callback's results and transcript.
+	ErrHashMismatch = errors.New("swarm hash mismatch")
+	// ErrUnsupportedHashVersion is returned when the swarm hashed with
+	// another version of the canonical hashing spec than this backend.
+	ErrUnsupportedHashVersion = errors.New("unsupported swarm hash version")
+	// ErrTaskCancelled is returned for callbacks on a task that was cancelled.
+	ErrTaskCancelled = errors.New("swarm task was cancelled")
+	// ErrTaskNotFound is returned when a task does not exist for the tenant.
+	ErrTaskNotFound = errors.New("swarm task not found")
+	// ErrInvalidTransition is returned when a lifecycle action does not apply
+	// to the task's current state.
+	ErrInvalidTransition = errors.New("invalid swarm task state for this action")
+	// ErrInvalidOverride is returned when an override lacks a verdict or justification.
+	ErrInvalidOverride = errors.New("verdict and justification are required")
+)
+
+const (
+	swarmQueueKey     = "swarm:tasks"
+	swarmCancelledKey = "swarm:cancelled"
+	swarmEventsKey    = "swarm:events"
+)
+
 type SwarmUsecase struct {
-	swarmRepo         *postgres.SwarmRepository
-	redis             cache.Cache
-	blockchainService *blockchain.AuditTrailService
-	mqClient          mq.TaskQueue
+	swarmRepo      *postgres.SwarmRepository
+	findingRepo    *postgres.SwarmFindingRepository
+	batchRepo      *postgres.AnchorBatchRepository
+	correctionRepo domain.LedgerCorrectionRepository
+	redis          cache.Cache
+	ledgers        *blockchain.Ledgers
+	mqClient       mq.TaskQueue
+	batchCfg       config.AnchorBatchConfig
+	// logos reads tenant logos kept in object storage; nil when storage is
+	// not configured.
+	logos report.LogoStore
 }
 
-func NewSwarmUsecase(swarmRepo *postgres.SwarmRepository, redis cache.Cache, bcService *blockchain.AuditTrailService, mqClient mq.TaskQueue) *SwarmUsecase {
+func NewSwarmUsecase(swarmRepo *postgres.SwarmRepository, findingRepo *postgres.SwarmFindingRepository, batchRepo *postgres.AnchorBatchRepository, correctionRepo domain.LedgerCorrectionRepository, redis cache.Cache, ledgers *blockchain.Ledgers, mqClient mq.TaskQueue, batchCfg config.AnchorBatchConfig, logos report.LogoStore) *SwarmUsecase {
 	return &SwarmUsecase{
-		swarmRepo:         swarmRepo,
-		redis:             redis,
-		blockchainService: bcService,
-		mqClient:          mqClient,
+		swarmRepo:      swarmRepo,
+		findingRepo:    findingRepo,
+		batchRepo:      batchRepo,
+		correctionRepo: correctionRepo,
+		redis:          redis,
+		ledgers:        ledgers,
+		mqClient:       mqClient,
+		batchCfg:       batchCfg,
+		logos:          logos,
 	}
 }
 
-func (u *SwarmUsecase) TriggerSwarm(ctx context.Context, documentID string, items []map[string]interface{}, tenantIDStr string, userIDStr string) (*domain.SwarmTask, error) {
+func (u *SwarmUsecase) TriggerSwarm(ctx context.Context, documentID string, items []map[string]interface{}, model string, tenantIDStr string, userIDStr string) (*domain.SwarmTask, error) {
 	var finalDocID string = documentID
 	if _, err := uuid.Parse(documentID); err != nil {
 		// Document ID is not a valid UUID (e.g. "draft-1")
@@ -76,40 +115,56 @@ func (u *SwarmUsecase) TriggerSwarm(ctx context.Context, documentID string, item
 	}
 
 	// 1. Create Task in DB
+	itemsBytes, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal items: %w", err)
+	}
 	task := &domain.SwarmTask{
 		DocumentID: finalDocID,
-		Status:     "PENDING",
+		Status:     domain.SwarmStatusPending,
+		Items:      datatypes.JSON(itemsBytes),
+		Model:      model,
 	}
 
 	if err := u.swarmRepo.Create(ctx, task); err != nil {
 		return nil, fmt.Errorf("failed to create swarm task: %w", err)
 	}
 
-	// 2. Prepare Payload
+	// 2. Publish to the swarm queue
+	if err := u.enqueue(ctx, task, items); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// enqueue pushes a task's payload onto the swarm work queue.
+func (u *SwarmUsecase) enqueue(ctx context.Context, task *domain.SwarmTask, items []map[string]interface{}) error {
 	payload := domain.SwarmPayload{
 		TaskID:       task.ID,
-		DocumentID:   finalDocID,
+		DocumentID:   task.DocumentID,
 		DocumentType: "RAPBD",
 		Items:        items,
 		WebhookURL:   "http://localhost:7777/api/v1/swarm/callback",
+		Model:        task.Model,
+	}
+	if task.ParentTaskID != nil {
+		payload.ParentTaskID = *task.ParentTaskID
 	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	// 3. LPUSH to Redis
-	if redisCache, ok := u.redis.(*cache.RedisCache); ok {
-		err = redisCache.GetClient().LPush(ctx, "swarm:tasks", payloadBytes).Err()
-		if err != nil {
-			return nil, fmt.Errorf("failed to publish to redis: %w", err)
-		}
-	} else {
-		return nil, fmt.Errorf("cache is not redis")
+	redisCache, ok := u.redis.(*cache.RedisCache)
+	if !ok {
+		return fmt.Errorf("cache is not redis")
 	}
-
-	return task, nil
+	if err := redisCache.GetClient().LPush(ctx, swarmQueueKey, payloadBytes).Err(); err != nil {
+		return fmt.Errorf("failed to publish to redis: %w", err)
+	}
+	return nil
 }
 
 func (u *SwarmUsecase) HandleCallback(ctx context.Context, callback domain.SwarmCallback) error {
@@ -117,22 +172,84 @@ func (u *SwarmUsecase) HandleCallback(ctx context.Context, callback domain.Swarm
 	if err != nil {
 		return fmt.Errorf("task not found: %w", err)
 	}
+	if task.Status == domain.SwarmStatusCancelled {
+		log.Printf("[Swarm] Ignoring callback for cancelled task %s", task.ID)
+		return ErrTaskCancelled
+	}
+
+	prevRationale, prevConsensus, prevStat := task.RationaleHash, task.ConsensusHash, task.BlockchainStat
+
+	// Never trust the reported hashes: recompute them from the payload we are
+	// about to store and refuse the callback if they disagree.
+	if callback.Hashes.RationaleHash != "" || callback.Hashes.ConsensusHash != "" {
+		if callback.Hashes.Version != blockchain.SwarmHashVersion {
+			return fmt.Errorf("%w: hashes.version is %d, expected %d", ErrUnsupportedHashVersion, callback.Hashes.Version, blockchain.SwarmHashVersion)
+		}
+		rationaleHash, consensusHash, err := blockchain.ComputeSwarmHashes(task.ID, callback.Summary, callback.Results, callback.Transcript)
+		if err != nil {
+			return fmt.Errorf("failed to compute swarm hashes: %w", err)
+		}
+		if !blockchain.HashesEqual(callback.Hashes.RationaleHash, rationaleHash) {
+			log.Printf("[Swarm] Rationale hash mismatch for task %s: reported %s, computed %s", task.ID, callback.Hashes.RationaleHash, rationaleHash)
+			return fmt.Errorf("%w: rationale hash does not match transcript", ErrHashMismatch)
+		}
+		if !blockchain.HashesEqual(callback.Hashes.ConsensusHash, consensusHash) {
+			log.Printf("[Swarm] Consensus hash mismatch for task %s: reported %s, computed %s", task.ID, callback.Hashes.ConsensusHash, consensusHash)
+			return fmt.Errorf("%w: consensus hash does not match results", ErrHashMismatch)
+		}
+		task.RationaleHash = rationaleHash
+		task.ConsensusHash = consensusHash
+		task.HashVersion = blockchain.SwarmHashVersion
+	} else {
+		task.RationaleHash = ""
+		task.ConsensusHash = ""
+		task.HashVersion = 0
+	}
 
-	task.Status = callback.Status
+	task.Status = domain.NormalizeSwarmStatus(callback.Status)
 	task.Summary = callback.Summary
-	task.RationaleHash = callback.Hashes.RationaleHash
-	task.ConsensusHash = callback.Hashes.ConsensusHash
 	task.BlockchainNet = callback.Blockchain.Network
 	task.BlockchainStat = callback.Blockchain.Status
+	anchor := u.ledgers != nil && task.RationaleHash != "" && task.ConsensusHash != ""
+	if anchor {
+		if err := u.assignLedger(ctx, task); err != nil {
+			log.Printf("[Swarm] No ledger for task %s: %v", task.ID, err)
+			task.BlockchainStat = domain.BlockchainStatusFailed
+			anchor = false
+		}
+	}
+	batched := anchor && u.batchCfg.Enabled
+	if batched {
+		if task.BatchID != nil && task.RationaleHash == prevRationale && task.ConsensusHash == prevConsensus {
+			// Already a leaf of a batch with these hashes; keep its status.
+			task.BlockchainStat = prevStat
+		} else {
+			// New or changed hashes: (re)join the next batch.
+			task.BatchID = nil
+			task.LeafIndex = nil
+			task.MerkleProof = nil
+			task.BlockchainStat = domain.BlockchainStatusPendingBatch
+		}
+	}
 
 	resultsBytes, _ := json.Marshal(callback.Results)
 	task.Results = datatypes.JSON(resultsBytes)
+	if callback.Transcript != nil {
+		transcriptBytes, _ := json.Marshal(callback.Transcript)
+		task.Transcript = datatypes.JSON(transcriptBytes)
+	}
 	task.UpdatedAt = time.Now()
 
 	if err := u.swarmRepo.Update(ctx, task); err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
 
+	if len(callback.Results) > 0 {
+		if err := u.syncFindings(ctx, task, callback.Results); err != nil {
+			log.Printf("[Swarm] Failed to normalize findings for task %s: %v", task.ID, err)
+		}
+	}
+
 	// Publish to Redis PubSub for SSE streaming
 	if redisCache, ok := u.redis.(*cache.RedisCache); ok {
 		ssePayload := map[string]interface{}{
@@ -147,18 +264,22 @@ func (u *SwarmUsecase) HandleCallback(ctx context.Context, callback domain.Swarm
 			"timestamp": time.Now().UnixNano() / int64(time.Millisecond),
 		}
 		sseBytes, _ := json.Marshal(ssePayload)
-		redisCache.GetClient().Publish(ctx, "swarm:events", sseBytes)
+		redisCache.GetClient().Publish(ctx, swarmEventsKey, sseBytes)
 	}
 
 	// Step 5 — Push hash to blockchain asynchronously via Asynq queue
-	if u.blockchainService != nil && task.RationaleHash != "" && task.ConsensusHash != "" {
+	if batched {
+		if task.BlockchainStat == domain.BlockchainStatusPendingBatch {
+			u.maybeSealBatch(ctx)
+		}
+	} else if anchor {
 		asynqTask, err := NewCommitSwarmToBlockchainTask(task.ID, task.RationaleHash, task.ConsensusHash)
 		if err != nil {
 			log.Printf("[Swarm] Failed to create blockchain commit task for task %s: %v", task.ID, err)
 		} else {
 			if _, err := u.mqClient.EnqueueTask(asynqTask); err != nil {
 				log.Printf("[Swarm] Failed to enqueue blockchain commit task for task %s: %v. Falling back to local state.", task.ID, err)
-				u.updateBlockchainStatus(ctx, task.ID, "", "PENDING_COMMIT")
+				u.updateBlockchainStatus(ctx, task.ID, "", domain.BlockchainStatusPendingCommit)
 			} else {
 				log.Printf("[Swarm] Successfully enqueued blockchain commit task for task %s", task.ID)
 			}
@@ -168,6 +289,50 @@ func (u *SwarmUsecase) HandleCallback(ctx context.Context, callback domain.Swarm
 	return nil
 }
 
+// assignLedger pins a task to its tenant's ledger profile the first time it
+// is anchored. Later commits, corrections and verification use the pinned
+// ledger, even after the tenant switches profiles.
+func (u *SwarmUsecase) assignLedger(ctx context.Context, task *domain.SwarmTask) error {
+	profile := task.LedgerProfile
+	if task.LedgerContract == "" && task.BlockchainTx == "" {
+		tenantProfile, err := u.swarmRepo.GetTenantLedgerProfile(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		profile = tenantProfile
+	}
+	ledger, err := u.ledgers.Anchored(profile, task.LedgerContract)
+	if err != nil {
+		return err
+	}
+	task.LedgerProfile = blockchain.NormalizeLedgerProfile(profile)
+	task.LedgerContract = ledger.ContractAddress()
+	task.BlockchainNet = ledger.Network()
+	return nil
+}
+
+// maybeSealBatch seals a batch ahead of the batcher's schedule once enough
+// tasks are pending. Otherwise the task waits for the next scheduled seal.
+func (u *SwarmUsecase) maybeSealBatch(ctx context.Context) {
+	pending, err := u.batchRepo.CountPending(ctx)
+	if err != nil {
+		log.Printf("[Swarm] Failed to count pending batch tasks: %v", err)
+		return
+	}
+	if pending < int64(u.batchCfg.MaxSize) {
+		return
+	}
+
+	sealTask, err := NewSealAnchorBatchTask(u.batchCfg.MaxSize)
+	if err != nil {
+		log.Printf("[Swarm] Failed to create batch seal task: %v", err)
+		return
+	}
+	if _, err := u.mqClient.EnqueueTask(sealTask); err != nil {
+		log.Printf("[Swarm] Failed to enqueue batch seal task: %v", err)
+	}
+}
+
 func (u *SwarmUsecase) updateBlockchainStatus(ctx context.Context, taskID, txHash, status string) {
 	task, err := u.swarmRepo.GetByID(ctx, taskID)
 	if err != nil {
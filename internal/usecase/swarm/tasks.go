@@ -7,19 +7,24 @@ import (
 	"log"
 	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mq"
 	"github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
 	"github.com/hibiken/asynq"
 )
 
 const (
-	TypeCommitSwarmToBlockchain = "swarm:commit_blockchain"
+	TypeCommitSwarmToBlockchain  = "swarm:commit_blockchain"
+	TypeCorrectSwarmOnBlockchain = "swarm:correct_blockchain"
 )
 
 type CommitBlockchainPayload struct {
 	TaskID        string `json:"task_id"`
 	RationaleHash string `json:"rationale_hash"`
 	ConsensusHash string `json:"consensus_hash"`
+	// CorrectionID links a reviewer override to its ledger_corrections row.
+	CorrectionID string `json:"correction_id,omitempty"`
 }
 
 func NewCommitSwarmToBlockchainTask(taskID, rationaleHash, consensusHash string) (*asynq.Task, error) {
@@ -39,15 +44,41 @@ func NewCommitSwarmToBlockchainTask(taskID, rationaleHash, consensusHash string)
 	), nil
 }
 
+// NewCorrectSwarmOnBlockchainTask anchors a reviewer override by superseding
+// the task's active log with the review hashes.
+func NewCorrectSwarmOnBlockchainTask(taskID, rationaleHash, consensusHash, correctionID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(CommitBlockchainPayload{
+		TaskID:        taskID,
+		RationaleHash: rationaleHash,
+		ConsensusHash: consensusHash,
+		CorrectionID:  correctionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeCorrectSwarmOnBlockchain,
+		payload,
+		asynq.MaxRetry(10),
+		asynq.Queue("default"),
+	), nil
+}
+
 type SwarmTaskHandler struct {
-	swarmRepo         *postgres.SwarmRepository
-	blockchainService *blockchain.AuditTrailService
+	swarmRepo      *postgres.SwarmRepository
+	batchRepo      *postgres.AnchorBatchRepository
+	correctionRepo domain.LedgerCorrectionRepository
+	ledgers        *blockchain.Ledgers
+	mqClient       mq.TaskQueue
 }
 
-func NewSwarmTaskHandler(swarmRepo *postgres.SwarmRepository, bcService *blockchain.AuditTrailService) *SwarmTaskHandler {
+func NewSwarmTaskHandler(swarmRepo *postgres.SwarmRepository, batchRepo *postgres.AnchorBatchRepository, correctionRepo domain.LedgerCorrectionRepository, ledgers *blockchain.Ledgers, mqClient mq.TaskQueue) *SwarmTaskHandler {
 	return &SwarmTaskHandler{
-		swarmRepo:         swarmRepo,
-		blockchainService: bcService,
+		swarmRepo:      swarmRepo,
+		batchRepo:      batchRepo,
+		correctionRepo: correctionRepo,
+		ledgers:        ledgers,
+		mqClient:       mqClient,
 	}
 }
 
@@ -57,44 +88,174 @@ func (h *SwarmTaskHandler) HandleCommitSwarmToBlockchain(ctx context.Context, t
 		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
 	}
 
-	if h.blockchainService == nil {
+	if h.ledgers == nil {
 		log.Printf("[Swarm-Worker] Blockchain service not initialized. Skipping commit for task %s", payload.TaskID)
 		return nil
 	}
+	ledger, err := h.taskLedger(ctx, payload.TaskID)
+	if err != nil {
+		h.updateBlockchainStatus(ctx, payload.TaskID, "", domain.BlockchainStatusFailed)
+		return fmt.Errorf("no ledger for task %s: %v: %w", payload.TaskID, err, asynq.SkipRetry)
+	}
 
-	log.Printf("[Swarm-Worker] ▶ Committing hashes to Sepolia for Swarm Task %s (Rationale: %s, Consensus: %s)",
-		payload.TaskID, payload.RationaleHash, payload.ConsensusHash)
+	log.Printf("[Swarm-Worker] ▶ Committing hashes to %s for Swarm Task %s (Rationale: %s, Consensus: %s)",
+		ledger.Network(), payload.TaskID, payload.RationaleHash, payload.ConsensusHash)
 
 	// Emit block transaction
-	txHash, err := h.blockchainService.InsertLog(ctx, payload.TaskID, payload.RationaleHash, payload.ConsensusHash)
+	txHash, err := ledger.InsertLog(ctx, payload.TaskID, payload.RationaleHash, payload.ConsensusHash)
 	if err != nil {
 		log.Printf("[Swarm-Worker] ❌ insertLog failed for task %s: %v", payload.TaskID, err)
-		h.updateBlockchainStatus(ctx, payload.TaskID, "", "FAILED")
+		h.updateBlockchainStatus(ctx, payload.TaskID, "", domain.BlockchainStatusFailed)
 		return fmt.Errorf("insertLog failed: %w", err)
 	}
 
 	log.Printf("[Swarm-Worker] 📨 insertLog submitted tx %s for task %s. Waiting for confirmation...", txHash, payload.TaskID)
-	h.updateBlockchainStatus(ctx, payload.TaskID, txHash, "PENDING_CONFIRMATION")
+	h.updateBlockchainStatus(ctx, payload.TaskID, txHash, domain.BlockchainStatusPendingConfirmation)
 
 	// Wait for blockchain confirmation
-	receipt, err := h.blockchainService.WaitForConfirmation(ctx, txHash, 90*time.Second)
+	receipt, err := ledger.WaitForConfirmation(ctx, txHash, 90*time.Second)
 	if err != nil {
-		log.Printf("[Swarm-Worker] ⚠️ Wait for confirmation timed out for task %s, tx: %s", payload.TaskID, txHash)
-		return fmt.Errorf("wait for confirmation timed out: %w", err)
+		// Retrying would submit a second insertLog; the chain indexer settles
+		// the status once the transaction is mined.
+		log.Printf("[Swarm-Worker] ⚠️ Wait for confirmation timed out for task %s, tx: %s. Left to the reconciler", payload.TaskID, txHash)
+		return nil
 	}
 
+	// A fee bump may have replaced the transaction; record the one that was mined.
+	txHash = receipt.TxHash.Hex()
 	if receipt.Status == 1 {
 		log.Printf("[Swarm-Worker] ✅ Tx confirmed for task %s in block %d", payload.TaskID, receipt.BlockNumber)
-		h.updateBlockchainStatus(ctx, payload.TaskID, txHash, "VERIFIED")
+		h.updateBlockchainStatus(ctx, payload.TaskID, txHash, domain.BlockchainStatusVerified)
 	} else {
 		log.Printf("[Swarm-Worker] ❌ Tx execution failed on-chain for task %s", payload.TaskID)
-		h.updateBlockchainStatus(ctx, payload.TaskID, txHash, "FAILED")
+		h.updateBlockchainStatus(ctx, payload.TaskID, txHash, domain.BlockchainStatusFailed)
 		return fmt.Errorf("transaction execution failed on chain")
 	}
 
 	return nil
 }
 
+// HandleCorrectSwarmOnBlockchain calls correctLog for a reviewer override.
+// correctLog requires the original log to exist, so the task is retried
+// until the machine commit has been confirmed.
+func (h *SwarmTaskHandler) HandleCorrectSwarmOnBlockchain(ctx context.Context, t *asynq.Task) error {
+	var payload CommitBlockchainPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if h.ledgers == nil {
+		log.Printf("[Swarm-Worker] Blockchain service not initialized. Skipping correction for task %s", payload.TaskID)
+		return nil
+	}
+
+	task, err := h.swarmRepo.GetByID(ctx, payload.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to load task %s: %w", payload.TaskID, err)
+	}
+	ledger, err := h.ledgers.Anchored(task.LedgerProfile, task.LedgerContract)
+	if err != nil {
+		h.updateReviewStatus(ctx, payload.TaskID, "", domain.BlockchainStatusFailed)
+		h.updateCorrection(ctx, ledger, payload.CorrectionID, "", domain.BlockchainStatusFailed)
+		return fmt.Errorf("no ledger for task %s: %v: %w", payload.TaskID, err, asynq.SkipRetry)
+	}
+	if task.ReviewRationaleHash != payload.RationaleHash || task.ReviewConsensusHash != payload.ConsensusHash {
+		log.Printf("[Swarm-Worker] Override for task %s changed since it was enqueued, skipping stale correction", payload.TaskID)
+		return nil
+	}
+	switch task.BlockchainStat {
+	case domain.BlockchainStatusVerified:
+	case domain.BlockchainStatusFailed:
+		h.updateReviewStatus(ctx, payload.TaskID, "", domain.BlockchainStatusFailed)
+		h.updateCorrection(ctx, ledger, payload.CorrectionID, "", domain.BlockchainStatusFailed)
+		return fmt.Errorf("original commit failed, nothing to correct: %w", asynq.SkipRetry)
+	default:
+		return fmt.Errorf("original commit for task %s is %s, retrying later", payload.TaskID, task.BlockchainStat)
+	}
+
+	log.Printf("[Swarm-Worker] ▶ Correcting on-chain log for Swarm Task %s (Rationale: %s, Consensus: %s)",
+		payload.TaskID, payload.RationaleHash, payload.ConsensusHash)
+
+	// A batched task has no log of its own to supersede; its override is
+	// anchored as the task's first log instead.
+	var txHash string
+	if task.BatchID != nil {
+		txHash, err = ledger.InsertLog(ctx, payload.TaskID, payload.RationaleHash, payload.ConsensusHash)
+	} else {
+		txHash, err = ledger.CorrectLog(ctx, payload.TaskID, payload.RationaleHash, payload.ConsensusHash)
+	}
+	if err != nil {
+		log.Printf("[Swarm-Worker] ❌ correctLog failed for task %s: %v", payload.TaskID, err)
+		if !finalAttempt(ctx) {
+			return fmt.Errorf("correctLog failed: %w", err)
+		}
+		h.updateReviewStatus(ctx, payload.TaskID, "", domain.BlockchainStatusFailed)
+		h.updateCorrection(ctx, ledger, payload.CorrectionID, "", domain.BlockchainStatusFailed)
+		return fmt.Errorf("correctLog failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	h.updateReviewStatus(ctx, payload.TaskID, txHash, domain.BlockchainStatusPendingConfirmation)
+	h.updateCorrection(ctx, ledger, payload.CorrectionID, txHash, domain.BlockchainStatusPendingConfirmation)
+
+	receipt, err := ledger.WaitForConfirmation(ctx, txHash, 90*time.Second)
+	if err != nil {
+		log.Printf("[Swarm-Worker] ⚠️ Wait for correction confirmation timed out for task %s, tx: %s. Left to the reconciler", payload.TaskID, txHash)
+		return nil
+	}
+
+	txHash = receipt.TxHash.Hex()
+	if receipt.Status == 1 {
+		log.Printf("[Swarm-Worker] ✅ Correction confirmed for task %s in block %d", payload.TaskID, receipt.BlockNumber)
+		h.updateReviewStatus(ctx, payload.TaskID, txHash, domain.BlockchainStatusVerified)
+		h.updateCorrection(ctx, ledger, payload.CorrectionID, txHash, domain.BlockchainStatusVerified)
+	} else {
+		log.Printf("[Swarm-Worker] ❌ Correction tx execution failed on-chain for task %s", payload.TaskID)
+		h.updateReviewStatus(ctx, payload.TaskID, txHash, domain.BlockchainStatusFailed)
+		h.updateCorrection(ctx, ledger, payload.CorrectionID, txHash, domain.BlockchainStatusFailed)
+		return fmt.Errorf("transaction execution failed on chain: %w", asynq.SkipRetry)
+	}
+
+	return nil
+}
+
+// finalAttempt reports whether asynq will not retry the task running in ctx
+// if it fails now. It is false outside an asynq worker.
+func finalAttempt(ctx context.Context) bool {
+	retry, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return false
+	}
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	return ok && retry >= maxRetry
+}
+
+// taskLedger returns the ledger a task is pinned to (see assignLedger).
+func (h *SwarmTaskHandler) taskLedger(ctx context.Context, taskID string) (blockchain.Ledger, error) {
+	task, err := h.swarmRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task %s: %w", taskID, err)
+	}
+	return h.ledgers.Anchored(task.LedgerProfile, task.LedgerContract)
+}
+
+func (h *SwarmTaskHandler) updateReviewStatus(ctx context.Context, taskID, txHash, status string) {
+	task, err := h.swarmRepo.GetByID(ctx, taskID)
+	if err != nil {
+		log.Printf("[Swarm-Worker] Failed to find task %s: %v", taskID, err)
+		return
+	}
+
+	if txHash != "" {
+		task.ReviewTx = txHash
+	}
+	task.ReviewChainStat = status
+	task.UpdatedAt = time.Now()
+
+	if err := h.swarmRepo.Update(ctx, task); err != nil {
+		log.Printf("[Swarm-Worker] Failed to update review status in PostgreSQL: %v", err)
+	}
+}
+
 func (h *SwarmTaskHandler) updateBlockchainStatus(ctx context.Context, taskID, txHash, status string) {
 	task, err := h.swarmRepo.GetByID(ctx, taskID)
 	if err != nil {
@@ -0,0 +1,135 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/blockchain"
+	"github.com/hibiken/asynq"
+)
+
+func TestHandleCorrectSwarmOnBlockchain(t *testing.T) {
+	batchID := "55555555-5555-5555-5555-555555555555"
+	cases := []struct {
+		name          string
+		task          domain.SwarmTask
+		ledger        fakeLedger
+		stale         bool
+		wantErr       bool
+		wantSkipRetry bool
+		// Review statuses written, in order, and the final correction status.
+		wantReview     []string
+		wantCorrection string
+		wantCall       string
+	}{
+		{
+			name:           "machine commit still pending",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusPendingConfirmation},
+			wantErr:        true,
+			wantCorrection: domain.BlockchainStatusPendingCommit,
+		},
+		{
+			name:           "machine commit failed",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusFailed},
+			wantErr:        true,
+			wantSkipRetry:  true,
+			wantReview:     []string{domain.BlockchainStatusFailed},
+			wantCorrection: domain.BlockchainStatusFailed,
+		},
+		{
+			name:           "override changed since enqueued",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusVerified},
+			stale:          true,
+			wantCorrection: domain.BlockchainStatusPendingCommit,
+		},
+		{
+			name:           "correction confirmed",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusVerified},
+			ledger:         fakeLedger{receiptStatus: 1},
+			wantReview:     []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusVerified},
+			wantCorrection: domain.BlockchainStatusVerified,
+			wantCall:       "correct",
+		},
+		{
+			name:           "batched task gets its own log",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusVerified, BatchID: &batchID},
+			ledger:         fakeLedger{receiptStatus: 1},
+			wantReview:     []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusVerified},
+			wantCorrection: domain.BlockchainStatusVerified,
+			wantCall:       "insert",
+		},
+		{
+			name:           "correctLog fails and is retried",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusVerified},
+			ledger:         fakeLedger{correctErr: errors.New("rpc down")},
+			wantErr:        true,
+			wantCorrection: domain.BlockchainStatusPendingCommit,
+		},
+		{
+			name:           "correction reverted",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusVerified},
+			ledger:         fakeLedger{receiptStatus: 0},
+			wantErr:        true,
+			wantSkipRetry:  true,
+			wantReview:     []string{domain.BlockchainStatusPendingConfirmation, domain.BlockchainStatusFailed},
+			wantCorrection: domain.BlockchainStatusFailed,
+			wantCall:       "correct",
+		},
+		{
+			name:           "confirmation left to the reconciler",
+			task:           domain.SwarmTask{BlockchainStat: domain.BlockchainStatusVerified},
+			ledger:         fakeLedger{waitErr: errors.New("timeout")},
+			wantReview:     []string{domain.BlockchainStatusPendingConfirmation},
+			wantCorrection: domain.BlockchainStatusPendingConfirmation,
+			wantCall:       "correct",
+		},
+	}
+	for _, c := range cases {
+		db := newTaskDB(t)
+		task := c.task
+		task.ID, task.DocumentID = testTaskID, testDocID
+		task.ReviewRationaleHash, task.ReviewConsensusHash = "r1", "c1"
+		task.ReviewChainStat = domain.BlockchainStatusPendingCommit
+		db.expectLoad(task)
+		for range c.wantReview {
+			db.expectLoad(task)
+			db.expectSave()
+		}
+		corrections := newFakeCorrections(&domain.LedgerCorrection{ID: "correction-1", TaskID: testTaskID, Status: domain.BlockchainStatusPendingCommit})
+		ledger := c.ledger
+		h := NewSwarmTaskHandler(db.swarmRepo(), nil, corrections, blockchain.SingleLedger(&ledger), &fakeQueue{})
+
+		rationale := "r1"
+		if c.stale {
+			rationale = "r0"
+		}
+		asynqTask, err := NewCorrectSwarmOnBlockchainTask(testTaskID, rationale, "c1", "correction-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.HandleCorrectSwarmOnBlockchain(context.Background(), asynqTask)
+		if (err != nil) != c.wantErr || errors.Is(err, asynq.SkipRetry) != c.wantSkipRetry {
+			t.Errorf("%s: err = %v, want error %v, skip retry %v", c.name, err, c.wantErr, c.wantSkipRetry)
+		}
+		if got := db.saved("review_chain_stat"); !reflect.DeepEqual(got, c.wantReview) {
+			t.Errorf("%s: review statuses %v, want %v", c.name, got, c.wantReview)
+		}
+		if got := corrections.byID["correction-1"].Status; got != c.wantCorrection {
+			t.Errorf("%s: correction status %s, want %s", c.name, got, c.wantCorrection)
+		}
+		var call string
+		switch {
+		case len(ledger.corrected) > 0:
+			call = "correct"
+		case len(ledger.inserted) > 0:
+			call = "insert"
+		}
+		if call != c.wantCall {
+			t.Errorf("%s: ledger call %q, want %q", c.name, call, c.wantCall)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/dto"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/ai"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine/handlers"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/engine/interceptors"
@@ -29,18 +30,22 @@ type WorkflowUseCase interface {
 }
 
 type workflowUseCase struct {
-	repo         repository.WorkflowRepository
-	docRepo      domain.DocumentRepository
-	auditRepo    domain.AuditRepository
-	geminiAPIKey string
+	repo      repository.WorkflowRepository
+	docRepo   domain.DocumentRepository
+	auditRepo domain.AuditRepository
+	embedders ai.TenantEmbedders
+	reranker  ai.Reranker      // nil: rag_retriever nodes cannot rerank
+	expander  ai.QueryExpander // nil: rag_retriever nodes cannot expand queries
 }
 
-func NewWorkflowUseCase(repo repository.WorkflowRepository, docRepo domain.DocumentRepository, auditRepo domain.AuditRepository, geminiAPIKey string) *workflowUseCase {
+func NewWorkflowUseCase(repo repository.WorkflowRepository, docRepo domain.DocumentRepository, auditRepo domain.AuditRepository, embedders ai.TenantEmbedders, reranker ai.Reranker, expander ai.QueryExpander) *workflowUseCase {
 	return &workflowUseCase{
-		repo:         repo,
-		docRepo:      docRepo,
-		auditRepo:    auditRepo,
-		geminiAPIKey: geminiAPIKey,
+		repo:      repo,
+		docRepo:   docRepo,
+		auditRepo: auditRepo,
+		embedders: embedders,
+		reranker:  reranker,
+		expander:  expander,
 	}
 }
 
@@ -170,11 +175,7 @@ func (uc *workflowUseCase) ExecutePipeline(ctx context.Context, tenantID uuid.UU
 	workflowEngine := engine.NewWorkflowEngine()
 	workflowEngine.Register("llm_agent", handlers.NewLLMAgentHandler())
 
-	ragHandler, err := handlers.NewRAGRetrieverHandler(uc.docRepo, uc.geminiAPIKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to init RAG retriever: %w", err)
-	}
-	workflowEngine.Register("rag_retriever", ragHandler)
+	workflowEngine.Register("rag_retriever", handlers.NewRAGRetrieverHandler(uc.docRepo, uc.embedders, uc.reranker, uc.expander))
 
 	// Mount Telemetry and Forensic Audit Interceptors
 	workflowEngine.Use(interceptors.NewTelemetryInterceptor())
@@ -43,7 +43,7 @@ func (m *MockWorkflowRepo) Update(ctx context.Context, workflow *domain.Workflow
 
 func TestWorkflowUseCase_UpdateGraph_Success(t *testing.T) {
 	mockRepo := &MockWorkflowRepo{}
-	usecase := workflow.NewWorkflowUseCase(mockRepo, nil, nil, "")
+	usecase := workflow.NewWorkflowUseCase(mockRepo, nil, nil, nil, nil, nil)
 
 	req := dto.SaveWorkflowGraphRequest{
 		Nodes: []dto.ReactFlowNodeDTO{
@@ -67,7 +67,7 @@ func TestWorkflowUseCase_UpdateGraph_Success(t *testing.T) {
 
 func TestWorkflowUseCase_PublishWorkflow_Success(t *testing.T) {
 	mockRepo := &MockWorkflowRepo{}
-	usecase := workflow.NewWorkflowUseCase(mockRepo, nil, nil, "")
+	usecase := workflow.NewWorkflowUseCase(mockRepo, nil, nil, nil, nil, nil)
 
 	req := dto.SaveWorkflowGraphRequest{
 		Nodes: []dto.ReactFlowNodeDTO{
@@ -104,7 +104,7 @@ func TestWorkflowUseCase_PublishWorkflow_Success(t *testing.T) {
 
 func TestWorkflowUseCase_PublishWorkflow_CycleError(t *testing.T) {
 	mockRepo := &MockWorkflowRepo{}
-	usecase := workflow.NewWorkflowUseCase(mockRepo, nil, nil, "")
+	usecase := workflow.NewWorkflowUseCase(mockRepo, nil, nil, nil, nil, nil)
 
 	req := dto.SaveWorkflowGraphRequest{
 		Nodes: []dto.ReactFlowNodeDTO{
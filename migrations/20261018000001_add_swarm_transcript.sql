@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE swarm_tasks
+    ADD COLUMN IF NOT EXISTS transcript JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE swarm_tasks
+    DROP COLUMN IF EXISTS transcript;
+-- +goose StatementEnd
@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS swarm_findings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES swarm_tasks(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,
+    item_index INT NOT NULL DEFAULT 0,
+    item_code VARCHAR(100),
+    item_name TEXT,
+    supplier VARCHAR(255),
+    unit VARCHAR(50),
+    quantity NUMERIC(20,4),
+    unit_price NUMERIC(20,2),
+    reference_price NUMERIC(20,2),
+    deviation_pct NUMERIC(10,2),
+    verdict VARCHAR(50),
+    rationale TEXT,
+    agent_votes JSONB,
+    raw JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_swarm_findings_tenant_created ON swarm_findings (tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_swarm_findings_tenant_verdict ON swarm_findings (tenant_id, verdict);
+CREATE INDEX IF NOT EXISTS idx_swarm_findings_tenant_supplier ON swarm_findings (tenant_id, supplier);
+CREATE INDEX IF NOT EXISTS idx_swarm_findings_tenant_deviation ON swarm_findings (tenant_id, deviation_pct);
+CREATE INDEX IF NOT EXISTS idx_swarm_findings_task ON swarm_findings (task_id);
+-- +goose StatementEnd
+
+-- Backfill from existing task results. Only the canonical key names are read
+-- here; new callbacks go through the tolerant mapping in the swarm usecase.
+-- +goose StatementBegin
+INSERT INTO swarm_findings (tenant_id, task_id, document_id, item_index, item_code, item_name, supplier, unit,
+                            quantity, unit_price, reference_price, deviation_pct, verdict, rationale, agent_votes, raw, created_at)
+SELECT d.tenant_id,
+       t.id,
+       t.document_id,
+       (e.ord - 1)::INT,
+       e.item->>'item_code',
+       COALESCE(e.item->>'item', e.item->>'item_name', e.item->>'name'),
+       e.item->>'supplier',
+       e.item->>'unit',
+       CASE WHEN jsonb_typeof(e.item->'quantity') = 'number' THEN (e.item->>'quantity')::NUMERIC END,
+       CASE WHEN jsonb_typeof(e.item->'unit_price') = 'number' THEN (e.item->>'unit_price')::NUMERIC END,
+       CASE WHEN jsonb_typeof(e.item->'reference_price') = 'number' THEN (e.item->>'reference_price')::NUMERIC END,
+       CASE WHEN jsonb_typeof(e.item->'deviation_pct') = 'number' THEN (e.item->>'deviation_pct')::NUMERIC END,
+       UPPER(e.item->>'verdict'),
+       e.item->>'rationale',
+       e.item->'agent_votes',
+       e.item,
+       t.updated_at
+FROM swarm_tasks t
+JOIN documents d ON d.id = t.document_id
+CROSS JOIN LATERAL jsonb_array_elements(t.results) WITH ORDINALITY AS e(item, ord)
+WHERE jsonb_typeof(t.results) = 'array'
+  AND jsonb_typeof(e.item) = 'object'
+  AND NOT EXISTS (SELECT 1 FROM swarm_findings f WHERE f.task_id = t.id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS swarm_findings;
+-- +goose StatementEnd
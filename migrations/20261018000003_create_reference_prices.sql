@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reference_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    item_code VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL,
+    unit VARCHAR(50),
+    region VARCHAR(150) NOT NULL DEFAULT '',
+    max_price NUMERIC(20,2) NOT NULL,
+    effective_date DATE NOT NULL,
+    fiscal_year INT NOT NULL,
+    source_regulation TEXT,
+    search_tsv TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', coalesce(item_code, '') || ' ' || coalesce(description, ''))
+    ) STORED,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_reference_prices_scope UNIQUE (tenant_id, fiscal_year, region, item_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reference_prices_tenant_year ON reference_prices (tenant_id, fiscal_year DESC);
+CREATE INDEX IF NOT EXISTS idx_reference_prices_search ON reference_prices USING GIN (search_tsv);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reference_prices;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE swarm_tasks
+    ADD COLUMN IF NOT EXISTS items JSONB,
+    ADD COLUMN IF NOT EXISTS model VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS parent_task_id UUID REFERENCES swarm_tasks(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS cancelled_by UUID,
+    ADD COLUMN IF NOT EXISTS review_verdict VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS review_justification TEXT,
+    ADD COLUMN IF NOT EXISTS reviewed_by UUID,
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS review_rationale_hash VARCHAR(128),
+    ADD COLUMN IF NOT EXISTS review_consensus_hash VARCHAR(128),
+    ADD COLUMN IF NOT EXISTS review_tx VARCHAR(128),
+    ADD COLUMN IF NOT EXISTS review_chain_stat VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_swarm_tasks_parent ON swarm_tasks (parent_task_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_swarm_tasks_parent;
+ALTER TABLE swarm_tasks
+    DROP COLUMN IF EXISTS items,
+    DROP COLUMN IF EXISTS model,
+    DROP COLUMN IF EXISTS parent_task_id,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancelled_by,
+    DROP COLUMN IF EXISTS review_verdict,
+    DROP COLUMN IF EXISTS review_justification,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS review_rationale_hash,
+    DROP COLUMN IF EXISTS review_consensus_hash,
+    DROP COLUMN IF EXISTS review_tx,
+    DROP COLUMN IF EXISTS review_chain_stat;
+-- +goose StatementEnd
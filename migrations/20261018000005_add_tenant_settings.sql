@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tenants
+    ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tenants
+    DROP COLUMN IF EXISTS settings;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS anchor_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merkle_root VARCHAR(128) NOT NULL,
+    commitment VARCHAR(128) NOT NULL,
+    leaf_count INTEGER NOT NULL,
+    tx_hash VARCHAR(128),
+    network VARCHAR(50),
+    status VARCHAR(50) NOT NULL DEFAULT 'PENDING_COMMIT',
+    anchored_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE swarm_tasks
+    ADD COLUMN IF NOT EXISTS batch_id UUID REFERENCES anchor_batches(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS leaf_index INTEGER,
+    ADD COLUMN IF NOT EXISTS merkle_proof JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_swarm_tasks_batch ON swarm_tasks (batch_id);
+CREATE INDEX IF NOT EXISTS idx_swarm_tasks_pending_batch ON swarm_tasks (updated_at)
+    WHERE blockchain_stat = 'PENDING_BATCH';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_swarm_tasks_pending_batch;
+DROP INDEX IF EXISTS idx_swarm_tasks_batch;
+ALTER TABLE swarm_tasks
+    DROP COLUMN IF EXISTS batch_id,
+    DROP COLUMN IF EXISTS leaf_index,
+    DROP COLUMN IF EXISTS merkle_proof;
+DROP TABLE IF EXISTS anchor_batches;
+-- +goose StatementEnd
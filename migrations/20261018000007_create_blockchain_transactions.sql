@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS blockchain_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tx_hash VARCHAR(128) NOT NULL UNIQUE,
+    chain_id BIGINT NOT NULL,
+    from_address VARCHAR(64) NOT NULL,
+    to_address VARCHAR(64),
+    nonce BIGINT NOT NULL,
+    data BYTEA,
+    gas_limit BIGINT,
+    max_fee_per_gas VARCHAR(80),
+    max_priority_fee_per_gas VARCHAR(80),
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    replaced_by VARCHAR(128),
+    attempt INTEGER NOT NULL DEFAULT 1,
+    block_number BIGINT,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_blockchain_transactions_pending ON blockchain_transactions (submitted_at)
+    WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_blockchain_transactions_sender_nonce ON blockchain_transactions (from_address, nonce);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS blockchain_transactions;
+-- +goose StatementEnd
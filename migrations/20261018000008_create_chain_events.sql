@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS chain_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    network VARCHAR(50) NOT NULL,
+    contract VARCHAR(255) NOT NULL,
+    event_name VARCHAR(50) NOT NULL,
+    entry_index BIGINT NOT NULL,
+    old_index BIGINT,
+    task_id VARCHAR(255) NOT NULL,
+    rationale_hash VARCHAR(128),
+    consensus_hash VARCHAR(128),
+    tx_hash VARCHAR(128) NOT NULL,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(128) NOT NULL,
+    log_index INTEGER NOT NULL,
+    confirmations BIGINT NOT NULL DEFAULT 0,
+    final BOOLEAN NOT NULL DEFAULT FALSE,
+    removed BOOLEAN NOT NULL DEFAULT FALSE,
+    reconciliation VARCHAR(20),
+    mismatch_reason TEXT,
+    reconciled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_chain_events_log UNIQUE (network, contract, block_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chain_events_task ON chain_events (task_id);
+CREATE INDEX IF NOT EXISTS idx_chain_events_tx ON chain_events (tx_hash);
+CREATE INDEX IF NOT EXISTS idx_chain_events_open ON chain_events (network, contract, block_number)
+    WHERE removed = FALSE AND (final = FALSE OR reconciled_at IS NULL);
+
+CREATE TABLE IF NOT EXISTS chain_cursors (
+    network VARCHAR(50) NOT NULL,
+    contract VARCHAR(255) NOT NULL,
+    block BIGINT NOT NULL,
+    block_hash VARCHAR(128),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (network, contract)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chain_cursors;
+DROP TABLE IF EXISTS chain_events;
+-- +goose StatementEnd
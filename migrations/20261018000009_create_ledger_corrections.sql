@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS ledger_corrections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES swarm_tasks(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    justification TEXT NOT NULL,
+    requested_by UUID NOT NULL,
+    previous_rationale_hash VARCHAR(128),
+    previous_consensus_hash VARCHAR(128),
+    rationale_hash VARCHAR(128) NOT NULL,
+    consensus_hash VARCHAR(128) NOT NULL,
+    tx_hash VARCHAR(128),
+    network VARCHAR(50),
+    status VARCHAR(50) NOT NULL DEFAULT 'PENDING_COMMIT',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_corrections_task ON ledger_corrections (task_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ledger_corrections;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE swarm_tasks
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS published_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_swarm_tasks_published ON swarm_tasks (published_at) WHERE published_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_swarm_tasks_published;
+ALTER TABLE swarm_tasks
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS published_by;
+-- +goose StatementEnd
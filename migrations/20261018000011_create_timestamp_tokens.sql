@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS timestamp_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    log_id VARCHAR(128) NOT NULL,
+    rationale_hash VARCHAR(66) NOT NULL,
+    consensus_hash VARCHAR(66) NOT NULL,
+    message_imprint VARCHAR(66) NOT NULL,
+    token BYTEA NOT NULL,
+    token_hash VARCHAR(66) NOT NULL UNIQUE,
+    serial_number VARCHAR(80),
+    gen_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    tsa_url TEXT,
+    supersedes BIGINT REFERENCES timestamp_tokens (id),
+    superseded_by BIGINT REFERENCES timestamp_tokens (id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_timestamp_tokens_log_id ON timestamp_tokens (log_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS timestamp_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_anchors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    entry_count INTEGER NOT NULL,
+    prev_head VARCHAR(128) NOT NULL,
+    head_hash VARCHAR(128) NOT NULL,
+    commitment VARCHAR(128) NOT NULL,
+    tx_hash VARCHAR(128),
+    network VARCHAR(50),
+    status VARCHAR(50) NOT NULL DEFAULT 'PENDING_COMMIT',
+    anchored_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, period_start)
+);
+
+-- Periods are hash-chained per tenant in (created_at, id) order.
+CREATE INDEX IF NOT EXISTS idx_enterprise_audit_logs_tenant_created
+    ON enterprise_audit_logs (tenant_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_enterprise_audit_logs_tenant_created;
+DROP TABLE IF EXISTS audit_anchors;
+-- +goose StatementEnd
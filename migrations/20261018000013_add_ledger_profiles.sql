@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Records anchored before ledger profiles existed went to the default ledger.
+ALTER TABLE swarm_tasks
+    ADD COLUMN IF NOT EXISTS ledger_profile VARCHAR(64) NOT NULL DEFAULT 'default',
+    ADD COLUMN IF NOT EXISTS ledger_contract VARCHAR(255);
+
+ALTER TABLE anchor_batches
+    ADD COLUMN IF NOT EXISTS ledger_profile VARCHAR(64) NOT NULL DEFAULT 'default',
+    ADD COLUMN IF NOT EXISTS ledger_contract VARCHAR(255);
+
+ALTER TABLE audit_anchors
+    ADD COLUMN IF NOT EXISTS ledger_profile VARCHAR(64) NOT NULL DEFAULT 'default',
+    ADD COLUMN IF NOT EXISTS ledger_contract VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_anchors
+    DROP COLUMN IF EXISTS ledger_profile,
+    DROP COLUMN IF EXISTS ledger_contract;
+
+ALTER TABLE anchor_batches
+    DROP COLUMN IF EXISTS ledger_profile,
+    DROP COLUMN IF EXISTS ledger_contract;
+
+ALTER TABLE swarm_tasks
+    DROP COLUMN IF EXISTS ledger_profile,
+    DROP COLUMN IF EXISTS ledger_contract;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Chunks embedded before the model was recorded all came from Gemini
+-- text-embedding-004.
+ALTER TABLE document_chunks
+    ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS embedding_dim INTEGER;
+
+UPDATE document_chunks
+SET embedding_model = 'text-embedding-004',
+    embedding_dim = vector_dims(embedding)
+WHERE embedding_model IS NULL AND embedding IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_document_chunks_tenant_model
+    ON document_chunks (tenant_id, embedding_model);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_document_chunks_tenant_model;
+
+ALTER TABLE document_chunks
+    DROP COLUMN IF EXISTS embedding_model,
+    DROP COLUMN IF EXISTS embedding_dim;
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+-- +goose Up
+-- +goose StatementBegin
+-- document_chunks.embedding holds the vectors of the tenant's ACTIVE
+-- embedding index, whatever their dimension. HNSW needs a fixed dimension,
+-- so each dimension gets a partial expression index (the repository creates
+-- new ones on demand, see EnsureVectorIndex).
+DROP INDEX IF EXISTS document_chunks_embedding_idx;
+DROP INDEX IF EXISTS idx_chunks_hnsw_embedding;
+
+ALTER TABLE document_chunks ALTER COLUMN embedding TYPE vector;
+
+CREATE INDEX IF NOT EXISTS idx_chunks_hnsw_1536
+    ON document_chunks USING hnsw ((embedding::vector(1536)) vector_cosine_ops)
+    WITH (m = 16, ef_construction = 64)
+    WHERE embedding_dim = 1536;
+
+CREATE TABLE IF NOT EXISTS embedding_indexes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    model VARCHAR(100) NOT NULL,
+    dimension INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'BUILDING',
+    total_chunks BIGINT NOT NULL DEFAULT 0,
+    embedded_chunks BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    activated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_indexes_tenant ON embedding_indexes (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_embedding_indexes_one_active
+    ON embedding_indexes (tenant_id) WHERE status = 'ACTIVE';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_embedding_indexes_one_building
+    ON embedding_indexes (tenant_id) WHERE status = 'BUILDING';
+
+-- Vectors of the BUILDING and STANDBY indexes. Never searched, so no HNSW.
+CREATE TABLE IF NOT EXISTS chunk_embeddings (
+    index_id UUID NOT NULL REFERENCES embedding_indexes(id) ON DELETE CASCADE,
+    chunk_id UUID NOT NULL REFERENCES document_chunks(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    embedding vector NOT NULL,
+    PRIMARY KEY (index_id, chunk_id)
+);
+
+-- Every tenant with chunks gets an ACTIVE index for the model most of its
+-- chunks were embedded by.
+INSERT INTO embedding_indexes (tenant_id, model, dimension, status, total_chunks, embedded_chunks, activated_at)
+SELECT DISTINCT ON (tenant_id) tenant_id, embedding_model, embedding_dim, 'ACTIVE', total, chunks, NOW()
+FROM (
+    SELECT tenant_id, embedding_model, embedding_dim, COUNT(*) AS chunks,
+           SUM(COUNT(*)) OVER (PARTITION BY tenant_id) AS total
+    FROM document_chunks
+    WHERE embedding_model IS NOT NULL
+    GROUP BY tenant_id, embedding_model, embedding_dim
+) usage
+ORDER BY tenant_id, chunks DESC;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chunk_embeddings;
+DROP TABLE IF EXISTS embedding_indexes;
+
+DO $$
+DECLARE idx record;
+BEGIN
+    FOR idx IN SELECT indexname FROM pg_indexes
+               WHERE tablename = 'document_chunks' AND indexname LIKE 'idx_chunks_hnsw_%'
+    LOOP
+        EXECUTE format('DROP INDEX IF EXISTS %I', idx.indexname);
+    END LOOP;
+END $$;
+
+-- Vectors of other dimensions cannot go back into vector(1536).
+UPDATE document_chunks SET embedding = NULL WHERE vector_dims(embedding) <> 1536;
+ALTER TABLE document_chunks ALTER COLUMN embedding TYPE vector(1536);
+CREATE INDEX IF NOT EXISTS document_chunks_embedding_idx
+    ON document_chunks USING hnsw (embedding vector_cosine_ops);
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The corpus is mostly Indonesian regulations (POJK, PBI, Perda): existing
+-- chunks are re-indexed with the Indonesian configuration. Each chunk's
+-- content_tsv is built with the configuration of its language; the CASE
+-- keeps the generation expression immutable.
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS language VARCHAR(20);
+
+ALTER TABLE document_chunks
+    ADD COLUMN IF NOT EXISTS language VARCHAR(20) NOT NULL DEFAULT 'indonesian';
+
+DROP INDEX IF EXISTS idx_chunks_fts;
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS content_tsv;
+ALTER TABLE document_chunks
+    ADD COLUMN content_tsv tsvector
+        GENERATED ALWAYS AS (to_tsvector(
+            CASE language
+                WHEN 'indonesian' THEN 'indonesian'::regconfig
+                WHEN 'english' THEN 'english'::regconfig
+                ELSE 'simple'::regconfig
+            END, content)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_chunks_fts
+    ON document_chunks USING GIN (content_tsv);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chunks_fts;
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS content_tsv;
+ALTER TABLE document_chunks
+    ADD COLUMN content_tsv tsvector
+        GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+CREATE INDEX IF NOT EXISTS idx_chunks_fts
+    ON document_chunks USING GIN (content_tsv);
+
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS language;
+ALTER TABLE documents DROP COLUMN IF EXISTS language;
+-- +goose StatementEnd
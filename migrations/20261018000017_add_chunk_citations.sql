@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Source location of each chunk, for citations. page_number stays the first
+-- page; existing chunks keep page_end = 0, which marks their page_number as
+-- an estimate that citations leave out until the document is re-ingested.
+ALTER TABLE document_chunks
+    ADD COLUMN IF NOT EXISTS page_end INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS header_path TEXT,
+    ADD COLUMN IF NOT EXISTS char_start INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS char_end INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS regions JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE document_chunks
+    DROP COLUMN IF EXISTS regions,
+    DROP COLUMN IF EXISTS char_end,
+    DROP COLUMN IF EXISTS char_start,
+    DROP COLUMN IF EXISTS header_path,
+    DROP COLUMN IF EXISTS page_end;
+-- +goose StatementEnd
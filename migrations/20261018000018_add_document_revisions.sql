@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Editing the text of a ready document re-indexes it incrementally; every
+-- re-index that changes its chunks bumps the document's revision, and each
+-- chunk records the revision it was last indexed at.
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE document_chunks
+    ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_document_chunks_document_index
+    ON document_chunks (document_id, chunk_index);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_document_chunks_document_index;
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS revision;
+ALTER TABLE documents DROP COLUMN IF EXISTS revision;
+-- +goose StatementEnd
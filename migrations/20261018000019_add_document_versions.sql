@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Documents are grouped into logical records of successive versions. Every
+-- existing document becomes version 1 of its own record.
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS record_id UUID,
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS superseded_at TIMESTAMPTZ;
+
+UPDATE documents SET record_id = id WHERE record_id IS NULL;
+ALTER TABLE documents ALTER COLUMN record_id SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_documents_record_version ON documents (record_id, version);
+-- Searches skip superseded versions by default.
+CREATE INDEX IF NOT EXISTS idx_documents_latest ON documents (id) WHERE superseded_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_documents_latest;
+DROP INDEX IF EXISTS idx_documents_record_version;
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS superseded_at,
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS record_id;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Version of the canonical hashing spec a task's hashes were computed with.
+-- Rows hashed before the spec was versioned keep 0.
+ALTER TABLE swarm_tasks
+    ADD COLUMN IF NOT EXISTS hash_version INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE swarm_tasks DROP COLUMN IF EXISTS hash_version;
+-- +goose StatementEnd
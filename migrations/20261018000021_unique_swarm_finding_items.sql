@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Findings are upserted per (task, item) so a repeated callback keeps the
+-- created_at of the items it reports again.
+DELETE FROM swarm_findings a
+USING swarm_findings b
+WHERE a.task_id = b.task_id
+  AND a.item_index = b.item_index
+  AND (a.created_at, a.id) > (b.created_at, b.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_swarm_findings_task_item ON swarm_findings (task_id, item_index);
+DROP INDEX IF EXISTS idx_swarm_findings_task;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_swarm_findings_task ON swarm_findings (task_id);
+DROP INDEX IF EXISTS idx_swarm_findings_task_item;
+-- +goose StatementEnd
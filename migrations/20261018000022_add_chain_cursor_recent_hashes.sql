@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chain_cursors ADD COLUMN IF NOT EXISTS recent_hashes JSONB;
+
+-- Events removed by a reorg before this release kept their reconciliation;
+-- clear it so they are reconciled again if their block returns.
+UPDATE chain_events
+SET reconciliation = NULL, mismatch_reason = NULL, reconciled_at = NULL
+WHERE removed = TRUE AND reconciled_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chain_cursors DROP COLUMN IF EXISTS recent_hashes;
+-- +goose StatementEnd
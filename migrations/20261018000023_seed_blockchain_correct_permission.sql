@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Manual ledger corrections (POST /api/v1/blockchain/correct/:task_id)
+-- require blockchain:correct; admins get it unless they already hold "*".
+UPDATE roles
+SET permissions = permissions || '["blockchain:correct"]'::jsonb
+WHERE name = 'admin'
+  AND NOT permissions ? 'blockchain:correct'
+  AND NOT permissions ? '*';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE roles
+SET permissions = permissions - 'blockchain:correct'
+WHERE name = 'admin';
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A log has at most one active timestamp token. Duplicates left by
+-- concurrent inserts are superseded by the newest, the one GetActive
+-- already returned.
+UPDATE timestamp_tokens t
+SET superseded_by = newest.id
+FROM (
+    SELECT DISTINCT ON (log_id) log_id, id
+    FROM timestamp_tokens
+    WHERE superseded_by IS NULL
+    ORDER BY log_id, id DESC
+) newest
+WHERE t.log_id = newest.log_id
+  AND t.superseded_by IS NULL
+  AND t.id <> newest.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_timestamp_tokens_active ON timestamp_tokens (log_id) WHERE superseded_by IS NULL;
+
+-- A correction retires the old token before the new row is inserted.
+ALTER TABLE timestamp_tokens ALTER CONSTRAINT timestamp_tokens_superseded_by_fkey DEFERRABLE INITIALLY DEFERRED;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE timestamp_tokens ALTER CONSTRAINT timestamp_tokens_superseded_by_fkey NOT DEFERRABLE;
+DROP INDEX IF EXISTS idx_timestamp_tokens_active;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- An audit entry created before the end of its tenant's latest anchored
+-- period would never be anchored: the anchorer only seals later periods.
+-- Such entries are rejected. The tenant's advisory lock is taken shared, and
+-- exclusively while the anchorer seals a period, so an entry is either in
+-- the sealed period or sees its anchor.
+CREATE OR REPLACE FUNCTION reject_anchored_audit_log() RETURNS trigger AS $$
+BEGIN
+    IF NEW.tenant_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+    PERFORM pg_advisory_xact_lock_shared(hashtextextended('audit_anchor:' || NEW.tenant_id::text, 0));
+    IF EXISTS (
+        SELECT 1 FROM audit_anchors
+        WHERE tenant_id = NEW.tenant_id AND period_end > NEW.created_at
+    ) THEN
+        RAISE EXCEPTION 'audit log of tenant % is anchored past %', NEW.tenant_id, NEW.created_at
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'audit_period_anchored';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_reject_anchored_audit_log
+    BEFORE INSERT ON enterprise_audit_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_anchored_audit_log();
+
+CREATE INDEX IF NOT EXISTS idx_audit_anchors_tenant_end ON audit_anchors (tenant_id, period_end);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_anchors_tenant_end;
+DROP TRIGGER IF EXISTS trg_reject_anchored_audit_log ON enterprise_audit_logs;
+DROP FUNCTION IF EXISTS reject_anchored_audit_log();
+-- +goose StatementEnd
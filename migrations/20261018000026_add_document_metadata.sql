@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- User-defined document metadata, matched by the metadata search filter
+-- (JSONB containment).
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_documents_metadata ON documents USING gin (metadata jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_documents_metadata;
+ALTER TABLE documents DROP COLUMN IF EXISTS metadata;
+-- +goose StatementEnd